@@ -1,12 +1,10 @@
 package cmd
 
 import (
-	"fmt"
 	"github.com/ZnNr/GopherVault/cmdutils"
 	"github.com/ZnNr/GopherVault/internal/models"
 	"github.com/spf13/cobra"
 	"log"
-	"net/http"
 	"strings"
 )
 
@@ -16,7 +14,7 @@ var addCardCmd = &cobra.Command{
 	Short: "Add bank card info to GopherVault.",
 	Long: `Add bank card info (bank name, card number, cv, password and metadata) to GopherVault database for
 long-term storage. Only authorized users can use this command. Password and cv are stored in the database in the encrypted form.`,
-	Example: "GopherVault add-card --user user-name --bank alpha --number 1111222233334444 --cv 123 --password 1243",
+	Example: "GopherVault add-card --user user-name --bank alpha --number 1111222233334444 --type debit",
 	Run:     addCardHandler,
 }
 
@@ -25,22 +23,21 @@ func addCardHandler(cmd *cobra.Command, args []string) {
 	cfg := cmdutil.LoadEnvVariables()
 
 	// Получение значений флагов из командной строки
-	userName, bank, number, cv, password, сardType, metadata, _ := cmdutil.GetFlagsValues(cmd)
+	values := cmdutil.GetFlagsValues(cmd)
+	userName, bank, number, cv, password, сardType, metadata :=
+		values.UserName, values.Bank, values.Number, values.CV, values.Password, values.CardType, values.Metadata
 
 	// Проверка наличия всех обязательных значений
 	checkRequiredValues(userName, bank, number, cv, password, сardType)
 
 	// Создание структуры запроса для карты
 	requestCard := createCardRequest(userName, bank, number, cv, password, сardType, metadata)
+	requestCard.Fields = createCustomFields(cmd)
 
 	// Преобразование в JSON и отправка запроса на сервер
+	requestCard.Folder, requestCard.Tags = getFolderAndTags(cmd)
 	body := cmdutil.ConvertToJSONRequestCards(requestCard)
-	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/save/card", cfg.ApplicationHost, cfg.ApplicationPort), body)
-	if err != nil {
-		log.Printf(err.Error())
-	}
-
-	cmdutil.HandleResponse(resp, http.StatusOK)
+	executeWriteRequest(cfg, userName, "/save/card", body)
 }
 
 func checkRequiredValues(userName, bank, number, cv, password, cardType string) {
@@ -77,14 +74,15 @@ func init() {
 	addCardCmd.Flags().String("user", "", "user name")
 	addCardCmd.Flags().String("bank", "", "bank")
 	addCardCmd.Flags().String("number", "", "card number")
-	addCardCmd.Flags().String("cv", "", "card cv")
-	addCardCmd.Flags().String("password", "", "card password")
+	cmdutil.AddSecretFlags(addCardCmd, "cv", "card cv", "")
+	cmdutil.AddPasswordFlags(addCardCmd, "card password", true)
+	addCardCmd.MarkFlagsMutuallyExclusive("cv-stdin", "password-stdin")
 	addCardCmd.Flags().String("type", "", "card type")
 	addCardCmd.Flags().String("metadata", "", "metadata")
+	addCustomFieldFlags(addCardCmd)
+	addFolderTagFlags(addCardCmd)
 	addCardCmd.MarkFlagRequired("user")
 	addCardCmd.MarkFlagRequired("bank")
 	addCardCmd.MarkFlagRequired("number")
-	addCardCmd.MarkFlagRequired("cv")
-	addCardCmd.MarkFlagRequired("password")
 	addCardCmd.MarkFlagRequired("type")
 }
@@ -1,12 +1,9 @@
 package cmd
 
 import (
-	"fmt"
 	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
 	"github.com/ZnNr/GopherVault/internal/models"
 	"github.com/spf13/cobra"
-	"log"
-	"net/http"
 )
 
 // addCredentialsCmd представляет команду add-credentials
@@ -15,30 +12,40 @@ var addCredentialsCmd = &cobra.Command{
 	Short: "Add a pair of login/password to GopherVault.",
 	Long: `Add a pair of login/password to GopherVault database for
 long-term storage. Only authorized users can use this command. The password is stored in the database in encrypted form.`,
-	Example: "GopherVault add-credentials --user <user-name> --login <user-login> --password <password to store> --metadata <some description>",
-	Run:     addCredentialsHandler,
+	Example: "GopherVault add-credentials --user <user-name> --login <user-login> --site <site> --url <site-url> --metadata <some description>\n" +
+		"GopherVault add-credentials --user <user-name> --login <user-login> --site <site> --generate --length 24",
+	Run: addCredentialsHandler,
 }
 
 func addCredentialsHandler(cmd *cobra.Command, args []string) {
 	cfg := cmdutil.LoadEnvVariables()
 
 	// Получение значений флагов из командной строки
-	userName, login, password, metadata, _, _, _, _ := cmdutil.GetFlagsValues(cmd)
+	values := cmdutil.GetFlagsValues(cmd)
+	userName, login, metadata := values.UserName, values.Login, values.Metadata
+	password := passwordOrGenerated(cmd, cfg, values.Password)
+	warnIfBreached(cmd, cfg, password)
 
-	requestCredentials := createCredentialRequest(userName, login, password, metadata)
+	site, _ := cmd.Flags().GetString("site")
+	name, _ := cmd.Flags().GetString("name")
+	urls, _ := cmd.Flags().GetStringArray("url")
+	match, _ := cmd.Flags().GetString("match")
 
-	body := cmdutil.ConvertToJSONRequestCredential(requestCredentials)
-
-	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/save/credentials", cfg.ApplicationHost, cfg.ApplicationPort), body)
-	if err != nil {
-		log.Printf(err.Error())
+	requestCredentials := createCredentialRequest(userName, login, password, metadata, site)
+	if name != "" {
+		requestCredentials.Name = &name
 	}
+	requestCredentials.URLs = createCredentialURLs(urls, match)
+	requestCredentials.Fields = createCustomFields(cmd)
+
+	requestCredentials.Folder, requestCredentials.Tags = getFolderAndTags(cmd)
+	body := cmdutil.ConvertToJSONRequestCredential(requestCredentials)
 
-	cmdutil.HandleResponse(resp, http.StatusOK)
+	executeWriteRequest(cfg, userName, "/save/credentials", body)
 
 }
 
-func createCredentialRequest(userName, login, password, metadata string) models.Credentials {
+func createCredentialRequest(userName, login, password, metadata, site string) models.Credentials {
 	requestCredentials := models.Credentials{
 		UserName: userName,
 		Login:    &login,
@@ -47,16 +54,35 @@ func createCredentialRequest(userName, login, password, metadata string) models.
 	if metadata != "" {
 		requestCredentials.Metadata = &metadata
 	}
+	if site != "" {
+		requestCredentials.Site = &site
+	}
 	return requestCredentials
 }
 
+// createCredentialURLs создает список адресов сайтов с общим правилом сопоставления
+func createCredentialURLs(urls []string, match string) []models.CredentialURL {
+	var res []models.CredentialURL
+	for _, u := range urls {
+		res = append(res, models.CredentialURL{URL: u, Match: models.MatchRule(match)})
+	}
+	return res
+}
+
 func init() {
 	rootCmd.AddCommand(addCredentialsCmd)
 	addCredentialsCmd.Flags().String("user", "", "user name")
 	addCredentialsCmd.Flags().String("login", "", "user login")
-	addCredentialsCmd.Flags().String("password", "", "user password")
+	addCredentialsCmd.Flags().String("site", "", "site or service the credentials belong to")
+	cmdutil.AddPasswordFlags(addCredentialsCmd, "password to store", true)
 	addCredentialsCmd.Flags().String("metadata", "", "metadata")
+	addCredentialsCmd.Flags().String("name", "", "display name of the credentials")
+	addCredentialsCmd.Flags().StringArray("url", nil, "site URL the credentials are used on (can be repeated)")
+	addCredentialsCmd.Flags().String("match", string(models.MatchBaseDomain), "URL match rule: base_domain, host or regex (must match the whole URL)")
+	addCredentialsCmd.Flags().Bool("generate", false, "generate the password instead of --password")
+	addGeneratorFlags(addCredentialsCmd)
+	addCustomFieldFlags(addCredentialsCmd)
+	addFolderTagFlags(addCredentialsCmd)
 	addCredentialsCmd.MarkFlagRequired("user")
 	addCredentialsCmd.MarkFlagRequired("login")
-	addCredentialsCmd.MarkFlagRequired("password")
 }
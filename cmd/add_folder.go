@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+)
+
+// addFolderCmd представляет команду add-folder
+var addFolderCmd = &cobra.Command{
+	Use:     "add-folder",
+	Short:   "Create a folder for organizing secrets in GopherVault.",
+	Example: "GopherVault add-folder --user <user-name> --path work/projects",
+	Run:     addFolderHandler,
+}
+
+func addFolderHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+	path, _ := cmd.Flags().GetString("path")
+
+	requestFolder := models.Folder{
+		UserName: userName,
+	}
+	if path != "" {
+		requestFolder.Path = &path
+	}
+	body := cmdutil.ConvertToJSONRequestFolder(requestFolder)
+
+	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/save/folder", cfg.ApplicationHost, cfg.ApplicationPort), body)
+	if err != nil {
+		log.Printf(err.Error())
+	}
+
+	cmdutil.HandleResponse(resp, http.StatusOK)
+}
+
+func init() {
+	rootCmd.AddCommand(addFolderCmd)
+	addFolderCmd.Flags().String("user", "", "user name")
+	addFolderCmd.Flags().String("path", "", "folder path, parent folders are created automatically")
+	addFolderCmd.MarkFlagRequired("user")
+	addFolderCmd.MarkFlagRequired("path")
+}
@@ -1,12 +1,9 @@
 package cmd
 
 import (
-	"fmt"
 	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
 	"github.com/ZnNr/GopherVault/internal/models"
 	"github.com/spf13/cobra"
-	"log"
-	"net/http"
 )
 
 // addNotesCmd represents the add-notes command
@@ -22,18 +19,16 @@ Only authorized users can use this command. The note content is stored in the da
 func addNoteHandler(cmd *cobra.Command, args []string) {
 	cfg := cmdutil.LoadEnvVariables()
 
-	userName, title, content, metadata, _, _, _, _ := cmdutil.GetFlagsValues(cmd)
+	values := cmdutil.GetFlagsValues(cmd)
+	userName, title, content, metadata := values.UserName, values.Title, values.Content, values.Metadata
 
 	requestNote := createNoteRequest(userName, title, content, metadata)
+	requestNote.Fields = createCustomFields(cmd)
 
+	requestNote.Folder, requestNote.Tags = getFolderAndTags(cmd)
 	body := cmdutil.ConvertToJSONRequestNotes(requestNote)
 
-	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/save/note", cfg.ApplicationHost, cfg.ApplicationPort), body)
-	if err != nil {
-		log.Printf(err.Error())
-	}
-
-	cmdutil.HandleResponse(resp, http.StatusOK)
+	executeWriteRequest(cfg, userName, "/save/note", body)
 }
 
 func createNoteRequest(userName, title, content, metadata string) models.Note {
@@ -56,7 +51,9 @@ func init() {
 	addNotesCmd.Flags().String("title", "", "user login")
 	addNotesCmd.Flags().String("content", "", "user password")
 	addNotesCmd.Flags().String("metadata", "", "metadata")
+	addCustomFieldFlags(addNotesCmd)
 	// Помечаем флаги, как обязательные
+	addFolderTagFlags(addNotesCmd)
 	addNotesCmd.MarkFlagRequired("user")
 	addNotesCmd.MarkFlagRequired("title")
 	addNotesCmd.MarkFlagRequired("content")
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+	"os"
+)
+
+// addSSHKeyCmd представляет команду add-ssh-key
+var addSSHKeyCmd = &cobra.Command{
+	Use:   "add-ssh-key",
+	Short: "Add an SSH private key to GopherVault.",
+	Long: `Add an SSH private key (ed25519, RSA or ECDSA) to GopherVault database for long-term storage.
+The public key and fingerprint are derived on save. Only authorized users can use this command.
+The private key and its passphrase are stored in the database in encrypted form.`,
+	Example: "GopherVault add-ssh-key --user <user-name> --name <key name> --file ~/.ssh/id_ed25519 --passphrase <key passphrase>",
+	Run:     addSSHKeyHandler,
+}
+
+func addSSHKeyHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+
+	userName, _ := cmd.Flags().GetString("user")
+	name, _ := cmd.Flags().GetString("name")
+	file, _ := cmd.Flags().GetString("file")
+	passphrase, _ := cmd.Flags().GetString("passphrase")
+	metadata, _ := cmd.Flags().GetString("metadata")
+
+	privateKey, err := os.ReadFile(file)
+	if err != nil {
+		log.Fatalf("ошибка при чтении файла ключа: %s", err.Error())
+	}
+	key := string(privateKey)
+
+	requestKey := models.SSHKey{
+		UserName:   userName,
+		Name:       &name,
+		PrivateKey: &key,
+	}
+	if passphrase != "" {
+		requestKey.Passphrase = &passphrase
+	}
+	if metadata != "" {
+		requestKey.Metadata = &metadata
+	}
+
+	body := cmdutil.ConvertToJSONRequestSSHKey(requestKey)
+
+	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/save/ssh-key", cfg.ApplicationHost, cfg.ApplicationPort), body)
+	if err != nil {
+		log.Printf(err.Error())
+	}
+
+	cmdutil.HandleResponse(resp, http.StatusOK)
+}
+
+func init() {
+	rootCmd.AddCommand(addSSHKeyCmd)
+	addSSHKeyCmd.Flags().String("user", "", "user name")
+	addSSHKeyCmd.Flags().String("name", "", "name of the key")
+	addSSHKeyCmd.Flags().String("file", "", "path to the private key file")
+	addSSHKeyCmd.Flags().String("passphrase", "", "passphrase of the private key")
+	addSSHKeyCmd.Flags().String("metadata", "", "metadata")
+	addSSHKeyCmd.MarkFlagRequired("user")
+	addSSHKeyCmd.MarkFlagRequired("name")
+	addSSHKeyCmd.MarkFlagRequired("file")
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+)
+
+// addTOTPCmd представляет команду add-totp
+var addTOTPCmd = &cobra.Command{
+	Use:   "add-totp",
+	Short: "Add a TOTP secret to GopherVault.",
+	Long: `Add a TOTP secret (otpauth:// URI with secret, digits, period and algorithm) to GopherVault database.
+The secret can be attached to existing credentials with --login and --site. Only authorized users can use this command.
+The URI is stored in the database in encrypted form.`,
+	Example: "GopherVault add-totp --user <user-name> --name <secret name> --uri 'otpauth://totp/Issuer:account?secret=BASE32SECRET' --login <saved-login>",
+	Run:     addTOTPHandler,
+}
+
+func addTOTPHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+
+	userName, _ := cmd.Flags().GetString("user")
+	name, _ := cmd.Flags().GetString("name")
+	uri, _ := cmd.Flags().GetString("uri")
+	login, _ := cmd.Flags().GetString("login")
+	site, _ := cmd.Flags().GetString("site")
+	metadata, _ := cmd.Flags().GetString("metadata")
+
+	requestTOTP := models.TOTP{
+		UserName: userName,
+		Name:     &name,
+		URI:      &uri,
+	}
+	if login != "" {
+		requestTOTP.Login = &login
+	}
+	if site != "" {
+		requestTOTP.Site = &site
+	}
+	if metadata != "" {
+		requestTOTP.Metadata = &metadata
+	}
+
+	body := cmdutil.ConvertToJSONRequestTOTP(requestTOTP)
+
+	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/save/totp", cfg.ApplicationHost, cfg.ApplicationPort), body)
+	if err != nil {
+		log.Printf(err.Error())
+	}
+
+	cmdutil.HandleResponse(resp, http.StatusOK)
+}
+
+func init() {
+	rootCmd.AddCommand(addTOTPCmd)
+	addTOTPCmd.Flags().String("user", "", "user name")
+	addTOTPCmd.Flags().String("name", "", "name of the secret")
+	addTOTPCmd.Flags().String("uri", "", "otpauth:// URI of the secret")
+	addTOTPCmd.Flags().String("login", "", "login of the credentials to attach the secret to")
+	addTOTPCmd.Flags().String("site", "", "site of the credentials to attach the secret to")
+	addTOTPCmd.Flags().String("metadata", "", "metadata")
+	addTOTPCmd.MarkFlagRequired("user")
+	addTOTPCmd.MarkFlagRequired("name")
+	addTOTPCmd.MarkFlagRequired("uri")
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"context"
+	"crypto/ecdh"
+	"fmt"
+	"log"
+	"os"
+
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/backupfile"
+	"github.com/ZnNr/GopherVault/internal/database"
+	"github.com/spf13/cobra"
+)
+
+// adminCmd представляет команду admin
+var adminCmd = &cobra.Command{
+	Use:   "admin",
+	Short: "Server administration: backup and restore of the database",
+	Long: `Administration commands run on the server host and connect to the database directly
+with the POSTGRES_* and KEEPER_ENCRYPTION_KEY variables from .env.`,
+}
+
+// adminBackupCmd представляет команду admin backup
+var adminBackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Write a consistent compressed backup of all tables",
+	Long: `Write all tables in one read-only transaction into a gzip-compressed archive with the schema version,
+per-table row counts and SHA-256 checksums. Secrets stay encrypted with KEEPER_ENCRYPTION_KEY.
+With --recipient the archive is encrypted to the operator public key from "admin keygen",
+and the data encryption key is stored in the archive wrapped with the same key.`,
+	Example: "GopherVault admin backup --out backup.gvb --recipient operator.key.pub",
+	Run:     adminBackupHandler,
+}
+
+// adminRestoreCmd представляет команду admin restore
+var adminRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore a backup into the database",
+	Long: `Restore a backup in one transaction. The database must be migrated to the schema version of the backup,
+use the same KEEPER_ENCRYPTION_KEY and be empty; --force replaces existing data.
+The checksums are verified before the transaction is committed. Requires a PostgreSQL superuser.`,
+	Example: "GopherVault admin restore --in backup.gvb --identity operator.key",
+	Run:     adminRestoreHandler,
+}
+
+// adminKeygenCmd представляет команду admin keygen
+var adminKeygenCmd = &cobra.Command{
+	Use:     "keygen",
+	Short:   "Generate an operator key pair for encrypted backups",
+	Long:    "Write the private key to --out and the public key to --out with the .pub suffix. Keep the private key offline.",
+	Example: "GopherVault admin keygen --out operator.key",
+	Run:     adminKeygenHandler,
+}
+
+// adminRecoverKeyCmd представляет команду admin recover-key
+var adminRecoverKeyCmd = &cobra.Command{
+	Use:     "recover-key",
+	Short:   "Print the data encryption key stored in a backup",
+	Long:    "Unwrap KEEPER_ENCRYPTION_KEY from the header of a backup made with --recipient using the operator private key.",
+	Example: "GopherVault admin recover-key --in backup.gvb --identity operator.key",
+	Run:     adminRecoverKeyHandler,
+}
+
+func adminBackupHandler(cmd *cobra.Command, args []string) {
+	out, _ := cmd.Flags().GetString("out")
+	recipientFile, _ := cmd.Flags().GetString("recipient")
+	force, _ := cmd.Flags().GetBool("force")
+	var recipient *ecdh.PublicKey
+	if recipientFile != "" {
+		text, err := os.ReadFile(recipientFile)
+		if err != nil {
+			log.Fatalf("ошибка при чтении открытого ключа оператора: %s", err)
+		}
+		if recipient, err = backupfile.ParsePublicKey(string(text)); err != nil {
+			log.Fatalf("некорректный открытый ключ оператора: %s", err)
+		}
+	}
+
+	pg := openAdminDatabase()
+	defer pg.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	file, err := os.OpenFile(out, flags, 0o600)
+	if err != nil {
+		log.Fatalf("ошибка при создании файла резервной копии: %s", err)
+	}
+	header, tables, err := pg.Backup(context.Background(), file, recipient)
+	if err == nil {
+		err = file.Close()
+	} else {
+		file.Close()
+	}
+	if err != nil {
+		// Неполный архив удаляем, чтобы его нельзя было принять за резервную копию
+		os.Remove(out)
+		log.Fatalf("ошибка при создании резервной копии: %s", err)
+	}
+	printBackupSummary(tables)
+	log.Printf("резервная копия схемы версии %d записана в %s", header.SchemaVersion, out)
+}
+
+func adminRestoreHandler(cmd *cobra.Command, args []string) {
+	in, _ := cmd.Flags().GetString("in")
+	force, _ := cmd.Flags().GetBool("force")
+	identity := readIdentity(cmd)
+
+	file, err := os.Open(in)
+	if err != nil {
+		log.Fatalf("ошибка при открытии резервной копии: %s", err)
+	}
+	defer file.Close()
+
+	pg := openAdminDatabase()
+	defer pg.Close()
+	header, tables, err := pg.Restore(context.Background(), file, identity, force)
+	if err != nil {
+		log.Fatalf("ошибка при восстановлении резервной копии: %s", err)
+	}
+	printBackupSummary(tables)
+	log.Printf("восстановлена резервная копия от %s, версия схемы %d", header.CreatedAt.Format("2006-01-02 15:04:05 MST"), header.SchemaVersion)
+}
+
+func adminKeygenHandler(cmd *cobra.Command, args []string) {
+	out, _ := cmd.Flags().GetString("out")
+	key, err := backupfile.GenerateKey()
+	if err != nil {
+		log.Fatalf("ошибка при создании ключа оператора: %s", err)
+	}
+	writeExportFile(out, []byte(backupfile.MarshalPrivateKey(key)), false)
+	writeExportFile(out+".pub", []byte(backupfile.MarshalPublicKey(key.PublicKey())), false)
+	log.Printf("закрытый ключ оператора записан в %s, открытый - в %s.pub", out, out)
+}
+
+func adminRecoverKeyHandler(cmd *cobra.Command, args []string) {
+	in, _ := cmd.Flags().GetString("in")
+	identity := readIdentity(cmd)
+	if identity == nil {
+		log.Fatalln("укажите закрытый ключ оператора флагом --identity")
+	}
+
+	file, err := os.Open(in)
+	if err != nil {
+		log.Fatalf("ошибка при открытии резервной копии: %s", err)
+	}
+	defer file.Close()
+	reader, err := backupfile.NewReader(file, identity)
+	if err != nil {
+		log.Fatalf("ошибка при чтении резервной копии: %s", err)
+	}
+	wrapped := reader.Header().WrappedKey
+	if len(wrapped) == 0 {
+		log.Fatalln("резервная копия создана без --recipient и не содержит ключ шифрования данных")
+	}
+	key, err := backupfile.UnwrapKey(identity, wrapped)
+	if err != nil {
+		log.Fatalf("ошибка при расшифровке ключа шифрования данных: %s", err)
+	}
+	fmt.Printf("KEEPER_ENCRYPTION_KEY=%s\n", key)
+}
+
+// openAdminDatabase подключается к базе данных по переменным окружения сервера
+func openAdminDatabase() *database.Db {
+	cfg := cmdutil.LoadEnvVariables()
+	pg, err := database.New(cfg)
+	if err != nil {
+		log.Fatalf("ошибка при подключении к базе данных: %s", err)
+	}
+	return pg
+}
+
+// readIdentity читает закрытый ключ оператора из файла флага --identity; без флага возвращает nil
+func readIdentity(cmd *cobra.Command) *ecdh.PrivateKey {
+	identityFile, _ := cmd.Flags().GetString("identity")
+	if identityFile == "" {
+		return nil
+	}
+	text, err := os.ReadFile(identityFile)
+	if err != nil {
+		log.Fatalf("ошибка при чтении закрытого ключа оператора: %s", err)
+	}
+	identity, err := backupfile.ParsePrivateKey(string(text))
+	if err != nil {
+		log.Fatalf("некорректный закрытый ключ оператора: %s", err)
+	}
+	return identity
+}
+
+// printBackupSummary выводит число строк и контрольную сумму каждой таблицы
+func printBackupSummary(tables []backupfile.TableSummary) {
+	for _, table := range tables {
+		fmt.Printf("%-20s %8d  %s\n", table.Name, table.Rows, table.SHA256)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(adminCmd)
+	for _, cmd := range []*cobra.Command{adminBackupCmd, adminRestoreCmd, adminKeygenCmd, adminRecoverKeyCmd} {
+		adminCmd.AddCommand(cmd)
+	}
+	adminBackupCmd.Flags().String("out", "", "path of the backup file")
+	adminBackupCmd.Flags().String("recipient", "", "operator public key file to encrypt the backup to")
+	adminBackupCmd.Flags().Bool("force", false, "overwrite the backup file if it exists")
+	adminBackupCmd.MarkFlagRequired("out")
+
+	adminRestoreCmd.Flags().String("in", "", "path of the backup file")
+	adminRestoreCmd.Flags().String("identity", "", "operator private key file for an encrypted backup")
+	adminRestoreCmd.Flags().Bool("force", false, "replace existing data in the database")
+	adminRestoreCmd.MarkFlagRequired("in")
+
+	adminKeygenCmd.Flags().String("out", "", "path of the private key file")
+	adminKeygenCmd.MarkFlagRequired("out")
+
+	adminRecoverKeyCmd.Flags().String("in", "", "path of the backup file")
+	adminRecoverKeyCmd.Flags().String("identity", "", "operator private key file")
+	adminRecoverKeyCmd.MarkFlagRequired("in")
+	adminRecoverKeyCmd.MarkFlagRequired("identity")
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// auditCmd представляет команду audit
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Report weak, reused, old and missing-2FA credentials",
+	Long: `Ask the server for a password health report of all user's credentials:
+  weak         strength score (0-4) below --min-score
+  reused       the same password is used by several credentials
+  old          not changed for --max-age-days days
+  missing 2FA  no TOTP secret is linked to the credentials
+Reused passwords are compared by HMAC with a key generated for every report, passwords are never printed.
+With --json the report is printed as JSON for dashboards.`,
+	Example: "GopherVault audit --user <user-name>\n" +
+		"GopherVault audit --user <user-name> --json --max-age-days 180 --min-score 4",
+	Run: auditHandler,
+}
+
+func auditHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+	maxAgeDays, _ := cmd.Flags().GetInt("max-age-days")
+	minScore, _ := cmd.Flags().GetInt("min-score")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	body, err := json.Marshal(models.HealthRequest{UserName: userName, MaxAgeDays: maxAgeDays, MinScore: minScore})
+	if err != nil {
+		log.Fatalf("ошибка при маршалинге запроса: %s", err)
+	}
+	resp, err := cmdutil.ExecutePostRequest(serverURL(cfg, "/reports/health"), body)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+	if resp.StatusCode() != http.StatusOK {
+		cmdutil.HandleResponse(resp, http.StatusOK)
+		os.Exit(1)
+	}
+	if asJSON {
+		fmt.Println(resp.String())
+		return
+	}
+	var report models.HealthReport
+	if err = json.Unmarshal(resp.Body(), &report); err != nil {
+		log.Fatalf("некорректный ответ сервера: %s", err)
+	}
+	printHealthReport(report)
+}
+
+// printHealthReport выводит отчет о состоянии паролей таблицами
+func printHealthReport(report models.HealthReport) {
+	summary := report.Summary
+	fmt.Printf("Проверено учетных данных: %d\n", summary.Total)
+	fmt.Printf("Слабые: %d, повторяющиеся: %d, старые: %d, без второго фактора: %d\n",
+		summary.Weak, summary.Reused, summary.Old, summary.Missing2FA)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if len(report.Weak) > 0 {
+		fmt.Fprintf(w, "\nСлабые пароли (оценка ниже %d):\n", report.MinScore)
+		fmt.Fprintln(w, "ID\tНАЗВАНИЕ\tОЦЕНКА\tПРИЧИНА")
+		for _, item := range report.Weak {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", healthItemID(item), item.Name, item.Score, item.Warning)
+		}
+	}
+	if len(report.Reused) > 0 {
+		fmt.Fprintln(w, "\nПовторяющиеся пароли:")
+		fmt.Fprintln(w, "ГРУППА\tID\tНАЗВАНИЕ")
+		for _, group := range report.Reused {
+			for _, item := range group.Items {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", group.Hash, healthItemID(item), item.Name)
+			}
+		}
+	}
+	if len(report.Old) > 0 {
+		fmt.Fprintf(w, "\nПароли не менялись %d дней и дольше:\n", report.MaxAgeDays)
+		fmt.Fprintln(w, "ID\tНАЗВАНИЕ\tДНЕЙ\tИЗМЕНЕН")
+		for _, item := range report.Old {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", healthItemID(item), item.Name, item.AgeDays, item.UpdatedAt.Format("2006-01-02"))
+		}
+	}
+	if len(report.Missing2FA) > 0 {
+		fmt.Fprintln(w, "\nБез второго фактора:")
+		fmt.Fprintln(w, "ID\tНАЗВАНИЕ")
+		for _, item := range report.Missing2FA {
+			fmt.Fprintf(w, "%s\t%s\n", healthItemID(item), item.Name)
+		}
+	}
+	w.Flush()
+}
+
+// healthItemID возвращает идентификатор учетных данных из отчета или прочерк, если он неизвестен
+func healthItemID(item models.HealthItem) string {
+	if item.ID == nil {
+		return "-"
+	}
+	return strconv.FormatInt(*item.ID, 10)
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.Flags().String("user", "", "user name")
+	auditCmd.Flags().Int("max-age-days", 0, "days without change after which a password is old (default 365)")
+	auditCmd.Flags().Int("min-score", 0, "strength score from 1 to 4 below which a password is weak (default 3)")
+	auditCmd.Flags().Bool("json", false, "print the report as JSON")
+	auditCmd.MarkFlagRequired("user")
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"text/tabwriter"
+
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/breach"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// breachCheckCmd представляет команду breach-check
+var breachCheckCmd = &cobra.Command{
+	Use:   "breach-check",
+	Short: "Check stored passwords against a local Pwned Passwords dataset",
+	Long: `Check every stored password against a local copy of the Pwned Passwords (HIBP) dataset without network access.
+The dataset is either a directory of SHA-1 range files XXXXX.txt as written by haveibeenpwned-downloader
+or an index built from it with "breach-check build-index". The path is taken from --dataset or KEEPER_BREACH_DATASET.
+Passwords are hashed on the client and never leave it. Exits with status 1 if a breached password is found.`,
+	Example: "GopherVault breach-check --user <user-name> --dataset /srv/pwnedpasswords",
+	Run:     breachCheckHandler,
+}
+
+// breachIndexCmd представляет команду breach-check build-index
+var breachIndexCmd = &cobra.Command{
+	Use:   "build-index",
+	Short: "Build a compact index from a directory of Pwned Passwords range files",
+	Long: `Write the sorted leading --prefix-bytes bytes of every SHA-1 hash from the range files into one file.
+The index does not keep breach counts; 8-byte prefixes make false positives practically impossible
+and take about 7 GB for the full dataset.`,
+	Example: "GopherVault breach-check build-index --from /srv/pwnedpasswords --out pwned.idx",
+	Run:     breachIndexHandler,
+}
+
+func breachCheckHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+	dataset := openBreachDataset(cmd, cfg)
+	if dataset == nil {
+		log.Fatalln("укажите базу утечек флагом --dataset или переменной KEEPER_BREACH_DATASET")
+	}
+	defer dataset.Close()
+
+	body := cmdutil.ConvertToJSONRequestCredential(models.Credentials{UserName: userName})
+	resp, err := cmdutil.ExecutePostRequest(serverURL(cfg, "/get/credentials"), body)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+	var credentials []models.Credentials
+	switch resp.StatusCode() {
+	case http.StatusOK:
+		if err = json.Unmarshal(resp.Body(), &credentials); err != nil {
+			log.Fatalf("некорректный ответ сервера: %s", err)
+		}
+	case http.StatusNoContent:
+	default:
+		cmdutil.HandleResponse(resp, http.StatusOK)
+		os.Exit(1)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	breached := 0
+	for _, creds := range credentials {
+		if creds.Password == nil {
+			continue
+		}
+		count, err := dataset.Count(*creds.Password)
+		if err != nil {
+			log.Fatalf("ошибка при проверке пароля %q: %s", creds.ReportName(), err)
+		}
+		if count == 0 {
+			continue
+		}
+		if breached == 0 {
+			fmt.Fprintln(w, "ID\tНАЗВАНИЕ\tПОЯВЛЕНИЙ В УТЕЧКАХ")
+		}
+		breached++
+		id := "-"
+		if creds.ID != nil {
+			id = fmt.Sprint(*creds.ID)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", id, creds.ReportName(), breachCount(count))
+	}
+	w.Flush()
+	fmt.Printf("Проверено паролей: %d, найдено в утечках: %d\n", len(credentials), breached)
+	if breached > 0 {
+		os.Exit(1)
+	}
+}
+
+func breachIndexHandler(cmd *cobra.Command, args []string) {
+	from, _ := cmd.Flags().GetString("from")
+	out, _ := cmd.Flags().GetString("out")
+	prefixBytes, _ := cmd.Flags().GetInt("prefix-bytes")
+	force, _ := cmd.Flags().GetBool("force")
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	file, err := os.OpenFile(out, flags, 0o644)
+	if err != nil {
+		log.Fatalf("ошибка при создании файла индекса: %s", err)
+	}
+	records, err := breach.BuildIndex(from, file, prefixBytes)
+	if err == nil {
+		err = file.Close()
+	} else {
+		file.Close()
+	}
+	if err != nil {
+		// Неполный индекс удаляем, чтобы по нему не проверялись пароли
+		os.Remove(out)
+		log.Fatalf("ошибка при построении индекса утечек: %s", err)
+	}
+	log.Printf("индекс из %d префиксов по %d байт записан в %s", records, prefixBytes, out)
+}
+
+// openBreachDataset открывает базу утечек из флага --dataset или переменной KEEPER_BREACH_DATASET.
+// Возвращает nil, если база не указана.
+func openBreachDataset(cmd *cobra.Command, cfg models.Params) breach.Dataset {
+	path := cfg.BreachDataset
+	if cmd.Flags().Lookup("dataset") != nil {
+		if flagPath, _ := cmd.Flags().GetString("dataset"); flagPath != "" {
+			path = flagPath
+		}
+	}
+	if path == "" {
+		return nil
+	}
+	dataset, err := breach.Open(path)
+	if err != nil {
+		log.Fatalf("ошибка при открытии базы утечек: %s", err)
+	}
+	return dataset
+}
+
+// warnIfBreached предупреждает, если сохраняемый пароль есть в базе утечек KEEPER_BREACH_DATASET.
+// Без базы проверка не выполняется, ошибка проверки не мешает сохранению.
+func warnIfBreached(cmd *cobra.Command, cfg models.Params, password string) {
+	dataset := openBreachDataset(cmd, cfg)
+	if dataset == nil {
+		return
+	}
+	defer dataset.Close()
+	count, err := dataset.Count(password)
+	if err != nil {
+		log.Printf("не удалось проверить пароль по базе утечек: %s", err)
+		return
+	}
+	if count > 0 {
+		log.Printf("ВНИМАНИЕ: пароль найден в утечках (%s), выберите другой пароль", breachCount(count))
+	}
+}
+
+// breachCount описывает число появлений пароля в утечках; индекс число появлений не хранит
+func breachCount(count int) string {
+	if count == 1 {
+		return "найден"
+	}
+	return fmt.Sprintf("%d раз", count)
+}
+
+func init() {
+	rootCmd.AddCommand(breachCheckCmd)
+	breachCheckCmd.AddCommand(breachIndexCmd)
+	breachCheckCmd.Flags().String("user", "", "user name")
+	breachCheckCmd.Flags().String("dataset", "", "directory of range files or index file (default KEEPER_BREACH_DATASET)")
+	breachCheckCmd.MarkFlagRequired("user")
+
+	breachIndexCmd.Flags().String("from", "", "directory of Pwned Passwords range files")
+	breachIndexCmd.Flags().String("out", "", "path of the index file")
+	breachIndexCmd.Flags().Int("prefix-bytes", breach.DefaultPrefixBytes, "bytes of every SHA-1 hash to keep, from 4 to 20")
+	breachIndexCmd.Flags().Bool("force", false, "overwrite the index file if it exists")
+	breachIndexCmd.MarkFlagRequired("from")
+	breachIndexCmd.MarkFlagRequired("out")
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+// addBulkDeleteFlags добавляет команде удаления флаги --all и --yes
+func addBulkDeleteFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("all", false, "delete every matching item when no item key is given")
+	cmd.Flags().Bool("yes", false, "confirm the deletion")
+}
+
+// confirmBulkDelete завершает команду, если удаление затрагивает все секреты пользователя (для команд с отбором
+// по папке и тегам - все секреты, подходящие под фильтр), а флаги --all и --yes не указаны
+func confirmBulkDelete(cmd *cobra.Command, what string) {
+	all, _ := cmd.Flags().GetBool("all")
+	yes, _ := cmd.Flags().GetBool("yes")
+	if all && yes {
+		return
+	}
+	if cmd.Flags().Lookup("folder") != nil {
+		what += " пользователя, подходящие под фильтр"
+	} else {
+		what += " пользователя"
+	}
+	log.Fatalf("не указан ключ удаления: будут удалены все %s; для подтверждения укажите флаги --all --yes", what)
+}
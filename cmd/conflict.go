@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"log"
+	"net/http"
+	"sort"
+)
+
+// diffIgnoredFields поля секрета, которые не сравниваются при выводе различий
+var diffIgnoredFields = map[string]bool{
+	"user_name": true, "id": true, "created_at": true, "updated_at": true, "last_accessed_at": true, "revision": true,
+}
+
+// executeConditionalUpdate отправляет запрос на изменение секрета с проверкой ревизии.
+// При конфликте ревизий предлагает показать различия с текущей версией (секретные значения - только с showSecrets)
+// и повторить изменение поверх нее.
+// Если сервер недоступен, изменение вместе с ожидаемой ревизией ставится в очередь локального кэша.
+func executeConditionalUpdate(cfg models.Params, userName, path string, body []byte, revision int64, showSecrets bool) {
+	for {
+		resp, err := cmdutil.ExecuteConditionalPostRequest(serverURL(cfg, path), body, revision)
+		if err != nil {
+			if queueOffline(cfg, userName, path, body, revision) {
+				return
+			}
+			log.Fatalln(err.Error())
+		}
+		if resp.StatusCode() != http.StatusPreconditionFailed {
+			cmdutil.HandleResponse(resp, http.StatusOK)
+			return
+		}
+
+		var conflict models.RevisionConflict
+		if err = json.Unmarshal(resp.Body(), &conflict); err != nil {
+			log.Fatalf("некорректный ответ сервера: %s", resp.String())
+		}
+		fmt.Println(conflict.Message)
+		if conflict.Current == nil {
+			return
+		}
+		if cmdutil.Confirm(fmt.Sprintf("Показать отличия от текущей ревизии %d?", conflict.Revision)) {
+			printDiff(conflict.Current, body, showSecrets)
+		}
+		if !cmdutil.Confirm(fmt.Sprintf("Повторить изменение поверх ревизии %d?", conflict.Revision)) {
+			return
+		}
+		revision = conflict.Revision
+	}
+}
+
+// diffSecretFields поля секрета, значения которых не выводятся при сравнении без флага --show-secrets
+var diffSecretFields = map[string]bool{"password": true, "content": true, "cv": true}
+
+// printDiff выводит поля, которые различаются в текущей версии секрета и отправляемом изменении.
+// Значения секретных полей и скрытых пользовательских полей заменяются отметкой об их изменении,
+// если showSecrets не указан, чтобы они не попали в историю терминала и журналы.
+func printDiff(current, next []byte, showSecrets bool) {
+	currentFields, currentSecrets := diffFields(current)
+	nextFields, nextSecrets := diffFields(next)
+
+	names := make(map[string]bool)
+	for name := range currentFields {
+		names[name] = true
+	}
+	for name := range nextFields {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		if !diffIgnoredFields[name] {
+			sorted = append(sorted, name)
+		}
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		currentValue, _ := json.Marshal(currentFields[name])
+		nextValue, _ := json.Marshal(nextFields[name])
+		_, inCurrent := currentFields[name]
+		_, inNext := nextFields[name]
+		changed := string(currentValue) != string(nextValue)
+		if !showSecrets && (currentSecrets[name] || nextSecrets[name]) {
+			switch {
+			case changed:
+				fmt.Printf("~ %s: (changed)\n", name)
+			case inCurrent && inNext:
+				fmt.Printf("  %s: (unchanged)\n", name)
+			}
+			continue
+		}
+		if !changed {
+			continue
+		}
+		if inCurrent {
+			fmt.Printf("- %s: %s\n", name, currentValue)
+		}
+		if inNext {
+			fmt.Printf("+ %s: %s\n", name, nextValue)
+		}
+	}
+}
+
+// diffFields разбирает JSON секрета в поля для сравнения. Пользовательские поля сравниваются по отдельности
+// под именами fields.<название>. Возвращает также имена полей с секретными значениями.
+func diffFields(data []byte) (map[string]interface{}, map[string]bool) {
+	var secret struct {
+		Fields []models.CustomField `json:"fields"`
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		log.Fatalf("некорректный ответ сервера: %s", err)
+	}
+	if err := json.Unmarshal(data, &secret); err != nil {
+		log.Fatalf("некорректный ответ сервера: %s", err)
+	}
+
+	secrets := make(map[string]bool)
+	for name := range fields {
+		if diffSecretFields[name] {
+			secrets[name] = true
+		}
+	}
+	delete(fields, "fields")
+	for _, f := range secret.Fields {
+		name := "fields." + f.Name
+		fields[name] = f.Value
+		if f.Type == models.FieldHidden {
+			secrets[name] = true
+		}
+	}
+	return fields, secrets
+}
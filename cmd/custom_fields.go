@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ZnNr/GopherVault/internal/fields"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// customFieldsFlag значение флагов --field и --secret-field. Оба флага добавляют поля в общий список,
+// поэтому поля сохраняют порядок, в котором они указаны в командной строке.
+type customFieldsFlag struct {
+	fields *[]models.CustomField
+	hidden bool // Поля флага --secret-field всегда скрытые
+}
+
+// Set разбирает поле name=value или name:type=value и добавляет его в список
+func (f customFieldsFlag) Set(spec string) error {
+	field, err := fields.Parse(spec, models.FieldText)
+	if err != nil {
+		return err
+	}
+	if f.hidden {
+		field.Type = models.FieldHidden
+	}
+	*f.fields = append(*f.fields, field)
+	return nil
+}
+
+// Type возвращает тип значения флага для справки
+func (f customFieldsFlag) Type() string {
+	return "stringArray"
+}
+
+// String возвращает поля флага в виде, в котором они указываются в командной строке. Значения скрытых полей не выводятся.
+func (f customFieldsFlag) String() string {
+	var specs []string
+	for _, field := range *f.fields {
+		switch hidden := field.Type == models.FieldHidden; {
+		case hidden && f.hidden:
+			specs = append(specs, field.Name+"=***")
+		case !hidden && !f.hidden:
+			specs = append(specs, fmt.Sprintf("%s:%s=%s", field.Name, field.Type, field.Value))
+		}
+	}
+	if len(specs) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(specs, ",") + "]"
+}
+
+// addCustomFieldFlags добавляет команде флаги пользовательских полей --field и --secret-field
+func addCustomFieldFlags(cmd *cobra.Command) {
+	var list []models.CustomField
+	cmd.Flags().Var(customFieldsFlag{fields: &list}, "field",
+		"custom field as name=value or name:type=value, type is text, url, email, date or boolean (can be repeated)")
+	cmd.Flags().Var(customFieldsFlag{fields: &list, hidden: true}, "secret-field",
+		"hidden custom field as name=value, stored encrypted (can be repeated)")
+}
+
+// createCustomFields возвращает пользовательские поля из флагов --field и --secret-field в порядке их указания.
+// Если поля не указаны, возвращается nil.
+func createCustomFields(cmd *cobra.Command) []models.CustomField {
+	flag := cmd.Flags().Lookup("field")
+	if flag == nil {
+		return nil
+	}
+	return *flag.Value.(customFieldsFlag).fields
+}
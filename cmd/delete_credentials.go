@@ -1,18 +1,15 @@
 package cmd
 
 import (
-	"fmt"
 	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
 	"github.com/ZnNr/GopherVault/internal/models"
 	"github.com/spf13/cobra"
-	"log"
-	"net/http"
 )
 
 // deleteCredentialsCmd представляет команду deleteCredentials
 var deleteCredentialsCmd = &cobra.Command{
 	Use:     "delete-credentials",
-	Short:   "Delete credentials for user from GopherVault storage",
+	Short:   "Move user's credentials to the trash",
 	Example: "GopherVault delete-credentials --user <user-name> --login <user-login>",
 	Run:     deleteCredentialsHandler,
 }
@@ -20,7 +17,8 @@ var deleteCredentialsCmd = &cobra.Command{
 func deleteCredentialsHandler(cmd *cobra.Command, args []string) {
 
 	cfg := cmdutil.LoadEnvVariables()
-	userName, login, _, _, _, _, _, _ := cmdutil.GetFlagsValues(cmd)
+	values := cmdutil.GetFlagsValues(cmd)
+	userName, login := values.UserName, values.Login
 
 	// Создаем объект модели Credentials для запроса
 	requestUserCredentials := models.Credentials{
@@ -29,22 +27,26 @@ func deleteCredentialsHandler(cmd *cobra.Command, args []string) {
 	// Добавляем логин, если он указан
 	if login != "" {
 		requestUserCredentials.Login = &login
+	} else {
+		confirmBulkDelete(cmd, "учетные данные")
+	}
+	if site, _ := cmd.Flags().GetString("site"); site != "" {
+		requestUserCredentials.Site = &site
 	}
 
+	requestUserCredentials.Folder, requestUserCredentials.Tags = getFolderAndTags(cmd)
 	body := cmdutil.ConvertToJSONRequestCredential(requestUserCredentials)
 
 	// Отправляем POST-запрос на удаление учетных данных
-	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/delete/credentials", cfg.ApplicationHost, cfg.ApplicationPort), body)
-	if err != nil {
-		log.Printf(err.Error())
-	}
-
-	cmdutil.HandleResponse(resp, http.StatusOK)
+	executeWriteRequest(cfg, userName, "/delete/credentials", body)
 }
 
 func init() {
 	rootCmd.AddCommand(deleteCredentialsCmd)
 	deleteCredentialsCmd.Flags().String("user", "", "user name")
 	deleteCredentialsCmd.Flags().String("login", "", "user login")
+	deleteCredentialsCmd.Flags().String("site", "", "site or service the credentials belong to")
+	addFolderTagFlags(deleteCredentialsCmd)
+	addBulkDeleteFlags(deleteCredentialsCmd)
 	deleteCredentialsCmd.MarkFlagRequired("user")
 }
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+)
+
+// deleteFolderCmd представляет команду delete-folder
+var deleteFolderCmd = &cobra.Command{
+	Use:     "delete-folder",
+	Short:   "Delete user's folder and its subfolders. Secrets are kept outside of folders.",
+	Example: "GopherVault delete-folder --user <user-name> --path work/projects",
+	Run:     deleteFolderHandler,
+}
+
+func deleteFolderHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+	path, _ := cmd.Flags().GetString("path")
+
+	requestFolder := models.Folder{
+		UserName: userName,
+	}
+	query := ""
+	if path != "" {
+		requestFolder.Path = &path
+	} else {
+		confirmBulkDelete(cmd, "папки")
+		query = "?all=true"
+	}
+	body := cmdutil.ConvertToJSONRequestFolder(requestFolder)
+
+	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/delete/folder%s", cfg.ApplicationHost, cfg.ApplicationPort, query), body)
+	if err != nil {
+		log.Printf(err.Error())
+	}
+
+	cmdutil.HandleResponse(resp, http.StatusOK)
+}
+
+func init() {
+	rootCmd.AddCommand(deleteFolderCmd)
+	deleteFolderCmd.Flags().String("user", "", "user name")
+	deleteFolderCmd.Flags().String("path", "", "folder path")
+	addBulkDeleteFlags(deleteFolderCmd)
+	deleteFolderCmd.MarkFlagRequired("user")
+}
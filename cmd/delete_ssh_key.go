@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+)
+
+// deleteSSHKeyCmd представляет команду delete-ssh-key
+var deleteSSHKeyCmd = &cobra.Command{
+	Use:     "delete-ssh-key",
+	Short:   "Delete user's SSH keys from GopherVault storage",
+	Example: "GopherVault delete-ssh-key --user <user-name> --name <key name>",
+	Run:     deleteSSHKeyHandler,
+}
+
+func deleteSSHKeyHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+	name, _ := cmd.Flags().GetString("name")
+
+	requestKey := models.SSHKey{
+		UserName: userName,
+	}
+	query := ""
+	if name != "" {
+		requestKey.Name = &name
+	} else {
+		confirmBulkDelete(cmd, "SSH-ключи")
+		query = "?all=true"
+	}
+	body := cmdutil.ConvertToJSONRequestSSHKey(requestKey)
+
+	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/delete/ssh-key%s", cfg.ApplicationHost, cfg.ApplicationPort, query), body)
+	if err != nil {
+		log.Printf(err.Error())
+	}
+
+	cmdutil.HandleResponse(resp, http.StatusOK)
+}
+
+func init() {
+	rootCmd.AddCommand(deleteSSHKeyCmd)
+	deleteSSHKeyCmd.Flags().String("user", "", "user name")
+	deleteSSHKeyCmd.Flags().String("name", "", "name of the key")
+	addBulkDeleteFlags(deleteSSHKeyCmd)
+	deleteSSHKeyCmd.MarkFlagRequired("user")
+}
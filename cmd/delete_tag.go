@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+)
+
+// deleteTagCmd представляет команду delete-tag
+var deleteTagCmd = &cobra.Command{
+	Use:     "delete-tag",
+	Short:   "Delete user's tag. Tagged secrets are kept in storage.",
+	Example: "GopherVault delete-tag --user <user-name> --name <tag>",
+	Run:     deleteTagHandler,
+}
+
+func deleteTagHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+	name, _ := cmd.Flags().GetString("name")
+
+	requestTag := models.Tag{
+		UserName: userName,
+	}
+	query := ""
+	if name != "" {
+		requestTag.Name = &name
+	} else {
+		confirmBulkDelete(cmd, "теги")
+		query = "?all=true"
+	}
+	body := cmdutil.ConvertToJSONRequestTag(requestTag)
+
+	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/delete/tag%s", cfg.ApplicationHost, cfg.ApplicationPort, query), body)
+	if err != nil {
+		log.Printf(err.Error())
+	}
+
+	cmdutil.HandleResponse(resp, http.StatusOK)
+}
+
+func init() {
+	rootCmd.AddCommand(deleteTagCmd)
+	deleteTagCmd.Flags().String("user", "", "user name")
+	deleteTagCmd.Flags().String("name", "", "tag name")
+	addBulkDeleteFlags(deleteTagCmd)
+	deleteTagCmd.MarkFlagRequired("user")
+}
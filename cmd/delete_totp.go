@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+)
+
+// deleteTOTPCmd представляет команду delete-totp
+var deleteTOTPCmd = &cobra.Command{
+	Use:     "delete-totp",
+	Short:   "Delete user's TOTP secrets from GopherVault storage",
+	Example: "GopherVault delete-totp --user <user-name> --name <secret name>",
+	Run:     deleteTOTPHandler,
+}
+
+func deleteTOTPHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+	name, _ := cmd.Flags().GetString("name")
+
+	requestTOTP := models.TOTP{
+		UserName: userName,
+	}
+	query := ""
+	if name != "" {
+		requestTOTP.Name = &name
+	} else {
+		confirmBulkDelete(cmd, "секреты TOTP")
+		query = "?all=true"
+	}
+	body := cmdutil.ConvertToJSONRequestTOTP(requestTOTP)
+
+	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/delete/totp%s", cfg.ApplicationHost, cfg.ApplicationPort, query), body)
+	if err != nil {
+		log.Printf(err.Error())
+	}
+
+	cmdutil.HandleResponse(resp, http.StatusOK)
+}
+
+func init() {
+	rootCmd.AddCommand(deleteTOTPCmd)
+	deleteTOTPCmd.Flags().String("user", "", "user name")
+	deleteTOTPCmd.Flags().String("name", "", "name of the secret")
+	addBulkDeleteFlags(deleteTOTPCmd)
+	deleteTOTPCmd.MarkFlagRequired("user")
+}
@@ -1,11 +1,8 @@
 package cmd
 
 import (
-	"fmt"
 	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
 	"github.com/ZnNr/GopherVault/internal/models"
-	"log"
-	"net/http"
 
 	"github.com/spf13/cobra"
 )
@@ -13,14 +10,15 @@ import (
 // deleteCardCmd представляет команду deleteCard.
 var deleteCardCmd = &cobra.Command{
 	Use:     "delete-card",
-	Short:   "Delete card info from GopherVault storage",
+	Short:   "Move user's cards to the trash",
 	Example: "GopherVault  delete-card --user user-name --bank alpha",
 	Run:     deleteCardHandler,
 }
 
 func deleteCardHandler(cmd *cobra.Command, args []string) {
 	cfg := cmdutil.LoadEnvVariables()
-	userName, bank, number, _, _, _, _, _ := cmdutil.GetFlagsValues(cmd)
+	values := cmdutil.GetFlagsValues(cmd)
+	userName, bank, number := values.UserName, values.Bank, values.Number
 
 	requestCard := models.Card{
 		UserName: userName,
@@ -31,14 +29,13 @@ func deleteCardHandler(cmd *cobra.Command, args []string) {
 	if number != "" {
 		requestCard.Number = &number
 	}
-	body := cmdutil.ConvertToJSONRequestCards(requestCard)
-
-	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/delete/card", cfg.ApplicationHost, cfg.ApplicationPort), body)
-	if err != nil {
-		log.Printf(err.Error())
+	if bank == "" && number == "" {
+		confirmBulkDelete(cmd, "карты")
 	}
+	requestCard.Folder, requestCard.Tags = getFolderAndTags(cmd)
+	body := cmdutil.ConvertToJSONRequestCards(requestCard)
 
-	cmdutil.HandleResponse(resp, http.StatusOK)
+	executeWriteRequest(cfg, userName, "/delete/card", body)
 }
 
 func init() {
@@ -46,5 +43,7 @@ func init() {
 	deleteCardCmd.Flags().String("user", "", "user name")
 	deleteCardCmd.Flags().String("bank", "", "bank")
 	deleteCardCmd.Flags().String("number", "", "card number")
+	addFolderTagFlags(deleteCardCmd)
+	addBulkDeleteFlags(deleteCardCmd)
 	deleteCardCmd.MarkFlagRequired("user")
 }
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/exporters"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/vaultfile"
+	"github.com/spf13/cobra"
+)
+
+// exportCmd представляет команду export
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export all user's secrets to an encrypted file or to another password manager",
+	Long: `Export folders, credentials, notes, cards, TOTP secrets and SSH keys of the user with their metadata
+into a single file encrypted with a key derived from the passphrase (Argon2id + AES-256-GCM).
+The passphrase never leaves the client; the file can be imported on another server with the import command.
+The passphrase is read from --passphrase-stdin or --passphrase-file, or asked twice in the terminal when omitted.
+The account password is asked again and the export is recorded in the server audit log.
+
+With --format other than gvx write credentials, notes and cards UNENCRYPTED for another password manager:
+  csv          Bitwarden CSV: credentials and notes only
+  json         unencrypted Bitwarden JSON
+  keepass-xml  KeePass 2.x XML
+The account password is asked again and the export is recorded in the server audit log.`,
+	Example: "GopherVault export --user <user-name> --out vault.gvx\n" +
+		"GopherVault export --user <user-name> --out vault.gvx --passphrase-file passphrase.txt\n" +
+		"GopherVault export --user <user-name> --out vault.json --format json",
+	Run: exportHandler,
+}
+
+func exportHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+	out, _ := cmd.Flags().GetString("out")
+	format, _ := cmd.Flags().GetString("format")
+	force, _ := cmd.Flags().GetBool("force")
+	if format != formatVaultFile {
+		if cmdutil.SecretFlagsChanged(cmd, "passphrase") {
+			log.Fatalln("флаги --passphrase поддерживаются только для формата gvx: остальные форматы не шифруются")
+		}
+		exportPlaintext(cfg, userName, out, exporters.Format(format), force)
+		return
+	}
+	passphrase := cmdutil.SecretValue(cmd, "passphrase", "Парольная фраза для шифрования выгрузки: ")
+	if passphrase == "" {
+		log.Fatalln("парольная фраза для шифрования выгрузки не должна быть пустой")
+	}
+
+	// Сервер выдает расшифрованные секреты только после повторной проверки пароля учетной записи
+	password := cmdutil.ReadPassword(fmt.Sprintf("Для подтверждения введите пароль учетной записи %q: ", userName))
+	body, err := json.Marshal(models.VaultExportRequest{UserName: userName, Password: password})
+	if err != nil {
+		log.Fatalf("ошибка при маршалинге запроса: %s", err)
+	}
+	resp, err := cmdutil.ExecutePostRequest(serverURL(cfg, "/export"), body)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+	if resp.StatusCode() != http.StatusOK {
+		cmdutil.HandleResponse(resp, http.StatusOK)
+		os.Exit(1)
+	}
+	var vault models.Vault
+	if err = json.Unmarshal(resp.Body(), &vault); err != nil {
+		log.Fatalf("некорректный ответ сервера: %s", err)
+	}
+
+	sealed, err := vaultfile.Seal(vault, passphrase)
+	if err != nil {
+		log.Fatalf("ошибка при шифровании выгрузки: %s", err)
+	}
+	writeExportFile(out, sealed, force)
+	log.Printf("секреты пользователя %q выгружены в %s: папок %d, учетных данных %d, заметок %d, карт %d, секретов TOTP %d, SSH-ключей %d",
+		userName, out, len(vault.Folders), len(vault.Credentials), len(vault.Notes), len(vault.Cards), len(vault.TOTP), len(vault.SSHKeys))
+}
+
+// plaintextWarning предупреждение о выгрузке секретов в открытом виде
+const plaintextWarning = `
+!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!
+!!  ВНИМАНИЕ: пароли, заметки и данные карт будут записаны в файл            !!
+!!  В ОТКРЫТОМ ВИДЕ, без шифрования. Любой, кто получит доступ к файлу,      !!
+!!  его копиям или резервным копиям диска, прочитает все секреты.            !!
+!!  Загрузите файл в другой менеджер паролей и сразу удалите его.            !!
+!!  Выгрузка будет записана в журнал аудита сервера.                         !!
+!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!
+`
+
+// exportPlaintext выгружает учетные данные, заметки и карты в открытом виде для переноса в другой менеджер паролей.
+// Перед выгрузкой пароль учетной записи запрашивается повторно.
+func exportPlaintext(cfg models.Params, userName, out string, format exporters.Format, force bool) {
+	if err := format.Valid(); err != nil {
+		log.Fatalln(err.Error())
+	}
+	fmt.Fprint(os.Stderr, plaintextWarning)
+	password := cmdutil.ReadPassword(fmt.Sprintf("Для подтверждения введите пароль учетной записи %q: ", userName))
+
+	body, err := json.Marshal(models.PlaintextExportRequest{UserName: userName, Password: password, Format: string(format)})
+	if err != nil {
+		log.Fatalf("ошибка при маршалинге запроса: %s", err)
+	}
+	resp, err := cmdutil.ExecutePostRequest(serverURL(cfg, "/export/plaintext"), body)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+	if resp.StatusCode() != http.StatusOK {
+		cmdutil.HandleResponse(resp, http.StatusOK)
+		os.Exit(1)
+	}
+	var vault models.Vault
+	if err = json.Unmarshal(resp.Body(), &vault); err != nil {
+		log.Fatalf("некорректный ответ сервера: %s", err)
+	}
+
+	data, err := exporters.Encode(format, vault)
+	if err != nil {
+		log.Fatalf("ошибка при записи выгрузки в формате %s: %s", format, err)
+	}
+	writeExportFile(out, data, force)
+	cards := len(vault.Cards)
+	if !format.SupportsCards() && cards > 0 {
+		log.Printf("карты не поддерживаются форматом %s и не выгружены (%d); используйте json или keepass-xml", format, cards)
+		cards = 0
+	}
+	if len(vault.TOTP) > 0 || len(vault.SSHKeys) > 0 {
+		log.Printf("секреты TOTP (%d) и SSH-ключи (%d) в открытую выгрузку не попадают", len(vault.TOTP), len(vault.SSHKeys))
+	}
+	log.Printf("секреты пользователя %q выгружены в открытом виде в %s: учетных данных %d, заметок %d, карт %d; удалите файл после переноса",
+		userName, out, len(vault.Credentials), len(vault.Notes), cards)
+}
+
+// writeExportFile записывает файл выгрузки с правами только для владельца.
+// Существующий файл перезаписывается только с флагом --force.
+func writeExportFile(out string, data []byte, force bool) {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	file, err := os.OpenFile(out, flags, 0o600)
+	if err != nil {
+		log.Fatalf("ошибка при создании файла выгрузки: %s", err)
+	}
+	if _, err = file.Write(data); err != nil {
+		file.Close()
+		log.Fatalf("ошибка при записи файла выгрузки: %s", err)
+	}
+	if err = file.Close(); err != nil {
+		log.Fatalf("ошибка при записи файла выгрузки: %s", err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().String("user", "", "user name")
+	exportCmd.Flags().String("out", "", "path of the export file")
+	exportCmd.Flags().String("format", formatVaultFile, "file format: gvx (encrypted), csv, json or keepass-xml (unencrypted)")
+	cmdutil.AddSecretFlags(exportCmd, "passphrase", "passphrase the export file is encrypted with (gvx format only)", "Повторите парольную фразу: ")
+	exportCmd.Flags().Bool("force", false, "overwrite the export file if it exists")
+	exportCmd.MarkFlagRequired("user")
+	exportCmd.MarkFlagRequired("out")
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// addFolderTagFlags добавляет команде флаги --folder и --tag
+func addFolderTagFlags(cmd *cobra.Command) {
+	cmd.Flags().String("folder", "", "folder path, e.g. work/projects")
+	cmd.Flags().StringArray("tag", nil, "tag (can be repeated)")
+}
+
+// getFolderAndTags возвращает значения флагов --folder и --tag. Если папка не указана, возвращается nil.
+func getFolderAndTags(cmd *cobra.Command) (*string, []string) {
+	folder, _ := cmd.Flags().GetString("folder")
+	tags, _ := cmd.Flags().GetStringArray("tag")
+	if folder == "" {
+		return nil, tags
+	}
+	return &folder, tags
+}
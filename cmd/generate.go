@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/spf13/cobra"
+)
+
+// generateCmd представляет команду generate
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate a password or a passphrase",
+	Long: `Generate a password with a cryptographically secure random generator:
+  random         random characters of the chosen classes, every class is present
+  diceware       words from the bundled list of 1296 words
+  pronounceable  alternating consonants and vowels
+Omitted length and number of words are taken from the password policy
+(KEEPER_PASSWORD_MIN_LENGTH, KEEPER_PASSPHRASE_MIN_WORDS); weaker options are rejected.
+The password is printed to stdout, its entropy to stderr.`,
+	Example: "GopherVault generate --length 24 --exclude-ambiguous\n" +
+		"GopherVault generate --mode diceware --words 6 --separator ' '",
+	Run: generateHandler,
+}
+
+func generateHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	count, _ := cmd.Flags().GetInt("count")
+	for i := 0; i < count; i++ {
+		result := generatePassword(cmd, cfg)
+		fmt.Println(result.Password)
+		if i == count-1 {
+			fmt.Fprintf(os.Stderr, "режим %s, энтропия %.1f бит\n", result.Mode, result.Entropy)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(generateCmd)
+	addGeneratorFlags(generateCmd)
+	generateCmd.Flags().Int("count", 1, "number of passwords to generate")
+}
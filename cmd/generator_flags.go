@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/passgen"
+	"github.com/spf13/cobra"
+)
+
+// addGeneratorFlags добавляет команде флаги параметров генератора паролей
+func addGeneratorFlags(cmd *cobra.Command) {
+	cmd.Flags().String("mode", string(passgen.ModeRandom), "generator mode: random, diceware or pronounceable")
+	cmd.Flags().Int("length", 0, "password length for random and pronounceable modes, 0 uses the policy default")
+	cmd.Flags().Int("words", 0, "number of diceware words, 0 uses the policy default")
+	cmd.Flags().String("separator", "-", "separator between diceware words")
+	cmd.Flags().StringSlice("classes", nil, "character classes for random mode: lower,upper,digits,symbols (default all)")
+	cmd.Flags().Bool("exclude-ambiguous", false, "exclude characters that are easy to confuse, such as l, 1, O and 0")
+}
+
+// generatePassword генерирует пароль по флагам команды и политике паролей из переменных окружения
+func generatePassword(cmd *cobra.Command, cfg models.Params) passgen.Result {
+	mode, _ := cmd.Flags().GetString("mode")
+	length, _ := cmd.Flags().GetInt("length")
+	words, _ := cmd.Flags().GetInt("words")
+	separator, _ := cmd.Flags().GetString("separator")
+	classes, _ := cmd.Flags().GetStringSlice("classes")
+	excludeAmbiguous, _ := cmd.Flags().GetBool("exclude-ambiguous")
+
+	opts := passgen.Options{Mode: passgen.Mode(mode), Length: length, Words: words, ExcludeAmbiguous: excludeAmbiguous}
+	if cmd.Flags().Changed("separator") {
+		opts.Separator = &separator
+	}
+	for _, c := range classes {
+		opts.Classes = append(opts.Classes, passgen.Class(c))
+	}
+
+	policy := passgen.NewPolicy(cfg.PasswordMinLength, cfg.PassphraseMinWords)
+	opts, err := policy.Apply(opts)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+	result, err := passgen.Generate(opts)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+	return result
+}
+
+// passwordOrGenerated возвращает введенный пароль или сгенерированный с флагом --generate.
+// Сгенерированный пароль выводится, чтобы пользователь мог сразу им воспользоваться.
+func passwordOrGenerated(cmd *cobra.Command, cfg models.Params, password string) string {
+	generate, _ := cmd.Flags().GetBool("generate")
+	if !generate {
+		if password == "" {
+			log.Fatalln("пароль не должен быть пустым, введите его или сгенерируйте флагом --generate")
+		}
+		return password
+	}
+	if password != "" {
+		log.Fatalln("флаг --generate нельзя использовать вместе с --password, --password-stdin и --password-file")
+	}
+	result := generatePassword(cmd, cfg)
+	fmt.Fprintf(os.Stderr, "сгенерирован пароль (%s, энтропия %.1f бит):\n", result.Mode, result.Entropy)
+	fmt.Println(result.Password)
+	return result.Password
+}
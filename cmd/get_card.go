@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/localcache"
 	"github.com/ZnNr/GopherVault/internal/models"
 	"log"
 	"net/http"
@@ -20,7 +21,8 @@ var getCardCmd = &cobra.Command{
 
 func getCardHandler(cmd *cobra.Command, args []string) {
 	cfg := cmdutil.LoadEnvVariables()
-	userName, bank, number, _, _, _, _, _ := cmdutil.GetFlagsValues(cmd)
+	values := cmdutil.GetFlagsValues(cmd)
+	userName, bank, number := values.UserName, values.Bank, values.Number
 
 	requestCard := models.Card{
 		UserName: userName,
@@ -31,10 +33,14 @@ func getCardHandler(cmd *cobra.Command, args []string) {
 	if number != "" {
 		requestCard.Number = &number
 	}
+	requestCard.Folder, requestCard.Tags = getFolderAndTags(cmd)
 	body := cmdutil.ConvertToJSONRequestCards(requestCard)
 
-	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/get/card", cfg.ApplicationHost, cfg.ApplicationPort), body)
+	resp, err := executeListRequest(cmd, fmt.Sprintf("http://%s:%s/get/card", cfg.ApplicationHost, cfg.ApplicationPort), body)
 	if err != nil {
+		if serveFromCache(cfg, userName, func(cache *localcache.Cache) []models.Card { return cache.Cards(requestCard) }) {
+			return
+		}
 		log.Printf(err.Error())
 	}
 
@@ -46,5 +52,7 @@ func init() {
 	getCardCmd.Flags().String("user", "", "user name")
 	getCardCmd.Flags().String("bank", "", "bank")
 	getCardCmd.Flags().String("number", "", "number")
+	addFolderTagFlags(getCardCmd)
+	addListFlags(getCardCmd)
 	getCardCmd.MarkFlagRequired("user")
 }
@@ -3,10 +3,14 @@ package cmd
 import (
 	"fmt"
 	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/localcache"
 	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/urlmatch"
+	"github.com/go-resty/resty/v2"
 	"github.com/spf13/cobra"
 	"log"
 	"net/http"
+	"net/url"
 )
 
 // getCredentialsCmd представляет команду get-credentials
@@ -15,13 +19,14 @@ var getCredentialsCmd = &cobra.Command{
 	Short: "Get a pair of login/password for specified user",
 	Long: `Get a pair of login/password for specified user from GopherVault storage. 
 Only authorized users can use this command`,
-	Example: "GopherVault get-credentials --user user_name",
+	Example: "GopherVault get-credentials --user user_name --url https://mail.example.com",
 	Run:     getCredentialsHandler,
 }
 
 func getCredentialsHandler(cmd *cobra.Command, args []string) {
 	cfg := cmdutil.LoadEnvVariables()
-	userName, userLogin, _, _, _, _, _, _ := cmdutil.GetFlagsValues(cmd)
+	values := cmdutil.GetFlagsValues(cmd)
+	userName, userLogin := values.UserName, values.Login
 
 	requestUserCredentials := models.Credentials{
 		UserName: userName,
@@ -29,19 +34,63 @@ func getCredentialsHandler(cmd *cobra.Command, args []string) {
 	if userLogin != "" {
 		requestUserCredentials.Login = &userLogin
 	}
+	if site, _ := cmd.Flags().GetString("site"); site != "" {
+		requestUserCredentials.Site = &site
+	}
+	requestUserCredentials.Folder, requestUserCredentials.Tags = getFolderAndTags(cmd)
 	body := cmdutil.ConvertToJSONRequestCredential(requestUserCredentials)
 
-	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/get/credentials", cfg.ApplicationHost, cfg.ApplicationPort), body)
+	// Если указан адрес сайта, запрашиваем наиболее подходящие для него учетные данные.
+	// Сервер возвращает одну запись, поэтому флаги постраничного получения списка с --url не используются.
+	var (
+		resp *resty.Response
+		err  error
+	)
+	siteURL, _ := cmd.Flags().GetString("url")
+	if siteURL != "" {
+		if name := changedListFlag(cmd); name != "" {
+			log.Fatalf("флаг --%s нельзя использовать вместе с --url", name)
+		}
+		resp, err = cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/credentials/match?url=%s", cfg.ApplicationHost, cfg.ApplicationPort, url.QueryEscape(siteURL)), body)
+	} else {
+		resp, err = executeListRequest(cmd, fmt.Sprintf("http://%s:%s/get/credentials", cfg.ApplicationHost, cfg.ApplicationPort), body)
+	}
 	if err != nil {
+		if serveFromCache(cfg, userName, func(cache *localcache.Cache) []models.Credentials {
+			return cachedCredentials(cache, requestUserCredentials, siteURL)
+		}) {
+			return
+		}
 		log.Printf(err.Error())
 	}
 
 	cmdutil.HandleResponse(resp, http.StatusOK)
 }
 
+// cachedCredentials отбирает учетные данные из локального кэша так же, как это делает сервер:
+// при указанном адресе сайта среди всех учетных данных выбираются наиболее подходящие для него
+func cachedCredentials(cache *localcache.Cache, filter models.Credentials, siteURL string) []models.Credentials {
+	if siteURL == "" {
+		return cache.Credentials(filter)
+	}
+	best, ok, err := urlmatch.Best(cache.Credentials(models.Credentials{}), siteURL)
+	if err != nil {
+		log.Println(err.Error())
+		return nil
+	}
+	if ok {
+		return []models.Credentials{best}
+	}
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(getCredentialsCmd)
 	getCredentialsCmd.Flags().String("user", "", "user name")
 	getCredentialsCmd.Flags().String("login", "", "user login")
+	getCredentialsCmd.Flags().String("site", "", "site or service the credentials belong to")
+	getCredentialsCmd.Flags().String("url", "", "return the credentials that best match the site URL")
+	addFolderTagFlags(getCredentialsCmd)
+	addListFlags(getCredentialsCmd)
 	getCredentialsCmd.MarkFlagRequired("user")
 }
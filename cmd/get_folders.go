@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+)
+
+// getFoldersCmd представляет команду get-folders
+var getFoldersCmd = &cobra.Command{
+	Use:     "get-folders",
+	Short:   "Get user's folders from GopherVault",
+	Example: "GopherVault get-folders --user <user-name> --path work",
+	Run:     getFoldersHandler,
+}
+
+func getFoldersHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+	path, _ := cmd.Flags().GetString("path")
+
+	requestFolder := models.Folder{
+		UserName: userName,
+	}
+	if path != "" {
+		requestFolder.Path = &path
+	}
+	body := cmdutil.ConvertToJSONRequestFolder(requestFolder)
+
+	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/get/folder", cfg.ApplicationHost, cfg.ApplicationPort), body)
+	if err != nil {
+		log.Printf(err.Error())
+	}
+
+	cmdutil.HandleResponse(resp, http.StatusOK)
+}
+
+func init() {
+	rootCmd.AddCommand(getFoldersCmd)
+	getFoldersCmd.Flags().String("user", "", "user name")
+	getFoldersCmd.Flags().String("path", "", "folder path, subfolders are included")
+	getFoldersCmd.MarkFlagRequired("user")
+}
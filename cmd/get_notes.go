@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/localcache"
 	"github.com/ZnNr/GopherVault/internal/models"
 	"github.com/spf13/cobra"
 	"log"
@@ -19,7 +20,8 @@ var getNotesCmd = &cobra.Command{
 
 func getNotesHandler(cmd *cobra.Command, args []string) {
 	cfg := cmdutil.LoadEnvVariables()
-	userName, title, _, _, _, _, _, _ := cmdutil.GetFlagsValues(cmd)
+	values := cmdutil.GetFlagsValues(cmd)
+	userName, title := values.UserName, values.Title
 
 	requestNotes := models.Note{
 		UserName: userName,
@@ -27,10 +29,14 @@ func getNotesHandler(cmd *cobra.Command, args []string) {
 	if title != "" {
 		requestNotes.Title = &title
 	}
+	requestNotes.Folder, requestNotes.Tags = getFolderAndTags(cmd)
 	body := cmdutil.ConvertToJSONRequestNotes(requestNotes)
 
-	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/get/note", cfg.ApplicationHost, cfg.ApplicationPort), body)
+	resp, err := executeListRequest(cmd, fmt.Sprintf("http://%s:%s/get/note", cfg.ApplicationHost, cfg.ApplicationPort), body)
 	if err != nil {
+		if serveFromCache(cfg, userName, func(cache *localcache.Cache) []models.Note { return cache.Notes(requestNotes) }) {
+			return
+		}
 		log.Printf(err.Error())
 	}
 
@@ -41,5 +47,7 @@ func init() {
 	rootCmd.AddCommand(getNotesCmd)
 	getNotesCmd.Flags().String("user", "", "user name")
 	getNotesCmd.Flags().String("title", "", "title of the note")
+	addFolderTagFlags(getNotesCmd)
+	addListFlags(getNotesCmd)
 	getNotesCmd.MarkFlagRequired("user")
 }
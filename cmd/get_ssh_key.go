@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+)
+
+// getSSHKeyCmd представляет команду get-ssh-key
+var getSSHKeyCmd = &cobra.Command{
+	Use:     "get-ssh-key",
+	Short:   "Get user's SSH keys from GopherVault",
+	Example: "GopherVault get-ssh-key --user <user-name> --name <key name>",
+	Run:     getSSHKeyHandler,
+}
+
+func getSSHKeyHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+	name, _ := cmd.Flags().GetString("name")
+
+	requestKey := models.SSHKey{
+		UserName: userName,
+	}
+	if name != "" {
+		requestKey.Name = &name
+	}
+	body := cmdutil.ConvertToJSONRequestSSHKey(requestKey)
+
+	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/get/ssh-key", cfg.ApplicationHost, cfg.ApplicationPort), body)
+	if err != nil {
+		log.Printf(err.Error())
+	}
+
+	cmdutil.HandleResponse(resp, http.StatusOK)
+}
+
+func init() {
+	rootCmd.AddCommand(getSSHKeyCmd)
+	getSSHKeyCmd.Flags().String("user", "", "user name")
+	getSSHKeyCmd.Flags().String("name", "", "name of the key")
+	getSSHKeyCmd.MarkFlagRequired("user")
+}
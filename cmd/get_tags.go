@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+)
+
+// getTagsCmd представляет команду get-tags
+var getTagsCmd = &cobra.Command{
+	Use:     "get-tags",
+	Short:   "Get user's tags from GopherVault",
+	Example: "GopherVault get-tags --user <user-name>",
+	Run:     getTagsHandler,
+}
+
+func getTagsHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+	name, _ := cmd.Flags().GetString("name")
+
+	requestTag := models.Tag{
+		UserName: userName,
+	}
+	if name != "" {
+		requestTag.Name = &name
+	}
+	body := cmdutil.ConvertToJSONRequestTag(requestTag)
+
+	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/get/tag", cfg.ApplicationHost, cfg.ApplicationPort), body)
+	if err != nil {
+		log.Printf(err.Error())
+	}
+
+	cmdutil.HandleResponse(resp, http.StatusOK)
+}
+
+func init() {
+	rootCmd.AddCommand(getTagsCmd)
+	getTagsCmd.Flags().String("user", "", "user name")
+	getTagsCmd.Flags().String("name", "", "tag name")
+	getTagsCmd.MarkFlagRequired("user")
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+)
+
+// getTOTPCmd представляет команду get-totp
+var getTOTPCmd = &cobra.Command{
+	Use:     "get-totp",
+	Short:   "Get user's TOTP secrets from GopherVault",
+	Example: "GopherVault get-totp --user <user-name> --name <secret name>",
+	Run:     getTOTPHandler,
+}
+
+func getTOTPHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+	name, _ := cmd.Flags().GetString("name")
+
+	requestTOTP := models.TOTP{
+		UserName: userName,
+	}
+	if name != "" {
+		requestTOTP.Name = &name
+	}
+	body := cmdutil.ConvertToJSONRequestTOTP(requestTOTP)
+
+	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/get/totp", cfg.ApplicationHost, cfg.ApplicationPort), body)
+	if err != nil {
+		log.Printf(err.Error())
+	}
+
+	cmdutil.HandleResponse(resp, http.StatusOK)
+}
+
+func init() {
+	rootCmd.AddCommand(getTOTPCmd)
+	getTOTPCmd.Flags().String("user", "", "user name")
+	getTOTPCmd.Flags().String("name", "", "name of the secret")
+	getTOTPCmd.MarkFlagRequired("user")
+}
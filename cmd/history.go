@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// historyCmd представляет команду history
+var historyCmd = &cobra.Command{
+	Use:     "history",
+	Short:   "Show previous versions of user's credentials or note",
+	Example: "GopherVault history --user <user-name> --type note --id 4 [--version 2]",
+	Run:     historyHandler,
+}
+
+func historyHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+	secretType, _ := cmd.Flags().GetString("type")
+	id, _ := cmd.Flags().GetInt64("id")
+	version, _ := cmd.Flags().GetInt64("version")
+
+	body := cmdutil.ConvertToJSONRequestHistory(models.HistoryRequest{UserName: userName, Version: version})
+	resp, err := cmdutil.ExecuteGetRequest(fmt.Sprintf("http://%s:%s/history/%s/%d", cfg.ApplicationHost, cfg.ApplicationPort, secretType, id), body)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+	if resp.StatusCode() != http.StatusOK {
+		cmdutil.HandleResponse(resp, http.StatusOK)
+		return
+	}
+	var versions []models.SecretVersion
+	if err = json.Unmarshal(resp.Body(), &versions); err != nil {
+		log.Fatalf("некорректный ответ сервера: %s", resp.String())
+	}
+
+	// Выводим, кто и когда изменил секрет и какие поля были изменены
+	for _, v := range versions {
+		fmt.Printf("версия %d\t%s\t%s\t%s\n", v.Version, v.ChangedAt.Local().Format(time.DateTime), v.ChangedBy, strings.Join(v.Changes, ", "))
+	}
+
+	// Для конкретной версии выводим и ее содержимое
+	if version != 0 {
+		snapshot, err := json.MarshalIndent(versions[0], "", "  ")
+		if err != nil {
+			log.Fatalln(err.Error())
+		}
+		fmt.Println(string(snapshot))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.Flags().String("user", "", "user name")
+	historyCmd.Flags().String("type", "", "secret type: credentials or note")
+	historyCmd.Flags().Int64("id", 0, "secret id")
+	historyCmd.Flags().Int64("version", 0, "show the contents of this version")
+	historyCmd.MarkFlagRequired("user")
+	historyCmd.MarkFlagRequired("type")
+	historyCmd.MarkFlagRequired("id")
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/importers"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/vault"
+	"github.com/ZnNr/GopherVault/internal/vaultfile"
+	"github.com/spf13/cobra"
+)
+
+// formatVaultFile формат зашифрованного файла, созданного командой export
+const formatVaultFile = "gvx"
+
+// defaultImportBatchSize число секретов в одном пакете при переносе из другого менеджера паролей
+const defaultImportBatchSize = 500
+
+// importCmd представляет команду import
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import user's secrets from an encrypted export file or another password manager",
+	Long: `Import folders, credentials, notes, cards, TOTP secrets and SSH keys from a file created by the export command.
+Modes:
+  skip-duplicates  keep secrets that already exist (default)
+  merge            replace existing secrets with the imported ones
+  replace          delete all user's secrets before the import: credentials, notes and cards are moved to the trash,
+                   TOTP secrets and SSH keys are deleted permanently; requires --yes
+The passphrase is read from --passphrase-stdin or --passphrase-file, or asked in the terminal when omitted.
+
+With --format other than gvx import credentials, notes and cards from another password manager:
+  bitwarden-json   unencrypted Bitwarden JSON export
+  keepass-xml      KeePass 2.x XML export
+  1password-1pux   1Password 1PUX export
+  chrome-csv       Chrome passwords CSV export
+  generic-csv      CSV with a header row; columns are matched by common names or --map field=Column,
+                   fields are name, url, login, password, notes, folder and tags
+Secrets that already exist (same login and site, note title or card number) are skipped. Each batch of --batch-size
+secrets is saved in one transaction. Use --dry-run to print the report without saving anything.`,
+	Example: "GopherVault import --user <user-name> --in vault.gvx [--passphrase-file passphrase.txt] [--mode merge]\n" +
+		"GopherVault import --user <user-name> --in bitwarden.json --format bitwarden-json [--dry-run]\n" +
+		"GopherVault import --user <user-name> --in passwords.csv --format generic-csv --map login=E-mail --map url=\"Web Site\"",
+	Run: importHandler,
+}
+
+func importHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+	in, _ := cmd.Flags().GetString("in")
+	format, _ := cmd.Flags().GetString("format")
+	if format != formatVaultFile {
+		importFromManager(cmd, cfg, userName, in, importers.Format(format))
+		return
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		log.Fatalln("флаг --dry-run поддерживается только при переносе из другого менеджера паролей")
+	}
+	passphrase := cmdutil.SecretValue(cmd, "passphrase", "Парольная фраза файла выгрузки: ")
+	if passphrase == "" {
+		log.Fatalln("парольная фраза файла выгрузки не должна быть пустой")
+	}
+	mode, _ := cmd.Flags().GetString("mode")
+
+	switch models.ImportMode(mode) {
+	case models.ImportSkipDuplicates, models.ImportMerge:
+	case models.ImportReplace:
+		if yes, _ := cmd.Flags().GetBool("yes"); !yes {
+			log.Fatalln("все секреты пользователя будут удалены перед загрузкой; для подтверждения укажите флаг --yes")
+		}
+	default:
+		log.Fatalf("неизвестный режим загрузки %q: используйте skip-duplicates, merge или replace", mode)
+	}
+
+	data, err := os.ReadFile(in)
+	if err != nil {
+		log.Fatalf("ошибка при чтении файла выгрузки: %s", err)
+	}
+	vault, err := vaultfile.Open(data, passphrase)
+	if err != nil {
+		log.Fatalf("ошибка при расшифровке файла выгрузки: %s", err)
+	}
+
+	body, err := json.Marshal(models.VaultImport{UserName: userName, Mode: models.ImportMode(mode), Vault: vault})
+	if err != nil {
+		log.Fatalf("ошибка при маршалинге запроса: %s", err)
+	}
+	resp, err := cmdutil.ExecutePostRequest(serverURL(cfg, "/import"), body)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+	if resp.StatusCode() != http.StatusOK {
+		cmdutil.HandleResponse(resp, http.StatusOK)
+		os.Exit(1)
+	}
+	var res models.ImportResult
+	if err = json.Unmarshal(resp.Body(), &res); err != nil {
+		log.Fatalf("некорректный ответ сервера: %s", err)
+	}
+	log.Printf("секреты из %s загружены для пользователя %q: создано %d, заменено %d, пропущено %d",
+		in, userName, res.Created, res.Updated, res.Skipped)
+}
+
+// importFromManager переносит учетные данные, заметки и карты из файла выгрузки другого менеджера паролей.
+// Файл разбирается на клиенте, секреты отправляются на сервер пакетами, каждый пакет сохраняется в одной транзакции.
+func importFromManager(cmd *cobra.Command, cfg models.Params, userName, in string, format importers.Format) {
+	if cmd.Flags().Changed("mode") || cmd.Flags().Changed("yes") {
+		log.Fatalln("флаги --mode и --yes поддерживаются только для файлов формата gvx; существующие секреты всегда пропускаются")
+	}
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	batchSize, _ := cmd.Flags().GetInt("batch-size")
+	if batchSize < 1 || batchSize > vault.MaxBatchSize {
+		log.Fatalf("размер пакета должен быть от 1 до %d", vault.MaxBatchSize)
+	}
+	specs, _ := cmd.Flags().GetStringArray("map")
+	mapping := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		field, column, ok := strings.Cut(spec, "=")
+		if !ok || field == "" || column == "" {
+			log.Fatalf("некорректное сопоставление столбца %q: используйте поле=Столбец", spec)
+		}
+		mapping[field] = column
+	}
+
+	data, err := os.ReadFile(in)
+	if err != nil {
+		log.Fatalf("ошибка при чтении файла выгрузки: %s", err)
+	}
+	parsed, err := importers.Parse(format, data, importers.Options{Mapping: mapping})
+	if err != nil {
+		log.Fatalf("ошибка при разборе файла выгрузки: %s", err)
+	}
+
+	total := models.ImportResult{Skipped: len(parsed.Duplicates), Report: parsed.Duplicates}
+	for _, batch := range splitImportBatches(parsed, batchSize) {
+		batch.UserName, batch.DryRun = userName, dryRun
+		body, err := json.Marshal(batch)
+		if err != nil {
+			log.Fatalf("ошибка при маршалинге запроса: %s", err)
+		}
+		resp, err := cmdutil.ExecutePostRequest(serverURL(cfg, "/import/batch"), body)
+		if err != nil {
+			log.Fatalln(err.Error())
+		}
+		if resp.StatusCode() != http.StatusOK {
+			cmdutil.HandleResponse(resp, http.StatusOK)
+			if !dryRun && total.Created > 0 {
+				log.Printf("пакет не сохранен; до ошибки создано секретов: %d", total.Created)
+			}
+			os.Exit(1)
+		}
+		var res models.ImportResult
+		if err = json.Unmarshal(resp.Body(), &res); err != nil {
+			log.Fatalf("некорректный ответ сервера: %s", err)
+		}
+		total.Created += res.Created
+		total.Skipped += res.Skipped
+		total.Report = append(total.Report, res.Report...)
+	}
+
+	for _, item := range total.Report {
+		fmt.Printf("%-8s %-12s %s\n", item.Action, item.Type, item.Name)
+	}
+	for _, name := range parsed.Unsupported {
+		log.Printf("запись %q не перенесена: тип записи не поддерживается", name)
+	}
+	if dryRun {
+		log.Printf("проверка %s завершена, секреты не сохранены: будет создано %d, пропущено %d",
+			in, total.Created, total.Skipped)
+		return
+	}
+	log.Printf("секреты из %s перенесены для пользователя %q: создано %d, пропущено %d",
+		in, userName, total.Created, total.Skipped)
+}
+
+// splitImportBatches разбивает секреты из файла выгрузки на пакеты не больше size секретов
+func splitImportBatches(parsed importers.Result, size int) []models.ImportBatch {
+	var (
+		batches []models.ImportBatch
+		batch   models.ImportBatch
+		count   int
+	)
+	// next начинает новый пакет, если текущий заполнен
+	next := func() {
+		if count == size {
+			batches = append(batches, batch)
+			batch, count = models.ImportBatch{}, 0
+		}
+		count++
+	}
+	for _, creds := range parsed.Credentials {
+		next()
+		batch.Credentials = append(batch.Credentials, creds)
+	}
+	for _, note := range parsed.Notes {
+		next()
+		batch.Notes = append(batch.Notes, note)
+	}
+	for _, card := range parsed.Cards {
+		next()
+		batch.Cards = append(batch.Cards, card)
+	}
+	if count > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().String("user", "", "user name")
+	importCmd.Flags().String("in", "", "path of the file to import")
+	importCmd.Flags().String("format", formatVaultFile, "file format: gvx, bitwarden-json, keepass-xml, 1password-1pux, chrome-csv or generic-csv")
+	cmdutil.AddSecretFlags(importCmd, "passphrase", "passphrase the export file is encrypted with (gvx format only)", "")
+	importCmd.Flags().String("mode", string(models.ImportSkipDuplicates), "import mode: skip-duplicates, merge or replace")
+	importCmd.Flags().Bool("yes", false, "confirm the deletion of all secrets in the replace mode")
+	importCmd.Flags().Bool("dry-run", false, "print the import report without saving secrets")
+	importCmd.Flags().Int("batch-size", defaultImportBatchSize, "number of secrets saved in one transaction")
+	importCmd.Flags().StringArray("map", nil, "generic-csv column for a field as field=Column (can be repeated)")
+	importCmd.MarkFlagRequired("user")
+	importCmd.MarkFlagRequired("in")
+}
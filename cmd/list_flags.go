@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/go-resty/resty/v2"
+	"github.com/spf13/cobra"
+)
+
+// listFlags флаги постраничного получения списка
+var listFlags = []string{"limit", "page", "sort", "desc", "unused-days", "fields"}
+
+// changedListFlag возвращает первый указанный флаг постраничного получения списка или пустую строку
+func changedListFlag(cmd *cobra.Command) string {
+	for _, name := range listFlags {
+		if cmd.Flags().Changed(name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// addListFlags добавляет команде флаги постраничного получения списка --limit, --page, --sort, --desc, --unused-days и --fields
+func addListFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("limit", 0, "page size, 0 returns all items")
+	cmd.Flags().Int("page", 1, "page number starting from 1, requires --limit")
+	cmd.Flags().String("sort", "", "sort by name, created or updated")
+	cmd.Flags().Bool("desc", false, "sort in descending order")
+	cmd.Flags().Int("unused-days", 0, "only items not read for the given number of days")
+	cmd.Flags().StringSlice("fields", nil, "fields to return besides the key ones, e.g. metadata,tags; secret fields not listed are not decrypted")
+}
+
+// executeListRequest запрашивает страницу списка секретов, указанную флагом --page.
+// Сервер отдает страницы по курсору, поэтому предыдущие страницы запрашиваются последовательно.
+// При некорректных флагах или отсутствии запрошенной страницы команда завершается с ошибкой.
+func executeListRequest(cmd *cobra.Command, url string, body []byte) (*resty.Response, error) {
+	limit, _ := cmd.Flags().GetInt("limit")
+	page, _ := cmd.Flags().GetInt("page")
+	sort, _ := cmd.Flags().GetString("sort")
+	desc, _ := cmd.Flags().GetBool("desc")
+	unusedDays, _ := cmd.Flags().GetInt("unused-days")
+	projection, _ := cmd.Flags().GetStringSlice("fields")
+	if page < 1 || (page > 1 && limit == 0) {
+		log.Fatalln("номер страницы должен быть не меньше 1, а для страниц после первой нужен --limit")
+	}
+
+	opts := models.ListOptions{Limit: limit, Sort: sort, Desc: desc, UnusedDays: unusedDays, Projection: projection}
+	for p := 1; ; p++ {
+		pageBody, err := withListOptions(body, opts)
+		if err != nil {
+			log.Fatalln(err.Error())
+		}
+		resp, err := cmdutil.ExecutePostRequest(url, pageBody)
+		if err != nil || p == page || resp.StatusCode() != http.StatusOK {
+			return resp, err
+		}
+		if opts.Cursor = resp.Header().Get(models.NextCursorHeader); opts.Cursor == "" {
+			log.Fatalf("страницы %d нет, всего страниц: %d", page, p)
+		}
+	}
+}
+
+// withListOptions добавляет параметры постраничного получения в тело запроса
+func withListOptions(body []byte, opts models.ListOptions) ([]byte, error) {
+	var request map[string]json.RawMessage
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, fmt.Errorf("ошибка при разборе запроса: %w", err)
+	}
+	optsBody, err := json.Marshal(opts)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при маршалинге параметров списка: %w", err)
+	}
+	var options map[string]json.RawMessage
+	if err = json.Unmarshal(optsBody, &options); err != nil {
+		return nil, fmt.Errorf("ошибка при маршалинге параметров списка: %w", err)
+	}
+	for k, v := range options {
+		request[k] = v
+	}
+	return json.Marshal(request)
+}
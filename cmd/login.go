@@ -15,17 +15,18 @@ var loginCmd = &cobra.Command{
 	Short: "Login to the GopherVault system",
 	Long: `Login to the GopherVault system with specified login and password. 
 Only registered users can run this command`,
-	Example: "GopherVault login --login <user-system-login> --password <user-system-password>`",
-	Run:     loginHandler,
+	Example: "GopherVault login --login <user-system-login>\n" +
+		"echo \"$PASSWORD\" | GopherVault login --login <user-system-login> --password-stdin",
+	Run: loginHandler,
 }
 
 func loginHandler(cmd *cobra.Command, args []string) {
 	cfg := cmdutil.LoadEnvVariables()
-	login, password, _, _, _, _, _, _ := cmdutil.GetFlagsValues(cmd)
+	values := cmdutil.GetFlagsValues(cmd)
 
 	userCreds := models.User{
-		Login:    login,
-		Password: password,
+		Login:    values.Login,
+		Password: values.Password,
 	}
 
 	body := cmdutil.ConvertToJSONRequestUserCredential(userCreds)
@@ -37,13 +38,12 @@ func loginHandler(cmd *cobra.Command, args []string) {
 
 	cmdutil.HandleResponse(resp, http.StatusOK)
 
-	log.Printf("Пользователь %q успешно вошел в систему GopherVault\n", login)
+	log.Printf("Пользователь %q успешно вошел в систему GopherVault\n", values.Login)
 }
 
 func init() {
 	rootCmd.AddCommand(loginCmd)
 	loginCmd.Flags().String("login", "", "user login")
-	loginCmd.Flags().String("password", "", "user password")
+	cmdutil.AddPasswordFlags(loginCmd, "user password", false)
 	loginCmd.MarkFlagRequired("login")
-	loginCmd.MarkFlagRequired("password")
 }
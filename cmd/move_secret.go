@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+)
+
+// moveSecretCmd представляет команду move-secret
+var moveSecretCmd = &cobra.Command{
+	Use:   "move-secret",
+	Short: "Move saved credentials, note or card to another folder",
+	Example: "GopherVault move-secret --user <user-name> --type note --id 4 --folder work/projects\n" +
+		"GopherVault move-secret --user <user-name> --type note --id 4 --no-folder",
+	Run: moveSecretHandler,
+}
+
+func moveSecretHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+	secretType, _ := cmd.Flags().GetString("type")
+	id, _ := cmd.Flags().GetInt64("id")
+	folder, _ := cmd.Flags().GetString("folder")
+	if noFolder, _ := cmd.Flags().GetBool("no-folder"); !noFolder && folder == "" {
+		log.Fatalln("укажите папку флагом --folder или уберите секрет из папки флагом --no-folder")
+	}
+
+	executeOrganizeRequest(cfg, models.OrganizeRequest{UserName: userName, Type: models.SecretType(secretType), SecretID: id, Folder: &folder})
+}
+
+// executeOrganizeRequest отправляет запрос на изменение папки и тегов секрета
+func executeOrganizeRequest(cfg models.Params, request models.OrganizeRequest) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		log.Fatalf("ошибка при маршалинге запроса: %s", err)
+	}
+	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/organize", cfg.ApplicationHost, cfg.ApplicationPort), body)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+
+	cmdutil.HandleResponse(resp, http.StatusOK)
+}
+
+func init() {
+	rootCmd.AddCommand(moveSecretCmd)
+	moveSecretCmd.Flags().String("user", "", "user name")
+	moveSecretCmd.Flags().String("type", "", "secret type: credentials, note or card")
+	moveSecretCmd.Flags().Int64("id", 0, "secret id")
+	moveSecretCmd.Flags().String("folder", "", "new folder path, e.g. work/projects; created if missing")
+	moveSecretCmd.Flags().Bool("no-folder", false, "remove the secret from its folder")
+	moveSecretCmd.MarkFlagsMutuallyExclusive("folder", "no-folder")
+	moveSecretCmd.MarkFlagRequired("user")
+	moveSecretCmd.MarkFlagRequired("type")
+	moveSecretCmd.MarkFlagRequired("id")
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/localcache"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/syncclient"
+)
+
+// serverURL возвращает адрес эндпоинта сервера
+func serverURL(cfg models.Params, path string) string {
+	return fmt.Sprintf("http://%s:%s%s", cfg.ApplicationHost, cfg.ApplicationPort, path)
+}
+
+// openCache открывает локальный кэш пользователя. Возвращает nil, если ключ кэша KEEPER_CACHE_KEY не задан.
+func openCache(cfg models.Params, userName string) *localcache.Cache {
+	if cfg.CacheKey == "" {
+		return nil
+	}
+	cache, err := localcache.Open(cfg.CacheDir, userName, cfg.CacheKey)
+	if err != nil {
+		log.Fatalf("ошибка при открытии локального кэша: %s", err)
+	}
+	return cache
+}
+
+// serveFromCache выводит секреты из локального кэша, когда сервер недоступен, и сообщает, насколько они свежие.
+// Возвращает false, если кэш не настроен и команда должна завершиться ошибкой связи с сервером.
+func serveFromCache[T any](cfg models.Params, userName string, pick func(cache *localcache.Cache) []T) bool {
+	cache := openCache(cfg, userName)
+	if cache == nil {
+		return false
+	}
+	syncedAt, err := cache.SyncedAt()
+	if errors.Is(err, localcache.ErrNotSynced) {
+		log.Fatalln("сервер недоступен, а локальный кэш еще не заполнен: выполните GopherVault sync, когда сервер будет доступен")
+	}
+
+	age := time.Since(syncedAt).Truncate(time.Second)
+	log.Printf("сервер недоступен, данные получены из локального кэша от %s (%s назад)", syncedAt.Local().Format(time.DateTime), age)
+	if age > cfg.CacheMaxAge {
+		log.Printf("ВНИМАНИЕ: кэш старше %s и может быть устаревшим", cfg.CacheMaxAge)
+	}
+	if pending := len(cache.Pending()); pending > 0 {
+		log.Printf("изменений, ожидающих отправки на сервер: %d; они не отражены в выводе", pending)
+	}
+
+	items := pick(cache)
+	if len(items) == 0 {
+		log.Printf("в локальном кэше нет подходящих данных для пользователя %q", userName)
+		return true
+	}
+	body, err := json.Marshal(items)
+	if err != nil {
+		log.Fatalf("ошибка при маршалинге данных из кэша: %s", err)
+	}
+	log.Println(string(body))
+	return true
+}
+
+// executeWriteRequest отправляет на сервер запрос на изменение секретов.
+// Если сервер недоступен и локальный кэш настроен, изменение ставится в очередь до следующей синхронизации.
+func executeWriteRequest(cfg models.Params, userName, path string, body []byte) {
+	resp, err := cmdutil.ExecutePostRequest(serverURL(cfg, path), body)
+	if err != nil {
+		if queueOffline(cfg, userName, path, body, 0) {
+			return
+		}
+		log.Fatalln(err.Error())
+	}
+	cmdutil.HandleResponse(resp, http.StatusOK)
+}
+
+// queueOffline ставит изменение в очередь локального кэша. Возвращает false, если кэш не настроен.
+// Если ревизия не указана, изменение привязывается к ревизии секрета из кэша, чтобы при синхронизации
+// обнаружить конфликт с изменениями на других устройствах.
+func queueOffline(cfg models.Params, userName, path string, body []byte, revision int64) bool {
+	cache := openCache(cfg, userName)
+	if cache == nil {
+		return false
+	}
+	if revision == 0 {
+		revision = syncclient.BaseRevision(cache, path, body)
+	}
+	cache.Queue(path, body, revision, time.Now())
+	if err := cache.Save(); err != nil {
+		log.Fatalf("ошибка при сохранении локального кэша: %s", err)
+	}
+	log.Printf("сервер недоступен, изменение поставлено в очередь и будет отправлено командой GopherVault sync (в очереди: %d)", len(cache.Pending()))
+	return true
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+)
+
+// otpCmd представляет команду otp
+var otpCmd = &cobra.Command{
+	Use:     "otp <name>",
+	Short:   "Show the current one-time code for a stored TOTP secret",
+	Example: "GopherVault otp github --user <user-name>",
+	Args:    cobra.ExactArgs(1),
+	Run:     otpHandler,
+}
+
+func otpHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+	name := args[0]
+
+	// Находим идентификатор секрета по его названию
+	body := cmdutil.ConvertToJSONRequestTOTP(models.TOTP{UserName: userName, Name: &name})
+	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/get/totp", cfg.ApplicationHost, cfg.ApplicationPort), body)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+	if resp.StatusCode() != http.StatusOK {
+		log.Fatalf("секрет TOTP %q не найден: %s %s", name, resp.Status(), resp.String())
+	}
+	var secrets []models.TOTP
+	if err = json.Unmarshal(resp.Body(), &secrets); err != nil || len(secrets) == 0 || secrets[0].ID == nil {
+		log.Fatalf("некорректный ответ сервера: %s", resp.String())
+	}
+
+	// Запрашиваем текущий код
+	body = cmdutil.ConvertToJSONRequestTOTP(models.TOTP{UserName: userName})
+	resp, err = cmdutil.ExecuteGetRequest(fmt.Sprintf("http://%s:%s/totp/%d/code", cfg.ApplicationHost, cfg.ApplicationPort, *secrets[0].ID), body)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+	if resp.StatusCode() != http.StatusOK {
+		cmdutil.HandleResponse(resp, http.StatusOK)
+		return
+	}
+	var code models.TOTPCode
+	if err = json.Unmarshal(resp.Body(), &code); err != nil {
+		log.Fatalf("некорректный ответ сервера: %s", resp.String())
+	}
+	log.Printf("%s (действителен еще %d с)\n", code.Code, code.Remaining)
+}
+
+func init() {
+	rootCmd.AddCommand(otpCmd)
+	otpCmd.Flags().String("user", "", "user name")
+	otpCmd.MarkFlagRequired("user")
+}
@@ -11,20 +11,21 @@ import (
 
 // registerCmd представляет команду регистрации пользователей.
 var registerCmd = &cobra.Command{
-	Use:     "register",
-	Short:   "Register in the GopherVault system.",
-	Long:    `Register in the GopherVault system with provided login and password`,
-	Example: "GopherVault register --login <user-system-login> --password <user-system-password>",
-	Run:     registerHandler,
+	Use:   "register",
+	Short: "Register in the GopherVault system.",
+	Long:  `Register in the GopherVault system with provided login and password`,
+	Example: "GopherVault register --login <user-system-login>\n" +
+		"GopherVault register --login <user-system-login> --password-file ~/.gophervault-password",
+	Run: registerHandler,
 }
 
 func registerHandler(cmd *cobra.Command, args []string) {
 	cfg := cmdutil.LoadEnvVariables()
-	login, password, _, _, _, _, _, _ := cmdutil.GetFlagsValues(cmd)
+	values := cmdutil.GetFlagsValues(cmd)
 
 	userCreds := models.User{
-		Login:    login,
-		Password: password,
+		Login:    values.Login,
+		Password: values.Password,
 	}
 
 	body := cmdutil.ConvertToJSONRequestUserCredential(userCreds)
@@ -36,11 +37,12 @@ func registerHandler(cmd *cobra.Command, args []string) {
 
 	cmdutil.HandleResponse(resp, http.StatusOK)
 
-	log.Printf("Пользователь %q успешно зарегистрировался в систему GopherVault\n", login)
+	log.Printf("Пользователь %q успешно зарегистрировался в систему GopherVault\n", values.Login)
 }
 
 func init() {
 	rootCmd.AddCommand(registerCmd)
 	registerCmd.Flags().String("login", "", "user login")
-	registerCmd.Flags().String("password", "", "user password")
+	cmdutil.AddPasswordFlags(registerCmd, "user password", true)
+	registerCmd.MarkFlagRequired("login")
 }
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+)
+
+// restoreCmd представляет команду restore
+var restoreCmd = &cobra.Command{
+	Use:     "restore",
+	Short:   "Restore a previous version of user's credentials or note",
+	Example: "GopherVault restore --user <user-name> --type note --id 4 --version 2",
+	Run:     restoreHandler,
+}
+
+func restoreHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+	secretType, _ := cmd.Flags().GetString("type")
+	id, _ := cmd.Flags().GetInt64("id")
+	version, _ := cmd.Flags().GetInt64("version")
+
+	body := cmdutil.ConvertToJSONRequestHistory(models.HistoryRequest{UserName: userName, Version: version})
+	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/history/%s/%d/restore", cfg.ApplicationHost, cfg.ApplicationPort, secretType, id), body)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+
+	cmdutil.HandleResponse(resp, http.StatusOK)
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+	restoreCmd.Flags().String("user", "", "user name")
+	restoreCmd.Flags().String("type", "", "secret type: credentials or note")
+	restoreCmd.Flags().Int64("id", 0, "secret id")
+	restoreCmd.Flags().Int64("version", 0, "version to restore")
+	restoreCmd.MarkFlagRequired("user")
+	restoreCmd.MarkFlagRequired("type")
+	restoreCmd.MarkFlagRequired("id")
+	restoreCmd.MarkFlagRequired("version")
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/ZnNr/GopherVault/internal/database"
 	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/passgen"
 	"github.com/ZnNr/GopherVault/internal/router"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/spf13/cobra"
@@ -53,12 +54,20 @@ func run(sugar *zap.SugaredLogger) error {
 	}
 	defer pg.Close()
 
+	// Запуск фоновой очистки корзины
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if cfg.TrashRetention > 0 {
+		go purgeTrash(ctx, pg, cfg.TrashRetention, sugar)
+	}
+	go reindexNotes(ctx, pg, sugar)
+
 	// Инициализация сервера
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.ApplicationPort))
 	if err != nil {
 		return fmt.Errorf("Ошибка при попытке прослушивания: %w", err)
 	}
-	router := router.New(pg, sugar)
+	router := router.New(pg, sugar, passgen.NewPolicy(cfg.PasswordMinLength, cfg.PassphraseMinWords))
 	server := &http.Server{
 		Handler: router,
 	}
@@ -90,3 +99,33 @@ func run(sugar *zap.SugaredLogger) error {
 
 	return nil
 }
+
+// reindexNotes строит поисковый индекс заметок, сохраненных до его появления
+func reindexNotes(ctx context.Context, pg *database.Db, sugar *zap.SugaredLogger) {
+	indexed, err := pg.ReindexNotes(ctx)
+	if err != nil {
+		sugar.Errorf("Ошибка при построении поискового индекса заметок: %v", err)
+	}
+	if indexed > 0 {
+		sugar.Infof("Построен поисковый индекс заметок: %d", indexed)
+	}
+}
+
+// trashPurgeInterval период запуска очистки корзины
+const trashPurgeInterval = time.Hour
+
+// purgeTrash периодически окончательно удаляет секреты, находящиеся в корзине дольше retentionDays дней
+func purgeTrash(ctx context.Context, pg *database.Db, retentionDays int, sugar *zap.SugaredLogger) {
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+	for {
+		if err := pg.PurgeTrash(ctx, retentionDays); err != nil {
+			sugar.Errorf("Ошибка при очистке корзины: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
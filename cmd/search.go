@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// searchCmd представляет команду search
+var searchCmd = &cobra.Command{
+	Use:     "search <query>",
+	Short:   "Search user's credentials, notes and cards in GopherVault",
+	Example: "GopherVault search github --user <user-name> --type credentials --type note",
+	Args:    cobra.MinimumNArgs(1),
+	Run:     searchHandler,
+}
+
+func searchHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+	types, _ := cmd.Flags().GetStringArray("type")
+
+	requestSearch := models.SearchRequest{
+		UserName: userName,
+		Query:    strings.Join(args, " "),
+	}
+	for _, t := range types {
+		requestSearch.Types = append(requestSearch.Types, models.SecretType(t))
+	}
+	body := cmdutil.ConvertToJSONRequestSearch(requestSearch)
+
+	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/search", cfg.ApplicationHost, cfg.ApplicationPort), body)
+	if err != nil {
+		log.Printf(err.Error())
+	}
+
+	cmdutil.HandleResponse(resp, http.StatusOK)
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().String("user", "", "user name")
+	searchCmd.Flags().StringArray("type", nil, "secret type to search: credentials, note or card (repeatable)")
+	searchCmd.MarkFlagRequired("user")
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// searchNotesCmd представляет команду search-notes
+var searchNotesCmd = &cobra.Command{
+	Use:     "search-notes <words>",
+	Short:   "Search user's notes by words in their content",
+	Example: "GopherVault search-notes dragonglass dagger --user <user-name>",
+	Args:    cobra.MinimumNArgs(1),
+	Run:     searchNotesHandler,
+}
+
+func searchNotesHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+
+	requestSearch := models.SearchRequest{
+		UserName: userName,
+		Query:    strings.Join(args, " "),
+	}
+	body := cmdutil.ConvertToJSONRequestSearch(requestSearch)
+
+	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/notes/search", cfg.ApplicationHost, cfg.ApplicationPort), body)
+	if err != nil {
+		log.Printf(err.Error())
+	}
+
+	cmdutil.HandleResponse(resp, http.StatusOK)
+}
+
+func init() {
+	rootCmd.AddCommand(searchNotesCmd)
+	searchNotesCmd.Flags().String("user", "", "user name")
+	searchNotesCmd.MarkFlagRequired("user")
+}
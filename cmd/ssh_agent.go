@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/sshkey"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/agent"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+)
+
+// sshAgentCmd представляет команду ssh-agent
+var sshAgentCmd = &cobra.Command{
+	Use:   "ssh-agent",
+	Short: "Serve stored SSH keys over the ssh-agent protocol.",
+	Long: `Load the user's SSH keys from GopherVault and serve them over the ssh-agent protocol on a Unix socket.
+Keys are kept in memory only and every use of a key is logged. Point SSH_AUTH_SOCK at the socket to use the agent.`,
+	Example: "GopherVault ssh-agent --user <user-name> --socket /tmp/gophervault-agent.sock",
+	Run:     sshAgentHandler,
+}
+
+func sshAgentHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+	socket, _ := cmd.Flags().GetString("socket")
+
+	// Получаем ключи пользователя из хранилища
+	body := cmdutil.ConvertToJSONRequestSSHKey(models.SSHKey{UserName: userName})
+	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/get/ssh-key", cfg.ApplicationHost, cfg.ApplicationPort), body)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+	if resp.StatusCode() != http.StatusOK {
+		log.Fatalf("не удалось получить SSH-ключи: %s %s", resp.Status(), resp.String())
+	}
+	var storedKeys []models.SSHKey
+	if err = json.Unmarshal(resp.Body(), &storedKeys); err != nil {
+		log.Fatalf("некорректный ответ сервера: %s", err.Error())
+	}
+
+	// Расшифровываем ключи для агента
+	var keys []agent.AddedKey
+	for _, k := range storedKeys {
+		var passphrase string
+		if k.Passphrase != nil {
+			passphrase = *k.Passphrase
+		}
+		raw, err := sshkey.ParseRawKey(*k.PrivateKey, passphrase)
+		if err != nil {
+			log.Printf("ключ %q пропущен: %s", *k.Name, err.Error())
+			continue
+		}
+		keys = append(keys, agent.AddedKey{PrivateKey: raw, Comment: *k.Name})
+	}
+
+	sshAgent, err := sshkey.NewAgent(keys, func(comment, fingerprint string) {
+		log.Printf("использован SSH-ключ %q (%s)", comment, fingerprint)
+	})
+	if err != nil {
+		log.Fatalf("ошибка при создании агента: %s", err.Error())
+	}
+
+	listener, err := listenAgentSocket(socket)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+	defer os.Remove(socket)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("Загружено ключей: %d. Выполните: export SSH_AUTH_SOCK=%s", len(keys), socket)
+	if err = sshkey.Serve(ctx, listener, sshAgent); err != nil {
+		log.Printf("ошибка при работе агента: %s", err.Error())
+	}
+}
+
+// listenAgentSocket открывает сокет агента, доступный только текущему пользователю.
+// Оставшийся от предыдущего запуска сокет удаляется, а любой другой файл по этому пути - нет.
+func listenAgentSocket(socket string) (net.Listener, error) {
+	info, err := os.Lstat(socket)
+	switch {
+	case err == nil && info.Mode()&os.ModeSocket == 0:
+		return nil, fmt.Errorf("файл %q уже существует и не является сокетом", socket)
+	case err == nil:
+		if err = os.Remove(socket); err != nil {
+			return nil, fmt.Errorf("ошибка при удалении старого сокета %q: %w", socket, err)
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, fmt.Errorf("ошибка при проверке сокета %q: %w", socket, err)
+	}
+
+	// Сокет создается сразу с правами 0600, чтобы к нему нельзя было подключиться до смены прав
+	var listener net.Listener
+	err = withPrivateUmask(func() error {
+		listener, err = net.Listen("unix", socket)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при открытии сокета %q: %w", socket, err)
+	}
+	if err = os.Chmod(socket, 0600); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("ошибка при изменении прав доступа к сокету: %w", err)
+	}
+	return listener, nil
+}
+
+func init() {
+	rootCmd.AddCommand(sshAgentCmd)
+	sshAgentCmd.Flags().String("user", "", "user name")
+	sshAgentCmd.Flags().String("socket", filepath.Join(os.TempDir(), "gophervault-agent.sock"), "path to the agent Unix socket")
+	sshAgentCmd.MarkFlagRequired("user")
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"log"
+	"time"
+
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/localcache"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/syncclient"
+	"github.com/spf13/cobra"
+)
+
+// syncCmd представляет команду sync
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Synchronize the local encrypted cache with GopherVault",
+	Long: `Send changes made while the server was unavailable and fetch changes made on other devices
+into the local encrypted cache of credentials, notes and cards. Only changes since the previous sync are fetched.
+Conflicting changes are resolved by the last write to the server, the overwritten version is kept as a conflict copy.
+The cache is encrypted with KEEPER_CACHE_KEY and lets get-credentials, get-note and get-card work when the server is down.`,
+	Example: "GopherVault sync --user <user-name>",
+	Run:     syncHandler,
+}
+
+func syncHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+
+	cache := openCache(cfg, userName)
+	if cache == nil {
+		log.Fatalln("локальный кэш не настроен: задайте ключ хранилища в переменной окружения KEEPER_CACHE_KEY")
+	}
+
+	if err := syncCache(cfg, userName, cache); err != nil {
+		log.Fatalf("ошибка синхронизации, в очереди осталось изменений: %d: %s", len(cache.Pending()), err)
+	}
+}
+
+// syncCache синхронизирует локальный кэш с сервером, сохраняет его и выводит итоги синхронизации
+func syncCache(cfg models.Params, userName string, cache *localcache.Cache) error {
+	res, syncErr := syncclient.New(serverURL(cfg, ""), userName).Sync(cache, time.Now())
+	// Кэш сохраняется и после ошибки, чтобы отправленные изменения не были отправлены повторно
+	if err := cache.Save(); err != nil {
+		log.Fatalf("ошибка при сохранении локального кэша: %s", err)
+	}
+	for _, conflict := range res.Conflicts {
+		log.Printf("конфликт: %s", conflict)
+	}
+	for _, rejected := range res.Rejected {
+		log.Printf("изменение отклонено сервером: %s", rejected)
+	}
+	if res.Sent > 0 {
+		log.Printf("отправлено изменений из очереди: %d", res.Sent)
+	}
+	if syncErr != nil {
+		return syncErr
+	}
+	log.Printf("локальный кэш пользователя %q синхронизирован: получено изменений %d, номер журнала %d",
+		userName, res.Received, res.Seq)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().String("user", "", "user name")
+	syncCmd.MarkFlagRequired("user")
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/spf13/cobra"
+	"log"
+)
+
+// tagSecretCmd представляет команду tag-secret
+var tagSecretCmd = &cobra.Command{
+	Use:     "tag-secret",
+	Short:   "Add tags to or remove tags from saved credentials, note or card",
+	Example: "GopherVault tag-secret --user <user-name> --type credentials --id 7 --add work --remove personal",
+	Run:     tagSecretHandler,
+}
+
+func tagSecretHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+	secretType, _ := cmd.Flags().GetString("type")
+	id, _ := cmd.Flags().GetInt64("id")
+	addTags, _ := cmd.Flags().GetStringArray("add")
+	removeTags, _ := cmd.Flags().GetStringArray("remove")
+	if len(addTags) == 0 && len(removeTags) == 0 {
+		log.Fatalln("укажите добавляемые теги флагом --add или снимаемые флагом --remove")
+	}
+
+	executeOrganizeRequest(cfg, models.OrganizeRequest{
+		UserName: userName, Type: models.SecretType(secretType), SecretID: id, AddTags: addTags, RemoveTags: removeTags,
+	})
+}
+
+func init() {
+	rootCmd.AddCommand(tagSecretCmd)
+	tagSecretCmd.Flags().String("user", "", "user name")
+	tagSecretCmd.Flags().String("type", "", "secret type: credentials, note or card")
+	tagSecretCmd.Flags().Int64("id", 0, "secret id")
+	tagSecretCmd.Flags().StringArray("add", nil, "tag to add, created if missing (can be repeated)")
+	tagSecretCmd.Flags().StringArray("remove", nil, "tag to remove (can be repeated)")
+	tagSecretCmd.MarkFlagRequired("user")
+	tagSecretCmd.MarkFlagRequired("type")
+	tagSecretCmd.MarkFlagRequired("id")
+}
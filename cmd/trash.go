@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+	"time"
+)
+
+// trashCmd представляет команду trash
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage deleted credentials, notes and cards",
+}
+
+// trashListCmd представляет команду trash list
+var trashListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "Show items in the trash",
+	Example: "GopherVault trash list --user <user-name> [--type note]",
+	Run:     trashListHandler,
+}
+
+// trashRestoreCmd представляет команду trash restore
+var trashRestoreCmd = &cobra.Command{
+	Use:     "restore",
+	Short:   "Restore items from the trash",
+	Example: "GopherVault trash restore --user <user-name> --type note --id 4",
+	Run:     trashRestoreHandler,
+}
+
+// trashEmptyCmd представляет команду trash empty
+var trashEmptyCmd = &cobra.Command{
+	Use:     "empty",
+	Short:   "Permanently delete items from the trash",
+	Example: "GopherVault trash empty --user <user-name> [--type note --id 4] --yes",
+	Run:     trashEmptyHandler,
+}
+
+// getTrashRequest собирает запрос к корзине по флагам команды
+func getTrashRequest(cmd *cobra.Command) models.TrashRequest {
+	userName, _ := cmd.Flags().GetString("user")
+	secretType, _ := cmd.Flags().GetString("type")
+	id, _ := cmd.Flags().GetInt64("id")
+	return models.TrashRequest{UserName: userName, Type: models.SecretType(secretType), SecretID: id}
+}
+
+func trashListHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	body := cmdutil.ConvertToJSONRequestTrash(getTrashRequest(cmd))
+
+	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/get/trash", cfg.ApplicationHost, cfg.ApplicationPort), body)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+	if resp.StatusCode() != http.StatusOK {
+		cmdutil.HandleResponse(resp, http.StatusOK)
+		return
+	}
+	var items []models.TrashItem
+	if err = json.Unmarshal(resp.Body(), &items); err != nil {
+		log.Fatalf("некорректный ответ сервера: %s", resp.String())
+	}
+	for _, item := range items {
+		fmt.Printf("%s\t%d\t%s\t%s\n", item.Type, item.SecretID, item.DeletedAt.Local().Format(time.DateTime), item.Title)
+	}
+}
+
+func trashRestoreHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	body := cmdutil.ConvertToJSONRequestTrash(getTrashRequest(cmd))
+
+	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/trash/restore", cfg.ApplicationHost, cfg.ApplicationPort), body)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+
+	cmdutil.HandleResponse(resp, http.StatusOK)
+}
+
+func trashEmptyHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	if yes, _ := cmd.Flags().GetBool("yes"); !yes {
+		log.Fatalln("секреты будут удалены без возможности восстановления; для подтверждения укажите флаг --yes")
+	}
+	body := cmdutil.ConvertToJSONRequestTrash(getTrashRequest(cmd))
+
+	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/trash/empty", cfg.ApplicationHost, cfg.ApplicationPort), body)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+
+	cmdutil.HandleResponse(resp, http.StatusOK)
+}
+
+func init() {
+	rootCmd.AddCommand(trashCmd)
+	for _, cmd := range []*cobra.Command{trashListCmd, trashRestoreCmd, trashEmptyCmd} {
+		trashCmd.AddCommand(cmd)
+		cmd.Flags().String("user", "", "user name")
+		cmd.Flags().String("type", "", "secret type: credentials, note or card")
+		cmd.Flags().Int64("id", 0, "secret id")
+		cmd.MarkFlagRequired("user")
+	}
+	trashEmptyCmd.Flags().Bool("yes", false, "confirm the permanent deletion")
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/tui"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// uiCmd представляет команду ui
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Browse and edit the vault in an interactive terminal UI",
+	Long: `Open a full-screen terminal UI to list, filter, view, add, edit and delete credentials, notes and cards.
+Secrets are masked until revealed and are hidden again after --reveal-timeout.
+Only authorized users can use this command`,
+	Example: "GopherVault ui --user user_name --reveal-timeout 30s",
+	Run:     uiHandler,
+}
+
+func uiHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+	revealTimeout, _ := cmd.Flags().GetDuration("reveal-timeout")
+	if revealTimeout <= 0 {
+		log.Fatalln("таймаут скрытия секретов должен быть положительным")
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		log.Fatalln("интерфейс можно открыть только в терминале")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGHUP)
+	defer stop()
+
+	model := tui.NewModel(&uiBackend{cfg: cfg, userName: userName}, userName, revealTimeout)
+	if err := model.Load(ctx); err != nil {
+		log.Fatalln(err.Error())
+	}
+	if err := tui.Run(ctx, model, os.Stdin, os.Stdout); err != nil {
+		log.Fatalln(err.Error())
+	}
+}
+
+// uiBackend хранилище интерфейса, работающее через HTTP API сервера
+type uiBackend struct {
+	cfg      models.Params
+	userName string
+}
+
+// List загружает учетные данные, заметки и карты пользователя
+func (b *uiBackend) List(ctx context.Context) ([]tui.Item, error) {
+	var credentials []models.Credentials
+	if err := b.fetch("/get/credentials", cmdutil.ConvertToJSONRequestCredential(models.Credentials{UserName: b.userName}), &credentials); err != nil {
+		return nil, err
+	}
+	var notes []models.Note
+	if err := b.fetch("/get/note", cmdutil.ConvertToJSONRequestNotes(models.Note{UserName: b.userName}), &notes); err != nil {
+		return nil, err
+	}
+	var cards []models.Card
+	if err := b.fetch("/get/card", cmdutil.ConvertToJSONRequestCards(models.Card{UserName: b.userName}), &cards); err != nil {
+		return nil, err
+	}
+
+	items := make([]tui.Item, 0, len(credentials)+len(notes)+len(cards))
+	for _, c := range credentials {
+		items = append(items, tui.Item{Type: models.SecretCredentials, Credentials: c})
+	}
+	for _, n := range notes {
+		items = append(items, tui.Item{Type: models.SecretNote, Note: n})
+	}
+	for _, c := range cards {
+		items = append(items, tui.Item{Type: models.SecretCard, Card: c})
+	}
+	return items, nil
+}
+
+// Save сохраняет новый секрет
+func (b *uiBackend) Save(ctx context.Context, item tui.Item) error {
+	path, body := b.request("/save", item)
+	return b.post(path, body, 0)
+}
+
+// Update изменяет секрет с проверкой ревизии
+func (b *uiBackend) Update(ctx context.Context, old, item tui.Item) error {
+	// Сервер сохраняет метаданные карты, не указанные в запросе, поэтому очищенные в форме метаданные передаются пустыми
+	if item.Type == models.SecretCard && item.Card.Metadata == nil {
+		item.Card.Metadata = new(string)
+	}
+	path, body := b.request("/update", item)
+	return b.post(path, body, old.Revision())
+}
+
+// Delete перемещает секрет в корзину. В запросе передаются только ключевые поля секрета, чтобы не удалить
+// другие секреты, а ревизия передается в заголовке If-Match, чтобы не удалить секрет, измененный после загрузки списка.
+func (b *uiBackend) Delete(ctx context.Context, item tui.Item) error {
+	request := tui.Item{Type: item.Type}
+	switch item.Type {
+	case models.SecretCredentials:
+		site := ""
+		if item.Credentials.Site != nil {
+			site = *item.Credentials.Site
+		}
+		request.Credentials = models.Credentials{UserName: b.userName, Login: item.Credentials.Login, Site: &site}
+	case models.SecretNote:
+		request.Note = models.Note{UserName: b.userName, Title: item.Note.Title}
+	default:
+		request.Card = models.Card{UserName: b.userName, BankName: item.Card.BankName, Number: item.Card.Number}
+	}
+	path, body := b.request("/delete", request)
+	return b.post(path, body, item.Revision())
+}
+
+// request возвращает путь запроса с префиксом prefix и тело запроса для секрета
+func (b *uiBackend) request(prefix string, item tui.Item) (string, []byte) {
+	switch item.Type {
+	case models.SecretCredentials:
+		return prefix + "/credentials", cmdutil.ConvertToJSONRequestCredential(item.Credentials)
+	case models.SecretNote:
+		return prefix + "/note", cmdutil.ConvertToJSONRequestNotes(item.Note)
+	default:
+		return prefix + "/card", cmdutil.ConvertToJSONRequestCards(item.Card)
+	}
+}
+
+// fetch запрашивает список секретов и разбирает ответ в dst. Пустой список сервер возвращает со статусом 204.
+func (b *uiBackend) fetch(path string, body []byte, dst any) error {
+	resp, err := cmdutil.ExecutePostRequest(serverURL(b.cfg, path), body)
+	if err != nil {
+		return err
+	}
+	switch resp.StatusCode() {
+	case http.StatusOK:
+		if err = json.Unmarshal(resp.Body(), dst); err != nil {
+			return fmt.Errorf("некорректный ответ сервера: %w", err)
+		}
+		return nil
+	case http.StatusNoContent:
+		return nil
+	default:
+		return responseError(resp.Status(), resp.String())
+	}
+}
+
+// post отправляет запрос на изменение секретов. Ревизия revision, если указана, передается в заголовке If-Match.
+func (b *uiBackend) post(path string, body []byte, revision int64) error {
+	resp, err := cmdutil.ExecuteConditionalPostRequest(serverURL(b.cfg, path), body, revision)
+	if err != nil {
+		return err
+	}
+	switch resp.StatusCode() {
+	case http.StatusOK:
+		return nil
+	case http.StatusPreconditionFailed:
+		var conflict models.RevisionConflict
+		if err = json.Unmarshal(resp.Body(), &conflict); err == nil && conflict.Message != "" {
+			return fmt.Errorf("%s, обновите список клавишей r", conflict.Message)
+		}
+		return responseError(resp.Status(), resp.String())
+	default:
+		return responseError(resp.Status(), resp.String())
+	}
+}
+
+// responseError формирует ошибку из неуспешного ответа сервера
+func responseError(status, body string) error {
+	if body = strings.TrimSpace(body); body != "" {
+		return fmt.Errorf("некорректный статус код %s: %s", status, body)
+	}
+	return fmt.Errorf("некорректный статус код %s", status)
+}
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+	uiCmd.Flags().String("user", "", "user name")
+	uiCmd.Flags().Duration("reveal-timeout", 15*time.Second, "hide revealed secrets after this time")
+	uiCmd.MarkFlagRequired("user")
+}
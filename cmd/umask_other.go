@@ -0,0 +1,8 @@
+//go:build !unix
+
+package cmd
+
+// withPrivateUmask выполняет fn; на платформах без маски прав доступ к файлу ограничивается после создания
+func withPrivateUmask(fn func() error) error {
+	return fn()
+}
@@ -0,0 +1,12 @@
+//go:build unix
+
+package cmd
+
+import "syscall"
+
+// withPrivateUmask выполняет fn с маской 0077, чтобы созданные файлы были доступны только текущему пользователю
+func withPrivateUmask(fn func() error) error {
+	old := syscall.Umask(0077)
+	defer syscall.Umask(old)
+	return fn()
+}
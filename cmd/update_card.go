@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"log"
+	"strings"
+
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// updateCardCmd представляет команду updateCard
+var updateCardCmd = &cobra.Command{
+	Use:   "update-card",
+	Short: "Update bank card info for the provided card number.",
+	Long: `Update bank card info for the provided card number. Only the given values are changed:
+the bank, type, cv, password, metadata and custom fields that are omitted keep their current values.`,
+	Example: "GopherVault update-card --user <user-name> --number 1111222233334444 --password-file new-password.txt\n" +
+		"GopherVault update-card --user <user-name> --number 1111222233334444 --bank beta --type credit --revision 2",
+	Run: updateCardHandler,
+}
+
+// updateCardHandler обработчик команды обновления карты
+func updateCardHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	flags := cmd.Flags()
+	userName, _ := flags.GetString("user")
+	number, _ := flags.GetString("number")
+	if len(number) != 16 {
+		log.Fatalln("идентификационный номер пластиковой карты должен состоять из 16 цифр.")
+	}
+
+	requestCard := models.Card{
+		UserName: userName,
+		Number:   &number,
+		Fields:   createCustomFields(cmd),
+	}
+	if bank, _ := flags.GetString("bank"); bank != "" {
+		requestCard.BankName = &bank
+	}
+	if cardType, _ := flags.GetString("type"); cardType != "" {
+		requestCard.CardType = &cardType
+	}
+	if flags.Changed("metadata") {
+		metadata, _ := flags.GetString("metadata")
+		requestCard.Metadata = &metadata
+	}
+	// CV и пароль меняются, только если указан источник нового значения
+	if cmdutil.SecretFlagsChanged(cmd, "cv") {
+		cv := strings.TrimSpace(cmdutil.SecretValue(cmd, "cv", "CV карты: "))
+		if len(cv) != 3 {
+			log.Fatalln("CV-код пластиковой карты должен состоять из 3 цифр.")
+		}
+		requestCard.CV = &cv
+	}
+	if cmdutil.SecretFlagsChanged(cmd, "password") {
+		password := cmdutil.SecretValue(cmd, "password", "Пароль: ")
+		requestCard.Password = &password
+	}
+
+	body := cmdutil.ConvertToJSONRequestCards(requestCard)
+
+	revision, _ := flags.GetInt64("revision")
+	showSecrets, _ := flags.GetBool("show-secrets")
+	executeConditionalUpdate(cfg, userName, "/update/card", body, revision, showSecrets)
+}
+
+func init() {
+	rootCmd.AddCommand(updateCardCmd)
+	updateCardCmd.Flags().String("user", "", "user name")
+	updateCardCmd.Flags().String("number", "", "card number")
+	updateCardCmd.Flags().String("bank", "", "bank; the current bank is kept if omitted")
+	updateCardCmd.Flags().String("type", "", "card type; the current type is kept if omitted")
+	cmdutil.AddSecretFlags(updateCardCmd, "cv", "new card cv", "")
+	cmdutil.AddSecretFlags(updateCardCmd, "password", "new card password", "")
+	updateCardCmd.MarkFlagsMutuallyExclusive("cv-stdin", "password-stdin")
+	updateCardCmd.Flags().String("metadata", "", "metadata; the current metadata is kept if omitted, an empty value clears it")
+	addCustomFieldFlags(updateCardCmd)
+	updateCardCmd.Flags().Int64("revision", 0, "expected revision of the card; the update is rejected if it was changed since")
+	updateCardCmd.Flags().Bool("show-secrets", false, "show secret values in the diff shown on a revision conflict")
+	updateCardCmd.MarkFlagRequired("user")
+	updateCardCmd.MarkFlagRequired("number")
+}
@@ -1,27 +1,28 @@
 package cmd
 
 import (
-	"fmt"
 	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
 	"github.com/ZnNr/GopherVault/internal/models"
-	"log"
-	"net/http"
 
 	"github.com/spf13/cobra"
 )
 
 // updateCredentialsCmd представляет команду updateCredentials
 var updateCredentialsCmd = &cobra.Command{
-	Use:     "update-credentials",
-	Short:   "Update user credentials for the provided login.",
-	Example: "GopherVault update-credentials --user <user-name> --login <saved-login> --password <new-password>",
-	Run:     updateCredentialsHandler,
+	Use:   "update-credentials",
+	Short: "Update user credentials for the provided login.",
+	Example: "GopherVault update-credentials --user <user-name> --login <saved-login> --password-file new-password.txt\n" +
+		"GopherVault update-credentials --user <user-name> --login <saved-login> --generate --mode diceware",
+	Run: updateCredentialsHandler,
 }
 
 // updateCredentialsHandler обработчик команды обновления учетных данных
 func updateCredentialsHandler(cmd *cobra.Command, args []string) {
 	cfg := cmdutil.LoadEnvVariables()
-	userName, login, password, metadata, _, _, _, _ := cmdutil.GetFlagsValues(cmd)
+	values := cmdutil.GetFlagsValues(cmd)
+	userName, login, metadata := values.UserName, values.Login, values.Metadata
+	password := passwordOrGenerated(cmd, cfg, values.Password)
+	warnIfBreached(cmd, cfg, password)
 
 	requestCredentials := models.Credentials{
 		UserName: userName,
@@ -29,24 +30,39 @@ func updateCredentialsHandler(cmd *cobra.Command, args []string) {
 		Password: &password,
 		Metadata: &metadata,
 	}
+	if site, _ := cmd.Flags().GetString("site"); site != "" {
+		requestCredentials.Site = &site
+	}
+	if name, _ := cmd.Flags().GetString("name"); name != "" {
+		requestCredentials.Name = &name
+	}
+	urls, _ := cmd.Flags().GetStringArray("url")
+	match, _ := cmd.Flags().GetString("match")
+	requestCredentials.URLs = createCredentialURLs(urls, match)
+	requestCredentials.Fields = createCustomFields(cmd)
 
 	body := cmdutil.ConvertToJSONRequestCredential(requestCredentials)
 
-	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/update/credentials", cfg.ApplicationHost, cfg.ApplicationPort), body)
-	if err != nil {
-		log.Printf(err.Error())
-	}
-
-	cmdutil.HandleResponse(resp, http.StatusOK)
+	revision, _ := cmd.Flags().GetInt64("revision")
+	showSecrets, _ := cmd.Flags().GetBool("show-secrets")
+	executeConditionalUpdate(cfg, userName, "/update/credentials", body, revision, showSecrets)
 }
 
 func init() {
 	rootCmd.AddCommand(updateCredentialsCmd)
 	updateCredentialsCmd.Flags().String("user", "", "user name")
 	updateCredentialsCmd.Flags().String("login", "", "user login")
-	updateCredentialsCmd.Flags().String("password", "", "user password")
+	updateCredentialsCmd.Flags().String("site", "", "site or service the credentials belong to")
+	cmdutil.AddPasswordFlags(updateCredentialsCmd, "new password", true)
 	updateCredentialsCmd.Flags().String("metadata", "", "metadata")
+	updateCredentialsCmd.Flags().String("name", "", "display name of the credentials; the current name is kept if omitted")
+	updateCredentialsCmd.Flags().StringArray("url", nil, "site URL the credentials are used on (can be repeated); the current URLs are kept if omitted")
+	updateCredentialsCmd.Flags().String("match", string(models.MatchBaseDomain), "URL match rule: base_domain, host or regex (must match the whole URL)")
+	updateCredentialsCmd.Flags().Bool("generate", false, "generate the new password instead of --password")
+	addGeneratorFlags(updateCredentialsCmd)
+	addCustomFieldFlags(updateCredentialsCmd)
+	updateCredentialsCmd.Flags().Int64("revision", 0, "expected revision of the credentials; the update is rejected if they were changed since")
+	updateCredentialsCmd.Flags().Bool("show-secrets", false, "show secret values in the diff shown on a revision conflict")
 	updateCredentialsCmd.MarkFlagRequired("user")
 	updateCredentialsCmd.MarkFlagRequired("login")
-	updateCredentialsCmd.MarkFlagRequired("password")
 }
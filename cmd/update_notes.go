@@ -1,12 +1,9 @@
 package cmd
 
 import (
-	"fmt"
 	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
 	"github.com/ZnNr/GopherVault/internal/models"
 	"github.com/spf13/cobra"
-	"log"
-	"net/http"
 )
 
 // updateNotesCmd представляет команду updateNotes
@@ -20,7 +17,8 @@ var updateNotesCmd = &cobra.Command{
 // updateNoteHandler обработчик команды обновления заметки
 func updateNoteHandler(cmd *cobra.Command, args []string) {
 	cfg := cmdutil.LoadEnvVariables()
-	userName, title, content, metadata, _, _, _, _ := cmdutil.GetFlagsValues(cmd)
+	values := cmdutil.GetFlagsValues(cmd)
+	userName, title, content, metadata := values.UserName, values.Title, values.Content, values.Metadata
 
 	// Создаем объект заметки
 	requestNote := models.Note{
@@ -28,17 +26,15 @@ func updateNoteHandler(cmd *cobra.Command, args []string) {
 		Title:    &title,
 		Content:  &content,
 		Metadata: &metadata,
+		Fields:   createCustomFields(cmd),
 	}
 
 	body := cmdutil.ConvertToJSONRequestNotes(requestNote)
 
 	// Отправляем POST-запрос на сервер
-	resp, err := cmdutil.ExecutePostRequest(fmt.Sprintf("http://%s:%s/update/note", cfg.ApplicationHost, cfg.ApplicationPort), body)
-	if err != nil {
-		log.Printf(err.Error())
-	}
-
-	cmdutil.HandleResponse(resp, http.StatusOK)
+	revision, _ := cmd.Flags().GetInt64("revision")
+	showSecrets, _ := cmd.Flags().GetBool("show-secrets")
+	executeConditionalUpdate(cfg, userName, "/update/note", body, revision, showSecrets)
 }
 
 func init() {
@@ -48,6 +44,9 @@ func init() {
 	updateNotesCmd.Flags().String("title", "", "title of the note")
 	updateNotesCmd.Flags().String("content", "", "new note's content")
 	updateNotesCmd.Flags().String("metadata", "", "metadata")
+	addCustomFieldFlags(updateNotesCmd)
+	updateNotesCmd.Flags().Int64("revision", 0, "expected revision of the note; the update is rejected if it was changed since")
+	updateNotesCmd.Flags().Bool("show-secrets", false, "show secret values in the diff shown on a revision conflict")
 	updateNotesCmd.MarkFlagRequired("user")
 	updateNotesCmd.MarkFlagRequired("title")
 	updateNotesCmd.MarkFlagRequired("content")
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	cmdutil "github.com/ZnNr/GopherVault/cmdutils"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/syncclient"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// watchRetryMin начальная задержка переподключения к потоку событий
+	watchRetryMin = time.Second
+	// watchRetryMax максимальная задержка переподключения к потоку событий
+	watchRetryMax = 30 * time.Second
+)
+
+// watchCmd представляет команду watch
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Watch changes of secrets made on other devices",
+	Long: `Print events about created, updated and deleted credentials, notes and cards as soon as they happen.
+Events contain only the type, id and revision of a secret, secret values are never sent.
+With --sync the local encrypted cache is synchronized on every event, so get commands stay fresh when the server goes down.
+The stream is resumed from the last received event after a connection loss.`,
+	Example: "GopherVault watch --user <user-name>\nGopherVault watch --user <user-name> --sync",
+	Run:     watchHandler,
+}
+
+func watchHandler(cmd *cobra.Command, args []string) {
+	cfg := cmdutil.LoadEnvVariables()
+	userName, _ := cmd.Flags().GetString("user")
+	refresh, _ := cmd.Flags().GetBool("sync")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// С синхронизацией кэша поток начинается с последнего изменения, известного кэшу
+	var after *int64
+	handle := func(event models.Event) error {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		log.Println(string(data))
+		return nil
+	}
+	if refresh {
+		cache := openCache(cfg, userName)
+		if cache == nil {
+			log.Fatalln("локальный кэш не настроен: задайте ключ хранилища в переменной окружения KEEPER_CACHE_KEY")
+		}
+		if err := syncCache(cfg, userName, cache); err != nil {
+			log.Printf("ошибка синхронизации: %s", err)
+		}
+		seq := cache.Seq()
+		after = &seq
+		printEvent := handle
+		handle = func(event models.Event) error {
+			if err := printEvent(event); err != nil {
+				return err
+			}
+			// Изменения, уже полученные предыдущей синхронизацией, не требуют новой
+			if event.Action != models.ChangeReset && event.Seq <= cache.Seq() {
+				return nil
+			}
+			if err := syncCache(cfg, userName, cache); err != nil {
+				log.Printf("ошибка синхронизации: %s", err)
+			}
+			return nil
+		}
+	}
+
+	client := syncclient.New(serverURL(cfg, ""), userName)
+	log.Printf("ожидание изменений секретов пользователя %q, для завершения нажмите Ctrl+C", userName)
+	retry := watchRetryMin
+	for {
+		last, err := client.Watch(ctx, after, handle)
+		if ctx.Err() != nil {
+			return
+		}
+		// Задержка сбрасывается, если до разрыва соединения были получены события
+		if last != after {
+			retry = watchRetryMin
+		}
+		after = last
+		switch {
+		case errors.Is(err, syncclient.ErrUnavailable):
+			log.Printf("сервер недоступен, повторное подключение через %s", retry)
+		case err != nil:
+			log.Fatalln(err.Error())
+		default:
+			log.Printf("сервер закрыл поток событий, повторное подключение через %s", retry)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		retry = min(retry*2, watchRetryMax)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().String("user", "", "user name")
+	watchCmd.Flags().Bool("sync", false, "synchronize the local cache on every event")
+	watchCmd.MarkFlagRequired("user")
+}
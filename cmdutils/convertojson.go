@@ -37,3 +37,62 @@ func ConvertToJSONRequestUserCredential(requestCredential models.User) []byte {
 	}
 	return body
 }
+
+func ConvertToJSONRequestTOTP(requestTOTP models.TOTP) []byte {
+	body, err := json.Marshal(requestTOTP)
+	if err != nil {
+		log.Fatalf("ошибка при маршалинге запроса: %s", err.Error())
+	}
+	return body
+}
+
+func ConvertToJSONRequestSSHKey(requestKey models.SSHKey) []byte {
+	body, err := json.Marshal(requestKey)
+	if err != nil {
+		log.Fatalf("ошибка при маршалинге запроса: %s", err.Error())
+	}
+	return body
+}
+
+func ConvertToJSONRequestFolder(requestFolder models.Folder) []byte {
+	body, err := json.Marshal(requestFolder)
+	if err != nil {
+		log.Fatalf("ошибка при маршалинге запроса: %s", err.Error())
+	}
+	return body
+}
+
+func ConvertToJSONRequestTag(requestTag models.Tag) []byte {
+	body, err := json.Marshal(requestTag)
+	if err != nil {
+		log.Fatalf("ошибка при маршалинге запроса: %s", err.Error())
+	}
+	return body
+}
+
+// ConvertToJSONRequestSearch преобразует запрос поиска в JSON
+func ConvertToJSONRequestSearch(requestSearch models.SearchRequest) []byte {
+	body, err := json.Marshal(requestSearch)
+	if err != nil {
+		log.Fatalf("ошибка при маршалинге запроса: %s", err.Error())
+	}
+	return body
+}
+
+// ConvertToJSONRequestHistory преобразует запрос к истории секрета в JSON
+func ConvertToJSONRequestHistory(requestHistory models.HistoryRequest) []byte {
+	body, err := json.Marshal(requestHistory)
+	if err != nil {
+		log.Fatalf("ошибка при маршалинге запроса: %s", err.Error())
+	}
+	return body
+}
+
+// ConvertToJSONRequestTrash преобразует запрос к корзине в JSON
+func ConvertToJSONRequestTrash(requestTrash models.TrashRequest) []byte {
+	body, err := json.Marshal(requestTrash)
+	if err != nil {
+		log.Fatalf("ошибка при маршалинге запроса: %s", err.Error())
+	}
+	return body
+}
@@ -1,6 +1,7 @@
 package cmdutil
 
 import (
+	"github.com/ZnNr/GopherVault/internal/models"
 	"github.com/go-resty/resty/v2"
 	"log"
 )
@@ -13,6 +14,25 @@ func ExecutePostRequest(url string, body []byte) (*resty.Response, error) {
 	return resp, err
 }
 
+// ExecuteConditionalPostRequest отправляет POST-запрос с заголовком If-Match, если указана ожидаемая ревизия секрета
+func ExecuteConditionalPostRequest(url string, body []byte, revision int64) (*resty.Response, error) {
+	req := resty.New().R().
+		SetHeader("Content-type", "application/json").
+		SetBody(body)
+	if revision != 0 {
+		req.SetHeader("If-Match", models.ETag(revision))
+	}
+	return req.Post(url)
+}
+
+func ExecuteGetRequest(url string, body []byte) (*resty.Response, error) {
+	resp, err := resty.New().SetAllowGetMethodPayload(true).R().
+		SetHeader("Content-type", "application/json").
+		SetBody(body).
+		Get(url)
+	return resp, err
+}
+
 func HandleResponse(resp *resty.Response, expectedCode int) {
 	if resp.StatusCode() != expectedCode {
 		log.Printf("некорректный статус код: %s\n", resp.Status())
@@ -1,17 +1,141 @@
 package cmdutil
 
 import (
+	"log"
+	"os"
+	"strings"
+
 	"github.com/spf13/cobra"
 )
 
-func GetFlagsValues(cmd *cobra.Command) (userName, bank, number, cv, login, password, сardType, metadata string) {
-	userName, _ = cmd.Flags().GetString("user")
-	bank, _ = cmd.Flags().GetString("bank")
-	number, _ = cmd.Flags().GetString("number")
-	cv, _ = cmd.Flags().GetString("cv")
-	login, _ = cmd.Flags().GetString("login")
-	password, _ = cmd.Flags().GetString("password")
-	сardType, _ = cmd.Flags().GetString("type")
-	metadata, _ = cmd.Flags().GetString("metadata")
-	return
+// confirmAnnotationPrefix префикс аннотации команды с приглашением повторного ввода секрета.
+// Запрошенный в терминале секрет с такой аннотацией вводится дважды.
+const confirmAnnotationPrefix = "confirm-"
+
+// FlagsValues значения общих флагов команд. Значения флагов, которых у команды нет, остаются пустыми.
+type FlagsValues struct {
+	UserName string
+	Login    string
+	Password string
+	Bank     string
+	Number   string
+	CV       string
+	CardType string
+	Title    string
+	Content  string
+	Metadata string
+}
+
+// AddPasswordFlags добавляет команде флаги источников пароля: --password, --password-stdin и --password-file.
+// Если ни один из них не указан, GetFlagsValues запрашивает пароль в терминале без отображения ввода;
+// при confirm пароль вводится дважды.
+func AddPasswordFlags(cmd *cobra.Command, usage string, confirm bool) {
+	confirmPrompt := ""
+	if confirm {
+		confirmPrompt = "Повторите пароль: "
+	}
+	AddSecretFlags(cmd, "password", usage, confirmPrompt)
+}
+
+// AddSecretFlags добавляет команде флаги источников секрета name: --<name>, --<name>-stdin и --<name>-file.
+// Если ни один из них не указан, SecretValue запрашивает секрет в терминале без отображения ввода;
+// с непустым confirmPrompt секрет вводится дважды.
+func AddSecretFlags(cmd *cobra.Command, name, usage, confirmPrompt string) {
+	cmd.Flags().String(name, "", usage+" (insecure: visible in shell history and ps, omit to be prompted)")
+	cmd.Flags().Bool(name+"-stdin", false, "read the "+usage+" from the first line of stdin")
+	cmd.Flags().String(name+"-file", "", "read the "+usage+" from the first line of the file")
+	cmd.MarkFlagsMutuallyExclusive(name, name+"-stdin", name+"-file")
+	if confirmPrompt != "" {
+		if cmd.Annotations == nil {
+			cmd.Annotations = map[string]string{}
+		}
+		cmd.Annotations[confirmAnnotationPrefix+name] = confirmPrompt
+	}
+}
+
+// SecretFlagsChanged проверяет, указан ли хотя бы один из флагов источников секрета name
+func SecretFlagsChanged(cmd *cobra.Command, name string) bool {
+	flags := cmd.Flags()
+	return flags.Changed(name) || flags.Changed(name+"-stdin") || flags.Changed(name+"-file")
+}
+
+// GetFlagsValues возвращает значения общих флагов команды.
+//
+// Пароль команд с флагами AddPasswordFlags берется из --password, --password-stdin или --password-file, а если
+// они не указаны, запрашивается в терминале. С флагом --generate пароль не запрашивается: его генерирует команда.
+// CV карт с флагами AddSecretFlags(cmd, "cv", ...) так же берется из --cv, --cv-stdin или --cv-file, а если они
+// не указаны, запрашивается в терминале. Секреты в аргументах командной строки видны в истории команд и списке
+// процессов, поэтому при их использовании выводится предупреждение.
+func GetFlagsValues(cmd *cobra.Command) FlagsValues {
+	flags := cmd.Flags()
+	var v FlagsValues
+	v.UserName, _ = flags.GetString("user")
+	v.Login, _ = flags.GetString("login")
+	v.Bank, _ = flags.GetString("bank")
+	v.Number, _ = flags.GetString("number")
+	v.CardType, _ = flags.GetString("type")
+	v.Title, _ = flags.GetString("title")
+	v.Content, _ = flags.GetString("content")
+	v.Metadata, _ = flags.GetString("metadata")
+	if flags.Lookup("password-stdin") != nil {
+		v.Password = passwordValue(cmd)
+	}
+	if flags.Lookup("cv-stdin") != nil {
+		v.CV = strings.TrimSpace(SecretValue(cmd, "cv", "CV карты: "))
+	}
+	return v
+}
+
+// passwordValue читает пароль из источника, указанного флагами AddPasswordFlags, или запрашивает его в терминале
+func passwordValue(cmd *cobra.Command) string {
+	flags := cmd.Flags()
+	// Сгенерированный пароль не запрашивается, а пароль из флага вернется для проверки несовместимости флагов
+	if generate, _ := flags.GetBool("generate"); generate && !flags.Changed("password-stdin") && !flags.Changed("password-file") {
+		password, _ := flags.GetString("password")
+		return password
+	}
+	return SecretValue(cmd, "password", "Пароль: ")
+}
+
+// SecretValue читает секрет name из источника, указанного флагами AddSecretFlags, или запрашивает его
+// в терминале с приглашением prompt
+func SecretValue(cmd *cobra.Command, name, prompt string) string {
+	flags := cmd.Flags()
+	if fromStdin, _ := flags.GetBool(name + "-stdin"); fromStdin {
+		value, err := ReadLine(os.Stdin)
+		if err != nil {
+			log.Fatalf("ошибка при чтении --%s из стандартного ввода: %s", name, err)
+		}
+		return value
+	}
+	if path, _ := flags.GetString(name + "-file"); path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Fatalf("ошибка при чтении файла --%s-file: %s", name, err)
+		}
+		defer file.Close()
+		value, err := ReadLine(file)
+		if err != nil {
+			log.Fatalf("ошибка при чтении файла --%s-file: %s", name, err)
+		}
+		return value
+	}
+	value := secretFlagValue(cmd, name, prompt)
+	if confirmPrompt := cmd.Annotations[confirmAnnotationPrefix+name]; confirmPrompt != "" && !flags.Changed(name) && stdinIsTerminal() {
+		if ReadPassword(confirmPrompt) != value {
+			log.Fatalln("введенные значения не совпадают")
+		}
+	}
+	return value
+}
+
+// secretFlagValue возвращает значение секретного флага с предупреждением об утечке или запрашивает секрет в терминале
+func secretFlagValue(cmd *cobra.Command, name, prompt string) string {
+	if cmd.Flags().Changed(name) {
+		log.Printf("ВНИМАНИЕ: значение --%s видно в истории команд и списке процессов, "+
+			"не указывайте флаг, чтобы ввести его в терминале", name)
+		value, _ := cmd.Flags().GetString(name)
+		return value
+	}
+	return ReadPassword(prompt)
 }
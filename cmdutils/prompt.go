@@ -0,0 +1,67 @@
+package cmdutil
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// stdinReader буферизованный стандартный ввод. Общий для всех чтений, чтобы строки, прочитанные в буфер
+// при запросе одного секрета, достались следующему запросу.
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// stdinIsTerminal проверяет, что стандартный ввод является терминалом
+func stdinIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// ReadPassword запрашивает пароль в терминале без отображения вводимых символов.
+// Если стандартный ввод не является терминалом, пароль читается из очередной строки ввода.
+func ReadPassword(prompt string) string {
+	fmt.Fprint(os.Stderr, prompt)
+	if stdinIsTerminal() {
+		password, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			log.Fatalf("ошибка при чтении пароля: %s", err)
+		}
+		return string(password)
+	}
+	password, err := ReadLine(os.Stdin)
+	if err != nil {
+		log.Fatalf("ошибка при чтении пароля: %s", err)
+	}
+	return password
+}
+
+// ReadLine читает из r одну строку без символов перевода строки. Стандартный ввод читается через общий буфер.
+func ReadLine(r io.Reader) (string, error) {
+	reader := stdinReader
+	if r != os.Stdin {
+		reader = bufio.NewReader(r)
+	}
+	line, err := reader.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// Confirm задает вопрос пользователю и возвращает true, если он ответил утвердительно.
+// Ответ читается из общего буфера стандартного ввода, поэтому не теряется после чтения секретов из stdin.
+func Confirm(question string) bool {
+	fmt.Printf("%s [y/N]: ", question)
+	answer, _ := ReadLine(os.Stdin)
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes", "д", "да":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,14 @@
+drop index if exists cards_user_name_idx;
+alter table cards drop constraint if exists cards_user_name_fkey;
+alter table cards drop constraint if exists cards_user_number_key;
+
+drop index if exists notes_user_name_idx;
+alter table notes drop constraint if exists notes_user_name_fkey;
+alter table notes drop constraint if exists notes_user_title_key;
+
+drop index if exists credentials_user_name_idx;
+alter table credentials drop constraint if exists credentials_user_name_fkey;
+alter table credentials drop constraint if exists credentials_user_login_site_key;
+alter table credentials drop column if exists site;
+alter table credentials add constraint credentials_user_name_key unique (user_name);
+alter table credentials add constraint credentials_login_key unique (login);
@@ -0,0 +1,14 @@
+alter table credentials drop constraint if exists credentials_user_name_key;
+alter table credentials drop constraint if exists credentials_login_key;
+alter table credentials add column if not exists site TEXT NOT NULL DEFAULT '';
+alter table credentials add constraint credentials_user_login_site_key unique (user_name, login, site);
+alter table credentials add constraint credentials_user_name_fkey foreign key (user_name) references registered_users (login) on delete cascade;
+create index if not exists credentials_user_name_idx on credentials (user_name);
+
+alter table notes add constraint notes_user_title_key unique (user_name, title);
+alter table notes add constraint notes_user_name_fkey foreign key (user_name) references registered_users (login) on delete cascade;
+create index if not exists notes_user_name_idx on notes (user_name);
+
+alter table cards add constraint cards_user_number_key unique (user_name, number);
+alter table cards add constraint cards_user_name_fkey foreign key (user_name) references registered_users (login) on delete cascade;
+create index if not exists cards_user_name_idx on cards (user_name);
@@ -0,0 +1,2 @@
+alter table credentials drop column if exists urls;
+alter table credentials drop column if exists name;
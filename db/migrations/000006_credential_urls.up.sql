@@ -0,0 +1,2 @@
+alter table credentials add column if not exists name TEXT;
+alter table credentials add column if not exists urls JSONB;
@@ -0,0 +1 @@
+drop table totp;
\ No newline at end of file
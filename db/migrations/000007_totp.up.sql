@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS totp (
+                                    id SERIAL PRIMARY KEY,
+                                    user_name TEXT NOT NULL REFERENCES registered_users (login) ON DELETE CASCADE,
+                                    name TEXT NOT NULL,
+                                    uri TEXT NOT NULL,
+                                    credential_id INTEGER REFERENCES credentials (id) ON DELETE SET NULL,
+                                    metadata TEXT,
+                                    CONSTRAINT totp_user_name_key UNIQUE (user_name, name)
+);
+CREATE INDEX IF NOT EXISTS totp_user_name_idx ON totp (user_name);
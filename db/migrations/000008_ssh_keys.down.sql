@@ -0,0 +1 @@
+drop table ssh_keys;
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS ssh_keys (
+                                        id SERIAL PRIMARY KEY,
+                                        user_name TEXT NOT NULL REFERENCES registered_users (login) ON DELETE CASCADE,
+                                        name TEXT NOT NULL,
+                                        private_key TEXT NOT NULL,
+                                        passphrase TEXT,
+                                        public_key TEXT NOT NULL,
+                                        fingerprint TEXT NOT NULL,
+                                        key_type TEXT NOT NULL,
+                                        metadata TEXT,
+                                        CONSTRAINT ssh_keys_user_name_key UNIQUE (user_name, name)
+);
+CREATE INDEX IF NOT EXISTS ssh_keys_user_name_idx ON ssh_keys (user_name);
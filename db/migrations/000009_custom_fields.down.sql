@@ -0,0 +1,3 @@
+alter table cards drop column if exists fields;
+alter table notes drop column if exists fields;
+alter table credentials drop column if exists fields;
@@ -0,0 +1,3 @@
+alter table credentials add column if not exists fields JSONB;
+alter table notes add column if not exists fields JSONB;
+alter table cards add column if not exists fields JSONB;
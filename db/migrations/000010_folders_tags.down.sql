@@ -0,0 +1,8 @@
+drop table if exists card_tags;
+drop table if exists note_tags;
+drop table if exists credential_tags;
+alter table cards drop column if exists folder_id;
+alter table notes drop column if exists folder_id;
+alter table credentials drop column if exists folder_id;
+drop table if exists tags;
+drop table if exists folders;
@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS folders (
+                                       id SERIAL PRIMARY KEY,
+                                       user_name TEXT NOT NULL REFERENCES registered_users (login) ON DELETE CASCADE,
+                                       name TEXT NOT NULL,
+                                       parent_id INTEGER REFERENCES folders (id) ON DELETE CASCADE,
+                                       path TEXT NOT NULL,
+                                       CONSTRAINT folders_user_path_key UNIQUE (user_name, path)
+);
+CREATE INDEX IF NOT EXISTS folders_parent_id_idx ON folders (parent_id);
+
+CREATE TABLE IF NOT EXISTS tags (
+                                    id SERIAL PRIMARY KEY,
+                                    user_name TEXT NOT NULL REFERENCES registered_users (login) ON DELETE CASCADE,
+                                    name TEXT NOT NULL,
+                                    CONSTRAINT tags_user_name_key UNIQUE (user_name, name)
+);
+
+alter table credentials add column if not exists folder_id INTEGER REFERENCES folders (id) ON DELETE SET NULL;
+alter table notes add column if not exists folder_id INTEGER REFERENCES folders (id) ON DELETE SET NULL;
+alter table cards add column if not exists folder_id INTEGER REFERENCES folders (id) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS credential_tags (
+                                               credential_id INTEGER NOT NULL REFERENCES credentials (id) ON DELETE CASCADE,
+                                               tag_id INTEGER NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+                                               PRIMARY KEY (credential_id, tag_id)
+);
+CREATE TABLE IF NOT EXISTS note_tags (
+                                         note_id INTEGER NOT NULL REFERENCES notes (id) ON DELETE CASCADE,
+                                         tag_id INTEGER NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+                                         PRIMARY KEY (note_id, tag_id)
+);
+CREATE TABLE IF NOT EXISTS card_tags (
+                                         card_id INTEGER NOT NULL REFERENCES cards (id) ON DELETE CASCADE,
+                                         tag_id INTEGER NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+                                         PRIMARY KEY (card_id, tag_id)
+);
@@ -0,0 +1,2 @@
+drop index if exists notes_search_tokens_idx;
+alter table notes drop column if exists search_tokens;
@@ -0,0 +1,2 @@
+alter table notes add column if not exists search_tokens text[];
+create index if not exists notes_search_tokens_idx on notes using gin (search_tokens);
@@ -0,0 +1,10 @@
+alter table ssh_keys drop column if exists updated_at;
+alter table ssh_keys drop column if exists created_at;
+alter table totp drop column if exists updated_at;
+alter table totp drop column if exists created_at;
+alter table cards drop column if exists updated_at;
+alter table cards drop column if exists created_at;
+alter table notes drop column if exists updated_at;
+alter table notes drop column if exists created_at;
+alter table credentials drop column if exists updated_at;
+alter table credentials drop column if exists created_at;
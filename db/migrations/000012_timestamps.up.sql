@@ -0,0 +1,10 @@
+alter table credentials add column if not exists created_at timestamptz not null default now();
+alter table credentials add column if not exists updated_at timestamptz not null default now();
+alter table notes add column if not exists created_at timestamptz not null default now();
+alter table notes add column if not exists updated_at timestamptz not null default now();
+alter table cards add column if not exists created_at timestamptz not null default now();
+alter table cards add column if not exists updated_at timestamptz not null default now();
+alter table totp add column if not exists created_at timestamptz not null default now();
+alter table totp add column if not exists updated_at timestamptz not null default now();
+alter table ssh_keys add column if not exists created_at timestamptz not null default now();
+alter table ssh_keys add column if not exists updated_at timestamptz not null default now();
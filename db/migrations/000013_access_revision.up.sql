@@ -0,0 +1,10 @@
+alter table credentials add column if not exists last_accessed_at timestamptz;
+alter table credentials add column if not exists revision bigint not null default 1;
+alter table notes add column if not exists last_accessed_at timestamptz;
+alter table notes add column if not exists revision bigint not null default 1;
+alter table cards add column if not exists last_accessed_at timestamptz;
+alter table cards add column if not exists revision bigint not null default 1;
+alter table totp add column if not exists last_accessed_at timestamptz;
+alter table totp add column if not exists revision bigint not null default 1;
+alter table ssh_keys add column if not exists last_accessed_at timestamptz;
+alter table ssh_keys add column if not exists revision bigint not null default 1;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS secret_history (
+                                              id SERIAL PRIMARY KEY,
+                                              user_name TEXT NOT NULL REFERENCES registered_users (login) ON DELETE CASCADE,
+                                              secret_type TEXT NOT NULL,
+                                              secret_id INTEGER NOT NULL,
+                                              revision BIGINT NOT NULL,
+                                              data TEXT NOT NULL,
+                                              changes TEXT[],
+                                              changed_by TEXT NOT NULL,
+                                              changed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                                              CONSTRAINT secret_history_revision_key UNIQUE (secret_type, secret_id, revision)
+);
+CREATE INDEX IF NOT EXISTS secret_history_user_name_idx ON secret_history (user_name);
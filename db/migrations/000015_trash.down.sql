@@ -0,0 +1,14 @@
+delete from cards where deleted_at is not null;
+drop index if exists cards_user_number_key;
+alter table cards add constraint cards_user_number_key unique (user_name, number);
+alter table cards drop column if exists deleted_at;
+
+delete from notes where deleted_at is not null;
+drop index if exists notes_user_title_key;
+alter table notes add constraint notes_user_title_key unique (user_name, title);
+alter table notes drop column if exists deleted_at;
+
+delete from credentials where deleted_at is not null;
+drop index if exists credentials_user_login_site_key;
+alter table credentials add constraint credentials_user_login_site_key unique (user_name, login, site);
+alter table credentials drop column if exists deleted_at;
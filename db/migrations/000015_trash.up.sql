@@ -0,0 +1,11 @@
+alter table credentials add column if not exists deleted_at timestamptz;
+alter table credentials drop constraint if exists credentials_user_login_site_key;
+create unique index if not exists credentials_user_login_site_key on credentials (user_name, login, site) where deleted_at is null;
+
+alter table notes add column if not exists deleted_at timestamptz;
+alter table notes drop constraint if exists notes_user_title_key;
+create unique index if not exists notes_user_title_key on notes (user_name, title) where deleted_at is null;
+
+alter table cards add column if not exists deleted_at timestamptz;
+alter table cards drop constraint if exists cards_user_number_key;
+create unique index if not exists cards_user_number_key on cards (user_name, number) where deleted_at is null;
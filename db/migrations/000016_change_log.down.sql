@@ -0,0 +1,10 @@
+drop trigger if exists cards_continue_revision on cards;
+drop trigger if exists notes_continue_revision on notes;
+drop trigger if exists credentials_continue_revision on credentials;
+drop function if exists continue_secret_revision();
+drop trigger if exists cards_change_log on cards;
+drop trigger if exists notes_change_log on notes;
+drop trigger if exists credentials_change_log on credentials;
+drop function if exists log_secret_change();
+drop table if exists change_log;
+drop table if exists sync_sequences;
@@ -0,0 +1,138 @@
+CREATE TABLE IF NOT EXISTS sync_sequences (
+                                              user_name TEXT PRIMARY KEY REFERENCES registered_users (login) ON DELETE CASCADE,
+                                              seq BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS change_log (
+                                          user_name TEXT NOT NULL REFERENCES registered_users (login) ON DELETE CASCADE,
+                                          seq BIGINT NOT NULL,
+                                          secret_type TEXT NOT NULL,
+                                          secret_id INTEGER NOT NULL,
+                                          revision BIGINT NOT NULL,
+                                          action TEXT NOT NULL,
+                                          changed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                                          PRIMARY KEY (user_name, seq)
+);
+CREATE INDEX IF NOT EXISTS change_log_secret_idx ON change_log (user_name, secret_type, secret_id, seq);
+
+-- Секреты, сохраненные до появления журнала, записываются в него как созданные
+insert into change_log (user_name, seq, secret_type, secret_id, revision, action, changed_at)
+select user_name,
+       row_number() over (partition by user_name order by changed_at, secret_type, id),
+       secret_type, id, revision, 'created', changed_at
+from (select user_name, 'credentials' as secret_type, id, revision, updated_at as changed_at from credentials where deleted_at is null
+      union all
+      select user_name, 'note', id, revision, updated_at from notes where deleted_at is null
+      union all
+      select user_name, 'card', id, revision, updated_at from cards where deleted_at is null) s;
+
+insert into sync_sequences (user_name, seq)
+select user_name, max(seq) from change_log group by user_name;
+
+-- log_secret_change записывает изменение секрета в журнал пользователя под следующим номером.
+-- Обновление только времени последнего чтения или поискового индекса и изменения секретов в корзине в журнал не попадают.
+create or replace function log_secret_change() returns trigger as
+$$
+declare
+    secret   record;
+    act      TEXT;
+    next_seq BIGINT;
+begin
+    if tg_op = 'INSERT' then
+        secret := new;
+        act := 'created';
+    elsif tg_op = 'DELETE' then
+        -- секрет из корзины уже отмечен удаленным, а при удалении пользователя журнал удаляется вместе с ним
+        if old.deleted_at is not null or not exists (select 1 from registered_users where login = old.user_name) then
+            return null;
+        end if;
+        secret := old;
+        act := 'deleted';
+    else
+        secret := new;
+        if old.deleted_at is null and new.deleted_at is not null then
+            act := 'deleted';
+        elsif old.deleted_at is not null and new.deleted_at is null then
+            act := 'created';
+        elsif new.deleted_at is not null or (to_jsonb(new) - 'last_accessed_at' - 'search_tokens') = (to_jsonb(old) - 'last_accessed_at' - 'search_tokens') then
+            return null;
+        else
+            act := 'updated';
+        end if;
+    end if;
+
+    insert into sync_sequences (user_name, seq)
+    values (secret.user_name, 1)
+    on conflict (user_name) do update set seq = sync_sequences.seq + 1
+    returning seq into next_seq;
+
+    insert into change_log (user_name, seq, secret_type, secret_id, revision, action)
+    values (secret.user_name, next_seq, tg_argv[0], secret.id, secret.revision, act);
+    return null;
+end;
+$$ language plpgsql;
+
+drop trigger if exists credentials_change_log on credentials;
+create trigger credentials_change_log
+    after insert or update or delete
+    on credentials
+    for each row
+execute function log_secret_change('credentials');
+
+drop trigger if exists notes_change_log on notes;
+create trigger notes_change_log
+    after insert or update or delete
+    on notes
+    for each row
+execute function log_secret_change('note');
+
+drop trigger if exists cards_change_log on cards;
+create trigger cards_change_log
+    after insert or update or delete
+    on cards
+    for each row
+execute function log_secret_change('card');
+
+-- continue_secret_revision продолжает ревизии секрета, созданного заново после удаления, с ревизии удаленной версии.
+-- Иначе ревизия, известная устройству до удаления, совпала бы с ревизией нового секрета и изменение
+-- с этого устройства перезаписало бы его без конфликта.
+create or replace function continue_secret_revision() returns trigger as
+$$
+declare
+    previous BIGINT;
+begin
+    if tg_table_name = 'credentials' then
+        select max(revision) into previous from credentials
+        where user_name = new.user_name and login = new.login and site = new.site and deleted_at is not null;
+    elsif tg_table_name = 'notes' then
+        select max(revision) into previous from notes
+        where user_name = new.user_name and title = new.title and deleted_at is not null;
+    else
+        select max(revision) into previous from cards
+        where user_name = new.user_name and number = new.number and deleted_at is not null;
+    end if;
+    new.revision := coalesce(previous, 0) + 1;
+    return new;
+end;
+$$ language plpgsql;
+
+drop trigger if exists credentials_continue_revision on credentials;
+create trigger credentials_continue_revision
+    before insert
+    on credentials
+    for each row
+execute function continue_secret_revision();
+
+drop trigger if exists notes_continue_revision on notes;
+create trigger notes_continue_revision
+    before insert
+    on notes
+    for each row
+execute function continue_secret_revision();
+
+drop trigger if exists cards_continue_revision on cards;
+create trigger cards_continue_revision
+    before insert
+    on cards
+    for each row
+execute function continue_secret_revision();
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+                                         id SERIAL PRIMARY KEY,
+                                         user_name TEXT NOT NULL REFERENCES registered_users (login) ON DELETE CASCADE,
+                                         event TEXT NOT NULL,
+                                         details TEXT,
+                                         created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS audit_log_user_idx ON audit_log (user_name, created_at);
@@ -0,0 +1,326 @@
+// Package backupfile записывает и читает архив резервной копии сервера.
+//
+// Архив - поток строк JSON, сжатый gzip: заголовок, затем для каждой таблицы строка с ее названием и строки
+// таблицы, в конце - итоговая строка с числом строк и SHA-256 каждой таблицы и SHA-256 всего потока до нее.
+// Итоговая строка обнаруживает повреждение и обрезку архива.
+//
+// Архив можно зашифровать открытым ключом оператора X25519. Формат зашифрованного архива: заголовок "GVB1",
+// открытый ключ отправителя и блоки AES-256-GCM по 64 КиБ. Ключ блоков получается из общего секрета X25519
+// с помощью HKDF-SHA256, последний блок помечается в nonce, поэтому обрезка архива обнаруживается при расшифровке.
+package backupfile
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/ecdh"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+)
+
+// Format название формата архива в заголовке
+const Format = "gophervault-backup"
+
+// Version версия формата архива
+const Version = 1
+
+var (
+	// ErrUnsupportedFormat означает, что файл не является резервной копией GopherVault
+	ErrUnsupportedFormat = errors.New("unsupported backup format")
+	// ErrCorrupted означает, что архив поврежден или обрезан
+	ErrCorrupted = errors.New("backup is corrupted")
+	// ErrEncrypted означает, что архив зашифрован, а закрытый ключ оператора не указан
+	ErrEncrypted = errors.New("backup is encrypted")
+	// ErrWrongIdentity означает, что архив или ключ зашифрованы для другого ключа оператора
+	ErrWrongIdentity = errors.New("backup is encrypted for another operator key")
+)
+
+// Header описывает резервную копию
+type Header struct {
+	Format         string    `json:"format"`
+	Version        int       `json:"version"`
+	SchemaVersion  int64     `json:"schema_version"`  // Версия миграций базы данных
+	CreatedAt      time.Time `json:"created_at"`      // Время начала транзакции, в которой сделана копия
+	KeyFingerprint string    `json:"key_fingerprint"` // Отпечаток ключа шифрования данных из Fingerprint
+	WrappedKey     []byte    `json:"wrapped_key,omitempty"`
+	Tables         []string  `json:"tables"` // Таблицы в порядке записи
+}
+
+// TableSummary описывает таблицу в итоговой строке архива
+type TableSummary struct {
+	Name   string `json:"name"`
+	Rows   int64  `json:"rows"`
+	SHA256 string `json:"sha256"` // SHA-256 строк таблицы в архиве
+}
+
+// trailer итоговая строка архива
+type trailer struct {
+	Tables []TableSummary `json:"tables"`
+	SHA256 string         `json:"sha256"` // SHA-256 всех строк архива до итоговой
+}
+
+// record строка архива: заголовок, начало таблицы, строка таблицы или итоговая строка
+type record struct {
+	Header  *Header         `json:"header,omitempty"`
+	Table   string          `json:"table,omitempty"`
+	Row     json.RawMessage `json:"row,omitempty"`
+	Trailer *trailer        `json:"trailer,omitempty"`
+}
+
+// Fingerprint возвращает отпечаток ключа шифрования данных. По отпечатку восстановление проверяет,
+// что сервер использует тот же ключ, которым зашифрованы секреты в резервной копии.
+func Fingerprint(key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("gophervault backup key fingerprint"))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// Writer записывает архив резервной копии
+type Writer struct {
+	closers []io.Closer // Потоки, закрываемые при завершении архива: gzip и шифрование
+	out     io.Writer
+	total   hash.Hash
+	table   hash.Hash
+	tables  []TableSummary
+}
+
+// NewWriter начинает архив с заголовком header. Если recipient не nil, архив шифруется для этого ключа оператора.
+func NewWriter(w io.Writer, header Header, recipient *ecdh.PublicKey) (*Writer, error) {
+	bw := &Writer{total: sha256.New()}
+	if recipient != nil {
+		ew, err := newEncryptWriter(w, recipient)
+		if err != nil {
+			return nil, err
+		}
+		bw.closers = append(bw.closers, ew)
+		w = ew
+	}
+	zw := gzip.NewWriter(w)
+	bw.closers = append([]io.Closer{zw}, bw.closers...)
+	bw.out = zw
+
+	header.Format, header.Version = Format, Version
+	if err := bw.write(record{Header: &header}); err != nil {
+		return nil, err
+	}
+	return bw, nil
+}
+
+// BeginTable начинает запись строк таблицы name
+func (w *Writer) BeginTable(name string) error {
+	w.finishTable()
+	w.tables = append(w.tables, TableSummary{Name: name})
+	w.table = sha256.New()
+	return w.write(record{Table: name})
+}
+
+// WriteRow записывает строку текущей таблицы в виде объекта JSON
+func (w *Writer) WriteRow(row []byte) error {
+	if w.table == nil {
+		return errors.New("строка записывается до начала таблицы")
+	}
+	w.tables[len(w.tables)-1].Rows++
+	return w.write(record{Row: row})
+}
+
+// Close записывает итоговую строку и завершает архив. Возвращает сведения о записанных таблицах.
+func (w *Writer) Close() ([]TableSummary, error) {
+	w.finishTable()
+	t := trailer{Tables: w.tables, SHA256: hex.EncodeToString(w.total.Sum(nil))}
+	if err := w.write(record{Trailer: &t}); err != nil {
+		return nil, err
+	}
+	for _, c := range w.closers {
+		if err := c.Close(); err != nil {
+			return nil, fmt.Errorf("ошибка при завершении архива: %w", err)
+		}
+	}
+	return w.tables, nil
+}
+
+// finishTable сохраняет SHA-256 текущей таблицы
+func (w *Writer) finishTable() {
+	if w.table != nil {
+		w.tables[len(w.tables)-1].SHA256 = hex.EncodeToString(w.table.Sum(nil))
+		w.table = nil
+	}
+}
+
+// write записывает строку архива и учитывает ее в контрольных суммах
+func (w *Writer) write(rec record) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("ошибка при записи архива: %w", err)
+	}
+	line = append(line, '\n')
+	if rec.Trailer == nil {
+		w.total.Write(line)
+	}
+	if rec.Row != nil {
+		w.table.Write(line)
+	}
+	if _, err = w.out.Write(line); err != nil {
+		return fmt.Errorf("ошибка при записи архива: %w", err)
+	}
+	return nil
+}
+
+// Reader читает архив резервной копии и проверяет его контрольные суммы
+type Reader struct {
+	in      *bufio.Reader
+	header  Header
+	total   hash.Hash
+	table   hash.Hash
+	current *TableSummary
+	tables  []TableSummary
+	done    bool
+}
+
+// NewReader открывает архив и читает его заголовок. Для зашифрованного архива нужен закрытый ключ оператора identity.
+func NewReader(r io.Reader, identity *ecdh.PrivateKey) (*Reader, error) {
+	br := bufio.NewReader(r)
+	prefix, err := br.Peek(len(magic))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+	var in io.Reader = br
+	if bytes.Equal(prefix, magic) {
+		if identity == nil {
+			return nil, ErrEncrypted
+		}
+		if in, err = newDecryptReader(br, identity); err != nil {
+			return nil, err
+		}
+	}
+	zr, err := gzip.NewReader(in)
+	if err != nil {
+		if errors.Is(err, gzip.ErrHeader) {
+			return nil, ErrUnsupportedFormat
+		}
+		if errors.Is(err, ErrWrongIdentity) || errors.Is(err, ErrCorrupted) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %s", ErrCorrupted, err)
+	}
+
+	reader := &Reader{in: bufio.NewReader(zr), total: sha256.New()}
+	rec, line, err := reader.read()
+	if err != nil {
+		return nil, err
+	}
+	if rec.Header == nil || rec.Header.Format != Format {
+		return nil, ErrUnsupportedFormat
+	}
+	if rec.Header.Version != Version {
+		return nil, fmt.Errorf("%w: версия архива %d", ErrUnsupportedFormat, rec.Header.Version)
+	}
+	reader.total.Write(line)
+	reader.header = *rec.Header
+	return reader, nil
+}
+
+// Header возвращает заголовок архива
+func (r *Reader) Header() Header {
+	return r.header
+}
+
+// Next возвращает следующую строку архива и название ее таблицы. После последней строки проверяет
+// контрольные суммы и возвращает io.EOF, если архив не поврежден.
+func (r *Reader) Next() (string, []byte, error) {
+	if r.done {
+		return "", nil, io.EOF
+	}
+	for {
+		rec, line, err := r.read()
+		if err != nil {
+			return "", nil, err
+		}
+		switch {
+		case rec.Trailer != nil:
+			r.finishTable()
+			if err = r.verify(*rec.Trailer); err != nil {
+				return "", nil, err
+			}
+			r.done = true
+			return "", nil, io.EOF
+		case rec.Table != "":
+			r.finishTable()
+			r.total.Write(line)
+			r.tables = append(r.tables, TableSummary{Name: rec.Table})
+			r.current = &r.tables[len(r.tables)-1]
+			r.table = sha256.New()
+		case rec.Row != nil:
+			if r.current == nil {
+				return "", nil, fmt.Errorf("%w: строка вне таблицы", ErrCorrupted)
+			}
+			r.total.Write(line)
+			r.table.Write(line)
+			r.current.Rows++
+			return r.current.Name, rec.Row, nil
+		default:
+			return "", nil, fmt.Errorf("%w: неизвестная строка архива", ErrCorrupted)
+		}
+	}
+}
+
+// Tables возвращает сведения о прочитанных таблицах
+func (r *Reader) Tables() []TableSummary {
+	return r.tables
+}
+
+// finishTable сохраняет SHA-256 текущей таблицы
+func (r *Reader) finishTable() {
+	if r.current != nil {
+		r.current.SHA256 = hex.EncodeToString(r.table.Sum(nil))
+		r.current, r.table = nil, nil
+	}
+}
+
+// verify сравнивает прочитанные таблицы и контрольные суммы с итоговой строкой архива
+func (r *Reader) verify(t trailer) error {
+	if hex.EncodeToString(r.total.Sum(nil)) != t.SHA256 {
+		return fmt.Errorf("%w: контрольная сумма архива не совпадает", ErrCorrupted)
+	}
+	if len(t.Tables) != len(r.tables) {
+		return fmt.Errorf("%w: в архиве %d таблиц вместо %d", ErrCorrupted, len(r.tables), len(t.Tables))
+	}
+	for i, table := range t.Tables {
+		if table != r.tables[i] {
+			return fmt.Errorf("%w: строки таблицы %q не совпадают с итоговой строкой", ErrCorrupted, table.Name)
+		}
+	}
+	// Проверяем, что за итоговой строкой ничего нет, а поток gzip завершен без ошибок
+	if _, err := r.in.ReadByte(); err != io.EOF {
+		if err == nil {
+			return fmt.Errorf("%w: данные после итоговой строки", ErrCorrupted)
+		}
+		return fmt.Errorf("%w: %s", ErrCorrupted, err)
+	}
+	return nil
+}
+
+// read читает и разбирает строку архива
+func (r *Reader) read() (record, []byte, error) {
+	line, err := r.in.ReadBytes('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return record{}, nil, fmt.Errorf("%w: архив обрезан", ErrCorrupted)
+		}
+		if errors.Is(err, ErrWrongIdentity) || errors.Is(err, ErrCorrupted) {
+			return record{}, nil, err
+		}
+		return record{}, nil, fmt.Errorf("%w: %s", ErrCorrupted, err)
+	}
+	var rec record
+	if err = json.Unmarshal(line, &rec); err != nil {
+		return record{}, nil, fmt.Errorf("%w: %s", ErrCorrupted, err)
+	}
+	return rec, line, nil
+}
@@ -0,0 +1,172 @@
+package backupfile
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeBackup записывает архив с таблицами registered_users и notes
+func writeBackup(t *testing.T, recipient *operatorKey, notes int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	header := Header{
+		SchemaVersion:  17,
+		CreatedAt:      time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC),
+		KeyFingerprint: Fingerprint([]byte("0123456789abcdef")),
+		Tables:         []string{"registered_users", "notes"},
+	}
+	w, err := NewWriter(&buf, header, recipient.public())
+	require.NoError(t, err)
+	require.NoError(t, w.BeginTable("registered_users"))
+	require.NoError(t, w.WriteRow([]byte(`{"login":"arya","password":"hash"}`)))
+	require.NoError(t, w.BeginTable("notes"))
+	for i := 0; i < notes; i++ {
+		// Случайное содержимое не сжимается, поэтому зашифрованный архив занимает несколько блоков
+		content := make([]byte, 256)
+		_, _ = rand.Read(content)
+		require.NoError(t, w.WriteRow([]byte(fmt.Sprintf(`{"id":%d,"content":"%x"}`, i+1, content))))
+	}
+	tables, err := w.Close()
+	require.NoError(t, err)
+	require.Len(t, tables, 2)
+	assert.Equal(t, int64(notes), tables[1].Rows)
+	return buf.Bytes()
+}
+
+// operatorKey ключ оператора для тестов, nil означает архив без шифрования
+type operatorKey struct {
+	t    *testing.T
+	text string
+}
+
+func newOperatorKey(t *testing.T) *operatorKey {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	return &operatorKey{t: t, text: MarshalPrivateKey(key)}
+}
+
+func (k *operatorKey) public() *ecdh.PublicKey {
+	if k == nil {
+		return nil
+	}
+	pub, err := ParsePublicKey(MarshalPublicKey(k.private().PublicKey()))
+	require.NoError(k.t, err)
+	return pub
+}
+
+func (k *operatorKey) private() *ecdh.PrivateKey {
+	if k == nil {
+		return nil
+	}
+	key, err := ParsePrivateKey(k.text)
+	require.NoError(k.t, err)
+	return key
+}
+
+// readBackup читает все строки архива
+func readBackup(data []byte, identity *ecdh.PrivateKey) (Header, map[string]int, error) {
+	r, err := NewReader(bytes.NewReader(data), identity)
+	if err != nil {
+		return Header{}, nil, err
+	}
+	rows := map[string]int{}
+	for {
+		table, _, err := r.Next()
+		if err == io.EOF {
+			return r.Header(), rows, nil
+		}
+		if err != nil {
+			return Header{}, nil, err
+		}
+		rows[table]++
+	}
+}
+
+func TestWriterReader(t *testing.T) {
+	key := newOperatorKey(t)
+	tests := []struct {
+		name string
+		key  *operatorKey
+	}{
+		{name: "без шифрования"},
+		{name: "с шифрованием", key: key},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := writeBackup(t, tt.key, 600)
+			header, rows, err := readBackup(data, tt.key.private())
+			require.NoError(t, err)
+			assert.Equal(t, Format, header.Format)
+			assert.Equal(t, int64(17), header.SchemaVersion)
+			assert.Equal(t, []string{"registered_users", "notes"}, header.Tables)
+			assert.Equal(t, map[string]int{"registered_users": 1, "notes": 600}, rows)
+		})
+	}
+}
+
+func TestReader_Errors(t *testing.T) {
+	key := newOperatorKey(t)
+	plain := writeBackup(t, nil, 10)
+	encrypted := writeBackup(t, key, 600)
+
+	_, _, err := readBackup([]byte(`{"version":1}`), nil)
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+
+	_, _, err = readBackup(encrypted, nil)
+	assert.ErrorIs(t, err, ErrEncrypted)
+
+	_, _, err = readBackup(encrypted, newOperatorKey(t).private())
+	assert.ErrorIs(t, err, ErrWrongIdentity)
+
+	// Обрезанный архив обнаруживается и без шифрования, и с ним
+	_, _, err = readBackup(plain[:len(plain)-20], nil)
+	assert.ErrorIs(t, err, ErrCorrupted)
+	_, _, err = readBackup(encrypted[:len(encrypted)-chunkSize/2], key.private())
+	assert.ErrorIs(t, err, ErrCorrupted)
+
+	// Изменение зашифрованного блока обнаруживается при расшифровке
+	tampered := append([]byte{}, encrypted...)
+	tampered[len(tampered)-100]++
+	_, _, err = readBackup(tampered, key.private())
+	assert.ErrorIs(t, err, ErrCorrupted)
+}
+
+func TestReader_Checksums(t *testing.T) {
+	// Архив с подмененной строкой таблицы, но исходной итоговой строкой
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, Header{Tables: []string{"notes"}}, nil)
+	require.NoError(t, err)
+	require.NoError(t, w.BeginTable("notes"))
+	require.NoError(t, w.WriteRow([]byte(`{"id":1}`)))
+	w.total.Reset()
+	_, err = w.Close()
+	require.NoError(t, err)
+
+	_, _, err = readBackup(buf.Bytes(), nil)
+	assert.ErrorIs(t, err, ErrCorrupted)
+}
+
+func TestWrapKey(t *testing.T) {
+	key := newOperatorKey(t)
+	wrapped, err := WrapKey(key.public(), []byte("0123456789abcdef"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(wrapped), "0123456789abcdef")
+
+	unwrapped, err := UnwrapKey(key.private(), wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("0123456789abcdef"), unwrapped)
+
+	_, err = UnwrapKey(newOperatorKey(t).private(), wrapped)
+	assert.ErrorIs(t, err, ErrWrongIdentity)
+
+	_, err = ParsePrivateKey(MarshalPublicKey(key.public()))
+	assert.Error(t, err)
+}
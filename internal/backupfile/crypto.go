@@ -0,0 +1,263 @@
+package backupfile
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// magic начало зашифрованного архива
+var magic = []byte("GVB1")
+
+// chunkSize размер открытого текста в блоке зашифрованного архива
+const chunkSize = 64 * 1024
+
+// Префиксы ключей оператора в текстовом виде
+const (
+	publicKeyPrefix  = "gophervault-backup-public-key:"
+	privateKeyPrefix = "gophervault-backup-private-key:"
+)
+
+// Строки info для HKDF: ключ потока архива и ключ обертки ключа шифрования данных
+const (
+	streamInfo = "gophervault backup stream"
+	wrapInfo   = "gophervault backup key wrap"
+)
+
+// GenerateKey создает ключ оператора X25519
+func GenerateKey() (*ecdh.PrivateKey, error) {
+	return ecdh.X25519().GenerateKey(rand.Reader)
+}
+
+// MarshalPublicKey возвращает открытый ключ оператора в текстовом виде
+func MarshalPublicKey(key *ecdh.PublicKey) string {
+	return publicKeyPrefix + base64.StdEncoding.EncodeToString(key.Bytes()) + "\n"
+}
+
+// MarshalPrivateKey возвращает закрытый ключ оператора в текстовом виде
+func MarshalPrivateKey(key *ecdh.PrivateKey) string {
+	return privateKeyPrefix + base64.StdEncoding.EncodeToString(key.Bytes()) + "\n"
+}
+
+// ParsePublicKey разбирает открытый ключ оператора из MarshalPublicKey
+func ParsePublicKey(text string) (*ecdh.PublicKey, error) {
+	raw, err := parseKey(text, publicKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	return ecdh.X25519().NewPublicKey(raw)
+}
+
+// ParsePrivateKey разбирает закрытый ключ оператора из MarshalPrivateKey
+func ParsePrivateKey(text string) (*ecdh.PrivateKey, error) {
+	raw, err := parseKey(text, privateKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	return ecdh.X25519().NewPrivateKey(raw)
+}
+
+// parseKey декодирует ключ оператора с префиксом prefix
+func parseKey(text, prefix string) ([]byte, error) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, prefix) {
+		return nil, fmt.Errorf("ключ должен начинаться с %q", prefix)
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(text, prefix))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при разборе ключа: %w", err)
+	}
+	return raw, nil
+}
+
+// WrapKey шифрует ключ шифрования данных открытым ключом оператора. Обернутый ключ сохраняется в заголовке архива,
+// чтобы при утере KEEPER_ENCRYPTION_KEY его можно было восстановить закрытым ключом оператора.
+func WrapKey(recipient *ecdh.PublicKey, key []byte) ([]byte, error) {
+	ephemeral, aead, err := senderAEAD(recipient, wrapInfo)
+	if err != nil {
+		return nil, err
+	}
+	// Ключ AES одноразовый, поэтому нулевой nonce безопасен
+	nonce := make([]byte, aead.NonceSize())
+	return aead.Seal(ephemeral.PublicKey().Bytes(), nonce, key, ephemeral.PublicKey().Bytes()), nil
+}
+
+// UnwrapKey расшифровывает ключ из WrapKey закрытым ключом оператора
+func UnwrapKey(identity *ecdh.PrivateKey, wrapped []byte) ([]byte, error) {
+	size := len(identity.PublicKey().Bytes())
+	if len(wrapped) < size {
+		return nil, fmt.Errorf("%w: обернутый ключ слишком короткий", ErrCorrupted)
+	}
+	aead, err := recipientAEAD(identity, wrapped[:size], wrapInfo)
+	if err != nil {
+		return nil, err
+	}
+	key, err := aead.Open(nil, make([]byte, aead.NonceSize()), wrapped[size:], wrapped[:size])
+	if err != nil {
+		return nil, ErrWrongIdentity
+	}
+	return key, nil
+}
+
+// senderAEAD создает одноразовый ключ отправителя и шифр для получателя recipient
+func senderAEAD(recipient *ecdh.PublicKey, info string) (*ecdh.PrivateKey, cipher.AEAD, error) {
+	ephemeral, err := GenerateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	secret, err := ephemeral.ECDH(recipient)
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := newAEAD(secret, ephemeral.PublicKey().Bytes(), recipient.Bytes(), info)
+	return ephemeral, aead, err
+}
+
+// recipientAEAD создает шифр получателя identity для открытого ключа отправителя ephemeral
+func recipientAEAD(identity *ecdh.PrivateKey, ephemeral []byte, info string) (cipher.AEAD, error) {
+	pub, err := ecdh.X25519().NewPublicKey(ephemeral)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCorrupted, err)
+	}
+	secret, err := identity.ECDH(pub)
+	if err != nil {
+		return nil, ErrWrongIdentity
+	}
+	return newAEAD(secret, ephemeral, identity.PublicKey().Bytes(), info)
+}
+
+// newAEAD получает ключ AES-256-GCM из общего секрета X25519
+func newAEAD(secret, ephemeral, recipient []byte, info string) (cipher.AEAD, error) {
+	salt := append(append([]byte{}, ephemeral...), recipient...)
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, []byte(info)), key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce возвращает nonce блока: номер блока и признак последнего блока
+func chunkNonce(size int, counter uint64, last bool) []byte {
+	nonce := make([]byte, size)
+	binary.BigEndian.PutUint64(nonce[size-9:size-1], counter)
+	if last {
+		nonce[size-1] = 1
+	}
+	return nonce
+}
+
+// encryptWriter шифрует поток блоками по chunkSize
+type encryptWriter struct {
+	out     io.Writer
+	aead    cipher.AEAD
+	buf     []byte
+	counter uint64
+}
+
+// newEncryptWriter записывает заголовок зашифрованного архива и возвращает поток для шифрования
+func newEncryptWriter(w io.Writer, recipient *ecdh.PublicKey) (*encryptWriter, error) {
+	ephemeral, aead, err := senderAEAD(recipient, streamInfo)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(append(append([]byte{}, magic...), ephemeral.PublicKey().Bytes()...)); err != nil {
+		return nil, err
+	}
+	return &encryptWriter{out: w, aead: aead}, nil
+}
+
+// Write накапливает открытый текст и записывает заполненные блоки. Блок записывается, только когда
+// за ним есть еще данные, потому что последний блок помечается при Close.
+func (w *encryptWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for len(w.buf) > chunkSize {
+		if err := w.flush(w.buf[:chunkSize], false); err != nil {
+			return 0, err
+		}
+		w.buf = w.buf[chunkSize:]
+	}
+	return len(p), nil
+}
+
+// Close записывает последний блок
+func (w *encryptWriter) Close() error {
+	return w.flush(w.buf, true)
+}
+
+// flush шифрует и записывает блок
+func (w *encryptWriter) flush(chunk []byte, last bool) error {
+	nonce := chunkNonce(w.aead.NonceSize(), w.counter, last)
+	w.counter++
+	_, err := w.out.Write(w.aead.Seal(nil, nonce, chunk, nil))
+	return err
+}
+
+// decryptReader расшифровывает поток из encryptWriter
+type decryptReader struct {
+	in      *bufio.Reader
+	aead    cipher.AEAD
+	buf     []byte
+	counter uint64
+	done    bool
+}
+
+// newDecryptReader читает заголовок зашифрованного архива и возвращает поток для расшифровки
+func newDecryptReader(r *bufio.Reader, identity *ecdh.PrivateKey) (*decryptReader, error) {
+	header := make([]byte, len(magic)+len(identity.PublicKey().Bytes()))
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCorrupted, err)
+	}
+	aead, err := recipientAEAD(identity, header[len(magic):], streamInfo)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{in: r, aead: aead}, nil
+}
+
+// Read возвращает расшифрованные данные. Блок, за которым нет данных, должен быть помечен последним,
+// иначе архив считается обрезанным.
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		chunk := make([]byte, chunkSize+r.aead.Overhead())
+		n, err := io.ReadFull(r.in, chunk)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+		last := n < len(chunk)
+		if !last {
+			if _, err = r.in.Peek(1); errors.Is(err, io.EOF) {
+				last = true
+			}
+		}
+		plain, err := r.aead.Open(nil, chunkNonce(r.aead.NonceSize(), r.counter, last), chunk[:n], nil)
+		if err != nil {
+			if r.counter == 0 {
+				return 0, ErrWrongIdentity
+			}
+			return 0, fmt.Errorf("%w: блок %d не расшифровывается", ErrCorrupted, r.counter)
+		}
+		r.counter++
+		r.buf, r.done = plain, last
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
@@ -0,0 +1,57 @@
+// Package blindindex строит слепой индекс слов для поиска по зашифрованным данным.
+// Вместо слов сервер хранит их ключевые HMAC-отпечатки: по отпечатку нельзя восстановить слово,
+// но совпадающие слова одного пользователя дают совпадающие отпечатки.
+package blindindex
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// indexContext отделяет ключ индекса от ключа шифрования, из которого он выводится
+const indexContext = "GopherVault notes index v1"
+
+// DeriveKey выводит ключ индекса из ключа шифрования хранилища
+func DeriveKey(encryptionKey string) []byte {
+	mac := hmac.New(sha256.New, []byte(encryptionKey))
+	mac.Write([]byte(indexContext))
+	return mac.Sum(nil)
+}
+
+// Words разбивает текст на нормализованные слова: приводит к нижнему регистру, заменяет ё на е
+// и отбрасывает знаки препинания. Слова возвращаются без повторов в порядке первого появления.
+func Words(text string) []string {
+	seen := make(map[string]struct{})
+	var words []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		w = strings.ReplaceAll(w, "ё", "е")
+		if _, ok := seen[w]; ok {
+			continue
+		}
+		seen[w] = struct{}{}
+		words = append(words, w)
+	}
+	return words
+}
+
+// Tokens возвращает отсортированные отпечатки слов текста для пользователя userName.
+// Имя пользователя входит в отпечаток, поэтому одинаковые слова разных пользователей не совпадают.
+func Tokens(key []byte, userName, text string) []string {
+	words := Words(text)
+	tokens := make([]string, 0, len(words))
+	for _, w := range words {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(userName))
+		mac.Write([]byte{0})
+		mac.Write([]byte(w))
+		tokens = append(tokens, hex.EncodeToString(mac.Sum(nil)))
+	}
+	sort.Strings(tokens)
+	return tokens
+}
@@ -0,0 +1,34 @@
+package blindindex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWords(t *testing.T) {
+	assert.Equal(t, []string{"winter", "is", "coming", "ежик", "2024"},
+		Words("Winter is COMING! winter... Ёжик, 2024"))
+	assert.Empty(t, Words(" ,.!? "))
+}
+
+func TestTokens(t *testing.T) {
+	key := DeriveKey("thisis32bitlongpassphraseimusing")
+
+	tokens := Tokens(key, "jon", "The night is dark, the NIGHT is long")
+	assert.Len(t, tokens, 5)
+	for _, token := range tokens {
+		assert.Len(t, token, 64)
+		assert.NotContains(t, token, "night")
+	}
+
+	t.Run("same words give same tokens", func(t *testing.T) {
+		assert.Subset(t, tokens, Tokens(key, "jon", "night dark"))
+	})
+	t.Run("tokens depend on user", func(t *testing.T) {
+		assert.NotEqual(t, Tokens(key, "jon", "night"), Tokens(key, "sansa", "night"))
+	})
+	t.Run("tokens depend on key", func(t *testing.T) {
+		assert.NotEqual(t, Tokens(key, "jon", "night"), Tokens(DeriveKey("another key"), "jon", "night"))
+	})
+}
@@ -0,0 +1,283 @@
+// Package breach проверяет пароли по локальной копии базы утекших паролей Pwned Passwords (HIBP) без доступа к сети.
+//
+// Поддерживаются два формата базы:
+//   - каталог файлов диапазонов, как их скачивает haveibeenpwned-downloader: файл XXXXX.txt для каждого
+//     пятисимвольного префикса SHA-1 в шестнадцатеричной записи со строками вида SUFFIX:COUNT;
+//   - компактный двоичный индекс, который строит BuildIndex: заголовок и отсортированные префиксы SHA-1
+//     фиксированной длины. Индекс не хранит число появлений пароля и допускает ложные срабатывания с вероятностью
+//     порядка числа паролей в базе, деленного на 2 в степени длины префикса в битах.
+package breach
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Параметры файлов диапазонов
+const (
+	rangePrefixLength = 5  // Число шестнадцатеричных символов префикса в имени файла
+	rangeSuffixLength = 35 // Число шестнадцатеричных символов суффикса в строке файла
+	rangeFileExt      = ".txt"
+)
+
+// indexMagic сигнатура двоичного индекса; за ней следует байт длины префикса
+const indexMagic = "GVHIBP01"
+
+// Ограничения длины префикса двоичного индекса в байтах
+const (
+	MinPrefixBytes     = 4
+	DefaultPrefixBytes = 8
+)
+
+var (
+	// ErrInvalidDataset означает, что путь не указывает на каталог диапазонов или двоичный индекс
+	ErrInvalidDataset = errors.New("invalid breach dataset")
+	// ErrUnsorted означает, что файлы диапазонов не отсортированы и по ним нельзя построить индекс
+	ErrUnsorted = errors.New("breach dataset is not sorted")
+)
+
+// Dataset локальная база утекших паролей
+type Dataset interface {
+	// Count возвращает число появлений пароля в утечках или 0, если пароль в базе не найден.
+	// Двоичный индекс не хранит число появлений, поэтому для найденного в нем пароля возвращается 1.
+	Count(password string) (int, error)
+	// Close закрывает базу
+	Close() error
+}
+
+// Open открывает базу по пути к каталогу файлов диапазонов или к двоичному индексу
+func Open(path string) (Dataset, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при открытии базы утечек: %w", err)
+	}
+	if info.IsDir() {
+		return rangeDir(path), nil
+	}
+	return openIndex(path, info.Size())
+}
+
+// hashHex возвращает SHA-1 пароля в шестнадцатеричной записи заглавными буквами, как в файлах диапазонов
+func hashHex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// rangeDir каталог файлов диапазонов
+type rangeDir string
+
+// Count ищет суффикс SHA-1 пароля в файле диапазона его префикса
+func (d rangeDir) Count(password string) (int, error) {
+	hash := hashHex(password)
+	file, err := os.Open(filepath.Join(string(d), hash[:rangePrefixLength]+rangeFileExt))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при чтении файла диапазона: %w", err)
+	}
+	defer file.Close()
+
+	var count int
+	err = scanRange(file, func(suffix string, n int) bool {
+		if suffix == hash[rangePrefixLength:] {
+			count = n
+			return false
+		}
+		return true
+	})
+	return count, err
+}
+
+// Close ничего не делает: файлы диапазонов открываются на время одной проверки
+func (d rangeDir) Close() error {
+	return nil
+}
+
+// scanRange разбирает строки файла диапазона и передает суффиксы и число появлений в fn, пока она возвращает true.
+// Строки с нулевым числом появлений, которыми загрузчик HIBP дополняет ответы, пропускаются.
+func scanRange(r io.Reader, fn func(suffix string, count int) bool) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		suffix, countText, ok := strings.Cut(text, ":")
+		count, err := strconv.Atoi(countText)
+		if !ok || err != nil || len(suffix) != rangeSuffixLength {
+			return fmt.Errorf("%w: некорректная строка %d файла диапазона", ErrInvalidDataset, line)
+		}
+		if count == 0 {
+			continue
+		}
+		if !fn(strings.ToUpper(suffix), count) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// index двоичный индекс префиксов SHA-1
+type index struct {
+	file        *os.File
+	prefixBytes int
+	records     int64
+}
+
+// indexHeaderLength длина заголовка индекса: сигнатура и байт длины префикса
+const indexHeaderLength = len(indexMagic) + 1
+
+// openIndex открывает двоичный индекс и проверяет его заголовок
+func openIndex(path string, size int64) (*index, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при открытии базы утечек: %w", err)
+	}
+	header := make([]byte, indexHeaderLength)
+	if _, err = io.ReadFull(file, header); err != nil || string(header[:len(indexMagic)]) != indexMagic {
+		file.Close()
+		return nil, fmt.Errorf("%w: %s не является каталогом файлов диапазонов или индексом", ErrInvalidDataset, path)
+	}
+	prefixBytes := int(header[len(indexMagic)])
+	if prefixBytes < MinPrefixBytes || prefixBytes > sha1.Size || (size-int64(indexHeaderLength))%int64(prefixBytes) != 0 {
+		file.Close()
+		return nil, fmt.Errorf("%w: поврежденный индекс %s", ErrInvalidDataset, path)
+	}
+	return &index{file: file, prefixBytes: prefixBytes, records: (size - int64(indexHeaderLength)) / int64(prefixBytes)}, nil
+}
+
+// Count ищет префикс SHA-1 пароля в индексе двоичным поиском
+func (x *index) Count(password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	prefix := sum[:x.prefixBytes]
+	record := make([]byte, x.prefixBytes)
+	var readErr error
+	i := sort.Search(int(x.records), func(i int) bool {
+		if readErr != nil {
+			return true
+		}
+		if _, err := x.file.ReadAt(record, int64(indexHeaderLength)+int64(i)*int64(x.prefixBytes)); err != nil {
+			readErr = err
+			return true
+		}
+		return bytes.Compare(record, prefix) >= 0
+	})
+	if readErr != nil {
+		return 0, fmt.Errorf("ошибка при чтении индекса утечек: %w", readErr)
+	}
+	if int64(i) == x.records {
+		return 0, nil
+	}
+	if _, err := x.file.ReadAt(record, int64(indexHeaderLength)+int64(i)*int64(x.prefixBytes)); err != nil {
+		return 0, fmt.Errorf("ошибка при чтении индекса утечек: %w", err)
+	}
+	if bytes.Equal(record, prefix) {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Close закрывает файл индекса
+func (x *index) Close() error {
+	return x.file.Close()
+}
+
+// BuildIndex записывает в w двоичный индекс по каталогу файлов диапазонов, оставляя от каждого SHA-1 первые
+// prefixBytes байт. Совпадающие после усечения префиксы записываются один раз. Возвращает число записей индекса.
+func BuildIndex(dir string, w io.Writer, prefixBytes int) (int64, error) {
+	if prefixBytes < MinPrefixBytes || prefixBytes > sha1.Size {
+		return 0, fmt.Errorf("%w: длина префикса должна быть от %d до %d байт", ErrInvalidDataset, MinPrefixBytes, sha1.Size)
+	}
+	prefixes, err := rangePrefixes(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	bw := bufio.NewWriter(w)
+	if _, err = bw.WriteString(indexMagic); err != nil {
+		return 0, err
+	}
+	if err = bw.WriteByte(byte(prefixBytes)); err != nil {
+		return 0, err
+	}
+	var records int64
+	var last []byte
+	for _, prefix := range prefixes {
+		file, err := os.Open(filepath.Join(dir, prefix.name))
+		if err != nil {
+			return 0, fmt.Errorf("ошибка при чтении файла диапазона: %w", err)
+		}
+		var recordErr error
+		err = scanRange(file, func(suffix string, _ int) bool {
+			sum, err := hex.DecodeString(prefix.hex + suffix)
+			if err != nil {
+				recordErr = fmt.Errorf("%w: некорректный хеш в файле %s", ErrInvalidDataset, prefix.name)
+				return false
+			}
+			record := sum[:prefixBytes]
+			switch cmp := bytes.Compare(record, last); {
+			case last != nil && cmp < 0:
+				recordErr = fmt.Errorf("%w: файл %s", ErrUnsorted, prefix.name)
+				return false
+			case last != nil && cmp == 0:
+				return true
+			}
+			if _, recordErr = bw.Write(record); recordErr != nil {
+				return false
+			}
+			last = record
+			records++
+			return true
+		})
+		file.Close()
+		if err == nil {
+			err = recordErr
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return records, bw.Flush()
+}
+
+// rangeFile файл диапазона и его префикс заглавными буквами
+type rangeFile struct {
+	name string
+	hex  string
+}
+
+// rangePrefixes возвращает файлы диапазонов каталога в порядке префиксов
+func rangePrefixes(dir string) ([]rangeFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при чтении каталога файлов диапазонов: %w", err)
+	}
+	var files []rangeFile
+	for _, entry := range entries {
+		name := entry.Name()
+		prefix := strings.ToUpper(strings.TrimSuffix(name, rangeFileExt))
+		if entry.IsDir() || !strings.HasSuffix(name, rangeFileExt) || len(prefix) != rangePrefixLength {
+			continue
+		}
+		if _, err := hex.DecodeString(prefix + "0"); err != nil {
+			continue
+		}
+		files = append(files, rangeFile{name: name, hex: prefix})
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: в каталоге %s нет файлов диапазонов", ErrInvalidDataset, dir)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].hex < files[j].hex })
+	return files, nil
+}
@@ -0,0 +1,111 @@
+package breach
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeRanges записывает файлы диапазонов для паролей с указанным числом появлений
+func writeRanges(t *testing.T, passwords map[string]int) string {
+	dir := t.TempDir()
+	lines := map[string][]string{}
+	for password, count := range passwords {
+		hash := hashHex(password)
+		lines[hash[:5]] = append(lines[hash[:5]], hash[5:]+":"+strconv.Itoa(count))
+	}
+	for prefix, l := range lines {
+		// Загрузчик HIBP дополняет файлы строками с нулевым числом появлений
+		l = append(l, strings.Repeat("F", 35)+":0")
+		sort.Strings(l)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, prefix+".txt"), []byte(strings.Join(l, "\r\n")+"\r\n"), 0o600))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a range file"), 0o600))
+	return dir
+}
+
+func TestRangeDir(t *testing.T) {
+	dir := writeRanges(t, map[string]int{"password": 9545824, "winterfell": 12})
+	dataset, err := Open(dir)
+	require.NoError(t, err)
+	defer dataset.Close()
+
+	count, err := dataset.Count("password")
+	require.NoError(t, err)
+	assert.Equal(t, 9545824, count)
+	count, err = dataset.Count("winterfell")
+	require.NoError(t, err)
+	assert.Equal(t, 12, count)
+	count, err = dataset.Count("xK9#mQ2$vL7!pR4@")
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestRangeDir_InvalidLine(t *testing.T) {
+	dir := t.TempDir()
+	hash := hashHex("password")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, hash[:5]+".txt"), []byte("broken line\n"), 0o600))
+	dataset, err := Open(dir)
+	require.NoError(t, err)
+	_, err = dataset.Count("password")
+	assert.ErrorIs(t, err, ErrInvalidDataset)
+}
+
+func TestIndex(t *testing.T) {
+	passwords := map[string]int{"password": 3, "123456": 5, "winterfell": 1, "dragon": 7}
+	dir := writeRanges(t, passwords)
+
+	var buf bytes.Buffer
+	records, err := BuildIndex(dir, &buf, DefaultPrefixBytes)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(passwords)), records)
+	assert.Equal(t, indexHeaderLength+len(passwords)*DefaultPrefixBytes, buf.Len())
+
+	path := filepath.Join(t.TempDir(), "pwned.idx")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+	dataset, err := Open(path)
+	require.NoError(t, err)
+	defer dataset.Close()
+	for password := range passwords {
+		count, err := dataset.Count(password)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count, password)
+	}
+	for _, password := range []string{"", "xK9#mQ2$vL7!pR4@", "zzzzzzzz"} {
+		count, err := dataset.Count(password)
+		require.NoError(t, err)
+		assert.Equal(t, 0, count, password)
+	}
+}
+
+func TestBuildIndex_Errors(t *testing.T) {
+	_, err := BuildIndex(t.TempDir(), &bytes.Buffer{}, DefaultPrefixBytes)
+	assert.ErrorIs(t, err, ErrInvalidDataset)
+
+	dir := writeRanges(t, map[string]int{"password": 1})
+	_, err = BuildIndex(dir, &bytes.Buffer{}, 2)
+	assert.ErrorIs(t, err, ErrInvalidDataset)
+
+	unsorted := t.TempDir()
+	content := strings.Repeat("B", 35) + ":1\n" + strings.Repeat("A", 35) + ":1\n"
+	require.NoError(t, os.WriteFile(filepath.Join(unsorted, "00000.txt"), []byte(content), 0o600))
+	_, err = BuildIndex(unsorted, &bytes.Buffer{}, DefaultPrefixBytes)
+	assert.ErrorIs(t, err, ErrUnsorted)
+}
+
+func TestOpen_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	require.NoError(t, os.WriteFile(path, []byte("not an index"), 0o600))
+	_, err := Open(path)
+	assert.ErrorIs(t, err, ErrInvalidDataset)
+
+	_, err = Open(filepath.Join(t.TempDir(), "missing"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
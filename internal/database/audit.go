@@ -0,0 +1,17 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// SaveAuditEvent записывает событие в журнал аудита
+func (d *Db) SaveAuditEvent(ctx context.Context, event models.AuditEvent) error {
+	saveEventQuery := "insert into audit_log (user_name, event, details) values ($1, $2, $3)"
+	if _, err := d.conn.ExecContext(ctx, saveEventQuery, event.UserName, event.Event, event.Details); err != nil {
+		return fmt.Errorf("ошибка при записи события %q в журнал аудита для пользователя %q: %w", event.Event, event.UserName, err)
+	}
+	return nil
+}
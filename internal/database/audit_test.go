@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDb_SaveAuditEvent(t *testing.T) {
+	ctx := context.Background()
+	event := models.AuditEvent{UserName: "arya", Event: models.AuditPlaintextExport, Details: "format=csv"}
+
+	testCases := []struct {
+		name        string
+		execError   error
+		expectedErr string
+	}{
+		{
+			name: "positive: event saved",
+		},
+		{
+			name:        "negative: storage error",
+			execError:   errors.New("connection refused"),
+			expectedErr: `ошибка при записи события "plaintext_export" в журнал аудита для пользователя "arya": connection refused`,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer mockDB.Close()
+
+			exec := mock.ExpectExec("insert into audit_log \\(user_name, event, details\\) values \\(\\$1, \\$2, \\$3\\)").
+				WithArgs("arya", models.AuditPlaintextExport, "format=csv")
+			if tt.execError != nil {
+				exec.WillReturnError(tt.execError)
+			} else {
+				exec.WillReturnResult(sqlmock.NewResult(1, 1))
+			}
+
+			pg := Db{conn: mockDB}
+			err = pg.SaveAuditEvent(ctx, event)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
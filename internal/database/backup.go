@@ -0,0 +1,251 @@
+package database
+
+import (
+	"context"
+	"crypto/ecdh"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/ZnNr/GopherVault/internal/backupfile"
+	"github.com/lib/pq"
+)
+
+// listTablesQuery возвращает таблицы схемы, кроме таблицы версий миграций, которую ведет migrate
+const listTablesQuery = "select table_name from information_schema.tables " +
+	"where table_schema = current_schema() and table_type = 'BASE TABLE' and table_name <> 'schema_migrations' order by table_name"
+
+// schemaVersionQuery возвращает версию миграций базы данных
+const schemaVersionQuery = "select version, dirty from schema_migrations"
+
+// Backup записывает резервную копию всех таблиц в w. Таблицы читаются в одной транзакции REPEATABLE READ
+// только для чтения, поэтому копия согласована на момент начала транзакции.
+// Если указан ключ оператора recipient, архив шифруется, а в заголовок добавляется обернутый ключ шифрования данных.
+func (d *Db) Backup(ctx context.Context, w io.Writer, recipient *ecdh.PublicKey) (backupfile.Header, []backupfile.TableSummary, error) {
+	tx, err := d.conn.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return backupfile.Header{}, nil, fmt.Errorf("ошибка при создании резервной копии: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	header := backupfile.Header{KeyFingerprint: backupfile.Fingerprint([]byte(d.encryptionKey))}
+	if header.SchemaVersion, err = schemaVersion(ctx, tx); err != nil {
+		return backupfile.Header{}, nil, err
+	}
+	if err = tx.QueryRowContext(ctx, "select now()").Scan(&header.CreatedAt); err != nil {
+		return backupfile.Header{}, nil, fmt.Errorf("ошибка при создании резервной копии: %w", err)
+	}
+	if header.Tables, err = listTables(ctx, tx); err != nil {
+		return backupfile.Header{}, nil, err
+	}
+	if recipient != nil {
+		if header.WrappedKey, err = backupfile.WrapKey(recipient, []byte(d.encryptionKey)); err != nil {
+			return backupfile.Header{}, nil, fmt.Errorf("ошибка при шифровании ключа данных: %w", err)
+		}
+	}
+
+	bw, err := backupfile.NewWriter(w, header, recipient)
+	if err != nil {
+		return backupfile.Header{}, nil, err
+	}
+	for _, table := range header.Tables {
+		if err = bw.BeginTable(table); err != nil {
+			return backupfile.Header{}, nil, err
+		}
+		if err = dumpTable(ctx, tx, table, bw); err != nil {
+			return backupfile.Header{}, nil, err
+		}
+	}
+	tables, err := bw.Close()
+	if err != nil {
+		return backupfile.Header{}, nil, err
+	}
+	return header, tables, nil
+}
+
+// dumpTable записывает строки таблицы в архив в виде объектов JSON
+func dumpTable(ctx context.Context, tx *sql.Tx, table string, bw *backupfile.Writer) error {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("select row_to_json(t)::text from %s t", pq.QuoteIdentifier(table)))
+	if err != nil {
+		return fmt.Errorf("ошибка при чтении таблицы %q: %w", table, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var row []byte
+		if err = rows.Scan(&row); err != nil {
+			return fmt.Errorf("ошибка при чтении таблицы %q: %w", table, err)
+		}
+		if err = bw.WriteRow(row); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("ошибка при чтении таблицы %q: %w", table, err)
+	}
+	return nil
+}
+
+// Restore восстанавливает резервную копию из r в одной транзакции. Версия схемы и ключ шифрования данных
+// должны совпадать с резервной копией, а таблицы должны быть пустыми; с force таблицы предварительно очищаются.
+// Строки вставляются с отключенными триггерами и проверками внешних ключей, поэтому журнал изменений
+// восстанавливается из копии, а не дополняется. Транзакция фиксируется только после проверки контрольных сумм архива.
+// Для отключения триггеров нужны права суперпользователя PostgreSQL.
+func (d *Db) Restore(ctx context.Context, r io.Reader, identity *ecdh.PrivateKey, force bool) (backupfile.Header, []backupfile.TableSummary, error) {
+	br, err := backupfile.NewReader(r, identity)
+	if err != nil {
+		return backupfile.Header{}, nil, err
+	}
+	header := br.Header()
+	if header.KeyFingerprint != backupfile.Fingerprint([]byte(d.encryptionKey)) {
+		return backupfile.Header{}, nil, fmt.Errorf("%w: укажите KEEPER_ENCRYPTION_KEY сервера, на котором создана копия", ErrBackupKeyMismatch)
+	}
+
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return backupfile.Header{}, nil, fmt.Errorf("ошибка при восстановлении резервной копии: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	version, err := schemaVersion(ctx, tx)
+	if err != nil {
+		return backupfile.Header{}, nil, err
+	}
+	if version != header.SchemaVersion {
+		return backupfile.Header{}, nil, fmt.Errorf("%w: версия схемы копии %d, базы данных %d", ErrBackupSchemaMismatch, header.SchemaVersion, version)
+	}
+	tables, err := listTables(ctx, tx)
+	if err != nil {
+		return backupfile.Header{}, nil, err
+	}
+	if !slices.Equal(tables, header.Tables) {
+		return backupfile.Header{}, nil, fmt.Errorf("%w: таблицы копии %s, базы данных %s",
+			ErrBackupSchemaMismatch, strings.Join(header.Tables, ", "), strings.Join(tables, ", "))
+	}
+	if err = prepareRestoreTarget(ctx, tx, tables, force); err != nil {
+		return backupfile.Header{}, nil, err
+	}
+
+	if _, err = tx.ExecContext(ctx, "set local session_replication_role = replica"); err != nil {
+		return backupfile.Header{}, nil, fmt.Errorf("ошибка при отключении триггеров: %w", err)
+	}
+	for {
+		table, row, err := br.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return backupfile.Header{}, nil, err
+		}
+		if !slices.Contains(tables, table) {
+			return backupfile.Header{}, nil, fmt.Errorf("%w: таблица %q отсутствует в заголовке", backupfile.ErrCorrupted, table)
+		}
+		insertQuery := fmt.Sprintf("insert into %[1]s select * from json_populate_record(null::%[1]s, $1)", pq.QuoteIdentifier(table))
+		if _, err = tx.ExecContext(ctx, insertQuery, string(row)); err != nil {
+			return backupfile.Header{}, nil, fmt.Errorf("ошибка при восстановлении строки таблицы %q: %w", table, err)
+		}
+	}
+	if err = resetSequences(ctx, tx); err != nil {
+		return backupfile.Header{}, nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return backupfile.Header{}, nil, fmt.Errorf("ошибка при восстановлении резервной копии: %w", err)
+	}
+	return header, br.Tables(), nil
+}
+
+// prepareRestoreTarget проверяет, что таблицы пусты, или очищает их с force
+func prepareRestoreTarget(ctx context.Context, tx *sql.Tx, tables []string, force bool) error {
+	quoted := make([]string, 0, len(tables))
+	for _, table := range tables {
+		quoted = append(quoted, pq.QuoteIdentifier(table))
+	}
+	if force {
+		if _, err := tx.ExecContext(ctx, "truncate table "+strings.Join(quoted, ", ")+" restart identity cascade"); err != nil {
+			return fmt.Errorf("ошибка при очистке таблиц: %w", err)
+		}
+		return nil
+	}
+	for i, table := range tables {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, "select exists(select 1 from "+quoted[i]+")").Scan(&exists); err != nil {
+			return fmt.Errorf("ошибка при проверке таблицы %q: %w", table, err)
+		}
+		if exists {
+			return fmt.Errorf("%w: в таблице %q есть строки, используйте --force для замены данных", ErrBackupTargetNotEmpty, table)
+		}
+	}
+	return nil
+}
+
+// resetSequences устанавливает последовательности столбцов serial после наибольшего восстановленного значения
+func resetSequences(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, "select table_name, column_name from information_schema.columns "+
+		"where table_schema = current_schema() and column_default like 'nextval(%' order by table_name, column_name")
+	if err != nil {
+		return fmt.Errorf("ошибка при получении последовательностей: %w", err)
+	}
+	var columns [][2]string
+	for rows.Next() {
+		var table, column string
+		if err = rows.Scan(&table, &column); err != nil {
+			rows.Close()
+			return fmt.Errorf("ошибка при получении последовательностей: %w", err)
+		}
+		columns = append(columns, [2]string{table, column})
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("ошибка при получении последовательностей: %w", err)
+	}
+
+	for _, c := range columns {
+		setvalQuery := fmt.Sprintf("select setval(pg_get_serial_sequence($1, $2), coalesce(max(%s), 0) + 1, false) from %s",
+			pq.QuoteIdentifier(c[1]), pq.QuoteIdentifier(c[0]))
+		if _, err = tx.ExecContext(ctx, setvalQuery, c[0], c[1]); err != nil {
+			return fmt.Errorf("ошибка при установке последовательности %s.%s: %w", c[0], c[1], err)
+		}
+	}
+	return nil
+}
+
+// schemaVersion возвращает версию миграций. Незавершенная миграция считается ошибкой.
+func schemaVersion(ctx context.Context, tx *sql.Tx) (int64, error) {
+	var version int64
+	var dirty bool
+	if err := tx.QueryRowContext(ctx, schemaVersionQuery).Scan(&version, &dirty); err != nil {
+		return 0, fmt.Errorf("ошибка при получении версии схемы: %w", err)
+	}
+	if dirty {
+		return 0, fmt.Errorf("%w: миграция %d не завершена", ErrBackupSchemaMismatch, version)
+	}
+	return version, nil
+}
+
+// listTables возвращает таблицы схемы в алфавитном порядке
+func listTables(ctx context.Context, tx *sql.Tx) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, listTablesQuery)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении списка таблиц: %w", err)
+	}
+	defer rows.Close()
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err = rows.Scan(&table); err != nil {
+			return nil, fmt.Errorf("ошибка при получении списка таблиц: %w", err)
+		}
+		tables = append(tables, table)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при получении списка таблиц: %w", err)
+	}
+	return tables, nil
+}
@@ -0,0 +1,123 @@
+//go:build integration
+
+package database
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ZnNr/GopherVault/internal/backupfile"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// integrationDSN переменная окружения со строкой подключения к PostgreSQL суперпользователем,
+// например "host=localhost port=5432 user=postgres password=postgres dbname=postgres sslmode=disable"
+const integrationDSN = "GOPHERVAULT_TEST_DSN"
+
+// createTestDatabase создает базу данных с примененными миграциями и возвращает подключение к ней
+func createTestDatabase(t *testing.T, admin *sql.DB, dsn, name, key string) *Db {
+	t.Helper()
+	_, err := admin.Exec("create database " + name)
+	require.NoError(t, err)
+	conn, err := sql.Open("postgres", dsn+" dbname="+name)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+		_, _ = admin.Exec("drop database if exists " + name)
+	})
+
+	// Применяем миграции так же, как migrate: по порядку номеров, с записью версии в schema_migrations
+	files, err := filepath.Glob(filepath.Join("..", "..", "db", "migrations", "*.up.sql"))
+	require.NoError(t, err)
+	sort.Strings(files)
+	for _, file := range files {
+		migration, err := os.ReadFile(file)
+		require.NoError(t, err)
+		_, err = conn.Exec(string(migration))
+		require.NoError(t, err, file)
+	}
+	version, err := strconv.ParseInt(strings.SplitN(filepath.Base(files[len(files)-1]), "_", 2)[0], 10, 64)
+	require.NoError(t, err)
+	_, err = conn.Exec("create table schema_migrations (version bigint not null primary key, dirty boolean not null)")
+	require.NoError(t, err)
+	_, err = conn.Exec("insert into schema_migrations (version, dirty) values ($1, false)", version)
+	require.NoError(t, err)
+
+	c, err := aes.NewCipher([]byte(key))
+	require.NoError(t, err)
+	return &Db{conn: conn, encryptionKey: key, dataCipher: c}
+}
+
+func TestBackupRestore_Integration(t *testing.T) {
+	dsn := os.Getenv(integrationDSN)
+	if dsn == "" {
+		t.Skipf("переменная %s не задана", integrationDSN)
+	}
+	ctx := context.Background()
+	admin, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	defer admin.Close()
+
+	const key = "0123456789abcdef"
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 10)
+	source := createTestDatabase(t, admin, dsn, "gophervault_backup_src_"+suffix, key)
+	target := createTestDatabase(t, admin, dsn, "gophervault_backup_dst_"+suffix, key)
+
+	require.NoError(t, source.Register(ctx, "arya", "needle"))
+	require.NoError(t, source.SaveFolder(ctx, models.Folder{UserName: "arya", Path: Ptr("travel/braavos")}))
+	require.NoError(t, source.SaveCredentials(ctx, models.Credentials{
+		UserName: "arya", Login: Ptr("arya"), Password: Ptr("valar morghulis"), Site: Ptr("braavos.example"),
+		Folder: Ptr("travel/braavos"), Tags: []string{"faces"},
+	}))
+	require.NoError(t, source.SaveNote(ctx, models.Note{UserName: "arya", Title: Ptr("list"), Content: Ptr("cersei, the mountain")}))
+
+	operator, err := backupfile.GenerateKey()
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	_, written, err := source.Backup(ctx, &buf, operator.PublicKey())
+	require.NoError(t, err)
+
+	_, restored, err := target.Restore(ctx, bytes.NewReader(buf.Bytes()), operator, false)
+	require.NoError(t, err)
+	assert.Equal(t, written, restored)
+
+	// Секреты читаются из восстановленной базы тем же ключом шифрования данных
+	creds, _, err := target.GetCredentials(ctx, models.Credentials{UserName: "arya"}, models.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, creds, 1)
+	assert.Equal(t, "valar morghulis", *creds[0].Password)
+	assert.Equal(t, "travel/braavos", *creds[0].Folder)
+	assert.Equal(t, []string{"faces"}, creds[0].Tags)
+	notes, _, err := target.GetNotes(ctx, models.Note{UserName: "arya"}, models.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, "cersei, the mountain", *notes[0].Content)
+
+	// Журнал изменений восстановлен без новых записей, а последовательности продолжаются после восстановленных строк
+	sourceChanges, err := source.GetChanges(ctx, models.SyncRequest{UserName: "arya"})
+	require.NoError(t, err)
+	targetChanges, err := target.GetChanges(ctx, models.SyncRequest{UserName: "arya"})
+	require.NoError(t, err)
+	assert.Equal(t, sourceChanges.Seq, targetChanges.Seq)
+	require.NoError(t, target.SaveNote(ctx, models.Note{UserName: "arya", Title: Ptr("second"), Content: Ptr("joffrey")}))
+
+	// Повторное восстановление в непустую базу требует force
+	_, _, err = target.Restore(ctx, bytes.NewReader(buf.Bytes()), operator, false)
+	assert.ErrorIs(t, err, ErrBackupTargetNotEmpty)
+	_, _, err = target.Restore(ctx, bytes.NewReader(buf.Bytes()), operator, true)
+	require.NoError(t, err)
+	notes, _, err = target.GetNotes(ctx, models.Note{UserName: "arya"}, models.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, notes, 1)
+}
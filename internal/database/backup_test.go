@@ -0,0 +1,216 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ZnNr/GopherVault/internal/backupfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// backupRows строки таблиц для тестов резервного копирования
+var backupRows = map[string][]string{
+	"notes":            {`{"id":1,"user_name":"arya","title":"list"}`, `{"id":2,"user_name":"arya","title":"faces"}`},
+	"registered_users": {`{"login":"arya","password":"hash"}`},
+}
+
+// expectBackup настраивает ожидания запросов Backup
+func expectBackup(mock sqlmock.Sqlmock) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(schemaVersionQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(17, false))
+	mock.ExpectQuery(regexp.QuoteMeta("select now()")).
+		WillReturnRows(sqlmock.NewRows([]string{"now"}).AddRow(time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)))
+	mock.ExpectQuery(regexp.QuoteMeta(listTablesQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("notes").AddRow("registered_users"))
+	for _, table := range []string{"notes", "registered_users"} {
+		rows := sqlmock.NewRows([]string{"row_to_json"})
+		for _, row := range backupRows[table] {
+			rows.AddRow(row)
+		}
+		mock.ExpectQuery(regexp.QuoteMeta(`select row_to_json(t)::text from "` + table + `" t`)).WillReturnRows(rows)
+	}
+	mock.ExpectRollback()
+}
+
+// makeBackup создает архив с таблицами backupRows
+func makeBackup(t *testing.T, key string) []byte {
+	t.Helper()
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+	expectBackup(mock)
+
+	var buf bytes.Buffer
+	pg := Db{conn: mockDB, encryptionKey: key}
+	_, _, err = pg.Backup(context.Background(), &buf, nil)
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+	return buf.Bytes()
+}
+
+func TestDb_Backup(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+	expectBackup(mock)
+
+	operator, err := backupfile.GenerateKey()
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	pg := Db{conn: mockDB, encryptionKey: "0123456789abcdef"}
+	header, tables, err := pg.Backup(context.Background(), &buf, operator.PublicKey())
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Equal(t, int64(17), header.SchemaVersion)
+	assert.Equal(t, []string{"notes", "registered_users"}, header.Tables)
+	assert.Equal(t, backupfile.Fingerprint([]byte("0123456789abcdef")), header.KeyFingerprint)
+	require.Len(t, tables, 2)
+	assert.Equal(t, int64(2), tables[0].Rows)
+	assert.Equal(t, int64(1), tables[1].Rows)
+
+	// Ключ шифрования данных можно получить из заголовка закрытым ключом оператора
+	key, err := backupfile.UnwrapKey(operator, header.WrappedKey)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("0123456789abcdef"), key)
+	assert.NotContains(t, buf.String(), "faces")
+}
+
+func TestDb_Restore(t *testing.T) {
+	ctx := context.Background()
+	data := makeBackup(t, "0123456789abcdef")
+
+	// expectTarget настраивает ожидания проверки версии схемы и таблиц
+	expectTarget := func(mock sqlmock.Sqlmock, version int64) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(schemaVersionQuery)).
+			WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(version, false))
+		if version != 17 {
+			mock.ExpectRollback()
+			return
+		}
+		mock.ExpectQuery(regexp.QuoteMeta(listTablesQuery)).
+			WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("notes").AddRow("registered_users"))
+	}
+	// expectInsert настраивает ожидания вставки строк и установки последовательностей
+	expectInsert := func(mock sqlmock.Sqlmock) {
+		mock.ExpectExec(regexp.QuoteMeta("set local session_replication_role = replica")).WillReturnResult(sqlmock.NewResult(0, 0))
+		for _, table := range []string{"notes", "registered_users"} {
+			for _, row := range backupRows[table] {
+				mock.ExpectExec(regexp.QuoteMeta(`insert into "` + table + `" select * from json_populate_record(null::"` + table + `", $1)`)).
+					WithArgs(row).WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+		}
+		mock.ExpectQuery(regexp.QuoteMeta("select table_name, column_name from information_schema.columns")).
+			WillReturnRows(sqlmock.NewRows([]string{"table_name", "column_name"}).AddRow("notes", "id"))
+		mock.ExpectExec(regexp.QuoteMeta(`select setval(pg_get_serial_sequence($1, $2), coalesce(max("id"), 0) + 1, false) from "notes"`)).
+			WithArgs("notes", "id").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+	}
+
+	testCases := []struct {
+		name        string
+		key         string
+		force       bool
+		setup       func(mock sqlmock.Sqlmock)
+		expectedErr error
+	}{
+		{
+			name: "positive: empty target",
+			key:  "0123456789abcdef",
+			setup: func(mock sqlmock.Sqlmock) {
+				expectTarget(mock, 17)
+				for _, table := range []string{"notes", "registered_users"} {
+					mock.ExpectQuery(regexp.QuoteMeta(`select exists(select 1 from "` + table + `")`)).
+						WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				}
+				expectInsert(mock)
+			},
+		},
+		{
+			name:  "positive: force replaces data",
+			key:   "0123456789abcdef",
+			force: true,
+			setup: func(mock sqlmock.Sqlmock) {
+				expectTarget(mock, 17)
+				mock.ExpectExec(regexp.QuoteMeta(`truncate table "notes", "registered_users" restart identity cascade`)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				expectInsert(mock)
+			},
+		},
+		{
+			name: "negative: target is not empty",
+			key:  "0123456789abcdef",
+			setup: func(mock sqlmock.Sqlmock) {
+				expectTarget(mock, 17)
+				mock.ExpectQuery(regexp.QuoteMeta(`select exists(select 1 from "notes")`)).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectRollback()
+			},
+			expectedErr: ErrBackupTargetNotEmpty,
+		},
+		{
+			name: "negative: schema version mismatch",
+			key:  "0123456789abcdef",
+			setup: func(mock sqlmock.Sqlmock) {
+				expectTarget(mock, 16)
+			},
+			expectedErr: ErrBackupSchemaMismatch,
+		},
+		{
+			name:        "negative: encryption key mismatch",
+			key:         "fedcba9876543210",
+			setup:       func(mock sqlmock.Sqlmock) {},
+			expectedErr: ErrBackupKeyMismatch,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer mockDB.Close()
+			tt.setup(mock)
+
+			pg := Db{conn: mockDB, encryptionKey: tt.key}
+			header, tables, err := pg.Restore(ctx, bytes.NewReader(data), nil, tt.force)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, int64(17), header.SchemaVersion)
+				assert.Len(t, tables, 2)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDb_Restore_Corrupted(t *testing.T) {
+	data := makeBackup(t, "0123456789abcdef")
+
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(schemaVersionQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(17, false))
+	mock.ExpectQuery(regexp.QuoteMeta(listTablesQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("notes").AddRow("registered_users"))
+	mock.ExpectExec(regexp.QuoteMeta("truncate table")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("set local session_replication_role = replica")).WillReturnResult(sqlmock.NewResult(0, 0))
+	for i := 0; i < 3; i++ {
+		mock.ExpectExec(regexp.QuoteMeta("insert into")).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	// Обрезка архива обнаруживается при чтении строк, поэтому транзакция откатывается без фиксации
+	pg := Db{conn: mockDB, encryptionKey: "0123456789abcdef"}
+	_, _, err = pg.Restore(context.Background(), bytes.NewReader(data[:len(data)-10]), nil, true)
+	assert.ErrorIs(t, err, backupfile.ErrCorrupted)
+}
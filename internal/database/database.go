@@ -6,6 +6,7 @@ import (
 	"crypto/cipher"
 	"database/sql"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/ZnNr/GopherVault/internal/models"
@@ -17,9 +18,16 @@ import (
 )
 
 type Db struct {
-	conn          *sql.DB
-	encryptionKey string
-	dataCipher    cipher.Block
+	conn             *sql.DB
+	encryptionKey    string
+	dataCipher       cipher.Block
+	historyRetention int // число хранимых предыдущих версий секрета; 0 хранит все версии
+}
+
+// queryer выполняет запросы в подключении к базе данных или в транзакции
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
 // New создает новый экземпляр базы данных и возвращает его
@@ -35,9 +43,10 @@ func New(params models.Params) (*Db, error) {
 		return nil, fmt.Errorf("error while creation cipher with key: %w", err)
 	}
 	pg := Db{
-		conn:          conn,
-		encryptionKey: params.EncryptionKey,
-		dataCipher:    c,
+		conn:             conn,
+		encryptionKey:    params.EncryptionKey,
+		dataCipher:       c,
+		historyRetention: params.HistoryRetention,
 	}
 
 	if err = pg.conn.Ping(); err != nil {
@@ -48,24 +57,50 @@ func New(params models.Params) (*Db, error) {
 
 // SaveNote сохраняет заметку в базе данных.
 func (d *Db) SaveNote(ctx context.Context, noteRequest models.Note) error {
-	encryptedContent, err := d.encryptAES(*noteRequest.Content)
+	saveNotesQuery, args, err := d.noteInsert(noteRequest)
 	if err != nil {
-		return fmt.Errorf("error encrypting your classified text: %w", err)
+		return err
 	}
-	saveNotesQuery := "insert into notes (user_name, title, content, metadata) values ($1, $2, $3, $4)"
-	if _, err = d.conn.ExecContext(ctx, saveNotesQuery, noteRequest.UserName, noteRequest.Title, encryptedContent, noteRequest.Metadata); err != nil {
+	if err = d.saveSecret(ctx, notesTable, noteRequest.UserName, noteRequest.Folder, noteRequest.Tags, saveNotesQuery, args...); err != nil {
 		return fmt.Errorf("ошибка при сохранении заметки для пользователя %q: %w", noteRequest.UserName, err)
 	}
 	return nil
 }
 
+// noteInsert возвращает запрос на вставку заметки и его аргументы без идентификатора папки
+func (d *Db) noteInsert(noteRequest models.Note) (string, []interface{}, error) {
+	encryptedContent, err := d.encryptAES(*noteRequest.Content)
+	if err != nil {
+		return "", nil, fmt.Errorf("error encrypting your classified text: %w", err)
+	}
+	fields, err := d.marshalFields(noteRequest.Fields)
+	if err != nil {
+		return "", nil, err
+	}
+	searchTokens := d.noteSearchTokens(noteRequest.UserName, *noteRequest.Content)
+	saveNotesQuery := "insert into notes (user_name, title, content, metadata, fields, search_tokens, folder_id) values ($1, $2, $3, $4, $5, $6, $7) returning id"
+	return saveNotesQuery, []interface{}{noteRequest.UserName, noteRequest.Title, encryptedContent, noteRequest.Metadata, fields, searchTokens}, nil
+}
+
 // GetNotes получает записи заметок из базы данных в соответствии с переданным запросом о заметках.
-func (d *Db) GetNotes(ctx context.Context, noteRequest models.Note) ([]models.Note, error) {
+// Вместе со страницей заметок возвращается курсор следующей страницы, если она есть.
+func (d *Db) GetNotes(ctx context.Context, noteRequest models.Note, opts models.ListOptions) ([]models.Note, string, error) {
 	log.Printf("Выполняется запрос заметок для пользователя: %q", noteRequest.UserName)
 
+	// Проверяем параметры постраничного получения
+	p, err := notesTable.newProjection(opts.Projection)
+	if err != nil {
+		return nil, "", err
+	}
+	keyColumns, err := notesTable.keyColumns(opts.Sort)
+	if err != nil {
+		return nil, "", err
+	}
+
 	// Подготовка аргументов для запроса
 	queryArgs := []interface{}{noteRequest.UserName}
-	query := "select user_name, title, content, metadata from notes where user_name = $1"
+	query := "select user_name, title, " + p.column("content", "content") + ", metadata, " + p.column("fields", "fields") + ", " +
+		notesTable.folderAndTagsColumns() + ", " + auditColumns + ", " + keyColumns + " from notes where user_name = $1 and deleted_at is null"
 
 	// Если указано название заметки, добавляем его в запрос и аргументы
 	if noteRequest.Title != nil {
@@ -73,12 +108,23 @@ func (d *Db) GetNotes(ctx context.Context, noteRequest models.Note) ([]models.No
 		query += fmt.Sprintf(" AND title = $%d", len(queryArgs))
 	}
 
+	// Отбираем заметки по папке и тегам
+	queryArgs, condition := notesTable.filter(queryArgs, noteRequest.Folder, noteRequest.Tags)
+	query += condition
+
+	// Добавляем курсор, сортировку и размер страницы
+	queryArgs, page, err := notesTable.paginate(queryArgs, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	query += page
+
 	// Инициируем запрос к базе данных
 	rows, err := d.conn.QueryContext(ctx, query, queryArgs...)
 	if err != nil {
 		errMsg := fmt.Errorf("ошибка при получении заметок для пользователя %q: %w", noteRequest.UserName, err)
 		log.Println(errMsg)
-		return nil, errMsg
+		return nil, "", errMsg
 	}
 	defer func() {
 		if cerr := rows.Close(); cerr != nil {
@@ -89,192 +135,534 @@ func (d *Db) GetNotes(ctx context.Context, noteRequest models.Note) ([]models.No
 		}
 	}()
 
-	// Объявляем список для хранения заметок
-	var notes []models.Note
-	for rows.Next() {
-		var userName, title, content string
-		var metadata sql.NullString
-		if err := rows.Scan(&userName, &title, &content, &metadata); err != nil {
-			errMsg := fmt.Errorf("ошибка при сканировании строк после запроса на получение заметок пользователя: %w", err)
-			log.Println(errMsg)
-			return nil, errMsg
-		}
+	notes, keys, err := d.scanNotes(rows, p)
+	if err != nil {
+		log.Println(err)
+		return nil, "", err
+	}
 
-		// Расшифровка контента заметки
-		decryptedContent, err := d.decryptAES(content)
-		if err != nil {
-			errMsg := fmt.Errorf("ошибка при расшифровке контента заметки: %w", err)
-			log.Println(errMsg)
-			return nil, errMsg
+	if len(notes) == 0 {
+		log.Printf("Для пользователя %q не найдено заметок", noteRequest.UserName)
+		return nil, "", ErrNoData
+	}
+	log.Printf("Запрос заметок для пользователя %q выполнен успешно", noteRequest.UserName)
+
+	notes, keys, next := nextPage(notes, keys, opts)
+	if err = d.touch(ctx, notesTable, p, keys); err != nil {
+		return nil, "", err
+	}
+	return notes, next, nil
+}
+
+// scanNotes считывает заметки из строк запроса и расшифровывает их содержимое.
+// Строки должны содержать колонки user_name, title, content, metadata, fields, колонки папки и тегов, служебные и ключевые колонки.
+func (d *Db) scanNotes(rows *sql.Rows, p projection) ([]models.Note, []rowKey, error) {
+	var (
+		notes []models.Note
+		keys  []rowKey
+	)
+	for rows.Next() {
+		var userName, title string
+		var key rowKey
+		var audit auditValues
+		var content, metadata, fields, folder, tags sql.NullString
+		dest := append([]interface{}{&userName, &title, &content, &metadata, &fields, &folder, &tags}, audit.dest()...)
+		if err := rows.Scan(append(dest, &key.id, &key.value)...); err != nil {
+			return nil, nil, fmt.Errorf("ошибка при сканировании строк после запроса на получение заметок пользователя: %w", err)
 		}
 
-		// Создание структуры заметки и добавление в список
+		// Создание структуры заметки
 		note := models.Note{
 			UserName: userName,
 			Title:    &title,
-			Content:  &decryptedContent,
+			Audit:    audit.audit(),
+		}
+
+		// Расшифровка контента заметки
+		if content.Valid {
+			decryptedContent, err := d.decryptAES(content.String)
+			if err != nil {
+				return nil, nil, fmt.Errorf("ошибка при расшифровке контента заметки: %w", err)
+			}
+			note.Content = &decryptedContent
 		}
-		if metadata.Valid {
+		if metadata.Valid && p.has("metadata") {
 			note.Metadata = &metadata.String
 		}
+		if fields.Valid {
+			var err error
+			if note.Fields, err = d.unmarshalFields(fields.String); err != nil {
+				return nil, nil, err
+			}
+		}
+		folderPath, tagNames, err := scanFolderAndTags(folder, tags)
+		if err != nil {
+			return nil, nil, err
+		}
+		if p.has("folder") {
+			note.Folder = folderPath
+		}
+		if p.has("tags") {
+			note.Tags = tagNames
+		}
+		note.ID = &key.id
 		notes = append(notes, note)
+		keys = append(keys, key)
 	}
 
-	if len(notes) == 0 {
-		log.Printf("Для пользователя %q не найдено заметок", noteRequest.UserName)
-		return nil, ErrNoData
-	}
-	log.Printf("Запрос заметок для пользователя %q выполнен успешно", noteRequest.UserName)
-
-	return notes, nil
+	return notes, keys, nil
 }
 
-// DeleteNotes удаляет заметки из базы данных в соответствии с переданным запросом о заметке.
+// DeleteNotes перемещает в корзину заметки, соответствующие переданному запросу о заметке.
+// Заметки окончательно удаляются при очистке корзины.
 func (d *Db) DeleteNotes(ctx context.Context, noteRequest models.Note) error {
 	// Подготовка аргументов для запроса
 	args := []interface{}{noteRequest.UserName}
-	deleteNotesQuery := "delete from notes where user_name = $1"
+	where := "user_name = $1 and deleted_at is null"
 
 	// Добавляем критерий выборки по названию заметки, если он указан
 	if noteRequest.Title != nil {
 		args = append(args, *noteRequest.Title)
-		deleteNotesQuery += " AND title = $2"
+		where += " AND title = $2"
 	}
 
+	// Отбираем заметки по папке и тегам
+	args, condition := notesTable.filter(args, noteRequest.Folder, noteRequest.Tags)
+	where += condition
+
 	// Выполняем запрос на удаление заметок
-	if _, err := d.conn.ExecContext(ctx, deleteNotesQuery, args...); err != nil {
+	revisionArgs, condition := revisionCondition(args, noteRequest.Revision)
+	res, err := d.conn.ExecContext(ctx, "update notes set deleted_at = now() where "+where+condition, revisionArgs...)
+	if err != nil {
 		return fmt.Errorf("ошибка при удалении заметок для пользователя %q: %w", noteRequest.UserName, err)
 	}
-	return nil
+	return d.checkDeletedRevision(ctx, res, "notes", where, args, noteRequest.Revision)
 }
 
 // UpdateNote обновляет информацию о заметке в базе данных в соответствии с переданным запросом о заметке.
 func (d *Db) UpdateNote(ctx context.Context, noteRequest models.Note) error {
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении заметки %q для пользователя %q: %w", *noteRequest.Title, noteRequest.UserName, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err = d.updateNote(ctx, tx, noteRequest); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// updateNote обновляет заметку в транзакции и сохраняет ее текущую версию в истории.
+// Пользовательские поля, не указанные в запросе, сохраняют текущие значения; пустой список их очищает.
+func (d *Db) updateNote(ctx context.Context, tx *sql.Tx, noteRequest models.Note) error {
+	// Сохраняем текущую версию заметки в истории
+	if err := d.saveNoteVersion(ctx, tx, &noteRequest); err != nil {
+		if errors.Is(err, ErrRevisionMismatch) {
+			return err
+		}
+		return fmt.Errorf("ошибка при сохранении истории заметки %q для пользователя %q: %w", *noteRequest.Title, noteRequest.UserName, err)
+	}
+
 	// Шифруем контент заметки
 	encryptedContent, err := d.encryptAES(*noteRequest.Content)
 	if err != nil {
 		return fmt.Errorf("ошибка шифрования контента заметки: %w", err)
 	}
 
+	fields, err := d.marshalFields(noteRequest.Fields)
+	if err != nil {
+		return err
+	}
+
+	// Пересчитываем поисковый индекс по новому содержимому
+	searchTokens := d.noteSearchTokens(noteRequest.UserName, *noteRequest.Content)
+
 	// Подготовка и выполнение запроса на обновление заметки
-	updateNoteQuery := "update notes set content = $1, metadata = $2 where user_name = $3 and title = $4"
-	if _, err := d.conn.ExecContext(ctx, updateNoteQuery, encryptedContent, noteRequest.Metadata, noteRequest.UserName, *noteRequest.Title); err != nil {
+	updateNoteQuery := "update notes set content = $1, metadata = $2, fields = $3, search_tokens = $4, updated_at = now(), revision = revision + 1 where user_name = $5 and title = $6 and deleted_at is null"
+	res, err := tx.ExecContext(ctx, updateNoteQuery, encryptedContent, noteRequest.Metadata, fields, searchTokens, noteRequest.UserName, *noteRequest.Title)
+	if err != nil {
 		return fmt.Errorf("ошибка при обновлении заметки %q для пользователя %q: %w", *noteRequest.Title, noteRequest.UserName, err)
 	}
-	return nil
+	return checkUpdated(res)
+}
+
+// replacingNote заполняет не указанные пользовательские поля заметки пустым списком,
+// чтобы обновление заменило заметку целиком
+func replacingNote(note models.Note) models.Note {
+	if note.Fields == nil {
+		note.Fields = []models.CustomField{}
+	}
+	return note
 }
 
 // SaveCredentials сохраняет учетные данные в базе данных.
 func (d *Db) SaveCredentials(ctx context.Context, credentialsRequest models.Credentials) error {
+	saveCredsQuery, args, err := d.credentialsInsert(credentialsRequest)
+	if err != nil {
+		return err
+	}
+	err = d.saveSecret(ctx, credentialsTable, credentialsRequest.UserName, credentialsRequest.Folder, credentialsRequest.Tags, saveCredsQuery, args...)
+	if err != nil {
+		return fmt.Errorf("error while saving credentials for user %q: %w", credentialsRequest.UserName, err)
+	}
+	return nil
+}
+
+// credentialsInsert возвращает запрос на вставку учетных данных и его аргументы без идентификатора папки
+func (d *Db) credentialsInsert(credentialsRequest models.Credentials) (string, []interface{}, error) {
 	// Шифруем пароль с использованием AES
 	encryptedPassword, err := d.encryptAES(*credentialsRequest.Password)
 	if err != nil {
-		return fmt.Errorf("error encrypting your classified text: %w", err)
+		return "", nil, fmt.Errorf("error encrypting your classified text: %w", err)
 	}
 
-	// Запрос для сохранения учетных данных
-	saveCredsQuery := "insert into credentials (user_name, login, password, metadata) values ($1, $2, $3, $4)"
-	_, err = d.conn.ExecContext(ctx, saveCredsQuery, credentialsRequest.UserName, *credentialsRequest.Login, encryptedPassword, credentialsRequest.Metadata)
+	urls, err := marshalURLs(credentialsRequest.URLs)
 	if err != nil {
-		return fmt.Errorf("error while saving credentials for user %q: %w", credentialsRequest.UserName, err)
+		return "", nil, err
 	}
-	return nil
+	fields, err := d.marshalFields(credentialsRequest.Fields)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Запрос для сохранения учетных данных
+	saveCredsQuery := "insert into credentials (user_name, login, password, metadata, site, name, urls, fields, folder_id) values ($1, $2, $3, $4, $5, $6, $7, $8, $9) returning id"
+	return saveCredsQuery, []interface{}{credentialsRequest.UserName, *credentialsRequest.Login, encryptedPassword, credentialsRequest.Metadata,
+		models.ValueOrEmpty(credentialsRequest.Site), credentialsRequest.Name, urls, fields}, nil
 }
 
 // GetCredentials получает учетные данные из базы данных.
-func (d *Db) GetCredentials(ctx context.Context, credentialsRequest models.Credentials) ([]models.Credentials, error) {
+// Вместе со страницей учетных данных возвращается курсор следующей страницы, если она есть.
+func (d *Db) GetCredentials(ctx context.Context, credentialsRequest models.Credentials, opts models.ListOptions) ([]models.Credentials, string, error) {
+	p, err := credentialsTable.newProjection(opts.Projection)
+	if err != nil {
+		return nil, "", err
+	}
+	keyColumns, err := credentialsTable.keyColumns(opts.Sort)
+	if err != nil {
+		return nil, "", err
+	}
+
 	args := []interface{}{credentialsRequest.UserName}
-	getCredsQuery := "select user_name, login, password, metadata from credentials where user_name = $1"
+	getCredsQuery := "select user_name, login, " + p.column("password", "password") + ", metadata, site, name, urls, " + p.column("fields", "fields") + ", " +
+		credentialsTable.folderAndTagsColumns() + ", " + auditColumns + ", " + keyColumns + " from credentials where user_name = $1 and deleted_at is null"
 	if credentialsRequest.Login != nil {
 		args = append(args, *credentialsRequest.Login)
 		getCredsQuery += fmt.Sprintf(" AND login = $%d", len(args))
 	}
+	if credentialsRequest.Site != nil {
+		args = append(args, *credentialsRequest.Site)
+		getCredsQuery += fmt.Sprintf(" AND site = $%d", len(args))
+	}
+	args, condition := credentialsTable.filter(args, credentialsRequest.Folder, credentialsRequest.Tags)
+	getCredsQuery += condition
+	args, page, err := credentialsTable.paginate(args, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	getCredsQuery += page
 	rows, err := d.conn.QueryContext(ctx, getCredsQuery, args...)
 	if err != nil {
-		return nil, fmt.Errorf("error while getting credentials for user %q: %w", credentialsRequest.UserName, err)
+		return nil, "", fmt.Errorf("error while getting credentials for user %q: %w", credentialsRequest.UserName, err)
 	}
 	defer func() {
 		_ = rows.Close()
 		_ = rows.Err()
 	}()
 
-	var creds []models.Credentials
+	creds, keys, err := d.scanCredentials(rows, p)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(creds) == 0 {
+		return nil, "", ErrNoData
+	}
+	creds, keys, next := nextPage(creds, keys, opts)
+	if err = d.touch(ctx, credentialsTable, p, keys); err != nil {
+		return nil, "", err
+	}
+	return creds, next, nil
+}
+
+// scanCredentials считывает учетные данные из строк запроса и расшифровывает пароли.
+// Строки должны содержать колонки user_name, login, password, metadata, site, name, urls, fields, колонки папки и тегов, служебные и ключевые колонки.
+func (d *Db) scanCredentials(rows *sql.Rows, p projection) ([]models.Credentials, []rowKey, error) {
+	var (
+		err   error
+		creds []models.Credentials
+		keys  []rowKey
+	)
 	for rows.Next() {
-		var userName, login, password string
-		var metadata sql.NullString
-		if err = rows.Scan(&userName, &login, &password, &metadata); err != nil {
-			return nil, fmt.Errorf("error while scanning rows after get user credentials query: %w", err)
-		}
-		// Дешифруем пароль
-		decryptedPassword, err := d.decryptAES(password)
-		if err != nil {
-			return nil, fmt.Errorf("error while decrypting password: %w", err)
+		var userName, login, site string
+		var key rowKey
+		var audit auditValues
+		var password, metadata, name, urls, fields, folder, tags sql.NullString
+		dest := append([]interface{}{&userName, &login, &password, &metadata, &site, &name, &urls, &fields, &folder, &tags}, audit.dest()...)
+		if err = rows.Scan(append(dest, &key.id, &key.value)...); err != nil {
+			return nil, nil, fmt.Errorf("error while scanning rows after get user credentials query: %w", err)
 		}
 		res := models.Credentials{
 			UserName: userName,
 			Login:    &login,
-			Password: &decryptedPassword,
+			Audit:    audit.audit(),
+		}
+		// Дешифруем пароль
+		if password.Valid {
+			decryptedPassword, err := d.decryptAES(password.String)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error while decrypting password: %w", err)
+			}
+			res.Password = &decryptedPassword
 		}
-		if metadata.Valid {
+		if metadata.Valid && p.has("metadata") {
 			res.Metadata = &metadata.String
 		}
+		if site != "" {
+			res.Site = &site
+		}
+		if name.Valid && p.has("name") {
+			res.Name = &name.String
+		}
+		if urls.Valid && p.has("urls") {
+			if err = json.Unmarshal([]byte(urls.String), &res.URLs); err != nil {
+				return nil, nil, fmt.Errorf("error while decoding credentials urls: %w", err)
+			}
+		}
+		if fields.Valid {
+			if res.Fields, err = d.unmarshalFields(fields.String); err != nil {
+				return nil, nil, err
+			}
+		}
+		folderPath, tagNames, err := scanFolderAndTags(folder, tags)
+		if err != nil {
+			return nil, nil, err
+		}
+		if p.has("folder") {
+			res.Folder = folderPath
+		}
+		if p.has("tags") {
+			res.Tags = tagNames
+		}
+		res.ID = &key.id
 		creds = append(creds, res)
+		keys = append(keys, key)
 	}
-	if len(creds) == 0 {
-		return nil, ErrNoData
-	}
-	return creds, nil
+	return creds, keys, nil
 }
 
-// DeleteCredentials удаляет учетные данные из базы данных.
+// DeleteCredentials перемещает учетные данные в корзину.
 func (d *Db) DeleteCredentials(ctx context.Context, credentialsRequest models.Credentials) error {
 	args := []any{credentialsRequest.UserName}
-	deleteCredsQuery := "delete from credentials where user_name = $1"
+	where := "user_name = $1 and deleted_at is null"
 	if credentialsRequest.Login != nil {
 
 		args = append(args, *credentialsRequest.Login)
-		deleteCredsQuery += " AND login = $" + strconv.Itoa(len(args))
+		where += " AND login = $" + strconv.Itoa(len(args))
+	}
+	if credentialsRequest.Site != nil {
+		args = append(args, *credentialsRequest.Site)
+		where += " AND site = $" + strconv.Itoa(len(args))
 	}
-	if _, err := d.conn.ExecContext(ctx, deleteCredsQuery, args...); err != nil {
+	args, condition := credentialsTable.filter(args, credentialsRequest.Folder, credentialsRequest.Tags)
+	where += condition
+	revisionArgs, condition := revisionCondition(args, credentialsRequest.Revision)
+	res, err := d.conn.ExecContext(ctx, "update credentials set deleted_at = now() where "+where+condition, revisionArgs...)
+	if err != nil {
 		return fmt.Errorf("ошибка при удалении учетных данных для пользователя %q: %w", credentialsRequest.UserName, err)
 	}
-	return nil
+	return d.checkDeletedRevision(ctx, res, "credentials", where, args, credentialsRequest.Revision)
 }
 
 // UpdateCredentials обновляет учетные данные в базе данных.
 func (d *Db) UpdateCredentials(ctx context.Context, credentialsRequest models.Credentials) error {
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении учетных данных для пользователя %q: %w", credentialsRequest.UserName, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err = d.updateCredentials(ctx, tx, credentialsRequest); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// updateCredentials обновляет учетные данные в транзакции и сохраняет их текущую версию в истории.
+// Название, адреса и пользовательские поля, не указанные в запросе, сохраняют текущие значения;
+// пустое название или пустой список их очищают.
+func (d *Db) updateCredentials(ctx context.Context, tx *sql.Tx, credentialsRequest models.Credentials) error {
+	// Сохраняем текущую версию учетных данных в истории
+	if err := d.saveCredentialsVersion(ctx, tx, &credentialsRequest); err != nil {
+		if errors.Is(err, ErrRevisionMismatch) {
+			return err
+		}
+		return fmt.Errorf("ошибка при сохранении истории учетных данных для пользователя %q: %w", credentialsRequest.UserName, err)
+	}
+
 	encryptedPassword, err := d.encryptAES(*credentialsRequest.Password)
 	if err != nil {
 		return fmt.Errorf("ошибка при шифровании пароля: %w", err)
 	}
-	updateCredsQuery := "update credentials set password = $1, metadata = $2 where user_name = $3 and login = $4"
-	if _, err := d.conn.ExecContext(ctx, updateCredsQuery, encryptedPassword, credentialsRequest.Metadata, credentialsRequest.UserName, *credentialsRequest.Login); err != nil {
+	urls, err := marshalURLs(credentialsRequest.URLs)
+	if err != nil {
+		return err
+	}
+	fields, err := d.marshalFields(credentialsRequest.Fields)
+	if err != nil {
+		return err
+	}
+
+	updateCredsQuery := "update credentials set password = $1, metadata = $2, name = $3, urls = $4, fields = $5, updated_at = now(), revision = revision + 1 where user_name = $6 and login = $7 and site = $8 and deleted_at is null"
+	res, err := tx.ExecContext(ctx, updateCredsQuery, encryptedPassword, credentialsRequest.Metadata, credentialsRequest.Name, urls, fields, credentialsRequest.UserName, *credentialsRequest.Login, models.ValueOrEmpty(credentialsRequest.Site))
+	if err != nil {
 		return fmt.Errorf("ошибка при обновлении учетных данных для пользователя %q: %w", credentialsRequest.UserName, err)
 	}
-	return nil
+	return checkUpdated(res)
+}
+
+// replacingCredentials заполняет не указанные название, адреса и пользовательские поля учетных данных
+// пустыми значениями, чтобы обновление заменило учетные данные целиком
+func replacingCredentials(credentials models.Credentials) models.Credentials {
+	if credentials.Name == nil {
+		credentials.Name = new(string)
+	}
+	if credentials.URLs == nil {
+		credentials.URLs = []models.CredentialURL{}
+	}
+	if credentials.Fields == nil {
+		credentials.Fields = []models.CustomField{}
+	}
+	return credentials
 }
 
 // SaveCard сохраняет данные карты в базе данных.
 func (d *Db) SaveCard(ctx context.Context, cardRequest models.Card) error {
+	saveCardQuery, args, err := d.cardInsert(cardRequest)
+	if err != nil {
+		return err
+	}
+	if err = d.saveSecret(ctx, cardsTable, cardRequest.UserName, cardRequest.Folder, cardRequest.Tags, saveCardQuery, args...); err != nil {
+		return fmt.Errorf("ошибка при сохранении данных карты для пользователя %q: %w", cardRequest.UserName, err)
+	}
+	return nil
+}
+
+// cardInsert возвращает запрос на вставку карты и его аргументы без идентификатора папки
+func (d *Db) cardInsert(cardRequest models.Card) (string, []interface{}, error) {
 	encryptedPassword, err := d.encryptAES(*cardRequest.Password)
 	if err != nil {
-		return fmt.Errorf("ошибка при шифровании пароля карты: %w", err)
+		return "", nil, fmt.Errorf("ошибка при шифровании пароля карты: %w", err)
 	}
 	encryptedCV, err := d.encryptAES(*cardRequest.CV)
 	if err != nil {
-		return fmt.Errorf("ошибка при шифровании CV карты: %w", err)
+		return "", nil, fmt.Errorf("ошибка при шифровании CV карты: %w", err)
 	}
-	saveCardQuery := "insert into cards (user_name, bank_name, number, cv, password, cardType, metadata) values ($1, $2, $3, $4, $5, $6)"
-	if _, err := d.conn.ExecContext(ctx, saveCardQuery, cardRequest.UserName, *cardRequest.BankName, *cardRequest.Number, encryptedCV, encryptedPassword, cardRequest.Metadata); err != nil {
-		return fmt.Errorf("ошибка при сохранении данных карты для пользователя %q: %w", cardRequest.UserName, err)
+	fields, err := d.marshalFields(cardRequest.Fields)
+	if err != nil {
+		return "", nil, err
 	}
-	return nil
+	saveCardQuery := "insert into cards (user_name, bank_name, number, cv, password, card_type, metadata, fields, folder_id) values ($1, $2, $3, $4, $5, $6, $7, $8, $9) returning id"
+	return saveCardQuery, []interface{}{cardRequest.UserName, *cardRequest.BankName, *cardRequest.Number, encryptedCV, encryptedPassword,
+		cardRequest.CardType, cardRequest.Metadata, fields}, nil
+}
+
+// UpdateCard обновляет карту пользователя с указанным номером в базе данных.
+// Если в запросе указана ожидаемая ревизия, а карта отсутствует или изменена, возвращается ErrRevisionMismatch.
+func (d *Db) UpdateCard(ctx context.Context, cardRequest models.Card) error {
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении карты для пользователя %q: %w", cardRequest.UserName, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err = d.updateCard(ctx, tx, cardRequest); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// updateCard обновляет карту в транзакции. Строка карты блокируется до конца транзакции.
+// Банк, CV, пароль, тип карты, метаданные и пользовательские поля, не указанные в запросе, сохраняют текущие значения;
+// пустые метаданные и пустой список пользовательских полей их очищают.
+func (d *Db) updateCard(ctx context.Context, tx *sql.Tx, cardRequest models.Card) error {
+	var (
+		revision int64
+		current  sql.NullString
+	)
+	currentQuery := "select fields, revision from cards where user_name = $1 and number = $2 and deleted_at is null for update"
+	err := tx.QueryRowContext(ctx, currentQuery, cardRequest.UserName, *cardRequest.Number).Scan(&current, &revision)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("ошибка при получении карты для пользователя %q: %w", cardRequest.UserName, err)
+	}
+	if err = checkRevision(cardRequest.Revision, revision); err != nil {
+		return err
+	}
+
+	var encryptedCV, encryptedPassword sql.NullString
+	if cardRequest.CV != nil {
+		if encryptedCV.String, err = d.encryptAES(*cardRequest.CV); err != nil {
+			return fmt.Errorf("ошибка при шифровании CV карты: %w", err)
+		}
+		encryptedCV.Valid = true
+	}
+	if cardRequest.Password != nil {
+		if encryptedPassword.String, err = d.encryptAES(*cardRequest.Password); err != nil {
+			return fmt.Errorf("ошибка при шифровании пароля карты: %w", err)
+		}
+		encryptedPassword.Valid = true
+	}
+	var fields any = current
+	if cardRequest.Fields != nil {
+		if fields, err = d.marshalFields(cardRequest.Fields); err != nil {
+			return err
+		}
+	}
+
+	updateCardQuery := "update cards set bank_name = coalesce($1, bank_name), cv = coalesce($2, cv), password = coalesce($3, password), " +
+		"card_type = coalesce($4, card_type), metadata = nullif(coalesce($5, metadata), ''), fields = $6, updated_at = now(), revision = revision + 1 " +
+		"where user_name = $7 and number = $8 and deleted_at is null"
+	res, err := tx.ExecContext(ctx, updateCardQuery, cardRequest.BankName, encryptedCV, encryptedPassword, cardRequest.CardType,
+		cardRequest.Metadata, fields, cardRequest.UserName, *cardRequest.Number)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении карты для пользователя %q: %w", cardRequest.UserName, err)
+	}
+	return checkUpdated(res)
+}
+
+// replacingCard заполняет не указанные метаданные и пользовательские поля карты пустыми значениями,
+// чтобы обновление заменило карту целиком
+func replacingCard(card models.Card) models.Card {
+	if card.Metadata == nil {
+		card.Metadata = new(string)
+	}
+	if card.Fields == nil {
+		card.Fields = []models.CustomField{}
+	}
+	return card
 }
 
 // GetCard извлекает карты из базы данных на основе запроса.
-func (d *Db) GetCard(ctx context.Context, cardRequest models.Card) ([]models.Card, error) {
+// Вместе со страницей карт возвращается курсор следующей страницы, если она есть.
+func (d *Db) GetCard(ctx context.Context, cardRequest models.Card, opts models.ListOptions) ([]models.Card, string, error) {
+	p, err := cardsTable.newProjection(opts.Projection)
+	if err != nil {
+		return nil, "", err
+	}
+	keyColumns, err := cardsTable.keyColumns(opts.Sort)
+	if err != nil {
+		return nil, "", err
+	}
+
 	args := []interface{}{cardRequest.UserName}
-	getCardsQuery := "select user_name, bank_name, number, cv, password, cardType, metadata from cards where user_name = $1"
+	getCardsQuery := "select user_name, bank_name, number, " + p.column("cv", "cv") + ", " + p.column("password", "password") + ", card_type, metadata, " +
+		p.column("fields", "fields") + ", " + cardsTable.folderAndTagsColumns() + ", " + auditColumns + ", " + keyColumns + " from cards where user_name = $1 and deleted_at is null"
 	if cardRequest.BankName != nil {
 		args = append(args, *cardRequest.BankName)
 		getCardsQuery += fmt.Sprintf(" AND bank_name = $%d", len(args))
@@ -283,64 +671,120 @@ func (d *Db) GetCard(ctx context.Context, cardRequest models.Card) ([]models.Car
 		args = append(args, *cardRequest.Number)
 		getCardsQuery += fmt.Sprintf(" AND number = $%d", len(args))
 	}
+	args, condition := cardsTable.filter(args, cardRequest.Folder, cardRequest.Tags)
+	getCardsQuery += condition
+	args, page, err := cardsTable.paginate(args, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	getCardsQuery += page
 	rows, err := d.conn.QueryContext(ctx, getCardsQuery, args...)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка при получении карт для пользователя %q: %w", cardRequest.UserName, err)
+		return nil, "", fmt.Errorf("ошибка при получении карт для пользователя %q: %w", cardRequest.UserName, err)
 	}
 	defer func() {
 		_ = rows.Close()
 	}()
 
-	var cards []models.Card
+	cards, keys, err := d.scanCards(rows, p)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(cards) == 0 {
+		return nil, "", ErrNoData
+	}
+	cards, keys, next := nextPage(cards, keys, opts)
+	if err = d.touch(ctx, cardsTable, p, keys); err != nil {
+		return nil, "", err
+	}
+	return cards, next, nil
+}
+
+// scanCards считывает карты из строк запроса и расшифровывает CV и пароли.
+// Строки должны содержать колонки user_name, bank_name, number, cv, password, card_type, metadata, fields, колонки папки и тегов, служебные и ключевые колонки.
+func (d *Db) scanCards(rows *sql.Rows, p projection) ([]models.Card, []rowKey, error) {
+	var (
+		err   error
+		cards []models.Card
+		keys  []rowKey
+	)
 	for rows.Next() {
-		var userName, bankName, number, cv, password, cardType string
-		var metadata sql.NullString
-		if err = rows.Scan(&userName, &bankName, &number, &cv, &password, &cardType, &metadata); err != nil {
-			return nil, fmt.Errorf("ошибка при сканировании строк после запроса на получение заметок пользователя: %w", err)
-		}
-		decryptedPassword, err := d.decryptAES(password)
-		if err != nil {
-			return nil, fmt.Errorf("ошибка при расшифровке пароля: %w", err)
-		}
-		decryptedCV, err := d.decryptAES(cv)
-		if err != nil {
-			return nil, fmt.Errorf("ошибка при расшифровке CV: %w", err)
+		var userName, bankName, number string
+		var key rowKey
+		var audit auditValues
+		var cv, password, cardType, metadata, fields, folder, tags sql.NullString
+		dest := append([]interface{}{&userName, &bankName, &number, &cv, &password, &cardType, &metadata, &fields, &folder, &tags}, audit.dest()...)
+		if err = rows.Scan(append(dest, &key.id, &key.value)...); err != nil {
+			return nil, nil, fmt.Errorf("ошибка при сканировании строк после запроса на получение заметок пользователя: %w", err)
 		}
 		res := models.Card{
 			UserName: userName,
 			BankName: &bankName,
 			Number:   &number,
-			CV:       &decryptedCV,
-			Password: &decryptedPassword,
-			CardType: &cardType,
+			Audit:    audit.audit(),
+		}
+		if password.Valid {
+			decryptedPassword, err := d.decryptAES(password.String)
+			if err != nil {
+				return nil, nil, fmt.Errorf("ошибка при расшифровке пароля: %w", err)
+			}
+			res.Password = &decryptedPassword
 		}
-		if metadata.Valid {
+		if cv.Valid {
+			decryptedCV, err := d.decryptAES(cv.String)
+			if err != nil {
+				return nil, nil, fmt.Errorf("ошибка при расшифровке CV: %w", err)
+			}
+			res.CV = &decryptedCV
+		}
+		if cardType.Valid && p.has("card_type") {
+			res.CardType = &cardType.String
+		}
+		if metadata.Valid && p.has("metadata") {
 			res.Metadata = &metadata.String
 		}
+		if fields.Valid {
+			if res.Fields, err = d.unmarshalFields(fields.String); err != nil {
+				return nil, nil, err
+			}
+		}
+		folderPath, tagNames, err := scanFolderAndTags(folder, tags)
+		if err != nil {
+			return nil, nil, err
+		}
+		if p.has("folder") {
+			res.Folder = folderPath
+		}
+		if p.has("tags") {
+			res.Tags = tagNames
+		}
+		res.ID = &key.id
 		cards = append(cards, res)
+		keys = append(keys, key)
 	}
-	if len(cards) == 0 {
-		return nil, ErrNoData
-	}
-	return cards, nil
+	return cards, keys, nil
 }
 
-// DeleteCards удаляет карты из базы данных на основе запроса.
+// DeleteCards перемещает в корзину карты, соответствующие запросу.
 func (d *Db) DeleteCards(ctx context.Context, cardRequest models.Card) error {
 	args := []interface{}{cardRequest.UserName}
-	deleteNotesQuery := "delete from cards where user_name = $1"
+	where := "user_name = $1 and deleted_at is null"
 	if cardRequest.Number != nil {
 		args = append(args, *cardRequest.Number)
-		deleteNotesQuery += fmt.Sprintf(" AND number = $%d", len(args))
+		where += fmt.Sprintf(" AND number = $%d", len(args))
 	}
 	if cardRequest.BankName != nil {
 		args = append(args, *cardRequest.BankName)
-		deleteNotesQuery += fmt.Sprintf(" AND bank_name = $%d", len(args))
+		where += fmt.Sprintf(" AND bank_name = $%d", len(args))
 	}
-	if _, err := d.conn.ExecContext(ctx, deleteNotesQuery, args...); err != nil {
+	args, condition := cardsTable.filter(args, cardRequest.Folder, cardRequest.Tags)
+	where += condition
+	revisionArgs, condition := revisionCondition(args, cardRequest.Revision)
+	res, err := d.conn.ExecContext(ctx, "update cards set deleted_at = now() where "+where+condition, revisionArgs...)
+	if err != nil {
 		return fmt.Errorf("ошибка при удалении карт для пользователя %q: %w", cardRequest.UserName, err)
 	}
-	return nil
+	return d.checkDeletedRevision(ctx, res, "cards", where, args, cardRequest.Revision)
 }
 
 // Login проверяет учетные данные пользователя в базе данных.
@@ -368,8 +812,7 @@ func (d *Db) Register(ctx context.Context, login string, password string) error
 	}
 	registerUser := `insert into registered_users values ($1, $2)`
 	if _, err = d.conn.ExecContext(ctx, registerUser, login, hash); err != nil {
-		duplicateKeyErr := ErrDuplicateKey{Key: "registered_users_pkey"}
-		if err.Error() == duplicateKeyErr.Error() {
+		if asConflictError(err) != nil {
 			return ErrUserAlreadyExists
 		}
 		return fmt.Errorf("ошибка при выполнении запроса на регистрацию пользователя: %w", err)
@@ -382,6 +825,65 @@ func (d *Db) Close() error {
 	return d.conn.Close()
 }
 
+// marshalURLs кодирует адреса учетных данных в JSON для сохранения в колонке urls.
+// Для пустого списка возвращает nil, чтобы в базе сохранялось значение NULL.
+func marshalURLs(urls []models.CredentialURL) (any, error) {
+	if len(urls) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(urls)
+	if err != nil {
+		return nil, fmt.Errorf("error while encoding credentials urls: %w", err)
+	}
+	return string(b), nil
+}
+
+// marshalFields кодирует пользовательские поля в JSON для сохранения в колонке fields.
+// Значения скрытых полей шифруются так же, как пароли. Для пустого списка возвращает nil.
+func (d *Db) marshalFields(fields []models.CustomField) (any, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	stored := make([]models.CustomField, len(fields))
+	for i, f := range fields {
+		if f.Type == "" {
+			f.Type = models.FieldText
+		}
+		if f.Type == models.FieldHidden {
+			encrypted, err := d.encryptAES(f.Value)
+			if err != nil {
+				return nil, fmt.Errorf("ошибка при шифровании поля %q: %w", f.Name, err)
+			}
+			f.Value = encrypted
+		}
+		stored[i] = f
+	}
+	b, err := json.Marshal(stored)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при кодировании пользовательских полей: %w", err)
+	}
+	return string(b), nil
+}
+
+// unmarshalFields декодирует пользовательские поля из колонки fields и расшифровывает скрытые значения.
+func (d *Db) unmarshalFields(data string) ([]models.CustomField, error) {
+	var fields []models.CustomField
+	if err := json.Unmarshal([]byte(data), &fields); err != nil {
+		return nil, fmt.Errorf("ошибка при декодировании пользовательских полей: %w", err)
+	}
+	for i, f := range fields {
+		if f.Type != models.FieldHidden {
+			continue
+		}
+		decrypted, err := d.decryptAES(f.Value)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при расшифровке поля %q: %w", f.Name, err)
+		}
+		fields[i].Value = decrypted
+	}
+	return fields, nil
+}
+
 // encryptAES выполняет шифрование переданного текста с использованием AES и возвращает зашифрованный текст в виде base64 закодированной строки.
 func (d *Db) encryptAES(plaintext string) (string, error) {
 	// Инициализируем шифр с использованием режима CFB
@@ -3,10 +3,13 @@ package database
 import (
 	"context"
 	"crypto/aes"
+	"database/sql"
 	"errors"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ZnNr/GopherVault/internal/blindindex"
 	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/crypto/bcrypt"
 	"testing"
@@ -41,7 +44,7 @@ func TestDb_Register(t *testing.T) {
 		defer mockDB.Close()
 
 		mock.ExpectExec("insert into registered_users values").
-			WillReturnError(ErrDuplicateKey{Key: "registered_users_pkey"})
+			WillReturnError(&pq.Error{Code: uniqueViolationCode, Constraint: "registered_users_pkey"})
 
 		pg := Db{
 			conn: mockDB,
@@ -158,17 +161,22 @@ func TestDb_GetCredentials(t *testing.T) {
 				Login:    Ptr("killer"),
 				Password: Ptr("sansaisfreak"),
 				Metadata: Ptr("bla bla password"),
+				Audit:    auditID(1),
 			},
 			{
 				UserName: userLogin,
 				Login:    Ptr("warrior"),
 				Password: Ptr("valarmorgulis"),
 				Metadata: Ptr("valar dohaeris"),
+				Name:     Ptr("Braavos"),
+				URLs:     []models.CredentialURL{{URL: "https://braavos.com", Match: models.MatchHost}},
+				Audit:    auditID(2),
 			},
 			{
 				UserName: userLogin,
 				Login:    Ptr("avenger"),
 				Password: Ptr("qwerty12"),
+				Audit:    auditID(3),
 			},
 		}
 
@@ -178,21 +186,23 @@ func TestDb_GetCredentials(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectQuery("select user_name, login, password, metadata from credentials where user_name").
+		mock.ExpectQuery("select user_name, login, password, metadata, site, name, urls, fields, .+ from credentials where user_name").
 			WithArgs(userLogin).
-			WillReturnRows(sqlmock.NewRows([]string{"user_name", "login", "password", "metadata"}).
-				AddRow(userLogin, "killer", "zwkcxfLKNXGHrfgP", "bla bla password").
-				AddRow(userLogin, "warrior", "ygke1+HOKWWSvfUNiQ==", "valar dohaeris").
-				AddRow(userLogin, "avenger", "zR8XxOfadyU=", nil))
+			WillReturnRows(sqlmock.NewRows([]string{"user_name", "login", "password", "metadata", "site", "name", "urls", "fields", "folder", "tags", "created_at", "updated_at", "last_accessed_at", "revision", "id", "sort_key"}).
+				AddRow(userLogin, "killer", "zwkcxfLKNXGHrfgP", "bla bla password", "", nil, nil, nil, nil, nil, nil, nil, nil, nil, 1, "1").
+				AddRow(userLogin, "warrior", "ygke1+HOKWWSvfUNiQ==", "valar dohaeris", "", "Braavos", `[{"url":"https://braavos.com","match":"host"}]`, nil, nil, nil, nil, nil, nil, nil, 2, "2").
+				AddRow(userLogin, "avenger", "zR8XxOfadyU=", nil, "", nil, nil, nil, nil, nil, nil, nil, nil, nil, 3, "3"))
+		mock.ExpectExec("update credentials set last_accessed_at").WillReturnResult(sqlmock.NewResult(0, 1))
 
 		pg := Db{
 			conn:          mockDB,
 			encryptionKey: key,
 			dataCipher:    c,
 		}
-		creds, err := pg.GetCredentials(
+		creds, _, err := pg.GetCredentials(
 			ctx,
 			models.Credentials{UserName: userLogin},
+			models.ListOptions{},
 		)
 		assert.NoError(t, err)
 		assert.Equal(t, expected, creds)
@@ -204,18 +214,18 @@ func TestDb_GetCredentials(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectQuery("select user_name, login, password, metadata from credentials where user_name").
+		mock.ExpectQuery("select user_name, login, password, metadata, site, name, urls, fields, .+ from credentials where user_name").
 			WithArgs(userLogin).
-			WillReturnRows(sqlmock.NewRows([]string{"user_name", "login", "password", "metadata"}))
+			WillReturnRows(sqlmock.NewRows([]string{"user_name", "login", "password", "metadata", "site", "name", "urls", "fields", "folder", "tags", "created_at", "updated_at", "last_accessed_at", "revision", "id", "sort_key"}))
 
 		pg := Db{
 			conn:          mockDB,
 			encryptionKey: key,
 			dataCipher:    c,
 		}
-		_, err = pg.GetCredentials(ctx, models.Credentials{
+		_, _, err = pg.GetCredentials(ctx, models.Credentials{
 			UserName: userLogin,
-		})
+		}, models.ListOptions{})
 		assert.EqualError(t, err, ErrNoData.Error())
 	})
 	t.Run("negative: query error", func(t *testing.T) {
@@ -225,7 +235,7 @@ func TestDb_GetCredentials(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectQuery("select user_name, login, password, metadata from credentials where user_name").
+		mock.ExpectQuery("select user_name, login, password, metadata, site, name, urls, fields, .+ from credentials where user_name").
 			WithArgs(userLogin).
 			WillReturnError(errors.New("query error"))
 
@@ -234,13 +244,41 @@ func TestDb_GetCredentials(t *testing.T) {
 			encryptionKey: key,
 			dataCipher:    c,
 		}
-		_, err = pg.GetCredentials(ctx, models.Credentials{
+		_, _, err = pg.GetCredentials(ctx, models.Credentials{
 			UserName: userLogin,
-		})
+		}, models.ListOptions{})
 		assert.EqualError(t, err, "error while getting credentials for user \"arya\": query error")
 	})
 }
 
+func TestDb_unmarshalFields(t *testing.T) {
+	key := "thisis32bitlongpassphraseimusing"
+	c, _ := aes.NewCipher([]byte(key))
+	pg := Db{
+		encryptionKey: key,
+		dataCipher:    c,
+	}
+	fields := []models.CustomField{
+		{Name: "sigil", Type: models.FieldText, Value: "lion"},
+		{Name: "vault", Type: models.FieldHidden, Value: "casterly rock"},
+		{Name: "born", Type: models.FieldDate, Value: "2001-01-01"},
+	}
+
+	t.Run("positive: hidden values are decrypted", func(t *testing.T) {
+		stored, err := pg.marshalFields(fields)
+		assert.NoError(t, err)
+		assert.NotContains(t, stored, "casterly rock")
+
+		decoded, err := pg.unmarshalFields(stored.(string))
+		assert.NoError(t, err)
+		assert.Equal(t, fields, decoded)
+	})
+	t.Run("negative: invalid json", func(t *testing.T) {
+		_, err := pg.unmarshalFields("{")
+		assert.Error(t, err)
+	})
+}
+
 func TestDb_SaveCredentials(t *testing.T) {
 	key := "thisis32bitlongpassphraseimusing"
 	c, _ := aes.NewCipher([]byte(key))
@@ -259,9 +297,11 @@ func TestDb_SaveCredentials(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectExec("insert into credentials").
-			WithArgs(credentials.UserName, credentials.Login, "1QQdwPbUL3mQ", credentials.Metadata).
-			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectBegin()
+		mock.ExpectQuery("insert into credentials").
+			WithArgs(credentials.UserName, credentials.Login, "1QQdwPbUL3mQ", credentials.Metadata, "", nil, nil, nil, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
 
 		pg := Db{
 			conn:          mockDB,
@@ -279,9 +319,11 @@ func TestDb_SaveCredentials(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectExec("insert into credentials").
-			WithArgs(credentials.UserName, credentials.Login, "1QQdwPbUL3mQ", nil).
-			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectBegin()
+		mock.ExpectQuery("insert into credentials").
+			WithArgs(credentials.UserName, credentials.Login, "1QQdwPbUL3mQ", nil, "", nil, nil, nil, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
 
 		pg := Db{
 			conn:          mockDB,
@@ -299,9 +341,11 @@ func TestDb_SaveCredentials(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectExec("insert into credentials").
-			WithArgs(credentials.UserName, credentials.Login, "1QQdwPbUL3mQ", nil).
+		mock.ExpectBegin()
+		mock.ExpectQuery("insert into credentials").
+			WithArgs(credentials.UserName, credentials.Login, "1QQdwPbUL3mQ", nil, "", nil, nil, nil, nil).
 			WillReturnError(errors.New("exec error"))
+		mock.ExpectRollback()
 
 		pg := Db{
 			conn:          mockDB,
@@ -312,6 +356,82 @@ func TestDb_SaveCredentials(t *testing.T) {
 		err = pg.SaveCredentials(ctx, credentials)
 		assert.EqualError(t, err, "error while saving credentials for user \"tirion\": exec error")
 	})
+	t.Run("positive: with name and urls", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("insert into credentials").
+			WithArgs(credentials.UserName, credentials.Login, "1QQdwPbUL3mQ", nil, "", "Lannisport", `[{"url":"lannisport.com","match":"host"}]`, nil, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		withURLs := credentials
+		withURLs.Metadata = nil
+		withURLs.Name = Ptr("Lannisport")
+		withURLs.URLs = []models.CredentialURL{{URL: "lannisport.com", Match: models.MatchHost}}
+		err = pg.SaveCredentials(ctx, withURLs)
+		assert.NoError(t, err)
+	})
+	t.Run("positive: with custom fields", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		encryptedPin, _ := pg.encryptAES("1234")
+		mock.ExpectBegin()
+		mock.ExpectQuery("insert into credentials").
+			WithArgs(credentials.UserName, credentials.Login, "1QQdwPbUL3mQ", nil, "", nil, nil,
+				`[{"name":"house","type":"text","value":"lannister"},{"name":"pin","type":"hidden","value":"`+encryptedPin+`"}]`, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
+
+		withFields := credentials
+		withFields.Metadata = nil
+		withFields.Fields = []models.CustomField{
+			{Name: "house", Value: "lannister"},
+			{Name: "pin", Type: models.FieldHidden, Value: "1234"},
+		}
+		err = pg.SaveCredentials(ctx, withFields)
+		assert.NoError(t, err)
+	})
+	t.Run("negative: credentials already exist", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("insert into credentials").
+			WithArgs(credentials.UserName, credentials.Login, "1QQdwPbUL3mQ", nil, "", nil, nil, nil, nil).
+			WillReturnError(&pq.Error{Code: uniqueViolationCode, Constraint: "credentials_user_login_site_key"})
+		mock.ExpectRollback()
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		credentials.Metadata = nil
+		err = pg.SaveCredentials(ctx, credentials)
+		assert.ErrorIs(t, err, ErrConflict)
+	})
 }
 
 func TestDb_DeleteCredentials(t *testing.T) {
@@ -326,7 +446,7 @@ func TestDb_DeleteCredentials(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectExec("delete from credentials").
+		mock.ExpectExec("update credentials set deleted_at = now\\(\\) where user_name = \\$1 and deleted_at is null").
 			WithArgs(user).
 			WillReturnResult(sqlmock.NewResult(0, 0))
 
@@ -345,7 +465,7 @@ func TestDb_DeleteCredentials(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectExec("delete from credentials").
+		mock.ExpectExec("update credentials set deleted_at = now\\(\\) where user_name = \\$1 and deleted_at is null").
 			WithArgs(user, &login).
 			WillReturnResult(sqlmock.NewResult(0, 0))
 
@@ -365,7 +485,7 @@ func TestDb_DeleteCredentials(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectExec("delete from credentials").
+		mock.ExpectExec("update credentials set deleted_at = now\\(\\) where user_name = \\$1 and deleted_at is null").
 			WithArgs(user).
 			WillReturnError(errors.New("some error"))
 
@@ -384,7 +504,7 @@ func TestDb_DeleteCredentials(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectExec("delete from credentials").
+		mock.ExpectExec("update credentials set deleted_at = now\\(\\) where user_name = \\$1 and deleted_at is null").
 			WithArgs(user, &login).
 			WillReturnError(errors.New("some error"))
 
@@ -417,9 +537,18 @@ func TestDb_UpdateCredentials(t *testing.T) {
 		}
 		defer mockDB.Close()
 
+		mock.ExpectBegin()
+		mock.ExpectQuery("select id, password, metadata, site, name, urls, fields, revision from credentials where user_name = \\$1 and login = \\$2 and site = \\$3 and deleted_at is null for update").
+			WithArgs(credentials.UserName, *credentials.Login, "").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "password", "metadata", "site", "name", "urls", "fields", "revision"}).
+				AddRow(7, "zR8XxOfadyU=", nil, "", nil, nil, nil, 2))
+		mock.ExpectExec("insert into secret_history").
+			WithArgs(credentials.UserName, models.SecretCredentials, 7, 2, sqlmock.AnyArg(), pq.Array([]string{"metadata", "password"}), credentials.UserName).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectExec("update credentials set password").
-			WithArgs("1QQdwPbUL3mQ", credentials.Metadata, credentials.UserName, credentials.Login).
-			WillReturnResult(sqlmock.NewResult(0, 0))
+			WithArgs("1QQdwPbUL3mQ", credentials.Metadata, nil, nil, nil, credentials.UserName, credentials.Login, "").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
 
 		pg := Db{
 			conn:          mockDB,
@@ -428,6 +557,7 @@ func TestDb_UpdateCredentials(t *testing.T) {
 		}
 		err = pg.UpdateCredentials(ctx, credentials)
 		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("positive: without metadata", func(t *testing.T) {
@@ -437,9 +567,14 @@ func TestDb_UpdateCredentials(t *testing.T) {
 		}
 		defer mockDB.Close()
 
+		mock.ExpectBegin()
+		mock.ExpectQuery("select id, password, .+ from credentials .+ for update").
+			WithArgs(credentials.UserName, *credentials.Login, "").
+			WillReturnError(sql.ErrNoRows)
 		mock.ExpectExec("update credentials set password").
-			WithArgs("1QQdwPbUL3mQ", nil, credentials.UserName, credentials.Login).
-			WillReturnResult(sqlmock.NewResult(0, 0))
+			WithArgs("1QQdwPbUL3mQ", nil, nil, nil, nil, credentials.UserName, credentials.Login, "").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
 
 		pg := Db{
 			conn:          mockDB,
@@ -450,6 +585,31 @@ func TestDb_UpdateCredentials(t *testing.T) {
 		err = pg.UpdateCredentials(ctx, credentials)
 		assert.NoError(t, err)
 	})
+	t.Run("negative: credentials are gone", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("select id, password, .+ from credentials .+ for update").
+			WithArgs(credentials.UserName, *credentials.Login, "").
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectExec("update credentials set password").
+			WithArgs("1QQdwPbUL3mQ", nil, nil, nil, nil, credentials.UserName, credentials.Login, "").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		credentials.Metadata = nil
+		assert.ErrorIs(t, pg.UpdateCredentials(ctx, credentials), ErrNoData)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 	t.Run("negative: exec error", func(t *testing.T) {
 		mockDB, mock, err := sqlmock.New()
 		if err != nil {
@@ -457,9 +617,14 @@ func TestDb_UpdateCredentials(t *testing.T) {
 		}
 		defer mockDB.Close()
 
+		mock.ExpectBegin()
+		mock.ExpectQuery("select id, password, .+ from credentials .+ for update").
+			WithArgs(credentials.UserName, *credentials.Login, "").
+			WillReturnError(sql.ErrNoRows)
 		mock.ExpectExec("update credentials set password").
-			WithArgs("1QQdwPbUL3mQ", nil, credentials.UserName, credentials.Login).
+			WithArgs("1QQdwPbUL3mQ", nil, nil, nil, nil, credentials.UserName, credentials.Login, "").
 			WillReturnError(errors.New("exec error"))
+		mock.ExpectRollback()
 
 		pg := Db{
 			conn:          mockDB,
@@ -470,6 +635,70 @@ func TestDb_UpdateCredentials(t *testing.T) {
 		err = pg.UpdateCredentials(ctx, credentials)
 		assert.EqualError(t, err, "ошибка при обновлении учетных данных для пользователя \"tirion\": exec error")
 	})
+	t.Run("positive: missing name, urls and fields keep current values", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		urls := `[{"url":"https://casterlyrock.org","match":"host"}]`
+		fields := `[{"name":"house","type":"text","value":"lannister"}]`
+		mock.ExpectBegin()
+		mock.ExpectQuery("select id, password, .+ from credentials .+ for update").
+			WithArgs(credentials.UserName, *credentials.Login, "").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "password", "metadata", "site", "name", "urls", "fields", "revision"}).
+				AddRow(7, "zR8XxOfadyU=", nil, "", "Hand", urls, fields, 2))
+		mock.ExpectExec("insert into secret_history").
+			WithArgs(credentials.UserName, models.SecretCredentials, 7, 2, sqlmock.AnyArg(), pq.Array([]string{"password"}), credentials.UserName).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("update credentials set password").
+			WithArgs("1QQdwPbUL3mQ", nil, "Hand", urls, fields, credentials.UserName, credentials.Login, "").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		credentials.Metadata = nil
+		err = pg.UpdateCredentials(ctx, credentials)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("positive: empty name and lists clear current values", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("select id, password, .+ from credentials .+ for update").
+			WithArgs(credentials.UserName, *credentials.Login, "").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "password", "metadata", "site", "name", "urls", "fields", "revision"}).
+				AddRow(7, "zR8XxOfadyU=", nil, "", "Hand", `[{"url":"https://casterlyrock.org","match":"host"}]`, nil, 2))
+		mock.ExpectExec("insert into secret_history").
+			WithArgs(credentials.UserName, models.SecretCredentials, 7, 2, sqlmock.AnyArg(), pq.Array([]string{"name", "password", "urls"}), credentials.UserName).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("update credentials set password").
+			WithArgs("1QQdwPbUL3mQ", nil, nil, nil, nil, credentials.UserName, credentials.Login, "").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		cleared := credentials
+		cleared.Metadata = nil
+		cleared.Name, cleared.URLs = Ptr(""), []models.CredentialURL{}
+		err = pg.UpdateCredentials(ctx, cleared)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
 func TestDb_SaveNote(t *testing.T) {
@@ -481,6 +710,7 @@ func TestDb_SaveNote(t *testing.T) {
 		Content:  Ptr("some note content"),
 		Metadata: Ptr("podric's best note"),
 	}
+	noteTokens := pq.Array(blindindex.Tokens(blindindex.DeriveKey(key), note.UserName, *note.Content))
 	ctx := context.Background()
 
 	t.Run("positive: with metadata", func(t *testing.T) {
@@ -490,9 +720,11 @@ func TestDb_SaveNote(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectExec("insert into notes").
-			WithArgs(note.UserName, *note.Title, "zwcf07PNKWOQ6PoLlO+3uU4=", note.Metadata).
-			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectBegin()
+		mock.ExpectQuery("insert into notes").
+			WithArgs(note.UserName, *note.Title, "zwcf07PNKWOQ6PoLlO+3uU4=", note.Metadata, nil, noteTokens, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
 
 		pg := Db{
 			conn:          mockDB,
@@ -510,9 +742,11 @@ func TestDb_SaveNote(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectExec("insert into notes").
-			WithArgs(note.UserName, *note.Title, "zwcf07PNKWOQ6PoLlO+3uU4=", nil).
-			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectBegin()
+		mock.ExpectQuery("insert into notes").
+			WithArgs(note.UserName, *note.Title, "zwcf07PNKWOQ6PoLlO+3uU4=", nil, nil, noteTokens, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
 
 		pg := Db{
 			conn:          mockDB,
@@ -530,9 +764,11 @@ func TestDb_SaveNote(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectExec("insert into notes").
-			WithArgs(note.UserName, note.Title, "zwcf07PNKWOQ6PoLlO+3uU4=", nil).
+		mock.ExpectBegin()
+		mock.ExpectQuery("insert into notes").
+			WithArgs(note.UserName, note.Title, "zwcf07PNKWOQ6PoLlO+3uU4=", nil, nil, noteTokens, nil).
 			WillReturnError(errors.New("exec error"))
+		mock.ExpectRollback()
 
 		pg := Db{
 			conn:          mockDB,
@@ -558,17 +794,20 @@ func TestDb_GetNote(t *testing.T) {
 				Title:    Ptr("notes from dorne"),
 				Content:  Ptr("some lovely notes"),
 				Metadata: Ptr("love"),
+				Audit:    auditID(1),
 			},
 			{
 				UserName: userLogin,
 				Title:    Ptr("notes from king's landing"),
 				Content:  Ptr("some not lovely notes"),
 				Metadata: Ptr("my worst days"),
+				Audit:    auditID(2),
 			},
 			{
 				UserName: userLogin,
 				Title:    Ptr("my dear diary"),
 				Content:  Ptr("personal notes"),
+				Audit:    auditID(3),
 			},
 		}
 
@@ -578,21 +817,22 @@ func TestDb_GetNote(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectQuery("select user_name, title, content, metadata from notes where user_name").
+		mock.ExpectQuery("select user_name, title, content, metadata, fields, .+ from notes where user_name").
 			WithArgs(userLogin).
-			WillReturnRows(sqlmock.NewRows([]string{"user_name", "title", "content", "metadata"}).
-				AddRow(userLogin, "notes from dorne", "zwcf07PPKWGQpOBElPSmsjQ=", "love").
-				AddRow(userLogin, "notes from king's landing", "zwcf07PNKWPVpPYSn/er95LFBKDJ", "my worst days").
-				AddRow(userLogin, "my dear diary", "zA0AxfzNJ3vVpvYQn+g=", nil))
+			WillReturnRows(sqlmock.NewRows([]string{"user_name", "title", "content", "metadata", "fields", "folder", "tags", "created_at", "updated_at", "last_accessed_at", "revision", "id", "sort_key"}).
+				AddRow(userLogin, "notes from dorne", "zwcf07PPKWGQpOBElPSmsjQ=", "love", nil, nil, nil, nil, nil, nil, nil, 1, "1").
+				AddRow(userLogin, "notes from king's landing", "zwcf07PNKWPVpPYSn/er95LFBKDJ", "my worst days", nil, nil, nil, nil, nil, nil, nil, 2, "2").
+				AddRow(userLogin, "my dear diary", "zA0AxfzNJ3vVpvYQn+g=", nil, nil, nil, nil, nil, nil, nil, nil, 3, "3"))
+		mock.ExpectExec("update notes set last_accessed_at").WillReturnResult(sqlmock.NewResult(0, 1))
 
 		pg := Db{
 			conn:          mockDB,
 			encryptionKey: key,
 			dataCipher:    c,
 		}
-		creds, err := pg.GetNotes(ctx, models.Note{
+		creds, _, err := pg.GetNotes(ctx, models.Note{
 			UserName: userLogin,
-		})
+		}, models.ListOptions{})
 		assert.NoError(t, err)
 		assert.Equal(t, expected, creds)
 	})
@@ -603,6 +843,7 @@ func TestDb_GetNote(t *testing.T) {
 				Title:    Ptr("notes from dorne"),
 				Content:  Ptr("some lovely notes"),
 				Metadata: Ptr("love"),
+				Audit:    auditID(1),
 			},
 		}
 
@@ -612,20 +853,21 @@ func TestDb_GetNote(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectQuery("select user_name, title, content, metadata from notes where user_name").
+		mock.ExpectQuery("select user_name, title, content, metadata, fields, .+ from notes where user_name").
 			WithArgs(userLogin, Ptr("notes from dorne")).
-			WillReturnRows(sqlmock.NewRows([]string{"user_name", "title", "content", "metadata"}).
-				AddRow(userLogin, "notes from dorne", "zwcf07PPKWGQpOBElPSmsjQ=", "love"))
+			WillReturnRows(sqlmock.NewRows([]string{"user_name", "title", "content", "metadata", "fields", "folder", "tags", "created_at", "updated_at", "last_accessed_at", "revision", "id", "sort_key"}).
+				AddRow(userLogin, "notes from dorne", "zwcf07PPKWGQpOBElPSmsjQ=", "love", nil, nil, nil, nil, nil, nil, nil, 1, "1"))
+		mock.ExpectExec("update notes set last_accessed_at").WillReturnResult(sqlmock.NewResult(0, 1))
 
 		pg := Db{
 			conn:          mockDB,
 			encryptionKey: key,
 			dataCipher:    c,
 		}
-		creds, err := pg.GetNotes(ctx, models.Note{
+		creds, _, err := pg.GetNotes(ctx, models.Note{
 			UserName: userLogin,
 			Title:    Ptr("notes from dorne"),
-		})
+		}, models.ListOptions{})
 		assert.NoError(t, err)
 		assert.Equal(t, expected, creds)
 	})
@@ -636,18 +878,18 @@ func TestDb_GetNote(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectQuery("select user_name, title, content, metadata from notes where user_name").
+		mock.ExpectQuery("select user_name, title, content, metadata, fields, .+ from notes where user_name").
 			WithArgs(userLogin).
-			WillReturnRows(sqlmock.NewRows([]string{"user_name", "title", "content", "metadata"}))
+			WillReturnRows(sqlmock.NewRows([]string{"user_name", "title", "content", "metadata", "fields", "folder", "tags", "created_at", "updated_at", "last_accessed_at", "revision", "id", "sort_key"}))
 
 		pg := Db{
 			conn:          mockDB,
 			encryptionKey: key,
 			dataCipher:    c,
 		}
-		_, err = pg.GetNotes(ctx, models.Note{
+		_, _, err = pg.GetNotes(ctx, models.Note{
 			UserName: userLogin,
-		})
+		}, models.ListOptions{})
 		assert.EqualError(t, err, ErrNoData.Error())
 	})
 	t.Run("negative: query error", func(t *testing.T) {
@@ -657,7 +899,7 @@ func TestDb_GetNote(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectQuery("select user_name, title, content, metadata from notes where user_name").
+		mock.ExpectQuery("select user_name, title, content, metadata, fields, .+ from notes where user_name").
 			WithArgs(userLogin).
 			WillReturnError(errors.New("query error"))
 
@@ -666,9 +908,9 @@ func TestDb_GetNote(t *testing.T) {
 			encryptionKey: key,
 			dataCipher:    c,
 		}
-		_, err = pg.GetNotes(ctx, models.Note{
+		_, _, err = pg.GetNotes(ctx, models.Note{
 			UserName: userLogin,
-		})
+		}, models.ListOptions{})
 		assert.EqualError(t, err, "ошибка при получении заметок для пользователя \"myrcella\": query error")
 	})
 }
@@ -685,7 +927,7 @@ func TestDb_DeleteNotes(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectExec("delete from notes").
+		mock.ExpectExec("update notes set deleted_at = now\\(\\) where user_name = \\$1 and deleted_at is null").
 			WithArgs(user).
 			WillReturnResult(sqlmock.NewResult(0, 0))
 
@@ -704,7 +946,7 @@ func TestDb_DeleteNotes(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectExec("delete from notes").
+		mock.ExpectExec("update notes set deleted_at = now\\(\\) where user_name = \\$1 and deleted_at is null").
 			WithArgs(user, title).
 			WillReturnResult(sqlmock.NewResult(0, 0))
 
@@ -724,7 +966,7 @@ func TestDb_DeleteNotes(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectExec("delete from notes").
+		mock.ExpectExec("update notes set deleted_at = now\\(\\) where user_name = \\$1 and deleted_at is null").
 			WithArgs(user).
 			WillReturnError(errors.New("some error"))
 
@@ -747,6 +989,7 @@ func TestDb_UpdateNote(t *testing.T) {
 		Content:  Ptr("some clever things"),
 		Metadata: Ptr("bla bla metadata"),
 	}
+	noteTokens := pq.Array(blindindex.Tokens(blindindex.DeriveKey(key), note.UserName, *note.Content))
 	ctx := context.Background()
 
 	t.Run("positive: with metadata", func(t *testing.T) {
@@ -756,17 +999,31 @@ func TestDb_UpdateNote(t *testing.T) {
 		}
 		defer mockDB.Close()
 
+		mock.ExpectBegin()
+		mock.ExpectQuery("select id, content, metadata, fields, revision from notes where user_name = \\$1 and title = \\$2 and deleted_at is null for update").
+			WithArgs(note.UserName, *note.Title).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "content", "metadata", "fields", "revision"}).
+				AddRow(4, "zwcf07PPKWGQpOBElPSmsjQ=", "love", nil, 5))
+		mock.ExpectExec("insert into secret_history").
+			WithArgs(note.UserName, models.SecretNote, 4, 5, sqlmock.AnyArg(), pq.Array([]string{"content", "metadata"}), note.UserName).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("delete from secret_history where secret_type = \\$1 and secret_id = \\$2 and revision <= \\$3").
+			WithArgs(models.SecretNote, 4, 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
 		mock.ExpectExec("update notes set content").
-			WithArgs("zwcf07PAKnKDretEjvO7uXwo", note.Metadata, note.UserName, *note.Title).
-			WillReturnResult(sqlmock.NewResult(0, 0))
+			WithArgs("zwcf07PAKnKDretEjvO7uXwo", note.Metadata, nil, noteTokens, note.UserName, *note.Title).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
 
 		pg := Db{
-			conn:          mockDB,
-			encryptionKey: key,
-			dataCipher:    c,
+			conn:             mockDB,
+			encryptionKey:    key,
+			dataCipher:       c,
+			historyRetention: 2,
 		}
 		err = pg.UpdateNote(ctx, note)
 		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("positive: without metadata", func(t *testing.T) {
@@ -776,9 +1033,14 @@ func TestDb_UpdateNote(t *testing.T) {
 		}
 		defer mockDB.Close()
 
+		mock.ExpectBegin()
+		mock.ExpectQuery("select id, content, .+ from notes .+ for update").
+			WithArgs(note.UserName, *note.Title).
+			WillReturnError(sql.ErrNoRows)
 		mock.ExpectExec("update notes set content").
-			WithArgs("zwcf07PAKnKDretEjvO7uXwo", nil, note.UserName, note.Title).
-			WillReturnResult(sqlmock.NewResult(0, 0))
+			WithArgs("zwcf07PAKnKDretEjvO7uXwo", nil, nil, noteTokens, note.UserName, note.Title).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
 
 		pg := Db{
 			conn:          mockDB,
@@ -789,6 +1051,31 @@ func TestDb_UpdateNote(t *testing.T) {
 		err = pg.UpdateNote(ctx, note)
 		assert.NoError(t, err)
 	})
+	t.Run("negative: note is gone", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("select id, content, .+ from notes .+ for update").
+			WithArgs(note.UserName, *note.Title).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectExec("update notes set content").
+			WithArgs("zwcf07PAKnKDretEjvO7uXwo", nil, nil, noteTokens, note.UserName, note.Title).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		note.Metadata = nil
+		assert.ErrorIs(t, pg.UpdateNote(ctx, note), ErrNoData)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 	t.Run("negative: exec error", func(t *testing.T) {
 		mockDB, mock, err := sqlmock.New()
 		if err != nil {
@@ -796,9 +1083,14 @@ func TestDb_UpdateNote(t *testing.T) {
 		}
 		defer mockDB.Close()
 
+		mock.ExpectBegin()
+		mock.ExpectQuery("select id, content, .+ from notes .+ for update").
+			WithArgs(note.UserName, *note.Title).
+			WillReturnError(sql.ErrNoRows)
 		mock.ExpectExec("update notes set content").
-			WithArgs("zwcf07PAKnKDretEjvO7uXwo", nil, note.UserName, note.Title).
+			WithArgs("zwcf07PAKnKDretEjvO7uXwo", nil, nil, noteTokens, note.UserName, note.Title).
 			WillReturnError(errors.New("exec error"))
+		mock.ExpectRollback()
 
 		pg := Db{
 			conn:          mockDB,
@@ -809,6 +1101,68 @@ func TestDb_UpdateNote(t *testing.T) {
 		err = pg.UpdateNote(ctx, note)
 		assert.EqualError(t, err, "ошибка при обновлении заметки \"shopping list\" для пользователя \"varys\": exec error")
 	})
+	t.Run("positive: missing fields keep current values", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		fields := `[{"name":"birds","type":"text","value":"little"}]`
+		mock.ExpectBegin()
+		mock.ExpectQuery("select id, content, .+ from notes .+ for update").
+			WithArgs(note.UserName, *note.Title).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "content", "metadata", "fields", "revision"}).
+				AddRow(4, "zwcf07PPKWGQpOBElPSmsjQ=", nil, fields, 5))
+		mock.ExpectExec("insert into secret_history").
+			WithArgs(note.UserName, models.SecretNote, 4, 5, sqlmock.AnyArg(), pq.Array([]string{"content"}), note.UserName).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("update notes set content").
+			WithArgs("zwcf07PAKnKDretEjvO7uXwo", nil, fields, noteTokens, note.UserName, *note.Title).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		note.Metadata = nil
+		err = pg.UpdateNote(ctx, note)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("positive: empty fields clear current values", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("select id, content, .+ from notes .+ for update").
+			WithArgs(note.UserName, *note.Title).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "content", "metadata", "fields", "revision"}).
+				AddRow(4, "zwcf07PPKWGQpOBElPSmsjQ=", nil, `[{"name":"birds","type":"text","value":"little"}]`, 5))
+		mock.ExpectExec("insert into secret_history").
+			WithArgs(note.UserName, models.SecretNote, 4, 5, sqlmock.AnyArg(), pq.Array([]string{"content", "fields"}), note.UserName).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("update notes set content").
+			WithArgs("zwcf07PAKnKDretEjvO7uXwo", nil, nil, noteTokens, note.UserName, *note.Title).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		cleared := note
+		cleared.Metadata, cleared.Fields = nil, []models.CustomField{}
+		err = pg.UpdateNote(ctx, cleared)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
 func TestDb_SaveCard(t *testing.T) {
@@ -831,9 +1185,11 @@ func TestDb_SaveCard(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectExec("insert into cards").
-			WithArgs(card.UserName, *card.BankName, *card.Number, "jVpB", "0A0V1/Da", *card.Metadata).
-			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectBegin()
+		mock.ExpectQuery("insert into cards").
+			WithArgs(card.UserName, *card.BankName, *card.Number, "jVpB", "0A0V1/Da", nil, *card.Metadata, nil, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
 
 		pg := Db{
 			conn:          mockDB,
@@ -851,9 +1207,11 @@ func TestDb_SaveCard(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectExec("insert into cards").
-			WithArgs(card.UserName, *card.BankName, *card.Number, "jVpB", "0A0V1/Da", nil).
-			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectBegin()
+		mock.ExpectQuery("insert into cards").
+			WithArgs(card.UserName, *card.BankName, *card.Number, "jVpB", "0A0V1/Da", nil, nil, nil, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
 
 		pg := Db{
 			conn:          mockDB,
@@ -871,9 +1229,11 @@ func TestDb_SaveCard(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectExec("insert into cards").
-			WithArgs(card.UserName, *card.BankName, *card.Number, "jVpB", "0A0V1/Da", nil).
+		mock.ExpectBegin()
+		mock.ExpectQuery("insert into cards").
+			WithArgs(card.UserName, *card.BankName, *card.Number, "jVpB", "0A0V1/Da", nil, nil, nil, nil).
 			WillReturnError(errors.New("exec error"))
+		mock.ExpectRollback()
 
 		pg := Db{
 			conn:          mockDB,
@@ -902,6 +1262,7 @@ func TestDb_GetCard(t *testing.T) {
 				Password: Ptr("ironborne"),
 				CardType: Ptr("debet"),
 				Metadata: Ptr("red bank"),
+				Audit:    auditID(1),
 			},
 			{
 				UserName: userLogin,
@@ -911,6 +1272,7 @@ func TestDb_GetCard(t *testing.T) {
 				Password: Ptr("ramsey"),
 				CardType: Ptr("debet"),
 				Metadata: Ptr("black bank"),
+				Audit:    auditID(2),
 			},
 			{
 				UserName: userLogin,
@@ -919,6 +1281,7 @@ func TestDb_GetCard(t *testing.T) {
 				CV:       Ptr("492"),
 				Password: Ptr("qwerty"),
 				CardType: Ptr("debet"),
+				Audit:    auditID(3),
 			},
 		}
 
@@ -928,21 +1291,22 @@ func TestDb_GetCard(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectQuery("select user_name, bank_name, number, cv, password, cardType, metadata from cards where user_name").
+		mock.ExpectQuery("select user_name, bank_name, number, cv, password, card_type, metadata, fields, .+ from cards where user_name").
 			WithArgs(userLogin).
-			WillReturnRows(sqlmock.NewRows([]string{"user_name", "bank_name", "number", "cv", "password", "card_type", "metadata"}).
-				AddRow(userLogin, "alpha", "9999333344446666", "j1pD", "1Rod2PHMNHmQ", "debet", "red bank").
-				AddRow(userLogin, "tinkoff", "5555444433337777", "hV1G", "zgkfxfba", "debet", "black bank").
-				AddRow(userLogin, "sber", "6666555544440000", "iFFA", "zR8XxOfa", "debet", nil))
+			WillReturnRows(sqlmock.NewRows([]string{"user_name", "bank_name", "number", "cv", "password", "card_type", "metadata", "fields", "folder", "tags", "created_at", "updated_at", "last_accessed_at", "revision", "id", "sort_key"}).
+				AddRow(userLogin, "alpha", "9999333344446666", "j1pD", "1Rod2PHMNHmQ", "debet", "red bank", nil, nil, nil, nil, nil, nil, nil, 1, "1").
+				AddRow(userLogin, "tinkoff", "5555444433337777", "hV1G", "zgkfxfba", "debet", "black bank", nil, nil, nil, nil, nil, nil, nil, 2, "2").
+				AddRow(userLogin, "sber", "6666555544440000", "iFFA", "zR8XxOfa", "debet", nil, nil, nil, nil, nil, nil, nil, nil, 3, "3"))
+		mock.ExpectExec("update cards set last_accessed_at").WillReturnResult(sqlmock.NewResult(0, 1))
 
 		pg := Db{
 			conn:          mockDB,
 			encryptionKey: key,
 			dataCipher:    c,
 		}
-		creds, err := pg.GetCard(ctx, models.Card{
+		creds, _, err := pg.GetCard(ctx, models.Card{
 			UserName: userLogin,
-		})
+		}, models.ListOptions{})
 		assert.NoError(t, err)
 		assert.Equal(t, expected, creds)
 	})
@@ -956,6 +1320,7 @@ func TestDb_GetCard(t *testing.T) {
 				Password: Ptr("ironborne"),
 				CardType: Ptr("debet"),
 				Metadata: Ptr("red bank"),
+				Audit:    auditID(1),
 			},
 		}
 
@@ -965,20 +1330,21 @@ func TestDb_GetCard(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectQuery("select user_name, bank_name, number, cv, password, cardType, metadata from cards where user_name").
+		mock.ExpectQuery("select user_name, bank_name, number, cv, password, card_type, metadata, fields, .+ from cards where user_name").
 			WithArgs(userLogin, "alpha").
-			WillReturnRows(sqlmock.NewRows([]string{"user_name", "bank_name", "number", "cv", "password", "card_type", "metadata"}).
-				AddRow(userLogin, "alpha", "9999333344446666", "j1pD", "1Rod2PHMNHmQ", "debet", "red bank"))
+			WillReturnRows(sqlmock.NewRows([]string{"user_name", "bank_name", "number", "cv", "password", "card_type", "metadata", "fields", "folder", "tags", "created_at", "updated_at", "last_accessed_at", "revision", "id", "sort_key"}).
+				AddRow(userLogin, "alpha", "9999333344446666", "j1pD", "1Rod2PHMNHmQ", "debet", "red bank", nil, nil, nil, nil, nil, nil, nil, 1, "1"))
+		mock.ExpectExec("update cards set last_accessed_at").WillReturnResult(sqlmock.NewResult(0, 1))
 
 		pg := Db{
 			conn:          mockDB,
 			encryptionKey: key,
 			dataCipher:    c,
 		}
-		creds, err := pg.GetCard(ctx, models.Card{
+		creds, _, err := pg.GetCard(ctx, models.Card{
 			UserName: userLogin,
 			BankName: Ptr("alpha"),
-		})
+		}, models.ListOptions{})
 		assert.NoError(t, err)
 		assert.Equal(t, expected, creds)
 	})
@@ -992,6 +1358,7 @@ func TestDb_GetCard(t *testing.T) {
 				Password: Ptr("ironborne"),
 				CardType: Ptr("credit"),
 				Metadata: Ptr("red bank"),
+				Audit:    auditID(1),
 			},
 		}
 
@@ -1001,20 +1368,21 @@ func TestDb_GetCard(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectQuery("select user_name, bank_name, number, cv, password, cardType, metadata from cards where user_name").
+		mock.ExpectQuery("select user_name, bank_name, number, cv, password, card_type, metadata, fields, .+ from cards where user_name").
 			WithArgs(userLogin, "9999333344446666").
-			WillReturnRows(sqlmock.NewRows([]string{"user_name", "bank_name", "number", "cv", "password", "card_type", "metadata"}).
-				AddRow(userLogin, "alpha", "9999333344446666", "j1pD", "1Rod2PHMNHmQ", "credit", "red bank"))
+			WillReturnRows(sqlmock.NewRows([]string{"user_name", "bank_name", "number", "cv", "password", "card_type", "metadata", "fields", "folder", "tags", "created_at", "updated_at", "last_accessed_at", "revision", "id", "sort_key"}).
+				AddRow(userLogin, "alpha", "9999333344446666", "j1pD", "1Rod2PHMNHmQ", "credit", "red bank", nil, nil, nil, nil, nil, nil, nil, 1, "1"))
+		mock.ExpectExec("update cards set last_accessed_at").WillReturnResult(sqlmock.NewResult(0, 1))
 
 		pg := Db{
 			conn:          mockDB,
 			encryptionKey: key,
 			dataCipher:    c,
 		}
-		creds, err := pg.GetCard(ctx, models.Card{
+		creds, _, err := pg.GetCard(ctx, models.Card{
 			UserName: userLogin,
 			Number:   Ptr("9999333344446666"),
-		})
+		}, models.ListOptions{})
 		assert.NoError(t, err)
 		assert.Equal(t, expected, creds)
 	})
@@ -1028,6 +1396,7 @@ func TestDb_GetCard(t *testing.T) {
 				Password: Ptr("ironborne"),
 				Metadata: Ptr("red bank"),
 				CardType: Ptr("credit"),
+				Audit:    auditID(1),
 			},
 		}
 
@@ -1037,21 +1406,22 @@ func TestDb_GetCard(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectQuery("select user_name, bank_name, number, cv, password, cardType, metadata from cards where user_name").
+		mock.ExpectQuery("select user_name, bank_name, number, cv, password, card_type, metadata, fields, .+ from cards where user_name").
 			WithArgs(userLogin, "alpha", "9999333344446666").
-			WillReturnRows(sqlmock.NewRows([]string{"user_name", "bank_name", "number", "cv", "password", "card_type", "metadata"}).
-				AddRow(userLogin, "alpha", "9999333344446666", "j1pD", "1Rod2PHMNHmQ", "credit", "red bank"))
+			WillReturnRows(sqlmock.NewRows([]string{"user_name", "bank_name", "number", "cv", "password", "card_type", "metadata", "fields", "folder", "tags", "created_at", "updated_at", "last_accessed_at", "revision", "id", "sort_key"}).
+				AddRow(userLogin, "alpha", "9999333344446666", "j1pD", "1Rod2PHMNHmQ", "credit", "red bank", nil, nil, nil, nil, nil, nil, nil, 1, "1"))
+		mock.ExpectExec("update cards set last_accessed_at").WillReturnResult(sqlmock.NewResult(0, 1))
 
 		pg := Db{
 			conn:          mockDB,
 			encryptionKey: key,
 			dataCipher:    c,
 		}
-		creds, err := pg.GetCard(ctx, models.Card{
+		creds, _, err := pg.GetCard(ctx, models.Card{
 			UserName: userLogin,
 			Number:   Ptr("9999333344446666"),
 			BankName: Ptr("alpha"),
-		})
+		}, models.ListOptions{})
 		assert.NoError(t, err)
 		assert.Equal(t, expected, creds)
 	})
@@ -1062,18 +1432,18 @@ func TestDb_GetCard(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectQuery("select user_name, bank_name, number, cv, password, cardType, metadata from cards where user_name").
+		mock.ExpectQuery("select user_name, bank_name, number, cv, password, card_type, metadata, fields, .+ from cards where user_name").
 			WithArgs(userLogin).
-			WillReturnRows(sqlmock.NewRows([]string{"user_name", "bank_name", "number", "cv", "password", "card_type", "metadata"}))
+			WillReturnRows(sqlmock.NewRows([]string{"user_name", "bank_name", "number", "cv", "password", "card_type", "metadata", "fields", "folder", "tags", "created_at", "updated_at", "last_accessed_at", "revision", "id", "sort_key"}))
 
 		pg := Db{
 			conn:          mockDB,
 			encryptionKey: key,
 			dataCipher:    c,
 		}
-		_, err = pg.GetCard(ctx, models.Card{
+		_, _, err = pg.GetCard(ctx, models.Card{
 			UserName: userLogin,
-		})
+		}, models.ListOptions{})
 		assert.EqualError(t, err, ErrNoData.Error())
 	})
 	t.Run("negative: query error", func(t *testing.T) {
@@ -1083,7 +1453,7 @@ func TestDb_GetCard(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectQuery("select user_name, bank_name, number, cv, password, cardType, metadata from cards where user_name").
+		mock.ExpectQuery("select user_name, bank_name, number, cv, password, card_type, metadata, fields, .+ from cards where user_name").
 			WithArgs(userLogin).
 			WillReturnError(errors.New("query error"))
 
@@ -1092,9 +1462,9 @@ func TestDb_GetCard(t *testing.T) {
 			encryptionKey: key,
 			dataCipher:    c,
 		}
-		_, err = pg.GetCard(ctx, models.Card{
+		_, _, err = pg.GetCard(ctx, models.Card{
 			UserName: userLogin,
-		})
+		}, models.ListOptions{})
 		assert.EqualError(t, err, "ошибка при получении карт для пользователя \"theon\": query error")
 	})
 }
@@ -1112,7 +1482,7 @@ func TestDb_DeleteCards(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectExec("delete from cards").
+		mock.ExpectExec("update cards set deleted_at = now\\(\\) where user_name = \\$1 and deleted_at is null").
 			WithArgs(user).
 			WillReturnResult(sqlmock.NewResult(0, 0))
 
@@ -1131,7 +1501,7 @@ func TestDb_DeleteCards(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectExec("delete from cards").
+		mock.ExpectExec("update cards set deleted_at = now\\(\\) where user_name = \\$1 and deleted_at is null").
 			WithArgs(user, &bank).
 			WillReturnResult(sqlmock.NewResult(0, 0))
 
@@ -1151,7 +1521,7 @@ func TestDb_DeleteCards(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectExec("delete from cards").
+		mock.ExpectExec("update cards set deleted_at = now\\(\\) where user_name = \\$1 and deleted_at is null").
 			WithArgs(user, &number).
 			WillReturnResult(sqlmock.NewResult(0, 0))
 
@@ -1171,7 +1541,7 @@ func TestDb_DeleteCards(t *testing.T) {
 		}
 		defer mockDB.Close()
 
-		mock.ExpectExec("delete from cards").
+		mock.ExpectExec("update cards set deleted_at = now\\(\\) where user_name = \\$1 and deleted_at is null").
 			WithArgs(user).
 			WillReturnError(errors.New("some error"))
 
@@ -1185,6 +1555,105 @@ func TestDb_DeleteCards(t *testing.T) {
 	})
 }
 
+func TestDb_UpdateCard(t *testing.T) {
+	key := "thisis32bitlongpassphraseimusing"
+	c, _ := aes.NewCipher([]byte(key))
+	ctx := context.Background()
+	updateQuery := "update cards set bank_name = coalesce\\(\\$1, bank_name\\), cv = coalesce\\(\\$2, cv\\), password = coalesce\\(\\$3, password\\), " +
+		"card_type = coalesce\\(\\$4, card_type\\), metadata = nullif\\(coalesce\\(\\$5, metadata\\), ''\\), fields = \\$6, updated_at = now\\(\\), revision = revision \\+ 1 " +
+		"where user_name = \\$7 and number = \\$8 and deleted_at is null"
+
+	t.Run("positive: omitted values and fields are kept", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		storedFields := `[{"name":"pin","type":"hidden","value":"zR8XxOfadyU="}]`
+		mock.ExpectBegin()
+		mock.ExpectQuery("select fields, revision from cards where user_name = \\$1 and number = \\$2 and deleted_at is null for update").
+			WithArgs("tyrion", "1111222233334444").
+			WillReturnRows(sqlmock.NewRows([]string{"fields", "revision"}).AddRow(storedFields, 3))
+		mock.ExpectExec(updateQuery).
+			WithArgs(nil, nil, sqlmock.AnyArg(), nil, nil, sql.NullString{String: storedFields, Valid: true}, "tyrion", "1111222233334444").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		pg := Db{conn: mockDB, encryptionKey: key, dataCipher: c}
+		err = pg.UpdateCard(ctx, models.Card{UserName: "tyrion", Number: Ptr("1111222233334444"), Password: Ptr("lannister"),
+			Audit: models.Audit{Revision: 3}})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("positive: empty fields and metadata clear them", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("select fields, revision from cards .+ for update").
+			WithArgs("tyrion", "1111222233334444").
+			WillReturnRows(sqlmock.NewRows([]string{"fields", "revision"}).AddRow(`[{"name":"branch","type":"text","value":"kings landing"}]`, 3))
+		mock.ExpectExec(updateQuery).
+			WithArgs("iron bank", nil, nil, nil, "", nil, "tyrion", "1111222233334444").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		pg := Db{conn: mockDB, encryptionKey: key, dataCipher: c}
+		err = pg.UpdateCard(ctx, replacingCard(models.Card{UserName: "tyrion", Number: Ptr("1111222233334444"), BankName: Ptr("iron bank")}))
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("negative: revision mismatch", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("select fields, revision from cards .+ for update").
+			WithArgs("tyrion", "1111222233334444").
+			WillReturnRows(sqlmock.NewRows([]string{"fields", "revision"}).AddRow(nil, 4))
+		mock.ExpectRollback()
+
+		pg := Db{conn: mockDB, encryptionKey: key, dataCipher: c}
+		err = pg.UpdateCard(ctx, models.Card{UserName: "tyrion", Number: Ptr("1111222233334444"), Password: Ptr("lannister"),
+			Audit: models.Audit{Revision: 3}})
+		assert.ErrorIs(t, err, ErrRevisionMismatch)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("negative: card not found", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("select fields, revision from cards .+ for update").
+			WithArgs("tyrion", "1111222233334444").
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectExec(updateQuery).
+			WithArgs(nil, nil, sqlmock.AnyArg(), nil, nil, sql.NullString{}, "tyrion", "1111222233334444").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		pg := Db{conn: mockDB, encryptionKey: key, dataCipher: c}
+		err = pg.UpdateCard(ctx, models.Card{UserName: "tyrion", Number: Ptr("1111222233334444"), Password: Ptr("lannister")})
+		assert.ErrorIs(t, err, ErrNoData)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// auditID возвращает служебные сведения секрета, содержащие только его идентификатор
+func auditID(id int64) models.Audit {
+	return models.Audit{ID: &id}
+}
+
 func Ptr(s string) *string {
 	return &s
 }
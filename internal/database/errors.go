@@ -3,16 +3,39 @@ package database
 import (
 	"errors"
 	"fmt"
+
+	"github.com/lib/pq"
 )
 
-// ErrDuplicateKey представляет ошибку дубликата ключа
-type ErrDuplicateKey struct {
-	Key string
+// uniqueViolationCode код ошибки PostgreSQL при нарушении ограничения уникальности
+const uniqueViolationCode = "23505"
+
+// ErrConflict означает, что запись с таким ключом уже существует
+var ErrConflict = errors.New("record already exists")
+
+// ConflictError представляет нарушение ограничения уникальности
+type ConflictError struct {
+	Constraint string
+}
+
+// Error возвращает текст ошибки нарушения ограничения уникальности
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("record violates unique constraint %q", e.Constraint)
+}
+
+// Is позволяет сравнивать ConflictError с ErrConflict через errors.Is
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
 }
 
-// Error возвращает текст ошибки дубликата ключа
-func (e ErrDuplicateKey) Error() string {
-	return fmt.Sprintf("ERROR: duplicate key value violates unique constraint %q (SQLSTATE 23505)", e.Key)
+// asConflictError преобразует ошибку нарушения уникальности PostgreSQL в *ConflictError.
+// Для остальных ошибок возвращает nil.
+func asConflictError(err error) *ConflictError {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolationCode {
+		return &ConflictError{Constraint: pqErr.Constraint}
+	}
+	return nil
 }
 
 // ErrUserAlreadyExists означает, что пользователь уже существует
@@ -26,3 +49,27 @@ var ErrInvalidCredentials = errors.New("incorrect password")
 
 // ErrNoData означает отсутствие данных для пользователя
 var ErrNoData = errors.New("no data for user")
+
+// ErrNoSuchCredentials означает, что учетные данные для привязки не найдены
+var ErrNoSuchCredentials = errors.New("no such credentials")
+
+// ErrInvalidFolderPath означает некорректный путь папки
+var ErrInvalidFolderPath = errors.New("invalid folder path")
+
+// ErrInvalidTagName означает пустое название тега
+var ErrInvalidTagName = errors.New("invalid tag name")
+
+// ErrInvalidListOptions означает некорректные параметры постраничного получения списка
+var ErrInvalidListOptions = errors.New("invalid list options")
+
+// ErrRevisionMismatch означает, что секрет был изменен или удален после ревизии, ожидаемой клиентом
+var ErrRevisionMismatch = errors.New("revision mismatch")
+
+// ErrBackupSchemaMismatch означает, что версия схемы резервной копии и базы данных различается
+var ErrBackupSchemaMismatch = errors.New("backup schema version mismatch")
+
+// ErrBackupKeyMismatch означает, что резервная копия зашифрована другим ключом шифрования данных
+var ErrBackupKeyMismatch = errors.New("backup encryption key mismatch")
+
+// ErrBackupTargetNotEmpty означает, что в базе данных для восстановления уже есть данные
+var ErrBackupTargetNotEmpty = errors.New("restore target is not empty")
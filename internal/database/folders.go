@@ -0,0 +1,294 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/lib/pq"
+)
+
+// secretTable описывает таблицу секретов, которые можно раскладывать по папкам и помечать тегами
+type secretTable struct {
+	name        string            // таблица секретов
+	tagsTable   string            // таблица связи секретов с тегами
+	tagsColumn  string            // колонка с идентификатором секрета в таблице связи
+	nameColumn  string            // выражение для сортировки по названию
+	projectable []string          // поля, которые можно запросить в проекции помимо ключевых
+	secrets     []string          // зашифрованные поля, чтение которых отмечается в last_accessed_at
+	secretType  models.SecretType // тип секретов, хранящихся в таблице
+}
+
+var (
+	credentialsTable = secretTable{
+		name: "credentials", tagsTable: "credential_tags", tagsColumn: "credential_id", secretType: models.SecretCredentials,
+		nameColumn:  "coalesce(nullif(name, ''), login)",
+		projectable: []string{"password", "metadata", "name", "urls", "fields", "folder", "tags"},
+		secrets:     []string{"password"},
+	}
+	notesTable = secretTable{
+		name: "notes", tagsTable: "note_tags", tagsColumn: "note_id", secretType: models.SecretNote,
+		nameColumn:  "title",
+		projectable: []string{"content", "metadata", "fields", "folder", "tags"},
+		secrets:     []string{"content"},
+	}
+	cardsTable = secretTable{
+		name: "cards", tagsTable: "card_tags", tagsColumn: "card_id", secretType: models.SecretCard,
+		nameColumn:  "bank_name",
+		projectable: []string{"cv", "password", "card_type", "metadata", "fields", "folder", "tags"},
+		secrets:     []string{"cv", "password"},
+	}
+)
+
+// tableOf возвращает таблицу секретов типа secretType
+func tableOf(secretType models.SecretType) (secretTable, error) {
+	for _, table := range []secretTable{credentialsTable, notesTable, cardsTable} {
+		if table.secretType == secretType {
+			return table, nil
+		}
+	}
+	return secretTable{}, fmt.Errorf("неизвестный тип секрета %q", secretType)
+}
+
+// folderAndTagsColumns возвращает выражения для выборки пути папки и JSON-массива тегов секрета
+func (s secretTable) folderAndTagsColumns() string {
+	return fmt.Sprintf("(select f.path from folders f where f.id = %[1]s.folder_id) as folder, "+
+		"(select json_agg(t.name order by t.name) from %[2]s st join tags t on t.id = st.tag_id where st.%[3]s = %[1]s.id) as tags",
+		s.name, s.tagsTable, s.tagsColumn)
+}
+
+// filter дополняет условие запроса отбором по папке (вместе с вложенными папками) и тегам.
+// Если передано несколько тегов, секрет должен быть помечен каждым из них. Имя пользователя должно быть первым аргументом.
+func (s secretTable) filter(args []interface{}, folder *string, tags []string) ([]interface{}, string) {
+	var condition string
+	if folder != nil {
+		args = append(args, strings.Trim(*folder, "/"))
+		condition += fmt.Sprintf(" AND folder_id in (select id from folders where user_name = $1 and (path = $%[1]d or starts_with(path, $%[1]d || '/')))", len(args))
+	}
+	for _, tag := range tags {
+		args = append(args, tag)
+		condition += fmt.Sprintf(" AND id in (select st.%s from %s st join tags t on t.id = st.tag_id where t.user_name = $1 and t.name = $%d)",
+			s.tagsColumn, s.tagsTable, len(args))
+	}
+	return args, condition
+}
+
+// attachTags помечает секрет тегами, создавая отсутствующие теги.
+func (s secretTable) attachTags(ctx context.Context, tx *sql.Tx, userName string, id int64, tags []string) error {
+	for _, tag := range tags {
+		tagID, err := ensureTag(ctx, tx, userName, tag)
+		if err != nil {
+			return err
+		}
+		attachQuery := fmt.Sprintf("insert into %s (%s, tag_id) values ($1, $2) on conflict do nothing", s.tagsTable, s.tagsColumn)
+		if _, err = tx.ExecContext(ctx, attachQuery, id, tagID); err != nil {
+			return fmt.Errorf("ошибка при добавлении тега %q: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+// scanFolderAndTags заполняет папку и теги секрета по значениям, полученным из folderAndTagsColumns
+func scanFolderAndTags(folder, tags sql.NullString) (*string, []string, error) {
+	var (
+		folderPath *string
+		tagNames   []string
+	)
+	if folder.Valid {
+		folderPath = &folder.String
+	}
+	if tags.Valid {
+		if err := json.Unmarshal([]byte(tags.String), &tagNames); err != nil {
+			return nil, nil, fmt.Errorf("ошибка при декодировании тегов: %w", err)
+		}
+	}
+	return folderPath, tagNames, nil
+}
+
+// splitFolderPath разбивает путь папки на составляющие, например work/projects на work и projects
+func splitFolderPath(path string) ([]string, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for _, p := range parts {
+		if strings.TrimSpace(p) == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidFolderPath, path)
+		}
+	}
+	return parts, nil
+}
+
+// ensureFolder создает папку вместе с родительскими папками, если они не существуют, и возвращает ее идентификатор.
+// Для пустого пути возвращает nil, чтобы секрет сохранялся вне папок.
+func ensureFolder(ctx context.Context, tx *sql.Tx, userName string, path *string) (interface{}, error) {
+	if path == nil {
+		return nil, nil
+	}
+	parts, err := splitFolderPath(*path)
+	if err != nil {
+		return nil, err
+	}
+	ensureFolderQuery := "insert into folders (user_name, name, parent_id, path) values ($1, $2, $3, $4) " +
+		"on conflict (user_name, path) do update set name = excluded.name returning id"
+	var parentID interface{}
+	for i, name := range parts {
+		var id int64
+		if err = tx.QueryRowContext(ctx, ensureFolderQuery, userName, name, parentID, strings.Join(parts[:i+1], "/")).Scan(&id); err != nil {
+			return nil, fmt.Errorf("ошибка при создании папки %q: %w", strings.Join(parts[:i+1], "/"), err)
+		}
+		parentID = id
+	}
+	return parentID, nil
+}
+
+// SaveFolder создает папку пользователя вместе с родительскими папками.
+func (d *Db) SaveFolder(ctx context.Context, folderRequest models.Folder) error {
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании папки для пользователя %q: %w", folderRequest.UserName, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err = ensureFolder(ctx, tx, folderRequest.UserName, folderRequest.Path); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetFolders получает папки пользователя. Если указан путь, возвращается папка вместе с вложенными папками.
+func (d *Db) GetFolders(ctx context.Context, folderRequest models.Folder) ([]models.Folder, error) {
+	args := []interface{}{folderRequest.UserName}
+	getFoldersQuery := "select user_name, path from folders where user_name = $1"
+	if folderRequest.Path != nil {
+		args = append(args, strings.Trim(*folderRequest.Path, "/"))
+		getFoldersQuery += " AND (path = $2 or starts_with(path, $2 || '/'))"
+	}
+	getFoldersQuery += " order by path"
+	rows, err := d.conn.QueryContext(ctx, getFoldersQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении папок для пользователя %q: %w", folderRequest.UserName, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var folders []models.Folder
+	for rows.Next() {
+		var userName, path string
+		if err = rows.Scan(&userName, &path); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании строк после запроса на получение папок: %w", err)
+		}
+		folders = append(folders, models.Folder{UserName: userName, Path: &path})
+	}
+	if len(folders) == 0 {
+		return nil, ErrNoData
+	}
+	return folders, nil
+}
+
+// DeleteFolder удаляет папку пользователя вместе с вложенными папками.
+// Секреты из удаленных папок остаются в хранилище вне папок.
+func (d *Db) DeleteFolder(ctx context.Context, folderRequest models.Folder) error {
+	args := []interface{}{folderRequest.UserName}
+	deleteFolderQuery := "delete from folders where user_name = $1"
+	if folderRequest.Path != nil {
+		args = append(args, strings.Trim(*folderRequest.Path, "/"))
+		deleteFolderQuery += " AND path = $2"
+	}
+	if _, err := d.conn.ExecContext(ctx, deleteFolderQuery, args...); err != nil {
+		return fmt.Errorf("ошибка при удалении папок для пользователя %q: %w", folderRequest.UserName, err)
+	}
+	return nil
+}
+
+// saveSecret сохраняет секрет в транзакции: создает папку, выполняет запрос на вставку и помечает секрет тегами.
+// Запрос должен принимать идентификатор папки последним аргументом и возвращать идентификатор секрета.
+func (d *Db) saveSecret(ctx context.Context, table secretTable, userName string, folder *string, tags []string, query string, args ...interface{}) error {
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err = table.insert(ctx, tx, userName, folder, tags, query, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insert выполняет в транзакции запрос на вставку секрета в папку folder и помечает секрет тегами.
+// Возвращает идентификатор секрета.
+func (s secretTable) insert(ctx context.Context, tx *sql.Tx, userName string, folder *string, tags []string, query string, args ...interface{}) (int64, error) {
+	folderID, err := ensureFolder(ctx, tx, userName, folder)
+	if err != nil {
+		return 0, err
+	}
+	var id int64
+	if err = tx.QueryRowContext(ctx, query, append(args, folderID)...).Scan(&id); err != nil {
+		if conflictErr := asConflictError(err); conflictErr != nil {
+			err = conflictErr
+		}
+		return 0, err
+	}
+	if err = s.attachTags(ctx, tx, userName, id, tags); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// OrganizeSecret перемещает сохраненный секрет в другую папку и изменяет его теги в одной транзакции.
+// Пустой путь папки убирает секрет из папки. Секрет отмечается измененным, чтобы изменение дошло
+// до других устройств при синхронизации; ревизия секрета при этом не меняется.
+func (d *Db) OrganizeSecret(ctx context.Context, request models.OrganizeRequest) error {
+	table, err := tableOf(request.Type)
+	if err != nil {
+		return err
+	}
+
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении папки и тегов секрета для пользователя %q: %w", request.UserName, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// Перемещаем секрет и отмечаем его измененным; секрета в корзине или чужого секрета нет
+	args := []interface{}{request.SecretID, request.UserName}
+	organizeQuery := fmt.Sprintf("update %s set updated_at = now()", table.name)
+	if request.Folder != nil {
+		var folderID interface{}
+		if strings.Trim(*request.Folder, "/") != "" {
+			if folderID, err = ensureFolder(ctx, tx, request.UserName, request.Folder); err != nil {
+				return err
+			}
+		}
+		args = append(args, folderID)
+		organizeQuery += fmt.Sprintf(", folder_id = $%d", len(args))
+	}
+	organizeQuery += " where id = $1 and user_name = $2 and deleted_at is null"
+	res, err := tx.ExecContext(ctx, organizeQuery, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка при перемещении секрета %d для пользователя %q: %w", request.SecretID, request.UserName, err)
+	}
+	if err = checkUpdated(res); err != nil {
+		return err
+	}
+
+	if err = table.attachTags(ctx, tx, request.UserName, request.SecretID, request.AddTags); err != nil {
+		return err
+	}
+	if len(request.RemoveTags) > 0 {
+		detachQuery := fmt.Sprintf("delete from %s where %s = $1 and tag_id in (select id from tags where user_name = $2 and name = any($3))",
+			table.tagsTable, table.tagsColumn)
+		if _, err = tx.ExecContext(ctx, detachQuery, request.SecretID, request.UserName, pq.Array(request.RemoveTags)); err != nil {
+			return fmt.Errorf("ошибка при снятии тегов с секрета %d для пользователя %q: %w", request.SecretID, request.UserName, err)
+		}
+	}
+	return tx.Commit()
+}
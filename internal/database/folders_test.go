@@ -0,0 +1,297 @@
+package database
+
+import (
+	"context"
+	"crypto/aes"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDb_SaveFolder(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("positive: nested folder with parents", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("insert into folders").
+			WithArgs("bran", "north", nil, "north").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery("insert into folders").
+			WithArgs("bran", "wall", 1, "north/wall").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		mock.ExpectCommit()
+
+		pg := Db{
+			conn: mockDB,
+		}
+		err = pg.SaveFolder(ctx, models.Folder{UserName: "bran", Path: Ptr("/north/wall/")})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("negative: empty path segment", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		pg := Db{
+			conn: mockDB,
+		}
+		err = pg.SaveFolder(ctx, models.Folder{UserName: "bran", Path: Ptr("north//wall")})
+		assert.ErrorIs(t, err, ErrInvalidFolderPath)
+	})
+}
+
+func TestDb_GetFolders(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("positive: folder with subfolders", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectQuery("select user_name, path from folders where user_name").
+			WithArgs("bran", "north").
+			WillReturnRows(sqlmock.NewRows([]string{"user_name", "path"}).
+				AddRow("bran", "north").
+				AddRow("bran", "north/wall"))
+
+		pg := Db{
+			conn: mockDB,
+		}
+		folders, err := pg.GetFolders(ctx, models.Folder{UserName: "bran", Path: Ptr("north")})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Folder{{UserName: "bran", Path: Ptr("north")}, {UserName: "bran", Path: Ptr("north/wall")}}, folders)
+	})
+	t.Run("negative: no folders", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectQuery("select user_name, path from folders where user_name").
+			WithArgs("bran").
+			WillReturnRows(sqlmock.NewRows([]string{"user_name", "path"}))
+
+		pg := Db{
+			conn: mockDB,
+		}
+		_, err = pg.GetFolders(ctx, models.Folder{UserName: "bran"})
+		assert.EqualError(t, err, ErrNoData.Error())
+	})
+}
+
+func TestDb_DeleteFolder(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("negative: exec error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectExec("delete from folders where user_name").
+			WithArgs("bran", "north").
+			WillReturnError(errors.New("some error"))
+
+		pg := Db{
+			conn: mockDB,
+		}
+		err = pg.DeleteFolder(ctx, models.Folder{UserName: "bran", Path: Ptr("north")})
+		assert.EqualError(t, err, "ошибка при удалении папок для пользователя \"bran\": some error")
+	})
+}
+
+func TestDb_SaveNoteWithFolderAndTags(t *testing.T) {
+	key := "thisis32bitlongpassphraseimusing"
+	c, _ := aes.NewCipher([]byte(key))
+	ctx := context.Background()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	pg := Db{
+		conn:          mockDB,
+		encryptionKey: key,
+		dataCipher:    c,
+	}
+	encryptedContent, _ := pg.encryptAES("the night is dark")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("insert into folders").
+		WithArgs("bran", "visions", nil, "visions").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	mock.ExpectQuery("insert into notes").
+		WithArgs("bran", "raven", encryptedContent, nil, nil, pg.noteSearchTokens("bran", "the night is dark"), 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	mock.ExpectQuery("insert into tags").
+		WithArgs("bran", "weirwood").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectExec("insert into note_tags").
+		WithArgs(11, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = pg.SaveNote(ctx, models.Note{
+		UserName: "bran",
+		Title:    Ptr("raven"),
+		Content:  Ptr("the night is dark"),
+		Folder:   Ptr("visions"),
+		Tags:     []string{"weirwood"},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDb_GetCardByFolderAndTags(t *testing.T) {
+	key := "thisis32bitlongpassphraseimusing"
+	c, _ := aes.NewCipher([]byte(key))
+	ctx := context.Background()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	mock.ExpectQuery(`from cards where user_name = \$1 and deleted_at is null AND folder_id in .+ AND id in .+ AND id in .+`).
+		WithArgs("bran", "north", "gold", "iron").
+		WillReturnRows(sqlmock.NewRows([]string{"user_name", "bank_name", "number", "cv", "password", "card_type", "metadata", "fields", "folder", "tags", "created_at", "updated_at", "last_accessed_at", "revision", "id", "sort_key"}).
+			AddRow("bran", "iron bank", "1111222233334444", "j1pD", "1Rod2PHMNHmQ", nil, nil, nil, "north/wall", `["gold", "iron"]`, nil, nil, nil, nil, 1, "1"))
+	mock.ExpectExec("update cards set last_accessed_at").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	pg := Db{
+		conn:          mockDB,
+		encryptionKey: key,
+		dataCipher:    c,
+	}
+	cards, _, err := pg.GetCard(ctx, models.Card{UserName: "bran", Folder: Ptr("north/"), Tags: []string{"gold", "iron"}}, models.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, cards, 1)
+	assert.Equal(t, Ptr("north/wall"), cards[0].Folder)
+	assert.Equal(t, []string{"gold", "iron"}, cards[0].Tags)
+}
+
+func TestDb_OrganizeSecret(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("positive: secret moved and tagged", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("insert into folders").
+			WithArgs("arya", "braavos", nil, "braavos").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		mock.ExpectExec("update notes set updated_at = now\\(\\), folder_id = \\$3 where id = \\$1 and user_name = \\$2 and deleted_at is null").
+			WithArgs(5, "arya", 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("insert into tags").
+			WithArgs("arya", "faceless").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+		mock.ExpectExec("insert into note_tags \\(note_id, tag_id\\)").
+			WithArgs(5, 9).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("delete from note_tags where note_id = \\$1 and tag_id in \\(select id from tags where user_name = \\$2 and name = any\\(\\$3\\)\\)").
+			WithArgs(5, "arya", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		pg := Db{
+			conn: mockDB,
+		}
+		err = pg.OrganizeSecret(ctx, models.OrganizeRequest{
+			UserName: "arya", Type: models.SecretNote, SecretID: 5, Folder: Ptr("braavos"), AddTags: []string{"faceless"}, RemoveTags: []string{"stark"},
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("positive: secret removed from folder", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("update cards set updated_at = now\\(\\), folder_id = \\$3 where id = \\$1").
+			WithArgs(2, "arya", nil).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		pg := Db{
+			conn: mockDB,
+		}
+		err = pg.OrganizeSecret(ctx, models.OrganizeRequest{UserName: "arya", Type: models.SecretCard, SecretID: 2, Folder: Ptr("")})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("negative: secret not found", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("update credentials set updated_at = now\\(\\) where id = \\$1").
+			WithArgs(8, "arya").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		pg := Db{
+			conn: mockDB,
+		}
+		err = pg.OrganizeSecret(ctx, models.OrganizeRequest{UserName: "arya", Type: models.SecretCredentials, SecretID: 8, AddTags: []string{"needle"}})
+		assert.ErrorIs(t, err, ErrNoData)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("negative: empty tag name", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("update notes set updated_at = now\\(\\) where id = \\$1").
+			WithArgs(5, "arya").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectRollback()
+
+		pg := Db{
+			conn: mockDB,
+		}
+		err = pg.OrganizeSecret(ctx, models.OrganizeRequest{UserName: "arya", Type: models.SecretNote, SecretID: 5, AddTags: []string{" "}})
+		assert.ErrorIs(t, err, ErrInvalidTagName)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("negative: unknown secret type", func(t *testing.T) {
+		pg := Db{}
+		err := pg.OrganizeSecret(ctx, models.OrganizeRequest{UserName: "arya", Type: "totp", SecretID: 5, AddTags: []string{"needle"}})
+		assert.EqualError(t, err, `неизвестный тип секрета "totp"`)
+	})
+}
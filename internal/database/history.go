@@ -0,0 +1,259 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/lib/pq"
+)
+
+// historyIgnoredFields поля секрета, которые не меняются при обновлении и не попадают в список изменений
+var historyIgnoredFields = map[string]bool{
+	"user_name": true, "id": true, "folder": true, "tags": true,
+	"created_at": true, "updated_at": true, "last_accessed_at": true, "revision": true,
+}
+
+// saveNoteVersion сохраняет в истории текущую версию заметки перед ее обновлением.
+// Строка заметки блокируется до конца транзакции. Если заметки нет, история не меняется.
+// Если в запросе указана ожидаемая ревизия, а заметка отсутствует или изменена, возвращается ErrRevisionMismatch.
+// Не указанные в next пользовательские поля заполняются текущими значениями.
+func (d *Db) saveNoteVersion(ctx context.Context, tx *sql.Tx, next *models.Note) error {
+	var (
+		id, revision int64
+		content      string
+		metadata     sql.NullString
+		fields       sql.NullString
+	)
+	currentQuery := "select id, content, metadata, fields, revision from notes where user_name = $1 and title = $2 and deleted_at is null for update"
+	err := tx.QueryRowContext(ctx, currentQuery, next.UserName, *next.Title).Scan(&id, &content, &metadata, &fields, &revision)
+	if errors.Is(err, sql.ErrNoRows) {
+		return checkRevision(next.Revision, 0)
+	}
+	if err != nil {
+		return err
+	}
+	if err = checkRevision(next.Revision, revision); err != nil {
+		return err
+	}
+
+	decryptedContent, err := d.decryptAES(content)
+	if err != nil {
+		return fmt.Errorf("ошибка при расшифровке контента заметки: %w", err)
+	}
+	previous := models.Note{UserName: next.UserName, Title: next.Title, Content: &decryptedContent}
+	if metadata.Valid {
+		previous.Metadata = &metadata.String
+	}
+	if fields.Valid {
+		if previous.Fields, err = d.unmarshalFields(fields.String); err != nil {
+			return err
+		}
+	}
+	if next.Fields == nil {
+		next.Fields = previous.Fields
+	}
+	return d.saveVersion(ctx, tx, models.SecretNote, next.UserName, id, revision, previous, *next)
+}
+
+// saveCredentialsVersion сохраняет в истории текущую версию учетных данных перед их обновлением.
+// Строка учетных данных блокируется до конца транзакции. Если учетных данных нет, история не меняется.
+// Если в запросе указана ожидаемая ревизия, а учетные данные отсутствуют или изменены, возвращается ErrRevisionMismatch.
+// Не указанные в next название, адреса и пользовательские поля заполняются текущими значениями,
+// а пустое название заменяется отсутствующим.
+func (d *Db) saveCredentialsVersion(ctx context.Context, tx *sql.Tx, next *models.Credentials) error {
+	var (
+		id, revision                 int64
+		password, site               string
+		metadata, name, urls, fields sql.NullString
+	)
+	currentQuery := "select id, password, metadata, site, name, urls, fields, revision from credentials where user_name = $1 and login = $2 and site = $3 and deleted_at is null for update"
+	err := tx.QueryRowContext(ctx, currentQuery, next.UserName, *next.Login, models.ValueOrEmpty(next.Site)).
+		Scan(&id, &password, &metadata, &site, &name, &urls, &fields, &revision)
+	if errors.Is(err, sql.ErrNoRows) {
+		return checkRevision(next.Revision, 0)
+	}
+	if err != nil {
+		return err
+	}
+	if err = checkRevision(next.Revision, revision); err != nil {
+		return err
+	}
+
+	decryptedPassword, err := d.decryptAES(password)
+	if err != nil {
+		return fmt.Errorf("error while decrypting password: %w", err)
+	}
+	previous := models.Credentials{UserName: next.UserName, Login: next.Login, Password: &decryptedPassword}
+	if metadata.Valid {
+		previous.Metadata = &metadata.String
+	}
+	if site != "" {
+		previous.Site = &site
+	}
+	if name.Valid {
+		previous.Name = &name.String
+	}
+	if urls.Valid {
+		if err = json.Unmarshal([]byte(urls.String), &previous.URLs); err != nil {
+			return fmt.Errorf("error while decoding credentials urls: %w", err)
+		}
+	}
+	if fields.Valid {
+		if previous.Fields, err = d.unmarshalFields(fields.String); err != nil {
+			return err
+		}
+	}
+	if next.Name == nil {
+		next.Name = previous.Name
+	} else if *next.Name == "" {
+		next.Name = nil
+	}
+	if next.URLs == nil {
+		next.URLs = previous.URLs
+	}
+	if next.Fields == nil {
+		next.Fields = previous.Fields
+	}
+	return d.saveVersion(ctx, tx, models.SecretCredentials, next.UserName, id, revision, previous, *next)
+}
+
+// saveVersion шифрует и сохраняет предыдущую версию секрета вместе со списком изменившихся полей,
+// после чего удаляет версии, вышедшие за пределы срока хранения.
+func (d *Db) saveVersion(ctx context.Context, tx *sql.Tx, secretType models.SecretType, userName string, id, revision int64, previous, next interface{}) error {
+	data, err := json.Marshal(previous)
+	if err != nil {
+		return fmt.Errorf("ошибка при кодировании версии секрета: %w", err)
+	}
+	encryptedData, err := d.encryptAES(string(data))
+	if err != nil {
+		return fmt.Errorf("ошибка при шифровании версии секрета: %w", err)
+	}
+	changes, err := changedFields(previous, next)
+	if err != nil {
+		return err
+	}
+
+	saveVersionQuery := "insert into secret_history (user_name, secret_type, secret_id, revision, data, changes, changed_by) values ($1, $2, $3, $4, $5, $6, $7)"
+	if _, err = tx.ExecContext(ctx, saveVersionQuery, userName, secretType, id, revision, encryptedData, pq.Array(changes), userName); err != nil {
+		return err
+	}
+	if d.historyRetention > 0 && revision > int64(d.historyRetention) {
+		pruneQuery := "delete from secret_history where secret_type = $1 and secret_id = $2 and revision <= $3"
+		if _, err = tx.ExecContext(ctx, pruneQuery, secretType, id, revision-int64(d.historyRetention)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// changedFields возвращает отсортированные JSON-имена полей, значения которых отличаются в двух версиях секрета
+func changedFields(previous, next interface{}) ([]string, error) {
+	prevFields, err := jsonFields(previous)
+	if err != nil {
+		return nil, err
+	}
+	nextFields, err := jsonFields(next)
+	if err != nil {
+		return nil, err
+	}
+	var changes []string
+	for name, prevValue := range prevFields {
+		if !reflect.DeepEqual(prevValue, nextFields[name]) {
+			changes = append(changes, name)
+		}
+	}
+	for name := range nextFields {
+		if _, ok := prevFields[name]; !ok {
+			changes = append(changes, name)
+		}
+	}
+	sort.Strings(changes)
+	return changes, nil
+}
+
+// jsonFields раскладывает секрет на JSON-поля, пропуская поля из historyIgnoredFields
+func jsonFields(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при кодировании версии секрета: %w", err)
+	}
+	var fields map[string]interface{}
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("ошибка при кодировании версии секрета: %w", err)
+	}
+	for name := range historyIgnoredFields {
+		delete(fields, name)
+	}
+	return fields, nil
+}
+
+// GetHistory получает сохраненные версии секрета пользователя, начиная с последней.
+// Если в запросе указан номер версии, возвращается только она.
+func (d *Db) GetHistory(ctx context.Context, historyRequest models.HistoryRequest) ([]models.SecretVersion, error) {
+	args := []interface{}{historyRequest.UserName, historyRequest.Type, historyRequest.SecretID}
+	getHistoryQuery := "select revision, data, changes, changed_by, changed_at from secret_history where user_name = $1 and secret_type = $2 and secret_id = $3"
+	if historyRequest.Version != 0 {
+		args = append(args, historyRequest.Version)
+		getHistoryQuery += fmt.Sprintf(" and revision = $%d", len(args))
+	}
+	getHistoryQuery += " order by revision desc"
+	rows, err := d.conn.QueryContext(ctx, getHistoryQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении истории секрета для пользователя %q: %w", historyRequest.UserName, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var versions []models.SecretVersion
+	for rows.Next() {
+		version := models.SecretVersion{Type: historyRequest.Type, SecretID: historyRequest.SecretID}
+		var data string
+		if err = rows.Scan(&version.Version, &data, pq.Array(&version.Changes), &version.ChangedBy, &version.ChangedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании строк после запроса на получение истории секрета: %w", err)
+		}
+		decryptedData, err := d.decryptAES(data)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при расшифровке версии секрета: %w", err)
+		}
+		switch historyRequest.Type {
+		case models.SecretNote:
+			version.Note = &models.Note{}
+			err = json.Unmarshal([]byte(decryptedData), version.Note)
+		case models.SecretCredentials:
+			version.Credentials = &models.Credentials{}
+			err = json.Unmarshal([]byte(decryptedData), version.Credentials)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при декодировании версии секрета: %w", err)
+		}
+		versions = append(versions, version)
+	}
+	if len(versions) == 0 {
+		return nil, ErrNoData
+	}
+	return versions, nil
+}
+
+// RestoreVersion восстанавливает сохраненную версию секрета.
+// Восстановление выполняется как обычное обновление, поэтому текущая версия тоже попадает в историю.
+func (d *Db) RestoreVersion(ctx context.Context, historyRequest models.HistoryRequest) error {
+	versions, err := d.GetHistory(ctx, historyRequest)
+	if err != nil {
+		return err
+	}
+	switch version := versions[0]; historyRequest.Type {
+	case models.SecretNote:
+		return d.UpdateNote(ctx, replacingNote(*version.Note))
+	case models.SecretCredentials:
+		return d.UpdateCredentials(ctx, replacingCredentials(*version.Credentials))
+	default:
+		return fmt.Errorf("история секретов типа %q не ведется", historyRequest.Type)
+	}
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"context"
+	"crypto/aes"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangedFields(t *testing.T) {
+	previous := models.Credentials{UserName: "bran", Login: Ptr("raven"), Password: Ptr("weirwood"), Folder: Ptr("north")}
+	next := models.Credentials{UserName: "bran", Login: Ptr("raven"), Password: Ptr("greensight"), Metadata: Ptr("three-eyed")}
+
+	changes, err := changedFields(previous, next)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"metadata", "password"}, changes)
+}
+
+func TestDb_GetHistory(t *testing.T) {
+	key := "thisis32bitlongpassphraseimusing"
+	c, _ := aes.NewCipher([]byte(key))
+	ctx := context.Background()
+	changedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"revision", "data", "changes", "changed_by", "changed_at"}
+
+	t.Run("positive: decrypted versions", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		data, _ := pg.encryptAES(`{"user_name":"bran","title":"visions","content":"the night king"}`)
+		mock.ExpectQuery("select revision, data, changes, changed_by, changed_at from secret_history where user_name = \\$1 and secret_type = \\$2 and secret_id = \\$3 order by revision desc").
+			WithArgs("bran", models.SecretNote, 4).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(2, data, "{content}", "bran", changedAt))
+
+		versions, err := pg.GetHistory(ctx, models.HistoryRequest{UserName: "bran", Type: models.SecretNote, SecretID: 4})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.SecretVersion{{
+			Type: models.SecretNote, SecretID: 4, Version: 2, ChangedBy: "bran", ChangedAt: changedAt,
+			Changes: []string{"content"},
+			Note:    &models.Note{UserName: "bran", Title: Ptr("visions"), Content: Ptr("the night king")},
+		}}, versions)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("negative: no versions", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectQuery("select revision, .+ from secret_history where .+ and revision = \\$4 order by revision desc").
+			WithArgs("bran", models.SecretCredentials, 4, 9).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		_, err = pg.GetHistory(ctx, models.HistoryRequest{UserName: "bran", Type: models.SecretCredentials, SecretID: 4, Version: 9})
+		assert.ErrorIs(t, err, ErrNoData)
+	})
+}
+
+func TestDb_RestoreVersion(t *testing.T) {
+	key := "thisis32bitlongpassphraseimusing"
+	c, _ := aes.NewCipher([]byte(key))
+	ctx := context.Background()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	pg := Db{
+		conn:          mockDB,
+		encryptionKey: key,
+		dataCipher:    c,
+	}
+	data, _ := pg.encryptAES(`{"user_name":"bran","login":"raven","password":"qwerty12"}`)
+	mock.ExpectQuery("select revision, .+ from secret_history").
+		WithArgs("bran", models.SecretCredentials, 7, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"revision", "data", "changes", "changed_by", "changed_at"}).
+			AddRow(1, data, "{password}", "bran", time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectQuery("select id, password, .+ from credentials .+ for update").
+		WithArgs("bran", "raven", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "password", "metadata", "site", "name", "urls", "fields", "revision"}).
+			AddRow(7, "1QQdwPbUL3mQ", nil, "", nil, nil, nil, 2))
+	mock.ExpectExec("insert into secret_history").
+		WithArgs("bran", models.SecretCredentials, 7, 2, sqlmock.AnyArg(), pq.Array([]string{"password"}), "bran").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("update credentials set password").
+		WithArgs("zR8XxOfadyU=", nil, nil, nil, nil, "bran", Ptr("raven"), "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = pg.RestoreVersion(ctx, models.HistoryRequest{UserName: "bran", Type: models.SecretCredentials, SecretID: 7, Version: 1})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,256 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// ImportSecrets сохраняет пакет учетных данных, заметок и карт в одной транзакции.
+// Секреты, ключ которых уже есть в хранилище или встречался раньше в пакете, пропускаются.
+// В режиме проверки пакет сохраняется так же, но транзакция откатывается, поэтому отчет совпадает с результатом загрузки.
+func (d *Db) ImportSecrets(ctx context.Context, batch models.ImportBatch) (models.ImportResult, error) {
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return models.ImportResult{}, fmt.Errorf("ошибка при загрузке секретов для пользователя %q: %w", batch.UserName, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	res := models.ImportResult{Report: []models.ImportReportItem{}}
+	// record учитывает результат загрузки секрета в отчете
+	record := func(secretType models.SecretType, name string, action models.ImportAction) {
+		res.Report = append(res.Report, models.ImportReportItem{Type: secretType, Name: name, Action: action})
+		if action == models.ImportCreated {
+			res.Created++
+		} else {
+			res.Skipped++
+		}
+	}
+
+	existsCredsQuery := "select exists(select 1 from credentials where user_name = $1 and login = $2 and site = $3 and deleted_at is null)"
+	for _, creds := range batch.Credentials {
+		creds.UserName = batch.UserName
+		var exists bool
+		if err = tx.QueryRowContext(ctx, existsCredsQuery, batch.UserName, *creds.Login, models.ValueOrEmpty(creds.Site)).Scan(&exists); err != nil {
+			return models.ImportResult{}, fmt.Errorf("ошибка при проверке учетных данных %q: %w", creds.ReportName(), err)
+		}
+		if !exists {
+			query, args, err := d.credentialsInsert(creds)
+			if err != nil {
+				return models.ImportResult{}, err
+			}
+			if _, err = credentialsTable.insert(ctx, tx, batch.UserName, creds.Folder, creds.Tags, query, args...); err != nil {
+				return models.ImportResult{}, fmt.Errorf("ошибка при загрузке учетных данных %q: %w", creds.ReportName(), err)
+			}
+		}
+		record(models.SecretCredentials, creds.ReportName(), importAction(exists))
+	}
+
+	existsNoteQuery := "select exists(select 1 from notes where user_name = $1 and title = $2 and deleted_at is null)"
+	for _, note := range batch.Notes {
+		note.UserName = batch.UserName
+		var exists bool
+		if err = tx.QueryRowContext(ctx, existsNoteQuery, batch.UserName, *note.Title).Scan(&exists); err != nil {
+			return models.ImportResult{}, fmt.Errorf("ошибка при проверке заметки %q: %w", note.ReportName(), err)
+		}
+		if !exists {
+			query, args, err := d.noteInsert(note)
+			if err != nil {
+				return models.ImportResult{}, err
+			}
+			if _, err = notesTable.insert(ctx, tx, batch.UserName, note.Folder, note.Tags, query, args...); err != nil {
+				return models.ImportResult{}, fmt.Errorf("ошибка при загрузке заметки %q: %w", note.ReportName(), err)
+			}
+		}
+		record(models.SecretNote, note.ReportName(), importAction(exists))
+	}
+
+	existsCardQuery := "select exists(select 1 from cards where user_name = $1 and number = $2 and deleted_at is null)"
+	for _, card := range batch.Cards {
+		card.UserName = batch.UserName
+		var exists bool
+		if err = tx.QueryRowContext(ctx, existsCardQuery, batch.UserName, *card.Number).Scan(&exists); err != nil {
+			return models.ImportResult{}, fmt.Errorf("ошибка при проверке карты %q: %w", card.ReportName(), err)
+		}
+		if !exists {
+			query, args, err := d.cardInsert(card)
+			if err != nil {
+				return models.ImportResult{}, err
+			}
+			if _, err = cardsTable.insert(ctx, tx, batch.UserName, card.Folder, card.Tags, query, args...); err != nil {
+				return models.ImportResult{}, fmt.Errorf("ошибка при загрузке карты %q: %w", card.ReportName(), err)
+			}
+		}
+		record(models.SecretCard, card.ReportName(), importAction(exists))
+	}
+
+	if batch.DryRun {
+		return res, nil
+	}
+	if err = tx.Commit(); err != nil {
+		return models.ImportResult{}, fmt.Errorf("ошибка при загрузке секретов для пользователя %q: %w", batch.UserName, err)
+	}
+	return res, nil
+}
+
+// importAction возвращает результат загрузки секрета по признаку его существования в хранилище
+func importAction(exists bool) models.ImportAction {
+	if exists {
+		return models.ImportSkipped
+	}
+	return models.ImportCreated
+}
+
+// ImportVault загружает выгрузку секретов пользователя в одной транзакции: при любой ошибке хранилище
+// пользователя остается таким, каким было до загрузки.
+//
+// В режиме skip-duplicates секреты, которые уже есть в хранилище, пропускаются, в режиме merge - заменяются
+// загружаемыми. В режиме replace все секреты пользователя предварительно удаляются: учетные данные, заметки
+// и карты перемещаются в корзину, а секреты TOTP и SSH-ключи удаляются окончательно.
+func (d *Db) ImportVault(ctx context.Context, vaultImport models.VaultImport) (models.ImportResult, error) {
+	userName, vault, mode := vaultImport.UserName, vaultImport.Vault, vaultImport.Mode
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return models.ImportResult{}, fmt.Errorf("ошибка при загрузке секретов для пользователя %q: %w", userName, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if mode == models.ImportReplace {
+		for _, query := range []string{
+			"delete from totp where user_name = $1",
+			"delete from ssh_keys where user_name = $1",
+			"update credentials set deleted_at = now() where user_name = $1 and deleted_at is null",
+			"update notes set deleted_at = now() where user_name = $1 and deleted_at is null",
+			"update cards set deleted_at = now() where user_name = $1 and deleted_at is null",
+		} {
+			if _, err = tx.ExecContext(ctx, query, userName); err != nil {
+				return models.ImportResult{}, fmt.Errorf("ошибка при удалении секретов пользователя %q перед загрузкой: %w", userName, err)
+			}
+		}
+	}
+
+	var res models.ImportResult
+	// importItem проверяет запросом exists, есть ли секрет в хранилище, и сохраняет его функцией insert.
+	// Существующий секрет в режиме merge заменяется функцией replace, в остальных режимах пропускается.
+	importItem := func(exists string, args []interface{}, insert, replace func() error) error {
+		var found bool
+		if err := tx.QueryRowContext(ctx, exists, args...).Scan(&found); err != nil {
+			return err
+		}
+		switch {
+		case !found:
+			if err := insert(); err != nil {
+				return err
+			}
+			res.Created++
+		case mode == models.ImportMerge:
+			if err := replace(); err != nil {
+				return err
+			}
+			res.Updated++
+		default:
+			res.Skipped++
+		}
+		return nil
+	}
+
+	for _, folder := range vault.Folders {
+		if _, err = ensureFolder(ctx, tx, userName, folder.Path); err != nil {
+			return models.ImportResult{}, fmt.Errorf("ошибка при загрузке папки %q: %w", *folder.Path, err)
+		}
+	}
+
+	existsCredsQuery := "select exists(select 1 from credentials where user_name = $1 and login = $2 and site = $3 and deleted_at is null)"
+	for _, creds := range vault.Credentials {
+		creds.UserName, creds.Audit = userName, models.Audit{}
+		err = importItem(existsCredsQuery, []interface{}{userName, *creds.Login, models.ValueOrEmpty(creds.Site)}, func() error {
+			query, args, err := d.credentialsInsert(creds)
+			if err != nil {
+				return err
+			}
+			_, err = credentialsTable.insert(ctx, tx, userName, creds.Folder, creds.Tags, query, args...)
+			return err
+		}, func() error {
+			return d.updateCredentials(ctx, tx, replacingCredentials(creds))
+		})
+		if err != nil {
+			return models.ImportResult{}, fmt.Errorf("ошибка при загрузке учетных данных %q: %w", *creds.Login, err)
+		}
+	}
+
+	existsNoteQuery := "select exists(select 1 from notes where user_name = $1 and title = $2 and deleted_at is null)"
+	for _, note := range vault.Notes {
+		note.UserName, note.Audit = userName, models.Audit{}
+		err = importItem(existsNoteQuery, []interface{}{userName, *note.Title}, func() error {
+			query, args, err := d.noteInsert(note)
+			if err != nil {
+				return err
+			}
+			_, err = notesTable.insert(ctx, tx, userName, note.Folder, note.Tags, query, args...)
+			return err
+		}, func() error {
+			return d.updateNote(ctx, tx, replacingNote(note))
+		})
+		if err != nil {
+			return models.ImportResult{}, fmt.Errorf("ошибка при загрузке заметки %q: %w", *note.Title, err)
+		}
+	}
+
+	existsCardQuery := "select exists(select 1 from cards where user_name = $1 and number = $2 and deleted_at is null)"
+	for _, card := range vault.Cards {
+		card.UserName, card.Audit = userName, models.Audit{}
+		insertCard := func() error {
+			query, args, err := d.cardInsert(card)
+			if err != nil {
+				return err
+			}
+			_, err = cardsTable.insert(ctx, tx, userName, card.Folder, card.Tags, query, args...)
+			return err
+		}
+		err = importItem(existsCardQuery, []interface{}{userName, *card.Number}, insertCard, func() error {
+			return d.updateCard(ctx, tx, replacingCard(card))
+		})
+		if err != nil {
+			return models.ImportResult{}, fmt.Errorf("ошибка при загрузке карты банка %q: %w", *card.BankName, err)
+		}
+	}
+
+	existsKeyQuery := "select exists(select 1 from ssh_keys where user_name = $1 and name = $2)"
+	for _, key := range vault.SSHKeys {
+		key.UserName, key.Audit = userName, models.Audit{}
+		insertKey := func() error {
+			return d.insertSSHKey(ctx, tx, key)
+		}
+		err = importItem(existsKeyQuery, []interface{}{userName, *key.Name}, insertKey, func() error {
+			return d.replaceSSHKey(ctx, tx, key)
+		})
+		if err != nil {
+			return models.ImportResult{}, fmt.Errorf("ошибка при загрузке SSH-ключа %q: %w", *key.Name, err)
+		}
+	}
+
+	// Секреты TOTP загружаются последними, так как привязываются к уже загруженным учетным данным
+	existsTOTPQuery := "select exists(select 1 from totp where user_name = $1 and name = $2)"
+	for _, totp := range vault.TOTP {
+		totp.UserName, totp.Audit = userName, models.Audit{}
+		insertTOTP := func() error {
+			return d.insertTOTP(ctx, tx, totp)
+		}
+		err = importItem(existsTOTPQuery, []interface{}{userName, *totp.Name}, insertTOTP, func() error {
+			return d.replaceTOTP(ctx, tx, totp)
+		})
+		if err != nil {
+			return models.ImportResult{}, fmt.Errorf("ошибка при загрузке секрета TOTP %q: %w", *totp.Name, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.ImportResult{}, fmt.Errorf("ошибка при загрузке секретов для пользователя %q: %w", userName, err)
+	}
+	return res, nil
+}
@@ -0,0 +1,59 @@
+//go:build integration
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportVault_RollbackIntegration(t *testing.T) {
+	dsn := os.Getenv(integrationDSN)
+	if dsn == "" {
+		t.Skipf("переменная %s не задана", integrationDSN)
+	}
+	ctx := context.Background()
+	admin, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	defer admin.Close()
+
+	const key = "0123456789abcdef"
+	db := createTestDatabase(t, admin, dsn, "gophervault_import_"+strconv.FormatInt(time.Now().UnixNano(), 10), key)
+
+	require.NoError(t, db.Register(ctx, "arya", "needle"))
+	require.NoError(t, db.SaveNote(ctx, models.Note{UserName: "arya", Title: Ptr("list"), Content: Ptr("cersei, the mountain")}))
+	require.NoError(t, db.SaveTOTP(ctx, models.TOTP{UserName: "arya", Name: Ptr("mail"), URI: Ptr("otpauth://totp/mail?secret=JBSWY3DP")}))
+	require.NoError(t, db.SaveSSHKey(ctx, models.SSHKey{UserName: "arya", Name: Ptr("deploy"), PrivateKey: Ptr("key"),
+		PublicKey: Ptr("ssh-ed25519 AAAA"), Fingerprint: Ptr("SHA256:x"), KeyType: Ptr("ssh-ed25519")}))
+
+	// Секрет TOTP ссылается на учетные данные, которых нет в выгрузке, поэтому загрузка прерывается после заметки
+	_, err = db.ImportVault(ctx, models.VaultImport{UserName: "arya", Mode: models.ImportReplace, Vault: models.Vault{
+		Version: models.VaultVersion,
+		Notes:   []models.Note{{Title: Ptr("new list"), Content: Ptr("the hound")}},
+		TOTP:    []models.TOTP{{Name: Ptr("bank"), URI: Ptr("otpauth://totp/bank?secret=JBSWY3DP"), Login: Ptr("nobody")}},
+	}})
+	require.ErrorIs(t, err, ErrNoSuchCredentials)
+
+	// Хранилище осталось таким, каким было до загрузки
+	notes, _, err := db.GetNotes(ctx, models.Note{UserName: "arya"}, models.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, "cersei, the mountain", *notes[0].Content)
+	totp, err := db.GetTOTP(ctx, models.TOTP{UserName: "arya"})
+	require.NoError(t, err)
+	require.Len(t, totp, 1)
+	assert.Equal(t, "mail", *totp[0].Name)
+	keys, err := db.GetSSHKeys(ctx, models.SSHKey{UserName: "arya"})
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	_, err = db.GetTrash(ctx, models.TrashRequest{UserName: "arya"})
+	assert.ErrorIs(t, err, ErrNoData)
+}
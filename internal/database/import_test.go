@@ -0,0 +1,235 @@
+package database
+
+import (
+	"context"
+	"crypto/aes"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDb_ImportSecrets(t *testing.T) {
+	key := "thisis32bitlongpassphraseimusing"
+	c, _ := aes.NewCipher([]byte(key))
+	ctx := context.Background()
+	batch := models.ImportBatch{
+		UserName:    "davos",
+		Credentials: []models.Credentials{{Login: Ptr("onion"), Password: Ptr("knight"), Site: Ptr("dragonstone.org"), Folder: Ptr("work")}},
+		Notes:       []models.Note{{Title: Ptr("letters"), Content: Ptr("some lovely notes")}},
+	}
+	existsCreds := "select exists\\(select 1 from credentials where user_name = \\$1 and login = \\$2 and site = \\$3 and deleted_at is null\\)"
+	existsNote := "select exists\\(select 1 from notes where user_name = \\$1 and title = \\$2 and deleted_at is null\\)"
+
+	expectBatch := func(mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(existsCreds).
+			WithArgs("davos", "onion", "dragonstone.org").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery("insert into folders").
+			WithArgs("davos", "work", nil, "work").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		mock.ExpectQuery("insert into credentials").
+			WithArgs("davos", "onion", sqlmock.AnyArg(), nil, "dragonstone.org", nil, nil, nil, int64(3)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+		mock.ExpectQuery(existsNote).
+			WithArgs("davos", "letters").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	}
+	expectedReport := []models.ImportReportItem{
+		{Type: models.SecretCredentials, Name: "onion @ dragonstone.org", Action: models.ImportCreated},
+		{Type: models.SecretNote, Name: "letters", Action: models.ImportSkipped},
+	}
+
+	t.Run("positive: new secrets created, existing skipped", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+		expectBatch(mock)
+		mock.ExpectCommit()
+
+		pg := Db{conn: mockDB, encryptionKey: key, dataCipher: c}
+		res, err := pg.ImportSecrets(ctx, batch)
+		assert.NoError(t, err)
+		assert.Equal(t, models.ImportResult{Created: 1, Skipped: 1, Report: expectedReport}, res)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("positive: dry run rolls back", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+		expectBatch(mock)
+		mock.ExpectRollback()
+
+		pg := Db{conn: mockDB, encryptionKey: key, dataCipher: c}
+		dryRun := batch
+		dryRun.DryRun = true
+		res, err := pg.ImportSecrets(ctx, dryRun)
+		assert.NoError(t, err)
+		assert.Equal(t, models.ImportResult{Created: 1, Skipped: 1, Report: expectedReport}, res)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("negative: insert error rolls back the whole batch", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+		mock.ExpectBegin()
+		mock.ExpectQuery(existsCreds).
+			WithArgs("davos", "onion", "dragonstone.org").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery("insert into folders").
+			WillReturnError(errors.New("connection refused"))
+		mock.ExpectRollback()
+
+		pg := Db{conn: mockDB, encryptionKey: key, dataCipher: c}
+		_, err = pg.ImportSecrets(ctx, batch)
+		assert.EqualError(t, err, `ошибка при загрузке учетных данных "onion @ dragonstone.org": ошибка при создании папки "work": connection refused`)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDb_ImportVault(t *testing.T) {
+	key := "thisis32bitlongpassphraseimusing"
+	c, _ := aes.NewCipher([]byte(key))
+	ctx := context.Background()
+	vault := models.Vault{
+		Version: models.VaultVersion,
+		Notes:   []models.Note{{UserName: "stannis", Title: Ptr("letters"), Content: Ptr("some lovely notes")}},
+		TOTP:    []models.TOTP{{UserName: "stannis", Name: Ptr("mail"), URI: Ptr("otpauth://totp/mail?secret=JBSWY3DP")}},
+		SSHKeys: []models.SSHKey{{UserName: "stannis", Name: Ptr("deploy"), PrivateKey: Ptr("key"), PublicKey: Ptr("ssh-ed25519 AAAA"),
+			Fingerprint: Ptr("SHA256:x"), KeyType: Ptr("ssh-ed25519")}},
+	}
+	existsNote := "select exists\\(select 1 from notes where user_name = \\$1 and title = \\$2 and deleted_at is null\\)"
+	existsKey := "select exists\\(select 1 from ssh_keys where user_name = \\$1 and name = \\$2\\)"
+	existsTOTP := "select exists\\(select 1 from totp where user_name = \\$1 and name = \\$2\\)"
+
+	expectReplace := func(mock sqlmock.Sqlmock) {
+		mock.ExpectExec("delete from totp where user_name = \\$1").WithArgs("davos").WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("delete from ssh_keys where user_name = \\$1").WithArgs("davos").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("update credentials set deleted_at = now\\(\\) where user_name = \\$1 and deleted_at is null").
+			WithArgs("davos").WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec("update notes set deleted_at = now\\(\\) where user_name = \\$1 and deleted_at is null").
+			WithArgs("davos").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("update cards set deleted_at = now\\(\\) where user_name = \\$1 and deleted_at is null").
+			WithArgs("davos").WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	t.Run("positive: replace deletes and loads in one transaction", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		expectReplace(mock)
+		mock.ExpectQuery(existsNote).WithArgs("davos", "letters").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery("insert into notes").
+			WithArgs("davos", "letters", sqlmock.AnyArg(), nil, nil, sqlmock.AnyArg(), nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+		mock.ExpectQuery(existsKey).WithArgs("davos", "deploy").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec("insert into ssh_keys").
+			WithArgs("davos", "deploy", sqlmock.AnyArg(), nil, "ssh-ed25519 AAAA", "SHA256:x", "ssh-ed25519", nil).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery(existsTOTP).WithArgs("davos", "mail").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec("insert into totp").
+			WithArgs("davos", "mail", sqlmock.AnyArg(), nil, nil).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		pg := Db{conn: mockDB, encryptionKey: key, dataCipher: c}
+		res, err := pg.ImportVault(ctx, models.VaultImport{UserName: "davos", Mode: models.ImportReplace, Vault: vault})
+		assert.NoError(t, err)
+		assert.Equal(t, models.ImportResult{Created: 3}, res)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("positive: merge replaces existing secrets, skip-duplicates keeps them", func(t *testing.T) {
+		notesOnly := models.Vault{Version: models.VaultVersion, Notes: vault.Notes}
+		for mode, expected := range map[models.ImportMode]models.ImportResult{
+			models.ImportMerge:          {Updated: 1},
+			models.ImportSkipDuplicates: {Skipped: 1},
+		} {
+			mockDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(existsNote).WithArgs("davos", "letters").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			if mode == models.ImportMerge {
+				mock.ExpectQuery("select id, content, metadata, fields, revision from notes .+ for update").
+					WithArgs("davos", "letters").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec("update notes set content").
+					WithArgs(sqlmock.AnyArg(), nil, nil, sqlmock.AnyArg(), "davos", "letters").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			mock.ExpectCommit()
+
+			pg := Db{conn: mockDB, encryptionKey: key, dataCipher: c}
+			res, err := pg.ImportVault(ctx, models.VaultImport{UserName: "davos", Mode: mode, Vault: notesOnly})
+			assert.NoError(t, err, mode)
+			assert.Equal(t, expected, res, mode)
+			assert.NoError(t, mock.ExpectationsWereMet(), mode)
+			mockDB.Close()
+		}
+	})
+	t.Run("positive: merge updates existing TOTP secrets and SSH keys in place", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(existsKey).WithArgs("davos", "deploy").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectExec("update ssh_keys set private_key = \\$3, .+, updated_at = now\\(\\), revision = revision \\+ 1 where user_name = \\$1 and name = \\$2").
+			WithArgs("davos", "deploy", sqlmock.AnyArg(), nil, "ssh-ed25519 AAAA", "SHA256:x", "ssh-ed25519", nil).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(existsTOTP).WithArgs("davos", "mail").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectExec("update totp set uri = \\$3, credential_id = \\$4, metadata = \\$5, updated_at = now\\(\\), revision = revision \\+ 1 where user_name = \\$1 and name = \\$2").
+			WithArgs("davos", "mail", sqlmock.AnyArg(), nil, nil).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		pg := Db{conn: mockDB, encryptionKey: key, dataCipher: c}
+		res, err := pg.ImportVault(ctx, models.VaultImport{UserName: "davos", Mode: models.ImportMerge,
+			Vault: models.Vault{Version: models.VaultVersion, TOTP: vault.TOTP, SSHKeys: vault.SSHKeys}})
+		assert.NoError(t, err)
+		assert.Equal(t, models.ImportResult{Updated: 2}, res)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("negative: failure halfway rolls back the deletes and loaded secrets", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		expectReplace(mock)
+		mock.ExpectQuery(existsNote).WithArgs("davos", "letters").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery("insert into notes").
+			WithArgs("davos", "letters", sqlmock.AnyArg(), nil, nil, sqlmock.AnyArg(), nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+		mock.ExpectQuery(existsKey).WithArgs("davos", "deploy").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec("insert into ssh_keys").WillReturnError(errors.New("connection reset"))
+		mock.ExpectRollback()
+
+		pg := Db{conn: mockDB, encryptionKey: key, dataCipher: c}
+		res, err := pg.ImportVault(ctx, models.VaultImport{UserName: "davos", Mode: models.ImportReplace, Vault: vault})
+		assert.EqualError(t, err, `ошибка при загрузке SSH-ключа "deploy": ошибка при сохранении SSH-ключа для пользователя "davos": connection reset`)
+		assert.Equal(t, models.ImportResult{}, res)
+		// Транзакция откатывается без фиксации, поэтому удаленные секреты TOTP и SSH-ключи и секреты в корзине остаются на месте
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
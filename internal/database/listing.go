@@ -0,0 +1,232 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/lib/pq"
+)
+
+// auditColumns колонки со служебными сведениями о секрете
+const auditColumns = "created_at, updated_at, last_accessed_at, revision"
+
+// auditValues принимает значения колонок auditColumns при сканировании строк
+type auditValues struct {
+	createdAt, updatedAt, lastAccessedAt sql.NullTime
+	revision                             sql.NullInt64
+}
+
+// dest возвращает приемники для rows.Scan в порядке колонок auditColumns
+func (a *auditValues) dest() []interface{} {
+	return []interface{}{&a.createdAt, &a.updatedAt, &a.lastAccessedAt, &a.revision}
+}
+
+// audit преобразует считанные значения в служебные сведения о секрете
+func (a auditValues) audit() models.Audit {
+	var res models.Audit
+	if a.createdAt.Valid {
+		res.CreatedAt = &a.createdAt.Time
+	}
+	if a.updatedAt.Valid {
+		res.UpdatedAt = &a.updatedAt.Time
+	}
+	if a.lastAccessedAt.Valid {
+		res.LastAccessedAt = &a.lastAccessedAt.Time
+	}
+	res.Revision = a.revision.Int64
+	return res
+}
+
+// maxListLimit ограничивает размер одной страницы списка секретов
+const maxListLimit = 1000
+
+// pageCursor описывает позицию в списке секретов: значение поля сортировки и идентификатор последней записи страницы
+type pageCursor struct {
+	Sort  string `json:"s,omitempty"`
+	Desc  bool   `json:"d,omitempty"`
+	Value string `json:"v,omitempty"`
+	ID    int64  `json:"id"`
+}
+
+// encodeCursor кодирует курсор в непрозрачную строку
+func encodeCursor(c pageCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor разбирает курсор, полученный от клиента
+func decodeCursor(s string) (pageCursor, error) {
+	var c pageCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(b, &c)
+	}
+	if err != nil {
+		return pageCursor{}, fmt.Errorf("%w: некорректный курсор", ErrInvalidListOptions)
+	}
+	return c, nil
+}
+
+// rowKey содержит идентификатор записи и значение поля сортировки для построения курсора
+type rowKey struct {
+	id    int64
+	value string
+}
+
+// sortKey возвращает выражение для сортировки списка секретов
+func (s secretTable) sortKey(sort string) (string, error) {
+	switch sort {
+	case "":
+		return "id", nil
+	case models.SortName:
+		return s.nameColumn, nil
+	case models.SortCreated:
+		return "created_at", nil
+	case models.SortUpdated:
+		return "updated_at", nil
+	default:
+		return "", fmt.Errorf("%w: неизвестное поле сортировки %q", ErrInvalidListOptions, sort)
+	}
+}
+
+// keyColumns возвращает выражения для выборки идентификатора записи и значения поля сортировки
+func (s secretTable) keyColumns(sort string) (string, error) {
+	key, err := s.sortKey(sort)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("id, (%s)::text as sort_key", key), nil
+}
+
+// paginate дополняет условие запроса отбором записей после курсора, сортировкой и ограничением размера страницы.
+// Запрашивается на одну запись больше размера страницы, чтобы определить, есть ли следующая страница.
+func (s secretTable) paginate(args []interface{}, opts models.ListOptions) ([]interface{}, string, error) {
+	if opts.Limit < 0 || opts.Limit > maxListLimit {
+		return nil, "", fmt.Errorf("%w: размер страницы должен быть от 0 до %d", ErrInvalidListOptions, maxListLimit)
+	}
+	key, err := s.sortKey(opts.Sort)
+	if err != nil {
+		return nil, "", err
+	}
+	cmp, dir := ">", "asc"
+	if opts.Desc {
+		cmp, dir = "<", "desc"
+	}
+
+	var condition string
+	if opts.UnusedDays < 0 {
+		return nil, "", fmt.Errorf("%w: число дней без использования не может быть отрицательным", ErrInvalidListOptions)
+	}
+	if opts.UnusedDays > 0 {
+		args = append(args, opts.UnusedDays)
+		condition += fmt.Sprintf(" AND coalesce(last_accessed_at, created_at) < now() - make_interval(days => $%d)", len(args))
+	}
+	if opts.Cursor != "" {
+		c, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		if c.Sort != opts.Sort || c.Desc != opts.Desc {
+			return nil, "", fmt.Errorf("%w: курсор получен для другой сортировки", ErrInvalidListOptions)
+		}
+		if key == "id" {
+			args = append(args, c.ID)
+			condition += fmt.Sprintf(" AND id %s $%d", cmp, len(args))
+		} else {
+			args = append(args, c.Value, c.ID)
+			condition += fmt.Sprintf(" AND (%s, id) %s ($%d, $%d)", key, cmp, len(args)-1, len(args))
+		}
+	}
+	if key == "id" {
+		condition += " order by id " + dir
+	} else {
+		condition += fmt.Sprintf(" order by %[1]s %[2]s, id %[2]s", key, dir)
+	}
+	if opts.Limit > 0 {
+		args = append(args, opts.Limit+1)
+		condition += fmt.Sprintf(" limit $%d", len(args))
+	}
+	return args, condition, nil
+}
+
+// nextPage обрезает лишнюю запись, запрошенную paginate, и возвращает курсор следующей страницы.
+// Если следующей страницы нет, курсор пустой. Ключи обрезаются вместе с записями.
+func nextPage[T any](items []T, keys []rowKey, opts models.ListOptions) ([]T, []rowKey, string) {
+	if opts.Limit == 0 || len(items) <= opts.Limit {
+		return items, keys, ""
+	}
+	last := keys[opts.Limit-1]
+	return items[:opts.Limit], keys[:opts.Limit], encodeCursor(pageCursor{Sort: opts.Sort, Desc: opts.Desc, Value: last.value, ID: last.id})
+}
+
+// touch отмечает чтение секретных полей записей в last_accessed_at.
+// Если секретные поля не входили в проекцию, время последнего чтения не меняется.
+func (d *Db) touch(ctx context.Context, table secretTable, p projection, keys []rowKey) error {
+	read := false
+	for _, f := range table.secrets {
+		read = read || p.has(f)
+	}
+	if !read || len(keys) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(keys))
+	for _, k := range keys {
+		ids = append(ids, k.id)
+	}
+	return d.touchIDs(ctx, table.name, ids)
+}
+
+// touchIDs обновляет время последнего чтения записей таблицы с указанными идентификаторами
+func (d *Db) touchIDs(ctx context.Context, table string, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	touchQuery := fmt.Sprintf("update %s set last_accessed_at = now() where id = any($1)", table)
+	if _, err := d.conn.ExecContext(ctx, touchQuery, pq.Array(ids)); err != nil {
+		return fmt.Errorf("ошибка при обновлении времени последнего чтения: %w", err)
+	}
+	return nil
+}
+
+// projection определяет набор полей секрета, возвращаемых в ответе. Пустая проекция означает все поля.
+type projection map[string]bool
+
+// newProjection проверяет запрошенные поля по списку допустимых для таблицы
+func (s secretTable) newProjection(fields []string) (projection, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	p := make(projection, len(fields))
+	for _, f := range fields {
+		allowed := false
+		for _, a := range s.projectable {
+			if f == a {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return nil, fmt.Errorf("%w: поле %q нельзя запросить для %s", ErrInvalidListOptions, f, s.name)
+		}
+		p[f] = true
+	}
+	return p, nil
+}
+
+// has проверяет, что поле входит в проекцию
+func (p projection) has(field string) bool {
+	return p == nil || p[field]
+}
+
+// column возвращает колонку, если поле входит в проекцию, и NULL вместо нее в противном случае.
+// Так секретные колонки вне проекции не читаются из базы и не расшифровываются.
+func (p projection) column(field, column string) string {
+	if p.has(field) {
+		return column
+	}
+	return "null as " + column
+}
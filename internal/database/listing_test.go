@@ -0,0 +1,222 @@
+package database
+
+import (
+	"context"
+	"crypto/aes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretTable_paginate(t *testing.T) {
+	testCases := []struct {
+		name              string
+		opts              models.ListOptions
+		expectedArgs      []interface{}
+		expectedCondition string
+		expectedErr       error
+	}{
+		{
+			name:              "positive: default order",
+			expectedArgs:      []interface{}{"tyrion"},
+			expectedCondition: " order by id asc",
+		},
+		{
+			name:              "positive: first page by name",
+			opts:              models.ListOptions{Limit: 2, Sort: models.SortName},
+			expectedArgs:      []interface{}{"tyrion", 3},
+			expectedCondition: " order by title asc, id asc limit $2",
+		},
+		{
+			name: "positive: next page by updated desc",
+			opts: models.ListOptions{Limit: 2, Sort: models.SortUpdated, Desc: true,
+				Cursor: encodeCursor(pageCursor{Sort: models.SortUpdated, Desc: true, Value: "2024-01-02 10:00:00+00", ID: 7})},
+			expectedArgs:      []interface{}{"tyrion", "2024-01-02 10:00:00+00", int64(7), 3},
+			expectedCondition: " AND (updated_at, id) < ($2, $3) order by updated_at desc, id desc limit $4",
+		},
+		{
+			name:              "positive: next page by id",
+			opts:              models.ListOptions{Cursor: encodeCursor(pageCursor{ID: 7})},
+			expectedArgs:      []interface{}{"tyrion", int64(7)},
+			expectedCondition: " AND id > $2 order by id asc",
+		},
+		{
+			name:              "positive: not used for 30 days",
+			opts:              models.ListOptions{UnusedDays: 30, Sort: models.SortCreated},
+			expectedArgs:      []interface{}{"tyrion", 30},
+			expectedCondition: " AND coalesce(last_accessed_at, created_at) < now() - make_interval(days => $2) order by created_at asc, id asc",
+		},
+		{
+			name:        "negative: negative unused days",
+			opts:        models.ListOptions{UnusedDays: -1},
+			expectedErr: ErrInvalidListOptions,
+		},
+		{
+			name:        "negative: unknown sort",
+			opts:        models.ListOptions{Sort: "color"},
+			expectedErr: ErrInvalidListOptions,
+		},
+		{
+			name:        "negative: cursor of another sort",
+			opts:        models.ListOptions{Sort: models.SortName, Cursor: encodeCursor(pageCursor{ID: 7})},
+			expectedErr: ErrInvalidListOptions,
+		},
+		{
+			name:        "negative: broken cursor",
+			opts:        models.ListOptions{Cursor: "not a cursor"},
+			expectedErr: ErrInvalidListOptions,
+		},
+		{
+			name:        "negative: negative limit",
+			opts:        models.ListOptions{Limit: -1},
+			expectedErr: ErrInvalidListOptions,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			args, condition, err := notesTable.paginate([]interface{}{"tyrion"}, tt.opts)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedArgs, args)
+			assert.Equal(t, tt.expectedCondition, condition)
+		})
+	}
+}
+
+func TestSecretTable_newProjection(t *testing.T) {
+	p, err := cardsTable.newProjection([]string{"metadata", "tags"})
+	assert.NoError(t, err)
+	assert.True(t, p.has("metadata"))
+	assert.False(t, p.has("cv"))
+	assert.Equal(t, "null as cv", p.column("cv", "cv"))
+	assert.Equal(t, "metadata", p.column("metadata", "metadata"))
+
+	_, err = cardsTable.newProjection([]string{"content"})
+	assert.ErrorIs(t, err, ErrInvalidListOptions)
+
+	var all projection
+	assert.True(t, all.has("cv"))
+}
+
+func TestDb_GetNotesPage(t *testing.T) {
+	key := "thisis32bitlongpassphraseimusing"
+	c, _ := aes.NewCipher([]byte(key))
+	ctx := context.Background()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	mock.ExpectQuery("select user_name, title, null as content, metadata, null as fields, .+, id, \\(title\\)::text as sort_key from notes where user_name = \\$1 and deleted_at is null order by title asc, id asc limit \\$2").
+		WithArgs("tyrion", 3).
+		WillReturnRows(sqlmock.NewRows([]string{"user_name", "title", "content", "metadata", "fields", "folder", "tags", "created_at", "updated_at", "last_accessed_at", "revision", "id", "sort_key"}).
+			AddRow("tyrion", "casterly rock", nil, "gold", nil, "west", nil, nil, nil, nil, nil, 4, "casterly rock").
+			AddRow("tyrion", "meereen", nil, "dragons", nil, nil, nil, nil, nil, nil, nil, 2, "meereen").
+			AddRow("tyrion", "wine", nil, nil, nil, nil, nil, nil, nil, nil, nil, 9, "wine"))
+
+	pg := Db{
+		conn:          mockDB,
+		encryptionKey: key,
+		dataCipher:    c,
+	}
+	notes, next, err := pg.GetNotes(ctx, models.Note{UserName: "tyrion"},
+		models.ListOptions{Limit: 2, Sort: models.SortName, Projection: []string{"metadata"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Note{
+		{UserName: "tyrion", Title: Ptr("casterly rock"), Metadata: Ptr("gold"), Audit: auditID(4)},
+		{UserName: "tyrion", Title: Ptr("meereen"), Metadata: Ptr("dragons"), Audit: auditID(2)},
+	}, notes)
+	assert.Equal(t, encodeCursor(pageCursor{Sort: models.SortName, Value: "meereen", ID: 2}), next)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDb_GetCredentialsAudit(t *testing.T) {
+	key := "thisis32bitlongpassphraseimusing"
+	c, _ := aes.NewCipher([]byte(key))
+	ctx := context.Background()
+	created := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	updated := time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)
+	columns := []string{"user_name", "login", "password", "metadata", "site", "name", "urls", "fields", "folder", "tags",
+		"created_at", "updated_at", "last_accessed_at", "revision", "id", "sort_key"}
+
+	t.Run("positive: audit returned and read recorded", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectQuery("select user_name, login, password, .+, created_at, updated_at, last_accessed_at, revision, id, .+ from credentials").
+			WithArgs("cersei").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("cersei", "queen", "zR8XxOfadyU=", nil, "", nil, nil, nil, nil, nil, created, updated, nil, 3, 12, "12"))
+		mock.ExpectExec("update credentials set last_accessed_at = now\\(\\) where id = any\\(\\$1\\)").
+			WithArgs(pq.Array([]int64{12})).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		creds, _, err := pg.GetCredentials(ctx, models.Credentials{UserName: "cersei"}, models.ListOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, creds, 1)
+		id := int64(12)
+		assert.Equal(t, models.Audit{ID: &id, CreatedAt: &created, UpdatedAt: &updated, Revision: 3}, creds[0].Audit)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("positive: listing without secrets is not recorded", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectQuery("select user_name, login, null as password, .+ from credentials").
+			WithArgs("cersei").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("cersei", "queen", nil, nil, "", nil, nil, nil, nil, nil, created, updated, nil, 3, 12, "12"))
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		creds, _, err := pg.GetCredentials(ctx, models.Credentials{UserName: "cersei"}, models.ListOptions{Projection: []string{"metadata"}})
+		assert.NoError(t, err)
+		assert.Nil(t, creds[0].Password)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("negative: read not recorded", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectQuery("select user_name, login, password").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("cersei", "queen", "zR8XxOfadyU=", nil, "", nil, nil, nil, nil, nil, created, updated, nil, 3, 12, "12"))
+		mock.ExpectExec("update credentials set last_accessed_at").
+			WillReturnError(errors.New("read only transaction"))
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		_, _, err = pg.GetCredentials(ctx, models.Credentials{UserName: "cersei"}, models.ListOptions{})
+		assert.EqualError(t, err, "ошибка при обновлении времени последнего чтения: read only transaction")
+	})
+}
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ZnNr/GopherVault/internal/blindindex"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/lib/pq"
+)
+
+// noteSearchTokens строит слепой индекс слов содержимого заметки для сохранения в колонке search_tokens
+func (d *Db) noteSearchTokens(userName, content string) interface{} {
+	return pq.Array(blindindex.Tokens(blindindex.DeriveKey(d.encryptionKey), userName, content))
+}
+
+// SearchNotes находит заметки пользователя, в содержимом которых встречаются все слова запроса.
+// Поиск выполняется по слепому индексу: сервер сравнивает отпечатки слов и не хранит содержимое в открытом виде.
+func (d *Db) SearchNotes(ctx context.Context, searchRequest models.SearchRequest) ([]models.Note, error) {
+	keyColumns, _ := notesTable.keyColumns("")
+	searchNotesQuery := "select user_name, title, content, metadata, fields, " + notesTable.folderAndTagsColumns() + ", " + auditColumns + ", " + keyColumns +
+		" from notes where user_name = $1 and deleted_at is null and search_tokens @> $2 order by id"
+	rows, err := d.conn.QueryContext(ctx, searchNotesQuery, searchRequest.UserName, d.noteSearchTokens(searchRequest.UserName, searchRequest.Query))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при поиске заметок для пользователя %q: %w", searchRequest.UserName, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	notes, keys, err := d.scanNotes(rows, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(notes) == 0 {
+		return nil, ErrNoData
+	}
+	if err = d.touch(ctx, notesTable, nil, keys); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// reindexBatchSize число заметок, индексируемых за один запрос
+const reindexBatchSize = 500
+
+// ReindexNotes строит поисковый индекс заметок, сохраненных до его появления: расшифровывает содержимое заметок
+// с пустой колонкой search_tokens, в том числе находящихся в корзине, и сохраняет отпечатки их слов.
+// Возвращает число проиндексированных заметок.
+func (d *Db) ReindexNotes(ctx context.Context) (int, error) {
+	var (
+		lastID  int64
+		indexed int
+	)
+	for {
+		batch, err := d.notesWithoutTokens(ctx, lastID)
+		if err != nil {
+			return indexed, err
+		}
+		for _, n := range batch {
+			content, err := d.decryptAES(n.content)
+			if err != nil {
+				return indexed, fmt.Errorf("ошибка при расшифровке контента заметки %d: %w", n.id, err)
+			}
+			reindexQuery := "update notes set search_tokens = $1 where id = $2 and search_tokens is null"
+			if _, err = d.conn.ExecContext(ctx, reindexQuery, d.noteSearchTokens(n.userName, content), n.id); err != nil {
+				return indexed, fmt.Errorf("ошибка при индексировании заметки %d: %w", n.id, err)
+			}
+			indexed++
+			lastID = n.id
+		}
+		if len(batch) < reindexBatchSize {
+			return indexed, nil
+		}
+	}
+}
+
+// unindexedNote заметка без поискового индекса
+type unindexedNote struct {
+	id       int64
+	userName string
+	content  string
+}
+
+// notesWithoutTokens возвращает очередную страницу заметок без поискового индекса с идентификаторами больше afterID
+func (d *Db) notesWithoutTokens(ctx context.Context, afterID int64) ([]unindexedNote, error) {
+	query := "select id, user_name, content from notes where search_tokens is null and id > $1 order by id limit $2"
+	rows, err := d.conn.QueryContext(ctx, query, afterID, reindexBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при поиске заметок без поискового индекса: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var notes []unindexedNote
+	for rows.Next() {
+		var n unindexedNote
+		if err = rows.Scan(&n.id, &n.userName, &n.content); err != nil {
+			return nil, err
+		}
+		notes = append(notes, n)
+	}
+	return notes, rows.Err()
+}
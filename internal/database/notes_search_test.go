@@ -0,0 +1,160 @@
+package database
+
+import (
+	"context"
+	"crypto/aes"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDb_SearchNotes(t *testing.T) {
+	key := "thisis32bitlongpassphraseimusing"
+	c, _ := aes.NewCipher([]byte(key))
+	ctx := context.Background()
+	request := models.SearchRequest{UserName: "sam", Query: "Dragonglass, DAGGER"}
+
+	t.Run("positive: notes found by words", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		encryptedContent, _ := pg.encryptAES("a dagger made of dragonglass")
+		mock.ExpectQuery("select user_name, title, content, metadata, fields, .+ from notes where user_name = \\$1 and deleted_at is null and search_tokens @> \\$2").
+			WithArgs("sam", pg.noteSearchTokens("sam", "dagger dragonglass")).
+			WillReturnRows(sqlmock.NewRows([]string{"user_name", "title", "content", "metadata", "fields", "folder", "tags", "created_at", "updated_at", "last_accessed_at", "revision", "id", "sort_key"}).
+				AddRow("sam", "white walkers", encryptedContent, nil, nil, nil, nil, nil, nil, nil, nil, 1, "1"))
+		mock.ExpectExec("update notes set last_accessed_at").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		notes, err := pg.SearchNotes(ctx, request)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Note{{UserName: "sam", Title: Ptr("white walkers"), Content: Ptr("a dagger made of dragonglass"), Audit: auditID(1)}}, notes)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("negative: no notes", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectQuery("select user_name, title, content, metadata, fields, .+ from notes where user_name = \\$1 and deleted_at is null and search_tokens @> \\$2").
+			WillReturnRows(sqlmock.NewRows([]string{"user_name", "title", "content", "metadata", "fields", "folder", "tags", "created_at", "updated_at", "last_accessed_at", "revision", "id", "sort_key"}))
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		_, err = pg.SearchNotes(ctx, request)
+		assert.ErrorIs(t, err, ErrNoData)
+	})
+	t.Run("negative: query error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectQuery("select user_name, title, content").
+			WillReturnError(errors.New("connection lost"))
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		_, err = pg.SearchNotes(ctx, request)
+		assert.EqualError(t, err, "ошибка при поиске заметок для пользователя \"sam\": connection lost")
+	})
+}
+
+func TestDb_ReindexNotes(t *testing.T) {
+	key := "thisis32bitlongpassphraseimusing"
+	c, _ := aes.NewCipher([]byte(key))
+	ctx := context.Background()
+
+	t.Run("positive: notes without tokens are indexed", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		first, _ := pg.encryptAES("winter is coming")
+		second, _ := pg.encryptAES("a lannister always pays his debts")
+		mock.ExpectQuery("select id, user_name, content from notes where search_tokens is null and id > \\$1 order by id limit \\$2").
+			WithArgs(0, reindexBatchSize).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_name", "content"}).
+				AddRow(3, "ned", first).
+				AddRow(7, "tyrion", second))
+		mock.ExpectExec("update notes set search_tokens = \\$1 where id = \\$2 and search_tokens is null").
+			WithArgs(pg.noteSearchTokens("ned", "winter is coming"), 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("update notes set search_tokens = \\$1 where id = \\$2 and search_tokens is null").
+			WithArgs(pg.noteSearchTokens("tyrion", "a lannister always pays his debts"), 7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		indexed, err := pg.ReindexNotes(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, indexed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("positive: nothing to index", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectQuery("select id, user_name, content from notes where search_tokens is null").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_name", "content"}))
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		indexed, err := pg.ReindexNotes(ctx)
+		assert.NoError(t, err)
+		assert.Zero(t, indexed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("negative: update error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		content, _ := pg.encryptAES("winter is coming")
+		mock.ExpectQuery("select id, user_name, content from notes where search_tokens is null").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_name", "content"}).AddRow(3, "ned", content))
+		mock.ExpectExec("update notes set search_tokens").
+			WillReturnError(errors.New("connection lost"))
+
+		indexed, err := pg.ReindexNotes(ctx)
+		assert.EqualError(t, err, "ошибка при индексировании заметки 3: connection lost")
+		assert.Zero(t, indexed)
+	})
+}
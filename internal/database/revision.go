@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// checkRevision сравнивает ревизию, ожидаемую клиентом, с текущей ревизией секрета.
+// Нулевая ожидаемая ревизия означает, что клиент не проверяет ревизию.
+func checkRevision(expected, current int64) error {
+	if expected != 0 && expected != current {
+		return ErrRevisionMismatch
+	}
+	return nil
+}
+
+// revisionCondition дополняет условие удаления проверкой ревизии, ожидаемой клиентом
+func revisionCondition(args []interface{}, expected int64) ([]interface{}, string) {
+	if expected == 0 {
+		return args, ""
+	}
+	args = append(args, expected)
+	return args, fmt.Sprintf(" AND revision = $%d", len(args))
+}
+
+// checkDeletedRevision проверяет результат удаления с проверкой ревизии. Если ни один секрет не был удален,
+// повторяет отбор без условия ревизии: секрета, которого нет или который уже в корзине, соответствует ErrNoData,
+// а секрету с другой ревизией - ErrRevisionMismatch.
+func (d *Db) checkDeletedRevision(ctx context.Context, res sql.Result, table, where string, args []interface{}, expected int64) error {
+	if expected == 0 {
+		return nil
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+	var exists bool
+	existsQuery := fmt.Sprintf("select exists (select 1 from %s where %s)", table, where)
+	if err = d.conn.QueryRowContext(ctx, existsQuery, args...).Scan(&exists); err != nil {
+		return fmt.Errorf("ошибка при проверке ревизии удаляемого секрета: %w", err)
+	}
+	if !exists {
+		return ErrNoData
+	}
+	return ErrRevisionMismatch
+}
+
+// checkUpdated возвращает ErrNoData, если изменяемого секрета нет: он удален или переименован другим клиентом
+func checkUpdated(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNoData
+	}
+	return nil
+}
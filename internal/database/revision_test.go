@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"crypto/aes"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDb_UpdateNoteRevision(t *testing.T) {
+	key := "thisis32bitlongpassphraseimusing"
+	c, _ := aes.NewCipher([]byte(key))
+	ctx := context.Background()
+	note := models.Note{
+		UserName: "davos",
+		Title:    Ptr("onions"),
+		Content:  Ptr("some clever things"),
+		Audit:    models.Audit{Revision: 2},
+	}
+	columns := []string{"id", "content", "metadata", "fields", "revision"}
+
+	t.Run("negative: stale revision", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("select id, content, .+ from notes .+ for update").
+			WithArgs(note.UserName, *note.Title).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(4, "zwcf07PPKWGQpOBElPSmsjQ=", nil, nil, 3))
+		mock.ExpectRollback()
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		assert.ErrorIs(t, pg.UpdateNote(ctx, note), ErrRevisionMismatch)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("negative: note is gone", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("select id, content, .+ from notes .+ for update").
+			WithArgs(note.UserName, *note.Title).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		assert.ErrorIs(t, pg.UpdateNote(ctx, note), ErrRevisionMismatch)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDb_DeleteNotesRevision(t *testing.T) {
+	key := "thisis32bitlongpassphraseimusing"
+	c, _ := aes.NewCipher([]byte(key))
+	ctx := context.Background()
+	note := models.Note{UserName: "davos", Title: Ptr("onions"), Audit: models.Audit{Revision: 2}}
+
+	testCases := []struct {
+		name        string
+		affected    int64
+		exists      bool
+		expectedErr error
+	}{
+		{name: "positive: revision matches", affected: 1},
+		{name: "negative: stale revision", affected: 0, exists: true, expectedErr: ErrRevisionMismatch},
+		{name: "negative: note is gone", affected: 0, exists: false, expectedErr: ErrNoData},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer mockDB.Close()
+
+			mock.ExpectExec("update notes set deleted_at = now\\(\\) where user_name = \\$1 and deleted_at is null AND title = \\$2 AND revision = \\$3").
+				WithArgs(note.UserName, *note.Title, 2).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			if tt.affected == 0 {
+				mock.ExpectQuery("select exists \\(select 1 from notes where user_name = \\$1 and deleted_at is null AND title = \\$2\\)").
+					WithArgs(note.UserName, *note.Title).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tt.exists))
+			}
+
+			pg := Db{
+				conn:          mockDB,
+				encryptionKey: key,
+				dataCipher:    c,
+			}
+			err = pg.DeleteNotes(ctx, note)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// GetSearchItems получает описания секретов пользователя, по которым выполняется поиск.
+// Выбираются только открытые колонки: пароли, CV и содержимое заметок не читаются и не расшифровываются.
+// Если типы секретов не указаны, выбираются секреты всех типов.
+func (d *Db) GetSearchItems(ctx context.Context, searchRequest models.SearchRequest) ([]models.SearchResult, error) {
+	types := searchRequest.Types
+	if len(types) == 0 {
+		types = []models.SecretType{models.SecretCredentials, models.SecretNote, models.SecretCard}
+	}
+
+	var items []models.SearchResult
+	for _, secretType := range types {
+		var (
+			found []models.SearchResult
+			err   error
+		)
+		switch secretType {
+		case models.SecretCredentials:
+			found, err = d.searchCredentials(ctx, searchRequest.UserName)
+		case models.SecretNote:
+			found, err = d.searchNotes(ctx, searchRequest.UserName)
+		case models.SecretCard:
+			found, err = d.searchCards(ctx, searchRequest.UserName)
+		default:
+			return nil, fmt.Errorf("неизвестный тип секрета %q", secretType)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при поиске секретов для пользователя %q: %w", searchRequest.UserName, err)
+		}
+		items = append(items, found...)
+	}
+	if len(items) == 0 {
+		return nil, ErrNoData
+	}
+	return items, nil
+}
+
+func (d *Db) searchCredentials(ctx context.Context, userName string) ([]models.SearchResult, error) {
+	query := "select login, site, name, urls, metadata, " + credentialsTable.folderAndTagsColumns() + " from credentials where user_name = $1 and deleted_at is null"
+	rows, err := d.conn.QueryContext(ctx, query, userName)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var items []models.SearchResult
+	for rows.Next() {
+		var login, site string
+		var name, urls, metadata, folder, tags sql.NullString
+		if err = rows.Scan(&login, &site, &name, &urls, &metadata, &folder, &tags); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании учетных данных: %w", err)
+		}
+		res := models.SearchResult{Type: models.SecretCredentials, Title: login, Login: &login}
+		if name.Valid && name.String != "" {
+			res.Title = name.String
+		}
+		if site != "" {
+			res.Site = &site
+		}
+		if urls.Valid {
+			var credentialURLs []models.CredentialURL
+			if err = json.Unmarshal([]byte(urls.String), &credentialURLs); err != nil {
+				return nil, fmt.Errorf("error while decoding credentials urls: %w", err)
+			}
+			for _, u := range credentialURLs {
+				res.URLs = append(res.URLs, u.URL)
+			}
+		}
+		if metadata.Valid {
+			res.Metadata = &metadata.String
+		}
+		if res.Folder, res.Tags, err = scanFolderAndTags(folder, tags); err != nil {
+			return nil, err
+		}
+		items = append(items, res)
+	}
+	return items, rows.Err()
+}
+
+func (d *Db) searchNotes(ctx context.Context, userName string) ([]models.SearchResult, error) {
+	query := "select title, metadata, " + notesTable.folderAndTagsColumns() + " from notes where user_name = $1 and deleted_at is null"
+	rows, err := d.conn.QueryContext(ctx, query, userName)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var items []models.SearchResult
+	for rows.Next() {
+		var title string
+		var metadata, folder, tags sql.NullString
+		if err = rows.Scan(&title, &metadata, &folder, &tags); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании заметок: %w", err)
+		}
+		res := models.SearchResult{Type: models.SecretNote, Title: title}
+		if metadata.Valid {
+			res.Metadata = &metadata.String
+		}
+		if res.Folder, res.Tags, err = scanFolderAndTags(folder, tags); err != nil {
+			return nil, err
+		}
+		items = append(items, res)
+	}
+	return items, rows.Err()
+}
+
+func (d *Db) searchCards(ctx context.Context, userName string) ([]models.SearchResult, error) {
+	query := "select bank_name, number, metadata, " + cardsTable.folderAndTagsColumns() + " from cards where user_name = $1 and deleted_at is null"
+	rows, err := d.conn.QueryContext(ctx, query, userName)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var items []models.SearchResult
+	for rows.Next() {
+		var bankName, number string
+		var metadata, folder, tags sql.NullString
+		if err = rows.Scan(&bankName, &number, &metadata, &folder, &tags); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании карт: %w", err)
+		}
+		masked := maskCardNumber(number)
+		res := models.SearchResult{Type: models.SecretCard, Title: bankName, Number: &masked}
+		if metadata.Valid {
+			res.Metadata = &metadata.String
+		}
+		if res.Folder, res.Tags, err = scanFolderAndTags(folder, tags); err != nil {
+			return nil, err
+		}
+		items = append(items, res)
+	}
+	return items, rows.Err()
+}
+
+// maskCardNumber скрывает все цифры номера карты, кроме последних четырех
+func maskCardNumber(number string) string {
+	digits := strings.ReplaceAll(number, " ", "")
+	if len(digits) <= 4 {
+		return digits
+	}
+	return strings.Repeat("*", len(digits)-4) + digits[len(digits)-4:]
+}
@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDb_GetSearchItems(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("positive: all types without secret columns", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectQuery("select login, site, name, urls, metadata, .+ from credentials where user_name = \\$1").
+			WithArgs("arya").
+			WillReturnRows(sqlmock.NewRows([]string{"login", "site", "name", "urls", "metadata", "folder", "tags"}).
+				AddRow("no one", "braavos.com", "House of Black and White", `[{"url":"https://braavos.com/login"}]`, nil, "east", `["faceless"]`))
+		mock.ExpectQuery("select title, metadata, .+ from notes where user_name = \\$1").
+			WithArgs("arya").
+			WillReturnRows(sqlmock.NewRows([]string{"title", "metadata", "folder", "tags"}).
+				AddRow("list", "names", nil, nil))
+		mock.ExpectQuery("select bank_name, number, metadata, .+ from cards where user_name = \\$1").
+			WithArgs("arya").
+			WillReturnRows(sqlmock.NewRows([]string{"bank_name", "number", "metadata", "folder", "tags"}).
+				AddRow("Iron Bank", "1234 5678 9012 3456", nil, nil, nil))
+
+		pg := Db{
+			conn: mockDB,
+		}
+		items, err := pg.GetSearchItems(ctx, models.SearchRequest{UserName: "arya", Query: "braavos"})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.SearchResult{
+			{
+				Type:   models.SecretCredentials,
+				Title:  "House of Black and White",
+				Login:  Ptr("no one"),
+				Site:   Ptr("braavos.com"),
+				URLs:   []string{"https://braavos.com/login"},
+				Folder: Ptr("east"),
+				Tags:   []string{"faceless"},
+			},
+			{Type: models.SecretNote, Title: "list", Metadata: Ptr("names")},
+			{Type: models.SecretCard, Title: "Iron Bank", Number: Ptr("************3456")},
+		}, items)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("positive: only requested types", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectQuery("select title, metadata, .+ from notes where user_name = \\$1").
+			WithArgs("arya").
+			WillReturnRows(sqlmock.NewRows([]string{"title", "metadata", "folder", "tags"}).
+				AddRow("list", nil, nil, nil))
+
+		pg := Db{
+			conn: mockDB,
+		}
+		items, err := pg.GetSearchItems(ctx, models.SearchRequest{UserName: "arya", Query: "list", Types: []models.SecretType{models.SecretNote}})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.SearchResult{{Type: models.SecretNote, Title: "list"}}, items)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("negative: no data", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectQuery("select bank_name, number, metadata, .+ from cards where user_name = \\$1").
+			WithArgs("arya").
+			WillReturnRows(sqlmock.NewRows([]string{"bank_name", "number", "metadata", "folder", "tags"}))
+
+		pg := Db{
+			conn: mockDB,
+		}
+		_, err = pg.GetSearchItems(ctx, models.SearchRequest{UserName: "arya", Query: "bank", Types: []models.SecretType{models.SecretCard}})
+		assert.ErrorIs(t, err, ErrNoData)
+	})
+	t.Run("negative: query error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectQuery("select login, site, name, urls, metadata, .+ from credentials where user_name = \\$1").
+			WithArgs("arya").
+			WillReturnError(errors.New("connection lost"))
+
+		pg := Db{
+			conn: mockDB,
+		}
+		_, err = pg.GetSearchItems(ctx, models.SearchRequest{UserName: "arya", Query: "x", Types: []models.SecretType{models.SecretCredentials}})
+		assert.Error(t, err)
+	})
+}
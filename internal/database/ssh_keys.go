@@ -0,0 +1,145 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// SaveSSHKey сохраняет SSH-ключ в базе данных. Закрытый ключ и парольная фраза хранятся в зашифрованном виде.
+func (d *Db) SaveSSHKey(ctx context.Context, keyRequest models.SSHKey) error {
+	return d.insertSSHKey(ctx, d.conn, keyRequest)
+}
+
+// insertSSHKey сохраняет SSH-ключ в подключении к базе данных или в транзакции q
+func (d *Db) insertSSHKey(ctx context.Context, q queryer, keyRequest models.SSHKey) error {
+	encryptedKey, encryptedPassphrase, err := d.encryptSSHKey(keyRequest)
+	if err != nil {
+		return err
+	}
+
+	saveKeyQuery := "insert into ssh_keys (user_name, name, private_key, passphrase, public_key, fingerprint, key_type, metadata) values ($1, $2, $3, $4, $5, $6, $7, $8)"
+	if _, err = q.ExecContext(ctx, saveKeyQuery, keyRequest.UserName, *keyRequest.Name, encryptedKey, encryptedPassphrase,
+		*keyRequest.PublicKey, *keyRequest.Fingerprint, *keyRequest.KeyType, keyRequest.Metadata); err != nil {
+		if conflictErr := asConflictError(err); conflictErr != nil {
+			err = conflictErr
+		}
+		return fmt.Errorf("ошибка при сохранении SSH-ключа для пользователя %q: %w", keyRequest.UserName, err)
+	}
+	return nil
+}
+
+// replaceSSHKey заменяет содержимое SSH-ключа с тем же названием, увеличивая номер ревизии
+func (d *Db) replaceSSHKey(ctx context.Context, q queryer, keyRequest models.SSHKey) error {
+	encryptedKey, encryptedPassphrase, err := d.encryptSSHKey(keyRequest)
+	if err != nil {
+		return err
+	}
+
+	updateKeyQuery := "update ssh_keys set private_key = $3, passphrase = $4, public_key = $5, fingerprint = $6, key_type = $7, metadata = $8, " +
+		"updated_at = now(), revision = revision + 1 where user_name = $1 and name = $2"
+	res, err := q.ExecContext(ctx, updateKeyQuery, keyRequest.UserName, *keyRequest.Name, encryptedKey, encryptedPassphrase,
+		*keyRequest.PublicKey, *keyRequest.Fingerprint, *keyRequest.KeyType, keyRequest.Metadata)
+	if err == nil {
+		err = checkUpdated(res)
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении SSH-ключа для пользователя %q: %w", keyRequest.UserName, err)
+	}
+	return nil
+}
+
+// encryptSSHKey шифрует закрытый ключ и парольную фразу SSH-ключа
+func (d *Db) encryptSSHKey(keyRequest models.SSHKey) (string, sql.NullString, error) {
+	var encryptedPassphrase sql.NullString
+	encryptedKey, err := d.encryptAES(*keyRequest.PrivateKey)
+	if err != nil {
+		return "", encryptedPassphrase, fmt.Errorf("ошибка при шифровании SSH-ключа: %w", err)
+	}
+	if keyRequest.Passphrase != nil && *keyRequest.Passphrase != "" {
+		encryptedPassphrase.String, err = d.encryptAES(*keyRequest.Passphrase)
+		if err != nil {
+			return "", encryptedPassphrase, fmt.Errorf("ошибка при шифровании парольной фразы SSH-ключа: %w", err)
+		}
+		encryptedPassphrase.Valid = true
+	}
+	return encryptedKey, encryptedPassphrase, nil
+}
+
+// GetSSHKeys получает SSH-ключи пользователя из базы данных.
+func (d *Db) GetSSHKeys(ctx context.Context, keyRequest models.SSHKey) ([]models.SSHKey, error) {
+	args := []interface{}{keyRequest.UserName}
+	getKeysQuery := "select id, user_name, name, private_key, passphrase, public_key, fingerprint, key_type, metadata, " + auditColumns + " from ssh_keys where user_name = $1"
+	if keyRequest.Name != nil {
+		args = append(args, *keyRequest.Name)
+		getKeysQuery += fmt.Sprintf(" AND name = $%d", len(args))
+	}
+	rows, err := d.conn.QueryContext(ctx, getKeysQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении SSH-ключей для пользователя %q: %w", keyRequest.UserName, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var keys []models.SSHKey
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		var userName, name, privateKey, publicKey, fingerprint, keyType string
+		var passphrase, metadata sql.NullString
+		var audit auditValues
+		if err = rows.Scan(append([]interface{}{&id, &userName, &name, &privateKey, &passphrase, &publicKey, &fingerprint, &keyType, &metadata}, audit.dest()...)...); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании строк после запроса на получение SSH-ключей: %w", err)
+		}
+		decryptedKey, err := d.decryptAES(privateKey)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при расшифровке SSH-ключа: %w", err)
+		}
+		res := models.SSHKey{
+			UserName:    userName,
+			Name:        &name,
+			PrivateKey:  &decryptedKey,
+			PublicKey:   &publicKey,
+			Fingerprint: &fingerprint,
+			KeyType:     &keyType,
+			Audit:       audit.audit(),
+		}
+		res.ID = &id
+		if passphrase.Valid {
+			decryptedPassphrase, err := d.decryptAES(passphrase.String)
+			if err != nil {
+				return nil, fmt.Errorf("ошибка при расшифровке парольной фразы SSH-ключа: %w", err)
+			}
+			res.Passphrase = &decryptedPassphrase
+		}
+		if metadata.Valid {
+			res.Metadata = &metadata.String
+		}
+		keys = append(keys, res)
+		ids = append(ids, id)
+	}
+	if len(keys) == 0 {
+		return nil, ErrNoData
+	}
+	if err = d.touchIDs(ctx, "ssh_keys", ids); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// DeleteSSHKeys удаляет SSH-ключи пользователя из базы данных.
+func (d *Db) DeleteSSHKeys(ctx context.Context, keyRequest models.SSHKey) error {
+	args := []interface{}{keyRequest.UserName}
+	deleteKeysQuery := "delete from ssh_keys where user_name = $1"
+	if keyRequest.Name != nil {
+		args = append(args, *keyRequest.Name)
+		deleteKeysQuery += fmt.Sprintf(" AND name = $%d", len(args))
+	}
+	if _, err := d.conn.ExecContext(ctx, deleteKeysQuery, args...); err != nil {
+		return fmt.Errorf("ошибка при удалении SSH-ключей для пользователя %q: %w", keyRequest.UserName, err)
+	}
+	return nil
+}
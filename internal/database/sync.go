@@ -0,0 +1,198 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/lib/pq"
+)
+
+// GetChanges возвращает изменения учетных данных, заметок и карт пользователя с номерами больше syncRequest.Since.
+// Для каждого секрета возвращается только его последнее изменение вместе с текущим значением,
+// а для удаленных секретов - только отметка об удалении.
+// Если клиент передал номер больше последнего номера журнала, возвращаются все секреты и признак сброса.
+// Журнал ведется триггерами таблиц секретов, поэтому изменения попадают в него при любом способе изменения.
+func (d *Db) GetChanges(ctx context.Context, syncRequest models.SyncRequest) (models.SyncResponse, error) {
+	// Номер журнала и изменения читаются из одного снимка базы данных
+	tx, err := d.conn.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return models.SyncResponse{}, fmt.Errorf("ошибка при получении изменений для пользователя %q: %w", syncRequest.UserName, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var res models.SyncResponse
+	seqQuery := "select seq from sync_sequences where user_name = $1"
+	if err = tx.QueryRowContext(ctx, seqQuery, syncRequest.UserName).Scan(&res.Seq); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return models.SyncResponse{}, fmt.Errorf("ошибка при получении номера журнала для пользователя %q: %w", syncRequest.UserName, err)
+	}
+	since := syncRequest.Since
+	if since > res.Seq {
+		res.Reset, since = true, 0
+	}
+
+	changesQuery := "select distinct on (secret_type, secret_id) seq, secret_type, secret_id, action, revision, changed_at from change_log " +
+		"where user_name = $1 and seq > $2 order by secret_type, secret_id, seq desc"
+	rows, err := tx.QueryContext(ctx, changesQuery, syncRequest.UserName, since)
+	if err != nil {
+		return models.SyncResponse{}, fmt.Errorf("ошибка при получении изменений для пользователя %q: %w", syncRequest.UserName, err)
+	}
+	res.Changes = []models.Change{}
+	for rows.Next() {
+		var change models.Change
+		if err = rows.Scan(&change.Seq, &change.Type, &change.SecretID, &change.Action, &change.Revision, &change.ChangedAt); err != nil {
+			_ = rows.Close()
+			return models.SyncResponse{}, fmt.Errorf("ошибка при сканировании изменений: %w", err)
+		}
+		res.Changes = append(res.Changes, change)
+	}
+	if err = rows.Close(); err != nil {
+		return models.SyncResponse{}, err
+	}
+	if err = rows.Err(); err != nil {
+		return models.SyncResponse{}, err
+	}
+	sort.Slice(res.Changes, func(i, j int) bool { return res.Changes[i].Seq < res.Changes[j].Seq })
+
+	if err = d.fillChanges(ctx, tx, syncRequest.UserName, res.Changes); err != nil {
+		return models.SyncResponse{}, err
+	}
+	return res, nil
+}
+
+// fillChanges дополняет изменения текущими значениями созданных и измененных секретов.
+// Время последнего чтения при синхронизации не обновляется.
+func (d *Db) fillChanges(ctx context.Context, tx *sql.Tx, userName string, changes []models.Change) error {
+	ids := make(map[models.SecretType][]int64)
+	for _, change := range changes {
+		if change.Action != models.ChangeDeleted {
+			ids[change.Type] = append(ids[change.Type], change.SecretID)
+		}
+	}
+
+	var (
+		credentials = make(map[int64]models.Credentials)
+		notes       = make(map[int64]models.Note)
+		cards       = make(map[int64]models.Card)
+	)
+	for _, table := range []secretTable{credentialsTable, notesTable, cardsTable} {
+		if len(ids[table.secretType]) == 0 {
+			continue
+		}
+		rows, err := tx.QueryContext(ctx, table.changedQuery(), userName, pq.Array(ids[table.secretType]))
+		if err != nil {
+			return fmt.Errorf("ошибка при получении измененных секретов для пользователя %q: %w", userName, err)
+		}
+		switch table.secretType {
+		case models.SecretCredentials:
+			items, _, err := d.scanCredentials(rows, nil)
+			for _, item := range items {
+				credentials[*item.ID] = item
+			}
+			_ = rows.Close()
+			if err != nil {
+				return err
+			}
+		case models.SecretNote:
+			items, _, err := d.scanNotes(rows, nil)
+			for _, item := range items {
+				notes[*item.ID] = item
+			}
+			_ = rows.Close()
+			if err != nil {
+				return err
+			}
+		case models.SecretCard:
+			items, _, err := d.scanCards(rows, nil)
+			for _, item := range items {
+				cards[*item.ID] = item
+			}
+			_ = rows.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := range changes {
+		if changes[i].Action == models.ChangeDeleted {
+			continue
+		}
+		switch changes[i].Type {
+		case models.SecretCredentials:
+			if item, ok := credentials[changes[i].SecretID]; ok {
+				changes[i].Credentials = &item
+			}
+		case models.SecretNote:
+			if item, ok := notes[changes[i].SecretID]; ok {
+				changes[i].Note = &item
+			}
+		case models.SecretCard:
+			if item, ok := cards[changes[i].SecretID]; ok {
+				changes[i].Card = &item
+			}
+		}
+	}
+	return nil
+}
+
+// changedQuery возвращает запрос всех полей неудаленных секретов пользователя по списку идентификаторов
+func (s secretTable) changedQuery() string {
+	var columns string
+	switch s.secretType {
+	case models.SecretCredentials:
+		columns = "user_name, login, password, metadata, site, name, urls, fields"
+	case models.SecretNote:
+		columns = "user_name, title, content, metadata, fields"
+	case models.SecretCard:
+		columns = "user_name, bank_name, number, cv, password, card_type, metadata, fields"
+	}
+	return fmt.Sprintf("select %s, %s, %s, id, id::text as sort_key from %s where user_name = $1 and id = any($2) and deleted_at is null",
+		columns, s.folderAndTagsColumns(), auditColumns, s.name)
+}
+
+// eventsBatchSize максимальное число событий, возвращаемых за один запрос
+const eventsBatchSize = 100
+
+// GetEvents возвращает события из журнала изменений пользователя с номерами больше eventsRequest.After.
+// События содержат только тип, идентификатор и ревизию секрета, значения секретов не читаются.
+// Если номер не передан, возвращается только номер последнего изменения, с которого начинается поток событий.
+func (d *Db) GetEvents(ctx context.Context, eventsRequest models.EventsRequest) (models.EventsResponse, error) {
+	var res models.EventsResponse
+	seqQuery := "select seq from sync_sequences where user_name = $1"
+	if err := d.conn.QueryRowContext(ctx, seqQuery, eventsRequest.UserName).Scan(&res.Seq); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return models.EventsResponse{}, fmt.Errorf("ошибка при получении номера журнала для пользователя %q: %w", eventsRequest.UserName, err)
+	}
+	res.Events = []models.Event{}
+	if eventsRequest.After == nil {
+		return res, nil
+	}
+	if *eventsRequest.After > res.Seq {
+		res.Reset = true
+		return res, nil
+	}
+
+	eventsQuery := "select seq, secret_type, secret_id, action, revision, changed_at from change_log " +
+		"where user_name = $1 and seq > $2 order by seq limit $3"
+	rows, err := d.conn.QueryContext(ctx, eventsQuery, eventsRequest.UserName, *eventsRequest.After, eventsBatchSize)
+	if err != nil {
+		return models.EventsResponse{}, fmt.Errorf("ошибка при получении событий для пользователя %q: %w", eventsRequest.UserName, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var event models.Event
+		if err = rows.Scan(&event.Seq, &event.Type, &event.SecretID, &event.Action, &event.Revision, &event.ChangedAt); err != nil {
+			return models.EventsResponse{}, fmt.Errorf("ошибка при сканировании событий: %w", err)
+		}
+		res.Events = append(res.Events, event)
+	}
+	if err = rows.Err(); err != nil {
+		return models.EventsResponse{}, err
+	}
+	return res, nil
+}
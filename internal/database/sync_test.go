@@ -0,0 +1,160 @@
+package database
+
+import (
+	"context"
+	"crypto/aes"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDb_GetChanges(t *testing.T) {
+	key := "thisis32bitlongpassphraseimusing"
+	c, _ := aes.NewCipher([]byte(key))
+	ctx := context.Background()
+	changedAt := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	changeColumns := []string{"seq", "secret_type", "secret_id", "action", "revision", "changed_at"}
+	noteColumns := []string{"user_name", "title", "content", "metadata", "fields", "folder", "tags",
+		"created_at", "updated_at", "last_accessed_at", "revision", "id", "sort_key"}
+
+	t.Run("positive: latest change per secret ordered by seq", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("select seq from sync_sequences where user_name = \\$1").
+			WithArgs("arya").
+			WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(9))
+		mock.ExpectQuery("select distinct on \\(secret_type, secret_id\\) seq, secret_type, secret_id, action, revision, changed_at from change_log where user_name = \\$1 and seq > \\$2").
+			WithArgs("arya", 4).
+			WillReturnRows(sqlmock.NewRows(changeColumns).
+				AddRow(8, "credentials", 3, "deleted", 2, changedAt).
+				AddRow(9, "note", 5, "updated", 4, changedAt))
+		mock.ExpectQuery("select user_name, title, content, metadata, fields, .+ from notes where user_name = \\$1 and id = any\\(\\$2\\) and deleted_at is null").
+			WithArgs("arya", pq.Array([]int64{5})).
+			WillReturnRows(sqlmock.NewRows(noteColumns).
+				AddRow("arya", "list", "zwcf07PPKWGQpOBElPSmsjQ=", nil, nil, nil, nil, changedAt, changedAt, nil, 4, 5, "5"))
+		mock.ExpectRollback()
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		res, err := pg.GetChanges(ctx, models.SyncRequest{UserName: "arya", Since: 4})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(9), res.Seq)
+		assert.False(t, res.Reset)
+		if assert.Len(t, res.Changes, 2) {
+			assert.Equal(t, models.Change{Seq: 8, Type: models.SecretCredentials, SecretID: 3, Action: models.ChangeDeleted, Revision: 2, ChangedAt: changedAt}, res.Changes[0])
+			assert.Equal(t, models.ChangeUpdated, res.Changes[1].Action)
+			if assert.NotNil(t, res.Changes[1].Note) {
+				assert.Equal(t, "some lovely notes", *res.Changes[1].Note.Content)
+				assert.Equal(t, int64(5), *res.Changes[1].Note.ID)
+			}
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("positive: since ahead of the log resets the client", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("select seq from sync_sequences where user_name = \\$1").
+			WithArgs("arya").
+			WillReturnRows(sqlmock.NewRows([]string{"seq"}))
+		mock.ExpectQuery("select distinct on \\(secret_type, secret_id\\) seq, secret_type, secret_id, action, revision, changed_at from change_log").
+			WithArgs("arya", 0).
+			WillReturnRows(sqlmock.NewRows(changeColumns))
+		mock.ExpectRollback()
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		res, err := pg.GetChanges(ctx, models.SyncRequest{UserName: "arya", Since: 12})
+		assert.NoError(t, err)
+		assert.Equal(t, models.SyncResponse{Seq: 0, Reset: true, Changes: []models.Change{}}, res)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDb_GetEvents(t *testing.T) {
+	ctx := context.Background()
+	changedAt := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	eventColumns := []string{"seq", "secret_type", "secret_id", "action", "revision", "changed_at"}
+	after := func(seq int64) *int64 {
+		return &seq
+	}
+
+	t.Run("positive: events after seq without secret values", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectQuery("select seq from sync_sequences where user_name = \\$1").
+			WithArgs("arya").
+			WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(9))
+		mock.ExpectQuery("select seq, secret_type, secret_id, action, revision, changed_at from change_log where user_name = \\$1 and seq > \\$2 order by seq limit \\$3").
+			WithArgs("arya", 7, eventsBatchSize).
+			WillReturnRows(sqlmock.NewRows(eventColumns).
+				AddRow(8, "credentials", 3, "deleted", 2, changedAt).
+				AddRow(9, "note", 5, "updated", 4, changedAt))
+
+		pg := Db{conn: mockDB}
+		res, err := pg.GetEvents(ctx, models.EventsRequest{UserName: "arya", After: after(7)})
+		assert.NoError(t, err)
+		assert.Equal(t, models.EventsResponse{Seq: 9, Events: []models.Event{
+			{Seq: 8, Type: models.SecretCredentials, SecretID: 3, Action: models.ChangeDeleted, Revision: 2, ChangedAt: changedAt},
+			{Seq: 9, Type: models.SecretNote, SecretID: 5, Action: models.ChangeUpdated, Revision: 4, ChangedAt: changedAt},
+		}}, res)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("positive: only the log seq for a new stream", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectQuery("select seq from sync_sequences where user_name = \\$1").
+			WithArgs("arya").
+			WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(9))
+
+		pg := Db{conn: mockDB}
+		res, err := pg.GetEvents(ctx, models.EventsRequest{UserName: "arya"})
+		assert.NoError(t, err)
+		assert.Equal(t, models.EventsResponse{Seq: 9, Events: []models.Event{}}, res)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("positive: event id ahead of the log resets the client", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectQuery("select seq from sync_sequences where user_name = \\$1").
+			WithArgs("arya").
+			WillReturnRows(sqlmock.NewRows([]string{"seq"}))
+
+		pg := Db{conn: mockDB}
+		res, err := pg.GetEvents(ctx, models.EventsRequest{UserName: "arya", After: after(3)})
+		assert.NoError(t, err)
+		assert.Equal(t, models.EventsResponse{Reset: true, Events: []models.Event{}}, res)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
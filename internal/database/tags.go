@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// ensureTag создает тег пользователя, если он не существует, и возвращает его идентификатор.
+// Пустое название или название из одних пробелов возвращает ErrInvalidTagName.
+func ensureTag(ctx context.Context, tx *sql.Tx, userName, name string) (int64, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return 0, ErrInvalidTagName
+	}
+	ensureTagQuery := "insert into tags (user_name, name) values ($1, $2) on conflict (user_name, name) do update set name = excluded.name returning id"
+	var id int64
+	if err := tx.QueryRowContext(ctx, ensureTagQuery, userName, name).Scan(&id); err != nil {
+		return 0, fmt.Errorf("ошибка при создании тега %q: %w", name, err)
+	}
+	return id, nil
+}
+
+// GetTags получает теги пользователя.
+func (d *Db) GetTags(ctx context.Context, tagRequest models.Tag) ([]models.Tag, error) {
+	args := []interface{}{tagRequest.UserName}
+	getTagsQuery := "select user_name, name from tags where user_name = $1"
+	if tagRequest.Name != nil {
+		args = append(args, *tagRequest.Name)
+		getTagsQuery += " AND name = $2"
+	}
+	getTagsQuery += " order by name"
+	rows, err := d.conn.QueryContext(ctx, getTagsQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении тегов для пользователя %q: %w", tagRequest.UserName, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var tags []models.Tag
+	for rows.Next() {
+		var userName, name string
+		if err = rows.Scan(&userName, &name); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании строк после запроса на получение тегов: %w", err)
+		}
+		tags = append(tags, models.Tag{UserName: userName, Name: &name})
+	}
+	if len(tags) == 0 {
+		return nil, ErrNoData
+	}
+	return tags, nil
+}
+
+// DeleteTag удаляет теги пользователя. Секреты, помеченные тегом, остаются в хранилище.
+func (d *Db) DeleteTag(ctx context.Context, tagRequest models.Tag) error {
+	args := []interface{}{tagRequest.UserName}
+	deleteTagQuery := "delete from tags where user_name = $1"
+	if tagRequest.Name != nil {
+		args = append(args, *tagRequest.Name)
+		deleteTagQuery += " AND name = $2"
+	}
+	if _, err := d.conn.ExecContext(ctx, deleteTagQuery, args...); err != nil {
+		return fmt.Errorf("ошибка при удалении тегов для пользователя %q: %w", tagRequest.UserName, err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDb_GetTags(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("positive: all tags", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectQuery("select user_name, name from tags where user_name").
+			WithArgs("hodor").
+			WillReturnRows(sqlmock.NewRows([]string{"user_name", "name"}).
+				AddRow("hodor", "door").
+				AddRow("hodor", "hold"))
+
+		pg := Db{
+			conn: mockDB,
+		}
+		tags, err := pg.GetTags(ctx, models.Tag{UserName: "hodor"})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Tag{{UserName: "hodor", Name: Ptr("door")}, {UserName: "hodor", Name: Ptr("hold")}}, tags)
+	})
+	t.Run("negative: no tags", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectQuery("select user_name, name from tags where user_name").
+			WithArgs("hodor").
+			WillReturnRows(sqlmock.NewRows([]string{"user_name", "name"}))
+
+		pg := Db{
+			conn: mockDB,
+		}
+		_, err = pg.GetTags(ctx, models.Tag{UserName: "hodor"})
+		assert.EqualError(t, err, ErrNoData.Error())
+	})
+}
+
+func TestDb_DeleteTag(t *testing.T) {
+	ctx := context.Background()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	mock.ExpectExec("delete from tags where user_name").
+		WithArgs("hodor", "door").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	pg := Db{
+		conn: mockDB,
+	}
+	err = pg.DeleteTag(ctx, models.Tag{UserName: "hodor", Name: Ptr("door")})
+	assert.NoError(t, err)
+}
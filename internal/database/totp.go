@@ -0,0 +1,151 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// SaveTOTP сохраняет секрет TOTP в базе данных.
+// Если указан логин, секрет привязывается к соответствующим учетным данным пользователя.
+func (d *Db) SaveTOTP(ctx context.Context, totpRequest models.TOTP) error {
+	return d.insertTOTP(ctx, d.conn, totpRequest)
+}
+
+// insertTOTP сохраняет секрет TOTP в подключении к базе данных или в транзакции q
+func (d *Db) insertTOTP(ctx context.Context, q queryer, totpRequest models.TOTP) error {
+	encryptedURI, credentialID, err := d.totpValues(ctx, q, totpRequest)
+	if err != nil {
+		return err
+	}
+
+	saveTOTPQuery := "insert into totp (user_name, name, uri, credential_id, metadata) values ($1, $2, $3, $4, $5)"
+	if _, err = q.ExecContext(ctx, saveTOTPQuery, totpRequest.UserName, *totpRequest.Name, encryptedURI, credentialID, totpRequest.Metadata); err != nil {
+		if conflictErr := asConflictError(err); conflictErr != nil {
+			err = conflictErr
+		}
+		return fmt.Errorf("ошибка при сохранении секрета TOTP для пользователя %q: %w", totpRequest.UserName, err)
+	}
+	return nil
+}
+
+// replaceTOTP заменяет содержимое секрета TOTP с тем же названием, увеличивая номер ревизии
+func (d *Db) replaceTOTP(ctx context.Context, q queryer, totpRequest models.TOTP) error {
+	encryptedURI, credentialID, err := d.totpValues(ctx, q, totpRequest)
+	if err != nil {
+		return err
+	}
+
+	updateTOTPQuery := "update totp set uri = $3, credential_id = $4, metadata = $5, updated_at = now(), revision = revision + 1 where user_name = $1 and name = $2"
+	res, err := q.ExecContext(ctx, updateTOTPQuery, totpRequest.UserName, *totpRequest.Name, encryptedURI, credentialID, totpRequest.Metadata)
+	if err == nil {
+		err = checkUpdated(res)
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении секрета TOTP для пользователя %q: %w", totpRequest.UserName, err)
+	}
+	return nil
+}
+
+// totpValues шифрует адрес секрета TOTP и находит учетные данные, к которым он привязан
+func (d *Db) totpValues(ctx context.Context, q queryer, totpRequest models.TOTP) (string, sql.NullInt64, error) {
+	var credentialID sql.NullInt64
+	encryptedURI, err := d.encryptAES(*totpRequest.URI)
+	if err != nil {
+		return "", credentialID, fmt.Errorf("ошибка при шифровании секрета TOTP: %w", err)
+	}
+
+	if totpRequest.Login != nil {
+		getCredIDQuery := "select id from credentials where user_name = $1 and login = $2 and site = $3 and deleted_at is null"
+		if err = q.QueryRowContext(ctx, getCredIDQuery, totpRequest.UserName, *totpRequest.Login, models.ValueOrEmpty(totpRequest.Site)).Scan(&credentialID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return "", credentialID, ErrNoSuchCredentials
+			}
+			return "", credentialID, fmt.Errorf("ошибка при поиске учетных данных для пользователя %q: %w", totpRequest.UserName, err)
+		}
+	}
+	return encryptedURI, credentialID, nil
+}
+
+// GetTOTP получает секреты TOTP из базы данных по имени или идентификатору.
+func (d *Db) GetTOTP(ctx context.Context, totpRequest models.TOTP) ([]models.TOTP, error) {
+	args := []interface{}{totpRequest.UserName}
+	getTOTPQuery := "select t.id, t.user_name, t.name, t.uri, c.login, c.site, t.metadata, t.created_at, t.updated_at, t.last_accessed_at, t.revision from totp t left join credentials c on c.id = t.credential_id and c.deleted_at is null where t.user_name = $1"
+	if totpRequest.ID != nil {
+		args = append(args, *totpRequest.ID)
+		getTOTPQuery += fmt.Sprintf(" AND t.id = $%d", len(args))
+	}
+	if totpRequest.Name != nil {
+		args = append(args, *totpRequest.Name)
+		getTOTPQuery += fmt.Sprintf(" AND t.name = $%d", len(args))
+	}
+	rows, err := d.conn.QueryContext(ctx, getTOTPQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении секретов TOTP для пользователя %q: %w", totpRequest.UserName, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var secrets []models.TOTP
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		var userName, name, uri string
+		var login, site, metadata sql.NullString
+		var audit auditValues
+		if err = rows.Scan(append([]interface{}{&id, &userName, &name, &uri, &login, &site, &metadata}, audit.dest()...)...); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании строк после запроса на получение секретов TOTP: %w", err)
+		}
+		decryptedURI, err := d.decryptAES(uri)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при расшифровке секрета TOTP: %w", err)
+		}
+		res := models.TOTP{
+			UserName: userName,
+			Name:     &name,
+			URI:      &decryptedURI,
+			Audit:    audit.audit(),
+		}
+		res.ID = &id
+		if login.Valid {
+			res.Login = &login.String
+		}
+		if site.Valid && site.String != "" {
+			res.Site = &site.String
+		}
+		if metadata.Valid {
+			res.Metadata = &metadata.String
+		}
+		secrets = append(secrets, res)
+		ids = append(ids, id)
+	}
+	if len(secrets) == 0 {
+		return nil, ErrNoData
+	}
+	if err = d.touchIDs(ctx, "totp", ids); err != nil {
+		return nil, err
+	}
+	return secrets, nil
+}
+
+// DeleteTOTP удаляет секреты TOTP из базы данных.
+func (d *Db) DeleteTOTP(ctx context.Context, totpRequest models.TOTP) error {
+	args := []interface{}{totpRequest.UserName}
+	deleteTOTPQuery := "delete from totp where user_name = $1"
+	if totpRequest.ID != nil {
+		args = append(args, *totpRequest.ID)
+		deleteTOTPQuery += fmt.Sprintf(" AND id = $%d", len(args))
+	}
+	if totpRequest.Name != nil {
+		args = append(args, *totpRequest.Name)
+		deleteTOTPQuery += fmt.Sprintf(" AND name = $%d", len(args))
+	}
+	if _, err := d.conn.ExecContext(ctx, deleteTOTPQuery, args...); err != nil {
+		return fmt.Errorf("ошибка при удалении секретов TOTP для пользователя %q: %w", totpRequest.UserName, err)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package database
+
+import (
+	"context"
+	"crypto/aes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDb_SaveTOTP(t *testing.T) {
+	key := "thisis32bitlongpassphraseimusing"
+	c, _ := aes.NewCipher([]byte(key))
+	uri := "otpauth://totp/Dragonstone:stannis?secret=JBSWY3DPEHPK3PXP"
+	ctx := context.Background()
+
+	t.Run("positive: without credentials", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		encryptedURI, _ := pg.encryptAES(uri)
+		mock.ExpectExec("insert into totp").
+			WithArgs("stannis", "dragonstone", encryptedURI, nil, nil).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err = pg.SaveTOTP(ctx, models.TOTP{UserName: "stannis", Name: Ptr("dragonstone"), URI: &uri})
+		assert.NoError(t, err)
+	})
+	t.Run("positive: attached to credentials", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		encryptedURI, _ := pg.encryptAES(uri)
+		mock.ExpectQuery("select id from credentials").
+			WithArgs("stannis", "king", "").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+		mock.ExpectExec("insert into totp").
+			WithArgs("stannis", "dragonstone", encryptedURI, 42, nil).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err = pg.SaveTOTP(ctx, models.TOTP{UserName: "stannis", Name: Ptr("dragonstone"), URI: &uri, Login: Ptr("king")})
+		assert.NoError(t, err)
+	})
+	t.Run("negative: credentials not found", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectQuery("select id from credentials").
+			WithArgs("stannis", "king", "").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		err = pg.SaveTOTP(ctx, models.TOTP{UserName: "stannis", Name: Ptr("dragonstone"), URI: &uri, Login: Ptr("king")})
+		assert.ErrorIs(t, err, ErrNoSuchCredentials)
+	})
+}
+
+func TestDb_GetTOTP(t *testing.T) {
+	key := "thisis32bitlongpassphraseimusing"
+	c, _ := aes.NewCipher([]byte(key))
+	uri := "otpauth://totp/Dragonstone:stannis?secret=JBSWY3DPEHPK3PXP"
+	ctx := context.Background()
+
+	t.Run("positive: by id", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		encryptedURI, _ := pg.encryptAES(uri)
+		createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+		updatedAt := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+		mock.ExpectQuery("select t.id, t.user_name, t.name, t.uri, c.login, c.site, t.metadata, t.created_at, t.updated_at, t.last_accessed_at, t.revision from totp t").
+			WithArgs("stannis", 7).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_name", "name", "uri", "login", "site", "metadata", "created_at", "updated_at", "last_accessed_at", "revision"}).
+				AddRow(7, "stannis", "dragonstone", encryptedURI, "king", "", nil, createdAt, updatedAt, nil, 2))
+		mock.ExpectExec("update totp set last_accessed_at = now\\(\\) where id = any").
+			WithArgs(pq.Array([]int64{7})).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		id := int64(7)
+		secrets, err := pg.GetTOTP(ctx, models.TOTP{UserName: "stannis", Audit: models.Audit{ID: &id}})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.TOTP{{UserName: "stannis", Name: Ptr("dragonstone"), URI: &uri, Login: Ptr("king"),
+			Audit: models.Audit{ID: &id, CreatedAt: &createdAt, UpdatedAt: &updatedAt, Revision: 2}}}, secrets)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("negative: no data for user", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectQuery("select t.id, t.user_name, t.name, t.uri, c.login, c.site, t.metadata, .+ from totp t").
+			WithArgs("stannis").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_name", "name", "uri", "login", "site", "metadata", "created_at", "updated_at", "last_accessed_at", "revision"}))
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		_, err = pg.GetTOTP(ctx, models.TOTP{UserName: "stannis"})
+		assert.EqualError(t, err, ErrNoData.Error())
+	})
+}
+
+func TestDb_DeleteTOTP(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("positive: by name", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectExec("delete from totp where user_name").
+			WithArgs("stannis", "dragonstone").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		pg := Db{
+			conn: mockDB,
+		}
+		err = pg.DeleteTOTP(ctx, models.TOTP{UserName: "stannis", Name: Ptr("dragonstone")})
+		assert.NoError(t, err)
+	})
+	t.Run("negative: exec error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectExec("delete from totp where user_name").
+			WithArgs("stannis").
+			WillReturnError(errors.New("some error"))
+
+		pg := Db{
+			conn: mockDB,
+		}
+		err = pg.DeleteTOTP(ctx, models.TOTP{UserName: "stannis"})
+		assert.EqualError(t, err, "ошибка при удалении секретов TOTP для пользователя \"stannis\": some error")
+	})
+}
@@ -0,0 +1,142 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// trashTables возвращает таблицы секретов, к которым относится запрос к корзине
+func trashTables(secretType models.SecretType) ([]secretTable, error) {
+	tables := []secretTable{credentialsTable, notesTable, cardsTable}
+	if secretType == "" {
+		return tables, nil
+	}
+	for _, table := range tables {
+		if table.secretType == secretType {
+			return []secretTable{table}, nil
+		}
+	}
+	return nil, fmt.Errorf("неизвестный тип секрета %q", secretType)
+}
+
+// trashCondition возвращает условие отбора секретов пользователя из корзины и его аргументы
+func trashCondition(trashRequest models.TrashRequest) (string, []interface{}) {
+	args := []interface{}{trashRequest.UserName}
+	condition := " where user_name = $1 and deleted_at is not null"
+	if trashRequest.SecretID != 0 {
+		args = append(args, trashRequest.SecretID)
+		condition += fmt.Sprintf(" and id = $%d", len(args))
+	}
+	return condition, args
+}
+
+// purgeQuery возвращает запрос, окончательно удаляющий отобранные секреты вместе с их историей версий
+func (s secretTable) purgeQuery(condition string, typeArg int) string {
+	return fmt.Sprintf("with purged as (delete from %s%s returning id) "+
+		"delete from secret_history where secret_type = $%d and secret_id in (select id from purged)", s.name, condition, typeArg)
+}
+
+// GetTrash получает секреты пользователя, находящиеся в корзине, начиная с последних удаленных.
+func (d *Db) GetTrash(ctx context.Context, trashRequest models.TrashRequest) ([]models.TrashItem, error) {
+	tables, err := trashTables(trashRequest.Type)
+	if err != nil {
+		return nil, err
+	}
+	condition, args := trashCondition(trashRequest)
+
+	var items []models.TrashItem
+	for _, table := range tables {
+		getTrashQuery := fmt.Sprintf("select id, %s, deleted_at from %s%s", table.nameColumn, table.name, condition)
+		rows, err := d.conn.QueryContext(ctx, getTrashQuery, args...)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при получении корзины для пользователя %q: %w", trashRequest.UserName, err)
+		}
+		for rows.Next() {
+			item := models.TrashItem{Type: table.secretType}
+			if err = rows.Scan(&item.SecretID, &item.Title, &item.DeletedAt); err != nil {
+				_ = rows.Close()
+				return nil, fmt.Errorf("ошибка при сканировании строк после запроса на получение корзины: %w", err)
+			}
+			items = append(items, item)
+		}
+		_ = rows.Close()
+	}
+	if len(items) == 0 {
+		return nil, ErrNoData
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	return items, nil
+}
+
+// RestoreFromTrash возвращает секреты пользователя из корзины.
+// Если за время нахождения в корзине был сохранен секрет с тем же ключом, возвращается ошибка конфликта.
+func (d *Db) RestoreFromTrash(ctx context.Context, trashRequest models.TrashRequest) error {
+	tables, err := trashTables(trashRequest.Type)
+	if err != nil {
+		return err
+	}
+	condition, args := trashCondition(trashRequest)
+
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка при восстановлении секретов из корзины для пользователя %q: %w", trashRequest.UserName, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var restored int64
+	for _, table := range tables {
+		restoreQuery := fmt.Sprintf("update %s set deleted_at = null%s", table.name, condition)
+		res, err := tx.ExecContext(ctx, restoreQuery, args...)
+		if err != nil {
+			if conflictErr := asConflictError(err); conflictErr != nil {
+				return conflictErr
+			}
+			return fmt.Errorf("ошибка при восстановлении секретов из корзины для пользователя %q: %w", trashRequest.UserName, err)
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		restored += affected
+	}
+	if restored == 0 {
+		return ErrNoData
+	}
+	return tx.Commit()
+}
+
+// EmptyTrash окончательно удаляет секреты пользователя из корзины вместе с их историей версий.
+func (d *Db) EmptyTrash(ctx context.Context, trashRequest models.TrashRequest) error {
+	tables, err := trashTables(trashRequest.Type)
+	if err != nil {
+		return err
+	}
+	condition, args := trashCondition(trashRequest)
+	args = append(args, nil)
+
+	for _, table := range tables {
+		args[len(args)-1] = table.secretType
+		if _, err = d.conn.ExecContext(ctx, table.purgeQuery(condition, len(args)), args...); err != nil {
+			return fmt.Errorf("ошибка при очистке корзины для пользователя %q: %w", trashRequest.UserName, err)
+		}
+	}
+	return nil
+}
+
+// PurgeTrash окончательно удаляет секреты всех пользователей, находящиеся в корзине дольше retentionDays дней.
+func (d *Db) PurgeTrash(ctx context.Context, retentionDays int) error {
+	condition := " where deleted_at < now() - make_interval(days => $1)"
+	for _, table := range []secretTable{credentialsTable, notesTable, cardsTable} {
+		if _, err := d.conn.ExecContext(ctx, table.purgeQuery(condition, 2), retentionDays, table.secretType); err != nil {
+			return fmt.Errorf("ошибка при очистке корзины: %w", err)
+		}
+	}
+	return nil
+}
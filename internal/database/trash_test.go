@@ -0,0 +1,194 @@
+package database
+
+import (
+	"context"
+	"crypto/aes"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDb_GetTrash(t *testing.T) {
+	key := "thisis32bitlongpassphraseimusing"
+	c, _ := aes.NewCipher([]byte(key))
+	ctx := context.Background()
+	earlier := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	later := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+
+	t.Run("positive: all types sorted by deletion time", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectQuery("select id, coalesce\\(nullif\\(name, ''\\), login\\), deleted_at from credentials where user_name = \\$1 and deleted_at is not null").
+			WithArgs("gendry").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "deleted_at"}).AddRow(3, "forge", earlier))
+		mock.ExpectQuery("select id, title, deleted_at from notes where user_name = \\$1 and deleted_at is not null").
+			WithArgs("gendry").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "deleted_at"}).AddRow(5, "hammer", later))
+		mock.ExpectQuery("select id, bank_name, deleted_at from cards where user_name = \\$1 and deleted_at is not null").
+			WithArgs("gendry").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "bank_name", "deleted_at"}))
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		items, err := pg.GetTrash(ctx, models.TrashRequest{UserName: "gendry"})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.TrashItem{
+			{Type: models.SecretNote, SecretID: 5, Title: "hammer", DeletedAt: later},
+			{Type: models.SecretCredentials, SecretID: 3, Title: "forge", DeletedAt: earlier},
+		}, items)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("negative: empty trash", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectQuery("select id, title, deleted_at from notes where user_name = \\$1 and deleted_at is not null and id = \\$2").
+			WithArgs("gendry", 5).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "deleted_at"}))
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		_, err = pg.GetTrash(ctx, models.TrashRequest{UserName: "gendry", Type: models.SecretNote, SecretID: 5})
+		assert.ErrorIs(t, err, ErrNoData)
+	})
+}
+
+func TestDb_RestoreFromTrash(t *testing.T) {
+	key := "thisis32bitlongpassphraseimusing"
+	c, _ := aes.NewCipher([]byte(key))
+	ctx := context.Background()
+	request := models.TrashRequest{UserName: "gendry", Type: models.SecretNote, SecretID: 5}
+
+	t.Run("positive: restored", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("update notes set deleted_at = null where user_name = \\$1 and deleted_at is not null and id = \\$2").
+			WithArgs("gendry", 5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		assert.NoError(t, pg.RestoreFromTrash(ctx, request))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("negative: nothing to restore", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("update notes set deleted_at = null").
+			WithArgs("gendry", 5).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		assert.ErrorIs(t, pg.RestoreFromTrash(ctx, request), ErrNoData)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("negative: key is taken", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("update notes set deleted_at = null").
+			WithArgs("gendry", 5).
+			WillReturnError(&pq.Error{Code: uniqueViolationCode, Constraint: "notes_user_title_key"})
+		mock.ExpectRollback()
+
+		pg := Db{
+			conn:          mockDB,
+			encryptionKey: key,
+			dataCipher:    c,
+		}
+		err = pg.RestoreFromTrash(ctx, request)
+		assert.ErrorIs(t, err, ErrConflict)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDb_EmptyTrash(t *testing.T) {
+	key := "thisis32bitlongpassphraseimusing"
+	c, _ := aes.NewCipher([]byte(key))
+	ctx := context.Background()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	mock.ExpectExec("with purged as \\(delete from cards where user_name = \\$1 and deleted_at is not null returning id\\) "+
+		"delete from secret_history where secret_type = \\$2 and secret_id in \\(select id from purged\\)").
+		WithArgs("gendry", models.SecretCard).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	pg := Db{
+		conn:          mockDB,
+		encryptionKey: key,
+		dataCipher:    c,
+	}
+	assert.NoError(t, pg.EmptyTrash(ctx, models.TrashRequest{UserName: "gendry", Type: models.SecretCard}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDb_PurgeTrash(t *testing.T) {
+	key := "thisis32bitlongpassphraseimusing"
+	c, _ := aes.NewCipher([]byte(key))
+	ctx := context.Background()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	for _, table := range []secretTable{credentialsTable, notesTable, cardsTable} {
+		mock.ExpectExec("with purged as \\(delete from "+table.name+" where deleted_at < now\\(\\) - make_interval\\(days => \\$1\\) returning id\\)").
+			WithArgs(30, table.secretType).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	pg := Db{
+		conn:          mockDB,
+		encryptionKey: key,
+		dataCipher:    c,
+	}
+	assert.NoError(t, pg.PurgeTrash(ctx, 30))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
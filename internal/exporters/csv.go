@@ -0,0 +1,52 @@
+package exporters
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// csvHeader столбцы CSV в формате Bitwarden
+var csvHeader = []string{"folder", "favorite", "type", "name", "notes", "fields", "reprompt",
+	"login_uri", "login_username", "login_password", "login_totp"}
+
+// encodeCSV записывает учетные данные и заметки в CSV в формате Bitwarden.
+// Пользовательские поля записываются построчно как "название: значение", несколько адресов - через запятую.
+func encodeCSV(vault models.Vault) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(csvHeader); err != nil {
+		return nil, err
+	}
+	for _, creds := range vault.Credentials {
+		urls := make([]string, 0, len(creds.URLs))
+		for _, u := range creds.URLs {
+			urls = append(urls, u.URL)
+		}
+		record := []string{models.ValueOrEmpty(creds.Folder), "", "login", credentialsName(creds), models.ValueOrEmpty(creds.Metadata),
+			csvFields(creds.Fields), "0", strings.Join(urls, ","), models.ValueOrEmpty(creds.Login), models.ValueOrEmpty(creds.Password), ""}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	for _, note := range vault.Notes {
+		record := []string{models.ValueOrEmpty(note.Folder), "", "note", models.ValueOrEmpty(note.Title), models.ValueOrEmpty(note.Content),
+			csvFields(note.Fields), "0", "", "", "", ""}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// csvFields записывает пользовательские поля построчно в формате "название: значение"
+func csvFields(fields []models.CustomField) string {
+	lines := make([]string, 0, len(fields))
+	for _, f := range fields {
+		lines = append(lines, f.Name+": "+f.Value)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,63 @@
+// Package exporters записывает учетные данные, заметки и карты в открытом виде в форматах,
+// которые принимают другие менеджеры паролей.
+//
+// Форматы совместимы с разбором в пакете importers, поэтому выгрузку можно загрузить обратно
+// командой import.
+package exporters
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// Format определяет формат выгрузки в открытом виде
+type Format string
+
+const (
+	FormatCSV     Format = "csv"         // CSV в формате Bitwarden: учетные данные и заметки
+	FormatJSON    Format = "json"        // незашифрованный JSON в формате Bitwarden
+	FormatKeePass Format = "keepass-xml" // XML в формате KeePass 2.x
+)
+
+// ErrUnknownFormat означает неподдерживаемый формат выгрузки
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// Valid проверяет, что формат поддерживается
+func (f Format) Valid() error {
+	switch f {
+	case FormatCSV, FormatJSON, FormatKeePass:
+		return nil
+	}
+	return fmt.Errorf("%w %q: используйте csv, json или keepass-xml", ErrUnknownFormat, f)
+}
+
+// SupportsCards сообщает, переносятся ли в формат банковские карты
+func (f Format) SupportsCards() bool {
+	return f != FormatCSV
+}
+
+// Encode записывает учетные данные, заметки и карты выгрузки в формате format.
+// Секреты TOTP и SSH-ключи в открытую выгрузку не попадают.
+func Encode(format Format, vault models.Vault) ([]byte, error) {
+	if err := format.Valid(); err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatCSV:
+		return encodeCSV(vault)
+	case FormatJSON:
+		return encodeJSON(vault)
+	default:
+		return encodeKeePass(vault)
+	}
+}
+
+// credentialsName возвращает название учетных данных для выгрузки: отображаемое название или сайт
+func credentialsName(creds models.Credentials) string {
+	if name := models.ValueOrEmpty(creds.Name); name != "" {
+		return name
+	}
+	return models.ValueOrEmpty(creds.Site)
+}
@@ -0,0 +1,129 @@
+package exporters
+
+import (
+	"testing"
+
+	"github.com/ZnNr/GopherVault/internal/importers"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func Ptr(s string) *string {
+	return &s
+}
+
+// testVault выгрузка с учетными данными, заметкой и картой
+var testVault = models.Vault{
+	Version: models.VaultVersion,
+	Credentials: []models.Credentials{{
+		UserName: "arya",
+		Login:    Ptr("arya"),
+		Password: Ptr("needle"),
+		Site:     Ptr("mail.example.com"),
+		Name:     Ptr("Mail"),
+		Metadata: Ptr("main box"),
+		URLs:     []models.CredentialURL{{URL: "https://mail.example.com"}, {URL: "https://webmail.example.com"}},
+		Fields:   []models.CustomField{{Name: "recovery", Type: models.FieldHidden, Value: "r3c"}},
+		Folder:   Ptr("work/mail"),
+		Tags:     []string{"mail"},
+	}},
+	Notes: []models.Note{{UserName: "arya", Title: Ptr("list"), Content: Ptr("cersei, joffrey")}},
+	Cards: []models.Card{{UserName: "arya", BankName: Ptr("Iron Bank"), Number: Ptr("4111111111111111"), CV: Ptr("123"),
+		Password: Ptr("0000"), CardType: Ptr("Visa")}},
+}
+
+func TestEncode_CSV(t *testing.T) {
+	data, err := Encode(FormatCSV, testVault)
+	assert.NoError(t, err)
+	assert.Equal(t, "folder,favorite,type,name,notes,fields,reprompt,login_uri,login_username,login_password,login_totp\n"+
+		"work/mail,,login,Mail,main box,recovery: r3c,0,\"https://mail.example.com,https://webmail.example.com\",arya,needle,\n"+
+		",,note,list,\"cersei, joffrey\",,0,,,,\n", string(data))
+
+	res, err := importers.Parse(importers.FormatGenericCSV, data, importers.Options{})
+	assert.NoError(t, err)
+	if assert.Len(t, res.Credentials, 1) {
+		assert.Equal(t, "mail.example.com", *res.Credentials[0].Site)
+		assert.Equal(t, testVault.Credentials[0].URLs, res.Credentials[0].URLs)
+	}
+	assert.Equal(t, []models.Note{{Title: Ptr("list"), Content: Ptr("cersei, joffrey")}}, res.Notes)
+}
+
+func TestEncode_JSON(t *testing.T) {
+	data, err := Encode(FormatJSON, testVault)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"folders": [
+    {
+      "id": "folder-1",
+      "name": "work/mail"
+    }
+  ]`)
+
+	res, err := importers.Parse(importers.FormatBitwarden, data, importers.Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Credentials{{
+		Login:    Ptr("arya"),
+		Password: Ptr("needle"),
+		Site:     Ptr("mail.example.com"),
+		Name:     Ptr("Mail"),
+		Metadata: Ptr("main box"),
+		URLs:     testVault.Credentials[0].URLs,
+		Fields:   []models.CustomField{{Name: "recovery", Type: models.FieldHidden, Value: "r3c"}},
+		Folder:   Ptr("work/mail"),
+	}}, res.Credentials)
+	assert.Equal(t, []models.Note{{Title: Ptr("list"), Content: Ptr("cersei, joffrey")}}, res.Notes)
+	if assert.Len(t, res.Cards, 1) {
+		assert.Equal(t, "4111111111111111", *res.Cards[0].Number)
+		assert.Equal(t, "123", *res.Cards[0].CV)
+		assert.Equal(t, []models.CustomField{{Name: "PIN", Type: models.FieldHidden, Value: "0000"}}, res.Cards[0].Fields)
+	}
+}
+
+func TestEncode_KeePass(t *testing.T) {
+	data, err := Encode(FormatKeePass, testVault)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "<Name>work</Name>")
+	assert.Contains(t, string(data), `<Value Protected="True">needle</Value>`)
+
+	again, err := Encode(FormatKeePass, testVault)
+	assert.NoError(t, err)
+	assert.Equal(t, data, again)
+
+	// Записи корневой группы разбираются раньше записей вложенных групп
+	res, err := importers.Parse(importers.FormatKeePass, data, importers.Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Credentials{
+		{
+			Login:    Ptr(""),
+			Password: Ptr("0000"),
+			Site:     Ptr("Iron Bank"),
+			Name:     Ptr("Iron Bank"),
+			Fields: []models.CustomField{
+				{Name: "Number", Type: models.FieldHidden, Value: "4111111111111111"},
+				{Name: "CV", Type: models.FieldHidden, Value: "123"},
+				{Name: "Card type", Type: models.FieldText, Value: "Visa"},
+			},
+		},
+		{
+			Login:    Ptr("arya"),
+			Password: Ptr("needle"),
+			Site:     Ptr("mail.example.com"),
+			Name:     Ptr("Mail"),
+			Metadata: Ptr("main box"),
+			URLs:     []models.CredentialURL{{URL: "https://mail.example.com"}},
+			Fields: []models.CustomField{
+				{Name: "URL_2", Type: models.FieldText, Value: "https://webmail.example.com"},
+				{Name: "recovery", Type: models.FieldHidden, Value: "r3c"},
+			},
+			Folder: Ptr("work/mail"),
+			Tags:   []string{"mail"},
+		},
+	}, res.Credentials)
+	assert.Equal(t, []models.Note{{Title: Ptr("list"), Content: Ptr("cersei, joffrey")}}, res.Notes)
+}
+
+func TestFormat_Valid(t *testing.T) {
+	assert.NoError(t, FormatKeePass.Valid())
+	assert.EqualError(t, Format("pdf").Valid(), `unknown export format "pdf": используйте csv, json или keepass-xml`)
+	assert.False(t, FormatCSV.SupportsCards())
+	assert.True(t, FormatJSON.SupportsCards())
+}
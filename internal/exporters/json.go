@@ -0,0 +1,148 @@
+package exporters
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// Типы записей и пользовательских полей Bitwarden
+const (
+	bitwardenTypeLogin = 1
+	bitwardenTypeNote  = 2
+	bitwardenTypeCard  = 3
+
+	bitwardenFieldText    = 0
+	bitwardenFieldHidden  = 1
+	bitwardenFieldBoolean = 2
+)
+
+type bitwardenExport struct {
+	Encrypted bool              `json:"encrypted"`
+	Folders   []bitwardenFolder `json:"folders"`
+	Items     []bitwardenItem   `json:"items"`
+}
+
+type bitwardenFolder struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type bitwardenItem struct {
+	ID         string               `json:"id"`
+	FolderID   *string              `json:"folderId"`
+	Type       int                  `json:"type"`
+	Name       string               `json:"name"`
+	Notes      *string              `json:"notes"`
+	Favorite   bool                 `json:"favorite"`
+	Fields     []bitwardenField     `json:"fields,omitempty"`
+	Login      *bitwardenLogin      `json:"login,omitempty"`
+	SecureNote *bitwardenSecureNote `json:"secureNote,omitempty"`
+	Card       *bitwardenCard       `json:"card,omitempty"`
+}
+
+type bitwardenField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Type  int    `json:"type"`
+}
+
+type bitwardenLogin struct {
+	URIs     []bitwardenURI `json:"uris"`
+	Username string         `json:"username"`
+	Password string         `json:"password"`
+	TOTP     *string        `json:"totp"`
+}
+
+type bitwardenURI struct {
+	Match *int   `json:"match"`
+	URI   string `json:"uri"`
+}
+
+type bitwardenSecureNote struct {
+	Type int `json:"type"`
+}
+
+type bitwardenCard struct {
+	CardholderName *string `json:"cardholderName"`
+	Brand          *string `json:"brand"`
+	Number         string  `json:"number"`
+	ExpMonth       *string `json:"expMonth"`
+	ExpYear        *string `json:"expYear"`
+	Code           string  `json:"code"`
+}
+
+// encodeJSON записывает учетные данные, заметки и карты в незашифрованный JSON в формате Bitwarden.
+// Метаданные записываются в заметки записи, PIN-код карты - в скрытое поле PIN.
+func encodeJSON(vault models.Vault) ([]byte, error) {
+	export := bitwardenExport{Folders: []bitwardenFolder{}, Items: []bitwardenItem{}}
+	folderIDs := make(map[string]string)
+	// folderID возвращает идентификатор папки выгрузки, добавляя папку при первом обращении
+	folderID := func(path *string) *string {
+		if path == nil || *path == "" {
+			return nil
+		}
+		id, ok := folderIDs[*path]
+		if !ok {
+			id = "folder-" + strconv.Itoa(len(folderIDs)+1)
+			folderIDs[*path] = id
+			export.Folders = append(export.Folders, bitwardenFolder{ID: id, Name: *path})
+		}
+		return &id
+	}
+	// itemID возвращает идентификатор следующей записи выгрузки
+	itemID := func() string {
+		return "item-" + strconv.Itoa(len(export.Items)+1)
+	}
+
+	for _, creds := range vault.Credentials {
+		login := &bitwardenLogin{Username: models.ValueOrEmpty(creds.Login), Password: models.ValueOrEmpty(creds.Password)}
+		for _, u := range creds.URLs {
+			login.URIs = append(login.URIs, bitwardenURI{URI: u.URL})
+		}
+		export.Items = append(export.Items, bitwardenItem{
+			ID: itemID(), FolderID: folderID(creds.Folder), Type: bitwardenTypeLogin, Name: credentialsName(creds),
+			Notes: creds.Metadata, Fields: bitwardenFields(creds.Fields), Login: login,
+		})
+	}
+	for _, note := range vault.Notes {
+		fields := bitwardenFields(note.Fields)
+		if metadata := models.ValueOrEmpty(note.Metadata); metadata != "" {
+			fields = append(fields, bitwardenField{Name: "Metadata", Value: metadata, Type: bitwardenFieldText})
+		}
+		export.Items = append(export.Items, bitwardenItem{
+			ID: itemID(), FolderID: folderID(note.Folder), Type: bitwardenTypeNote, Name: models.ValueOrEmpty(note.Title),
+			Notes: note.Content, Fields: fields, SecureNote: &bitwardenSecureNote{},
+		})
+	}
+	for _, card := range vault.Cards {
+		fields := bitwardenFields(card.Fields)
+		if pin := models.ValueOrEmpty(card.Password); pin != "" {
+			fields = append(fields, bitwardenField{Name: "PIN", Value: pin, Type: bitwardenFieldHidden})
+		}
+		export.Items = append(export.Items, bitwardenItem{
+			ID: itemID(), FolderID: folderID(card.Folder), Type: bitwardenTypeCard, Name: models.ValueOrEmpty(card.BankName),
+			Notes: card.Metadata, Fields: fields,
+			Card: &bitwardenCard{Brand: card.CardType, Number: models.ValueOrEmpty(card.Number), Code: models.ValueOrEmpty(card.CV)},
+		})
+	}
+	return json.MarshalIndent(export, "", "  ")
+}
+
+// bitwardenFields преобразует пользовательские поля в поля Bitwarden. Адреса, почта и даты
+// записываются текстовыми полями.
+func bitwardenFields(fields []models.CustomField) []bitwardenField {
+	var res []bitwardenField
+	for _, f := range fields {
+		fieldType := bitwardenFieldText
+		switch f.Type {
+		case models.FieldHidden:
+			fieldType = bitwardenFieldHidden
+		case models.FieldBoolean:
+			fieldType = bitwardenFieldBoolean
+		}
+		res = append(res, bitwardenField{Name: f.Name, Value: f.Value, Type: fieldType})
+	}
+	return res
+}
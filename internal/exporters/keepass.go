@@ -0,0 +1,155 @@
+package exporters
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"strconv"
+	"strings"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// keePassRootName название корневой группы выгрузки
+const keePassRootName = "GopherVault"
+
+type keePassFile struct {
+	XMLName xml.Name `xml:"KeePassFile"`
+	Meta    struct {
+		Generator string `xml:"Generator"`
+	} `xml:"Meta"`
+	Root struct {
+		Group *keePassGroup `xml:"Group"`
+	} `xml:"Root"`
+}
+
+type keePassGroup struct {
+	UUID    string          `xml:"UUID"`
+	Name    string          `xml:"Name"`
+	Entries []keePassEntry  `xml:"Entry"`
+	Groups  []*keePassGroup `xml:"Group"`
+}
+
+type keePassEntry struct {
+	UUID    string          `xml:"UUID"`
+	Tags    string          `xml:"Tags,omitempty"`
+	Strings []keePassString `xml:"String"`
+}
+
+type keePassString struct {
+	Key   string `xml:"Key"`
+	Value struct {
+		Text      string `xml:",chardata"`
+		Protected string `xml:"Protected,attr,omitempty"`
+	} `xml:"Value"`
+}
+
+// encodeKeePass записывает учетные данные, заметки и карты в XML в формате KeePass 2.x.
+// Папки становятся вложенными группами, пароли и скрытые поля помечаются как защищенные.
+// Номер, CV и тип карты записываются дополнительными полями записи, PIN-код - паролем.
+func encodeKeePass(vault models.Vault) ([]byte, error) {
+	root := &keePassGroup{UUID: keePassUUID("group", ""), Name: keePassRootName}
+	groups := map[string]*keePassGroup{"": root}
+	// group возвращает группу папки, создавая недостающие группы пути
+	var group func(path string) *keePassGroup
+	group = func(path string) *keePassGroup {
+		if g, ok := groups[path]; ok {
+			return g
+		}
+		parent, name := "", path
+		if i := strings.LastIndex(path, "/"); i >= 0 {
+			parent, name = path[:i], path[i+1:]
+		}
+		g := &keePassGroup{UUID: keePassUUID("group", path), Name: name}
+		p := group(parent)
+		p.Groups = append(p.Groups, g)
+		groups[path] = g
+		return g
+	}
+	// add добавляет запись в группу папки
+	add := func(folder *string, tags []string, strs ...keePassString) {
+		g := group(models.ValueOrEmpty(folder))
+		id := keePassUUID("entry", models.ValueOrEmpty(folder)+"/"+strconv.Itoa(len(g.Entries)))
+		g.Entries = append(g.Entries, keePassEntry{UUID: id, Tags: strings.Join(tags, ";"), Strings: strs})
+	}
+
+	for _, creds := range vault.Credentials {
+		strs := []keePassString{
+			keePassValue("Title", credentialsName(creds), false),
+			keePassValue("UserName", models.ValueOrEmpty(creds.Login), false),
+			keePassValue("Password", models.ValueOrEmpty(creds.Password), true),
+		}
+		for i, u := range creds.URLs {
+			key := "URL"
+			if i > 0 {
+				key = "URL_" + strconv.Itoa(i+1)
+			}
+			strs = append(strs, keePassValue(key, u.URL, false))
+		}
+		strs = append(strs, keePassValue("Notes", models.ValueOrEmpty(creds.Metadata), false))
+		add(creds.Folder, creds.Tags, append(strs, keePassFields(creds.Fields)...)...)
+	}
+	for _, note := range vault.Notes {
+		strs := []keePassString{
+			keePassValue("Title", models.ValueOrEmpty(note.Title), false),
+			keePassValue("Notes", models.ValueOrEmpty(note.Content), false),
+		}
+		if metadata := models.ValueOrEmpty(note.Metadata); metadata != "" {
+			strs = append(strs, keePassValue("Metadata", metadata, false))
+		}
+		add(note.Folder, note.Tags, append(strs, keePassFields(note.Fields)...)...)
+	}
+	for _, card := range vault.Cards {
+		strs := []keePassString{
+			keePassValue("Title", models.ValueOrEmpty(card.BankName), false),
+			keePassValue("Password", models.ValueOrEmpty(card.Password), true),
+			keePassValue("Notes", models.ValueOrEmpty(card.Metadata), false),
+			keePassValue("Number", models.ValueOrEmpty(card.Number), true),
+			keePassValue("CV", models.ValueOrEmpty(card.CV), true),
+		}
+		if cardType := models.ValueOrEmpty(card.CardType); cardType != "" {
+			strs = append(strs, keePassValue("Card type", cardType, false))
+		}
+		add(card.Folder, card.Tags, append(strs, keePassFields(card.Fields)...)...)
+	}
+
+	file := keePassFile{}
+	file.Meta.Generator = keePassRootName
+	file.Root.Group = root
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "\t")
+	if err := enc.Encode(file); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// keePassValue создает поле записи KeePass
+func keePassValue(key, value string, protected bool) keePassString {
+	s := keePassString{Key: key}
+	s.Value.Text = value
+	if protected {
+		s.Value.Protected = "True"
+	}
+	return s
+}
+
+// keePassFields преобразует пользовательские поля в поля записи KeePass, скрытые поля помечаются как защищенные
+func keePassFields(fields []models.CustomField) []keePassString {
+	var res []keePassString
+	for _, f := range fields {
+		res = append(res, keePassValue(f.Name, f.Value, f.Type == models.FieldHidden))
+	}
+	return res
+}
+
+// keePassUUID возвращает идентификатор группы или записи KeePass. Идентификатор получается из пути,
+// поэтому повторная выгрузка тех же секретов дает тот же файл.
+func keePassUUID(kind, path string) string {
+	sum := sha256.Sum256([]byte(kind + "\x00" + path))
+	return base64.StdEncoding.EncodeToString(sum[:16])
+}
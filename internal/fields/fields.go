@@ -0,0 +1,85 @@
+package fields
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// DateLayout формат значений полей типа date
+const DateLayout = "2006-01-02"
+
+// ErrInvalidField означает, что пользовательское поле заполнено некорректно
+var ErrInvalidField = errors.New("invalid custom field")
+
+// Validate проверяет имена, типы и значения пользовательских полей.
+// Имена полей должны быть непустыми и уникальными в рамках секрета.
+func Validate(fields []models.CustomField) error {
+	names := make(map[string]struct{}, len(fields))
+	for _, f := range fields {
+		if strings.TrimSpace(f.Name) == "" {
+			return fmt.Errorf("%w: empty field name", ErrInvalidField)
+		}
+		if _, ok := names[f.Name]; ok {
+			return fmt.Errorf("%w: duplicate field %q", ErrInvalidField, f.Name)
+		}
+		names[f.Name] = struct{}{}
+		if err := validateValue(f); err != nil {
+			return fmt.Errorf("%w: field %q: %s", ErrInvalidField, f.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// validateValue проверяет значение поля в соответствии с его типом
+func validateValue(f models.CustomField) error {
+	switch f.Type {
+	case "", models.FieldText, models.FieldHidden:
+		return nil
+	case models.FieldURL:
+		u, err := url.Parse(f.Value)
+		if err != nil {
+			return err
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return errors.New("url must be absolute")
+		}
+		return nil
+	case models.FieldEmail:
+		addr, err := mail.ParseAddress(f.Value)
+		if err != nil {
+			return err
+		}
+		if addr.Address != f.Value {
+			return errors.New("email must not contain display name")
+		}
+		return nil
+	case models.FieldDate:
+		_, err := time.Parse(DateLayout, f.Value)
+		return err
+	case models.FieldBoolean:
+		_, err := strconv.ParseBool(f.Value)
+		return err
+	default:
+		return fmt.Errorf("unknown field type %q", f.Type)
+	}
+}
+
+// Parse разбирает поле, заданное в виде name=value или name:type=value.
+// Если тип не указан, используется fieldType.
+func Parse(spec string, fieldType models.FieldType) (models.CustomField, error) {
+	name, value, ok := strings.Cut(spec, "=")
+	if !ok {
+		return models.CustomField{}, fmt.Errorf("%w: %q should be in form name=value", ErrInvalidField, spec)
+	}
+	if n, t, ok := strings.Cut(name, ":"); ok {
+		name, fieldType = n, models.FieldType(t)
+	}
+	return models.CustomField{Name: name, Type: fieldType, Value: value}, nil
+}
@@ -0,0 +1,91 @@
+package fields
+
+import (
+	"testing"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		fields  []models.CustomField
+		wantErr bool
+	}{
+		{
+			name: "positive: all types",
+			fields: []models.CustomField{
+				{Name: "motto", Value: "winter is coming"},
+				{Name: "pin", Type: models.FieldHidden, Value: "1234"},
+				{Name: "castle", Type: models.FieldURL, Value: "https://winterfell.example.com"},
+				{Name: "raven", Type: models.FieldEmail, Value: "ned@winterfell.example.com"},
+				{Name: "born", Type: models.FieldDate, Value: "2024-02-29"},
+				{Name: "alive", Type: models.FieldBoolean, Value: "false"},
+			},
+		},
+		{name: "positive: no fields"},
+		{
+			name:    "negative: empty name",
+			fields:  []models.CustomField{{Name: " ", Value: "hodor"}},
+			wantErr: true,
+		},
+		{
+			name:    "negative: duplicate name",
+			fields:  []models.CustomField{{Name: "sword", Value: "ice"}, {Name: "sword", Value: "needle"}},
+			wantErr: true,
+		},
+		{
+			name:    "negative: relative url",
+			fields:  []models.CustomField{{Name: "castle", Type: models.FieldURL, Value: "winterfell"}},
+			wantErr: true,
+		},
+		{
+			name:    "negative: invalid email",
+			fields:  []models.CustomField{{Name: "raven", Type: models.FieldEmail, Value: "ned at winterfell"}},
+			wantErr: true,
+		},
+		{
+			name:    "negative: invalid date",
+			fields:  []models.CustomField{{Name: "born", Type: models.FieldDate, Value: "2023-02-29"}},
+			wantErr: true,
+		},
+		{
+			name:    "negative: invalid boolean",
+			fields:  []models.CustomField{{Name: "alive", Type: models.FieldBoolean, Value: "maybe"}},
+			wantErr: true,
+		},
+		{
+			name:    "negative: unknown type",
+			fields:  []models.CustomField{{Name: "house", Type: "sigil", Value: "direwolf"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.fields)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidField)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Run("positive: default type", func(t *testing.T) {
+		f, err := Parse("pin=12=34", models.FieldHidden)
+		assert.NoError(t, err)
+		assert.Equal(t, models.CustomField{Name: "pin", Type: models.FieldHidden, Value: "12=34"}, f)
+	})
+	t.Run("positive: explicit type", func(t *testing.T) {
+		f, err := Parse("raven:email=ned@winterfell.example.com", models.FieldText)
+		assert.NoError(t, err)
+		assert.Equal(t, models.CustomField{Name: "raven", Type: models.FieldEmail, Value: "ned@winterfell.example.com"}, f)
+	})
+	t.Run("negative: no value", func(t *testing.T) {
+		_, err := Parse("pin", models.FieldText)
+		assert.ErrorIs(t, err, ErrInvalidField)
+	})
+}
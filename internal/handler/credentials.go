@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/ZnNr/GopherVault/internal/database"
+	"github.com/ZnNr/GopherVault/internal/fields"
 	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/urlmatch"
 	"io"
 	"net/http"
 )
@@ -30,14 +34,25 @@ func (h *handler) GetUserCredentialsHandler(w http.ResponseWriter, r *http.Reque
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	var listOptions models.ListOptions
+	if err = json.Unmarshal(body, &listOptions); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	// Получаем учетные данные пользователя из хранилища
-	creds, err := h.db.GetCredentials(ctx, userCredentialsRequest)
+	creds, nextCursor, err := h.db.GetCredentials(ctx, userCredentialsRequest, listOptions)
 	if err != nil {
 		message, status := handleUserError(userCredentialsRequest.UserName, err)
 		http.Error(w, message, status)
 		return
 	}
+	if nextCursor != "" {
+		w.Header().Set(models.NextCursorHeader, nextCursor)
+	}
+	if len(creds) == 1 {
+		w.Header().Set("ETag", models.ETag(creds[0].Revision))
+	}
 
 	// Формируем ответ
 	userCredentialsJSON, err := json.Marshal(creds)
@@ -79,6 +94,18 @@ func (h *handler) SaveUserCredentialsHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	// Проверяем пользовательские поля
+	if err := fields.Validate(requestCredentials.Fields); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Проверяем правила сопоставления адресов сайтов
+	if err := urlmatch.Validate(requestCredentials.URLs); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Сохраняем учетные данные пользователя в хранилище
 	if err := h.db.SaveCredentials(ctx, requestCredentials); err != nil {
 		message, status := handleUserError(requestCredentials.UserName, err)
@@ -115,8 +142,30 @@ func (h *handler) DeleteUserCredentialsHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// Ожидаемая клиентом ревизия секрета передается в заголовке If-Match
+	revision, err := ifMatchRevision(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if revision != 0 && userCredentialsRequest.Login == nil {
+		http.Error(w, "заголовок If-Match допустим только при удалении одних учетных данных", http.StatusBadRequest)
+		return
+	}
+	userCredentialsRequest.Revision = revision
+
 	// Удаляем учетные данные из хранилища
 	if err := h.db.DeleteCredentials(ctx, userCredentialsRequest); err != nil {
+		if errors.Is(err, database.ErrRevisionMismatch) {
+			current, revision := currentItem(h.db.GetCredentials(ctx, models.Credentials{UserName: userCredentialsRequest.UserName, Login: userCredentialsRequest.Login, Site: userCredentialsRequest.Site}, models.ListOptions{}))
+			writeRevisionConflict(w, userCredentialsRequest.UserName, current, revision)
+			return
+		}
+		// Секрета, ревизию которого проверяли, нет или он уже в корзине
+		if errors.Is(err, database.ErrNoData) {
+			http.Error(w, fmt.Sprintf("секрет пользователя %q не найден", userCredentialsRequest.UserName), http.StatusNotFound)
+			return
+		}
 		message, status := handleUserError(userCredentialsRequest.UserName, err)
 		http.Error(w, message, status)
 		return
@@ -159,14 +208,44 @@ func (h *handler) UpdateUserCredentialsHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// Ожидаемая клиентом ревизия секрета передается в заголовке If-Match
+	revision, err := ifMatchRevision(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	requestCredentials.Revision = revision
+
 	// Проверяем обязательные поля (логин и пароль)
 	if requestCredentials.Login == nil || requestCredentials.Password == nil {
 		http.Error(w, "login and password should not be empty", http.StatusBadRequest)
 		return
 	}
 
+	// Проверяем пользовательские поля
+	if err := fields.Validate(requestCredentials.Fields); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Проверяем правила сопоставления адресов сайтов
+	if err := urlmatch.Validate(requestCredentials.URLs); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Обновляем учетные данные пользователя в хранилище
 	if err := h.db.UpdateCredentials(ctx, requestCredentials); err != nil {
+		if errors.Is(err, database.ErrRevisionMismatch) {
+			current, revision := currentItem(h.db.GetCredentials(ctx, models.Credentials{UserName: requestCredentials.UserName, Login: requestCredentials.Login, Site: requestCredentials.Site}, models.ListOptions{}))
+			writeRevisionConflict(w, requestCredentials.UserName, current, revision)
+			return
+		}
+		// Секрет удален или переименован другим клиентом
+		if errors.Is(err, database.ErrNoData) {
+			http.Error(w, fmt.Sprintf("секрет пользователя %q не найден", requestCredentials.UserName), http.StatusNotFound)
+			return
+		}
 		message, status := handleUserError(requestCredentials.UserName, err)
 		http.Error(w, message, status)
 		return
@@ -181,3 +260,76 @@ func (h *handler) UpdateUserCredentialsHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 }
+
+// MatchUserCredentialsHandler обрабатывает запросы на поиск учетных данных, наиболее подходящих для адреса сайта
+func (h *handler) MatchUserCredentialsHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	// Адрес сайта передается в параметре url
+	target := r.URL.Query().Get("url")
+	if target == "" {
+		http.Error(w, "адрес сайта не должен быть пустым", http.StatusBadRequest)
+		return
+	}
+
+	// Извлекаем данные пользователя из тела запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var userCredentialsRequest models.Credentials
+	if err = json.Unmarshal(body, &userCredentialsRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Выбираем наиболее подходящие учетные данные по адресам сайтов: пароли и поля при этом не читаются
+	// и не расшифровываются, а время последнего чтения не изменяется
+	creds, _, err := h.db.GetCredentials(ctx, models.Credentials{UserName: userCredentialsRequest.UserName}, models.ListOptions{Projection: []string{"urls"}})
+	if err != nil {
+		message, status := handleUserError(userCredentialsRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+	best, ok, err := urlmatch.Best(creds, target)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("ошибка сопоставления адреса для пользователя %q: %s", userCredentialsRequest.UserName, err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		message, status := handleUserError(userCredentialsRequest.UserName, database.ErrNoData)
+		http.Error(w, message, status)
+		return
+	}
+
+	// Полностью читаем только выбранные учетные данные. Пустой сайт указывается явно, чтобы не выбрать
+	// учетные данные с тем же логином для другого сайта
+	site := ""
+	if best.Site != nil {
+		site = *best.Site
+	}
+	found, _, err := h.db.GetCredentials(ctx, models.Credentials{UserName: best.UserName, Login: best.Login, Site: &site}, models.ListOptions{})
+	if err != nil {
+		message, status := handleUserError(userCredentialsRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+	best = found[0]
+
+	// Формируем ответ
+	bestJSON, err := json.Marshal(best)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err = io.WriteString(w, string(bestJSON)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+const (
+	// eventsPollInterval период проверки журнала изменений для потока событий
+	eventsPollInterval = time.Second
+	// eventsKeepAlive период отправки комментария, который не дает прокси закрыть неактивное соединение
+	eventsKeepAlive = 15 * time.Second
+	// eventsRetry задержка переподключения клиента в миллисекундах
+	eventsRetry = 3000
+)
+
+// GetEventsHandler отправляет поток событий Server-Sent Events об изменениях секретов пользователя.
+// События содержат только тип, идентификатор, вид изменения и ревизию секрета, значения секретов не передаются.
+// Идентификатор события - номер изменения в журнале, поэтому клиент возобновляет поток с заголовком Last-Event-ID.
+// Без заголовка поток начинается с текущего изменения.
+func (h *handler) GetEventsHandler(w http.ResponseWriter, r *http.Request) {
+	// Поток событий не удерживает cookiesMu, чтобы не блокировать остальные запросы на время соединения
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	// Номер последнего полученного клиентом события передается в заголовке Last-Event-ID
+	var eventsRequest models.EventsRequest
+	if value := r.Header.Get("Last-Event-ID"); value != "" {
+		after, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || after < 0 {
+			http.Error(w, "заголовок Last-Event-ID должен быть неотрицательным числом", http.StatusBadRequest)
+			return
+		}
+		eventsRequest.After = &after
+	}
+
+	// Извлекаем имя пользователя из тела запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err = json.Unmarshal(body, &eventsRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "потоковая передача не поддерживается", http.StatusInternalServerError)
+		return
+	}
+
+	// Получаем первые события до отправки заголовков, чтобы вернуть ошибку хранилища обычным ответом
+	events, err := h.db.GetEvents(ctx, eventsRequest)
+	if err != nil {
+		message, status := handleUserError(eventsRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if _, err = fmt.Fprintf(w, "retry: %d\n\n", eventsRetry); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	poll := time.NewTicker(h.eventsPoll)
+	defer poll.Stop()
+	lastWrite := time.Now()
+	for {
+		if events.Reset {
+			// Журнал сервера начат заново: клиент должен выполнить полную синхронизацию
+			events.Events = []models.Event{{Seq: events.Seq, Action: models.ChangeReset, ChangedAt: time.Now().UTC()}}
+		}
+		if eventsRequest.After == nil {
+			seq := events.Seq
+			eventsRequest.After = &seq
+		}
+		for _, event := range events.Events {
+			if err = writeEvent(w, event); err != nil {
+				return
+			}
+			seq := event.Seq
+			eventsRequest.After = &seq
+		}
+		if len(events.Events) > 0 {
+			flusher.Flush()
+			lastWrite = time.Now()
+		}
+
+		// Если получены события, сразу проверяем, нет ли следующих, иначе ждем следующей проверки журнала
+		if len(events.Events) == 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-poll.C:
+			}
+			if time.Since(lastWrite) >= eventsKeepAlive {
+				if _, err = io.WriteString(w, ": keep-alive\n\n"); err != nil {
+					return
+				}
+				flusher.Flush()
+				lastWrite = time.Now()
+			}
+		}
+
+		if events, err = h.db.GetEvents(ctx, eventsRequest); err != nil {
+			if ctx.Err() == nil {
+				h.log.Errorf("ошибка при получении событий для пользователя %q: %v", eventsRequest.UserName, err)
+			}
+			return
+		}
+	}
+}
+
+// writeEvent записывает событие в формате Server-Sent Events
+func writeEvent(w io.Writer, event models.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Action, data)
+	return err
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/models/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestHandler_GetEvents(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "bran"
+	systemPassword := "hodor"
+	changedAt := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	after := func(seq int64) *int64 {
+		return &seq
+	}
+
+	type storageCall struct {
+		request  models.EventsRequest
+		response models.EventsResponse
+		err      error
+		repeated bool
+	}
+	testCases := []struct {
+		name         string
+		lastEventID  string
+		storageCalls []storageCall
+		expectedCode int
+		events       int
+		expectedBody string
+	}{
+		{
+			name:        "positive: resume after last event id",
+			lastEventID: "7",
+			storageCalls: []storageCall{
+				{request: models.EventsRequest{UserName: userName, After: after(7)}, response: models.EventsResponse{Seq: 9, Events: []models.Event{
+					{Seq: 8, Type: models.SecretCredentials, SecretID: 3, Action: models.ChangeDeleted, Revision: 2, ChangedAt: changedAt},
+					{Seq: 9, Type: models.SecretNote, SecretID: 5, Action: models.ChangeUpdated, Revision: 4, ChangedAt: changedAt},
+				}}},
+				{request: models.EventsRequest{UserName: userName, After: after(9)}, response: models.EventsResponse{Seq: 9}, repeated: true},
+			},
+			expectedCode: http.StatusOK,
+			events:       2,
+			expectedBody: "retry: 3000\n\n" +
+				"id: 8\nevent: deleted\ndata: {\"seq\":8,\"type\":\"credentials\",\"id\":3,\"action\":\"deleted\",\"revision\":2,\"changed_at\":\"2024-05-02T10:00:00Z\"}\n\n" +
+				"id: 9\nevent: updated\ndata: {\"seq\":9,\"type\":\"note\",\"id\":5,\"action\":\"updated\",\"revision\":4,\"changed_at\":\"2024-05-02T10:00:00Z\"}\n\n",
+		},
+		{
+			name: "positive: new stream starts from the current change",
+			storageCalls: []storageCall{
+				{request: models.EventsRequest{UserName: userName}, response: models.EventsResponse{Seq: 9}},
+				{request: models.EventsRequest{UserName: userName, After: after(9)}, response: models.EventsResponse{Seq: 10, Events: []models.Event{
+					{Seq: 10, Type: models.SecretCard, SecretID: 1, Action: models.ChangeCreated, Revision: 1, ChangedAt: changedAt},
+				}}},
+				{request: models.EventsRequest{UserName: userName, After: after(10)}, response: models.EventsResponse{Seq: 10}, repeated: true},
+			},
+			expectedCode: http.StatusOK,
+			events:       1,
+			expectedBody: "retry: 3000\n\n" +
+				"id: 10\nevent: created\ndata: {\"seq\":10,\"type\":\"card\",\"id\":1,\"action\":\"created\",\"revision\":1,\"changed_at\":\"2024-05-02T10:00:00Z\"}\n\n",
+		},
+		{
+			name:        "positive: event id ahead of the log sends reset",
+			lastEventID: "12",
+			storageCalls: []storageCall{
+				{request: models.EventsRequest{UserName: userName, After: after(12)}, response: models.EventsResponse{Seq: 4, Reset: true}},
+				{request: models.EventsRequest{UserName: userName, After: after(4)}, response: models.EventsResponse{Seq: 4}, repeated: true},
+			},
+			expectedCode: http.StatusOK,
+			events:       1,
+			expectedBody: "retry: 3000\n\nid: 4\nevent: reset\n",
+		},
+		{
+			name:         "negative: invalid last event id",
+			lastEventID:  "abc",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "заголовок Last-Event-ID должен быть неотрицательным числом",
+		},
+		{
+			name:        "negative: storage error",
+			lastEventID: "1",
+			storageCalls: []storageCall{
+				{request: models.EventsRequest{UserName: userName, After: after(1)}, err: errors.New("connection refused")},
+			},
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: "ошибка запроса пользователя \"bran\": connection refused",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			for _, call := range tt.storageCalls {
+				expectation := mockedStorage.On("GetEvents", mock.Anything, call.request).Return(call.response, call.err)
+				if call.repeated {
+					expectation.Maybe()
+				} else {
+					expectation.Once()
+				}
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			h.eventsPoll = 10 * time.Millisecond
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Get("/events", h.GetEventsHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			req := resty.New().SetAllowGetMethodPayload(true).R().
+				SetContext(ctx).
+				SetDoNotParseResponse(true).
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"user_name": %q}`, userName))
+			if tt.lastEventID != "" {
+				req.SetHeader("Last-Event-ID", tt.lastEventID)
+			}
+			resp, err := req.Get(fmt.Sprintf("%s/events", srv.URL))
+			require.NoError(t, err)
+			defer resp.RawBody().Close()
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			if tt.expectedCode != http.StatusOK {
+				body := new(strings.Builder)
+				_, _ = bufio.NewReader(resp.RawBody()).WriteTo(body)
+				assert.Equal(t, tt.expectedBody, strings.TrimSuffix(body.String(), "\n"))
+				return
+			}
+			assert.Equal(t, "text/event-stream", resp.Header().Get("Content-Type"))
+
+			// Читаем поток до получения ожидаемого числа событий
+			body := new(strings.Builder)
+			scanner := bufio.NewScanner(resp.RawBody())
+			for events := 0; events < tt.events && scanner.Scan(); {
+				body.WriteString(scanner.Text() + "\n")
+				if strings.HasPrefix(scanner.Text(), "data: ") {
+					events++
+				}
+				if events == tt.events {
+					scanner.Scan()
+					body.WriteString(scanner.Text() + "\n")
+				}
+			}
+			require.NoError(t, scanner.Err())
+			assert.True(t, strings.HasPrefix(body.String(), tt.expectedBody), body.String())
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ZnNr/GopherVault/internal/database"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// SaveFolderHandler обрабатывает запросы на создание папки пользователя.
+// Родительские папки создаются автоматически.
+func (h *handler) SaveFolderHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	// Читаем тело запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Распаковываем данные из тела запроса в структуру Folder
+	var folderRequest models.Folder
+	if err = json.Unmarshal(body, &folderRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if folderRequest.Path == nil || *folderRequest.Path == "" {
+		http.Error(w, "путь папки не должен быть пустым", http.StatusBadRequest)
+		return
+	}
+
+	// Создаем папку в хранилище
+	if err = h.db.SaveFolder(ctx, folderRequest); err != nil {
+		message, status := handleUserError(folderRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	// Отправляем ответ клиенту
+	response := fmt.Sprintf("Папка %q для пользователя %q успешно создана", *folderRequest.Path, folderRequest.UserName)
+	if _, err = io.WriteString(w, response); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetFoldersHandler обрабатывает запросы на получение папок пользователя
+func (h *handler) GetFoldersHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	// Читаем тело запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Распаковываем данные из тела запроса в структуру Folder
+	var folderRequest models.Folder
+	if err = json.Unmarshal(body, &folderRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Получаем папки пользователя из хранилища
+	folders, err := h.db.GetFolders(ctx, folderRequest)
+	if err != nil {
+		message, status := handleUserError(folderRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	// Формируем ответ
+	foldersResponse, err := json.Marshal(folders)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err = io.WriteString(w, string(foldersResponse)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// DeleteFolderHandler обрабатывает запросы на удаление папок пользователя.
+// Секреты из удаленных папок остаются в хранилище.
+func (h *handler) DeleteFolderHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	// Читаем тело запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Распаковываем данные из тела запроса в структуру Folder
+	var folderRequest models.Folder
+	if err = json.Unmarshal(body, &folderRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Удаление всех папок пользователя требует подтверждения
+	if folderRequest.Path == nil && !confirmBulkDelete(w, r, folderRequest.UserName, "папки") {
+		return
+	}
+
+	// Удаляем папки пользователя из хранилища
+	if err = h.db.DeleteFolder(ctx, folderRequest); err != nil {
+		message, status := handleUserError(folderRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	response := fmt.Sprintf("Папки пользователя %q были успешно удалены", folderRequest.UserName)
+	if folderRequest.Path != nil {
+		response = fmt.Sprintf("Папка %q пользователя %q была успешно удалена", *folderRequest.Path, folderRequest.UserName)
+	}
+
+	// Отправляем ответ клиенту
+	if _, err = io.WriteString(w, response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetTagsHandler обрабатывает запросы на получение тегов пользователя
+func (h *handler) GetTagsHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	// Читаем тело запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Распаковываем данные из тела запроса в структуру Tag
+	var tagRequest models.Tag
+	if err = json.Unmarshal(body, &tagRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Получаем теги пользователя из хранилища
+	tags, err := h.db.GetTags(ctx, tagRequest)
+	if err != nil {
+		message, status := handleUserError(tagRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	// Формируем ответ
+	tagsResponse, err := json.Marshal(tags)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err = io.WriteString(w, string(tagsResponse)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// DeleteTagHandler обрабатывает запросы на удаление тегов пользователя.
+// Помеченные тегом секреты остаются в хранилище.
+func (h *handler) DeleteTagHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	// Читаем тело запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Распаковываем данные из тела запроса в структуру Tag
+	var tagRequest models.Tag
+	if err = json.Unmarshal(body, &tagRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Удаление всех тегов пользователя требует подтверждения
+	if tagRequest.Name == nil && !confirmBulkDelete(w, r, tagRequest.UserName, "теги") {
+		return
+	}
+
+	// Удаляем теги пользователя из хранилища
+	if err = h.db.DeleteTag(ctx, tagRequest); err != nil {
+		message, status := handleUserError(tagRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	response := fmt.Sprintf("Теги пользователя %q были успешно удалены", tagRequest.UserName)
+	if tagRequest.Name != nil {
+		response = fmt.Sprintf("Тег %q пользователя %q был успешно удален", *tagRequest.Name, tagRequest.UserName)
+	}
+
+	// Отправляем ответ клиенту
+	if _, err = io.WriteString(w, response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// OrganizeSecretHandler обрабатывает запросы на перемещение сохраненного секрета в другую папку
+// и изменение его тегов
+func (h *handler) OrganizeSecretHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	// Читаем тело запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Распаковываем данные из тела запроса в структуру OrganizeRequest
+	var organizeRequest models.OrganizeRequest
+	if err = json.Unmarshal(body, &organizeRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validateOrganizeRequest(organizeRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Перемещаем секрет и изменяем его теги
+	if err = h.db.OrganizeSecret(ctx, organizeRequest); err != nil {
+		if errors.Is(err, database.ErrNoData) {
+			http.Error(w, fmt.Sprintf("секрет пользователя %q не найден", organizeRequest.UserName), http.StatusNotFound)
+			return
+		}
+		message, status := handleUserError(organizeRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	// Отправляем ответ клиенту
+	response := fmt.Sprintf("Папка и теги секрета %d пользователя %q успешно изменены", organizeRequest.SecretID, organizeRequest.UserName)
+	if _, err = io.WriteString(w, response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// validateOrganizeRequest проверяет тип и идентификатор секрета и названия тегов.
+// Запрос должен изменять папку или теги секрета.
+func validateOrganizeRequest(request models.OrganizeRequest) error {
+	switch request.Type {
+	case models.SecretCredentials, models.SecretNote, models.SecretCard:
+	default:
+		return fmt.Errorf("неизвестный тип секрета %q", request.Type)
+	}
+	if request.SecretID <= 0 {
+		return errors.New("идентификатор секрета должен быть указан")
+	}
+	if request.Folder == nil && len(request.AddTags) == 0 && len(request.RemoveTags) == 0 {
+		return errors.New("укажите новую папку или изменяемые теги секрета")
+	}
+	for _, tag := range append(append([]string{}, request.AddTags...), request.RemoveTags...) {
+		if strings.TrimSpace(tag) == "" {
+			return errors.New("название тега не должно быть пустым")
+		}
+	}
+	return nil
+}
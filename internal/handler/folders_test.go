@@ -0,0 +1,390 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ZnNr/GopherVault/internal/database"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/models/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestHandler_SaveFolder(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "samwell"
+	systemPassword := "citadel"
+
+	testCases := []struct {
+		name                 string
+		body                 string
+		storageCall          bool
+		storageResponseError error
+		expectedCode         int
+		expectedBody         string
+	}{
+		{
+			name:         "positive: folder created",
+			body:         fmt.Sprintf(`{"user_name": %q, "path": "maesters/books"}`, userName),
+			storageCall:  true,
+			expectedCode: http.StatusOK,
+			expectedBody: `Папка "maesters/books" для пользователя "samwell" успешно создана`,
+		},
+		{
+			name:                 "negative: invalid path",
+			body:                 fmt.Sprintf(`{"user_name": %q, "path": "maesters/books"}`, userName),
+			storageCall:          true,
+			storageResponseError: database.ErrInvalidFolderPath,
+			expectedCode:         http.StatusBadRequest,
+			expectedBody:         "некорректный путь папки пользователя \"samwell\"",
+		},
+		{
+			name:         "negative: path is not provided",
+			body:         fmt.Sprintf(`{"user_name": %q}`, userName),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "путь папки не должен быть пустым",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			if tt.storageCall {
+				mockedStorage.On("SaveFolder", mock.Anything, models.Folder{UserName: userName, Path: Ptr("maesters/books")}).Return(tt.storageResponseError)
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/save/folder", h.SaveFolderHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(tt.body).
+				Post(fmt.Sprintf("%s/save/folder", srv.URL))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			assert.Equal(t, tt.expectedBody, resp.String())
+		})
+	}
+}
+
+func TestHandler_GetTags(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "gilly"
+	systemPassword := "craster"
+
+	testCases := []struct {
+		name                 string
+		storageResponse      []models.Tag
+		storageResponseError error
+		expectedCode         int
+		expectedBody         string
+	}{
+		{
+			name:            "positive: tags found",
+			storageResponse: []models.Tag{{UserName: userName, Name: Ptr("wildlings")}},
+			expectedCode:    http.StatusOK,
+			expectedBody:    `[{"user_name":"gilly","name":"wildlings"}]`,
+		},
+		{
+			name:                 "negative: no tags",
+			storageResponseError: database.ErrNoData,
+			expectedCode:         http.StatusNoContent,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			mockedStorage.On("GetTags", mock.Anything, models.Tag{UserName: userName}).Return(tt.storageResponse, tt.storageResponseError)
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/get/tag", h.GetTagsHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"user_name": %q}`, userName)).
+				Post(fmt.Sprintf("%s/get/tag", srv.URL))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, resp.String())
+			}
+		})
+	}
+}
+
+func TestHandler_OrganizeSecret(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "podrick"
+	systemPassword := "payne"
+	request := models.OrganizeRequest{UserName: userName, Type: models.SecretNote, SecretID: 4, Folder: Ptr("kingsguard"), AddTags: []string{"squire"}, RemoveTags: []string{"page"}}
+
+	testCases := []struct {
+		name                 string
+		body                 string
+		storageCall          bool
+		storageResponseError error
+		expectedCode         int
+		expectedBody         string
+	}{
+		{
+			name:         "positive: secret moved and tagged",
+			body:         fmt.Sprintf(`{"user_name": %q, "type": "note", "id": 4, "folder": "kingsguard", "add_tags": ["squire"], "remove_tags": ["page"]}`, userName),
+			storageCall:  true,
+			expectedCode: http.StatusOK,
+			expectedBody: `Папка и теги секрета 4 пользователя "podrick" успешно изменены`,
+		},
+		{
+			name:                 "negative: secret not found",
+			body:                 fmt.Sprintf(`{"user_name": %q, "type": "note", "id": 4, "folder": "kingsguard", "add_tags": ["squire"], "remove_tags": ["page"]}`, userName),
+			storageCall:          true,
+			storageResponseError: database.ErrNoData,
+			expectedCode:         http.StatusNotFound,
+			expectedBody:         `секрет пользователя "podrick" не найден`,
+		},
+		{
+			name:         "negative: unknown secret type",
+			body:         fmt.Sprintf(`{"user_name": %q, "type": "totp", "id": 4, "folder": "kingsguard"}`, userName),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `неизвестный тип секрета "totp"`,
+		},
+		{
+			name:         "negative: nothing to change",
+			body:         fmt.Sprintf(`{"user_name": %q, "type": "note", "id": 4}`, userName),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "укажите новую папку или изменяемые теги секрета",
+		},
+		{
+			name:         "negative: empty tag name",
+			body:         fmt.Sprintf(`{"user_name": %q, "type": "note", "id": 4, "add_tags": ["  "]}`, userName),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "название тега не должно быть пустым",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			if tt.storageCall {
+				mockedStorage.On("OrganizeSecret", mock.Anything, request).Return(tt.storageResponseError)
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/organize", h.OrganizeSecretHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(tt.body).
+				Post(fmt.Sprintf("%s/organize", srv.URL))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			assert.Equal(t, tt.expectedBody, resp.String())
+		})
+	}
+}
+
+func TestHandler_DeleteFolder(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "hodor"
+	systemPassword := "holdthedoor"
+
+	testCases := []struct {
+		name         string
+		body         string
+		query        string
+		request      models.Folder
+		storageCall  bool
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "positive: folder deleted",
+			body:         fmt.Sprintf(`{"user_name": %q, "path": "work"}`, userName),
+			request:      models.Folder{UserName: userName, Path: Ptr("work")},
+			storageCall:  true,
+			expectedCode: http.StatusOK,
+			expectedBody: `Папка "work" пользователя "hodor" была успешно удалена`,
+		},
+		{
+			name:         "positive: all folders deleted with confirmation",
+			body:         fmt.Sprintf(`{"user_name": %q}`, userName),
+			query:        "?all=true",
+			request:      models.Folder{UserName: userName},
+			storageCall:  true,
+			expectedCode: http.StatusOK,
+			expectedBody: `Папки пользователя "hodor" были успешно удалены`,
+		},
+		{
+			name:         "negative: all folders without confirmation",
+			body:         fmt.Sprintf(`{"user_name": %q}`, userName),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `не указан ключ удаления: будут удалены все папки пользователя "hodor"; для подтверждения передайте параметр all=true`,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			if tt.storageCall {
+				mockedStorage.On("DeleteFolder", mock.Anything, tt.request).Return(nil)
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/delete/folder", h.DeleteFolderHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(tt.body).
+				Post(fmt.Sprintf("%s/delete/folder%s", srv.URL, tt.query))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			assert.Equal(t, tt.expectedBody, resp.String())
+		})
+	}
+}
+
+func TestHandler_DeleteTag(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "meera"
+	systemPassword := "reed"
+
+	testCases := []struct {
+		name         string
+		body         string
+		query        string
+		request      models.Tag
+		storageCall  bool
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "positive: tag deleted",
+			body:         fmt.Sprintf(`{"user_name": %q, "name": "urgent"}`, userName),
+			request:      models.Tag{UserName: userName, Name: Ptr("urgent")},
+			storageCall:  true,
+			expectedCode: http.StatusOK,
+			expectedBody: `Тег "urgent" пользователя "meera" был успешно удален`,
+		},
+		{
+			name:         "positive: all tags deleted with confirmation",
+			body:         fmt.Sprintf(`{"user_name": %q}`, userName),
+			query:        "?all=true",
+			request:      models.Tag{UserName: userName},
+			storageCall:  true,
+			expectedCode: http.StatusOK,
+			expectedBody: `Теги пользователя "meera" были успешно удалены`,
+		},
+		{
+			name:         "negative: all tags without confirmation",
+			body:         fmt.Sprintf(`{"user_name": %q}`, userName),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `не указан ключ удаления: будут удалены все теги пользователя "meera"; для подтверждения передайте параметр all=true`,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			if tt.storageCall {
+				mockedStorage.On("DeleteTag", mock.Anything, tt.request).Return(nil)
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/delete/tag", h.DeleteTagHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(tt.body).
+				Post(fmt.Sprintf("%s/delete/tag%s", srv.URL, tt.query))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			assert.Equal(t, tt.expectedBody, resp.String())
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/passgen"
+)
+
+// generateRequest описывает запрос на генерацию пароля: имя пользователя и параметры генерации
+type generateRequest struct {
+	models.UserBase
+	passgen.Options
+}
+
+// GeneratePasswordHandler обрабатывает запросы на генерацию пароля. Незаданные параметры берутся из политики паролей
+// сервера, параметры слабее политики отклоняются.
+func (h *handler) GeneratePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	// Читаем тело запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var request generateRequest
+	if err = json.Unmarshal(body, &request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Дополняем параметры значениями по умолчанию политики и генерируем пароль
+	opts, err := h.policy.Apply(request.Options)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result, err := passgen.Generate(opts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Формируем ответ
+	generateResponse, err := json.Marshal(result)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err = io.WriteString(w, string(generateResponse)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.log.Infof("для пользователя %q сгенерирован пароль в режиме %s", request.UserName, result.Mode)
+}
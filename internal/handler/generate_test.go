@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ZnNr/GopherVault/internal/models/mocks"
+	"github.com/ZnNr/GopherVault/internal/passgen"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestHandler_GeneratePassword(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "tyrion"
+	systemPassword := "lannister"
+
+	testCases := []struct {
+		name         string
+		body         string
+		expectedCode int
+		expectedBody string
+		check        func(t *testing.T, res passgen.Result)
+	}{
+		{
+			name:         "positive: policy defaults",
+			body:         fmt.Sprintf(`{"user_name": %q}`, userName),
+			expectedCode: http.StatusOK,
+			check: func(t *testing.T, res passgen.Result) {
+				assert.Equal(t, passgen.ModeRandom, res.Mode)
+				assert.Len(t, res.Password, 24)
+			},
+		},
+		{
+			name:         "positive: diceware passphrase",
+			body:         fmt.Sprintf(`{"user_name": %q, "mode": "diceware", "separator": "."}`, userName),
+			expectedCode: http.StatusOK,
+			check: func(t *testing.T, res passgen.Result) {
+				assert.Len(t, strings.Split(res.Password, "."), 7)
+			},
+		},
+		{
+			name:         "negative: shorter than policy",
+			body:         fmt.Sprintf(`{"user_name": %q, "length": 8}`, userName),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "generator options violate password policy: длина пароля 8 меньше минимальной 16",
+		},
+		{
+			name:         "negative: unknown mode",
+			body:         fmt.Sprintf(`{"user_name": %q, "mode": "emoji"}`, userName),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `invalid generator options: неизвестный режим "emoji", используйте random, diceware или pronounceable`,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			policy := passgen.NewPolicy(16, 7)
+			policy.DefaultLength = 24
+			h.SetPasswordPolicy(policy)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/generate", h.GeneratePasswordHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(tt.body).
+				Post(fmt.Sprintf("%s/generate", srv.URL))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, strings.TrimSpace(string(resp.Body())))
+			}
+			if tt.check != nil {
+				var res passgen.Result
+				assert.NoError(t, json.Unmarshal(resp.Body(), &res))
+				tt.check(t, res)
+			}
+		})
+	}
+}
@@ -3,8 +3,12 @@ package handler
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/ZnNr/GopherVault/internal/database"
+	"github.com/ZnNr/GopherVault/internal/fields"
 	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/passgen"
 	"sync"
 	"time"
 
@@ -21,17 +25,28 @@ type handler struct {
 	log       *zap.SugaredLogger
 	cookiesMu sync.Mutex
 	cookies   map[string]string
+	// eventsPoll период проверки журнала изменений для потока событий
+	eventsPoll time.Duration
+	// policy политика паролей для генерации паролей
+	policy passgen.Policy
 }
 
 func New(db models.Storage, log *zap.SugaredLogger) *handler {
 	return &handler{
-		db:        db,
-		log:       log,
-		cookiesMu: sync.Mutex{},
-		cookies:   make(map[string]string),
+		db:         db,
+		log:        log,
+		cookiesMu:  sync.Mutex{},
+		cookies:    make(map[string]string),
+		eventsPoll: eventsPollInterval,
+		policy:     passgen.DefaultPolicy,
 	}
 }
 
+// SetPasswordPolicy задает политику паролей, по которой генерируются пароли
+func (h *handler) SetPasswordPolicy(policy passgen.Policy) {
+	h.policy = policy
+}
+
 // LoginHandler обрабатывает запросы на аутентификацию пользователей
 func (h *handler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	h.cookiesMu.Lock()
@@ -181,6 +196,12 @@ func (h *handler) SaveCardHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Проверяем пользовательские поля
+	if err := fields.Validate(requestCard.Fields); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Сохраняем карточку пользователя в хранилище goph-keeper
 	if err := h.db.SaveCard(ctx, requestCard); err != nil {
 		message, status := handleUserError(requestCard.UserName, err)
@@ -220,13 +241,26 @@ func (h *handler) GetCardHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Распаковываем параметры постраничного получения
+	var listOptions models.ListOptions
+	if err := json.Unmarshal(body, &listOptions); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Получаем карточки пользователя из хранилища goph-keeper
-	cards, err := h.db.GetCard(ctx, cardRequest)
+	cards, nextCursor, err := h.db.GetCard(ctx, cardRequest, listOptions)
 	if err != nil {
 		message, status := handleUserError(cardRequest.UserName, err)
 		http.Error(w, message, status)
 		return
 	}
+	if nextCursor != "" {
+		w.Header().Set(models.NextCursorHeader, nextCursor)
+	}
+	if len(cards) == 1 {
+		w.Header().Set("ETag", models.ETag(cards[0].Revision))
+	}
 
 	// Формируем ответ
 	cardsResponse, err := json.Marshal(cards)
@@ -242,6 +276,75 @@ func (h *handler) GetCardHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// UpdateCardHandler обрабатывает запросы на обновление карточки пользователя
+func (h *handler) UpdateCardHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Создаем контекст для запроса
+	ctx := r.Context()
+
+	// Читаем тело запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Распаковываем данные из тела запроса в структуру Card
+	var cardRequest models.Card
+	if err = json.Unmarshal(body, &cardRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Ожидаемая клиентом ревизия секрета передается в заголовке If-Match
+	revision, err := ifMatchRevision(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cardRequest.Revision = revision
+
+	// Проверяем, что номер карты указан
+	if cardRequest.Number == nil {
+		http.Error(w, "number should not be empty", http.StatusBadRequest)
+		return
+	}
+
+	// Проверяем пользовательские поля
+	if err = fields.Validate(cardRequest.Fields); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Обновляем карточку пользователя в хранилище goph-keeper
+	if err = h.db.UpdateCard(ctx, cardRequest); err != nil {
+		if errors.Is(err, database.ErrRevisionMismatch) {
+			current, revision := currentItem(h.db.GetCard(ctx, models.Card{UserName: cardRequest.UserName, Number: cardRequest.Number}, models.ListOptions{}))
+			writeRevisionConflict(w, cardRequest.UserName, current, revision)
+			return
+		}
+		// Секрет удален другим клиентом
+		if errors.Is(err, database.ErrNoData) {
+			http.Error(w, fmt.Sprintf("секрет пользователя %q не найден", cardRequest.UserName), http.StatusNotFound)
+			return
+		}
+		message, status := handleUserError(cardRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	// Формируем ответ
+	response := fmt.Sprintf("Карточка для пользователя %q успешно обновлена", cardRequest.UserName)
+
+	// Отправляем ответ клиенту
+	if _, err = io.WriteString(w, response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
 // DeleteCardHandler обрабатывает запросы на удаление карточек пользователя
 func (h *handler) DeleteCardHandler(w http.ResponseWriter, r *http.Request) {
 	h.cookiesMu.Lock()
@@ -264,8 +367,30 @@ func (h *handler) DeleteCardHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Ожидаемая клиентом ревизия секрета передается в заголовке If-Match
+	revision, err := ifMatchRevision(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if revision != 0 && cardRequest.Number == nil {
+		http.Error(w, "заголовок If-Match допустим только при удалении одной карты", http.StatusBadRequest)
+		return
+	}
+	cardRequest.Revision = revision
+
 	// Удаляем карточки пользователя из хранилища goph-keeper
 	if err = h.db.DeleteCards(ctx, cardRequest); err != nil {
+		if errors.Is(err, database.ErrRevisionMismatch) {
+			current, revision := currentItem(h.db.GetCard(ctx, models.Card{UserName: cardRequest.UserName, Number: cardRequest.Number}, models.ListOptions{}))
+			writeRevisionConflict(w, cardRequest.UserName, current, revision)
+			return
+		}
+		// Секрета, ревизию которого проверяли, нет или он уже в корзине
+		if errors.Is(err, database.ErrNoData) {
+			http.Error(w, fmt.Sprintf("секрет пользователя %q не найден", cardRequest.UserName), http.StatusNotFound)
+			return
+		}
 		message, status := handleUserError(cardRequest.UserName, err)
 		http.Error(w, message, status)
 		return
@@ -207,7 +207,7 @@ func TestHandler_GetUserCredentials(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockedStorage := mocks.NewStorage(t)
 			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
-			mockedStorage.On("GetCredentials", mock.Anything, models.Credentials{UserName: userName}).Return(tt.storageResponse, tt.storageResponseError)
+			mockedStorage.On("GetCredentials", mock.Anything, models.Credentials{UserName: userName}, models.ListOptions{}).Return(tt.storageResponse, "", tt.storageResponseError)
 
 			r := chi.NewRouter()
 			h := New(mockedStorage, log)
@@ -328,6 +328,12 @@ func TestHandler_SaveUserCredentials(t *testing.T) {
 			storageResponseError: errors.New("save error"),
 			expectedBody:         `ошибка запроса пользователя "shae": save error`,
 		},
+		{
+			name:                 "negative: credentials already exist",
+			expectedCode:         http.StatusConflict,
+			storageResponseError: &database.ConflictError{Constraint: "credentials_user_login_site_key"},
+			expectedBody:         `запись пользователя "shae" с таким ключом уже существует`,
+		},
 	}
 	for _, tt := range testCases {
 		t.Run(tt.name, func(t *testing.T) {
@@ -395,6 +401,39 @@ func TestHandler_SaveUserCredentials(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, resp.StatusCode(), http.StatusBadRequest)
 	})
+	t.Run("negative: invalid url match rules", func(t *testing.T) {
+		mockedStorage := mocks.NewStorage(t)
+		mockedStorage.On("Register", mock.Anything, systemName, systemPassword).Return(nil)
+
+		r := chi.NewRouter()
+		h := New(mockedStorage, log)
+		r.Post("/auth/register", h.RegisterHandler)
+		r.Group(func(r chi.Router) {
+			r.Use(h.CheckAuthorization)
+			r.Post("/save/credentials", h.SaveUserCredentialsHandler)
+		})
+		srv := httptest.NewServer(r)
+		defer srv.Close()
+
+		_, err := resty.New().R().
+			SetHeader("content-type", "application/json").
+			SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, systemName, systemPassword)).
+			Post(fmt.Sprintf("%s/auth/register", srv.URL))
+		assert.NoError(t, err)
+
+		for _, urls := range []string{
+			`[{"url": "example.com", "match": "prefix"}]`,
+			`[{"url": "https://([a-z", "match": "regex"}]`,
+		} {
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"user_name": %q, "login": %q, "password": %q, "urls": %s}`, systemName, loginName, password, urls)).
+				Post(fmt.Sprintf("%s/save/credentials", srv.URL))
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode(), urls)
+		}
+	})
 }
 
 func TestHandler_DeleteUserCredentials(t *testing.T) {
@@ -573,6 +612,177 @@ func TestHandler_UpdateUserCredentials(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, resp.StatusCode(), http.StatusBadRequest)
 	})
+	t.Run("negative: invalid url match rules", func(t *testing.T) {
+		mockedStorage := mocks.NewStorage(t)
+		mockedStorage.On("Register", mock.Anything, systemName, systemPassword).Return(nil)
+
+		r := chi.NewRouter()
+		h := New(mockedStorage, log)
+		r.Post("/auth/register", h.RegisterHandler)
+		r.Group(func(r chi.Router) {
+			r.Use(h.CheckAuthorization)
+			r.Post("/update/credentials", h.UpdateUserCredentialsHandler)
+		})
+		srv := httptest.NewServer(r)
+		defer srv.Close()
+
+		_, err := resty.New().R().
+			SetHeader("content-type", "application/json").
+			SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, systemName, systemPassword)).
+			Post(fmt.Sprintf("%s/auth/register", srv.URL))
+		assert.NoError(t, err)
+
+		for _, urls := range []string{
+			`[{"url": "example.com", "match": "prefix"}]`,
+			`[{"url": "https://([a-z", "match": "regex"}]`,
+		} {
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"user_name": %q, "login": %q, "password": %q, "urls": %s}`, systemName, loginName, password, urls)).
+				Post(fmt.Sprintf("%s/update/credentials", srv.URL))
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode(), urls)
+		}
+	})
+}
+
+func TestHandler_MatchUserCredentials(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "olenna"
+	systemPassword := "thorns"
+	// Адреса выбираются без паролей, а полностью читаются только выбранные учетные данные
+	storedCredentials := []models.Credentials{
+		{
+			UserName: userName,
+			Login:    Ptr("highgarden"),
+			URLs:     []models.CredentialURL{{URL: "https://www.highgarden.com"}},
+		},
+		{
+			UserName: userName,
+			Login:    Ptr("reach"),
+			URLs:     []models.CredentialURL{{URL: "mail.highgarden.com", Match: models.MatchHost}},
+		},
+	}
+	fullCredentials := map[string]models.Credentials{
+		"highgarden": {
+			UserName: userName,
+			Login:    Ptr("highgarden"),
+			Password: Ptr("roses"),
+			URLs:     []models.CredentialURL{{URL: "https://www.highgarden.com"}},
+		},
+		"reach": {
+			UserName: userName,
+			Login:    Ptr("reach"),
+			Password: Ptr("tyrell"),
+			URLs:     []models.CredentialURL{{URL: "mail.highgarden.com", Match: models.MatchHost}},
+		},
+	}
+
+	testCases := []struct {
+		name                 string
+		url                  string
+		storageResponse      []models.Credentials
+		storageResponseError error
+		matchedLogin         string
+		expectedCode         int
+		expectedBody         string
+	}{
+		{
+			name:            "positive: exact host is preferred",
+			url:             "https://mail.highgarden.com/inbox",
+			storageResponse: storedCredentials,
+			matchedLogin:    "reach",
+			expectedCode:    http.StatusOK,
+			expectedBody:    `{"user_name":"olenna","login":"reach","password":"tyrell","urls":[{"url":"mail.highgarden.com","match":"host"}]}`,
+		},
+		{
+			name:            "positive: base domain match",
+			url:             "https://shop.highgarden.com",
+			storageResponse: storedCredentials,
+			matchedLogin:    "highgarden",
+			expectedCode:    http.StatusOK,
+			expectedBody:    `{"user_name":"olenna","login":"highgarden","password":"roses","urls":[{"url":"https://www.highgarden.com"}]}`,
+		},
+		{
+			name:            "negative: no matching credentials",
+			url:             "https://kingslanding.com",
+			storageResponse: storedCredentials,
+			expectedCode:    http.StatusNoContent,
+		},
+		{
+			name:                 "negative: no data for user",
+			url:                  "https://kingslanding.com",
+			storageResponseError: database.ErrNoData,
+			expectedCode:         http.StatusNoContent,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			mockedStorage.On("GetCredentials", mock.Anything, models.Credentials{UserName: userName}, models.ListOptions{Projection: []string{"urls"}}).Return(tt.storageResponse, "", tt.storageResponseError)
+			if tt.matchedLogin != "" {
+				mockedStorage.On("GetCredentials", mock.Anything, models.Credentials{UserName: userName, Login: Ptr(tt.matchedLogin), Site: Ptr("")}, models.ListOptions{}).
+					Return([]models.Credentials{fullCredentials[tt.matchedLogin]}, "", nil)
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/credentials/match", h.MatchUserCredentialsHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetQueryParam("url", tt.url).
+				SetBody(fmt.Sprintf(`{"user_name": %q}`, userName)).
+				Post(fmt.Sprintf("%s/credentials/match", srv.URL))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			assert.Equal(t, tt.expectedBody, resp.String())
+		})
+	}
+	t.Run("negative: url is not provided", func(t *testing.T) {
+		mockedStorage := mocks.NewStorage(t)
+		mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+
+		r := chi.NewRouter()
+		h := New(mockedStorage, log)
+		r.Post("/auth/register", h.RegisterHandler)
+		r.Group(func(r chi.Router) {
+			r.Use(h.CheckAuthorization)
+			r.Post("/credentials/match", h.MatchUserCredentialsHandler)
+		})
+		srv := httptest.NewServer(r)
+		defer srv.Close()
+
+		_, err := resty.New().R().
+			SetHeader("content-type", "application/json").
+			SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+			Post(fmt.Sprintf("%s/auth/register", srv.URL))
+		assert.NoError(t, err)
+
+		resp, err := resty.New().R().
+			SetHeader("content-type", "application/json").
+			SetBody(fmt.Sprintf(`{"user_name": %q}`, userName)).
+			Post(fmt.Sprintf("%s/credentials/match", srv.URL))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	})
 }
 
 func TestHandler_SaveUserNote(t *testing.T) {
@@ -671,6 +881,34 @@ func TestHandler_SaveUserNote(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, resp.StatusCode(), http.StatusBadRequest)
 	})
+	t.Run("negative: invalid custom field", func(t *testing.T) {
+		mockedStorage := mocks.NewStorage(t)
+		mockedStorage.On("Register", mock.Anything, systemName, systemPassword).Return(nil)
+
+		r := chi.NewRouter()
+		h := New(mockedStorage, log)
+		r.Post("/auth/register", h.RegisterHandler)
+		r.Group(func(r chi.Router) {
+			r.Use(h.CheckAuthorization)
+			r.Post("/save/note", h.SaveUserNoteHandler)
+		})
+		srv := httptest.NewServer(r)
+		defer srv.Close()
+
+		_, err := resty.New().R().
+			SetHeader("content-type", "application/json").
+			SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, systemName, systemPassword)).
+			Post(fmt.Sprintf("%s/auth/register", srv.URL))
+		assert.NoError(t, err)
+
+		resp, err := resty.New().R().
+			SetHeader("content-type", "application/json").
+			SetBody(fmt.Sprintf(`{"user_name": %q, "title": %q, "content": %q, "fields": [{"name": "born", "type": "date", "value": "yesterday"}]}`, systemName, title, content)).
+			Post(fmt.Sprintf("%s/save/note", srv.URL))
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	})
 }
 
 func TestHandler_GetUserNote(t *testing.T) {
@@ -715,7 +953,7 @@ func TestHandler_GetUserNote(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockedStorage := mocks.NewStorage(t)
 			mockedStorage.On("Register", mock.Anything, systemName, systemPassword).Return(nil)
-			mockedStorage.On("GetNotes", mock.Anything, models.Note{UserName: systemName}).Return(tt.storageResponse, tt.storageResponseError)
+			mockedStorage.On("GetNotes", mock.Anything, models.Note{UserName: systemName}, models.ListOptions{}).Return(tt.storageResponse, "", tt.storageResponseError)
 
 			r := chi.NewRouter()
 			h := New(mockedStorage, log)
@@ -1096,7 +1334,7 @@ func TestHandler_GetCard(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockedStorage := mocks.NewStorage(t)
 			mockedStorage.On("Register", mock.Anything, systemName, systemPassword).Return(nil)
-			mockedStorage.On("GetCard", mock.Anything, models.Card{UserName: systemName}).Return(tt.storageResponse, tt.storageResponseError)
+			mockedStorage.On("GetCard", mock.Anything, models.Card{UserName: systemName}, models.ListOptions{}).Return(tt.storageResponse, "", tt.storageResponseError)
 
 			r := chi.NewRouter()
 			h := New(mockedStorage, log)
@@ -1265,3 +1503,182 @@ func TestHandler_DeleteCard(t *testing.T) {
 		assert.Equal(t, resp.String(), "Карты с номером \"0000888822227777\" принадлежащие пользователю \"hound\" были успешно удалены")
 	})
 }
+
+func TestHandler_UpdateCard(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+	systemName := "hound"
+	systemPassword := "ihavebadbrother"
+	number := "0000888822227777"
+	current := models.Card{UserName: systemName, BankName: Ptr("alpha"), Number: &number, Audit: models.Audit{Revision: 4}}
+
+	testCases := []struct {
+		name                 string
+		body                 string
+		ifMatch              string
+		storageCall          bool
+		storageRevision      int64
+		storageResponseError error
+		currentCall          bool
+		expectedCode         int
+		expectedBody         string
+	}{
+		{
+			name:            "positive: success updating card",
+			body:            fmt.Sprintf(`{"user_name": %q, "number": %q, "password": "chicken"}`, systemName, number),
+			ifMatch:         `"4"`,
+			storageCall:     true,
+			storageRevision: 4,
+			expectedCode:    http.StatusOK,
+			expectedBody:    `Карточка для пользователя "hound" успешно обновлена`,
+		},
+		{
+			name:         "negative: no number",
+			body:         fmt.Sprintf(`{"user_name": %q, "password": "chicken"}`, systemName),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "number should not be empty",
+		},
+		{
+			name:                 "negative: card is gone",
+			body:                 fmt.Sprintf(`{"user_name": %q, "number": %q, "password": "chicken"}`, systemName, number),
+			storageCall:          true,
+			storageResponseError: database.ErrNoData,
+			expectedCode:         http.StatusNotFound,
+			expectedBody:         "секрет пользователя \"hound\" не найден",
+		},
+		{
+			name:                 "negative: stale revision",
+			body:                 fmt.Sprintf(`{"user_name": %q, "number": %q, "password": "chicken"}`, systemName, number),
+			ifMatch:              `"3"`,
+			storageCall:          true,
+			storageRevision:      3,
+			storageResponseError: database.ErrRevisionMismatch,
+			currentCall:          true,
+			expectedCode:         http.StatusPreconditionFailed,
+			expectedBody: `{"message":"секрет пользователя \"hound\" был изменен или удален другим клиентом","revision":4,` +
+				`"current":{"user_name":"hound","bank_name":"alpha","number":"0000888822227777","revision":4}}`,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, systemName, systemPassword).Return(nil)
+			if tt.storageCall {
+				request := models.Card{UserName: systemName, Number: &number, Password: Ptr("chicken"), Audit: models.Audit{Revision: tt.storageRevision}}
+				mockedStorage.On("UpdateCard", mock.Anything, request).Return(tt.storageResponseError)
+			}
+			if tt.currentCall {
+				mockedStorage.On("GetCard", mock.Anything, models.Card{UserName: systemName, Number: &number}, models.ListOptions{}).
+					Return([]models.Card{current}, "", nil)
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/update/card", h.UpdateCardHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, systemName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			req := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(tt.body)
+			if tt.ifMatch != "" {
+				req.SetHeader("If-Match", tt.ifMatch)
+			}
+			resp, err := req.Post(fmt.Sprintf("%s/update/card", srv.URL))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			if tt.expectedCode == http.StatusPreconditionFailed {
+				assert.JSONEq(t, tt.expectedBody, resp.String())
+			} else {
+				assert.Equal(t, tt.expectedBody, resp.String())
+			}
+		})
+	}
+}
+
+func TestHandler_GetNotesPage(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "tyrion"
+	systemPassword := "lannister"
+
+	testCases := []struct {
+		name                 string
+		storageResponse      []models.Note
+		storageNextCursor    string
+		storageResponseError error
+		expectedCode         int
+		expectedBody         string
+		expectedCursor       string
+	}{
+		{
+			name:              "positive: page with next cursor",
+			storageResponse:   []models.Note{{UserName: userName, Title: Ptr("wine")}},
+			storageNextCursor: "next-page",
+			expectedCode:      http.StatusOK,
+			expectedBody:      `[{"user_name":"tyrion","title":"wine"}]`,
+			expectedCursor:    "next-page",
+		},
+		{
+			name:                 "negative: invalid list options",
+			storageResponseError: fmt.Errorf("%w: неизвестное поле сортировки", database.ErrInvalidListOptions),
+			expectedCode:         http.StatusBadRequest,
+			expectedBody:         `некорректные параметры списка для пользователя "tyrion": invalid list options: неизвестное поле сортировки`,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := models.ListOptions{Limit: 1, Cursor: "page", Sort: models.SortName, Desc: true, Projection: []string{"metadata"}}
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			mockedStorage.On("GetNotes", mock.Anything, models.Note{UserName: userName}, opts).
+				Return(tt.storageResponse, tt.storageNextCursor, tt.storageResponseError)
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/get/note", h.GetUserNoteHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"user_name": %q, "limit": 1, "cursor": "page", "sort": "name", "desc": true, "projection": ["metadata"]}`, userName)).
+				Post(fmt.Sprintf("%s/get/note", srv.URL))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			assert.Equal(t, tt.expectedCursor, resp.Header().Get(models.NextCursorHeader))
+			if tt.expectedCode == http.StatusOK {
+				assert.JSONEq(t, tt.expectedBody, resp.String())
+			} else {
+				assert.Equal(t, tt.expectedBody, resp.String())
+			}
+		})
+	}
+}
+
+func Ptr(s string) *string {
+	return &s
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ZnNr/GopherVault/internal/health"
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// HealthReportHandler обрабатывает запросы отчета о слабых, повторяющихся и давно не менявшихся паролях
+// и учетных данных без второго фактора
+func (h *handler) HealthReportHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	// Извлекаем параметры отчета из тела запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var healthRequest models.HealthRequest
+	if err = json.Unmarshal(body, &healthRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Составляем отчет по учетным данным из хранилища
+	report, err := health.Report(ctx, h.db, healthRequest, time.Now())
+	if errors.Is(err, health.ErrInvalidRequest) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		message, status := handleUserError(healthRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	// Формируем ответ
+	reportResponse, err := json.Marshal(report)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err = io.WriteString(w, string(reportResponse)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.log.Infof("для пользователя %q составлен отчет о состоянии %d паролей", healthRequest.UserName, report.Summary.Total)
+}
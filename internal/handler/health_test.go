@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ZnNr/GopherVault/internal/database"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/models/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestHandler_HealthReport(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "jon"
+	systemPassword := "snow"
+	id1, id2 := int64(1), int64(2)
+	updatedAt := time.Now().AddDate(-2, 0, 0)
+	credentials := []models.Credentials{
+		{UserName: userName, Login: Ptr("jon"), Site: Ptr("castle-black.org"), Password: Ptr("password"), Audit: models.Audit{ID: &id1, UpdatedAt: &updatedAt}},
+		{UserName: userName, Login: Ptr("lord"), Site: Ptr("nights-watch.org"), Password: Ptr("password"), Audit: models.Audit{ID: &id2, UpdatedAt: &updatedAt}},
+	}
+
+	testCases := []struct {
+		name         string
+		body         string
+		prepare      func(m *mocks.Storage)
+		expectedCode int
+		expectedBody string
+		check        func(t *testing.T, report models.HealthReport)
+	}{
+		{
+			name: "positive",
+			body: fmt.Sprintf(`{"user_name": %q}`, userName),
+			prepare: func(m *mocks.Storage) {
+				m.On("GetCredentials", mock.Anything, models.Credentials{UserName: userName}, models.ListOptions{}).Return(credentials, "", nil)
+				m.On("GetTOTP", mock.Anything, models.TOTP{UserName: userName}).Return(nil, database.ErrNoData)
+			},
+			expectedCode: http.StatusOK,
+			check: func(t *testing.T, report models.HealthReport) {
+				assert.Equal(t, models.HealthSummary{Total: 2, Weak: 2, Reused: 2, Old: 2, Missing2FA: 2}, report.Summary)
+				// Пароли в отчет не попадают
+				assert.NotContains(t, fmt.Sprint(report), "password")
+			},
+		},
+		{
+			name:         "negative: invalid min score",
+			body:         fmt.Sprintf(`{"user_name": %q, "min_score": 7}`, userName),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "invalid health report request: min_score должно быть от 1 до 4",
+		},
+		{
+			name: "negative: storage error",
+			body: fmt.Sprintf(`{"user_name": %q}`, userName),
+			prepare: func(m *mocks.Storage) {
+				m.On("GetCredentials", mock.Anything, models.Credentials{UserName: userName}, models.ListOptions{}).Return(nil, "", database.ErrNoSuchUser)
+			},
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			if tt.prepare != nil {
+				tt.prepare(mockedStorage)
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/reports/health", h.HealthReportHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(tt.body).
+				Post(fmt.Sprintf("%s/reports/health", srv.URL))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, strings.TrimSpace(string(resp.Body())))
+			}
+			if tt.check != nil {
+				var report models.HealthReport
+				assert.NoError(t, json.Unmarshal(resp.Body(), &report))
+				tt.check(t, report)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// parseHistoryRequest собирает запрос к истории секрета из пути и тела запроса.
+// История ведется только для учетных данных и заметок, так как остальные секреты не обновляются.
+func parseHistoryRequest(r *http.Request) (models.HistoryRequest, int, error) {
+	var historyRequest models.HistoryRequest
+
+	// Тип и идентификатор секрета передаются в пути запроса
+	secretType := models.SecretType(chi.URLParam(r, "type"))
+	switch secretType {
+	case models.SecretCredentials, models.SecretNote:
+	default:
+		return historyRequest, http.StatusBadRequest, fmt.Errorf("история секретов типа %q не ведется", secretType)
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return historyRequest, http.StatusBadRequest, fmt.Errorf("некорректный идентификатор секрета")
+	}
+
+	// Читаем тело запроса для получения имени пользователя и номера версии
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return historyRequest, http.StatusInternalServerError, err
+	}
+	if err = json.Unmarshal(body, &historyRequest); err != nil {
+		return historyRequest, http.StatusBadRequest, err
+	}
+	historyRequest.Type = secretType
+	historyRequest.SecretID = id
+	return historyRequest, http.StatusOK, nil
+}
+
+// GetHistoryHandler обрабатывает запросы на получение истории версий секрета
+func (h *handler) GetHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	historyRequest, status, err := parseHistoryRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	// Получаем версии секрета из хранилища
+	versions, err := h.db.GetHistory(ctx, historyRequest)
+	if err != nil {
+		message, status := handleUserError(historyRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	// Формируем ответ
+	historyResponse, err := json.Marshal(versions)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err = io.WriteString(w, string(historyResponse)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// RestoreVersionHandler обрабатывает запросы на восстановление версии секрета
+func (h *handler) RestoreVersionHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	historyRequest, status, err := parseHistoryRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if historyRequest.Version <= 0 {
+		http.Error(w, "номер версии должен быть указан", http.StatusBadRequest)
+		return
+	}
+
+	// Восстанавливаем версию секрета
+	if err = h.db.RestoreVersion(ctx, historyRequest); err != nil {
+		message, status := handleUserError(historyRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	// Отправляем сообщение об успешном восстановлении
+	response := fmt.Sprintf("Версия %d секрета %d для пользователя %q была успешно восстановлена", historyRequest.Version, historyRequest.SecretID, historyRequest.UserName)
+	if _, err = io.WriteString(w, response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
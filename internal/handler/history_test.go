@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ZnNr/GopherVault/internal/database"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/models/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestHandler_GetHistory(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "brienne"
+	systemPassword := "tarth"
+	changedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name                 string
+		path                 string
+		storageCall          bool
+		storageRequest       models.HistoryRequest
+		storageResponse      []models.SecretVersion
+		storageResponseError error
+		expectedCode         int
+		expectedBody         string
+	}{
+		{
+			name:           "positive: versions returned",
+			path:           "/history/note/4",
+			storageCall:    true,
+			storageRequest: models.HistoryRequest{UserName: userName, Type: models.SecretNote, SecretID: 4},
+			storageResponse: []models.SecretVersion{{
+				Type: models.SecretNote, SecretID: 4, Version: 2, ChangedBy: userName, ChangedAt: changedAt,
+				Changes: []string{"content"},
+				Note:    &models.Note{UserName: userName, Title: Ptr("oath"), Content: Ptr("keep the oath")},
+			}},
+			expectedCode: http.StatusOK,
+			expectedBody: `[{"type":"note","id":4,"version":2,"changed_by":"brienne","changed_at":"2024-03-01T12:00:00Z",` +
+				`"changes":["content"],"note":{"user_name":"brienne","title":"oath","content":"keep the oath"}}]`,
+		},
+		{
+			name:                 "negative: no history",
+			path:                 "/history/credentials/7",
+			storageCall:          true,
+			storageRequest:       models.HistoryRequest{UserName: userName, Type: models.SecretCredentials, SecretID: 7},
+			storageResponseError: database.ErrNoData,
+			expectedCode:         http.StatusNoContent,
+		},
+		{
+			name:         "negative: type without history",
+			path:         "/history/card/7",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `история секретов типа "card" не ведется`,
+		},
+		{
+			name:         "negative: bad id",
+			path:         "/history/note/oath",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "некорректный идентификатор секрета",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			if tt.storageCall {
+				mockedStorage.On("GetHistory", mock.Anything, tt.storageRequest).Return(tt.storageResponse, tt.storageResponseError)
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Get("/history/{type}/{id}", h.GetHistoryHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().SetAllowGetMethodPayload(true).R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"user_name": %q}`, userName)).
+				Get(srv.URL + tt.path)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			if tt.expectedCode == http.StatusOK {
+				assert.JSONEq(t, tt.expectedBody, resp.String())
+			} else if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, resp.String())
+			}
+		})
+	}
+}
+
+func TestHandler_RestoreVersion(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "brienne"
+	systemPassword := "tarth"
+
+	testCases := []struct {
+		name                 string
+		body                 string
+		storageCall          bool
+		storageResponseError error
+		expectedCode         int
+		expectedBody         string
+	}{
+		{
+			name:         "positive: version restored",
+			body:         fmt.Sprintf(`{"user_name": %q, "version": 2}`, userName),
+			storageCall:  true,
+			expectedCode: http.StatusOK,
+			expectedBody: `Версия 2 секрета 4 для пользователя "brienne" была успешно восстановлена`,
+		},
+		{
+			name:                 "negative: unknown version",
+			body:                 fmt.Sprintf(`{"user_name": %q, "version": 2}`, userName),
+			storageCall:          true,
+			storageResponseError: database.ErrNoData,
+			expectedCode:         http.StatusNoContent,
+		},
+		{
+			name:         "negative: version missing",
+			body:         fmt.Sprintf(`{"user_name": %q}`, userName),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "номер версии должен быть указан",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			if tt.storageCall {
+				mockedStorage.On("RestoreVersion", mock.Anything, models.HistoryRequest{
+					UserName: userName, Type: models.SecretNote, SecretID: 4, Version: 2,
+				}).Return(tt.storageResponseError)
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/history/{type}/{id}/restore", h.RestoreVersionHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(tt.body).
+				Post(fmt.Sprintf("%s/history/note/4/restore", srv.URL))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, resp.String())
+			}
+		})
+	}
+}
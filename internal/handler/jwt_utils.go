@@ -71,6 +71,17 @@ func createToken(username string, expiration time.Time) (string, error) {
 	return tokenString, nil
 }
 
+// confirmBulkDelete проверяет, что удаление всех секретов пользователя подтверждено параметром запроса all=true.
+// Без подтверждения отвечает клиенту ошибкой 400 и возвращает false.
+func confirmBulkDelete(w http.ResponseWriter, r *http.Request, userName, what string) bool {
+	if r.URL.Query().Get("all") == "true" {
+		return true
+	}
+	http.Error(w, fmt.Sprintf("не указан ключ удаления: будут удалены все %s пользователя %q; для подтверждения передайте параметр all=true",
+		what, userName), http.StatusBadRequest)
+	return false
+}
+
 // обработка ошибок, связанных с пользовательским запросом
 func handleUserError(userName string, err error) (string, int) {
 	switch {
@@ -80,6 +91,18 @@ func handleUserError(userName string, err error) (string, int) {
 		return fmt.Sprintf("предоставлен неверный пароль для пользователя %q", userName), http.StatusUnauthorized
 	case errors.Is(err, database.ErrUserAlreadyExists):
 		return fmt.Sprintf("логин %q уже занят", userName), http.StatusConflict
+	case errors.Is(err, database.ErrConflict):
+		return fmt.Sprintf("запись пользователя %q с таким ключом уже существует", userName), http.StatusConflict
+	case errors.Is(err, database.ErrNoSuchCredentials):
+		return fmt.Sprintf("учетные данные пользователя %q не найдены", userName), http.StatusNotFound
+	case errors.Is(err, database.ErrInvalidFolderPath):
+		return fmt.Sprintf("некорректный путь папки пользователя %q", userName), http.StatusBadRequest
+	case errors.Is(err, database.ErrInvalidTagName):
+		return fmt.Sprintf("название тега пользователя %q не должно быть пустым", userName), http.StatusBadRequest
+	case errors.Is(err, database.ErrInvalidListOptions):
+		return fmt.Sprintf("некорректные параметры списка для пользователя %q: %s", userName, err.Error()), http.StatusBadRequest
+	case errors.Is(err, database.ErrRevisionMismatch):
+		return fmt.Sprintf("секрет пользователя %q был изменен или удален другим клиентом", userName), http.StatusPreconditionFailed
 	case errors.Is(err, database.ErrNoData):
 		return fmt.Sprintf("нет данных для пользователя %q", userName), http.StatusNoContent
 	case errors.Is(err, jwt.ErrSignatureInvalid), errors.Is(err, jwt.ErrTokenExpired), errors.Is(err, ErrTokenIsEmpty), errors.Is(err, ErrNoToken):
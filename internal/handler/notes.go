@@ -4,7 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/ZnNr/GopherVault/internal/blindindex"
+	"github.com/ZnNr/GopherVault/internal/database"
+	"github.com/ZnNr/GopherVault/internal/fields"
 	"github.com/ZnNr/GopherVault/internal/models"
 	"io"
 	"net/http"
@@ -38,6 +42,12 @@ func (h *handler) SaveUserNoteHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Проверяем пользовательские поля
+	if err := fields.Validate(requestNote.Fields); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Сохраняем заметку пользователя в хранилище
 	if err := h.db.SaveNote(ctx, requestNote); err != nil {
 		message, status := handleUserError(requestNote.UserName, err)
@@ -77,13 +87,26 @@ func (h *handler) GetUserNoteHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Распаковываем параметры постраничного получения
+	var listOptions models.ListOptions
+	if err := json.Unmarshal(body, &listOptions); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Получаем заметку пользователя из хранилища goph-keeper
-	creds, err := h.db.GetNotes(ctx, userNotesRequest)
+	creds, nextCursor, err := h.db.GetNotes(ctx, userNotesRequest, listOptions)
 	if err != nil {
 		message, status := handleUserError(userNotesRequest.UserName, err)
 		http.Error(w, message, status)
 		return
 	}
+	if nextCursor != "" {
+		w.Header().Set(models.NextCursorHeader, nextCursor)
+	}
+	if len(creds) == 1 {
+		w.Header().Set("ETag", models.ETag(creds[0].Revision))
+	}
 
 	// Преобразуем данные заметки пользователя в формат JSON
 	notesResponse, err := json.Marshal(creds)
@@ -99,6 +122,57 @@ func (h *handler) GetUserNoteHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// SearchUserNotesHandler обрабатывает запросы на поиск заметок пользователя по словам содержимого.
+// Возвращаются заметки, содержащие все слова запроса.
+func (h *handler) SearchUserNotesHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Создаем контекст для запроса
+	ctx := r.Context()
+
+	// Читаем тело запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Распаковываем данные из тела запроса в структуру SearchRequest
+	var searchRequest models.SearchRequest
+	if err = json.Unmarshal(body, &searchRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Проверяем, что в запросе есть слова для поиска
+	if len(blindindex.Words(searchRequest.Query)) == 0 {
+		http.Error(w, "строка поиска не должна быть пустой", http.StatusBadRequest)
+		return
+	}
+
+	// Ищем заметки пользователя в хранилище
+	notes, err := h.db.SearchNotes(ctx, searchRequest)
+	if err != nil {
+		message, status := handleUserError(searchRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	// Преобразуем найденные заметки в формат JSON
+	notesResponse, err := json.Marshal(notes)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Отправляем ответ клиенту
+	if _, err = io.WriteString(w, string(notesResponse)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
 // DeleteUserNotesHandler обрабатывает запросы на удаление заметок пользователя
 func (h *handler) DeleteUserNotesHandler(w http.ResponseWriter, r *http.Request) {
 	h.cookiesMu.Lock()
@@ -121,8 +195,30 @@ func (h *handler) DeleteUserNotesHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Ожидаемая клиентом ревизия секрета передается в заголовке If-Match
+	revision, err := ifMatchRevision(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if revision != 0 && userNotesRequest.Title == nil {
+		http.Error(w, "заголовок If-Match допустим только при удалении одной заметки", http.StatusBadRequest)
+		return
+	}
+	userNotesRequest.Revision = revision
+
 	// Удаляем заметки пользователя из хранилища goph-keeper
 	if err = h.db.DeleteNotes(ctx, userNotesRequest); err != nil {
+		if errors.Is(err, database.ErrRevisionMismatch) {
+			current, revision := currentItem(h.db.GetNotes(ctx, models.Note{UserName: userNotesRequest.UserName, Title: userNotesRequest.Title}, models.ListOptions{}))
+			writeRevisionConflict(w, userNotesRequest.UserName, current, revision)
+			return
+		}
+		// Секрета, ревизию которого проверяли, нет или он уже в корзине
+		if errors.Is(err, database.ErrNoData) {
+			http.Error(w, fmt.Sprintf("секрет пользователя %q не найден", userNotesRequest.UserName), http.StatusNotFound)
+			return
+		}
 		message, status := handleUserError(userNotesRequest.UserName, err)
 		http.Error(w, message, status)
 		return
@@ -162,14 +258,38 @@ func (h *handler) UpdateUserNoteHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Ожидаемая клиентом ревизия секрета передается в заголовке If-Match
+	revision, err := ifMatchRevision(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	requestNote.Revision = revision
+
 	// Проверяем, что заголовок и содержимое заметки не пустые
 	if requestNote.Title == nil || requestNote.Content == nil {
 		http.Error(w, "title and content should not be empty", http.StatusBadRequest)
 		return
 	}
 
+	// Проверяем пользовательские поля
+	if err := fields.Validate(requestNote.Fields); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Обновляем заметку пользователя в хранилище goph-keeper
 	if err := h.db.UpdateNote(ctx, requestNote); err != nil {
+		if errors.Is(err, database.ErrRevisionMismatch) {
+			current, revision := currentItem(h.db.GetNotes(ctx, models.Note{UserName: requestNote.UserName, Title: requestNote.Title}, models.ListOptions{}))
+			writeRevisionConflict(w, requestNote.UserName, current, revision)
+			return
+		}
+		// Секрет удален или переименован другим клиентом
+		if errors.Is(err, database.ErrNoData) {
+			http.Error(w, fmt.Sprintf("секрет пользователя %q не найден", requestNote.UserName), http.StatusNotFound)
+			return
+		}
 		message, status := handleUserError(requestNote.UserName, err)
 		http.Error(w, message, status)
 		return
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// ifMatchRevision возвращает ревизию секрета из заголовка If-Match.
+// Если заголовок не указан или равен "*", возвращается 0 и ревизия не проверяется.
+func ifMatchRevision(r *http.Request) (int64, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+	revision, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(value, "W/"), `"`), 10, 64)
+	if err != nil || revision <= 0 {
+		return 0, fmt.Errorf("некорректный заголовок If-Match %q", value)
+	}
+	return revision, nil
+}
+
+// currentItem возвращает первый найденный секрет и его ревизию для ответа о конфликте ревизий.
+// Если секрет не найден, возвращается nil.
+func currentItem[T interface{ CurrentRevision() int64 }](items []T, _ string, err error) (interface{}, int64) {
+	if err != nil || len(items) == 0 {
+		return nil, 0
+	}
+	return items[0], items[0].CurrentRevision()
+}
+
+// writeRevisionConflict отправляет ответ 412 Precondition Failed с текущей версией секрета
+func writeRevisionConflict(w http.ResponseWriter, userName string, current interface{}, revision int64) {
+	conflict := models.RevisionConflict{
+		Message:  fmt.Sprintf("секрет пользователя %q был изменен или удален другим клиентом", userName),
+		Revision: revision,
+	}
+	if current != nil {
+		data, err := json.Marshal(current)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		conflict.Current = data
+	}
+	conflictResponse, err := json.Marshal(conflict)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if revision != 0 {
+		w.Header().Set("ETag", models.ETag(revision))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusPreconditionFailed)
+	_, _ = w.Write(conflictResponse)
+}
@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ZnNr/GopherVault/internal/database"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/models/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestHandler_UpdateNoteIfMatch(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "davos"
+	systemPassword := "onionknight"
+	body := fmt.Sprintf(`{"user_name": %q, "title": "onions", "content": "new content", "revision": 9}`, userName)
+	current := models.Note{UserName: userName, Title: Ptr("onions"), Content: Ptr("old content"), Audit: models.Audit{Revision: 3}}
+
+	testCases := []struct {
+		name                 string
+		ifMatch              string
+		storageCall          bool
+		storageRevision      int64
+		storageResponseError error
+		currentCall          bool
+		expectedCode         int
+		expectedBody         string
+		expectedETag         string
+	}{
+		{
+			name:            "positive: revision matches",
+			ifMatch:         `"3"`,
+			storageCall:     true,
+			storageRevision: 3,
+			expectedCode:    http.StatusOK,
+			expectedBody:    `Заметка для пользователя "davos" успешно обновлена`,
+		},
+		{
+			name:            "positive: no precondition",
+			storageCall:     true,
+			storageRevision: 0,
+			expectedCode:    http.StatusOK,
+			expectedBody:    `Заметка для пользователя "davos" успешно обновлена`,
+		},
+		{
+			name:                 "negative: note is gone",
+			storageCall:          true,
+			storageRevision:      0,
+			storageResponseError: database.ErrNoData,
+			expectedCode:         http.StatusNotFound,
+			expectedBody:         `секрет пользователя "davos" не найден`,
+		},
+		{
+			name:                 "negative: stale revision",
+			ifMatch:              `"2"`,
+			storageCall:          true,
+			storageRevision:      2,
+			storageResponseError: database.ErrRevisionMismatch,
+			currentCall:          true,
+			expectedCode:         http.StatusPreconditionFailed,
+			expectedBody: `{"message":"секрет пользователя \"davos\" был изменен или удален другим клиентом","revision":3,` +
+				`"current":{"user_name":"davos","title":"onions","content":"old content","revision":3}}`,
+			expectedETag: `"3"`,
+		},
+		{
+			name:         "negative: invalid header",
+			ifMatch:      "three",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `некорректный заголовок If-Match "three"`,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			if tt.storageCall {
+				request := models.Note{UserName: userName, Title: Ptr("onions"), Content: Ptr("new content"), Audit: models.Audit{Revision: tt.storageRevision}}
+				mockedStorage.On("UpdateNote", mock.Anything, request).Return(tt.storageResponseError)
+			}
+			if tt.currentCall {
+				mockedStorage.On("GetNotes", mock.Anything, models.Note{UserName: userName, Title: Ptr("onions")}, models.ListOptions{}).
+					Return([]models.Note{current}, "", nil)
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/update/note", h.UpdateUserNoteHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			req := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(body)
+			if tt.ifMatch != "" {
+				req.SetHeader("If-Match", tt.ifMatch)
+			}
+			resp, err := req.Post(fmt.Sprintf("%s/update/note", srv.URL))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			if tt.expectedCode == http.StatusPreconditionFailed {
+				assert.JSONEq(t, tt.expectedBody, resp.String())
+			} else {
+				assert.Equal(t, tt.expectedBody, resp.String())
+			}
+			assert.Equal(t, tt.expectedETag, resp.Header().Get("ETag"))
+		})
+	}
+}
+
+func TestHandler_DeleteNotesIfMatch(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "davos"
+	systemPassword := "onionknight"
+
+	mockedStorage := mocks.NewStorage(t)
+	mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+
+	r := chi.NewRouter()
+	h := New(mockedStorage, log)
+	r.Post("/auth/register", h.RegisterHandler)
+	r.Group(func(r chi.Router) {
+		r.Use(h.CheckAuthorization)
+		r.Post("/delete/note", h.DeleteUserNotesHandler)
+	})
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	_, err := resty.New().R().
+		SetHeader("content-type", "application/json").
+		SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+		Post(fmt.Sprintf("%s/auth/register", srv.URL))
+	assert.NoError(t, err)
+
+	resp, err := resty.New().R().
+		SetHeader("content-type", "application/json").
+		SetHeader("If-Match", `"3"`).
+		SetBody(fmt.Sprintf(`{"user_name": %q}`, userName)).
+		Post(fmt.Sprintf("%s/delete/note", srv.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	assert.Equal(t, "заголовок If-Match допустим только при удалении одной заметки", resp.String())
+}
+
+func TestHandler_DeleteNotesIfMatchMissing(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "davos"
+	systemPassword := "onionknight"
+
+	mockedStorage := mocks.NewStorage(t)
+	mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+	mockedStorage.On("DeleteNotes", mock.Anything, models.Note{UserName: userName, Title: Ptr("onions"), Audit: models.Audit{Revision: 3}}).Return(database.ErrNoData)
+
+	r := chi.NewRouter()
+	h := New(mockedStorage, log)
+	r.Post("/auth/register", h.RegisterHandler)
+	r.Group(func(r chi.Router) {
+		r.Use(h.CheckAuthorization)
+		r.Post("/delete/note", h.DeleteUserNotesHandler)
+	})
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	_, err := resty.New().R().
+		SetHeader("content-type", "application/json").
+		SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+		Post(fmt.Sprintf("%s/auth/register", srv.URL))
+	assert.NoError(t, err)
+
+	resp, err := resty.New().R().
+		SetHeader("content-type", "application/json").
+		SetHeader("If-Match", `"3"`).
+		SetBody(fmt.Sprintf(`{"user_name": %q, "title": "onions"}`, userName)).
+		Post(fmt.Sprintf("%s/delete/note", srv.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+	assert.Equal(t, "секрет пользователя \"davos\" не найден", resp.String())
+}
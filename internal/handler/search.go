@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ZnNr/GopherVault/internal/database"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/search"
+)
+
+// SearchHandler обрабатывает запросы на поиск по секретам пользователя.
+// Поиск выполняется по названиям, логинам, адресам, заголовкам, папкам, тегам и метаинформации;
+// пароли, CV и содержимое заметок не расшифровываются и в ответ не попадают.
+func (h *handler) SearchHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	// Читаем тело запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Распаковываем данные из тела запроса в структуру SearchRequest
+	var searchRequest models.SearchRequest
+	if err = json.Unmarshal(body, &searchRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Проверяем строку поиска и типы секретов
+	if strings.TrimSpace(searchRequest.Query) == "" {
+		http.Error(w, "строка поиска не должна быть пустой", http.StatusBadRequest)
+		return
+	}
+	for _, secretType := range searchRequest.Types {
+		switch secretType {
+		case models.SecretCredentials, models.SecretNote, models.SecretCard:
+		default:
+			http.Error(w, fmt.Sprintf("неизвестный тип секрета %q", secretType), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Получаем секреты пользователя и отбираем совпадающие с запросом
+	items, err := h.db.GetSearchItems(ctx, searchRequest)
+	if err == nil {
+		if items = search.Filter(items, searchRequest.Query); len(items) == 0 {
+			err = database.ErrNoData
+		}
+	}
+	if err != nil {
+		message, status := handleUserError(searchRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	// Формируем ответ
+	searchResponse, err := json.Marshal(items)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err = io.WriteString(w, string(searchResponse)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ZnNr/GopherVault/internal/database"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/models/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestHandler_Search(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "varys"
+	systemPassword := "littlebirds"
+
+	items := []models.SearchResult{
+		{Type: models.SecretNote, Title: "pentos"},
+		{Type: models.SecretCredentials, Title: "Red Keep", Login: Ptr("spider")},
+		{Type: models.SecretCard, Title: "Iron Bank", Tags: []string{"red keep"}},
+	}
+
+	testCases := []struct {
+		name                 string
+		body                 string
+		storageCall          bool
+		storageRequest       models.SearchRequest
+		storageResponse      []models.SearchResult
+		storageResponseError error
+		expectedCode         int
+		expectedBody         string
+	}{
+		{
+			name:            "positive: matches sorted by score",
+			body:            fmt.Sprintf(`{"user_name": %q, "query": "red keep"}`, userName),
+			storageCall:     true,
+			storageRequest:  models.SearchRequest{UserName: userName, Query: "red keep"},
+			storageResponse: items,
+			expectedCode:    http.StatusOK,
+			expectedBody: `[{"type":"credentials","title":"Red Keep","login":"spider","score":50},` +
+				`{"type":"card","title":"Iron Bank","tags":["red keep"],"score":50}]`,
+		},
+		{
+			name:            "positive: nothing matches",
+			body:            fmt.Sprintf(`{"user_name": %q, "query": "dorne", "types": ["note"]}`, userName),
+			storageCall:     true,
+			storageRequest:  models.SearchRequest{UserName: userName, Query: "dorne", Types: []models.SecretType{models.SecretNote}},
+			storageResponse: items[:1],
+			expectedCode:    http.StatusNoContent,
+		},
+		{
+			name:                 "negative: no secrets",
+			body:                 fmt.Sprintf(`{"user_name": %q, "query": "dorne"}`, userName),
+			storageCall:          true,
+			storageRequest:       models.SearchRequest{UserName: userName, Query: "dorne"},
+			storageResponseError: database.ErrNoData,
+			expectedCode:         http.StatusNoContent,
+		},
+		{
+			name:         "negative: empty query",
+			body:         fmt.Sprintf(`{"user_name": %q, "query": " "}`, userName),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "строка поиска не должна быть пустой",
+		},
+		{
+			name:         "negative: unknown type",
+			body:         fmt.Sprintf(`{"user_name": %q, "query": "keep", "types": ["dragon"]}`, userName),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `неизвестный тип секрета "dragon"`,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			if tt.storageCall {
+				mockedStorage.On("GetSearchItems", mock.Anything, tt.storageRequest).Return(tt.storageResponse, tt.storageResponseError)
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/search", h.SearchHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(tt.body).
+				Post(fmt.Sprintf("%s/search", srv.URL))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			if tt.expectedCode == http.StatusOK {
+				assert.JSONEq(t, tt.expectedBody, resp.String())
+			} else if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, resp.String())
+			}
+		})
+	}
+}
+
+func TestHandler_SearchUserNotes(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "sam"
+	systemPassword := "oldtown"
+
+	testCases := []struct {
+		name                 string
+		body                 string
+		storageCall          bool
+		storageResponse      []models.Note
+		storageResponseError error
+		expectedCode         int
+		expectedBody         string
+	}{
+		{
+			name:            "positive: notes found",
+			body:            fmt.Sprintf(`{"user_name": %q, "query": "dragonglass"}`, userName),
+			storageCall:     true,
+			storageResponse: []models.Note{{UserName: userName, Title: Ptr("white walkers"), Content: Ptr("dragonglass kills them")}},
+			expectedCode:    http.StatusOK,
+			expectedBody:    `[{"user_name":"sam","title":"white walkers","content":"dragonglass kills them"}]`,
+		},
+		{
+			name:                 "negative: nothing found",
+			body:                 fmt.Sprintf(`{"user_name": %q, "query": "dragonglass"}`, userName),
+			storageCall:          true,
+			storageResponseError: database.ErrNoData,
+			expectedCode:         http.StatusNoContent,
+		},
+		{
+			name:         "negative: no words in query",
+			body:         fmt.Sprintf(`{"user_name": %q, "query": "?!"}`, userName),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "строка поиска не должна быть пустой",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			if tt.storageCall {
+				mockedStorage.On("SearchNotes", mock.Anything, models.SearchRequest{UserName: userName, Query: "dragonglass"}).
+					Return(tt.storageResponse, tt.storageResponseError)
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/notes/search", h.SearchUserNotesHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(tt.body).
+				Post(fmt.Sprintf("%s/notes/search", srv.URL))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			if tt.expectedCode == http.StatusOK {
+				assert.JSONEq(t, tt.expectedBody, resp.String())
+			} else if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, resp.String())
+			}
+		})
+	}
+}
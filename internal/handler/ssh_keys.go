@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/sshkey"
+	"io"
+	"net/http"
+)
+
+// SaveSSHKeyHandler обрабатывает запросы на сохранение SSH-ключа пользователя.
+// Тип ключа, открытый ключ и отпечаток вычисляются по закрытому ключу.
+func (h *handler) SaveSSHKeyHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	// Читаем тело запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Распаковываем данные из тела запроса в структуру SSHKey
+	var requestKey models.SSHKey
+	if err = json.Unmarshal(body, &requestKey); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Проверяем обязательные поля
+	if requestKey.Name == nil || *requestKey.Name == "" || requestKey.PrivateKey == nil {
+		http.Error(w, "название и закрытый ключ не должны быть пустыми", http.StatusBadRequest)
+		return
+	}
+
+	// Вычисляем открытый ключ и отпечаток
+	var passphrase string
+	if requestKey.Passphrase != nil {
+		passphrase = *requestKey.Passphrase
+	}
+	info, err := sshkey.Inspect(*requestKey.PrivateKey, passphrase)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	requestKey.KeyType = &info.Type
+	requestKey.PublicKey = &info.PublicKey
+	requestKey.Fingerprint = &info.Fingerprint
+
+	// Сохраняем ключ в хранилище
+	if err = h.db.SaveSSHKey(ctx, requestKey); err != nil {
+		message, status := handleUserError(requestKey.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	// Отправляем ответ клиенту
+	response := fmt.Sprintf("SSH-ключ %q (%s) для пользователя %q успешно сохранен", *requestKey.Name, info.Fingerprint, requestKey.UserName)
+	if _, err = io.WriteString(w, response); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetSSHKeysHandler обрабатывает запросы на получение SSH-ключей пользователя
+func (h *handler) GetSSHKeysHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	// Читаем тело запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Распаковываем данные из тела запроса в структуру SSHKey
+	var keyRequest models.SSHKey
+	if err = json.Unmarshal(body, &keyRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Получаем ключи пользователя из хранилища
+	keys, err := h.db.GetSSHKeys(ctx, keyRequest)
+	if err != nil {
+		message, status := handleUserError(keyRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	// Формируем ответ
+	keysResponse, err := json.Marshal(keys)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err = io.WriteString(w, string(keysResponse)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// DeleteSSHKeysHandler обрабатывает запросы на удаление SSH-ключей пользователя
+func (h *handler) DeleteSSHKeysHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	// Читаем тело запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Распаковываем данные из тела запроса в структуру SSHKey
+	var keyRequest models.SSHKey
+	if err = json.Unmarshal(body, &keyRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Удаление всех SSH-ключей пользователя требует подтверждения
+	if keyRequest.Name == nil && !confirmBulkDelete(w, r, keyRequest.UserName, "SSH-ключи") {
+		return
+	}
+
+	// Удаляем ключи пользователя из хранилища
+	if err = h.db.DeleteSSHKeys(ctx, keyRequest); err != nil {
+		message, status := handleUserError(keyRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	response := fmt.Sprintf("SSH-ключи пользователя %q были успешно удалены", keyRequest.UserName)
+	if keyRequest.Name != nil {
+		response = fmt.Sprintf("SSH-ключ %q пользователя %q был успешно удален", *keyRequest.Name, keyRequest.UserName)
+	}
+
+	// Отправляем ответ клиенту
+	if _, err = io.WriteString(w, response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
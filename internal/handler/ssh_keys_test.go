@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ZnNr/GopherVault/internal/database"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/models/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestHandler_SaveSSHKey(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "arya"
+	systemPassword := "needle"
+
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	block, err := ssh.MarshalPrivateKey(edKey, "arya")
+	require.NoError(t, err)
+	privateKey := string(pem.EncodeToMemory(block))
+
+	testCases := []struct {
+		name                 string
+		body                 string
+		storageCall          bool
+		storageResponseError error
+		expectedCode         int
+	}{
+		{
+			name:         "positive: key saved",
+			body:         fmt.Sprintf(`{"user_name": %q, "name": "faceless", "private_key": %q}`, userName, privateKey),
+			storageCall:  true,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:                 "negative: key with such name already exists",
+			body:                 fmt.Sprintf(`{"user_name": %q, "name": "faceless", "private_key": %q}`, userName, privateKey),
+			storageCall:          true,
+			storageResponseError: database.ErrConflict,
+			expectedCode:         http.StatusConflict,
+		},
+		{
+			name:         "negative: invalid key",
+			body:         fmt.Sprintf(`{"user_name": %q, "name": "faceless", "private_key": "valar morghulis"}`, userName),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "negative: name is not provided",
+			body:         fmt.Sprintf(`{"user_name": %q, "private_key": %q}`, userName, privateKey),
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			if tt.storageCall {
+				mockedStorage.On("SaveSSHKey", mock.Anything, mock.MatchedBy(func(k models.SSHKey) bool {
+					return k.UserName == userName && *k.Name == "faceless" && *k.KeyType == ssh.KeyAlgoED25519 &&
+						k.Fingerprint != nil && k.PublicKey != nil
+				})).Return(tt.storageResponseError)
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/save/ssh-key", h.SaveSSHKeyHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(tt.body).
+				Post(fmt.Sprintf("%s/save/ssh-key", srv.URL))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+		})
+	}
+}
+
+func TestHandler_GetSSHKeys(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "sansa"
+	systemPassword := "lemoncakes"
+
+	testCases := []struct {
+		name                 string
+		storageResponse      []models.SSHKey
+		storageResponseError error
+		expectedCode         int
+		expectedBody         string
+	}{
+		{
+			name:            "positive: keys found",
+			storageResponse: []models.SSHKey{{UserName: userName, Name: Ptr("winterfell"), KeyType: Ptr("ssh-ed25519")}},
+			expectedCode:    http.StatusOK,
+			expectedBody:    `[{"user_name":"sansa","name":"winterfell","key_type":"ssh-ed25519"}]`,
+		},
+		{
+			name:                 "negative: no keys",
+			storageResponseError: database.ErrNoData,
+			expectedCode:         http.StatusNoContent,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			mockedStorage.On("GetSSHKeys", mock.Anything, models.SSHKey{UserName: userName}).Return(tt.storageResponse, tt.storageResponseError)
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/get/ssh-key", h.GetSSHKeysHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"user_name": %q}`, userName)).
+				Post(fmt.Sprintf("%s/get/ssh-key", srv.URL))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, resp.String())
+			}
+		})
+	}
+}
+
+func TestHandler_DeleteSSHKeys(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "gendry"
+	systemPassword := "hammer"
+
+	testCases := []struct {
+		name         string
+		body         string
+		query        string
+		request      models.SSHKey
+		storageCall  bool
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "positive: key deleted",
+			body:         fmt.Sprintf(`{"user_name": %q, "name": "deploy"}`, userName),
+			request:      models.SSHKey{UserName: userName, Name: Ptr("deploy")},
+			storageCall:  true,
+			expectedCode: http.StatusOK,
+			expectedBody: `SSH-ключ "deploy" пользователя "gendry" был успешно удален`,
+		},
+		{
+			name:         "positive: all keys deleted with confirmation",
+			body:         fmt.Sprintf(`{"user_name": %q}`, userName),
+			query:        "?all=true",
+			request:      models.SSHKey{UserName: userName},
+			storageCall:  true,
+			expectedCode: http.StatusOK,
+			expectedBody: `SSH-ключи пользователя "gendry" были успешно удалены`,
+		},
+		{
+			name:         "negative: all keys without confirmation",
+			body:         fmt.Sprintf(`{"user_name": %q}`, userName),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `не указан ключ удаления: будут удалены все SSH-ключи пользователя "gendry"; для подтверждения передайте параметр all=true`,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			if tt.storageCall {
+				mockedStorage.On("DeleteSSHKeys", mock.Anything, tt.request).Return(nil)
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/delete/ssh-key", h.DeleteSSHKeysHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(tt.body).
+				Post(fmt.Sprintf("%s/delete/ssh-key%s", srv.URL, tt.query))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			assert.Equal(t, tt.expectedBody, resp.String())
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// GetChangesHandler обрабатывает запросы на получение изменений секретов пользователя с номера, указанного в параметре since
+func (h *handler) GetChangesHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	// Номер последнего полученного клиентом изменения передается в параметре since
+	var since int64
+	if value := r.URL.Query().Get("since"); value != "" {
+		var err error
+		if since, err = strconv.ParseInt(value, 10, 64); err != nil || since < 0 {
+			http.Error(w, "параметр since должен быть неотрицательным числом", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Извлекаем имя пользователя из тела запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var syncRequest models.SyncRequest
+	if err = json.Unmarshal(body, &syncRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	syncRequest.Since = since
+
+	// Получаем изменения из хранилища
+	changes, err := h.db.GetChanges(ctx, syncRequest)
+	if err != nil {
+		message, status := handleUserError(syncRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	// Формируем ответ
+	changesResponse, err := json.Marshal(changes)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err = io.WriteString(w, string(changesResponse)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
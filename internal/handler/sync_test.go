@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/models/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestHandler_GetChanges(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "arya"
+	systemPassword := "needle"
+	title := "list"
+	changedAt := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name                 string
+		query                string
+		storageCall          bool
+		storageRequest       models.SyncRequest
+		storageResponse      models.SyncResponse
+		storageResponseError error
+		expectedCode         int
+		expectedBody         string
+	}{
+		{
+			name:           "positive: changes since seq",
+			query:          "?since=4",
+			storageCall:    true,
+			storageRequest: models.SyncRequest{UserName: userName, Since: 4},
+			storageResponse: models.SyncResponse{Seq: 9, Changes: []models.Change{
+				{Seq: 8, Type: models.SecretCredentials, SecretID: 3, Action: models.ChangeDeleted, Revision: 2, ChangedAt: changedAt},
+				{Seq: 9, Type: models.SecretNote, SecretID: 5, Action: models.ChangeCreated, Revision: 1, ChangedAt: changedAt,
+					Note: &models.Note{UserName: userName, Title: &title}},
+			}},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"seq":9,"changes":[` +
+				`{"seq":8,"type":"credentials","id":3,"action":"deleted","revision":2,"changed_at":"2024-05-02T10:00:00Z"},` +
+				`{"seq":9,"type":"note","id":5,"action":"created","revision":1,"changed_at":"2024-05-02T10:00:00Z","note":{"user_name":"arya","title":"list"}}]}`,
+		},
+		{
+			name:            "positive: full sync without since",
+			storageCall:     true,
+			storageRequest:  models.SyncRequest{UserName: userName},
+			storageResponse: models.SyncResponse{Changes: []models.Change{}},
+			expectedCode:    http.StatusOK,
+			expectedBody:    `{"seq":0,"changes":[]}`,
+		},
+		{
+			name:         "negative: invalid since",
+			query:        "?since=-1",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "параметр since должен быть неотрицательным числом",
+		},
+		{
+			name:                 "negative: storage error",
+			query:                "?since=1",
+			storageCall:          true,
+			storageRequest:       models.SyncRequest{UserName: userName, Since: 1},
+			storageResponseError: errors.New("connection refused"),
+			expectedCode:         http.StatusInternalServerError,
+			expectedBody:         "ошибка запроса пользователя \"arya\": connection refused",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			if tt.storageCall {
+				mockedStorage.On("GetChanges", mock.Anything, tt.storageRequest).Return(tt.storageResponse, tt.storageResponseError)
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Get("/sync", h.GetChangesHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().SetAllowGetMethodPayload(true).R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"user_name": %q}`, userName)).
+				Get(fmt.Sprintf("%s/sync%s", srv.URL, tt.query))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			if tt.expectedCode == http.StatusOK {
+				assert.JSONEq(t, tt.expectedBody, resp.String())
+			} else {
+				assert.Equal(t, tt.expectedBody, resp.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/totp"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// SaveTOTPHandler обрабатывает запросы на сохранение секрета TOTP пользователя
+func (h *handler) SaveTOTPHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	// Читаем тело запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Распаковываем данные из тела запроса в структуру TOTP
+	var requestTOTP models.TOTP
+	if err = json.Unmarshal(body, &requestTOTP); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Проверяем обязательные поля и корректность адреса otpauth://
+	if requestTOTP.Name == nil || *requestTOTP.Name == "" || requestTOTP.URI == nil {
+		http.Error(w, "название и адрес секрета не должны быть пустыми", http.StatusBadRequest)
+		return
+	}
+	if _, err = totp.Parse(*requestTOTP.URI); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Сохраняем секрет в хранилище
+	if err = h.db.SaveTOTP(ctx, requestTOTP); err != nil {
+		message, status := handleUserError(requestTOTP.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	// Отправляем ответ клиенту
+	response := fmt.Sprintf("Секрет TOTP %q для пользователя %q успешно сохранен", *requestTOTP.Name, requestTOTP.UserName)
+	if _, err = io.WriteString(w, response); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetTOTPHandler обрабатывает запросы на получение секретов TOTP пользователя
+func (h *handler) GetTOTPHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	// Читаем тело запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Распаковываем данные из тела запроса в структуру TOTP
+	var totpRequest models.TOTP
+	if err = json.Unmarshal(body, &totpRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Получаем секреты пользователя из хранилища
+	secrets, err := h.db.GetTOTP(ctx, totpRequest)
+	if err != nil {
+		message, status := handleUserError(totpRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	// Формируем ответ
+	secretsResponse, err := json.Marshal(secrets)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err = io.WriteString(w, string(secretsResponse)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// DeleteTOTPHandler обрабатывает запросы на удаление секретов TOTP пользователя
+func (h *handler) DeleteTOTPHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	// Читаем тело запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Распаковываем данные из тела запроса в структуру TOTP
+	var totpRequest models.TOTP
+	if err = json.Unmarshal(body, &totpRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Удаление всех секретов TOTP пользователя требует подтверждения
+	if totpRequest.Name == nil && totpRequest.ID == nil && !confirmBulkDelete(w, r, totpRequest.UserName, "секреты TOTP") {
+		return
+	}
+
+	// Удаляем секреты пользователя из хранилища
+	if err = h.db.DeleteTOTP(ctx, totpRequest); err != nil {
+		message, status := handleUserError(totpRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	response := fmt.Sprintf("Секреты TOTP пользователя %q были успешно удалены", totpRequest.UserName)
+	if totpRequest.Name != nil {
+		response = fmt.Sprintf("Секрет TOTP %q пользователя %q был успешно удален", *totpRequest.Name, totpRequest.UserName)
+	}
+
+	// Отправляем ответ клиенту
+	if _, err = io.WriteString(w, response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetTOTPCodeHandler обрабатывает запросы на получение текущего одноразового кода по идентификатору секрета
+func (h *handler) GetTOTPCodeHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	// Идентификатор секрета передается в пути запроса
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "некорректный идентификатор секрета", http.StatusBadRequest)
+		return
+	}
+
+	// Читаем тело запроса для получения имени пользователя
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var totpRequest models.TOTP
+	if err = json.Unmarshal(body, &totpRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Получаем секрет пользователя из хранилища
+	secrets, err := h.db.GetTOTP(ctx, models.TOTP{UserName: totpRequest.UserName, Audit: models.Audit{ID: &id}})
+	if err != nil {
+		message, status := handleUserError(totpRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	// Вычисляем текущий код
+	key, err := totp.Parse(*secrets[0].URI)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	code, remaining := key.Code(time.Now())
+
+	// Формируем ответ
+	codeResponse, err := json.Marshal(models.TOTPCode{Code: code, Remaining: remaining})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err = io.WriteString(w, string(codeResponse)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ZnNr/GopherVault/internal/database"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/models/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestHandler_SaveTOTP(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "davos"
+	systemPassword := "onion"
+	uri := "otpauth://totp/Dragonstone:davos?secret=JBSWY3DPEHPK3PXP"
+
+	testCases := []struct {
+		name                 string
+		body                 string
+		storageCall          bool
+		storageResponseError error
+		expectedCode         int
+	}{
+		{
+			name:         "positive: secret saved",
+			body:         fmt.Sprintf(`{"user_name": %q, "name": "ship", "uri": %q}`, userName, uri),
+			storageCall:  true,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:                 "negative: credentials to attach not found",
+			body:                 fmt.Sprintf(`{"user_name": %q, "name": "ship", "uri": %q}`, userName, uri),
+			storageCall:          true,
+			storageResponseError: database.ErrNoSuchCredentials,
+			expectedCode:         http.StatusNotFound,
+		},
+		{
+			name:         "negative: invalid uri",
+			body:         fmt.Sprintf(`{"user_name": %q, "name": "ship", "uri": "https://example.com"}`, userName),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "negative: name is not provided",
+			body:         fmt.Sprintf(`{"user_name": %q, "uri": %q}`, userName, uri),
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			if tt.storageCall {
+				mockedStorage.On("SaveTOTP", mock.Anything, models.TOTP{UserName: userName, Name: Ptr("ship"), URI: &uri}).Return(tt.storageResponseError)
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/save/totp", h.SaveTOTPHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(tt.body).
+				Post(fmt.Sprintf("%s/save/totp", srv.URL))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+		})
+	}
+}
+
+func TestHandler_GetTOTPCode(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "melisandre"
+	systemPassword := "lordoflight"
+	uri := "otpauth://totp/Asshai:melisandre?secret=JBSWY3DPEHPK3PXP&digits=8"
+	id := int64(3)
+
+	testCases := []struct {
+		name                 string
+		path                 string
+		storageCall          bool
+		storageResponse      []models.TOTP
+		storageResponseError error
+		expectedCode         int
+	}{
+		{
+			name:            "positive: current code",
+			path:            "/totp/3/code",
+			storageCall:     true,
+			storageResponse: []models.TOTP{{UserName: userName, Audit: models.Audit{ID: &id}, Name: Ptr("fire"), URI: &uri}},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:                 "negative: no such secret",
+			path:                 "/totp/3/code",
+			storageCall:          true,
+			storageResponseError: database.ErrNoData,
+			expectedCode:         http.StatusNoContent,
+		},
+		{
+			name:         "negative: invalid id",
+			path:         "/totp/fire/code",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			if tt.storageCall {
+				mockedStorage.On("GetTOTP", mock.Anything, models.TOTP{UserName: userName, Audit: models.Audit{ID: &id}}).Return(tt.storageResponse, tt.storageResponseError)
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Get("/totp/{id}/code", h.GetTOTPCodeHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().SetAllowGetMethodPayload(true).R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"user_name": %q}`, userName)).
+				Get(srv.URL + tt.path)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			if tt.expectedCode == http.StatusOK {
+				var code models.TOTPCode
+				assert.NoError(t, json.Unmarshal(resp.Body(), &code))
+				assert.Len(t, code.Code, 8)
+				assert.True(t, code.Remaining > 0 && code.Remaining <= 30)
+			}
+		})
+	}
+}
+
+func TestHandler_DeleteTOTP(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "jorah"
+	systemPassword := "bear"
+
+	testCases := []struct {
+		name         string
+		body         string
+		query        string
+		request      models.TOTP
+		storageCall  bool
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "positive: secret deleted",
+			body:         fmt.Sprintf(`{"user_name": %q, "name": "mail"}`, userName),
+			request:      models.TOTP{UserName: userName, Name: Ptr("mail")},
+			storageCall:  true,
+			expectedCode: http.StatusOK,
+			expectedBody: `Секрет TOTP "mail" пользователя "jorah" был успешно удален`,
+		},
+		{
+			name:         "positive: all secrets deleted with confirmation",
+			body:         fmt.Sprintf(`{"user_name": %q}`, userName),
+			query:        "?all=true",
+			request:      models.TOTP{UserName: userName},
+			storageCall:  true,
+			expectedCode: http.StatusOK,
+			expectedBody: `Секреты TOTP пользователя "jorah" были успешно удалены`,
+		},
+		{
+			name:         "negative: all secrets without confirmation",
+			body:         fmt.Sprintf(`{"user_name": %q}`, userName),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `не указан ключ удаления: будут удалены все секреты TOTP пользователя "jorah"; для подтверждения передайте параметр all=true`,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			if tt.storageCall {
+				mockedStorage.On("DeleteTOTP", mock.Anything, tt.request).Return(nil)
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/delete/totp", h.DeleteTOTPHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(tt.body).
+				Post(fmt.Sprintf("%s/delete/totp%s", srv.URL, tt.query))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			assert.Equal(t, tt.expectedBody, resp.String())
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// parseTrashRequest читает запрос к корзине из тела запроса и проверяет тип секрета
+func parseTrashRequest(r *http.Request) (models.TrashRequest, int, error) {
+	var trashRequest models.TrashRequest
+
+	// Читаем тело запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return trashRequest, http.StatusInternalServerError, err
+	}
+	if err = json.Unmarshal(body, &trashRequest); err != nil {
+		return trashRequest, http.StatusBadRequest, err
+	}
+
+	// Проверяем тип секрета
+	switch trashRequest.Type {
+	case "", models.SecretCredentials, models.SecretNote, models.SecretCard:
+	default:
+		return trashRequest, http.StatusBadRequest, fmt.Errorf("неизвестный тип секрета %q", trashRequest.Type)
+	}
+	if trashRequest.SecretID != 0 && trashRequest.Type == "" {
+		return trashRequest, http.StatusBadRequest, fmt.Errorf("для идентификатора секрета должен быть указан его тип")
+	}
+	return trashRequest, http.StatusOK, nil
+}
+
+// GetTrashHandler обрабатывает запросы на получение секретов из корзины
+func (h *handler) GetTrashHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	trashRequest, status, err := parseTrashRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	// Получаем секреты из корзины
+	items, err := h.db.GetTrash(ctx, trashRequest)
+	if err != nil {
+		message, status := handleUserError(trashRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	// Формируем ответ
+	trashResponse, err := json.Marshal(items)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err = io.WriteString(w, string(trashResponse)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// RestoreFromTrashHandler обрабатывает запросы на восстановление секретов из корзины
+func (h *handler) RestoreFromTrashHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	trashRequest, status, err := parseTrashRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	// Возвращаем секреты из корзины
+	if err = h.db.RestoreFromTrash(ctx, trashRequest); err != nil {
+		message, status := handleUserError(trashRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	// Отправляем сообщение об успешном восстановлении
+	response := fmt.Sprintf("Секреты пользователя %q были успешно восстановлены из корзины", trashRequest.UserName)
+	if _, err = io.WriteString(w, response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// EmptyTrashHandler обрабатывает запросы на окончательное удаление секретов из корзины
+func (h *handler) EmptyTrashHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	trashRequest, status, err := parseTrashRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	// Окончательно удаляем секреты из корзины
+	if err = h.db.EmptyTrash(ctx, trashRequest); err != nil {
+		message, status := handleUserError(trashRequest.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	// Отправляем сообщение об успешной очистке
+	response := fmt.Sprintf("Корзина пользователя %q была успешно очищена", trashRequest.UserName)
+	if _, err = io.WriteString(w, response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
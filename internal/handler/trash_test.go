@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ZnNr/GopherVault/internal/database"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/models/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestHandler_GetTrash(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "gendry"
+	systemPassword := "bastard"
+	deletedAt := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name                 string
+		body                 string
+		storageCall          bool
+		storageRequest       models.TrashRequest
+		storageResponse      []models.TrashItem
+		storageResponseError error
+		expectedCode         int
+		expectedBody         string
+	}{
+		{
+			name:            "positive: trash listed",
+			body:            fmt.Sprintf(`{"user_name": %q}`, userName),
+			storageCall:     true,
+			storageRequest:  models.TrashRequest{UserName: userName},
+			storageResponse: []models.TrashItem{{Type: models.SecretNote, SecretID: 5, Title: "hammer", DeletedAt: deletedAt}},
+			expectedCode:    http.StatusOK,
+			expectedBody:    `[{"type":"note","id":5,"title":"hammer","deleted_at":"2024-05-02T10:00:00Z"}]`,
+		},
+		{
+			name:                 "negative: empty trash",
+			body:                 fmt.Sprintf(`{"user_name": %q, "type": "card"}`, userName),
+			storageCall:          true,
+			storageRequest:       models.TrashRequest{UserName: userName, Type: models.SecretCard},
+			storageResponseError: database.ErrNoData,
+			expectedCode:         http.StatusNoContent,
+		},
+		{
+			name:         "negative: unknown type",
+			body:         fmt.Sprintf(`{"user_name": %q, "type": "dragon"}`, userName),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `неизвестный тип секрета "dragon"`,
+		},
+		{
+			name:         "negative: id without type",
+			body:         fmt.Sprintf(`{"user_name": %q, "id": 5}`, userName),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "для идентификатора секрета должен быть указан его тип",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			if tt.storageCall {
+				mockedStorage.On("GetTrash", mock.Anything, tt.storageRequest).Return(tt.storageResponse, tt.storageResponseError)
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/get/trash", h.GetTrashHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(tt.body).
+				Post(fmt.Sprintf("%s/get/trash", srv.URL))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			if tt.expectedCode == http.StatusOK {
+				assert.JSONEq(t, tt.expectedBody, resp.String())
+			} else if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, resp.String())
+			}
+		})
+	}
+}
+
+func TestHandler_RestoreAndEmptyTrash(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "gendry"
+	systemPassword := "bastard"
+	request := models.TrashRequest{UserName: userName, Type: models.SecretNote, SecretID: 5}
+
+	testCases := []struct {
+		name                 string
+		path                 string
+		storageMethod        string
+		storageResponseError error
+		expectedCode         int
+		expectedBody         string
+	}{
+		{
+			name:          "positive: restored",
+			path:          "/trash/restore",
+			storageMethod: "RestoreFromTrash",
+			expectedCode:  http.StatusOK,
+			expectedBody:  `Секреты пользователя "gendry" были успешно восстановлены из корзины`,
+		},
+		{
+			name:                 "negative: restored key is taken",
+			path:                 "/trash/restore",
+			storageMethod:        "RestoreFromTrash",
+			storageResponseError: &database.ConflictError{Constraint: "notes_user_title_key"},
+			expectedCode:         http.StatusConflict,
+			expectedBody:         `запись пользователя "gendry" с таким ключом уже существует`,
+		},
+		{
+			name:          "positive: emptied",
+			path:          "/trash/empty",
+			storageMethod: "EmptyTrash",
+			expectedCode:  http.StatusOK,
+			expectedBody:  `Корзина пользователя "gendry" была успешно очищена`,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			mockedStorage.On(tt.storageMethod, mock.Anything, request).Return(tt.storageResponseError)
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/trash/restore", h.RestoreFromTrashHandler)
+				r.Post("/trash/empty", h.EmptyTrashHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"user_name": %q, "type": "note", "id": 5}`, userName)).
+				Post(srv.URL + tt.path)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			assert.Equal(t, tt.expectedBody, resp.String())
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ZnNr/GopherVault/internal/exporters"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/vault"
+)
+
+// ExportVaultHandler обрабатывает запросы на выгрузку всех секретов пользователя. Секреты передаются
+// расшифрованными, поэтому выгрузка выполняется только после повторной проверки пароля учетной записи
+// и записи события в журнал аудита.
+func (h *handler) ExportVaultHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Извлекаем запрос на выгрузку из тела запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var exportRequest models.VaultExportRequest
+	if err = json.Unmarshal(body, &exportRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if h.exportAudited(w, r, exportRequest.UserName, exportRequest.Password, models.AuditVaultExport, "") {
+		h.log.Infof("секреты пользователя %q выгружены", exportRequest.UserName)
+	}
+}
+
+// PlaintextExportHandler обрабатывает запросы на выгрузку секретов для переноса в другой менеджер паролей.
+// Секреты передаются клиенту так же, как при обычной выгрузке, с той же проверкой пароля и записью в журнал аудита.
+func (h *handler) PlaintextExportHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Извлекаем запрос на выгрузку из тела запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var exportRequest models.PlaintextExportRequest
+	if err = json.Unmarshal(body, &exportRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = exporters.Format(exportRequest.Format).Valid(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if h.exportAudited(w, r, exportRequest.UserName, exportRequest.Password, models.AuditPlaintextExport, "format="+exportRequest.Format+" ") {
+		h.log.Warnf("секреты пользователя %q выгружены в открытом виде в формате %s", exportRequest.UserName, exportRequest.Format)
+	}
+}
+
+// exportAudited повторно проверяет пароль учетной записи, выгружает секреты пользователя, записывает событие
+// в журнал аудита и передает секреты клиенту. Без записи в журнал секреты не выдаются.
+// Возвращает true, если секреты выданы.
+func (h *handler) exportAudited(w http.ResponseWriter, r *http.Request, userName, password string, eventType models.AuditEventType, details string) bool {
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	if password == "" {
+		http.Error(w, "для выгрузки секретов нужно повторно ввести пароль учетной записи", http.StatusBadRequest)
+		return false
+	}
+
+	// Повторно проверяем пароль учетной записи
+	if err := h.db.Login(ctx, userName, password); err != nil {
+		message, status := handleUserError(userName, err)
+		http.Error(w, message, status)
+		return false
+	}
+
+	// Выгружаем секреты из хранилища
+	userVault, err := vault.Export(ctx, h.db, userName, time.Now())
+	if err != nil {
+		message, status := handleUserError(userName, err)
+		http.Error(w, message, status)
+		return false
+	}
+
+	event := models.AuditEvent{
+		UserName: userName,
+		Event:    eventType,
+		Details: fmt.Sprintf("%scredentials=%d notes=%d cards=%d totp=%d ssh_keys=%d", details,
+			len(userVault.Credentials), len(userVault.Notes), len(userVault.Cards), len(userVault.TOTP), len(userVault.SSHKeys)),
+	}
+	if err = h.db.SaveAuditEvent(ctx, event); err != nil {
+		message, status := handleUserError(userName, err)
+		http.Error(w, message, status)
+		return false
+	}
+
+	// Формируем ответ
+	vaultResponse, err := json.Marshal(userVault)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	if _, err = io.WriteString(w, string(vaultResponse)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// ImportVaultHandler обрабатывает запросы на загрузку секретов пользователя
+func (h *handler) ImportVaultHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	// Извлекаем секреты и режим загрузки из тела запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var vaultImport models.VaultImport
+	if err = json.Unmarshal(body, &vaultImport); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = vault.Validate(vaultImport); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Загружаем секреты в хранилище; при ошибке хранилище пользователя остается без изменений
+	res, err := vault.Import(ctx, h.db, vaultImport)
+	if err != nil {
+		message, status := handleUserError(vaultImport.UserName, err)
+		http.Error(w, message+"; секреты не загружены", status)
+		return
+	}
+
+	// Формируем ответ
+	importResponse, err := json.Marshal(res)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err = io.WriteString(w, string(importResponse)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.log.Infof("секреты пользователя %q загружены: создано %d, заменено %d, пропущено %d",
+		vaultImport.UserName, res.Created, res.Updated, res.Skipped)
+}
+
+// ImportBatchHandler обрабатывает запросы на загрузку пакета секретов, перенесенных из другого менеджера паролей
+func (h *handler) ImportBatchHandler(w http.ResponseWriter, r *http.Request) {
+	h.cookiesMu.Lock()
+	defer h.cookiesMu.Unlock()
+
+	// Используем контекст из запроса
+	ctx := r.Context()
+
+	// Извлекаем пакет секретов из тела запроса
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var batch models.ImportBatch
+	if err = json.Unmarshal(body, &batch); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = vault.ValidateBatch(batch); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Сохраняем пакет в одной транзакции
+	res, err := h.db.ImportSecrets(ctx, batch)
+	if err != nil {
+		message, status := handleUserError(batch.UserName, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	// Формируем ответ
+	importResponse, err := json.Marshal(res)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err = io.WriteString(w, string(importResponse)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !batch.DryRun {
+		h.log.Infof("пакет секретов пользователя %q загружен: создано %d, пропущено %d", batch.UserName, res.Created, res.Skipped)
+	}
+}
@@ -0,0 +1,408 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ZnNr/GopherVault/internal/database"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/models/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestHandler_ExportVault(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "jaime"
+	systemPassword := "kingslayer"
+
+	testCases := []struct {
+		name                 string
+		body                 string
+		notes                []models.Note
+		loginCall            bool
+		loginError           error
+		exportCall           bool
+		storageResponseError error
+		auditError           error
+		expectedCode         int
+		expectedBody         string
+	}{
+		{
+			name:         "positive: vault exported and audited",
+			body:         fmt.Sprintf(`{"user_name": %q, "password": %q}`, userName, systemPassword),
+			notes:        []models.Note{{UserName: userName, Title: Ptr("oath"), Content: Ptr("kingsguard")}},
+			loginCall:    true,
+			exportCall:   true,
+			expectedCode: http.StatusOK,
+			expectedBody: `"notes":[{"user_name":"jaime","title":"oath","content":"kingsguard"}]`,
+		},
+		{
+			name:         "negative: password missing",
+			body:         fmt.Sprintf(`{"user_name": %q}`, userName),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "для выгрузки секретов нужно повторно ввести пароль учетной записи",
+		},
+		{
+			name:         "negative: wrong password",
+			body:         fmt.Sprintf(`{"user_name": %q, "password": "goldenhand"}`, userName),
+			loginCall:    true,
+			loginError:   database.ErrInvalidCredentials,
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: "предоставлен неверный пароль для пользователя \"jaime\"",
+		},
+		{
+			name:                 "negative: storage error",
+			body:                 fmt.Sprintf(`{"user_name": %q, "password": %q}`, userName, systemPassword),
+			loginCall:            true,
+			exportCall:           true,
+			storageResponseError: errors.New("connection refused"),
+			expectedCode:         http.StatusInternalServerError,
+			expectedBody:         "ошибка запроса пользователя \"jaime\": connection refused",
+		},
+		{
+			name:         "negative: audit log unavailable",
+			body:         fmt.Sprintf(`{"user_name": %q, "password": %q}`, userName, systemPassword),
+			loginCall:    true,
+			exportCall:   true,
+			auditError:   errors.New("connection refused"),
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: "ошибка запроса пользователя \"jaime\": connection refused",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			if tt.loginCall {
+				mockedStorage.On("Login", mock.Anything, userName, mock.Anything).Return(tt.loginError)
+			}
+			if tt.exportCall {
+				mockedStorage.On("GetFolders", mock.Anything, models.Folder{UserName: userName}).Return(nil, database.ErrNoData)
+				mockedStorage.On("GetCredentials", mock.Anything, models.Credentials{UserName: userName}, models.ListOptions{}).Return(nil, "", database.ErrNoData)
+			}
+			if tt.exportCall && tt.storageResponseError != nil {
+				mockedStorage.On("GetNotes", mock.Anything, models.Note{UserName: userName}, models.ListOptions{}).Return(nil, "", tt.storageResponseError)
+			} else if tt.exportCall {
+				mockedStorage.On("GetNotes", mock.Anything, models.Note{UserName: userName}, models.ListOptions{}).Return(tt.notes, "", nil)
+				mockedStorage.On("GetCard", mock.Anything, models.Card{UserName: userName}, models.ListOptions{}).Return(nil, "", database.ErrNoData)
+				mockedStorage.On("GetTOTP", mock.Anything, models.TOTP{UserName: userName}).Return(nil, database.ErrNoData)
+				mockedStorage.On("GetSSHKeys", mock.Anything, models.SSHKey{UserName: userName}).Return(nil, database.ErrNoData)
+				mockedStorage.On("SaveAuditEvent", mock.Anything, mock.MatchedBy(func(event models.AuditEvent) bool {
+					return event.UserName == userName && event.Event == models.AuditVaultExport
+				})).Return(tt.auditError)
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/export", h.ExportVaultHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(tt.body).
+				Post(fmt.Sprintf("%s/export", srv.URL))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			if tt.expectedCode == http.StatusOK {
+				assert.Contains(t, resp.String(), `{"version":1,`)
+				assert.Contains(t, resp.String(), tt.expectedBody)
+			} else {
+				assert.Equal(t, tt.expectedBody, resp.String())
+			}
+		})
+	}
+}
+
+func TestHandler_ImportVault(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "brienne"
+	systemPassword := "oathkeeper"
+	noteID := int64(7)
+	vaultImport := models.VaultImport{UserName: userName, Mode: models.ImportSkipDuplicates, Vault: models.Vault{
+		Version:     models.VaultVersion,
+		Credentials: []models.Credentials{{UserName: "jaime", Login: Ptr("maid"), Password: Ptr("tarth")}},
+		Notes:       []models.Note{{UserName: "jaime", Title: Ptr("oath"), Content: Ptr("kingsguard"), Audit: models.Audit{ID: &noteID, Revision: 3}}},
+	}}
+	body := fmt.Sprintf(`{"user_name": %q, "vault": {"version": 1, `+
+		`"credentials": [{"user_name": "jaime", "login": "maid", "password": "tarth"}], `+
+		`"notes": [{"user_name": "jaime", "title": "oath", "content": "kingsguard", "id": 7, "revision": 3}]}}`, userName)
+
+	testCases := []struct {
+		name         string
+		body         string
+		storageCall  bool
+		storageRes   models.ImportResult
+		storageError error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "positive: duplicates skipped",
+			body:         body,
+			storageCall:  true,
+			storageRes:   models.ImportResult{Created: 1, Skipped: 1},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"created":1,"updated":0,"skipped":1}`,
+		},
+		{
+			name:         "negative: unknown mode",
+			body:         fmt.Sprintf(`{"user_name": %q, "mode": "overwrite", "vault": {"version": 1}}`, userName),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `invalid import: неизвестный режим загрузки "overwrite"`,
+		},
+		{
+			name:         "negative: unsupported version",
+			body:         fmt.Sprintf(`{"user_name": %q, "vault": {"version": 7}}`, userName),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "invalid import: неподдерживаемая версия выгрузки 7",
+		},
+		{
+			name:         "negative: storage error rolls back the import",
+			body:         body,
+			storageCall:  true,
+			storageError: errors.New(`ошибка при загрузке заметки "oath": connection refused`),
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: "ошибка запроса пользователя \"brienne\": ошибка при загрузке заметки \"oath\": connection refused; секреты не загружены",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			if tt.storageCall {
+				mockedStorage.On("ImportVault", mock.Anything, vaultImport).Return(tt.storageRes, tt.storageError)
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/import", h.ImportVaultHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(tt.body).
+				Post(fmt.Sprintf("%s/import", srv.URL))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			assert.Equal(t, tt.expectedBody, resp.String())
+		})
+	}
+}
+
+func TestHandler_ImportBatch(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "podrick"
+	systemPassword := "squire"
+	batch := models.ImportBatch{
+		UserName:    userName,
+		DryRun:      true,
+		Credentials: []models.Credentials{{Login: Ptr("pod"), Password: Ptr("payne"), Site: Ptr("example.com")}},
+	}
+
+	testCases := []struct {
+		name                 string
+		body                 string
+		storageCall          bool
+		storageResponse      models.ImportResult
+		storageResponseError error
+		expectedCode         int
+		expectedBody         string
+	}{
+		{
+			name:        "positive: dry run report",
+			body:        fmt.Sprintf(`{"user_name": %q, "dry_run": true, "credentials": [{"login": "pod", "password": "payne", "site": "example.com"}]}`, userName),
+			storageCall: true,
+			storageResponse: models.ImportResult{Skipped: 1, Report: []models.ImportReportItem{
+				{Type: models.SecretCredentials, Name: "pod @ example.com", Action: models.ImportSkipped},
+			}},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"created":0,"updated":0,"skipped":1,"report":[{"type":"credentials","name":"pod @ example.com","action":"skipped"}]}`,
+		},
+		{
+			name:         "negative: credentials without password",
+			body:         fmt.Sprintf(`{"user_name": %q, "credentials": [{"login": "pod"}]}`, userName),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "invalid import: у учетных данных 1 не указан логин или пароль",
+		},
+		{
+			name:                 "negative: storage error",
+			body:                 fmt.Sprintf(`{"user_name": %q, "dry_run": true, "credentials": [{"login": "pod", "password": "payne", "site": "example.com"}]}`, userName),
+			storageCall:          true,
+			storageResponseError: errors.New("connection refused"),
+			expectedCode:         http.StatusInternalServerError,
+			expectedBody:         "ошибка запроса пользователя \"podrick\": connection refused",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			if tt.storageCall {
+				mockedStorage.On("ImportSecrets", mock.Anything, batch).Return(tt.storageResponse, tt.storageResponseError)
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/import/batch", h.ImportBatchHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(tt.body).
+				Post(fmt.Sprintf("%s/import/batch", srv.URL))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			assert.Equal(t, tt.expectedBody, resp.String())
+		})
+	}
+}
+
+func TestHandler_PlaintextExport(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync() // flushes buffer, if any
+	log := logger.Sugar()
+
+	userName := "varys"
+	systemPassword := "littlebirds"
+	notes := []models.Note{{UserName: userName, Title: Ptr("whispers"), Content: Ptr("king's landing")}}
+
+	testCases := []struct {
+		name         string
+		body         string
+		loginError   error
+		loginCall    bool
+		exportCall   bool
+		auditError   error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "positive: vault exported and audited",
+			body:         fmt.Sprintf(`{"user_name": %q, "password": %q, "format": "csv"}`, userName, systemPassword),
+			loginCall:    true,
+			exportCall:   true,
+			expectedCode: http.StatusOK,
+			expectedBody: `"notes":[{"user_name":"varys","title":"whispers","content":"king's landing"}]`,
+		},
+		{
+			name:         "negative: wrong password",
+			body:         fmt.Sprintf(`{"user_name": %q, "password": "spider", "format": "csv"}`, userName),
+			loginCall:    true,
+			loginError:   database.ErrInvalidCredentials,
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: "предоставлен неверный пароль для пользователя \"varys\"",
+		},
+		{
+			name:         "negative: unknown format",
+			body:         fmt.Sprintf(`{"user_name": %q, "password": %q, "format": "pdf"}`, userName, systemPassword),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `unknown export format "pdf": используйте csv, json или keepass-xml`,
+		},
+		{
+			name:         "negative: audit log unavailable",
+			body:         fmt.Sprintf(`{"user_name": %q, "password": %q, "format": "json"}`, userName, systemPassword),
+			loginCall:    true,
+			exportCall:   true,
+			auditError:   errors.New("connection refused"),
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: "ошибка запроса пользователя \"varys\": connection refused",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedStorage := mocks.NewStorage(t)
+			mockedStorage.On("Register", mock.Anything, userName, systemPassword).Return(nil)
+			if tt.loginCall {
+				mockedStorage.On("Login", mock.Anything, userName, mock.Anything).Return(tt.loginError)
+			}
+			if tt.exportCall {
+				mockedStorage.On("GetFolders", mock.Anything, models.Folder{UserName: userName}).Return(nil, database.ErrNoData)
+				mockedStorage.On("GetCredentials", mock.Anything, models.Credentials{UserName: userName}, models.ListOptions{}).Return(nil, "", database.ErrNoData)
+				mockedStorage.On("GetNotes", mock.Anything, models.Note{UserName: userName}, models.ListOptions{}).Return(notes, "", nil)
+				mockedStorage.On("GetCard", mock.Anything, models.Card{UserName: userName}, models.ListOptions{}).Return(nil, "", database.ErrNoData)
+				mockedStorage.On("GetTOTP", mock.Anything, models.TOTP{UserName: userName}).Return(nil, database.ErrNoData)
+				mockedStorage.On("GetSSHKeys", mock.Anything, models.SSHKey{UserName: userName}).Return(nil, database.ErrNoData)
+				mockedStorage.On("SaveAuditEvent", mock.Anything, mock.MatchedBy(func(event models.AuditEvent) bool {
+					return event.UserName == userName && event.Event == models.AuditPlaintextExport
+				})).Return(tt.auditError)
+			}
+
+			r := chi.NewRouter()
+			h := New(mockedStorage, log)
+			r.Post("/auth/register", h.RegisterHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(h.CheckAuthorization)
+				r.Post("/export/plaintext", h.PlaintextExportHandler)
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			_, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(fmt.Sprintf(`{"login": %q, "password": %q}`, userName, systemPassword)).
+				Post(fmt.Sprintf("%s/auth/register", srv.URL))
+			assert.NoError(t, err)
+
+			resp, err := resty.New().R().
+				SetHeader("content-type", "application/json").
+				SetBody(tt.body).
+				Post(fmt.Sprintf("%s/export/plaintext", srv.URL))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode())
+			if tt.expectedCode == http.StatusOK {
+				assert.Contains(t, resp.String(), tt.expectedBody)
+			} else {
+				assert.Equal(t, tt.expectedBody, resp.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// Package health составляет отчет о состоянии паролей пользователя: слабые, повторяющиеся и давно не менявшиеся
+// пароли, а также учетные данные без второго фактора.
+//
+// Повторы находятся сравнением HMAC-SHA256 паролей на случайном ключе, который создается для каждого отчета
+// и сразу забывается. Ни пароли, ни их хеши, пригодные для подбора, в отчет не попадают.
+package health
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ZnNr/GopherVault/internal/database"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/strength"
+)
+
+// Значения параметров отчета по умолчанию
+const (
+	DefaultMaxAgeDays = 365
+	DefaultMinScore   = 3
+)
+
+// hashLength число символов HMAC, которое показывается в отчете как идентификатор группы повторов
+const hashLength = 12
+
+// ErrInvalidRequest означает некорректные параметры отчета
+var ErrInvalidRequest = errors.New("invalid health report request")
+
+// Report составляет отчет о состоянии паролей пользователя. Учетные данные из корзины не проверяются.
+func Report(ctx context.Context, db models.Storage, request models.HealthRequest, now time.Time) (models.HealthReport, error) {
+	if request.MaxAgeDays == 0 {
+		request.MaxAgeDays = DefaultMaxAgeDays
+	}
+	if request.MinScore == 0 {
+		request.MinScore = DefaultMinScore
+	}
+	if request.MaxAgeDays < 0 {
+		return models.HealthReport{}, fmt.Errorf("%w: max_age_days должно быть положительным", ErrInvalidRequest)
+	}
+	if request.MinScore < 1 || request.MinScore > 4 {
+		return models.HealthReport{}, fmt.Errorf("%w: min_score должно быть от 1 до 4", ErrInvalidRequest)
+	}
+
+	credentials, _, err := db.GetCredentials(ctx, models.Credentials{UserName: request.UserName}, models.ListOptions{})
+	if err != nil && !errors.Is(err, database.ErrNoData) {
+		return models.HealthReport{}, err
+	}
+	totp, err := db.GetTOTP(ctx, models.TOTP{UserName: request.UserName})
+	if err != nil && !errors.Is(err, database.ErrNoData) {
+		return models.HealthReport{}, err
+	}
+
+	key := make([]byte, sha256.Size)
+	if _, err = rand.Read(key); err != nil {
+		return models.HealthReport{}, fmt.Errorf("ошибка генератора случайных чисел: %w", err)
+	}
+
+	report := models.HealthReport{
+		GeneratedAt: now.UTC(),
+		MaxAgeDays:  request.MaxAgeDays,
+		MinScore:    request.MinScore,
+		Weak:        []models.HealthItem{},
+		Reused:      []models.ReusedGroup{},
+		Old:         []models.HealthItem{},
+		Missing2FA:  []models.HealthItem{},
+	}
+	groups := map[string][]models.HealthItem{}
+	for _, creds := range credentials {
+		password := models.ValueOrEmpty(creds.Password)
+		estimate := strength.Check(password)
+		item := models.HealthItem{
+			ID:        creds.ID,
+			Name:      creds.ReportName(),
+			UpdatedAt: creds.UpdatedAt,
+			Score:     estimate.Score,
+			Warning:   estimate.Warning,
+		}
+		if creds.UpdatedAt != nil {
+			item.AgeDays = int(now.Sub(*creds.UpdatedAt).Hours() / 24)
+		}
+
+		if estimate.Score < request.MinScore {
+			report.Weak = append(report.Weak, item)
+		}
+		if item.UpdatedAt != nil && item.AgeDays >= request.MaxAgeDays {
+			report.Old = append(report.Old, item)
+		}
+		if !hasTOTP(creds, totp) {
+			report.Missing2FA = append(report.Missing2FA, item)
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(password))
+		hash := hex.EncodeToString(mac.Sum(nil))[:hashLength]
+		groups[hash] = append(groups[hash], item)
+	}
+	for hash, items := range groups {
+		if len(items) > 1 {
+			report.Reused = append(report.Reused, models.ReusedGroup{Hash: hash, Items: items})
+			report.Summary.Reused += len(items)
+		}
+	}
+
+	// Самые серьезные проблемы показываются первыми
+	sort.SliceStable(report.Weak, func(i, j int) bool { return report.Weak[i].Score < report.Weak[j].Score })
+	sort.SliceStable(report.Old, func(i, j int) bool { return report.Old[i].AgeDays > report.Old[j].AgeDays })
+	sort.Slice(report.Reused, func(i, j int) bool {
+		if len(report.Reused[i].Items) != len(report.Reused[j].Items) {
+			return len(report.Reused[i].Items) > len(report.Reused[j].Items)
+		}
+		return report.Reused[i].Hash < report.Reused[j].Hash
+	})
+
+	report.Summary.Total = len(credentials)
+	report.Summary.Weak = len(report.Weak)
+	report.Summary.Old = len(report.Old)
+	report.Summary.Missing2FA = len(report.Missing2FA)
+	return report, nil
+}
+
+// hasTOTP проверяет, привязан ли к учетным данным секрет TOTP
+func hasTOTP(creds models.Credentials, totp []models.TOTP) bool {
+	for _, t := range totp {
+		if models.ValueOrEmpty(t.Login) == models.ValueOrEmpty(creds.Login) && models.ValueOrEmpty(t.Site) == models.ValueOrEmpty(creds.Site) {
+			return true
+		}
+	}
+	return false
+}
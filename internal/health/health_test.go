@@ -0,0 +1,121 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ZnNr/GopherVault/internal/database"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/models/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Ptr(s string) *string {
+	return &s
+}
+
+func credentials(id int64, login, site, password string, updatedAt time.Time) models.Credentials {
+	return models.Credentials{
+		UserName: "bran",
+		Login:    Ptr(login),
+		Site:     Ptr(site),
+		Password: Ptr(password),
+		Audit:    models.Audit{ID: &id, UpdatedAt: &updatedAt},
+	}
+}
+
+func TestReport(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	strong := "xK9#mQ2$vL7!pR4@"
+
+	db := mocks.NewStorage(t)
+	db.On("GetCredentials", ctx, models.Credentials{UserName: "bran"}, models.ListOptions{}).Return([]models.Credentials{
+		credentials(1, "bran", "github.com", strong, now.AddDate(0, 0, -10)),
+		credentials(2, "bran", "gitlab.com", strong, now.AddDate(0, 0, -20)),
+		credentials(3, "raven", "mail.com", "password", now.AddDate(-2, 0, 0)),
+		credentials(4, "hodor", "bank.com", "Tr0ub4dor&3Zq", now.AddDate(0, 0, -400)),
+	}, "", nil)
+	db.On("GetTOTP", ctx, models.TOTP{UserName: "bran"}).Return([]models.TOTP{
+		{UserName: "bran", Name: Ptr("github"), Login: Ptr("bran"), Site: Ptr("github.com")},
+		{UserName: "bran", Name: Ptr("standalone")},
+	}, nil)
+
+	report, err := Report(ctx, db, models.HealthRequest{UserName: "bran"}, now)
+	require.NoError(t, err)
+	assert.Equal(t, now, report.GeneratedAt)
+	assert.Equal(t, DefaultMaxAgeDays, report.MaxAgeDays)
+	assert.Equal(t, DefaultMinScore, report.MinScore)
+	assert.Equal(t, models.HealthSummary{Total: 4, Weak: 1, Reused: 2, Old: 2, Missing2FA: 3}, report.Summary)
+
+	require.Len(t, report.Weak, 1)
+	assert.Equal(t, "raven @ mail.com", report.Weak[0].Name)
+	assert.Equal(t, 0, report.Weak[0].Score)
+	assert.NotEmpty(t, report.Weak[0].Warning)
+
+	require.Len(t, report.Reused, 1)
+	assert.Len(t, report.Reused[0].Hash, hashLength)
+	assert.Equal(t, int64(1), *report.Reused[0].Items[0].ID)
+	assert.Equal(t, int64(2), *report.Reused[0].Items[1].ID)
+
+	// Сначала самые старые пароли
+	require.Len(t, report.Old, 2)
+	assert.Equal(t, int64(3), *report.Old[0].ID)
+	assert.Equal(t, 731, report.Old[0].AgeDays)
+	assert.Equal(t, int64(4), *report.Old[1].ID)
+
+	ids := []int64{}
+	for _, item := range report.Missing2FA {
+		ids = append(ids, *item.ID)
+	}
+	assert.Equal(t, []int64{2, 3, 4}, ids)
+}
+
+func TestReport_HashIsKeyedPerReport(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	db := mocks.NewStorage(t)
+	db.On("GetCredentials", ctx, models.Credentials{UserName: "bran"}, models.ListOptions{}).Return([]models.Credentials{
+		credentials(1, "bran", "a.com", "winterfell", now),
+		credentials(2, "bran", "b.com", "winterfell", now),
+	}, "", nil)
+	db.On("GetTOTP", ctx, models.TOTP{UserName: "bran"}).Return(nil, database.ErrNoData)
+
+	first, err := Report(ctx, db, models.HealthRequest{UserName: "bran"}, now)
+	require.NoError(t, err)
+	second, err := Report(ctx, db, models.HealthRequest{UserName: "bran"}, now)
+	require.NoError(t, err)
+	require.Len(t, first.Reused, 1)
+	require.Len(t, second.Reused, 1)
+	assert.NotEqual(t, first.Reused[0].Hash, second.Reused[0].Hash)
+}
+
+func TestReport_Empty(t *testing.T) {
+	ctx := context.Background()
+	db := mocks.NewStorage(t)
+	db.On("GetCredentials", ctx, models.Credentials{UserName: "bran"}, models.ListOptions{}).Return(nil, "", database.ErrNoData)
+	db.On("GetTOTP", ctx, models.TOTP{UserName: "bran"}).Return(nil, database.ErrNoData)
+
+	report, err := Report(ctx, db, models.HealthRequest{UserName: "bran", MaxAgeDays: 90, MinScore: 4}, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, models.HealthSummary{}, report.Summary)
+	assert.Empty(t, report.Weak)
+	assert.NotNil(t, report.Weak)
+	assert.Equal(t, 90, report.MaxAgeDays)
+}
+
+func TestReport_Errors(t *testing.T) {
+	ctx := context.Background()
+	db := mocks.NewStorage(t)
+	_, err := Report(ctx, db, models.HealthRequest{UserName: "bran", MinScore: 5}, time.Now())
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+	_, err = Report(ctx, db, models.HealthRequest{UserName: "bran", MaxAgeDays: -1}, time.Now())
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+
+	db.On("GetCredentials", ctx, models.Credentials{UserName: "bran"}, models.ListOptions{}).Return(nil, "", errors.New("connection refused"))
+	_, err = Report(ctx, db, models.HealthRequest{UserName: "bran"}, time.Now())
+	assert.EqualError(t, err, "connection refused")
+}
@@ -0,0 +1,125 @@
+package importers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// Типы записей Bitwarden
+const (
+	bitwardenLogin = 1
+	bitwardenNote  = 2
+	bitwardenCard  = 3
+)
+
+// Типы пользовательских полей Bitwarden
+const (
+	bitwardenFieldText    = 0
+	bitwardenFieldHidden  = 1
+	bitwardenFieldBoolean = 2
+)
+
+// bitwardenExport описывает незашифрованную выгрузку Bitwarden
+type bitwardenExport struct {
+	Encrypted bool `json:"encrypted"`
+	Folders   []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"folders"`
+	Items []bitwardenItem `json:"items"`
+}
+
+type bitwardenItem struct {
+	Type     int     `json:"type"`
+	Name     string  `json:"name"`
+	Notes    *string `json:"notes"`
+	FolderID *string `json:"folderId"`
+	Fields   []struct {
+		Name  string  `json:"name"`
+		Value *string `json:"value"`
+		Type  int     `json:"type"`
+	} `json:"fields"`
+	Login *struct {
+		URIs []struct {
+			URI *string `json:"uri"`
+		} `json:"uris"`
+		Username *string `json:"username"`
+		Password *string `json:"password"`
+		TOTP     *string `json:"totp"`
+	} `json:"login"`
+	Card *struct {
+		CardholderName *string `json:"cardholderName"`
+		Brand          *string `json:"brand"`
+		Number         *string `json:"number"`
+		ExpMonth       *string `json:"expMonth"`
+		ExpYear        *string `json:"expYear"`
+		Code           *string `json:"code"`
+	} `json:"card"`
+}
+
+// parseBitwarden разбирает выгрузку Bitwarden в JSON. Папки Bitwarden переносятся как папки,
+// секрет TOTP - как скрытое пользовательское поле учетных данных.
+func parseBitwarden(data []byte) (Result, error) {
+	var export bitwardenExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return Result{}, fmt.Errorf("%w: %s", ErrInvalidFile, err)
+	}
+	if export.Encrypted {
+		return Result{}, fmt.Errorf("%w: выгрузка Bitwarden зашифрована, выгрузите хранилище в формате JSON без шифрования", ErrInvalidFile)
+	}
+	folders := make(map[string]string, len(export.Folders))
+	for _, folder := range export.Folders {
+		folders[folder.ID] = folder.Name
+	}
+
+	var res Result
+	for _, item := range export.Items {
+		e := entry{name: item.Name, notes: models.ValueOrEmpty(item.Notes)}
+		if item.FolderID != nil {
+			e.folder = folders[*item.FolderID]
+		}
+		for _, field := range item.Fields {
+			switch field.Type {
+			case bitwardenFieldText:
+				e.fields = append(e.fields, models.CustomField{Name: field.Name, Type: models.FieldText, Value: models.ValueOrEmpty(field.Value)})
+			case bitwardenFieldHidden:
+				e.fields = append(e.fields, models.CustomField{Name: field.Name, Type: models.FieldHidden, Value: models.ValueOrEmpty(field.Value)})
+			case bitwardenFieldBoolean:
+				e.fields = append(e.fields, models.CustomField{Name: field.Name, Type: models.FieldBoolean, Value: models.ValueOrEmpty(field.Value)})
+			}
+		}
+
+		switch item.Type {
+		case bitwardenLogin:
+			if item.Login != nil {
+				e.login, e.password = models.ValueOrEmpty(item.Login.Username), models.ValueOrEmpty(item.Login.Password)
+				for _, uri := range item.Login.URIs {
+					e.urls = append(e.urls, models.ValueOrEmpty(uri.URI))
+				}
+				if totp := models.ValueOrEmpty(item.Login.TOTP); totp != "" {
+					e.fields = append(e.fields, models.CustomField{Name: "TOTP", Type: models.FieldHidden, Value: totp})
+				}
+			}
+			res.Credentials = append(res.Credentials, e.credentials())
+		case bitwardenNote:
+			res.Notes = append(res.Notes, e.note())
+		case bitwardenCard:
+			if item.Card == nil {
+				return Result{}, fmt.Errorf("%w: у карты %q нет данных карты", ErrInvalidFile, item.Name)
+			}
+			if holder := models.ValueOrEmpty(item.Card.CardholderName); holder != "" {
+				e.fields = append(e.fields, models.CustomField{Name: "Cardholder", Type: models.FieldText, Value: holder})
+			}
+			if month, year := models.ValueOrEmpty(item.Card.ExpMonth), models.ValueOrEmpty(item.Card.ExpYear); month != "" || year != "" {
+				e.fields = append(e.fields, models.CustomField{Name: "Expiry", Type: models.FieldText, Value: month + "/" + year})
+			}
+			res.Cards = append(res.Cards, e.card(models.ValueOrEmpty(item.Card.Number), models.ValueOrEmpty(item.Card.Code), models.ValueOrEmpty(item.Card.Brand)))
+		default:
+			// Удостоверения личности и другие типы записей не переносятся
+			res.Unsupported = append(res.Unsupported, item.Name)
+		}
+	}
+	return res, nil
+}
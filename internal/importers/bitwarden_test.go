@@ -0,0 +1,61 @@
+package importers
+
+import (
+	"testing"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBitwarden(t *testing.T) {
+	data := []byte(`{
+  "encrypted": false,
+  "folders": [{"id": "f1", "name": "Work"}],
+  "items": [
+    {"type": 1, "name": "Mail", "folderId": "f1", "notes": "main box",
+     "fields": [{"name": "recovery", "value": "r3c", "type": 1}, {"name": "linked", "value": null, "type": 3}],
+     "login": {"uris": [{"match": null, "uri": "https://mail.example.com/login"}], "username": "arya", "password": "needle", "totp": "JBSWY3DP"}},
+    {"type": 2, "name": "List", "folderId": null, "notes": "cersei, joffrey", "secureNote": {"type": 0}},
+    {"type": 3, "name": "Iron Bank", "notes": null,
+     "card": {"cardholderName": "Arya Stark", "brand": "Visa", "number": "4111111111111111", "expMonth": "12", "expYear": "2030", "code": "123"}},
+    {"type": 4, "name": "Passport", "identity": {"firstName": "Arya"}}
+  ]
+}`)
+
+	res, err := parseBitwarden(data)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Credentials{{
+		Login:    Ptr("arya"),
+		Password: Ptr("needle"),
+		Site:     Ptr("mail.example.com"),
+		Name:     Ptr("Mail"),
+		Metadata: Ptr("main box"),
+		URLs:     []models.CredentialURL{{URL: "https://mail.example.com/login"}},
+		Fields: []models.CustomField{
+			{Name: "recovery", Type: models.FieldHidden, Value: "r3c"},
+			{Name: "TOTP", Type: models.FieldHidden, Value: "JBSWY3DP"},
+		},
+		Folder: Ptr("Work"),
+	}}, res.Credentials)
+	assert.Equal(t, []models.Note{{Title: Ptr("List"), Content: Ptr("cersei, joffrey")}}, res.Notes)
+	assert.Equal(t, []models.Card{{
+		BankName: Ptr("Iron Bank"),
+		Number:   Ptr("4111111111111111"),
+		CV:       Ptr("123"),
+		Password: Ptr(""),
+		CardType: Ptr("Visa"),
+		Fields: []models.CustomField{
+			{Name: "Cardholder", Type: models.FieldText, Value: "Arya Stark"},
+			{Name: "Expiry", Type: models.FieldText, Value: "12/2030"},
+		},
+	}}, res.Cards)
+	assert.Equal(t, []string{"Passport"}, res.Unsupported)
+}
+
+func TestParseBitwarden_Invalid(t *testing.T) {
+	_, err := parseBitwarden([]byte(`{"encrypted": true, "items": []}`))
+	assert.ErrorIs(t, err, ErrInvalidFile)
+
+	_, err = parseBitwarden([]byte(`name,password`))
+	assert.ErrorIs(t, err, ErrInvalidFile)
+}
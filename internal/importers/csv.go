@@ -0,0 +1,134 @@
+package importers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Поля секрета, которые заполняются из столбцов CSV
+const (
+	columnName     = "name"
+	columnURL      = "url"
+	columnLogin    = "login"
+	columnPassword = "password"
+	columnNotes    = "notes"
+	columnFolder   = "folder"
+	columnTags     = "tags"
+)
+
+// chromeColumns столбцы выгрузки паролей Chrome
+var chromeColumns = map[string][]string{
+	columnName:     {"name"},
+	columnURL:      {"url"},
+	columnLogin:    {"username"},
+	columnPassword: {"password"},
+	columnNotes:    {"note"},
+}
+
+// genericAliases стандартные названия столбцов для каждого поля секрета
+var genericAliases = map[string][]string{
+	columnName:     {"name", "title"},
+	columnURL:      {"url", "uri", "website", "login_uri"},
+	columnLogin:    {"login", "username", "user", "login_username", "email"},
+	columnPassword: {"password", "login_password"},
+	columnNotes:    {"notes", "note", "comments", "extra"},
+	columnFolder:   {"folder", "group", "grouping"},
+	columnTags:     {"tags"},
+}
+
+// genericColumns дополняет стандартные названия столбцов сопоставлением mapping.
+// Столбец из mapping проверяется раньше стандартных.
+func genericColumns(mapping map[string]string) (map[string][]string, error) {
+	columns := make(map[string][]string, len(genericAliases))
+	for field, aliases := range genericAliases {
+		columns[field] = aliases
+	}
+	for field, column := range mapping {
+		if _, ok := genericAliases[field]; !ok {
+			fields := make([]string, 0, len(genericAliases))
+			for name := range genericAliases {
+				fields = append(fields, name)
+			}
+			sort.Strings(fields)
+			return nil, fmt.Errorf("%w: неизвестное поле %q в сопоставлении столбцов, используйте %s",
+				ErrInvalidFile, field, strings.Join(fields, ", "))
+		}
+		columns[field] = append([]string{column}, columns[field]...)
+	}
+	return columns, nil
+}
+
+// parseCSV разбирает CSV с заголовком. Названия столбцов сравниваются без учета регистра,
+// для каждого поля используется первый найденный столбец из columns.
+// Строки без логина, пароля и адреса переносятся как заметки, пустые строки пропускаются.
+func parseCSV(data []byte, columns map[string][]string) (Result, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %s", ErrInvalidFile, err)
+	}
+	if len(records) == 0 {
+		return Result{}, fmt.Errorf("%w: в файле нет заголовка", ErrInvalidFile)
+	}
+
+	header := make(map[string]int, len(records[0]))
+	for i, column := range records[0] {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if _, ok := header[column]; !ok {
+			header[column] = i
+		}
+	}
+	index := make(map[string]int, len(columns))
+	for field, aliases := range columns {
+		for _, alias := range aliases {
+			if i, ok := header[strings.ToLower(alias)]; ok {
+				index[field] = i
+				break
+			}
+		}
+	}
+	if _, ok := index[columnLogin]; !ok {
+		if _, ok = index[columnPassword]; !ok {
+			return Result{}, fmt.Errorf("%w: не найдены столбцы логина и пароля, укажите их сопоставлением столбцов", ErrInvalidFile)
+		}
+	}
+
+	var res Result
+	for _, record := range records[1:] {
+		// value возвращает значение поля строки или пустую строку, если столбца нет
+		value := func(field string) string {
+			i, ok := index[field]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return record[i]
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		e := entry{
+			name:     value(columnName),
+			login:    value(columnLogin),
+			password: value(columnPassword),
+			notes:    value(columnNotes),
+			folder:   value(columnFolder),
+			tags:     splitTags(value(columnTags)),
+		}
+		// Несколько адресов записываются через запятую, как в выгрузке Bitwarden
+		for _, u := range strings.Split(value(columnURL), ",") {
+			if u = strings.TrimSpace(u); u != "" {
+				e.urls = append(e.urls, u)
+			}
+		}
+		if e.isNote() {
+			res.Notes = append(res.Notes, e.note())
+		} else {
+			res.Credentials = append(res.Credentials, e.credentials())
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,56 @@
+package importers
+
+import (
+	"testing"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCSV_Chrome(t *testing.T) {
+	data := []byte("\ufeffname,url,username,password,note\n" +
+		"mail.example.com,https://mail.example.com/,arya,needle,main box\n" +
+		",,,,\n")
+
+	res, err := Parse(FormatChromeCSV, data, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Credentials{{
+		Login:    Ptr("arya"),
+		Password: Ptr("needle"),
+		Site:     Ptr("mail.example.com"),
+		Name:     Ptr("mail.example.com"),
+		Metadata: Ptr("main box"),
+		URLs:     []models.CredentialURL{{URL: "https://mail.example.com/"}},
+	}}, res.Credentials)
+	assert.Empty(t, res.Notes)
+}
+
+func TestParseCSV_Generic(t *testing.T) {
+	data := []byte("Title,Web Site,E-mail,Secret,Comments,Group,Tags\n" +
+		"Mail,mail.example.com,arya@example.com,needle,,Personal,\"mail, main\"\n" +
+		"Wifi,,,,guest: winter,Home,\n")
+
+	t.Run("positive: mapped columns", func(t *testing.T) {
+		res, err := Parse(FormatGenericCSV, data, Options{Mapping: map[string]string{"url": "Web Site", "login": "E-mail", "password": "Secret"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Credentials{{
+			Login:    Ptr("arya@example.com"),
+			Password: Ptr("needle"),
+			Site:     Ptr("mail.example.com"),
+			Name:     Ptr("Mail"),
+			URLs:     []models.CredentialURL{{URL: "mail.example.com"}},
+			Folder:   Ptr("Personal"),
+			Tags:     []string{"mail", "main"},
+		}}, res.Credentials)
+		assert.Equal(t, []models.Note{{Title: Ptr("Wifi"), Content: Ptr("guest: winter"), Folder: Ptr("Home")}}, res.Notes)
+	})
+	t.Run("negative: unknown field in mapping", func(t *testing.T) {
+		_, err := Parse(FormatGenericCSV, data, Options{Mapping: map[string]string{"pin": "Secret"}})
+		assert.EqualError(t, err, `invalid import file: неизвестное поле "pin" в сопоставлении столбцов, `+
+			"используйте folder, login, name, notes, password, tags, url")
+	})
+	t.Run("negative: no login and password columns", func(t *testing.T) {
+		_, err := Parse(FormatGenericCSV, []byte("Title,Comments\nWifi,guest\n"), Options{})
+		assert.ErrorIs(t, err, ErrInvalidFile)
+	})
+}
@@ -0,0 +1,225 @@
+// Package importers разбирает файлы выгрузки других менеджеров паролей и преобразует их записи
+// в учетные данные, заметки и карты GopherVault.
+//
+// Разбор выполняется на клиенте: файлы выгрузки не зашифрованы и не передаются на сервер целиком,
+// сервер получает только подготовленные пакеты секретов.
+package importers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// Format определяет формат файла выгрузки
+type Format string
+
+const (
+	FormatBitwarden  Format = "bitwarden-json" // незашифрованная выгрузка Bitwarden в JSON
+	FormatKeePass    Format = "keepass-xml"    // выгрузка KeePass 2.x в XML
+	Format1Password  Format = "1password-1pux" // выгрузка 1Password в формате 1PUX
+	FormatChromeCSV  Format = "chrome-csv"     // выгрузка паролей Chrome в CSV
+	FormatGenericCSV Format = "generic-csv"    // CSV с произвольными столбцами
+)
+
+var (
+	// ErrUnknownFormat означает неподдерживаемый формат файла выгрузки
+	ErrUnknownFormat = errors.New("unknown import format")
+	// ErrInvalidFile означает, что файл не соответствует указанному формату
+	ErrInvalidFile = errors.New("invalid import file")
+)
+
+// Options задает параметры разбора файла выгрузки
+type Options struct {
+	// Mapping сопоставляет поля секрета (name, url, login, password, notes, folder, tags) со столбцами CSV.
+	// Используется только для формата generic-csv и дополняет стандартные названия столбцов.
+	Mapping map[string]string
+}
+
+// Result содержит секреты, прочитанные из файла выгрузки
+type Result struct {
+	Credentials []models.Credentials
+	Notes       []models.Note
+	Cards       []models.Card
+	Duplicates  []models.ImportReportItem // Повторы секретов внутри файла, которые не будут загружены
+	Unsupported []string                  // Записи, тип которых не переносится, например удостоверения личности
+}
+
+// Parse разбирает файл выгрузки формата format. Повторы секретов внутри файла
+// отбрасываются и попадают в Duplicates.
+func Parse(format Format, data []byte, opts Options) (Result, error) {
+	var (
+		res Result
+		err error
+	)
+	switch format {
+	case FormatBitwarden:
+		res, err = parseBitwarden(data)
+	case FormatKeePass:
+		res, err = parseKeePass(data)
+	case Format1Password:
+		res, err = parse1Password(data)
+	case FormatChromeCSV:
+		res, err = parseCSV(data, chromeColumns)
+	case FormatGenericCSV:
+		var columns map[string][]string
+		if columns, err = genericColumns(opts.Mapping); err == nil {
+			res, err = parseCSV(data, columns)
+		}
+	default:
+		return Result{}, fmt.Errorf("%w %q: используйте bitwarden-json, keepass-xml, 1password-1pux, chrome-csv или generic-csv", ErrUnknownFormat, format)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	res.dedup()
+	return res, nil
+}
+
+// dedup оставляет первое вхождение каждого секрета. Ключи совпадают с ключами, по которым
+// сервер пропускает уже сохраненные секреты: логин и сайт, заголовок заметки, номер карты.
+func (r *Result) dedup() {
+	seen := make(map[string]bool)
+	// duplicate отмечает ключ и сообщает, встречался ли он раньше
+	duplicate := func(secretType models.SecretType, key, name string) bool {
+		key = string(secretType) + "\x00" + key
+		if !seen[key] {
+			seen[key] = true
+			return false
+		}
+		r.Duplicates = append(r.Duplicates, models.ImportReportItem{Type: secretType, Name: name, Action: models.ImportSkipped})
+		return true
+	}
+
+	credentials := r.Credentials[:0]
+	for _, creds := range r.Credentials {
+		if !duplicate(models.SecretCredentials, *creds.Login+"\x00"+*creds.Site, creds.ReportName()) {
+			credentials = append(credentials, creds)
+		}
+	}
+	r.Credentials = credentials
+
+	notes := r.Notes[:0]
+	for _, note := range r.Notes {
+		if !duplicate(models.SecretNote, *note.Title, note.ReportName()) {
+			notes = append(notes, note)
+		}
+	}
+	r.Notes = notes
+
+	cards := r.Cards[:0]
+	for _, card := range r.Cards {
+		if !duplicate(models.SecretCard, *card.Number, card.ReportName()) {
+			cards = append(cards, card)
+		}
+	}
+	r.Cards = cards
+}
+
+// entry содержит общие поля записи любого менеджера паролей
+type entry struct {
+	name     string
+	login    string
+	password string
+	notes    string
+	urls     []string
+	folder   string
+	tags     []string
+	fields   []models.CustomField
+}
+
+// credentials преобразует запись в учетные данные. Сайтом считается хост первого адреса,
+// а если адресов нет - название записи.
+func (e entry) credentials() models.Credentials {
+	creds := models.Credentials{
+		Login:    ptr(e.login),
+		Password: ptr(e.password),
+		Site:     ptr(siteOf(e.name, e.urls)),
+		Name:     optional(e.name),
+		Metadata: optional(e.notes),
+		Fields:   e.fields,
+		Folder:   optional(e.folder),
+		Tags:     e.tags,
+	}
+	for _, u := range e.urls {
+		if u = strings.TrimSpace(u); u != "" {
+			creds.URLs = append(creds.URLs, models.CredentialURL{URL: u})
+		}
+	}
+	return creds
+}
+
+// note преобразует запись в заметку с текстом из поля заметок
+func (e entry) note() models.Note {
+	return models.Note{
+		Title:   ptr(e.name),
+		Content: ptr(e.notes),
+		Fields:  e.fields,
+		Folder:  optional(e.folder),
+		Tags:    e.tags,
+	}
+}
+
+// card преобразует запись в карту. Банком считается название записи, PIN-код переносится из пароля записи.
+func (e entry) card(number, cv, cardType string) models.Card {
+	return models.Card{
+		BankName: ptr(e.name),
+		Number:   ptr(number),
+		CV:       ptr(cv),
+		Password: ptr(e.password),
+		CardType: optional(cardType),
+		Metadata: optional(e.notes),
+		Fields:   e.fields,
+		Folder:   optional(e.folder),
+		Tags:     e.tags,
+	}
+}
+
+// isNote сообщает, что у записи нет логина, пароля и адресов и ее следует перенести как заметку
+func (e entry) isNote() bool {
+	return e.login == "" && e.password == "" && len(e.urls) == 0 && e.notes != ""
+}
+
+// siteOf возвращает хост первого адреса из urls или название записи
+func siteOf(name string, urls []string) string {
+	for _, raw := range urls {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		if !strings.Contains(raw, "://") {
+			raw = "https://" + raw
+		}
+		if u, err := url.Parse(raw); err == nil && u.Hostname() != "" {
+			return u.Hostname()
+		}
+	}
+	return name
+}
+
+// splitTags разбирает список тегов, разделенных запятыми или точками с запятой
+func splitTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' }) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// ptr возвращает указатель на строку
+func ptr(s string) *string {
+	return &s
+}
+
+// optional возвращает указатель на непустую строку или nil
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,38 @@
+package importers
+
+import (
+	"testing"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func Ptr(s string) *string {
+	return &s
+}
+
+func TestParse_Duplicates(t *testing.T) {
+	data := []byte("name,url,username,password\n" +
+		"mail,https://mail.example.com/login,arya,needle\n" +
+		"mail again,https://mail.example.com,arya,other\n" +
+		"shop,https://shop.example.com,arya,needle\n")
+
+	res, err := Parse(FormatChromeCSV, data, Options{})
+	assert.NoError(t, err)
+	assert.Len(t, res.Credentials, 2)
+	assert.Equal(t, "shop.example.com", *res.Credentials[1].Site)
+	assert.Equal(t, []models.ImportReportItem{
+		{Type: models.SecretCredentials, Name: "arya @ mail.example.com", Action: models.ImportSkipped},
+	}, res.Duplicates)
+}
+
+func TestParse_UnknownFormat(t *testing.T) {
+	_, err := Parse("lastpass", nil, Options{})
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestSiteOf(t *testing.T) {
+	assert.Equal(t, "mail.example.com", siteOf("mail", []string{"", "https://mail.example.com:8443/login"}))
+	assert.Equal(t, "example.com", siteOf("mail", []string{"example.com/path"}))
+	assert.Equal(t, "mail", siteOf("mail", nil))
+}
@@ -0,0 +1,105 @@
+package importers
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// Стандартные поля записи KeePass; остальные поля переносятся как пользовательские
+const (
+	keePassTitle    = "Title"
+	keePassUserName = "UserName"
+	keePassPassword = "Password"
+	keePassURL      = "URL"
+	keePassNotes    = "Notes"
+)
+
+// keePassFile описывает выгрузку KeePass 2.x в XML
+type keePassFile struct {
+	XMLName xml.Name `xml:"KeePassFile"`
+	Meta    struct {
+		RecycleBinUUID string `xml:"RecycleBinUUID"`
+	} `xml:"Meta"`
+	Root struct {
+		Groups []keePassGroup `xml:"Group"`
+	} `xml:"Root"`
+}
+
+type keePassGroup struct {
+	UUID    string         `xml:"UUID"`
+	Name    string         `xml:"Name"`
+	Entries []keePassEntry `xml:"Entry"`
+	Groups  []keePassGroup `xml:"Group"`
+}
+
+type keePassEntry struct {
+	Strings []struct {
+		Key   string `xml:"Key"`
+		Value struct {
+			Text      string `xml:",chardata"`
+			Protected bool   `xml:"Protected,attr"`
+		} `xml:"Value"`
+	} `xml:"String"`
+	Tags string `xml:"Tags"`
+}
+
+// parseKeePass разбирает выгрузку KeePass 2.x в XML. Путь группы записи без корневой группы становится папкой,
+// записи из корзины не переносятся. Записи без логина, пароля и адреса переносятся как заметки.
+func parseKeePass(data []byte) (Result, error) {
+	var file keePassFile
+	if err := xml.Unmarshal(data, &file); err != nil {
+		return Result{}, fmt.Errorf("%w: %s", ErrInvalidFile, err)
+	}
+
+	var res Result
+	var walk func(group keePassGroup, folder string)
+	walk = func(group keePassGroup, folder string) {
+		if file.Meta.RecycleBinUUID != "" && group.UUID == file.Meta.RecycleBinUUID {
+			return
+		}
+		for _, kpEntry := range group.Entries {
+			e := entry{folder: folder, tags: splitTags(kpEntry.Tags)}
+			for _, s := range kpEntry.Strings {
+				switch s.Key {
+				case keePassTitle:
+					e.name = s.Value.Text
+				case keePassUserName:
+					e.login = s.Value.Text
+				case keePassPassword:
+					e.password = s.Value.Text
+				case keePassURL:
+					if s.Value.Text != "" {
+						e.urls = append(e.urls, s.Value.Text)
+					}
+				case keePassNotes:
+					e.notes = s.Value.Text
+				default:
+					fieldType := models.FieldText
+					if s.Value.Protected {
+						fieldType = models.FieldHidden
+					}
+					e.fields = append(e.fields, models.CustomField{Name: s.Key, Type: fieldType, Value: s.Value.Text})
+				}
+			}
+			if e.isNote() {
+				res.Notes = append(res.Notes, e.note())
+			} else {
+				res.Credentials = append(res.Credentials, e.credentials())
+			}
+		}
+		for _, child := range group.Groups {
+			path := child.Name
+			if folder != "" {
+				path = folder + "/" + child.Name
+			}
+			walk(child, path)
+		}
+	}
+	// Корневая группа соответствует всей базе KeePass, поэтому ее записи переносятся без папки
+	for _, root := range file.Root.Groups {
+		walk(root, "")
+	}
+	return res, nil
+}
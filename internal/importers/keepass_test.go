@@ -0,0 +1,82 @@
+package importers
+
+import (
+	"testing"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKeePass(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<KeePassFile>
+	<Meta><RecycleBinUUID>bin</RecycleBinUUID></Meta>
+	<Root>
+		<Group>
+			<UUID>root</UUID>
+			<Name>Database</Name>
+			<Entry>
+				<String><Key>Title</Key><Value>Router</Value></String>
+				<String><Key>UserName</Key><Value>admin</Value></String>
+				<String><Key>Password</Key><Value Protected="True">hodor</Value></String>
+				<String><Key>URL</Key><Value></Value></String>
+				<Tags>home;network</Tags>
+			</Entry>
+			<Group>
+				<UUID>work</UUID>
+				<Name>Work</Name>
+				<Group>
+					<UUID>projects</UUID>
+					<Name>Projects</Name>
+					<Entry>
+						<String><Key>Title</Key><Value>Git</Value></String>
+						<String><Key>UserName</Key><Value>arya</Value></String>
+						<String><Key>Password</Key><Value Protected="True">needle</Value></String>
+						<String><Key>URL</Key><Value>https://git.example.com</Value></String>
+						<String><Key>Notes</Key><Value>ci account</Value></String>
+						<String><Key>Token</Key><Value Protected="True">t0k3n</Value></String>
+						<String><Key>Team</Key><Value>platform</Value></String>
+						<History>
+							<Entry><String><Key>Title</Key><Value>Old git</Value></String></Entry>
+						</History>
+					</Entry>
+					<Entry>
+						<String><Key>Title</Key><Value>Wifi</Value></String>
+						<String><Key>Notes</Key><Value>guest: winter</Value></String>
+					</Entry>
+				</Group>
+			</Group>
+			<Group>
+				<UUID>bin</UUID>
+				<Name>Recycle Bin</Name>
+				<Entry><String><Key>Title</Key><Value>Deleted</Value></String></Entry>
+			</Group>
+		</Group>
+	</Root>
+</KeePassFile>`)
+
+	res, err := parseKeePass(data)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Credentials{
+		{Login: Ptr("admin"), Password: Ptr("hodor"), Site: Ptr("Router"), Name: Ptr("Router"), Tags: []string{"home", "network"}},
+		{
+			Login:    Ptr("arya"),
+			Password: Ptr("needle"),
+			Site:     Ptr("git.example.com"),
+			Name:     Ptr("Git"),
+			Metadata: Ptr("ci account"),
+			URLs:     []models.CredentialURL{{URL: "https://git.example.com"}},
+			Fields: []models.CustomField{
+				{Name: "Token", Type: models.FieldHidden, Value: "t0k3n"},
+				{Name: "Team", Type: models.FieldText, Value: "platform"},
+			},
+			Folder: Ptr("Work/Projects"),
+		},
+	}, res.Credentials)
+	assert.Equal(t, []models.Note{{Title: Ptr("Wifi"), Content: Ptr("guest: winter"), Folder: Ptr("Work/Projects")}}, res.Notes)
+}
+
+func TestParseKeePass_Invalid(t *testing.T) {
+	_, err := parseKeePass([]byte(`<Database></Database>`))
+	assert.ErrorIs(t, err, ErrInvalidFile)
+}
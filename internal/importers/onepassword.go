@@ -0,0 +1,202 @@
+package importers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// Категории записей 1Password
+const (
+	onePasswordLogin = "001"
+	onePasswordCard  = "002"
+	onePasswordNote  = "003"
+)
+
+// onePasswordData имя файла с записями внутри архива 1PUX
+const onePasswordData = "export.data"
+
+// onePasswordExport описывает файл export.data из архива 1PUX
+type onePasswordExport struct {
+	Accounts []struct {
+		Vaults []struct {
+			Attrs struct {
+				Name string `json:"name"`
+			} `json:"attrs"`
+			Items []onePasswordItem `json:"items"`
+		} `json:"vaults"`
+	} `json:"accounts"`
+}
+
+type onePasswordItem struct {
+	State        string `json:"state"`
+	CategoryUUID string `json:"categoryUuid"`
+	Overview     struct {
+		Title string `json:"title"`
+		URL   string `json:"url"`
+		URLs  []struct {
+			URL string `json:"url"`
+		} `json:"urls"`
+		Tags []string `json:"tags"`
+	} `json:"overview"`
+	Details struct {
+		LoginFields []struct {
+			Value       string `json:"value"`
+			Designation string `json:"designation"`
+		} `json:"loginFields"`
+		NotesPlain string `json:"notesPlain"`
+		Password   string `json:"password"`
+		Sections   []struct {
+			Fields []struct {
+				Title string                     `json:"title"`
+				ID    string                     `json:"id"`
+				Value map[string]json.RawMessage `json:"value"`
+			} `json:"fields"`
+		} `json:"sections"`
+	} `json:"details"`
+}
+
+// parse1Password разбирает архив 1PUX. Название хранилища 1Password становится папкой, записи из корзины
+// не переносятся. Поля разделов записи переносятся как пользовательские, поля карты - как данные карты.
+func parse1Password(data []byte) (Result, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %s", ErrInvalidFile, err)
+	}
+	file, err := archive.Open(onePasswordData)
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: в архиве нет файла %s", ErrInvalidFile, onePasswordData)
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %s", ErrInvalidFile, err)
+	}
+	var export onePasswordExport
+	if err = json.Unmarshal(content, &export); err != nil {
+		return Result{}, fmt.Errorf("%w: %s", ErrInvalidFile, err)
+	}
+
+	var res Result
+	for _, account := range export.Accounts {
+		for _, vault := range account.Vaults {
+			for _, item := range vault.Items {
+				if item.State == "trashed" {
+					continue
+				}
+				e := entry{
+					name:     item.Overview.Title,
+					password: item.Details.Password,
+					notes:    item.Details.NotesPlain,
+					folder:   vault.Attrs.Name,
+					tags:     item.Overview.Tags,
+				}
+				for _, u := range item.Overview.URLs {
+					e.urls = append(e.urls, u.URL)
+				}
+				if len(e.urls) == 0 && item.Overview.URL != "" {
+					e.urls = append(e.urls, item.Overview.URL)
+				}
+				for _, field := range item.Details.LoginFields {
+					switch field.Designation {
+					case "username":
+						e.login = field.Value
+					case "password":
+						e.password = field.Value
+					}
+				}
+
+				// Данные карты хранятся в полях раздела с известными идентификаторами
+				card := map[string]string{}
+				for _, section := range item.Details.Sections {
+					for _, field := range section.Fields {
+						value, fieldType := onePasswordValue(field.Value)
+						if item.CategoryUUID == onePasswordCard {
+							switch field.ID {
+							case "ccnum", "cvv", "type", "bank", "pin":
+								card[field.ID] = value
+								continue
+							}
+						}
+						if value == "" {
+							continue
+						}
+						name := field.Title
+						if name == "" {
+							name = field.ID
+						}
+						e.fields = append(e.fields, models.CustomField{Name: name, Type: fieldType, Value: value})
+					}
+				}
+
+				switch item.CategoryUUID {
+				case onePasswordLogin:
+					res.Credentials = append(res.Credentials, e.credentials())
+				case onePasswordNote:
+					res.Notes = append(res.Notes, e.note())
+				case onePasswordCard:
+					e.password = card["pin"]
+					if card["bank"] != "" {
+						e.name = card["bank"]
+					}
+					res.Cards = append(res.Cards, e.card(card["ccnum"], card["cvv"], card["type"]))
+				default:
+					res.Unsupported = append(res.Unsupported, item.Overview.Title)
+				}
+			}
+		}
+	}
+	return res, nil
+}
+
+// onePasswordValue возвращает значение поля 1Password и тип пользовательского поля для него.
+// Значение поля - объект с одним ключом, который определяет тип значения.
+func onePasswordValue(value map[string]json.RawMessage) (string, models.FieldType) {
+	for kind, raw := range value {
+		switch kind {
+		case "concealed", "totp", "creditCardNumber":
+			var s string
+			_ = json.Unmarshal(raw, &s)
+			return s, models.FieldHidden
+		case "email":
+			var email struct {
+				Address string `json:"email_address"`
+			}
+			if err := json.Unmarshal(raw, &email); err != nil {
+				// В старых выгрузках адрес хранится строкой
+				_ = json.Unmarshal(raw, &email.Address)
+			}
+			return email.Address, models.FieldEmail
+		case "url":
+			var s string
+			_ = json.Unmarshal(raw, &s)
+			return s, models.FieldURL
+		case "monthYear":
+			// Срок действия хранится числом ГГГГММ
+			var n int64
+			if err := json.Unmarshal(raw, &n); err != nil || n == 0 {
+				return "", models.FieldText
+			}
+			return fmt.Sprintf("%02d/%d", n%100, n/100), models.FieldText
+		case "date":
+			// Дата хранится временем Unix в секундах
+			var n int64
+			if err := json.Unmarshal(raw, &n); err != nil || n == 0 {
+				return "", models.FieldDate
+			}
+			return time.Unix(n, 0).UTC().Format(time.DateOnly), models.FieldDate
+		default:
+			var s string
+			if err := json.Unmarshal(raw, &s); err != nil {
+				return "", models.FieldText
+			}
+			return s, models.FieldText
+		}
+	}
+	return "", models.FieldText
+}
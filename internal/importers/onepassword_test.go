@@ -0,0 +1,93 @@
+package importers
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// onePasswordArchive упаковывает содержимое export.data в архив 1PUX
+func onePasswordArchive(t *testing.T, exportData string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.Create("export.attributes")
+	assert.NoError(t, err)
+	_, err = f.Write([]byte(`{"version": 3}`))
+	assert.NoError(t, err)
+	f, err = w.Create(onePasswordData)
+	assert.NoError(t, err)
+	_, err = f.Write([]byte(exportData))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestParse1Password(t *testing.T) {
+	data := onePasswordArchive(t, `{"accounts": [{"attrs": {"name": "Stark"}, "vaults": [{"attrs": {"name": "Private"}, "items": [
+  {"uuid": "a", "state": "active", "categoryUuid": "001",
+   "overview": {"title": "Mail", "url": "https://mail.example.com", "urls": [{"label": "", "url": "https://mail.example.com"}], "tags": ["mail"]},
+   "details": {"loginFields": [{"value": "arya", "name": "username", "fieldType": "T", "designation": "username"},
+                               {"value": "needle", "name": "password", "fieldType": "P", "designation": "password"}],
+               "notesPlain": "",
+               "sections": [{"title": "", "fields": [{"title": "one-time password", "id": "TOTP_1", "value": {"totp": "JBSWY3DP"}},
+                                                     {"title": "recovery email", "id": "e", "value": {"email": {"email_address": "arya@example.com", "provider": null}}}]}]}},
+  {"uuid": "b", "state": "active", "categoryUuid": "003",
+   "overview": {"title": "List"}, "details": {"notesPlain": "cersei, joffrey", "sections": []}},
+  {"uuid": "c", "state": "active", "categoryUuid": "002",
+   "overview": {"title": "Visa"},
+   "details": {"notesPlain": "", "sections": [{"title": "", "fields": [
+     {"title": "cardholder name", "id": "cardholder", "value": {"string": "Arya Stark"}},
+     {"title": "type", "id": "type", "value": {"creditCardType": "visa"}},
+     {"title": "number", "id": "ccnum", "value": {"creditCardNumber": "4111111111111111"}},
+     {"title": "verification number", "id": "cvv", "value": {"concealed": "123"}},
+     {"title": "expiry date", "id": "expiry", "value": {"monthYear": 203012}},
+     {"title": "issuing bank", "id": "bank", "value": {"string": "Iron Bank"}},
+     {"title": "PIN", "id": "pin", "value": {"concealed": "0000"}}]}]}},
+  {"uuid": "d", "state": "trashed", "categoryUuid": "001", "overview": {"title": "Old"}, "details": {}},
+  {"uuid": "e", "state": "active", "categoryUuid": "006", "overview": {"title": "Scan"}, "details": {}}
+]}]}]}`)
+
+	res, err := parse1Password(data)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Credentials{{
+		Login:    Ptr("arya"),
+		Password: Ptr("needle"),
+		Site:     Ptr("mail.example.com"),
+		Name:     Ptr("Mail"),
+		URLs:     []models.CredentialURL{{URL: "https://mail.example.com"}},
+		Fields: []models.CustomField{
+			{Name: "one-time password", Type: models.FieldHidden, Value: "JBSWY3DP"},
+			{Name: "recovery email", Type: models.FieldEmail, Value: "arya@example.com"},
+		},
+		Folder: Ptr("Private"),
+		Tags:   []string{"mail"},
+	}}, res.Credentials)
+	assert.Equal(t, []models.Note{{Title: Ptr("List"), Content: Ptr("cersei, joffrey"), Folder: Ptr("Private")}}, res.Notes)
+	assert.Equal(t, []models.Card{{
+		BankName: Ptr("Iron Bank"),
+		Number:   Ptr("4111111111111111"),
+		CV:       Ptr("123"),
+		Password: Ptr("0000"),
+		CardType: Ptr("visa"),
+		Fields: []models.CustomField{
+			{Name: "cardholder name", Type: models.FieldText, Value: "Arya Stark"},
+			{Name: "expiry date", Type: models.FieldText, Value: "12/2030"},
+		},
+		Folder: Ptr("Private"),
+	}}, res.Cards)
+	assert.Equal(t, []string{"Scan"}, res.Unsupported)
+}
+
+func TestParse1Password_Invalid(t *testing.T) {
+	_, err := parse1Password([]byte("not a zip"))
+	assert.ErrorIs(t, err, ErrInvalidFile)
+
+	var buf bytes.Buffer
+	assert.NoError(t, zip.NewWriter(&buf).Close())
+	_, err = parse1Password(buf.Bytes())
+	assert.EqualError(t, err, "invalid import file: в архиве нет файла export.data")
+}
@@ -0,0 +1,305 @@
+// Package localcache хранит на стороне клиента зашифрованную копию секретов пользователя,
+// чтобы команды чтения работали без связи с сервером, и очередь изменений, ожидающих отправки.
+package localcache
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	saltSize = 16
+	keySize  = 32
+	// argon2Time, argon2Memory и argon2Threads параметры Argon2id для получения ключа кэша из ключа хранилища
+	argon2Time    = 1
+	argon2Memory  = 64 * 1024
+	argon2Threads = 4
+)
+
+// magic заголовок файла кэша, по которому проверяется формат
+var magic = []byte("GVC1")
+
+var (
+	// ErrWrongKey означает, что кэш не удалось расшифровать: ключ хранилища неверен или файл поврежден
+	ErrWrongKey = errors.New("wrong cache key or corrupted cache")
+	// ErrNotSynced означает, что кэш еще ни разу не заполнялся с сервера
+	ErrNotSynced = errors.New("cache has never been synced")
+)
+
+// PendingRequest изменение, сделанное без связи с сервером и ожидающее отправки
+type PendingRequest struct {
+	Path     string          `json:"path"`               // Путь эндпоинта, например /save/note
+	Body     json.RawMessage `json:"body"`               // Тело запроса
+	Revision int64           `json:"revision,omitempty"` // Ожидаемая ревизия секрета для заголовка If-Match
+	QueuedAt time.Time       `json:"queued_at"`          // Время постановки в очередь
+}
+
+// snapshot содержимое файла кэша до шифрования
+type snapshot struct {
+	Seq         int64                `json:"seq"` // Номер последнего изменения, полученного с сервера
+	SyncedAt    time.Time            `json:"synced_at"`
+	Credentials []models.Credentials `json:"credentials,omitempty"`
+	Notes       []models.Note        `json:"notes,omitempty"`
+	Cards       []models.Card        `json:"cards,omitempty"`
+	Pending     []PendingRequest     `json:"pending,omitempty"`
+}
+
+// Cache локальная копия секретов одного пользователя
+type Cache struct {
+	path     string
+	userName string
+	salt     []byte
+	aead     cipher.AEAD
+	data     snapshot
+}
+
+// Open открывает кэш пользователя в каталоге dir, расшифровывая его ключом, полученным из ключа хранилища vaultKey.
+// Если файла кэша еще нет, возвращается пустой кэш, который будет создан при первом сохранении.
+func Open(dir, userName, vaultKey string) (*Cache, error) {
+	if vaultKey == "" {
+		return nil, fmt.Errorf("%w: empty vault key", ErrWrongKey)
+	}
+	c := &Cache{path: filepath.Join(dir, fileName(userName)), userName: userName}
+
+	raw, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		c.salt = make([]byte, saltSize)
+		if _, err = rand.Read(c.salt); err != nil {
+			return nil, fmt.Errorf("ошибка при генерации соли кэша: %w", err)
+		}
+		if c.aead, err = newAEAD(vaultKey, c.salt); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при чтении кэша: %w", err)
+	}
+
+	if len(raw) < len(magic)+saltSize || !bytes.Equal(raw[:len(magic)], magic) {
+		return nil, ErrWrongKey
+	}
+	c.salt = raw[len(magic) : len(magic)+saltSize]
+	if c.aead, err = newAEAD(vaultKey, c.salt); err != nil {
+		return nil, err
+	}
+	sealed := raw[len(magic)+saltSize:]
+	if len(sealed) < c.aead.NonceSize() {
+		return nil, ErrWrongKey
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, c.additionalData())
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	if err = json.Unmarshal(plaintext, &c.data); err != nil {
+		return nil, fmt.Errorf("ошибка при разборе кэша: %w", err)
+	}
+	return c, nil
+}
+
+// Save шифрует и атомарно записывает кэш на диск. Файл доступен только владельцу.
+func (c *Cache) Save() error {
+	plaintext, err := json.Marshal(c.data)
+	if err != nil {
+		return fmt.Errorf("ошибка при маршалинге кэша: %w", err)
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return fmt.Errorf("ошибка при генерации nonce: %w", err)
+	}
+	out := append(append(append([]byte{}, magic...), c.salt...), nonce...)
+	out = c.aead.Seal(out, nonce, plaintext, c.additionalData())
+
+	if err = os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return fmt.Errorf("ошибка при создании каталога кэша: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".cache-*")
+	if err != nil {
+		return fmt.Errorf("ошибка при записи кэша: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(out); err != nil {
+		tmp.Close()
+		return fmt.Errorf("ошибка при записи кэша: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("ошибка при записи кэша: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("ошибка при записи кэша: %w", err)
+	}
+	return nil
+}
+
+// Apply применяет к кэшу изменение секрета, полученное с сервера: сохраняет новое значение секрета
+// или удаляет секрет по отметке об удалении
+func (c *Cache) Apply(change models.Change) {
+	switch change.Type {
+	case models.SecretCredentials:
+		c.data.Credentials = apply(c.data.Credentials, change.SecretID, change.Credentials)
+	case models.SecretNote:
+		c.data.Notes = apply(c.data.Notes, change.SecretID, change.Note)
+	case models.SecretCard:
+		c.data.Cards = apply(c.data.Cards, change.SecretID, change.Card)
+	}
+}
+
+// Reset удаляет из кэша все секреты, сохраняя очередь изменений
+func (c *Cache) Reset() {
+	c.data.Credentials, c.data.Notes, c.data.Cards = nil, nil, nil
+	c.data.Seq = 0
+}
+
+// MarkSynced запоминает номер последнего полученного изменения и время синхронизации
+func (c *Cache) MarkSynced(seq int64, syncedAt time.Time) {
+	c.data.Seq = seq
+	c.data.SyncedAt = syncedAt
+}
+
+// Seq возвращает номер последнего изменения, полученного с сервера
+func (c *Cache) Seq() int64 {
+	return c.data.Seq
+}
+
+// SyncedAt возвращает время последней синхронизации; ErrNotSynced, если кэш еще не заполнялся
+func (c *Cache) SyncedAt() (time.Time, error) {
+	if c.data.SyncedAt.IsZero() {
+		return time.Time{}, ErrNotSynced
+	}
+	return c.data.SyncedAt, nil
+}
+
+// Credentials возвращает учетные данные, отобранные по логину, сайту, папке и тегам из filter
+func (c *Cache) Credentials(filter models.Credentials) []models.Credentials {
+	var res []models.Credentials
+	for _, item := range c.data.Credentials {
+		if equal(filter.Login, item.Login) && equal(filter.Site, item.Site) && inFolder(filter.Folder, item.Folder) && hasTags(filter.Tags, item.Tags) {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+// Notes возвращает заметки, отобранные по заголовку, папке и тегам из filter
+func (c *Cache) Notes(filter models.Note) []models.Note {
+	var res []models.Note
+	for _, item := range c.data.Notes {
+		if equal(filter.Title, item.Title) && inFolder(filter.Folder, item.Folder) && hasTags(filter.Tags, item.Tags) {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+// Cards возвращает карты, отобранные по банку, номеру, папке и тегам из filter
+func (c *Cache) Cards(filter models.Card) []models.Card {
+	var res []models.Card
+	for _, item := range c.data.Cards {
+		if equal(filter.BankName, item.BankName) && equal(filter.Number, item.Number) && inFolder(filter.Folder, item.Folder) && hasTags(filter.Tags, item.Tags) {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+// Queue ставит изменение в очередь на отправку при следующей синхронизации
+func (c *Cache) Queue(path string, body []byte, revision int64, queuedAt time.Time) {
+	c.data.Pending = append(c.data.Pending, PendingRequest{Path: path, Body: body, Revision: revision, QueuedAt: queuedAt})
+}
+
+// Pending возвращает изменения, ожидающие отправки, в порядке их постановки в очередь
+func (c *Cache) Pending() []PendingRequest {
+	return c.data.Pending
+}
+
+// Dequeue удаляет из очереди n первых изменений, уже отправленных на сервер
+func (c *Cache) Dequeue(n int) {
+	if n > len(c.data.Pending) {
+		n = len(c.data.Pending)
+	}
+	c.data.Pending = c.data.Pending[n:]
+}
+
+// additionalData привязывает шифротекст к формату и пользователю, чтобы кэш нельзя было подменить кэшем другого пользователя
+func (c *Cache) additionalData() []byte {
+	return append(append([]byte{}, magic...), c.userName...)
+}
+
+// newAEAD создает шифр AES-256-GCM с ключом, полученным из ключа хранилища с помощью Argon2id
+func newAEAD(vaultKey string, salt []byte) (cipher.AEAD, error) {
+	key := argon2.IDKey([]byte(vaultKey), salt, argon2Time, argon2Memory, argon2Threads, keySize)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при создании шифра кэша: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// fileName возвращает имя файла кэша, не раскрывающее имя пользователя
+func fileName(userName string) string {
+	sum := sha256.Sum256([]byte(userName))
+	return hex.EncodeToString(sum[:8]) + ".cache"
+}
+
+// apply заменяет секрет с идентификатором id значением item или удаляет его, если item равен nil
+func apply[T interface{ ItemID() int64 }](items []T, id int64, item *T) []T {
+	res := items[:0]
+	for _, existing := range items {
+		if existing.ItemID() != id {
+			res = append(res, existing)
+		}
+	}
+	if item != nil {
+		res = append(res, *item)
+	}
+	return res
+}
+
+// equal сравнивает значение поля с условием отбора; пустое условие подходит любому значению
+func equal(want, got *string) bool {
+	return want == nil || (got != nil && *got == *want)
+}
+
+// inFolder проверяет, что секрет лежит в папке folder или в одной из вложенных в нее папок
+func inFolder(folder, got *string) bool {
+	if folder == nil {
+		return true
+	}
+	if got == nil {
+		return false
+	}
+	want := strings.Trim(*folder, "/")
+	return *got == want || strings.HasPrefix(*got, want+"/")
+}
+
+// hasTags проверяет, что секрет помечен всеми тегами из tags
+func hasTags(tags, got []string) bool {
+	for _, tag := range tags {
+		found := false
+		for _, t := range got {
+			if t == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
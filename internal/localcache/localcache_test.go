@@ -0,0 +1,159 @@
+package localcache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func idPtr(id int64) *int64 {
+	return &id
+}
+
+// fill заполняет кэш секретами так, как это делает синхронизация с сервера
+func fill(c *Cache, credentials []models.Credentials, notes []models.Note, cards []models.Card, syncedAt time.Time) {
+	var seq int64
+	next := func() int64 {
+		seq++
+		return seq
+	}
+	for i := range credentials {
+		id := next()
+		credentials[i].ID = &id
+		c.Apply(models.Change{Seq: id, Type: models.SecretCredentials, SecretID: id, Action: models.ChangeCreated, Credentials: &credentials[i]})
+	}
+	for i := range notes {
+		id := next()
+		notes[i].ID = &id
+		c.Apply(models.Change{Seq: id, Type: models.SecretNote, SecretID: id, Action: models.ChangeCreated, Note: &notes[i]})
+	}
+	for i := range cards {
+		id := next()
+		cards[i].ID = &id
+		c.Apply(models.Change{Seq: id, Type: models.SecretCard, SecretID: id, Action: models.ChangeCreated, Card: &cards[i]})
+	}
+	c.MarkSynced(seq, syncedAt)
+}
+
+func TestCacheRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	syncedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	c, err := Open(dir, "alice", "vault-key")
+	require.NoError(t, err)
+	_, err = c.SyncedAt()
+	assert.ErrorIs(t, err, ErrNotSynced)
+
+	fill(c,
+		[]models.Credentials{{UserName: "alice", Login: strPtr("alice@mail"), Password: strPtr("qwerty12")}},
+		[]models.Note{{UserName: "alice", Title: strPtr("shopping"), Content: strPtr("some lovely notes")}},
+		nil,
+		syncedAt,
+	)
+	c.Queue("/save/note", []byte(`{"user_name":"alice","title":"todo"}`), 0, syncedAt)
+	require.NoError(t, c.Save())
+
+	raw, err := os.ReadFile(filepath.Join(dir, fileName("alice")))
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "qwerty12")
+	assert.NotContains(t, string(raw), "alice")
+
+	reopened, err := Open(dir, "alice", "vault-key")
+	require.NoError(t, err)
+	got, err := reopened.SyncedAt()
+	require.NoError(t, err)
+	assert.True(t, got.Equal(syncedAt))
+	assert.Equal(t, int64(2), reopened.Seq())
+	assert.Equal(t, c.Credentials(models.Credentials{}), reopened.Credentials(models.Credentials{}))
+	require.Len(t, reopened.Pending(), 1)
+	assert.Equal(t, "/save/note", reopened.Pending()[0].Path)
+}
+
+func TestOpenWrongKey(t *testing.T) {
+	dir := t.TempDir()
+	c, err := Open(dir, "alice", "vault-key")
+	require.NoError(t, err)
+	require.NoError(t, c.Save())
+
+	_, err = Open(dir, "alice", "other-key")
+	assert.ErrorIs(t, err, ErrWrongKey)
+
+	_, err = Open(dir, "alice", "")
+	assert.ErrorIs(t, err, ErrWrongKey)
+
+	// Подмененный файл другого пользователя не расшифровывается
+	require.NoError(t, os.Rename(filepath.Join(dir, fileName("alice")), filepath.Join(dir, fileName("bob"))))
+	_, err = Open(dir, "bob", "vault-key")
+	assert.ErrorIs(t, err, ErrWrongKey)
+}
+
+func TestCacheFilters(t *testing.T) {
+	c, err := Open(t.TempDir(), "alice", "vault-key")
+	require.NoError(t, err)
+	fill(c,
+		[]models.Credentials{
+			{Login: strPtr("alice"), Site: strPtr("mail"), Folder: strPtr("work/mail"), Tags: []string{"prod", "shared"}},
+			{Login: strPtr("bob"), Folder: strPtr("workshop")},
+		},
+		[]models.Note{{Title: strPtr("shopping")}, {Title: strPtr("ideas"), Tags: []string{"prod"}}},
+		[]models.Card{{BankName: strPtr("sber"), Number: strPtr("1111")}, {BankName: strPtr("tinkoff"), Number: strPtr("2222")}},
+		time.Now(),
+	)
+
+	assert.Len(t, c.Credentials(models.Credentials{}), 2)
+	assert.Len(t, c.Credentials(models.Credentials{Folder: strPtr("/work/")}), 1)
+	assert.Len(t, c.Credentials(models.Credentials{Site: strPtr("mail")}), 1)
+	assert.Len(t, c.Credentials(models.Credentials{Tags: []string{"prod", "shared"}}), 1)
+	assert.Empty(t, c.Credentials(models.Credentials{Tags: []string{"prod", "dev"}}))
+	assert.Len(t, c.Notes(models.Note{Title: strPtr("ideas")}), 1)
+	assert.Len(t, c.Notes(models.Note{Tags: []string{"prod"}}), 1)
+	assert.Len(t, c.Cards(models.Card{Number: strPtr("2222")}), 1)
+	assert.Empty(t, c.Cards(models.Card{BankName: strPtr("sber"), Number: strPtr("2222")}))
+}
+
+func TestDequeue(t *testing.T) {
+	c, err := Open(t.TempDir(), "alice", "vault-key")
+	require.NoError(t, err)
+	now := time.Now()
+	c.Queue("/save/note", []byte(`{}`), 0, now)
+	c.Queue("/update/note", []byte(`{}`), 3, now)
+	c.Queue("/delete/note", []byte(`{}`), 0, now)
+
+	c.Dequeue(2)
+	require.Len(t, c.Pending(), 1)
+	assert.Equal(t, "/delete/note", c.Pending()[0].Path)
+
+	c.Dequeue(5)
+	assert.Empty(t, c.Pending())
+}
+
+func TestCacheApply(t *testing.T) {
+	c, err := Open(t.TempDir(), "alice", "vault-key")
+	require.NoError(t, err)
+	fill(c, nil, []models.Note{{Title: strPtr("shopping")}, {Title: strPtr("ideas")}}, nil, time.Now())
+
+	updated := models.Note{Title: strPtr("shopping"), Content: strPtr("milk"), Audit: models.Audit{ID: idPtr(1), Revision: 2}}
+	c.Apply(models.Change{Seq: 3, Type: models.SecretNote, SecretID: 1, Action: models.ChangeUpdated, Revision: 2, Note: &updated})
+	notes := c.Notes(models.Note{Title: strPtr("shopping")})
+	require.Len(t, notes, 1)
+	assert.Equal(t, "milk", *notes[0].Content)
+	assert.Len(t, c.Notes(models.Note{}), 2)
+
+	c.Apply(models.Change{Seq: 4, Type: models.SecretNote, SecretID: 2, Action: models.ChangeDeleted})
+	assert.Empty(t, c.Notes(models.Note{Title: strPtr("ideas")}))
+
+	c.Queue("/save/note", []byte(`{}`), 0, time.Now())
+	c.Reset()
+	assert.Empty(t, c.Notes(models.Note{}))
+	assert.Equal(t, int64(0), c.Seq())
+	assert.Len(t, c.Pending(), 1)
+}
@@ -67,6 +67,24 @@ func (_m *Storage) DeleteCredentials(ctx context.Context, credentialsRequest mod
 	return r0
 }
 
+// DeleteFolder provides a mock function with given fields: ctx, folderRequest
+func (_m *Storage) DeleteFolder(ctx context.Context, folderRequest models.Folder) error {
+	ret := _m.Called(ctx, folderRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteFolder")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.Folder) error); ok {
+		r0 = rf(ctx, folderRequest)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteNotes provides a mock function with given fields: ctx, noteRequest
 func (_m *Storage) DeleteNotes(ctx context.Context, noteRequest models.Note) error {
 	ret := _m.Called(ctx, noteRequest)
@@ -85,29 +103,136 @@ func (_m *Storage) DeleteNotes(ctx context.Context, noteRequest models.Note) err
 	return r0
 }
 
-// GetCard provides a mock function with given fields: ctx, cardRequest
-func (_m *Storage) GetCard(ctx context.Context, cardRequest models.Card) ([]models.Card, error) {
-	ret := _m.Called(ctx, cardRequest)
+// DeleteSSHKeys provides a mock function with given fields: ctx, keyRequest
+func (_m *Storage) DeleteSSHKeys(ctx context.Context, keyRequest models.SSHKey) error {
+	ret := _m.Called(ctx, keyRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSSHKeys")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.SSHKey) error); ok {
+		r0 = rf(ctx, keyRequest)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteTOTP provides a mock function with given fields: ctx, totpRequest
+func (_m *Storage) DeleteTOTP(ctx context.Context, totpRequest models.TOTP) error {
+	ret := _m.Called(ctx, totpRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteTOTP")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.TOTP) error); ok {
+		r0 = rf(ctx, totpRequest)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteTag provides a mock function with given fields: ctx, tagRequest
+func (_m *Storage) DeleteTag(ctx context.Context, tagRequest models.Tag) error {
+	ret := _m.Called(ctx, tagRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteTag")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.Tag) error); ok {
+		r0 = rf(ctx, tagRequest)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EmptyTrash provides a mock function with given fields: ctx, trashRequest
+func (_m *Storage) EmptyTrash(ctx context.Context, trashRequest models.TrashRequest) error {
+	ret := _m.Called(ctx, trashRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EmptyTrash")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.TrashRequest) error); ok {
+		r0 = rf(ctx, trashRequest)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetCard provides a mock function with given fields: ctx, cardRequest, opts
+func (_m *Storage) GetCard(ctx context.Context, cardRequest models.Card, opts models.ListOptions) ([]models.Card, string, error) {
+	ret := _m.Called(ctx, cardRequest, opts)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetCard")
 	}
 
 	var r0 []models.Card
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, models.Card) ([]models.Card, error)); ok {
-		return rf(ctx, cardRequest)
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.Card, models.ListOptions) ([]models.Card, string, error)); ok {
+		return rf(ctx, cardRequest, opts)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, models.Card) []models.Card); ok {
-		r0 = rf(ctx, cardRequest)
+	if rf, ok := ret.Get(0).(func(context.Context, models.Card, models.ListOptions) []models.Card); ok {
+		r0 = rf(ctx, cardRequest, opts)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]models.Card)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, models.Card) error); ok {
-		r1 = rf(ctx, cardRequest)
+	if rf, ok := ret.Get(1).(func(context.Context, models.Card, models.ListOptions) string); ok {
+		r1 = rf(ctx, cardRequest, opts)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, models.Card, models.ListOptions) error); ok {
+		r2 = rf(ctx, cardRequest, opts)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetChanges provides a mock function with given fields: ctx, syncRequest
+func (_m *Storage) GetChanges(ctx context.Context, syncRequest models.SyncRequest) (models.SyncResponse, error) {
+	ret := _m.Called(ctx, syncRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChanges")
+	}
+
+	var r0 models.SyncResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.SyncRequest) (models.SyncResponse, error)); ok {
+		return rf(ctx, syncRequest)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.SyncRequest) models.SyncResponse); ok {
+		r0 = rf(ctx, syncRequest)
+	} else {
+		r0 = ret.Get(0).(models.SyncResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.SyncRequest) error); ok {
+		r1 = rf(ctx, syncRequest)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -115,29 +240,64 @@ func (_m *Storage) GetCard(ctx context.Context, cardRequest models.Card) ([]mode
 	return r0, r1
 }
 
-// GetCredentials provides a mock function with given fields: ctx, credentialsRequest
-func (_m *Storage) GetCredentials(ctx context.Context, credentialsRequest models.Credentials) ([]models.Credentials, error) {
-	ret := _m.Called(ctx, credentialsRequest)
+// GetCredentials provides a mock function with given fields: ctx, credentialsRequest, opts
+func (_m *Storage) GetCredentials(ctx context.Context, credentialsRequest models.Credentials, opts models.ListOptions) ([]models.Credentials, string, error) {
+	ret := _m.Called(ctx, credentialsRequest, opts)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetCredentials")
 	}
 
 	var r0 []models.Credentials
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, models.Credentials) ([]models.Credentials, error)); ok {
-		return rf(ctx, credentialsRequest)
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.Credentials, models.ListOptions) ([]models.Credentials, string, error)); ok {
+		return rf(ctx, credentialsRequest, opts)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, models.Credentials) []models.Credentials); ok {
-		r0 = rf(ctx, credentialsRequest)
+	if rf, ok := ret.Get(0).(func(context.Context, models.Credentials, models.ListOptions) []models.Credentials); ok {
+		r0 = rf(ctx, credentialsRequest, opts)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]models.Credentials)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, models.Credentials) error); ok {
-		r1 = rf(ctx, credentialsRequest)
+	if rf, ok := ret.Get(1).(func(context.Context, models.Credentials, models.ListOptions) string); ok {
+		r1 = rf(ctx, credentialsRequest, opts)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, models.Credentials, models.ListOptions) error); ok {
+		r2 = rf(ctx, credentialsRequest, opts)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetEvents provides a mock function with given fields: ctx, eventsRequest
+func (_m *Storage) GetEvents(ctx context.Context, eventsRequest models.EventsRequest) (models.EventsResponse, error) {
+	ret := _m.Called(ctx, eventsRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEvents")
+	}
+
+	var r0 models.EventsResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.EventsRequest) (models.EventsResponse, error)); ok {
+		return rf(ctx, eventsRequest)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.EventsRequest) models.EventsResponse); ok {
+		r0 = rf(ctx, eventsRequest)
+	} else {
+		r0 = ret.Get(0).(models.EventsResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.EventsRequest) error); ok {
+		r1 = rf(ctx, eventsRequest)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -145,29 +305,302 @@ func (_m *Storage) GetCredentials(ctx context.Context, credentialsRequest models
 	return r0, r1
 }
 
-// GetNotes provides a mock function with given fields: ctx, noteRequest
-func (_m *Storage) GetNotes(ctx context.Context, noteRequest models.Note) ([]models.Note, error) {
-	ret := _m.Called(ctx, noteRequest)
+// GetFolders provides a mock function with given fields: ctx, folderRequest
+func (_m *Storage) GetFolders(ctx context.Context, folderRequest models.Folder) ([]models.Folder, error) {
+	ret := _m.Called(ctx, folderRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFolders")
+	}
+
+	var r0 []models.Folder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.Folder) ([]models.Folder, error)); ok {
+		return rf(ctx, folderRequest)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.Folder) []models.Folder); ok {
+		r0 = rf(ctx, folderRequest)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Folder)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.Folder) error); ok {
+		r1 = rf(ctx, folderRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetHistory provides a mock function with given fields: ctx, historyRequest
+func (_m *Storage) GetHistory(ctx context.Context, historyRequest models.HistoryRequest) ([]models.SecretVersion, error) {
+	ret := _m.Called(ctx, historyRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHistory")
+	}
+
+	var r0 []models.SecretVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.HistoryRequest) ([]models.SecretVersion, error)); ok {
+		return rf(ctx, historyRequest)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.HistoryRequest) []models.SecretVersion); ok {
+		r0 = rf(ctx, historyRequest)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.SecretVersion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.HistoryRequest) error); ok {
+		r1 = rf(ctx, historyRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetNotes provides a mock function with given fields: ctx, noteRequest, opts
+func (_m *Storage) GetNotes(ctx context.Context, noteRequest models.Note, opts models.ListOptions) ([]models.Note, string, error) {
+	ret := _m.Called(ctx, noteRequest, opts)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetNotes")
 	}
 
 	var r0 []models.Note
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, models.Note) ([]models.Note, error)); ok {
-		return rf(ctx, noteRequest)
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.Note, models.ListOptions) ([]models.Note, string, error)); ok {
+		return rf(ctx, noteRequest, opts)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, models.Note) []models.Note); ok {
-		r0 = rf(ctx, noteRequest)
+	if rf, ok := ret.Get(0).(func(context.Context, models.Note, models.ListOptions) []models.Note); ok {
+		r0 = rf(ctx, noteRequest, opts)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]models.Note)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, models.Note) error); ok {
-		r1 = rf(ctx, noteRequest)
+	if rf, ok := ret.Get(1).(func(context.Context, models.Note, models.ListOptions) string); ok {
+		r1 = rf(ctx, noteRequest, opts)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, models.Note, models.ListOptions) error); ok {
+		r2 = rf(ctx, noteRequest, opts)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetSSHKeys provides a mock function with given fields: ctx, keyRequest
+func (_m *Storage) GetSSHKeys(ctx context.Context, keyRequest models.SSHKey) ([]models.SSHKey, error) {
+	ret := _m.Called(ctx, keyRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSSHKeys")
+	}
+
+	var r0 []models.SSHKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.SSHKey) ([]models.SSHKey, error)); ok {
+		return rf(ctx, keyRequest)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.SSHKey) []models.SSHKey); ok {
+		r0 = rf(ctx, keyRequest)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.SSHKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.SSHKey) error); ok {
+		r1 = rf(ctx, keyRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSearchItems provides a mock function with given fields: ctx, searchRequest
+func (_m *Storage) GetSearchItems(ctx context.Context, searchRequest models.SearchRequest) ([]models.SearchResult, error) {
+	ret := _m.Called(ctx, searchRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSearchItems")
+	}
+
+	var r0 []models.SearchResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.SearchRequest) ([]models.SearchResult, error)); ok {
+		return rf(ctx, searchRequest)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.SearchRequest) []models.SearchResult); ok {
+		r0 = rf(ctx, searchRequest)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.SearchResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.SearchRequest) error); ok {
+		r1 = rf(ctx, searchRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTOTP provides a mock function with given fields: ctx, totpRequest
+func (_m *Storage) GetTOTP(ctx context.Context, totpRequest models.TOTP) ([]models.TOTP, error) {
+	ret := _m.Called(ctx, totpRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTOTP")
+	}
+
+	var r0 []models.TOTP
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.TOTP) ([]models.TOTP, error)); ok {
+		return rf(ctx, totpRequest)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.TOTP) []models.TOTP); ok {
+		r0 = rf(ctx, totpRequest)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.TOTP)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.TOTP) error); ok {
+		r1 = rf(ctx, totpRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTags provides a mock function with given fields: ctx, tagRequest
+func (_m *Storage) GetTags(ctx context.Context, tagRequest models.Tag) ([]models.Tag, error) {
+	ret := _m.Called(ctx, tagRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTags")
+	}
+
+	var r0 []models.Tag
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.Tag) ([]models.Tag, error)); ok {
+		return rf(ctx, tagRequest)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.Tag) []models.Tag); ok {
+		r0 = rf(ctx, tagRequest)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Tag)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.Tag) error); ok {
+		r1 = rf(ctx, tagRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTrash provides a mock function with given fields: ctx, trashRequest
+func (_m *Storage) GetTrash(ctx context.Context, trashRequest models.TrashRequest) ([]models.TrashItem, error) {
+	ret := _m.Called(ctx, trashRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTrash")
+	}
+
+	var r0 []models.TrashItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.TrashRequest) ([]models.TrashItem, error)); ok {
+		return rf(ctx, trashRequest)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.TrashRequest) []models.TrashItem); ok {
+		r0 = rf(ctx, trashRequest)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.TrashItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.TrashRequest) error); ok {
+		r1 = rf(ctx, trashRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ImportSecrets provides a mock function with given fields: ctx, batch
+func (_m *Storage) ImportSecrets(ctx context.Context, batch models.ImportBatch) (models.ImportResult, error) {
+	ret := _m.Called(ctx, batch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportSecrets")
+	}
+
+	var r0 models.ImportResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.ImportBatch) (models.ImportResult, error)); ok {
+		return rf(ctx, batch)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.ImportBatch) models.ImportResult); ok {
+		r0 = rf(ctx, batch)
+	} else {
+		r0 = ret.Get(0).(models.ImportResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.ImportBatch) error); ok {
+		r1 = rf(ctx, batch)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ImportVault provides a mock function with given fields: ctx, vaultImport
+func (_m *Storage) ImportVault(ctx context.Context, vaultImport models.VaultImport) (models.ImportResult, error) {
+	ret := _m.Called(ctx, vaultImport)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportVault")
+	}
+
+	var r0 models.ImportResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.VaultImport) (models.ImportResult, error)); ok {
+		return rf(ctx, vaultImport)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.VaultImport) models.ImportResult); ok {
+		r0 = rf(ctx, vaultImport)
+	} else {
+		r0 = ret.Get(0).(models.ImportResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.VaultImport) error); ok {
+		r1 = rf(ctx, vaultImport)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -193,6 +626,24 @@ func (_m *Storage) Login(ctx context.Context, login string, password string) err
 	return r0
 }
 
+// OrganizeSecret provides a mock function with given fields: ctx, request
+func (_m *Storage) OrganizeSecret(ctx context.Context, request models.OrganizeRequest) error {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OrganizeSecret")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.OrganizeRequest) error); ok {
+		r0 = rf(ctx, request)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Register provides a mock function with given fields: ctx, login, password
 func (_m *Storage) Register(ctx context.Context, login string, password string) error {
 	ret := _m.Called(ctx, login, password)
@@ -211,6 +662,60 @@ func (_m *Storage) Register(ctx context.Context, login string, password string)
 	return r0
 }
 
+// RestoreFromTrash provides a mock function with given fields: ctx, trashRequest
+func (_m *Storage) RestoreFromTrash(ctx context.Context, trashRequest models.TrashRequest) error {
+	ret := _m.Called(ctx, trashRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreFromTrash")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.TrashRequest) error); ok {
+		r0 = rf(ctx, trashRequest)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestoreVersion provides a mock function with given fields: ctx, historyRequest
+func (_m *Storage) RestoreVersion(ctx context.Context, historyRequest models.HistoryRequest) error {
+	ret := _m.Called(ctx, historyRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreVersion")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.HistoryRequest) error); ok {
+		r0 = rf(ctx, historyRequest)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveAuditEvent provides a mock function with given fields: ctx, event
+func (_m *Storage) SaveAuditEvent(ctx context.Context, event models.AuditEvent) error {
+	ret := _m.Called(ctx, event)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveAuditEvent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.AuditEvent) error); ok {
+		r0 = rf(ctx, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SaveCard provides a mock function with given fields: ctx, card
 func (_m *Storage) SaveCard(ctx context.Context, card models.Card) error {
 	ret := _m.Called(ctx, card)
@@ -247,6 +752,24 @@ func (_m *Storage) SaveCredentials(ctx context.Context, credentialsRequest model
 	return r0
 }
 
+// SaveFolder provides a mock function with given fields: ctx, folder
+func (_m *Storage) SaveFolder(ctx context.Context, folder models.Folder) error {
+	ret := _m.Called(ctx, folder)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveFolder")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.Folder) error); ok {
+		r0 = rf(ctx, folder)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SaveNote provides a mock function with given fields: ctx, note
 func (_m *Storage) SaveNote(ctx context.Context, note models.Note) error {
 	ret := _m.Called(ctx, note)
@@ -265,6 +788,90 @@ func (_m *Storage) SaveNote(ctx context.Context, note models.Note) error {
 	return r0
 }
 
+// SaveSSHKey provides a mock function with given fields: ctx, key
+func (_m *Storage) SaveSSHKey(ctx context.Context, key models.SSHKey) error {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveSSHKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.SSHKey) error); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveTOTP provides a mock function with given fields: ctx, totp
+func (_m *Storage) SaveTOTP(ctx context.Context, totp models.TOTP) error {
+	ret := _m.Called(ctx, totp)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveTOTP")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.TOTP) error); ok {
+		r0 = rf(ctx, totp)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SearchNotes provides a mock function with given fields: ctx, searchRequest
+func (_m *Storage) SearchNotes(ctx context.Context, searchRequest models.SearchRequest) ([]models.Note, error) {
+	ret := _m.Called(ctx, searchRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchNotes")
+	}
+
+	var r0 []models.Note
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.SearchRequest) ([]models.Note, error)); ok {
+		return rf(ctx, searchRequest)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.SearchRequest) []models.Note); ok {
+		r0 = rf(ctx, searchRequest)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Note)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.SearchRequest) error); ok {
+		r1 = rf(ctx, searchRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateCard provides a mock function with given fields: ctx, card
+func (_m *Storage) UpdateCard(ctx context.Context, card models.Card) error {
+	ret := _m.Called(ctx, card)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateCard")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.Card) error); ok {
+		r0 = rf(ctx, card)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateCredentials provides a mock function with given fields: ctx, credentials
 func (_m *Storage) UpdateCredentials(ctx context.Context, credentials models.Credentials) error {
 	ret := _m.Called(ctx, credentials)
@@ -1,6 +1,14 @@
 package models
 
-import "github.com/golang-jwt/jwt/v4"
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
 
 type UserBase struct {
 	UserName string `json:"user_name"` // Имя пользователя
@@ -17,27 +25,480 @@ type Claims struct {
 }
 
 type Note struct {
-	UserName string  `json:"user_name"`
-	Title    *string `json:"title,omitempty"`
-	Content  *string `json:"content,omitempty"`
-	Metadata *string `json:"metadata,omitempty"`
+	UserName string        `json:"user_name"`
+	Title    *string       `json:"title,omitempty"`
+	Content  *string       `json:"content,omitempty"`
+	Metadata *string       `json:"metadata,omitempty"`
+	Fields   []CustomField `json:"fields,omitempty"` // Пользовательские поля
+	Folder   *string       `json:"folder,omitempty"` // Путь папки, например work/projects
+	Tags     []string      `json:"tags,omitempty"`   // Теги
+	Audit
+}
+
+// Audit содержит служебные сведения о секрете. Заполняется хранилищем и игнорируется в запросах на сохранение.
+type Audit struct {
+	ID             *int64     `json:"id,omitempty"`               // Идентификатор секрета
+	CreatedAt      *time.Time `json:"created_at,omitempty"`       // Время создания
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`       // Время последнего изменения
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"` // Время последнего чтения секретных полей
+	Revision       int64      `json:"revision,omitempty"`         // Номер ревизии, увеличивается при каждом изменении
+}
+
+// CurrentRevision возвращает номер ревизии секрета
+func (a Audit) CurrentRevision() int64 {
+	return a.Revision
+}
+
+// ItemID возвращает идентификатор секрета; 0, если он неизвестен
+func (a Audit) ItemID() int64 {
+	if a.ID == nil {
+		return 0
+	}
+	return *a.ID
+}
+
+// ETag возвращает значение заголовков ETag и If-Match для ревизии секрета
+func ETag(revision int64) string {
+	return strconv.Quote(strconv.FormatInt(revision, 10))
+}
+
+// RevisionConflict описывает ответ на изменение секрета по устаревшей ревизии
+type RevisionConflict struct {
+	Message  string          `json:"message"`
+	Revision int64           `json:"revision"`          // Текущая ревизия секрета; 0, если секрет не найден
+	Current  json.RawMessage `json:"current,omitempty"` // Текущая версия секрета
 }
 
 type Credentials struct {
-	UserName string  `json:"user_name"`
-	Login    *string `json:"login,omitempty"`    // Логин пользователя
-	Password *string `json:"password,omitempty"` // Пароль пользователя
-	Metadata *string `json:"metadata,omitempty"` // Дополнительная метаинформация
+	UserName string          `json:"user_name"`
+	Login    *string         `json:"login,omitempty"`    // Логин пользователя
+	Password *string         `json:"password,omitempty"` // Пароль пользователя
+	Metadata *string         `json:"metadata,omitempty"` // Дополнительная метаинформация
+	Site     *string         `json:"site,omitempty"`     // Сайт или сервис, к которому относятся учетные данные
+	Name     *string         `json:"name,omitempty"`     // Отображаемое название учетных данных
+	URLs     []CredentialURL `json:"urls,omitempty"`     // Адреса сайтов, на которых используются учетные данные
+	Fields   []CustomField   `json:"fields,omitempty"`   // Пользовательские поля
+	Folder   *string         `json:"folder,omitempty"`   // Путь папки, например work/projects
+	Tags     []string        `json:"tags,omitempty"`     // Теги
+	Audit
+}
+
+// MatchRule определяет способ сопоставления адреса сайта с сохраненным URL
+type MatchRule string
+
+const (
+	MatchBaseDomain MatchRule = "base_domain" // совпадение по базовому домену (используется по умолчанию)
+	MatchHost       MatchRule = "host"        // точное совпадение хоста
+	MatchRegex      MatchRule = "regex"       // совпадение адреса с регулярным выражением
+)
+
+// CredentialURL описывает адрес сайта и правило сопоставления с ним
+type CredentialURL struct {
+	URL   string    `json:"url"`
+	Match MatchRule `json:"match,omitempty"`
 }
 
 type Card struct {
+	UserName string        `json:"user_name"`
+	BankName *string       `json:"bank_name,omitempty"` // Наименование банка
+	Number   *string       `json:"number,omitempty"`    // Номер карты
+	CV       *string       `json:"cv,omitempty"`        // Код CV (Security code)
+	Password *string       `json:"password,omitempty"`  // Пароль карты
+	CardType *string       `json:"card_type,omitempty"` // тип карты
+	Metadata *string       `json:"metadata,omitempty"`  // Дополнительная метаинформация
+	Fields   []CustomField `json:"fields,omitempty"`    // Пользовательские поля
+	Folder   *string       `json:"folder,omitempty"`    // Путь папки, например work/projects
+	Tags     []string      `json:"tags,omitempty"`      // Теги
+	Audit
+}
+
+// Folder описывает папку для группировки секретов. Вложенность задается путем через "/".
+type Folder struct {
+	UserName string  `json:"user_name"`
+	Path     *string `json:"path,omitempty"` // Путь папки, например work/projects
+}
+
+// Tag описывает тег, которым помечаются секреты
+type Tag struct {
 	UserName string  `json:"user_name"`
-	BankName *string `json:"bank_name,omitempty"` // Наименование банка
-	Number   *string `json:"number,omitempty"`    // Номер карты
-	CV       *string `json:"cv,omitempty"`        // Код CV (Security code)
-	Password *string `json:"password,omitempty"`  // Пароль карты
-	CardType *string `json:"card_type,omitempty"` // тип карты
-	Metadata *string `json:"metadata,omitempty"`  // Дополнительная метаинформация
+	Name     *string `json:"name,omitempty"` // Название тега
+}
+
+// OrganizeRequest описывает перемещение сохраненного секрета в другую папку и изменение его тегов
+type OrganizeRequest struct {
+	UserName   string     `json:"user_name"`
+	Type       SecretType `json:"type"`
+	SecretID   int64      `json:"id"`
+	Folder     *string    `json:"folder,omitempty"`      // Новый путь папки; пустая строка убирает секрет из папки
+	AddTags    []string   `json:"add_tags,omitempty"`    // Добавляемые теги; отсутствующие теги создаются
+	RemoveTags []string   `json:"remove_tags,omitempty"` // Снимаемые теги
+}
+
+// Поля сортировки списков секретов
+const (
+	SortName    = "name"    // по названию: заголовку заметки, имени или логину учетных данных, банку карты
+	SortCreated = "created" // по времени создания
+	SortUpdated = "updated" // по времени последнего изменения
+)
+
+// NextCursorHeader заголовок ответа с курсором следующей страницы списка секретов
+const NextCursorHeader = "X-Next-Cursor"
+
+// ListOptions описывает постраничное получение списка секретов.
+// Передается в теле запроса на получение секретов вместе с полями отбора.
+type ListOptions struct {
+	Limit      int      `json:"limit,omitempty"`       // Размер страницы; 0 означает все записи
+	Cursor     string   `json:"cursor,omitempty"`      // Курсор следующей страницы из ответа на предыдущий запрос
+	Sort       string   `json:"sort,omitempty"`        // Поле сортировки: name, created или updated; по умолчанию порядок добавления
+	Desc       bool     `json:"desc,omitempty"`        // Сортировка по убыванию
+	UnusedDays int      `json:"unused_days,omitempty"` // Только секреты, которые не читались указанное число дней
+	Projection []string `json:"projection,omitempty"`  // Возвращаемые поля помимо ключевых; секретные поля вне списка не расшифровываются
+}
+
+// SecretType определяет тип секрета
+type SecretType string
+
+const (
+	SecretCredentials SecretType = "credentials" // учетные данные
+	SecretNote        SecretType = "note"        // заметка
+	SecretCard        SecretType = "card"        // банковская карта
+)
+
+// SearchRequest описывает запрос на поиск секретов пользователя
+type SearchRequest struct {
+	UserName string       `json:"user_name"`
+	Query    string       `json:"query"`           // Строка поиска
+	Types    []SecretType `json:"types,omitempty"` // Типы секретов, среди которых выполняется поиск; по умолчанию все
+}
+
+// SearchResult описывает найденный секрет. Пароли, CV и содержимое заметок в результаты не попадают.
+type SearchResult struct {
+	Type     SecretType `json:"type"`
+	Title    string     `json:"title"`              // Название: имя или логин учетных данных, заголовок заметки, банк карты
+	Login    *string    `json:"login,omitempty"`    // Логин учетных данных
+	Site     *string    `json:"site,omitempty"`     // Сайт учетных данных
+	URLs     []string   `json:"urls,omitempty"`     // Адреса сайтов учетных данных
+	Number   *string    `json:"number,omitempty"`   // Маскированный номер карты
+	Folder   *string    `json:"folder,omitempty"`   // Путь папки
+	Tags     []string   `json:"tags,omitempty"`     // Теги
+	Metadata *string    `json:"metadata,omitempty"` // Дополнительная метаинформация
+	Score    int        `json:"score"`              // Оценка совпадения с запросом
+}
+
+// HistoryRequest описывает запрос истории изменений секрета или восстановления его версии
+type HistoryRequest struct {
+	UserName string     `json:"user_name"`
+	Type     SecretType `json:"type,omitempty"`    // Тип секрета: credentials или note
+	SecretID int64      `json:"id,omitempty"`      // Идентификатор секрета
+	Version  int64      `json:"version,omitempty"` // Номер ревизии; для истории необязателен
+}
+
+// SecretVersion описывает сохраненную предыдущую версию секрета
+type SecretVersion struct {
+	Type        SecretType   `json:"type"`
+	SecretID    int64        `json:"id"`
+	Version     int64        `json:"version"`               // Номер ревизии секрета до изменения
+	ChangedBy   string       `json:"changed_by"`            // Пользователь, изменивший секрет
+	ChangedAt   time.Time    `json:"changed_at"`            // Время изменения
+	Changes     []string     `json:"changes,omitempty"`     // Поля, измененные следующей ревизией
+	Credentials *Credentials `json:"credentials,omitempty"` // Версия учетных данных
+	Note        *Note        `json:"note,omitempty"`        // Версия заметки
+}
+
+// TrashRequest описывает запрос к корзине пользователя
+type TrashRequest struct {
+	UserName string     `json:"user_name"`
+	Type     SecretType `json:"type,omitempty"` // Тип секрета; по умолчанию все типы
+	SecretID int64      `json:"id,omitempty"`   // Идентификатор секрета; по умолчанию все секреты в корзине
+}
+
+// TrashItem описывает удаленный секрет, находящийся в корзине
+type TrashItem struct {
+	Type      SecretType `json:"type"`
+	SecretID  int64      `json:"id"`
+	Title     string     `json:"title"`      // Название: имя или логин учетных данных, заголовок заметки, банк карты
+	DeletedAt time.Time  `json:"deleted_at"` // Время удаления
+}
+
+// SyncRequest описывает запрос изменений секретов пользователя для синхронизации
+type SyncRequest struct {
+	UserName string `json:"user_name"`
+	Since    int64  `json:"-"` // Номер последнего полученного клиентом изменения из параметра since; 0 - получить все секреты
+}
+
+// ChangeAction определяет вид изменения секрета
+type ChangeAction string
+
+const (
+	ChangeCreated ChangeAction = "created" // секрет создан или восстановлен из корзины
+	ChangeUpdated ChangeAction = "updated" // секрет изменен
+	ChangeDeleted ChangeAction = "deleted" // секрет удален; значение секрета не передается
+)
+
+// Change описывает последнее изменение секрета. Для удаленных секретов передается только отметка об удалении.
+type Change struct {
+	Seq         int64        `json:"seq"` // Номер изменения в журнале пользователя
+	Type        SecretType   `json:"type"`
+	SecretID    int64        `json:"id"`
+	Action      ChangeAction `json:"action"`
+	Revision    int64        `json:"revision"`
+	ChangedAt   time.Time    `json:"changed_at"`
+	Credentials *Credentials `json:"credentials,omitempty"`
+	Note        *Note        `json:"note,omitempty"`
+	Card        *Card        `json:"card,omitempty"`
+}
+
+// SyncResponse содержит изменения секретов пользователя в порядке их номеров
+type SyncResponse struct {
+	Seq     int64    `json:"seq"`             // Номер последнего изменения; передается в since при следующей синхронизации
+	Reset   bool     `json:"reset,omitempty"` // Клиент должен удалить локальные данные: since больше номера последнего изменения
+	Changes []Change `json:"changes"`
+}
+
+// ChangeReset сообщает в потоке событий, что журнал сервера начат заново и клиенту нужна полная синхронизация
+const ChangeReset ChangeAction = "reset"
+
+// EventsRequest описывает запрос событий об изменениях секретов пользователя
+type EventsRequest struct {
+	UserName string `json:"user_name"`
+	After    *int64 `json:"-"` // Номер последнего полученного клиентом события; nil - получить только номер журнала
+}
+
+// Event описывает событие об изменении секрета. Значение секрета в событии никогда не передается.
+type Event struct {
+	Seq       int64        `json:"seq"` // Номер изменения в журнале пользователя; используется как идентификатор события
+	Type      SecretType   `json:"type,omitempty"`
+	SecretID  int64        `json:"id,omitempty"`
+	Action    ChangeAction `json:"action"`
+	Revision  int64        `json:"revision,omitempty"`
+	ChangedAt time.Time    `json:"changed_at"`
+}
+
+// EventsResponse содержит события пользователя в порядке их номеров
+type EventsResponse struct {
+	Seq    int64   // Номер последнего изменения в журнале
+	Reset  bool    // Номер события клиента больше номера последнего изменения
+	Events []Event // События с номерами больше запрошенного
+}
+
+// FieldType определяет тип пользовательского поля
+type FieldType string
+
+const (
+	FieldText    FieldType = "text"    // произвольный текст (используется по умолчанию)
+	FieldHidden  FieldType = "hidden"  // скрытое значение, хранится в зашифрованном виде
+	FieldURL     FieldType = "url"     // адрес сайта
+	FieldEmail   FieldType = "email"   // адрес электронной почты
+	FieldDate    FieldType = "date"    // дата в формате ГГГГ-ММ-ДД
+	FieldBoolean FieldType = "boolean" // логическое значение true/false
+)
+
+// CustomField описывает пользовательское поле секрета. Порядок полей сохраняется.
+type CustomField struct {
+	Name  string    `json:"name"`
+	Type  FieldType `json:"type,omitempty"`
+	Value string    `json:"value"`
+}
+
+// TOTP описывает секрет для генерации одноразовых кодов двухфакторной аутентификации
+type TOTP struct {
+	UserName string  `json:"user_name"`
+	Name     *string `json:"name,omitempty"`     // Название секрета
+	URI      *string `json:"uri,omitempty"`      // Адрес otpauth:// с параметрами секрета
+	Login    *string `json:"login,omitempty"`    // Логин учетных данных, к которым привязан секрет
+	Site     *string `json:"site,omitempty"`     // Сайт учетных данных, к которым привязан секрет
+	Metadata *string `json:"metadata,omitempty"` // Дополнительная метаинформация
+	Audit
+}
+
+// TOTPCode текущий одноразовый код
+type TOTPCode struct {
+	Code      string `json:"code"`      // Одноразовый код
+	Remaining int    `json:"remaining"` // Количество секунд до смены кода
+}
+
+// SSHKey описывает закрытый SSH-ключ пользователя
+type SSHKey struct {
+	UserName    string  `json:"user_name"`
+	Name        *string `json:"name,omitempty"`        // Название ключа
+	PrivateKey  *string `json:"private_key,omitempty"` // Закрытый ключ в формате PEM/OpenSSH
+	Passphrase  *string `json:"passphrase,omitempty"`  // Парольная фраза закрытого ключа
+	PublicKey   *string `json:"public_key,omitempty"`  // Открытый ключ в формате authorized_keys
+	Fingerprint *string `json:"fingerprint,omitempty"` // Отпечаток открытого ключа SHA256
+	KeyType     *string `json:"key_type,omitempty"`    // Тип ключа
+	Metadata    *string `json:"metadata,omitempty"`    // Дополнительная метаинформация
+	Audit
+}
+
+// AuditEventType определяет тип события журнала аудита
+type AuditEventType string
+
+const (
+	AuditVaultExport     AuditEventType = "vault_export"     // выгрузка секретов для шифрования на клиенте
+	AuditPlaintextExport AuditEventType = "plaintext_export" // выгрузка секретов в открытом виде
+)
+
+// AuditEvent описывает событие журнала аудита, требующее внимания администратора
+type AuditEvent struct {
+	UserName string
+	Event    AuditEventType
+	Details  string // Подробности события, например формат выгрузки
+}
+
+// VaultExportRequest описывает запрос на выгрузку хранилища пользователя. Сервер передает секреты
+// расшифрованными, поэтому пароль учетной записи вводится повторно.
+type VaultExportRequest struct {
+	UserName string `json:"user_name"`
+	Password string `json:"password"` // Пароль учетной записи пользователя
+}
+
+// PlaintextExportRequest описывает запрос на выгрузку секретов для переноса в другой менеджер паролей.
+// Пароль учетной записи вводится повторно, так как выгрузка не защищена шифрованием.
+type PlaintextExportRequest struct {
+	UserName string `json:"user_name"`
+	Password string `json:"password"` // Пароль учетной записи пользователя
+	Format   string `json:"format"`   // Формат файла, в который клиент запишет секреты
+}
+
+// VaultVersion версия формата выгрузки хранилища пользователя
+const VaultVersion = 1
+
+// Vault содержит все секреты пользователя для переноса в другое хранилище вместе с их метаданными.
+// Имя пользователя в секретах при загрузке заменяется именем пользователя, выполняющего загрузку.
+type Vault struct {
+	Version     int           `json:"version"`
+	ExportedAt  time.Time     `json:"exported_at"`
+	Folders     []Folder      `json:"folders,omitempty"`
+	Credentials []Credentials `json:"credentials,omitempty"`
+	Notes       []Note        `json:"notes,omitempty"`
+	Cards       []Card        `json:"cards,omitempty"`
+	TOTP        []TOTP        `json:"totp,omitempty"`
+	SSHKeys     []SSHKey      `json:"ssh_keys,omitempty"`
+}
+
+// ImportMode определяет, как загрузка поступает с секретами, которые уже есть в хранилище
+type ImportMode string
+
+const (
+	ImportSkipDuplicates ImportMode = "skip-duplicates" // существующие секреты не изменяются (используется по умолчанию)
+	ImportMerge          ImportMode = "merge"           // существующие секреты заменяются загружаемыми
+	ImportReplace        ImportMode = "replace"         // все секреты пользователя удаляются перед загрузкой
+)
+
+// VaultImport описывает запрос на загрузку секретов пользователя
+type VaultImport struct {
+	UserName string     `json:"user_name"`
+	Mode     ImportMode `json:"mode,omitempty"`
+	Vault    Vault      `json:"vault"`
+}
+
+// ImportResult описывает итог загрузки секретов
+type ImportResult struct {
+	Created int                `json:"created"`          // Число созданных секретов
+	Updated int                `json:"updated"`          // Число замененных существующих секретов
+	Skipped int                `json:"skipped"`          // Число пропущенных секретов, которые уже есть в хранилище
+	Report  []ImportReportItem `json:"report,omitempty"` // Результат загрузки каждого секрета пакета
+}
+
+// ImportAction описывает, что загрузка сделала с секретом
+type ImportAction string
+
+const (
+	ImportCreated ImportAction = "created" // секрет создан
+	ImportSkipped ImportAction = "skipped" // секрет с таким ключом уже есть, загрузка пропущена
+)
+
+// ImportReportItem описывает результат загрузки одного секрета
+type ImportReportItem struct {
+	Type   SecretType   `json:"type"`
+	Name   string       `json:"name"` // Название секрета из ReportName
+	Action ImportAction `json:"action"`
+}
+
+// ImportBatch описывает пакет учетных данных, заметок и карт, перенесенных из другого менеджера паролей.
+// Пакет сохраняется в одной транзакции; секреты, ключ которых уже есть в хранилище, пропускаются.
+type ImportBatch struct {
+	UserName    string        `json:"user_name"`
+	DryRun      bool          `json:"dry_run,omitempty"` // Только вернуть отчет о загрузке, не сохраняя секреты
+	Credentials []Credentials `json:"credentials,omitempty"`
+	Notes       []Note        `json:"notes,omitempty"`
+	Cards       []Card        `json:"cards,omitempty"`
+}
+
+// ReportName возвращает название учетных данных для отчетов: логин и сайт
+func (c Credentials) ReportName() string {
+	name := ValueOrEmpty(c.Login)
+	if site := ValueOrEmpty(c.Site); site != "" {
+		name += " @ " + site
+	}
+	return name
+}
+
+// ReportName возвращает название заметки для отчетов
+func (n Note) ReportName() string {
+	return ValueOrEmpty(n.Title)
+}
+
+// ReportName возвращает название карты для отчетов: банк и последние четыре цифры номера
+func (c Card) ReportName() string {
+	number := strings.ReplaceAll(ValueOrEmpty(c.Number), " ", "")
+	if len(number) > 4 {
+		number = number[len(number)-4:]
+	}
+	return fmt.Sprintf("%s *%s", ValueOrEmpty(c.BankName), number)
+}
+
+// ValueOrEmpty возвращает значение строки или пустую строку для nil
+func ValueOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// HealthRequest описывает запрос отчета о состоянии паролей. Нулевые значения заменяются значениями по умолчанию.
+type HealthRequest struct {
+	UserName   string `json:"user_name"`
+	MaxAgeDays int    `json:"max_age_days,omitempty"` // Число дней без изменения, после которого пароль считается старым; по умолчанию 365
+	MinScore   int    `json:"min_score,omitempty"`    // Минимальная оценка стойкости от 1 до 4, ниже которой пароль считается слабым; по умолчанию 3
+}
+
+// HealthItem описывает учетные данные в отчете о состоянии паролей. Пароль в отчет не попадает.
+type HealthItem struct {
+	ID        *int64     `json:"id,omitempty"`
+	Name      string     `json:"name"`                 // Название учетных данных из ReportName
+	UpdatedAt *time.Time `json:"updated_at,omitempty"` // Время последнего изменения
+	AgeDays   int        `json:"age_days"`             // Число дней с последнего изменения
+	Score     int        `json:"score"`                // Оценка стойкости пароля от 0 до 4
+	Warning   string     `json:"warning,omitempty"`    // Главная слабость пароля
+}
+
+// ReusedGroup описывает группу учетных данных с одинаковым паролем. Hash - префикс HMAC пароля на ключе,
+// который создается для каждого отчета и не сохраняется, поэтому он различает группы только внутри отчета.
+type ReusedGroup struct {
+	Hash  string       `json:"hash"`
+	Items []HealthItem `json:"items"`
+}
+
+// HealthSummary число учетных данных с каждой проблемой
+type HealthSummary struct {
+	Total      int `json:"total"`       // Число проверенных учетных данных
+	Weak       int `json:"weak"`        // Слабые пароли
+	Reused     int `json:"reused"`      // Учетные данные с неуникальным паролем
+	Old        int `json:"old"`         // Давно не менявшиеся пароли
+	Missing2FA int `json:"missing_2fa"` // Учетные данные без привязанного секрета TOTP
+}
+
+// HealthReport отчет о состоянии паролей пользователя
+type HealthReport struct {
+	GeneratedAt time.Time     `json:"generated_at"`
+	MaxAgeDays  int           `json:"max_age_days"`
+	MinScore    int           `json:"min_score"`
+	Summary     HealthSummary `json:"summary"`
+	Weak        []HealthItem  `json:"weak"`
+	Reused      []ReusedGroup `json:"reused"`
+	Old         []HealthItem  `json:"old"`
+	Missing2FA  []HealthItem  `json:"missing_2fa"`
 }
 
 type Params struct {
@@ -49,4 +510,21 @@ type Params struct {
 	ApplicationPort string `envconfig:"APPLICATION_PORT"`
 	ApplicationHost string `envconfig:"APPLICATION_HOST"`
 	EncryptionKey   string `envconfig:"KEEPER_ENCRYPTION_KEY"`
+	// HistoryRetention число хранимых предыдущих версий каждого секрета; 0 хранит все версии
+	HistoryRetention int `envconfig:"KEEPER_HISTORY_RETENTION" default:"10"`
+	// TrashRetention число дней хранения удаленных секретов в корзине; 0 отключает автоматическую очистку
+	TrashRetention int `envconfig:"KEEPER_TRASH_RETENTION" default:"30"`
+	// CacheDir каталог, в котором клиент хранит локальный кэш секретов
+	CacheDir string `envconfig:"KEEPER_CACHE_DIR" default:".gophervault"`
+	// CacheKey ключ хранилища, которым шифруется локальный кэш; пустой ключ отключает работу клиента без сервера
+	CacheKey string `envconfig:"KEEPER_CACHE_KEY"`
+	// CacheMaxAge возраст кэша, после которого данные из него помечаются как устаревшие
+	CacheMaxAge time.Duration `envconfig:"KEEPER_CACHE_MAX_AGE" default:"24h"`
+	// PasswordMinLength минимальная длина генерируемого пароля
+	PasswordMinLength int `envconfig:"KEEPER_PASSWORD_MIN_LENGTH" default:"12"`
+	// PassphraseMinWords минимальное число слов генерируемой парольной фразы
+	PassphraseMinWords int `envconfig:"KEEPER_PASSPHRASE_MIN_WORDS" default:"5"`
+	// BreachDataset каталог файлов диапазонов Pwned Passwords или индекс утечек, по которому клиент проверяет
+	// сохраняемые пароли; пустое значение отключает проверку
+	BreachDataset string `envconfig:"KEEPER_BREACH_DATASET"`
 }
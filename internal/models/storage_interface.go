@@ -11,10 +11,10 @@ type Storage interface {
 	// SaveCredentials сохраняет учетные данные
 	SaveCredentials(ctx context.Context, credentialsRequest Credentials) error
 
-	// GetCredentials получает учетные данные
-	GetCredentials(ctx context.Context, credentialsRequest Credentials) ([]Credentials, error)
+	// GetCredentials получает страницу учетных данных и курсор следующей страницы
+	GetCredentials(ctx context.Context, credentialsRequest Credentials, opts ListOptions) ([]Credentials, string, error)
 
-	// DeleteCredentials удаляет учетные данные
+	// DeleteCredentials перемещает учетные данные в корзину
 	DeleteCredentials(ctx context.Context, credentialsRequest Credentials) error
 
 	// UpdateCredentials обновляет учетные данные
@@ -23,10 +23,10 @@ type Storage interface {
 	// SaveNote сохраняет заметку
 	SaveNote(ctx context.Context, note Note) error
 
-	// GetNotes получает заметки
-	GetNotes(ctx context.Context, noteRequest Note) ([]Note, error)
+	// GetNotes получает страницу заметок и курсор следующей страницы
+	GetNotes(ctx context.Context, noteRequest Note, opts ListOptions) ([]Note, string, error)
 
-	// DeleteNotes удаляет заметки
+	// DeleteNotes перемещает заметки в корзину
 	DeleteNotes(ctx context.Context, noteRequest Note) error
 
 	// UpdateNote обновляет заметку
@@ -35,12 +35,87 @@ type Storage interface {
 	// SaveCard сохраняет карту
 	SaveCard(ctx context.Context, card Card) error
 
-	// GetCard получает карты
-	GetCard(ctx context.Context, cardRequest Card) ([]Card, error)
+	// GetCard получает страницу карт и курсор следующей страницы
+	GetCard(ctx context.Context, cardRequest Card, opts ListOptions) ([]Card, string, error)
 
-	// DeleteCards удаляет карты
+	// UpdateCard обновляет карту
+	UpdateCard(ctx context.Context, card Card) error
+
+	// DeleteCards перемещает карты в корзину
 	DeleteCards(ctx context.Context, cardRequest Card) error
 
+	// SaveTOTP сохраняет секрет TOTP
+	SaveTOTP(ctx context.Context, totp TOTP) error
+
+	// GetTOTP получает секреты TOTP
+	GetTOTP(ctx context.Context, totpRequest TOTP) ([]TOTP, error)
+
+	// DeleteTOTP удаляет секреты TOTP
+	DeleteTOTP(ctx context.Context, totpRequest TOTP) error
+
+	// SaveSSHKey сохраняет SSH-ключ
+	SaveSSHKey(ctx context.Context, key SSHKey) error
+
+	// GetSSHKeys получает SSH-ключи
+	GetSSHKeys(ctx context.Context, keyRequest SSHKey) ([]SSHKey, error)
+
+	// DeleteSSHKeys удаляет SSH-ключи
+	DeleteSSHKeys(ctx context.Context, keyRequest SSHKey) error
+
+	// SaveFolder создает папку
+	SaveFolder(ctx context.Context, folder Folder) error
+
+	// GetFolders получает папки
+	GetFolders(ctx context.Context, folderRequest Folder) ([]Folder, error)
+
+	// DeleteFolder удаляет папки
+	DeleteFolder(ctx context.Context, folderRequest Folder) error
+
+	// GetTags получает теги
+	GetTags(ctx context.Context, tagRequest Tag) ([]Tag, error)
+
+	// DeleteTag удаляет теги
+	DeleteTag(ctx context.Context, tagRequest Tag) error
+
+	// OrganizeSecret перемещает секрет в другую папку и изменяет его теги
+	OrganizeSecret(ctx context.Context, request OrganizeRequest) error
+
+	// SearchNotes находит заметки по словам содержимого
+	SearchNotes(ctx context.Context, searchRequest SearchRequest) ([]Note, error)
+
+	// GetSearchItems получает описания секретов для поиска
+	GetSearchItems(ctx context.Context, searchRequest SearchRequest) ([]SearchResult, error)
+
+	// GetHistory получает сохраненные версии секрета
+	GetHistory(ctx context.Context, historyRequest HistoryRequest) ([]SecretVersion, error)
+
+	// RestoreVersion восстанавливает сохраненную версию секрета
+	RestoreVersion(ctx context.Context, historyRequest HistoryRequest) error
+
+	// GetTrash получает секреты из корзины
+	GetTrash(ctx context.Context, trashRequest TrashRequest) ([]TrashItem, error)
+
+	// RestoreFromTrash возвращает секреты из корзины
+	RestoreFromTrash(ctx context.Context, trashRequest TrashRequest) error
+
+	// EmptyTrash окончательно удаляет секреты из корзины
+	EmptyTrash(ctx context.Context, trashRequest TrashRequest) error
+
+	// GetChanges получает изменения секретов пользователя для синхронизации
+	GetChanges(ctx context.Context, syncRequest SyncRequest) (SyncResponse, error)
+
+	// GetEvents получает события об изменениях секретов пользователя без значений секретов
+	GetEvents(ctx context.Context, eventsRequest EventsRequest) (EventsResponse, error)
+
+	// ImportSecrets сохраняет пакет перенесенных секретов в одной транзакции, пропуская уже существующие
+	ImportSecrets(ctx context.Context, batch ImportBatch) (ImportResult, error)
+
+	// ImportVault загружает выгрузку секретов пользователя в одной транзакции
+	ImportVault(ctx context.Context, vaultImport VaultImport) (ImportResult, error)
+
+	// SaveAuditEvent записывает событие в журнал аудита
+	SaveAuditEvent(ctx context.Context, event AuditEvent) error
+
 	// Register регистрирует пользователя
 	Register(ctx context.Context, login string, password string) error
 
@@ -0,0 +1,302 @@
+// Package passgen генерирует пароли и парольные фразы генератором crypto/rand.
+//
+// Поддерживаются три режима: случайные символы выбранных классов, парольные фразы diceware из встроенного
+// списка 1296 слов (шесть в четвертой степени, поэтому слово можно выбрать и четырьмя бросками кубика)
+// и произносимые пароли из чередующихся согласных и гласных.
+package passgen
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// Mode определяет режим генерации
+type Mode string
+
+const (
+	ModeRandom        Mode = "random"        // случайные символы выбранных классов
+	ModeDiceware      Mode = "diceware"      // слова из встроенного списка
+	ModePronounceable Mode = "pronounceable" // чередующиеся согласные и гласные
+)
+
+// Class определяет класс символов случайного пароля
+type Class string
+
+const (
+	ClassLower   Class = "lower"
+	ClassUpper   Class = "upper"
+	ClassDigits  Class = "digits"
+	ClassSymbols Class = "symbols"
+)
+
+// classChars символы каждого класса
+var classChars = map[Class]string{
+	ClassLower:   "abcdefghijklmnopqrstuvwxyz",
+	ClassUpper:   "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	ClassDigits:  "0123456789",
+	ClassSymbols: "!#$%&*+-=?@^_~()[]{}<>/|.,:;",
+}
+
+// allClasses классы символов в порядке по умолчанию
+var allClasses = []Class{ClassLower, ClassUpper, ClassDigits, ClassSymbols}
+
+// ambiguousChars символы, которые легко спутать при чтении или наборе
+const ambiguousChars = "Il1|O0o.,:;"
+
+// Гласные и согласные произносимых паролей
+const (
+	vowels     = "aeiouy"
+	consonants = "bcdfghjklmnpqrstvwxz"
+)
+
+// Ограничения параметров генерации
+const (
+	maxLength = 128
+	maxWords  = 32
+)
+
+//go:embed wordlist.txt
+var wordlistData string
+
+// wordlist встроенный список слов diceware
+var wordlist = strings.Fields(wordlistData)
+
+var (
+	// ErrInvalidOptions означает некорректные параметры генерации
+	ErrInvalidOptions = errors.New("invalid generator options")
+	// ErrPolicyViolation означает, что параметры генерации слабее политики сервера
+	ErrPolicyViolation = errors.New("generator options violate password policy")
+)
+
+// Options параметры генерации. Нулевые значения заменяются значениями по умолчанию из Policy.
+type Options struct {
+	Mode             Mode    `json:"mode,omitempty"`
+	Length           int     `json:"length,omitempty"`    // Длина случайного или произносимого пароля
+	Words            int     `json:"words,omitempty"`     // Число слов парольной фразы
+	Separator        *string `json:"separator,omitempty"` // Разделитель слов парольной фразы, по умолчанию "-"
+	Classes          []Class `json:"classes,omitempty"`   // Классы символов случайного пароля, по умолчанию все
+	ExcludeAmbiguous bool    `json:"exclude_ambiguous,omitempty"`
+}
+
+// Policy политика паролей: минимальные и используемые по умолчанию длина пароля и число слов
+type Policy struct {
+	MinLength     int
+	MinWords      int
+	DefaultLength int
+	DefaultWords  int
+}
+
+// DefaultPolicy политика паролей по умолчанию
+var DefaultPolicy = Policy{MinLength: 12, MinWords: 5, DefaultLength: 20, DefaultWords: 6}
+
+// NewPolicy создает политику с заданными минимумами. Значения по умолчанию не меньше минимумов.
+func NewPolicy(minLength, minWords int) Policy {
+	p := DefaultPolicy
+	p.MinLength, p.MinWords = minLength, minWords
+	p.DefaultLength = max(p.DefaultLength, minLength)
+	p.DefaultWords = max(p.DefaultWords, minWords)
+	return p
+}
+
+// Result сгенерированный пароль и его энтропия в битах
+type Result struct {
+	Password string  `json:"password"`
+	Entropy  float64 `json:"entropy"`
+	Mode     Mode    `json:"mode"`
+}
+
+// Apply заполняет незаданные параметры значениями по умолчанию политики и проверяет, что параметры не слабее ее
+func (p Policy) Apply(opts Options) (Options, error) {
+	if opts.Mode == "" {
+		opts.Mode = ModeRandom
+	}
+	switch opts.Mode {
+	case ModeRandom, ModePronounceable:
+		if opts.Length == 0 {
+			opts.Length = p.DefaultLength
+		}
+		if opts.Length < p.MinLength {
+			return Options{}, fmt.Errorf("%w: длина пароля %d меньше минимальной %d", ErrPolicyViolation, opts.Length, p.MinLength)
+		}
+	case ModeDiceware:
+		if opts.Words == 0 {
+			opts.Words = p.DefaultWords
+		}
+		if opts.Words < p.MinWords {
+			return Options{}, fmt.Errorf("%w: число слов %d меньше минимального %d", ErrPolicyViolation, opts.Words, p.MinWords)
+		}
+	}
+	if opts.Mode == ModeRandom && len(opts.Classes) == 0 {
+		opts.Classes = allClasses
+	}
+	if opts.Mode == ModeDiceware && opts.Separator == nil {
+		separator := "-"
+		opts.Separator = &separator
+	}
+	return opts, opts.Validate()
+}
+
+// Validate проверяет параметры генерации
+func (o Options) Validate() error {
+	switch o.Mode {
+	case ModeRandom:
+		if o.Length < 1 || o.Length > maxLength {
+			return fmt.Errorf("%w: длина пароля должна быть от 1 до %d", ErrInvalidOptions, maxLength)
+		}
+		if len(o.Classes) == 0 {
+			return fmt.Errorf("%w: укажите хотя бы один класс символов", ErrInvalidOptions)
+		}
+		seen := map[Class]bool{}
+		for _, c := range o.Classes {
+			if _, ok := classChars[c]; !ok {
+				return fmt.Errorf("%w: неизвестный класс символов %q, используйте lower, upper, digits или symbols", ErrInvalidOptions, c)
+			}
+			if seen[c] {
+				return fmt.Errorf("%w: класс символов %q указан дважды", ErrInvalidOptions, c)
+			}
+			seen[c] = true
+		}
+		if o.Length < len(o.Classes) {
+			return fmt.Errorf("%w: длина пароля меньше числа классов символов", ErrInvalidOptions)
+		}
+	case ModePronounceable:
+		if o.Length < 1 || o.Length > maxLength {
+			return fmt.Errorf("%w: длина пароля должна быть от 1 до %d", ErrInvalidOptions, maxLength)
+		}
+	case ModeDiceware:
+		if o.Words < 1 || o.Words > maxWords {
+			return fmt.Errorf("%w: число слов должно быть от 1 до %d", ErrInvalidOptions, maxWords)
+		}
+	default:
+		return fmt.Errorf("%w: неизвестный режим %q, используйте random, diceware или pronounceable", ErrInvalidOptions, o.Mode)
+	}
+	return nil
+}
+
+// Generate генерирует пароль с параметрами opts. Параметры должны быть заполнены, например, Policy.Apply.
+func Generate(opts Options) (Result, error) {
+	if err := opts.Validate(); err != nil {
+		return Result{}, err
+	}
+	switch opts.Mode {
+	case ModeDiceware:
+		return generateDiceware(opts)
+	case ModePronounceable:
+		return generatePronounceable(opts)
+	default:
+		return generateRandom(opts)
+	}
+}
+
+// generateRandom генерирует пароль из символов выбранных классов, в котором есть хотя бы один символ каждого класса.
+// Пароли без какого-либо класса отбрасываются, поэтому распределение остается равномерным среди подходящих паролей.
+func generateRandom(opts Options) (Result, error) {
+	sets := make([]string, 0, len(opts.Classes))
+	var alphabet string
+	for _, c := range opts.Classes {
+		set := classChars[c]
+		if opts.ExcludeAmbiguous {
+			set = removeChars(set, ambiguousChars)
+		}
+		sets = append(sets, set)
+		alphabet += set
+	}
+	for {
+		password := make([]byte, opts.Length)
+		for i := range password {
+			n, err := randomInt(len(alphabet))
+			if err != nil {
+				return Result{}, err
+			}
+			password[i] = alphabet[n]
+		}
+		if containsAll(string(password), sets) {
+			return Result{
+				Password: string(password),
+				Entropy:  entropy(float64(opts.Length) * math.Log2(float64(len(alphabet)))),
+				Mode:     opts.Mode,
+			}, nil
+		}
+	}
+}
+
+// generateDiceware генерирует парольную фразу из слов встроенного списка
+func generateDiceware(opts Options) (Result, error) {
+	words := make([]string, opts.Words)
+	for i := range words {
+		n, err := randomInt(len(wordlist))
+		if err != nil {
+			return Result{}, err
+		}
+		words[i] = wordlist[n]
+	}
+	return Result{
+		Password: strings.Join(words, *opts.Separator),
+		Entropy:  entropy(float64(opts.Words) * math.Log2(float64(len(wordlist)))),
+		Mode:     opts.Mode,
+	}, nil
+}
+
+// generatePronounceable генерирует пароль из чередующихся согласных и гласных, начиная с согласной
+func generatePronounceable(opts Options) (Result, error) {
+	sets := [2]string{consonants, vowels}
+	if opts.ExcludeAmbiguous {
+		sets = [2]string{removeChars(consonants, ambiguousChars), removeChars(vowels, ambiguousChars)}
+	}
+	password := make([]byte, opts.Length)
+	var bits float64
+	for i := range password {
+		set := sets[i%2]
+		n, err := randomInt(len(set))
+		if err != nil {
+			return Result{}, err
+		}
+		password[i] = set[n]
+		bits += math.Log2(float64(len(set)))
+	}
+	return Result{Password: string(password), Entropy: entropy(bits), Mode: opts.Mode}, nil
+}
+
+// randomInt возвращает равномерно распределенное случайное число от 0 до n-1
+func randomInt(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("ошибка генератора случайных чисел: %w", err)
+	}
+	return int(v.Int64()), nil
+}
+
+// removeChars удаляет из s символы chars
+func removeChars(s, chars string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(chars, r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// containsAll проверяет, что в s есть хотя бы один символ из каждого набора
+func containsAll(s string, sets []string) bool {
+	for _, set := range sets {
+		if !strings.ContainsAny(s, set) {
+			return false
+		}
+	}
+	return true
+}
+
+// entropy округляет энтропию до десятых бита
+func entropy(bits float64) float64 {
+	return math.Round(bits*10) / 10
+}
+
+// Wordlist возвращает копию встроенного списка слов diceware
+func Wordlist() []string {
+	return append([]string(nil), wordlist...)
+}
@@ -0,0 +1,99 @@
+package passgen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestWordlist(t *testing.T) {
+	require.Len(t, wordlist, 1296)
+	seen := map[string]bool{}
+	for _, w := range wordlist {
+		assert.False(t, seen[w], "слово %q повторяется", w)
+		seen[w] = true
+		assert.Equal(t, strings.ToLower(w), w)
+	}
+}
+
+func TestPolicy_Apply(t *testing.T) {
+	policy := NewPolicy(16, 7)
+	assert.Equal(t, 20, policy.DefaultLength)
+	assert.Equal(t, 7, policy.DefaultWords)
+
+	opts, err := policy.Apply(Options{})
+	require.NoError(t, err)
+	assert.Equal(t, Options{Mode: ModeRandom, Length: 20, Classes: allClasses}, opts)
+
+	opts, err = policy.Apply(Options{Mode: ModeDiceware})
+	require.NoError(t, err)
+	assert.Equal(t, Options{Mode: ModeDiceware, Words: 7, Separator: strPtr("-")}, opts)
+
+	_, err = policy.Apply(Options{Length: 12})
+	assert.ErrorIs(t, err, ErrPolicyViolation)
+	_, err = policy.Apply(Options{Mode: ModeDiceware, Words: 4})
+	assert.ErrorIs(t, err, ErrPolicyViolation)
+	_, err = policy.Apply(Options{Mode: "emoji"})
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+	_, err = policy.Apply(Options{Classes: []Class{ClassDigits, "runes"}})
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+	_, err = policy.Apply(Options{Length: 200})
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+}
+
+func TestGenerate_Random(t *testing.T) {
+	opts := Options{Mode: ModeRandom, Length: 4, Classes: allClasses, ExcludeAmbiguous: true}
+	for i := 0; i < 200; i++ {
+		res, err := Generate(opts)
+		require.NoError(t, err)
+		require.Len(t, res.Password, 4)
+		// В каждом пароле есть символ каждого класса и нет неоднозначных символов
+		assert.True(t, strings.ContainsAny(res.Password, classChars[ClassLower]))
+		assert.True(t, strings.ContainsAny(res.Password, classChars[ClassUpper]))
+		assert.True(t, strings.ContainsAny(res.Password, classChars[ClassDigits]))
+		assert.True(t, strings.ContainsAny(res.Password, classChars[ClassSymbols]))
+		assert.False(t, strings.ContainsAny(res.Password, ambiguousChars), res.Password)
+	}
+
+	res, err := Generate(Options{Mode: ModeRandom, Length: 20, Classes: []Class{ClassDigits}})
+	require.NoError(t, err)
+	assert.Regexp(t, `^[0-9]{20}$`, res.Password)
+	assert.Equal(t, 66.4, res.Entropy)
+
+	// Повторная генерация дает другой пароль
+	again, err := Generate(Options{Mode: ModeRandom, Length: 20, Classes: []Class{ClassDigits}})
+	require.NoError(t, err)
+	assert.NotEqual(t, res.Password, again.Password)
+}
+
+func TestGenerate_Diceware(t *testing.T) {
+	res, err := Generate(Options{Mode: ModeDiceware, Words: 6, Separator: strPtr(" ")})
+	require.NoError(t, err)
+	words := strings.Split(res.Password, " ")
+	require.Len(t, words, 6)
+	for _, w := range words {
+		assert.Contains(t, wordlist, w)
+	}
+	assert.Equal(t, 62.0, res.Entropy)
+}
+
+func TestGenerate_Pronounceable(t *testing.T) {
+	res, err := Generate(Options{Mode: ModePronounceable, Length: 12, ExcludeAmbiguous: true})
+	require.NoError(t, err)
+	require.Len(t, res.Password, 12)
+	for i, r := range res.Password {
+		if i%2 == 0 {
+			assert.Contains(t, consonants, string(r))
+		} else {
+			assert.Contains(t, vowels, string(r))
+		}
+	}
+	assert.False(t, strings.ContainsAny(res.Password, ambiguousChars))
+	assert.Equal(t, 39.4, res.Entropy)
+}
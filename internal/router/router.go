@@ -3,6 +3,7 @@ package router
 import (
 	"github.com/ZnNr/GopherVault/internal/handler"
 	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/passgen"
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 	"net/http"
@@ -17,9 +18,10 @@ func setJSONContentTypeMiddleware(next http.Handler) http.Handler {
 }
 
 // New создает новый маршрутизатор с настройками и обработчиками
-func New(db models.Storage, log *zap.SugaredLogger) *chi.Mux {
+func New(db models.Storage, log *zap.SugaredLogger, policy passgen.Policy) *chi.Mux {
 	// Создаем обработчик HTTP запросов
 	httpHandler := handler.New(db, log)
+	httpHandler.SetPasswordPolicy(policy)
 
 	// Инициализируем новый маршрутизатор Chi
 	r := chi.NewRouter()
@@ -41,18 +43,67 @@ func New(db models.Storage, log *zap.SugaredLogger) *chi.Mux {
 		r.Post("/delete/credentials", httpHandler.DeleteUserCredentialsHandler)
 		r.Post("/get/credentials", httpHandler.GetUserCredentialsHandler)
 		r.Post("/update/credentials", httpHandler.UpdateUserCredentialsHandler)
+		r.Post("/credentials/match", httpHandler.MatchUserCredentialsHandler)
 
 		// Маршруты для управления заметками пользователя
 		r.Post("/save/note", httpHandler.SaveUserNoteHandler)
 		r.Post("/delete/note", httpHandler.DeleteUserNotesHandler)
 		r.Post("/get/note", httpHandler.GetUserNoteHandler)
 		r.Post("/update/note", httpHandler.UpdateUserNoteHandler)
+		r.Post("/notes/search", httpHandler.SearchUserNotesHandler)
 
 		// Маршруты для управления банковскими картами
-		// Обновление карт не предусмотрено
 		r.Post("/save/card", httpHandler.SaveCardHandler)
 		r.Post("/delete/card", httpHandler.DeleteCardHandler)
 		r.Post("/get/card", httpHandler.GetCardHandler)
+		r.Post("/update/card", httpHandler.UpdateCardHandler)
+
+		// Маршруты для управления секретами TOTP
+		r.Post("/save/totp", httpHandler.SaveTOTPHandler)
+		r.Post("/delete/totp", httpHandler.DeleteTOTPHandler)
+		r.Post("/get/totp", httpHandler.GetTOTPHandler)
+		r.Get("/totp/{id}/code", httpHandler.GetTOTPCodeHandler)
+
+		// Маршруты для управления SSH-ключами
+		r.Post("/save/ssh-key", httpHandler.SaveSSHKeyHandler)
+		r.Post("/delete/ssh-key", httpHandler.DeleteSSHKeysHandler)
+		r.Post("/get/ssh-key", httpHandler.GetSSHKeysHandler)
+
+		// Маршруты для управления папками и тегами
+		r.Post("/save/folder", httpHandler.SaveFolderHandler)
+		r.Post("/delete/folder", httpHandler.DeleteFolderHandler)
+		r.Post("/get/folder", httpHandler.GetFoldersHandler)
+		r.Post("/delete/tag", httpHandler.DeleteTagHandler)
+		r.Post("/get/tag", httpHandler.GetTagsHandler)
+		r.Post("/organize", httpHandler.OrganizeSecretHandler)
+
+		// Маршрут для поиска по секретам
+		r.Post("/search", httpHandler.SearchHandler)
+
+		// Маршруты для истории версий секретов
+		r.Get("/history/{type}/{id}", httpHandler.GetHistoryHandler)
+		r.Post("/history/{type}/{id}/restore", httpHandler.RestoreVersionHandler)
+
+		// Маршруты для управления корзиной
+		r.Post("/get/trash", httpHandler.GetTrashHandler)
+		r.Post("/trash/restore", httpHandler.RestoreFromTrashHandler)
+		r.Post("/trash/empty", httpHandler.EmptyTrashHandler)
+
+		// Маршруты для выгрузки и загрузки секретов пользователя
+		r.Post("/export", httpHandler.ExportVaultHandler)
+		r.Post("/export/plaintext", httpHandler.PlaintextExportHandler)
+		r.Post("/import", httpHandler.ImportVaultHandler)
+		r.Post("/import/batch", httpHandler.ImportBatchHandler)
+
+		// Маршруты для синхронизации клиентов и потока событий об изменениях
+		r.Get("/sync", httpHandler.GetChangesHandler)
+		r.Get("/events", httpHandler.GetEventsHandler)
+
+		// Маршрут для генерации паролей
+		r.Post("/generate", httpHandler.GeneratePasswordHandler)
+
+		// Маршрут для отчета о состоянии паролей
+		r.Post("/reports/health", httpHandler.HealthReportHandler)
 	})
 
 	// Возвращаем итоговый маршрутизатор
@@ -0,0 +1,132 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// Оценки качества совпадения: чем больше значение, тем точнее совпадение
+const (
+	scoreNone      = 0
+	scoreFuzzy     = 10 // символы запроса встречаются в значении по порядку
+	scoreTypo      = 20 // слово значения отличается от запроса не более чем на одну правку
+	scoreSubstring = 30
+	scorePrefix    = 40
+	scoreExact     = 50
+)
+
+// Score возвращает наилучшую оценку совпадения запроса query с одним из значений values.
+// Сравнение выполняется без учета регистра. Нулевое значение означает, что совпадения нет.
+func Score(query string, values ...string) int {
+	query = normalize(query)
+	if query == "" {
+		return scoreNone
+	}
+	best := scoreNone
+	for _, v := range values {
+		if s := scoreValue(query, normalize(v)); s > best {
+			best = s
+		}
+	}
+	return best
+}
+
+// Filter оставляет секреты, совпадающие с запросом, и сортирует их по убыванию оценки.
+// При равной оценке сохраняется исходный порядок.
+func Filter(items []models.SearchResult, query string) []models.SearchResult {
+	var res []models.SearchResult
+	for _, item := range items {
+		if item.Score = Score(query, searchableValues(item)...); item.Score > scoreNone {
+			res = append(res, item)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Score > res[j].Score
+	})
+	return res
+}
+
+// searchableValues возвращает значения секрета, по которым выполняется поиск
+func searchableValues(item models.SearchResult) []string {
+	values := append([]string{item.Title}, item.URLs...)
+	values = append(values, item.Tags...)
+	for _, v := range []*string{item.Login, item.Site, item.Folder, item.Metadata} {
+		if v != nil {
+			values = append(values, *v)
+		}
+	}
+	return values
+}
+
+func scoreValue(query, value string) int {
+	switch {
+	case value == "":
+		return scoreNone
+	case value == query:
+		return scoreExact
+	case strings.HasPrefix(value, query):
+		return scorePrefix
+	case strings.Contains(value, query):
+		return scoreSubstring
+	}
+	for _, word := range strings.FieldsFunc(value, isSeparator) {
+		if utf8.RuneCountInString(query) >= 4 && withinOneEdit(query, word) {
+			return scoreTypo
+		}
+	}
+	if isSubsequence(query, value) {
+		return scoreFuzzy
+	}
+	return scoreNone
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+func isSeparator(r rune) bool {
+	return strings.ContainsRune(" \t\n/.,:;-_@", r)
+}
+
+// isSubsequence проверяет, что все символы query встречаются в value в том же порядке
+func isSubsequence(query, value string) bool {
+	q := []rune(query)
+	i := 0
+	for _, r := range value {
+		if i < len(q) && r == q[i] {
+			i++
+		}
+	}
+	return i == len(q)
+}
+
+// withinOneEdit проверяет, что строки отличаются не более чем на одну вставку, удаление или замену символа
+func withinOneEdit(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) > len(rb) {
+		ra, rb = rb, ra
+	}
+	if len(rb)-len(ra) > 1 {
+		return false
+	}
+	i, j, edits := 0, 0, 0
+	for i < len(ra) && j < len(rb) {
+		if ra[i] == rb[j] {
+			i++
+			j++
+			continue
+		}
+		edits++
+		if edits > 1 {
+			return false
+		}
+		if len(ra) == len(rb) {
+			i++
+		}
+		j++
+	}
+	return edits+(len(rb)-j)+(len(ra)-i) <= 1
+}
@@ -0,0 +1,48 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScore(t *testing.T) {
+	testCases := []struct {
+		name     string
+		query    string
+		values   []string
+		expected int
+	}{
+		{name: "exact, case insensitive", query: "Braavos", values: []string{"braavos"}, expected: scoreExact},
+		{name: "prefix", query: "iron", values: []string{"Iron Bank"}, expected: scorePrefix},
+		{name: "substring", query: "bank", values: []string{"Iron Bank"}, expected: scoreSubstring},
+		{name: "typo in word", query: "lanister", values: []string{"house lannister"}, expected: scoreTypo},
+		{name: "fuzzy subsequence", query: "kgl", values: []string{"kings landing"}, expected: scoreFuzzy},
+		{name: "best of values", query: "wall", values: []string{"castle black", "the wall"}, expected: scoreSubstring},
+		{name: "no match", query: "dorne", values: []string{"winterfell"}, expected: scoreNone},
+		{name: "empty query", query: " ", values: []string{"winterfell"}, expected: scoreNone},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Score(tt.query, tt.values...))
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	login := "arya"
+	items := []models.SearchResult{
+		{Type: models.SecretNote, Title: "list of names"},
+		{Type: models.SecretCredentials, Title: "Braavos", Login: &login},
+		{Type: models.SecretCard, Title: "Iron Bank", Tags: []string{"braavos"}},
+		{Type: models.SecretNote, Title: "valar morghulis"},
+	}
+
+	res := Filter(items, "braavos")
+	assert.Equal(t, []models.SearchResult{
+		{Type: models.SecretCredentials, Title: "Braavos", Login: &login, Score: scoreExact},
+		{Type: models.SecretCard, Title: "Iron Bank", Tags: []string{"braavos"}, Score: scoreExact},
+	}, res)
+	assert.Empty(t, Filter(items, "dragonstone"))
+}
@@ -0,0 +1,126 @@
+package sshkey
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// ErrReadOnly означает попытку изменить набор ключей агента через протокол ssh-agent
+var ErrReadOnly = errors.New("GopherVault agent is read-only")
+
+// UseLogger вызывается при каждом использовании ключа агентом
+type UseLogger func(comment, fingerprint string)
+
+// Agent обслуживает ключи из хранилища по протоколу ssh-agent.
+// Набор ключей нельзя изменить клиентами агента, каждое использование ключа логируется.
+type Agent struct {
+	keyring agent.ExtendedAgent
+	logUse  UseLogger
+}
+
+// NewAgent создает агента с переданными ключами
+func NewAgent(keys []agent.AddedKey, logUse UseLogger) (*Agent, error) {
+	keyring := agent.NewKeyring().(agent.ExtendedAgent)
+	for _, k := range keys {
+		if err := keyring.Add(k); err != nil {
+			return nil, err
+		}
+	}
+	return &Agent{keyring: keyring, logUse: logUse}, nil
+}
+
+// List возвращает открытые ключи агента
+func (a *Agent) List() ([]*agent.Key, error) {
+	return a.keyring.List()
+}
+
+// Sign подписывает данные ключом и логирует его использование
+func (a *Agent) Sign(key ssh.PublicKey, data []byte) (*ssh.Signature, error) {
+	return a.SignWithFlags(key, data, 0)
+}
+
+// SignWithFlags подписывает данные ключом с учетом флагов и логирует его использование
+func (a *Agent) SignWithFlags(key ssh.PublicKey, data []byte, flags agent.SignatureFlags) (*ssh.Signature, error) {
+	sig, err := a.keyring.SignWithFlags(key, data, flags)
+	if err != nil {
+		return nil, err
+	}
+	if a.logUse != nil {
+		a.logUse(a.comment(key), ssh.FingerprintSHA256(key))
+	}
+	return sig, nil
+}
+
+// Signers возвращает подписывающие объекты для всех ключей агента
+func (a *Agent) Signers() ([]ssh.Signer, error) {
+	return a.keyring.Signers()
+}
+
+// Add не поддерживается: ключи добавляются только из хранилища
+func (a *Agent) Add(agent.AddedKey) error {
+	return ErrReadOnly
+}
+
+// Remove не поддерживается: ключи удаляются только из хранилища
+func (a *Agent) Remove(ssh.PublicKey) error {
+	return ErrReadOnly
+}
+
+// RemoveAll не поддерживается: ключи удаляются только из хранилища
+func (a *Agent) RemoveAll() error {
+	return ErrReadOnly
+}
+
+// Lock блокирует агента парольной фразой
+func (a *Agent) Lock(passphrase []byte) error {
+	return a.keyring.Lock(passphrase)
+}
+
+// Unlock разблокирует агента
+func (a *Agent) Unlock(passphrase []byte) error {
+	return a.keyring.Unlock(passphrase)
+}
+
+// Extension не поддерживается
+func (a *Agent) Extension(string, []byte) ([]byte, error) {
+	return nil, agent.ErrExtensionUnsupported
+}
+
+// comment возвращает комментарий (название) ключа
+func (a *Agent) comment(key ssh.PublicKey) string {
+	keys, err := a.keyring.List()
+	if err != nil {
+		return ""
+	}
+	for _, k := range keys {
+		if string(k.Marshal()) == string(key.Marshal()) {
+			return k.Comment
+		}
+	}
+	return ""
+}
+
+// Serve принимает соединения на listener и обслуживает их агентом до отмены контекста
+func Serve(ctx context.Context, listener net.Listener, a agent.Agent) error {
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			_ = agent.ServeAgent(a, conn)
+		}()
+	}
+}
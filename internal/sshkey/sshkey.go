@@ -0,0 +1,69 @@
+package sshkey
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrInvalidKey означает, что закрытый ключ не удалось разобрать
+var ErrInvalidKey = errors.New("invalid ssh private key")
+
+// ErrUnsupportedKey означает, что тип ключа не поддерживается
+var ErrUnsupportedKey = errors.New("unsupported ssh key type")
+
+// Info содержит сведения, вычисляемые по закрытому ключу
+type Info struct {
+	Type        string // Тип ключа, например ssh-ed25519
+	PublicKey   string // Открытый ключ в формате authorized_keys
+	Fingerprint string // Отпечаток открытого ключа SHA256
+}
+
+// ParseRawKey разбирает закрытый ключ в формате PEM/OpenSSH, при необходимости используя парольную фразу.
+// Поддерживаются ключи ed25519, RSA и ECDSA.
+func ParseRawKey(privateKey, passphrase string) (interface{}, error) {
+	var (
+		raw interface{}
+		err error
+	)
+	if passphrase != "" {
+		raw, err = ssh.ParseRawPrivateKeyWithPassphrase([]byte(privateKey), []byte(passphrase))
+	} else {
+		raw, err = ssh.ParseRawPrivateKey([]byte(privateKey))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKey, err.Error())
+	}
+
+	switch k := raw.(type) {
+	case ed25519.PrivateKey, *rsa.PrivateKey, *ecdsa.PrivateKey:
+		return raw, nil
+	case *ed25519.PrivateKey:
+		return *k, nil
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, raw)
+	}
+}
+
+// Inspect вычисляет тип, открытый ключ и отпечаток для закрытого ключа
+func Inspect(privateKey, passphrase string) (Info, error) {
+	raw, err := ParseRawKey(privateKey, passphrase)
+	if err != nil {
+		return Info{}, err
+	}
+	signer, err := ssh.NewSignerFromKey(raw)
+	if err != nil {
+		return Info{}, fmt.Errorf("%w: %s", ErrInvalidKey, err.Error())
+	}
+	pub := signer.PublicKey()
+	return Info{
+		Type:        pub.Type(),
+		PublicKey:   strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pub))),
+		Fingerprint: ssh.FingerprintSHA256(pub),
+	}, nil
+}
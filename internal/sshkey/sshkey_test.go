@@ -0,0 +1,113 @@
+package sshkey
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func marshalKey(t *testing.T, key interface{}, passphrase string) string {
+	var (
+		block *pem.Block
+		err   error
+	)
+	if passphrase != "" {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(key, "test", []byte(passphrase))
+	} else {
+		block, err = ssh.MarshalPrivateKey(key, "test")
+	}
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(block))
+}
+
+func TestInspect(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	testCases := []struct {
+		name         string
+		key          interface{}
+		passphrase   string
+		expectedType string
+	}{
+		{name: "ed25519", key: edKey, expectedType: ssh.KeyAlgoED25519},
+		{name: "ed25519 with passphrase", key: edKey, passphrase: "hodor", expectedType: ssh.KeyAlgoED25519},
+		{name: "rsa", key: rsaKey, expectedType: ssh.KeyAlgoRSA},
+		{name: "ecdsa", key: ecKey, expectedType: ssh.KeyAlgoECDSA256},
+	}
+	for _, tt := range testCases {
+		t.Run("positive: "+tt.name, func(t *testing.T) {
+			info, err := Inspect(marshalKey(t, tt.key, tt.passphrase), tt.passphrase)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedType, info.Type)
+
+			pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(info.PublicKey))
+			assert.NoError(t, err)
+			assert.Equal(t, ssh.FingerprintSHA256(pub), info.Fingerprint)
+		})
+	}
+	t.Run("negative: wrong passphrase", func(t *testing.T) {
+		_, err := Inspect(marshalKey(t, edKey, "hodor"), "hold the door")
+		assert.ErrorIs(t, err, ErrInvalidKey)
+	})
+	t.Run("negative: not a key", func(t *testing.T) {
+		_, err := Inspect("winter is coming", "")
+		assert.ErrorIs(t, err, ErrInvalidKey)
+	})
+}
+
+func TestAgent(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	var used []string
+	a, err := NewAgent([]agent.AddedKey{{PrivateKey: edKey, Comment: "winterfell"}}, func(comment, fingerprint string) {
+		used = append(used, comment)
+	})
+	require.NoError(t, err)
+
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = Serve(ctx, listener, a)
+	}()
+
+	conn, err := net.Dial("unix", socket)
+	require.NoError(t, err)
+	defer conn.Close()
+	client := agent.NewClient(conn)
+
+	keys, err := client.List()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "winterfell", keys[0].Comment)
+
+	t.Run("positive: sign logs key use", func(t *testing.T) {
+		sig, err := client.Sign(keys[0], []byte("the north remembers"))
+		assert.NoError(t, err)
+		assert.NoError(t, keys[0].Verify([]byte("the north remembers"), sig))
+		assert.Equal(t, []string{"winterfell"}, used)
+	})
+	t.Run("negative: agent is read-only", func(t *testing.T) {
+		_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+		assert.Error(t, client.Add(agent.AddedKey{PrivateKey: otherKey}))
+		assert.Error(t, client.RemoveAll())
+		keys, err := client.List()
+		assert.NoError(t, err)
+		assert.Len(t, keys, 1)
+	})
+}
@@ -0,0 +1,409 @@
+// Package strength оценивает стойкость паролей по числу попыток, которое понадобится для их подбора.
+//
+// Оценка устроена так же, как в zxcvbn: в пароле ищутся шаблоны (распространенные пароли, словарные слова,
+// в том числе с заменой букв похожими символами, повторы, последовательности, ряды клавиатуры и годы),
+// затем выбирается разбиение пароля на шаблоны и подбираемые перебором участки с наименьшим числом попыток.
+// Число попыток переводится в оценку от 0 (подбирается мгновенно) до 4 (очень стойкий пароль).
+package strength
+
+import (
+	_ "embed"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ZnNr/GopherVault/internal/passgen"
+)
+
+// Pattern определяет вид найденного в пароле шаблона
+type Pattern string
+
+const (
+	PatternCommon     Pattern = "common_password" // распространенный пароль
+	PatternDictionary Pattern = "dictionary"      // словарное слово
+	PatternRepeat     Pattern = "repeat"          // повтор символа
+	PatternSequence   Pattern = "sequence"        // последовательность вроде abc или 321
+	PatternKeyboard   Pattern = "keyboard"        // ряд клавиатуры вроде qwerty
+	PatternYear       Pattern = "year"            // год
+	PatternBruteforce Pattern = "bruteforce"      // участок без шаблонов
+)
+
+// maxLength длина, после которой символы пароля не учитываются в оценке
+const maxLength = 100
+
+// bruteforceCardinality число вариантов символа участка, подбираемого перебором
+const bruteforceCardinality = 10
+
+// Пороги числа попыток для оценок 1-4
+var scoreThresholds = [4]float64{1e3, 1e6, 1e8, 1e10}
+
+//go:embed common.txt
+var commonData string
+
+// dictionary ранги слов: распространенные пароли по порядку, затем слова списка diceware
+var dictionary = buildDictionary()
+
+// keyboardRows ряды клавиатуры QWERTY
+var keyboardRows = []string{"1234567890", "qwertyuiop", "asdfghjkl", "zxcvbnm"}
+
+// leetSubstitutions замены букв похожими символами
+var leetSubstitutions = map[rune]rune{'4': 'a', '@': 'a', '3': 'e', '1': 'i', '!': 'i', '0': 'o', '$': 's', '5': 's', '7': 't', '+': 't'}
+
+// dictionaryEntry ранг слова словаря и признак распространенного пароля
+type dictionaryEntry struct {
+	rank   int
+	common bool
+}
+
+// buildDictionary собирает словарь из встроенного списка распространенных паролей и списка слов diceware
+func buildDictionary() map[string]dictionaryEntry {
+	common := strings.Fields(commonData)
+	words := passgen.Wordlist()
+	dict := make(map[string]dictionaryEntry, len(common)+len(words))
+	for i, w := range common {
+		dict[w] = dictionaryEntry{rank: i + 1, common: true}
+	}
+	// Частота слов списка diceware неизвестна, поэтому у всех слов одинаковый ранг
+	for _, w := range words {
+		if _, ok := dict[w]; !ok {
+			dict[w] = dictionaryEntry{rank: len(common) + len(words)}
+		}
+	}
+	return dict
+}
+
+// Match шаблон, найденный в пароле
+type Match struct {
+	Pattern Pattern `json:"pattern"`
+	Token   string  `json:"-"`
+	Guesses float64 `json:"-"`
+	i, j    int     // Позиции первого и последнего символа шаблона
+}
+
+// Estimate оценка стойкости пароля
+type Estimate struct {
+	Score        int       `json:"score"`         // Оценка от 0 до 4
+	GuessesLog10 float64   `json:"guesses_log10"` // Десятичный логарифм числа попыток подбора
+	Patterns     []Pattern `json:"patterns,omitempty"`
+	Warning      string    `json:"warning,omitempty"` // Главная слабость пароля
+}
+
+// Check оценивает стойкость пароля
+func Check(password string) Estimate {
+	runes := []rune(password)
+	if len(runes) > maxLength {
+		runes = runes[:maxLength]
+	}
+	if len(runes) == 0 {
+		return Estimate{Warning: "пустой пароль"}
+	}
+	sequence, guesses := bestSequence(runes, findMatches(runes))
+
+	est := Estimate{GuessesLog10: math.Round(math.Log10(guesses)*100) / 100}
+	for _, threshold := range scoreThresholds {
+		if guesses >= threshold+5 {
+			est.Score++
+		}
+	}
+	seen := map[Pattern]bool{}
+	for _, m := range sequence {
+		if !seen[m.Pattern] && m.Pattern != PatternBruteforce {
+			seen[m.Pattern] = true
+			est.Patterns = append(est.Patterns, m.Pattern)
+		}
+	}
+	est.Warning = warning(sequence, len(runes))
+	return est
+}
+
+// warning возвращает описание главной слабости пароля или пустую строку для стойкого пароля
+func warning(sequence []Match, length int) string {
+	if len(sequence) == 1 {
+		switch sequence[0].Pattern {
+		case PatternCommon:
+			return "один из самых распространенных паролей"
+		case PatternDictionary:
+			return "одно словарное слово подбирается легко"
+		}
+	}
+	for _, m := range sequence {
+		switch m.Pattern {
+		case PatternCommon:
+			return "содержит распространенный пароль"
+		case PatternRepeat:
+			return "повторы вроде aaa легко угадать"
+		case PatternSequence:
+			return "последовательности вроде abc или 6543 легко угадать"
+		case PatternKeyboard:
+			return "ряды клавиатуры вроде qwerty легко угадать"
+		case PatternYear:
+			return "годы и даты легко угадать"
+		}
+	}
+	if length < 8 {
+		return "слишком короткий пароль"
+	}
+	return ""
+}
+
+// findMatches находит в пароле все шаблоны
+func findMatches(runes []rune) []Match {
+	lower := []rune(strings.ToLower(string(runes)))
+	var matches []Match
+	matches = append(matches, dictionaryMatches(runes, lower)...)
+	matches = append(matches, repeatMatches(lower)...)
+	matches = append(matches, sequenceMatches(lower)...)
+	matches = append(matches, keyboardMatches(lower)...)
+	matches = append(matches, yearMatches(lower)...)
+	return matches
+}
+
+// dictionaryMatches находит словарные слова, в том числе с заменой букв похожими символами
+func dictionaryMatches(runes, lower []rune) []Match {
+	unleet := make([]rune, len(lower))
+	for i, r := range lower {
+		if s, ok := leetSubstitutions[r]; ok {
+			unleet[i] = s
+		} else {
+			unleet[i] = r
+		}
+	}
+	var matches []Match
+	for i := range lower {
+		for j := i + 2; j < len(lower); j++ {
+			substitutions := 0
+			entry, ok := dictionary[string(lower[i:j+1])]
+			if !ok {
+				if entry, ok = dictionary[string(unleet[i:j+1])]; !ok {
+					continue
+				}
+				for k := i; k <= j; k++ {
+					if unleet[k] != lower[k] {
+						substitutions++
+					}
+				}
+			}
+			pattern := PatternDictionary
+			if entry.common {
+				pattern = PatternCommon
+			}
+			guesses := float64(entry.rank) * uppercaseVariations(runes[i:j+1]) * math.Pow(2, float64(substitutions))
+			matches = append(matches, Match{Pattern: pattern, Token: string(runes[i : j+1]), Guesses: guesses, i: i, j: j})
+		}
+	}
+	return matches
+}
+
+// uppercaseVariations возвращает множитель попыток за заглавные буквы: заглавная первая или все буквы
+// почти не усложняют подбор, остальные сочетания учитываются числом способов выбрать заглавные буквы
+func uppercaseVariations(token []rune) float64 {
+	upper, letters := 0, 0
+	for _, r := range token {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	switch {
+	case upper == 0:
+		return 1
+	case upper == letters || (upper == 1 && unicode.IsUpper(token[0])):
+		return 2
+	}
+	variations := 0.0
+	for k := 1; k <= min(upper, letters-upper); k++ {
+		variations += binomial(letters, k)
+	}
+	return variations
+}
+
+// repeatMatches находит повторы одного символа длиной от трех символов
+func repeatMatches(lower []rune) []Match {
+	var matches []Match
+	for i := 0; i < len(lower); {
+		j := i
+		for j+1 < len(lower) && lower[j+1] == lower[i] {
+			j++
+		}
+		if j-i >= 2 {
+			guesses := float64(charCardinality(lower[i])) * float64(j-i+1)
+			matches = append(matches, Match{Pattern: PatternRepeat, Token: string(lower[i : j+1]), Guesses: guesses, i: i, j: j})
+		}
+		i = j + 1
+	}
+	return matches
+}
+
+// sequenceMatches находит возрастающие и убывающие последовательности букв или цифр длиной от трех символов
+func sequenceMatches(lower []rune) []Match {
+	var matches []Match
+	for i := 0; i+2 < len(lower); {
+		delta := lower[i+1] - lower[i]
+		if (delta != 1 && delta != -1) || charCardinality(lower[i]) != charCardinality(lower[i+1]) {
+			i++
+			continue
+		}
+		j := i + 1
+		for j+1 < len(lower) && lower[j+1]-lower[j] == delta && charCardinality(lower[j+1]) == charCardinality(lower[i]) {
+			j++
+		}
+		if j-i >= 2 {
+			// Последовательности от a или 1 угадываются первыми
+			base := float64(charCardinality(lower[i]))
+			if lower[i] == 'a' || lower[i] == '1' || lower[i] == '0' || lower[i] == 'z' || lower[i] == '9' {
+				base = 4
+			}
+			guesses := base * float64(j-i+1)
+			if delta < 0 {
+				guesses *= 2
+			}
+			matches = append(matches, Match{Pattern: PatternSequence, Token: string(lower[i : j+1]), Guesses: guesses, i: i, j: j})
+		}
+		i = j
+	}
+	return matches
+}
+
+// keyboardMatches находит участки рядов клавиатуры длиной от четырех символов в обоих направлениях
+func keyboardMatches(lower []rune) []Match {
+	var matches []Match
+	for _, row := range keyboardRows {
+		reversed := reverse(row)
+		for i := range lower {
+			for j := i + 3; j < len(lower); j++ {
+				token := string(lower[i : j+1])
+				if !strings.Contains(row, token) && !strings.Contains(reversed, token) {
+					break
+				}
+				guesses := float64(len(row)) * 2 * float64(j-i+1)
+				matches = append(matches, Match{Pattern: PatternKeyboard, Token: token, Guesses: guesses, i: i, j: j})
+			}
+		}
+	}
+	return matches
+}
+
+// yearMatches находит годы с 1900 по 2099
+func yearMatches(lower []rune) []Match {
+	var matches []Match
+	for i := 0; i+3 < len(lower); i++ {
+		token := string(lower[i : i+4])
+		year, err := strconv.Atoi(token)
+		if err != nil || year < 1900 || year > 2099 {
+			continue
+		}
+		// Годы ближе к текущему угадываются первыми, поэтому учитывается расстояние от 2000 года
+		guesses := math.Max(math.Abs(float64(year-2000)), 20) * 2
+		matches = append(matches, Match{Pattern: PatternYear, Token: token, Guesses: guesses, i: i, j: i + 3})
+	}
+	return matches
+}
+
+// bestSequence выбирает разбиение пароля на шаблоны и участки перебора с наименьшим числом попыток.
+// Как в zxcvbn, число попыток разбиения из n частей умножается на n!, потому что атакующему нужно перебрать
+// и порядок шаблонов.
+func bestSequence(runes []rune, matches []Match) ([]Match, float64) {
+	n := len(runes)
+	byEnd := make([][]Match, n)
+	for _, m := range matches {
+		byEnd[m.j] = append(byEnd[m.j], m)
+	}
+
+	// best[k][l] наименьшее произведение попыток для первых k+1 символов из l частей
+	type state struct {
+		guesses float64
+		last    Match
+		ok      bool
+	}
+	best := make([][]state, n)
+	for k := range best {
+		best[k] = make([]state, n+2)
+	}
+	// update сохраняет разбиение, если оно лучше известного
+	update := func(m Match, l int, guesses float64) {
+		if s := best[m.j][l]; !s.ok || guesses < s.guesses {
+			best[m.j][l] = state{guesses: guesses, last: m, ok: true}
+		}
+	}
+	for k := 0; k < n; k++ {
+		candidates := append([]Match(nil), byEnd[k]...)
+		// Участок перебора может начинаться в любой позиции
+		for i := 0; i <= k; i++ {
+			candidates = append(candidates, Match{
+				Pattern: PatternBruteforce, Token: string(runes[i : k+1]),
+				Guesses: math.Pow(bruteforceCardinality, float64(k-i+1)), i: i, j: k,
+			})
+		}
+		for _, m := range candidates {
+			if m.i == 0 {
+				update(m, 1, m.Guesses)
+				continue
+			}
+			for l, prev := range best[m.i-1] {
+				// Два участка перебора подряд не имеют смысла: это один участок
+				if prev.ok && l+1 < len(best[k]) && !(prev.last.Pattern == PatternBruteforce && m.Pattern == PatternBruteforce) {
+					update(m, l+1, prev.guesses*m.Guesses)
+				}
+			}
+		}
+	}
+
+	bestL, bestGuesses := 0, math.Inf(1)
+	for l, s := range best[n-1] {
+		if !s.ok {
+			continue
+		}
+		if g := s.guesses * factorial(l); g < bestGuesses {
+			bestL, bestGuesses = l, g
+		}
+	}
+	sequence := make([]Match, bestL)
+	for k, l := n-1, bestL; l > 0; l-- {
+		m := best[k][l].last
+		sequence[l-1] = m
+		k = m.i - 1
+	}
+	return sequence, math.Max(bestGuesses, 1)
+}
+
+// charCardinality возвращает число символов класса, к которому относится r
+func charCardinality(r rune) int {
+	switch {
+	case unicode.IsDigit(r):
+		return 10
+	case unicode.IsLower(r):
+		return 26
+	default:
+		return 33
+	}
+}
+
+// reverse возвращает строку в обратном порядке
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+// factorial возвращает n!
+func factorial(n int) float64 {
+	res := 1.0
+	for i := 2; i <= n; i++ {
+		res *= float64(i)
+	}
+	return res
+}
+
+// binomial возвращает число сочетаний из n по k
+func binomial(n, k int) float64 {
+	res := 1.0
+	for i := 1; i <= k; i++ {
+		res = res * float64(n-k+i) / float64(i)
+	}
+	return res
+}
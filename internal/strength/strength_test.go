@@ -0,0 +1,62 @@
+package strength
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	testCases := []struct {
+		name     string
+		password string
+		score    int
+		pattern  Pattern
+		warning  string
+	}{
+		{name: "empty", password: "", score: 0, warning: "пустой пароль"},
+		{name: "common password", password: "password", score: 0, pattern: PatternCommon, warning: "один из самых распространенных паролей"},
+		{name: "common password with substitutions", password: "P@ssw0rd", score: 0, pattern: PatternCommon},
+		{name: "repeat", password: "aaaaaaaa", score: 0, pattern: PatternRepeat, warning: "повторы вроде aaa легко угадать"},
+		{name: "sequence", password: "abcdef", score: 0, pattern: PatternSequence},
+		{name: "keyboard row", password: "asdfgh12345", score: 1, pattern: PatternKeyboard, warning: "ряды клавиатуры вроде qwerty легко угадать"},
+		{name: "word and year", password: "kitten1987", score: 2, pattern: PatternYear, warning: "годы и даты легко угадать"},
+		{name: "random", password: "xK9#mQ2$vL7!pR4@", score: 4},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			est := Check(tt.password)
+			assert.Equal(t, tt.score, est.Score)
+			if tt.pattern != "" {
+				assert.Contains(t, est.Patterns, tt.pattern)
+			}
+			if tt.warning != "" {
+				assert.Equal(t, tt.warning, est.Warning)
+			}
+		})
+	}
+}
+
+func TestCheck_Uppercase(t *testing.T) {
+	// Заглавная первая буква почти не усложняет подбор, заглавные в середине слова усложняют сильнее
+	lower := Check("monkey").GuessesLog10
+	assert.Less(t, Check("Monkey").GuessesLog10-lower, 0.5)
+	assert.Greater(t, Check("mOnKeY").GuessesLog10, Check("Monkey").GuessesLog10)
+}
+
+func TestCheck_LongPassword(t *testing.T) {
+	// Символы после maxLength не учитываются
+	est := Check(strings.Repeat("x7#Qp", 100))
+	assert.Equal(t, 4, est.Score)
+}
+
+func TestBestSequence(t *testing.T) {
+	runes := []rune("qwerty2024")
+	sequence, _ := bestSequence(runes, findMatches(runes))
+	tokens := make([]string, len(sequence))
+	for i, m := range sequence {
+		tokens[i] = m.Token
+	}
+	assert.Equal(t, []string{"qwerty", "2024"}, tokens)
+}
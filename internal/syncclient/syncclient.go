@@ -0,0 +1,429 @@
+// Package syncclient синхронизирует локальный кэш клиента с сервером: отправляет изменения из очереди,
+// разрешает конфликты с изменениями других устройств и получает с сервера изменения по журналу.
+//
+// Конфликты разрешаются по правилу «последняя запись побеждает» по времени изменения, которое ставит сервер
+// (updated_at), поэтому часы устройств на результат не влияют. Изменение из очереди сервер записывает при
+// синхронизации, то есть позже версии, с которой оно конфликтует, поэтому побеждает изменение, отправленное последним.
+// Перезаписанная версия не теряется, а сохраняется как конфликтная копия с временем ее изменения на сервере.
+// Изменение секрета, удаленного на другом устройстве, создает его заново. Карты не имеют конфликтных копий,
+// поэтому изменение карты, конфликтующее с версией сервера, отклоняется, а версия сервера сохраняется.
+package syncclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ZnNr/GopherVault/internal/localcache"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/go-resty/resty/v2"
+)
+
+// ErrUnavailable означает, что сервер недоступен; неотправленные изменения остаются в очереди
+var ErrUnavailable = errors.New("server is unavailable")
+
+// conflictCopyLayout формат времени в названии конфликтной копии
+const conflictCopyLayout = "2006-01-02 15:04:05"
+
+// Result описывает итог синхронизации
+type Result struct {
+	Sent      int      // Число отправленных изменений из очереди
+	Conflicts []string // Описания разрешенных конфликтов
+	Rejected  []string // Изменения, отклоненные сервером
+	Received  int      // Число изменений, полученных с сервера
+	Seq       int64    // Номер последнего полученного изменения
+}
+
+// Client синхронизирует локальный кэш пользователя с сервером
+type Client struct {
+	baseURL  string
+	userName string
+	http     *resty.Client
+}
+
+// New создает клиент синхронизации для сервера с адресом baseURL, например http://localhost:8080
+func New(baseURL, userName string) *Client {
+	return &Client{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		userName: userName,
+		http:     resty.New().SetAllowGetMethodPayload(true),
+	}
+}
+
+// Sync отправляет на сервер изменения из очереди кэша и применяет к кэшу изменения с сервера.
+// Кэш изменяется в памяти, сохранять его должен вызывающий код - в том числе после ошибки,
+// чтобы отправленные изменения не были отправлены повторно.
+func (c *Client) Sync(cache *localcache.Cache, now time.Time) (Result, error) {
+	var res Result
+	if err := c.push(cache, &res); err != nil {
+		return res, err
+	}
+	if err := c.pull(cache, &res, now); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// pushState состояние отправки очереди. Конфликт с версией сервера разрешается один раз для каждого секрета,
+// а следующие изменения того же секрета из очереди применяются к победившей версии.
+type pushState struct {
+	won map[string]bool // Итог разрешения конфликта: true, если победила локальная версия
+}
+
+// push отправляет изменения из очереди в порядке их постановки
+func (c *Client) push(cache *localcache.Cache, res *Result) error {
+	st := &pushState{won: make(map[string]bool)}
+	for _, req := range cache.Pending() {
+		if err := c.send(req, res, st); err != nil {
+			return err
+		}
+		cache.Dequeue(1)
+		res.Sent++
+	}
+	return nil
+}
+
+// send отправляет одно изменение и разрешает конфликт, если секрет был изменен на сервере
+func (c *Client) send(req localcache.PendingRequest, res *Result, st *pushState) error {
+	key := secretKey(req.Path, req.Body)
+	revision := req.Revision
+	if won, decided := st.won[key]; decided {
+		// Локальное изменение отклонено при разрешении конфликта, следующие изменения секрета тоже не применяются
+		if !won {
+			return nil
+		}
+		// Изменение применяется поверх уже отправленной локальной версии
+		revision = 0
+	}
+
+	resp, err := c.post(req.Path, req.Body, revision)
+	if err != nil {
+		return err
+	}
+	switch resp.StatusCode() {
+	case http.StatusOK:
+		if key != "" {
+			st.won[key] = true
+		}
+		return nil
+	case http.StatusConflict:
+		return c.resolveDuplicate(req, resp, res)
+	case http.StatusNotFound:
+		// Секрета уже нет на сервере: удаление выполнено другим устройством, а изменение конфликтует с удалением
+		if strings.HasPrefix(req.Path, "/delete/") {
+			st.won[key] = true
+			return nil
+		}
+		if strings.HasPrefix(req.Path, "/update/") {
+			return c.resolveUpdate(req, models.RevisionConflict{}, res, st)
+		}
+		res.Rejected = append(res.Rejected, fmt.Sprintf("%s от %s: %s: %s",
+			req.Path, req.QueuedAt.Format(conflictCopyLayout), resp.Status(), resp.String()))
+		return nil
+	case http.StatusPreconditionFailed:
+		var conflict models.RevisionConflict
+		if err = json.Unmarshal(resp.Body(), &conflict); err != nil {
+			return fmt.Errorf("некорректный ответ сервера на %s: %w", req.Path, err)
+		}
+		if strings.HasPrefix(req.Path, "/delete/") {
+			return c.resolveDelete(req, conflict, res, st)
+		}
+		return c.resolveUpdate(req, conflict, res, st)
+	default:
+		res.Rejected = append(res.Rejected, fmt.Sprintf("%s от %s: %s: %s",
+			req.Path, req.QueuedAt.Format(conflictCopyLayout), resp.Status(), resp.String()))
+		return nil
+	}
+}
+
+// resolveDuplicate сохраняет созданный без связи секрет как конфликтную копию, если на сервере уже есть секрет с таким ключом
+func (c *Client) resolveDuplicate(req localcache.PendingRequest, resp *resty.Response, res *Result) error {
+	secretType, ok := pathType(req.Path)
+	if !ok || !strings.HasPrefix(req.Path, "/save/") || secretType == models.SecretCard {
+		res.Rejected = append(res.Rejected, fmt.Sprintf("%s от %s: %s: %s",
+			req.Path, req.QueuedAt.Format(conflictCopyLayout), resp.Status(), resp.String()))
+		return nil
+	}
+	copied, name, err := conflictCopy(secretType, req.Body, req.QueuedAt)
+	if err != nil {
+		return err
+	}
+	if err = c.saveCopy(secretType, copied); err != nil {
+		return err
+	}
+	res.Conflicts = append(res.Conflicts, fmt.Sprintf("секрет с таким ключом уже создан на другом устройстве, локальная версия сохранена как %q", name))
+	return nil
+}
+
+// resolveUpdate разрешает конфликт изменения секрета, измененного или удаленного на сервере
+func (c *Client) resolveUpdate(req localcache.PendingRequest, conflict models.RevisionConflict, res *Result, st *pushState) error {
+	secretType, ok := pathType(req.Path)
+	if !ok {
+		return fmt.Errorf("неизвестный тип секрета в запросе %s", req.Path)
+	}
+	key := secretKey(req.Path, req.Body)
+
+	// Секрет удален на сервере: изменение записывается позже удаления, и секрет создается заново
+	if conflict.Current == nil {
+		if err := c.saveCopy(secretType, req.Body); err != nil {
+			return err
+		}
+		st.won[key] = true
+		res.Conflicts = append(res.Conflicts, fmt.Sprintf("%s: секрет удален на другом устройстве и создан заново с локальными изменениями", req.Path))
+		return nil
+	}
+
+	name, ok, err := c.overwrite(req, conflict, res, st)
+	if err != nil || !ok {
+		return err
+	}
+	res.Conflicts = append(res.Conflicts, fmt.Sprintf("%s: локальное изменение записано последним, версия с другого устройства сохранена как %q", req.Path, name))
+	return nil
+}
+
+// resolveDelete разрешает конфликт удаления секрета, измененного на сервере после ревизии, известной клиенту.
+// Удаление записывается последним, а измененная версия сохраняется как конфликтная копия.
+func (c *Client) resolveDelete(req localcache.PendingRequest, conflict models.RevisionConflict, res *Result, st *pushState) error {
+	key := secretKey(req.Path, req.Body)
+	// Секрет уже удален на другом устройстве
+	if conflict.Current == nil {
+		st.won[key] = true
+		return nil
+	}
+	name, ok, err := c.overwrite(req, conflict, res, st)
+	if err != nil || !ok {
+		return err
+	}
+	res.Conflicts = append(res.Conflicts, fmt.Sprintf("%s: секрет удален, версия с другого устройства сохранена как %q", req.Path, name))
+	return nil
+}
+
+// overwrite сохраняет версию секрета с сервера как конфликтную копию с временем ее изменения на сервере
+// и повторяет запрос req поверх этой версии. Возвращает название копии. Если копию сохранить нельзя,
+// запрос отклоняется, версия сервера остается без изменений и возвращается false.
+func (c *Client) overwrite(req localcache.PendingRequest, conflict models.RevisionConflict, res *Result, st *pushState) (string, bool, error) {
+	secretType, _ := pathType(req.Path)
+	key := secretKey(req.Path, req.Body)
+	if secretType == models.SecretCard {
+		st.won[key] = false
+		res.Rejected = append(res.Rejected, fmt.Sprintf("%s от %s: карта изменена на другом устройстве, конфликтные копии карт не поддерживаются",
+			req.Path, req.QueuedAt.Format(conflictCopyLayout)))
+		return "", false, nil
+	}
+
+	serverTime, err := updatedAt(conflict.Current)
+	if err != nil {
+		return "", false, err
+	}
+	copied, name, err := conflictCopy(secretType, conflict.Current, serverTime)
+	if err != nil {
+		return "", false, err
+	}
+	if err = c.saveCopy(secretType, copied); err != nil {
+		return "", false, err
+	}
+	resp, err := c.post(req.Path, req.Body, conflict.Revision)
+	if err != nil {
+		return "", false, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return "", false, fmt.Errorf("ошибка при повторной отправке %s: %s: %s", req.Path, resp.Status(), resp.String())
+	}
+	st.won[key] = true
+	return name, true, nil
+}
+
+// saveCopy создает секрет на сервере; уже существующая копия от прерванной синхронизации считается созданной
+func (c *Client) saveCopy(secretType models.SecretType, body []byte) error {
+	resp, err := c.post("/save/"+string(secretType), body, 0)
+	if err != nil {
+		return err
+	}
+	if code := resp.StatusCode(); code != http.StatusOK && code != http.StatusConflict {
+		return fmt.Errorf("ошибка при сохранении конфликтной копии: %s: %s", resp.Status(), resp.String())
+	}
+	return nil
+}
+
+// pull получает изменения с номерами больше известного кэшу и применяет их
+func (c *Client) pull(cache *localcache.Cache, res *Result, now time.Time) error {
+	resp, err := c.http.R().
+		SetHeader("Content-type", "application/json").
+		SetBody(models.UserBase{UserName: c.userName}).
+		Get(fmt.Sprintf("%s/sync?since=%d", c.baseURL, cache.Seq()))
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrUnavailable, err.Error())
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("ошибка при получении изменений: %s: %s", resp.Status(), resp.String())
+	}
+	var changes models.SyncResponse
+	if err = json.Unmarshal(resp.Body(), &changes); err != nil {
+		return fmt.Errorf("некорректный ответ сервера на /sync: %w", err)
+	}
+	if changes.Reset {
+		cache.Reset()
+	}
+	for _, change := range changes.Changes {
+		cache.Apply(change)
+	}
+	cache.MarkSynced(changes.Seq, now)
+	res.Received = len(changes.Changes)
+	res.Seq = changes.Seq
+	return nil
+}
+
+// post отправляет запрос на изменение секретов с ожидаемой ревизией в заголовке If-Match
+func (c *Client) post(path string, body []byte, revision int64) (*resty.Response, error) {
+	req := c.http.R().
+		SetHeader("Content-type", "application/json").
+		SetBody(body)
+	if revision != 0 {
+		req.SetHeader("If-Match", models.ETag(revision))
+	}
+	resp, err := req.Post(c.baseURL + path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnavailable, err.Error())
+	}
+	return resp, nil
+}
+
+// BaseRevision возвращает ревизию секрета из кэша, которую изменяет или удаляет запрос, поставленный в очередь.
+// Если запрос не относится к одному известному кэшу секрету, возвращается 0 и ревизия не проверяется.
+func BaseRevision(cache *localcache.Cache, path string, body []byte) int64 {
+	if !strings.HasPrefix(path, "/update/") && !strings.HasPrefix(path, "/delete/") {
+		return 0
+	}
+	var revisions []int64
+	switch secretType, _ := pathType(path); secretType {
+	case models.SecretNote:
+		var note models.Note
+		if json.Unmarshal(body, &note) != nil || note.Title == nil {
+			return 0
+		}
+		for _, item := range cache.Notes(models.Note{Title: note.Title}) {
+			revisions = append(revisions, item.Revision)
+		}
+	case models.SecretCredentials:
+		var creds models.Credentials
+		if json.Unmarshal(body, &creds) != nil || creds.Login == nil {
+			return 0
+		}
+		if creds.Site == nil {
+			creds.Site = new(string)
+		}
+		for _, item := range cache.Credentials(models.Credentials{Login: creds.Login}) {
+			if models.ValueOrEmpty(item.Site) == *creds.Site {
+				revisions = append(revisions, item.Revision)
+			}
+		}
+	case models.SecretCard:
+		var card models.Card
+		if json.Unmarshal(body, &card) != nil || card.Number == nil {
+			return 0
+		}
+		for _, item := range cache.Cards(models.Card{Number: card.Number}) {
+			revisions = append(revisions, item.Revision)
+		}
+	}
+	if len(revisions) != 1 {
+		return 0
+	}
+	return revisions[0]
+}
+
+// pathType возвращает тип секрета по пути эндпоинта, например /update/note
+func pathType(path string) (models.SecretType, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 2 {
+		return "", false
+	}
+	switch secretType := models.SecretType(parts[1]); secretType {
+	case models.SecretCredentials, models.SecretNote, models.SecretCard:
+		return secretType, true
+	default:
+		return "", false
+	}
+}
+
+// secretKey возвращает ключ секрета, который изменяет или удаляет запрос, например note:список.
+// Для запросов, не относящихся к одному секрету, возвращается пустая строка.
+func secretKey(path string, body []byte) string {
+	if !strings.HasPrefix(path, "/update/") && !strings.HasPrefix(path, "/delete/") {
+		return ""
+	}
+	switch secretType, _ := pathType(path); secretType {
+	case models.SecretNote:
+		var note models.Note
+		if json.Unmarshal(body, &note) != nil || note.Title == nil {
+			return ""
+		}
+		return string(secretType) + ":" + *note.Title
+	case models.SecretCredentials:
+		var creds models.Credentials
+		if json.Unmarshal(body, &creds) != nil || creds.Login == nil {
+			return ""
+		}
+		return string(secretType) + ":" + *creds.Login + "@" + models.ValueOrEmpty(creds.Site)
+	case models.SecretCard:
+		var card models.Card
+		if json.Unmarshal(body, &card) != nil || card.Number == nil {
+			return ""
+		}
+		return string(secretType) + ":" + *card.Number
+	default:
+		return ""
+	}
+}
+
+// conflictCopy возвращает тело запроса на сохранение копии секрета под новым ключом и название копии.
+// Ключ копии зависит только от исходного ключа и времени изменения, поэтому повторная синхронизация не создает дублей.
+func conflictCopy(secretType models.SecretType, body []byte, changedAt time.Time) ([]byte, string, error) {
+	suffix := fmt.Sprintf(" (конфликт %s)", changedAt.UTC().Format(conflictCopyLayout))
+	var (
+		copied interface{}
+		name   string
+	)
+	switch secretType {
+	case models.SecretNote:
+		var note models.Note
+		if err := json.Unmarshal(body, &note); err != nil {
+			return nil, "", fmt.Errorf("ошибка при разборе заметки: %w", err)
+		}
+		name = models.ValueOrEmpty(note.Title) + suffix
+		note.Title, note.Audit = &name, models.Audit{}
+		copied = note
+	case models.SecretCredentials:
+		// Учетные данные уникальны по логину и сайту, поэтому копия сохраняется с измененным сайтом
+		var creds models.Credentials
+		if err := json.Unmarshal(body, &creds); err != nil {
+			return nil, "", fmt.Errorf("ошибка при разборе учетных данных: %w", err)
+		}
+		site := strings.TrimSpace(models.ValueOrEmpty(creds.Site) + suffix)
+		creds.Site, creds.Audit = &site, models.Audit{}
+		name = models.ValueOrEmpty(creds.Login) + " @ " + site
+		copied = creds
+	default:
+		return nil, "", fmt.Errorf("конфликтные копии секретов типа %q не поддерживаются", secretType)
+	}
+	data, err := json.Marshal(copied)
+	if err != nil {
+		return nil, "", fmt.Errorf("ошибка при маршалинге конфликтной копии: %w", err)
+	}
+	return data, name, nil
+}
+
+// updatedAt возвращает время последнего изменения секрета из ответа сервера
+func updatedAt(current json.RawMessage) (time.Time, error) {
+	var audit models.Audit
+	if err := json.Unmarshal(current, &audit); err != nil {
+		return time.Time{}, fmt.Errorf("некорректная версия секрета в ответе сервера: %w", err)
+	}
+	if audit.UpdatedAt == nil {
+		return time.Time{}, nil
+	}
+	return *audit.UpdatedAt, nil
+}
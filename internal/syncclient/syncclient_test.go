@@ -0,0 +1,464 @@
+package syncclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ZnNr/GopherVault/internal/database"
+	"github.com/ZnNr/GopherVault/internal/localcache"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/passgen"
+	"github.com/ZnNr/GopherVault/internal/router"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const userName = "arya"
+
+// memStorage хранит заметки в памяти и ведет журнал изменений так же, как триггеры базы данных.
+// Остальные методы хранилища в тестах синхронизации не вызываются.
+type memStorage struct {
+	models.Storage
+
+	mu    sync.Mutex
+	now   time.Time
+	seq   int64
+	id    int64
+	notes map[int64]models.Note
+	// deleted последняя ревизия удаленной заметки: заметка, созданная заново, продолжает ее ревизии
+	deleted map[string]int64
+	log     []models.Change
+	// eventsRequests число запросов событий; позволяет дождаться подключения к потоку
+	eventsRequests int
+}
+
+func newMemStorage() *memStorage {
+	return &memStorage{notes: make(map[int64]models.Note), deleted: make(map[string]int64)}
+}
+
+// setNow задает время, которым хранилище отмечает следующие изменения
+func (s *memStorage) setNow(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+func (s *memStorage) record(note models.Note, action models.ChangeAction) {
+	s.seq++
+	s.log = append(s.log, models.Change{
+		Seq: s.seq, Type: models.SecretNote, SecretID: *note.ID, Action: action, Revision: note.Revision, ChangedAt: s.now,
+	})
+}
+
+func (s *memStorage) findNote(title *string) (models.Note, bool) {
+	for _, note := range s.notes {
+		if title != nil && *note.Title == *title {
+			return note, true
+		}
+	}
+	return models.Note{}, false
+}
+
+func (s *memStorage) Register(context.Context, string, string) error {
+	return nil
+}
+
+func (s *memStorage) SaveNote(_ context.Context, note models.Note) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.findNote(note.Title); ok {
+		return &database.ConflictError{Constraint: "notes_user_title_key"}
+	}
+	s.id++
+	id, now := s.id, s.now
+	note.Audit = models.Audit{ID: &id, CreatedAt: &now, UpdatedAt: &now, Revision: s.deleted[*note.Title] + 1}
+	s.notes[id] = note
+	s.record(note, models.ChangeCreated)
+	return nil
+}
+
+func (s *memStorage) GetNotes(_ context.Context, noteRequest models.Note, _ models.ListOptions) ([]models.Note, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var notes []models.Note
+	for _, note := range s.notes {
+		if noteRequest.Title == nil || *note.Title == *noteRequest.Title {
+			notes = append(notes, note)
+		}
+	}
+	if len(notes) == 0 {
+		return nil, "", database.ErrNoData
+	}
+	return notes, "", nil
+}
+
+func (s *memStorage) UpdateNote(_ context.Context, note models.Note) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.findNote(note.Title)
+	if !ok || (note.Revision != 0 && note.Revision != current.Revision) {
+		if note.Revision != 0 {
+			return database.ErrRevisionMismatch
+		}
+		return database.ErrNoData
+	}
+	now := s.now
+	current.Content, current.Metadata, current.Fields = note.Content, note.Metadata, note.Fields
+	current.UpdatedAt = &now
+	current.Revision++
+	s.notes[*current.ID] = current
+	s.record(current, models.ChangeUpdated)
+	return nil
+}
+
+func (s *memStorage) DeleteNotes(_ context.Context, noteRequest models.Note) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.findNote(noteRequest.Title)
+	if !ok && noteRequest.Revision != 0 {
+		return database.ErrNoData
+	}
+	if !ok || (noteRequest.Revision != 0 && noteRequest.Revision != current.Revision) {
+		if noteRequest.Revision != 0 {
+			return database.ErrRevisionMismatch
+		}
+		return nil
+	}
+	delete(s.notes, *current.ID)
+	s.deleted[*current.Title] = current.Revision
+	s.record(current, models.ChangeDeleted)
+	return nil
+}
+
+func (s *memStorage) GetChanges(_ context.Context, syncRequest models.SyncRequest) (models.SyncResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := models.SyncResponse{Seq: s.seq, Changes: []models.Change{}}
+	since := syncRequest.Since
+	if since > s.seq {
+		res.Reset, since = true, 0
+	}
+	latest := make(map[int64]models.Change)
+	for _, change := range s.log {
+		if change.Seq > since {
+			latest[change.SecretID] = change
+		}
+	}
+	for _, change := range latest {
+		if note, ok := s.notes[change.SecretID]; ok && change.Action != models.ChangeDeleted {
+			change.Note = &note
+		}
+		res.Changes = append(res.Changes, change)
+	}
+	sort.Slice(res.Changes, func(i, j int) bool { return res.Changes[i].Seq < res.Changes[j].Seq })
+	return res, nil
+}
+
+func (s *memStorage) GetEvents(_ context.Context, eventsRequest models.EventsRequest) (models.EventsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.eventsRequests++
+	res := models.EventsResponse{Seq: s.seq, Events: []models.Event{}}
+	if eventsRequest.After == nil {
+		return res, nil
+	}
+	if *eventsRequest.After > s.seq {
+		res.Reset = true
+		return res, nil
+	}
+	for _, change := range s.log {
+		if change.Seq > *eventsRequest.After {
+			res.Events = append(res.Events, models.Event{Seq: change.Seq, Type: change.Type, SecretID: change.SecretID,
+				Action: change.Action, Revision: change.Revision, ChangedAt: change.ChangedAt})
+		}
+	}
+	return res, nil
+}
+
+// device описывает клиент на отдельном устройстве со своим локальным кэшем
+type device struct {
+	t      *testing.T
+	cache  *localcache.Cache
+	client *Client
+	url    string
+}
+
+func newDevice(t *testing.T, url string) *device {
+	cache, err := localcache.Open(t.TempDir(), userName, "vault-key")
+	require.NoError(t, err)
+	return &device{t: t, cache: cache, client: New(url, userName), url: url}
+}
+
+// post выполняет изменение при доступном сервере
+func (d *device) post(path string, note models.Note) {
+	note.UserName = userName
+	body, _ := json.Marshal(note)
+	resp, err := resty.New().R().SetHeader("Content-type", "application/json").SetBody(body).Post(d.url + path)
+	require.NoError(d.t, err)
+	require.Equal(d.t, http.StatusOK, resp.StatusCode(), resp.String())
+}
+
+// queue ставит изменение в очередь так, как это делает клиент без связи с сервером
+func (d *device) queue(path string, note models.Note, at time.Time) {
+	note.UserName = userName
+	body, _ := json.Marshal(note)
+	d.cache.Queue(path, body, BaseRevision(d.cache, path, body), at)
+}
+
+func (d *device) sync() Result {
+	res, err := d.client.Sync(d.cache, time.Now())
+	require.NoError(d.t, err)
+	return res
+}
+
+// contents возвращает содержимое заметок кэша по заголовкам
+func (d *device) contents() map[string]string {
+	res := make(map[string]string)
+	for _, note := range d.cache.Notes(models.Note{}) {
+		res[*note.Title] = *note.Content
+	}
+	return res
+}
+
+func note(title, content string) models.Note {
+	return models.Note{Title: &title, Content: &content}
+}
+
+func newServer(t *testing.T) (*memStorage, string) {
+	storage := newMemStorage()
+	srv := httptest.NewServer(router.New(storage, zap.NewNop().Sugar(), passgen.DefaultPolicy))
+	t.Cleanup(srv.Close)
+
+	_, err := resty.New().R().
+		SetHeader("Content-type", "application/json").
+		SetBody(fmt.Sprintf(`{"login": %q, "password": "needle"}`, userName)).
+		Post(srv.URL + "/auth/register")
+	require.NoError(t, err)
+	return storage, srv.URL
+}
+
+func TestSync_PropagatesChangesAndTombstones(t *testing.T) {
+	storage, url := newServer(t)
+	storage.setNow(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	laptop, phone := newDevice(t, url), newDevice(t, url)
+
+	laptop.post("/save/note", note("list", "milk"))
+	laptop.post("/save/note", note("ideas", "dragons"))
+	res := phone.sync()
+	assert.Equal(t, 2, res.Received)
+	assert.Equal(t, map[string]string{"list": "milk", "ideas": "dragons"}, phone.contents())
+
+	// Повторная синхронизация без изменений ничего не получает
+	assert.Equal(t, 0, phone.sync().Received)
+
+	laptop.post("/update/note", note("list", "milk, bread"))
+	laptop.post("/delete/note", models.Note{Title: note("ideas", "").Title})
+	res = phone.sync()
+	assert.Equal(t, 2, res.Received)
+	assert.Equal(t, map[string]string{"list": "milk, bread"}, phone.contents())
+
+	// Запрос с номером больше журнала сервера сбрасывает кэш и получает все секреты заново
+	phone.cache.MarkSynced(100, time.Now())
+	phone.sync()
+	assert.Equal(t, map[string]string{"list": "milk, bread"}, phone.contents())
+	assert.Equal(t, storage.seq, phone.cache.Seq())
+}
+
+func TestSync_LastWriterWinsWithConflictCopies(t *testing.T) {
+	storage, url := newServer(t)
+	storage.setNow(time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC))
+	laptop, phone := newDevice(t, url), newDevice(t, url)
+
+	laptop.post("/save/note", note("list", "milk"))
+	laptop.sync()
+	phone.sync()
+
+	// Оба устройства без связи меняют одну заметку. Телефон синхронизируется последним, поэтому его версия
+	// побеждает, хотя по часам ноутбука изменение ноутбука сделано позже
+	laptop.queue("/update/note", note("list", "milk, laptop"), time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	phone.queue("/update/note", note("list", "milk, phone"), time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	phone.queue("/update/note", note("list", "milk, phone, tea"), time.Date(2024, 5, 1, 10, 1, 0, 0, time.UTC))
+
+	storage.setNow(time.Date(2024, 5, 1, 10, 2, 0, 0, time.UTC))
+	res := laptop.sync()
+	assert.Equal(t, 1, res.Sent)
+	assert.Empty(t, res.Conflicts)
+
+	// Версия ноутбука сохраняется как копия с временем ее записи на сервере,
+	// а второе изменение телефона применяется поверх первого без конфликта с самим собой
+	storage.setNow(time.Date(2024, 5, 1, 10, 20, 0, 0, time.UTC))
+	res = phone.sync()
+	assert.Equal(t, 2, res.Sent)
+	assert.Len(t, res.Conflicts, 1)
+
+	expected := map[string]string{
+		"list": "milk, phone, tea",
+		"list (конфликт 2024-05-01 10:02:00)": "milk, laptop",
+	}
+	assert.Equal(t, expected, phone.contents())
+
+	// После синхронизации оба устройства видят одинаковые данные
+	laptop.sync()
+	assert.Equal(t, expected, laptop.contents())
+	assert.Empty(t, laptop.cache.Pending())
+	assert.Empty(t, phone.cache.Pending())
+}
+
+func TestSync_ConflictResolutionIgnoresClockSkew(t *testing.T) {
+	behind := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	ahead := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	// run выполняет одинаковые изменения без связи на трех устройствах с заданными часами ноутбука и телефона
+	// и синхронизирует устройства в заданном порядке.
+	// Возвращает содержимое заметок без конфликтных копий и содержимое конфликтных копий.
+	run := func(order []string, laptopClock, phoneClock time.Time) (map[string]string, []string) {
+		storage, url := newServer(t)
+		storage.setNow(time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC))
+		devices := map[string]*device{"laptop": newDevice(t, url), "phone": newDevice(t, url), "tablet": newDevice(t, url)}
+		devices["laptop"].post("/save/note", note("list", "milk"))
+		devices["laptop"].post("/save/note", note("plan", "rest"))
+		for _, d := range devices {
+			d.sync()
+		}
+
+		devices["laptop"].queue("/update/note", note("list", "milk, laptop"), laptopClock)
+		devices["phone"].queue("/update/note", note("list", "milk, phone"), phoneClock)
+		devices["tablet"].queue("/update/note", note("plan", "tablet plan"), laptopClock)
+		devices["tablet"].queue("/update/note", note("plan", "tablet plan, v2"), laptopClock.Add(time.Minute))
+		devices["laptop"].queue("/update/note", note("plan", "laptop plan"), laptopClock)
+		devices["phone"].queue("/delete/note", models.Note{Title: note("plan", "").Title}, phoneClock)
+
+		for i, name := range order {
+			storage.setNow(time.Date(2024, 5, 1, 11, i, 0, 0, time.UTC))
+			devices[name].sync()
+		}
+		for _, d := range devices {
+			d.sync()
+		}
+
+		main := make(map[string]string)
+		var copies []string
+		for title, content := range devices["laptop"].contents() {
+			if title == "list" || title == "plan" {
+				main[title] = content
+			} else {
+				copies = append(copies, content)
+			}
+		}
+		sort.Strings(copies)
+		for _, d := range devices {
+			assert.Equal(t, devices["laptop"].contents(), d.contents())
+		}
+		return main, copies
+	}
+
+	for _, tt := range []struct {
+		order          []string
+		expectedMain   map[string]string
+		expectedCopies []string
+	}{
+		{
+			// Телефон записывает list после ноутбука и удаляет plan после изменения ноутбука,
+			// а планшет создает удаленную plan заново
+			order:          []string{"laptop", "phone", "tablet"},
+			expectedMain:   map[string]string{"list": "milk, phone", "plan": "tablet plan, v2"},
+			expectedCopies: []string{"laptop plan", "milk, laptop"},
+		},
+		{
+			// Ноутбук записывает list последним и создает заново plan, удаленную телефоном после изменений планшета
+			order:          []string{"tablet", "phone", "laptop"},
+			expectedMain:   map[string]string{"list": "milk, laptop", "plan": "laptop plan"},
+			expectedCopies: []string{"milk, phone", "tablet plan, v2"},
+		},
+	} {
+		// Результат зависит только от порядка записи на сервер: часы устройств, отстающие или спешащие, на него не влияют
+		for _, clocks := range [][2]time.Time{{behind, ahead}, {ahead, behind}} {
+			main, copies := run(tt.order, clocks[0], clocks[1])
+			assert.Equal(t, tt.expectedMain, main, "порядок синхронизации %v", tt.order)
+			assert.Equal(t, tt.expectedCopies, copies, "порядок синхронизации %v", tt.order)
+		}
+	}
+}
+
+func TestSync_DeleteAndDuplicateConflicts(t *testing.T) {
+	storage, url := newServer(t)
+	storage.setNow(time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC))
+	laptop, phone := newDevice(t, url), newDevice(t, url)
+
+	laptop.post("/save/note", note("list", "milk"))
+	laptop.sync()
+	phone.sync()
+
+	// Ноутбук удаляет заметку без связи, а телефон меняет ее на сервере: удаление записывается последним,
+	// а версия телефона сохраняется как копия
+	laptop.queue("/delete/note", models.Note{Title: note("list", "").Title}, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	// Обе стороны создают заметку с одинаковым заголовком
+	laptop.queue("/save/note", note("todo", "laptop todo"), time.Date(2024, 5, 1, 10, 1, 0, 0, time.UTC))
+
+	storage.setNow(time.Date(2024, 5, 1, 10, 5, 0, 0, time.UTC))
+	phone.post("/update/note", note("list", "milk, eggs"))
+	phone.post("/save/note", note("todo", "phone todo"))
+
+	res := laptop.sync()
+	assert.Equal(t, 2, res.Sent)
+	assert.Len(t, res.Conflicts, 2)
+	assert.Empty(t, res.Rejected)
+
+	expected := map[string]string{
+		"list (конфликт 2024-05-01 10:05:00)": "milk, eggs",
+		"todo": "phone todo",
+		"todo (конфликт 2024-05-01 10:01:00)": "laptop todo",
+	}
+	assert.Equal(t, expected, laptop.contents())
+	phone.sync()
+	assert.Equal(t, expected, phone.contents())
+
+	// Изменение заметки, удаленной на другом устройстве, создает ее заново
+	phone.queue("/update/note", note("todo", "phone todo, tea"), time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC))
+	laptop.post("/delete/note", models.Note{Title: note("todo", "").Title})
+	phone.sync()
+	laptop.sync()
+	assert.Equal(t, "phone todo, tea", laptop.contents()["todo"])
+	assert.Equal(t, laptop.contents(), phone.contents())
+}
+
+func TestSync_UpdateOfMissingSecretIsNotDropped(t *testing.T) {
+	storage, url := newServer(t)
+	storage.setNow(time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC))
+	laptop, phone := newDevice(t, url), newDevice(t, url)
+
+	// Телефон не успел получить заметку и меняет ее без ожидаемой ревизии, а ноутбук тем временем ее удаляет
+	laptop.post("/save/note", note("list", "milk"))
+	phone.queue("/update/note", note("list", "milk, tea"), time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	laptop.post("/delete/note", models.Note{Title: note("list", "").Title})
+
+	res := phone.sync()
+	assert.Equal(t, 1, res.Sent)
+	assert.Len(t, res.Conflicts, 1)
+	assert.Empty(t, res.Rejected)
+	assert.Equal(t, map[string]string{"list": "milk, tea"}, phone.contents())
+	laptop.sync()
+	assert.Equal(t, phone.contents(), laptop.contents())
+}
+
+func TestSync_ServerUnavailableKeepsQueue(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	d := newDevice(t, url)
+	d.queue("/save/note", note("list", "milk"), time.Now())
+	res, err := d.client.Sync(d.cache, time.Now())
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Equal(t, 0, res.Sent)
+	assert.Len(t, d.cache.Pending(), 1)
+}
@@ -0,0 +1,79 @@
+package syncclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// Watch подключается к потоку событий сервера и вызывает handle для каждого полученного события,
+// пока сервер не закроет поток, не будет отменен ctx или handle не вернет ошибку.
+// after - номер последнего полученного события, с которого возобновляется поток; nil - получать только новые события.
+// Возвращает номер последнего обработанного события, который передается в after при переподключении.
+func (c *Client) Watch(ctx context.Context, after *int64, handle func(models.Event) error) (*int64, error) {
+	req := c.http.R().
+		SetContext(ctx).
+		SetDoNotParseResponse(true).
+		SetHeader("Content-type", "application/json").
+		SetHeader("Accept", "text/event-stream").
+		SetBody(models.UserBase{UserName: c.userName})
+	if after != nil {
+		req.SetHeader("Last-Event-ID", strconv.FormatInt(*after, 10))
+	}
+	resp, err := req.Get(c.baseURL + "/events")
+	if err != nil {
+		if ctx.Err() != nil {
+			return after, ctx.Err()
+		}
+		return after, fmt.Errorf("%w: %s", ErrUnavailable, err.Error())
+	}
+	body := resp.RawBody()
+	defer body.Close()
+	if resp.StatusCode() != http.StatusOK {
+		message := new(strings.Builder)
+		_, _ = bufio.NewReader(body).WriteTo(message)
+		return after, fmt.Errorf("ошибка при подключении к потоку событий: %s: %s", resp.Status(), strings.TrimSpace(message.String()))
+	}
+
+	// Разбираем поток в формате Server-Sent Events: поля события завершаются пустой строкой
+	var data strings.Builder
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data.Len() == 0 {
+				continue
+			}
+			var event models.Event
+			if err = json.Unmarshal([]byte(data.String()), &event); err != nil {
+				return after, fmt.Errorf("некорректное событие от сервера: %w", err)
+			}
+			data.Reset()
+			if err = handle(event); err != nil {
+				return after, err
+			}
+			seq := event.Seq
+			after = &seq
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+		// Комментарии и поля id, event и retry не используются: номер и вид изменения передаются в данных события
+	}
+	if ctx.Err() != nil {
+		return after, ctx.Err()
+	}
+	if err = scanner.Err(); err != nil {
+		return after, fmt.Errorf("%w: %s", ErrUnavailable, err.Error())
+	}
+	return after, nil
+}
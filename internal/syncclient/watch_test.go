@@ -0,0 +1,112 @@
+package syncclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collect получает из потока count событий, начиная после номера after
+func collect(t *testing.T, client *Client, after *int64, count int) ([]models.Event, *int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var events []models.Event
+	last, err := client.Watch(ctx, after, func(event models.Event) error {
+		events = append(events, event)
+		if len(events) == count {
+			cancel()
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	return events, last
+}
+
+func TestWatch_ResumesAfterLastEventID(t *testing.T) {
+	storage, url := newServer(t)
+	storage.setNow(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	laptop, phone := newDevice(t, url), newDevice(t, url)
+
+	laptop.post("/save/note", note("list", "milk"))
+	laptop.post("/save/note", note("ideas", "dragons"))
+	events, last := collect(t, phone.client, new(int64), 2)
+	assert.Equal(t, []models.Event{
+		{Seq: 1, Type: models.SecretNote, SecretID: 1, Action: models.ChangeCreated, Revision: 1, ChangedAt: storage.now},
+		{Seq: 2, Type: models.SecretNote, SecretID: 2, Action: models.ChangeCreated, Revision: 1, ChangedAt: storage.now},
+	}, events)
+	require.NotNil(t, last)
+	assert.Equal(t, int64(2), *last)
+
+	// После переподключения приходят только события, пропущенные клиентом
+	laptop.post("/update/note", note("list", "milk, bread"))
+	laptop.post("/delete/note", models.Note{Title: note("ideas", "").Title})
+	events, last = collect(t, phone.client, last, 2)
+	assert.Equal(t, []models.ChangeAction{models.ChangeUpdated, models.ChangeDeleted}, []models.ChangeAction{events[0].Action, events[1].Action})
+	assert.Equal(t, int64(4), *last)
+}
+
+func TestWatch_NewStreamReceivesOnlyNewEvents(t *testing.T) {
+	storage, url := newServer(t)
+	laptop, phone := newDevice(t, url), newDevice(t, url)
+	laptop.post("/save/note", note("list", "milk"))
+
+	// Изменение выполняется после подключения к потоку
+	go func() {
+		for {
+			time.Sleep(50 * time.Millisecond)
+			storage.mu.Lock()
+			connected := storage.eventsRequests > 0
+			storage.mu.Unlock()
+			if connected {
+				laptop.post("/update/note", note("list", "milk, bread"))
+				return
+			}
+		}
+	}()
+	events, last := collect(t, phone.client, nil, 1)
+	assert.Equal(t, int64(2), events[0].Seq)
+	assert.Equal(t, models.ChangeUpdated, events[0].Action)
+	assert.Equal(t, int64(2), *last)
+}
+
+func TestWatch_ResetWhenEventIDIsAheadOfLog(t *testing.T) {
+	_, url := newServer(t)
+	laptop, phone := newDevice(t, url), newDevice(t, url)
+	laptop.post("/save/note", note("list", "milk"))
+
+	ahead := int64(100)
+	events, last := collect(t, phone.client, &ahead, 1)
+	assert.Equal(t, models.ChangeReset, events[0].Action)
+	assert.Equal(t, int64(1), *last)
+}
+
+func TestWatch_ServerUnavailable(t *testing.T) {
+	client := New("http://127.0.0.1:1", userName)
+	after := int64(3)
+	last, err := client.Watch(context.Background(), &after, func(models.Event) error { return nil })
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Equal(t, &after, last)
+}
+
+func TestWatch_HandlerErrorStopsStream(t *testing.T) {
+	_, url := newServer(t)
+	laptop, phone := newDevice(t, url), newDevice(t, url)
+	laptop.post("/save/note", note("list", "milk"))
+	laptop.post("/save/note", note("ideas", "dragons"))
+
+	// Событие, которое не удалось обработать, не считается полученным
+	errStop := errors.New("stop")
+	last, err := phone.client.Watch(context.Background(), new(int64), func(event models.Event) error {
+		if event.Seq == 2 {
+			return errStop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, int64(1), *last)
+}
@@ -0,0 +1,136 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Значения параметров по умолчанию согласно формату Key Uri
+const (
+	defaultDigits    = 6
+	defaultPeriod    = 30
+	defaultAlgorithm = "SHA1"
+)
+
+// ErrInvalidURI означает, что адрес не является корректным otpauth://totp адресом
+var ErrInvalidURI = errors.New("invalid otpauth uri")
+
+// Key содержит параметры секрета TOTP
+type Key struct {
+	Issuer    string
+	Account   string
+	Secret    []byte
+	Digits    int
+	Period    int
+	Algorithm string
+}
+
+// Parse разбирает адрес вида otpauth://totp/Issuer:account?secret=...&digits=6&period=30&algorithm=SHA1
+func Parse(uri string) (*Key, error) {
+	u, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidURI, err.Error())
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" {
+		return nil, fmt.Errorf("%w: expected otpauth://totp scheme", ErrInvalidURI)
+	}
+
+	q := u.Query()
+	key := &Key{
+		Issuer:    q.Get("issuer"),
+		Digits:    defaultDigits,
+		Period:    defaultPeriod,
+		Algorithm: defaultAlgorithm,
+	}
+
+	// Метка имеет вид "Issuer:account" или "account"
+	label := strings.TrimPrefix(u.Path, "/")
+	if issuer, account, ok := strings.Cut(label, ":"); ok {
+		key.Account = strings.TrimSpace(account)
+		if key.Issuer == "" {
+			key.Issuer = issuer
+		}
+	} else {
+		key.Account = label
+	}
+
+	secret := strings.ToUpper(strings.ReplaceAll(q.Get("secret"), " ", ""))
+	if secret == "" {
+		return nil, fmt.Errorf("%w: secret is empty", ErrInvalidURI)
+	}
+	key.Secret, err = base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(secret, "="))
+	if err != nil {
+		return nil, fmt.Errorf("%w: secret is not base32: %s", ErrInvalidURI, err.Error())
+	}
+
+	if v := q.Get("digits"); v != "" {
+		if key.Digits, err = strconv.Atoi(v); err != nil || key.Digits < 6 || key.Digits > 10 {
+			return nil, fmt.Errorf("%w: digits must be between 6 and 10", ErrInvalidURI)
+		}
+	}
+	if v := q.Get("period"); v != "" {
+		if key.Period, err = strconv.Atoi(v); err != nil || key.Period <= 0 {
+			return nil, fmt.Errorf("%w: period must be positive", ErrInvalidURI)
+		}
+	}
+	if v := q.Get("algorithm"); v != "" {
+		key.Algorithm = strings.ToUpper(v)
+	}
+	if _, err = key.hash(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Code возвращает одноразовый код для момента времени t и количество секунд до его смены
+func (k *Key) Code(t time.Time) (string, int) {
+	counter := uint64(t.Unix()) / uint64(k.Period)
+	remaining := k.Period - int(t.Unix()%int64(k.Period))
+	return k.hotp(counter), remaining
+}
+
+// hotp вычисляет код HOTP (RFC 4226) для значения счетчика
+func (k *Key) hotp(counter uint64) string {
+	newHash, _ := k.hash()
+	mac := hmac.New(newHash, k.Secret)
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// Динамическое усечение
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint64(1)
+	for i := 0; i < k.Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", k.Digits, uint64(value)%mod)
+}
+
+// hash возвращает хеш-функцию для алгоритма секрета
+func (k *Key) hash() (func() hash.Hash, error) {
+	switch k.Algorithm {
+	case "SHA1":
+		return sha1.New, nil
+	case "SHA256":
+		return sha256.New, nil
+	case "SHA512":
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidURI, k.Algorithm)
+	}
+}
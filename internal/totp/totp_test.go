@@ -0,0 +1,80 @@
+package totp
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("positive: defaults", func(t *testing.T) {
+		key, err := Parse("otpauth://totp/Winterfell:ned@stark.com?secret=JBSWY3DPEHPK3PXP")
+		assert.NoError(t, err)
+		assert.Equal(t, "Winterfell", key.Issuer)
+		assert.Equal(t, "ned@stark.com", key.Account)
+		assert.Equal(t, []byte("Hello!\xde\xad\xbe\xef"), key.Secret)
+		assert.Equal(t, 6, key.Digits)
+		assert.Equal(t, 30, key.Period)
+		assert.Equal(t, "SHA1", key.Algorithm)
+	})
+	t.Run("positive: explicit parameters", func(t *testing.T) {
+		key, err := Parse("otpauth://totp/ned?secret=jbswy3dpehpk3pxp&issuer=Stark&digits=8&period=60&algorithm=sha256")
+		assert.NoError(t, err)
+		assert.Equal(t, "Stark", key.Issuer)
+		assert.Equal(t, "ned", key.Account)
+		assert.Equal(t, 8, key.Digits)
+		assert.Equal(t, 60, key.Period)
+		assert.Equal(t, "SHA256", key.Algorithm)
+	})
+
+	negative := map[string]string{
+		"wrong scheme":          "https://totp/ned?secret=JBSWY3DPEHPK3PXP",
+		"hotp is not supported": "otpauth://hotp/ned?secret=JBSWY3DPEHPK3PXP",
+		"empty secret":          "otpauth://totp/ned",
+		"secret is not base32":  "otpauth://totp/ned?secret=18!",
+		"too few digits":        "otpauth://totp/ned?secret=JBSWY3DPEHPK3PXP&digits=4",
+		"negative period":       "otpauth://totp/ned?secret=JBSWY3DPEHPK3PXP&period=-30",
+		"unknown algorithm":     "otpauth://totp/ned?secret=JBSWY3DPEHPK3PXP&algorithm=MD5",
+	}
+	for name, uri := range negative {
+		t.Run("negative: "+name, func(t *testing.T) {
+			_, err := Parse(uri)
+			assert.ErrorIs(t, err, ErrInvalidURI)
+		})
+	}
+}
+
+// Тестовые векторы из приложения B RFC 6238
+func TestKey_Code(t *testing.T) {
+	secrets := map[string]string{
+		"SHA1":   "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+		"SHA256": "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZA",
+		"SHA512": "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNA",
+	}
+	testCases := []struct {
+		unix      int64
+		algorithm string
+		expected  string
+	}{
+		{unix: 59, algorithm: "SHA1", expected: "94287082"},
+		{unix: 59, algorithm: "SHA256", expected: "46119246"},
+		{unix: 59, algorithm: "SHA512", expected: "90693936"},
+		{unix: 1111111109, algorithm: "SHA1", expected: "07081804"},
+		{unix: 1111111109, algorithm: "SHA256", expected: "68084774"},
+		{unix: 1111111109, algorithm: "SHA512", expected: "25091201"},
+		{unix: 20000000000, algorithm: "SHA1", expected: "65353130"},
+		{unix: 20000000000, algorithm: "SHA256", expected: "77737706"},
+		{unix: 20000000000, algorithm: "SHA512", expected: "47863826"},
+	}
+	for _, tt := range testCases {
+		t.Run(fmt.Sprintf("%s at %d", tt.algorithm, tt.unix), func(t *testing.T) {
+			key, err := Parse(fmt.Sprintf("otpauth://totp/rfc?secret=%s&digits=8&algorithm=%s", secrets[tt.algorithm], tt.algorithm))
+			assert.NoError(t, err)
+			code, remaining := key.Code(time.Unix(tt.unix, 0))
+			assert.Equal(t, tt.expected, code)
+			assert.Equal(t, 30-int(tt.unix%30), remaining)
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// ErrInvalidItem означает, что в форме не заполнены обязательные поля или они некорректны
+var ErrInvalidItem = errors.New("invalid item")
+
+// Backend хранилище, с которым работает интерфейс
+type Backend interface {
+	// List возвращает все учетные данные, заметки и карты пользователя
+	List(ctx context.Context) ([]Item, error)
+	// Save сохраняет новый секрет
+	Save(ctx context.Context, item Item) error
+	// Update заменяет секрет old измененным item
+	Update(ctx context.Context, old, item Item) error
+	// Delete перемещает секрет в корзину
+	Delete(ctx context.Context, item Item) error
+}
+
+// Item секрет одного из типов: учетные данные, заметка или карта. Заполнено поле, соответствующее Type.
+type Item struct {
+	Type        models.SecretType
+	Credentials models.Credentials
+	Note        models.Note
+	Card        models.Card
+}
+
+// Title возвращает название секрета для списка
+func (it Item) Title() string {
+	switch it.Type {
+	case models.SecretCredentials:
+		if name := models.ValueOrEmpty(it.Credentials.Name); name != "" {
+			return name
+		}
+		return it.Credentials.ReportName()
+	case models.SecretNote:
+		return it.Note.ReportName()
+	default:
+		return it.Card.ReportName()
+	}
+}
+
+// Subtitle возвращает краткое описание секрета для списка
+func (it Item) Subtitle() string {
+	switch it.Type {
+	case models.SecretCredentials:
+		if models.ValueOrEmpty(it.Credentials.Name) != "" {
+			return it.Credentials.ReportName()
+		}
+		return models.ValueOrEmpty(it.Credentials.Metadata)
+	case models.SecretNote:
+		return models.ValueOrEmpty(it.Note.Metadata)
+	default:
+		return models.ValueOrEmpty(it.Card.CardType)
+	}
+}
+
+// Revision возвращает ревизию секрета
+func (it Item) Revision() int64 {
+	switch it.Type {
+	case models.SecretCredentials:
+		return it.Credentials.Revision
+	case models.SecretNote:
+		return it.Note.Revision
+	default:
+		return it.Card.Revision
+	}
+}
+
+// matches проверяет, что название, описание или метаинформация секрета содержат строку фильтра без учета регистра
+func (it Item) matches(filter string) bool {
+	if filter == "" {
+		return true
+	}
+	filter = strings.ToLower(filter)
+	for _, s := range []string{it.Title(), it.Subtitle(), models.ValueOrEmpty(it.Credentials.Metadata), models.ValueOrEmpty(it.Note.Metadata), models.ValueOrEmpty(it.Card.Metadata)} {
+		if strings.Contains(strings.ToLower(s), filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// field поле секрета для просмотра и редактирования
+type field struct {
+	label  string
+	value  string
+	secret bool // Значение скрывается, пока его не откроют
+	key    bool // Ключевое поле, которое нельзя изменить после сохранения
+}
+
+// fields возвращает поля секрета для просмотра и редактирования в постоянном порядке
+func (it Item) fields() []field {
+	var fields []field
+	var custom []models.CustomField
+	switch it.Type {
+	case models.SecretCredentials:
+		c := it.Credentials
+		fields = []field{
+			{label: "Логин", value: models.ValueOrEmpty(c.Login), key: true},
+			{label: "Сайт", value: models.ValueOrEmpty(c.Site), key: true},
+			{label: "Название", value: models.ValueOrEmpty(c.Name)},
+			{label: "Пароль", value: models.ValueOrEmpty(c.Password), secret: true},
+			{label: "Метаданные", value: models.ValueOrEmpty(c.Metadata)},
+		}
+		custom = c.Fields
+	case models.SecretNote:
+		n := it.Note
+		fields = []field{
+			{label: "Заголовок", value: models.ValueOrEmpty(n.Title), key: true},
+			{label: "Содержимое", value: models.ValueOrEmpty(n.Content), secret: true},
+			{label: "Метаданные", value: models.ValueOrEmpty(n.Metadata)},
+		}
+		custom = n.Fields
+	default:
+		c := it.Card
+		fields = []field{
+			{label: "Банк", value: models.ValueOrEmpty(c.BankName)},
+			{label: "Номер", value: models.ValueOrEmpty(c.Number)},
+			{label: "Тип", value: models.ValueOrEmpty(c.CardType)},
+			{label: "CV", value: models.ValueOrEmpty(c.CV), secret: true},
+			{label: "Пароль", value: models.ValueOrEmpty(c.Password), secret: true},
+			{label: "Метаданные", value: models.ValueOrEmpty(c.Metadata)},
+		}
+		custom = c.Fields
+	}
+	// Пользовательские поля показываются, но в форме не редактируются
+	for _, f := range custom {
+		fields = append(fields, field{label: f.Name, value: f.Value, secret: f.Type == models.FieldHidden, key: true})
+	}
+	return fields
+}
+
+// withFields возвращает копию секрета со значениями полей формы. Пустые необязательные поля сбрасываются.
+func (it Item) withFields(values []string) (Item, error) {
+	get := func(i int) *string {
+		if values[i] == "" {
+			return nil
+		}
+		v := values[i]
+		return &v
+	}
+	switch it.Type {
+	case models.SecretCredentials:
+		if values[0] == "" || values[3] == "" {
+			return Item{}, fmt.Errorf("%w: логин и пароль не должны быть пустыми", ErrInvalidItem)
+		}
+		it.Credentials.Login, it.Credentials.Site, it.Credentials.Name = get(0), get(1), get(2)
+		it.Credentials.Password, it.Credentials.Metadata = get(3), get(4)
+	case models.SecretNote:
+		if values[0] == "" || values[1] == "" {
+			return Item{}, fmt.Errorf("%w: заголовок и содержимое не должны быть пустыми", ErrInvalidItem)
+		}
+		it.Note.Title, it.Note.Content, it.Note.Metadata = get(0), get(1), get(2)
+	default:
+		for i := 0; i < 5; i++ {
+			if strings.TrimSpace(values[i]) == "" {
+				return Item{}, fmt.Errorf("%w: банк, номер, тип, CV и пароль карты не должны быть пустыми", ErrInvalidItem)
+			}
+		}
+		if len(values[1]) != 16 {
+			return Item{}, fmt.Errorf("%w: номер карты должен состоять из 16 цифр", ErrInvalidItem)
+		}
+		if len(values[3]) != 3 {
+			return Item{}, fmt.Errorf("%w: CV-код карты должен состоять из 3 цифр", ErrInvalidItem)
+		}
+		it.Card.BankName, it.Card.Number, it.Card.CardType = get(0), get(1), get(2)
+		it.Card.CV, it.Card.Password, it.Card.Metadata = get(3), get(4), get(5)
+	}
+	return it, nil
+}
+
+// newItem создает пустой секрет указанного типа
+func newItem(t models.SecretType, userName string) Item {
+	return Item{
+		Type:        t,
+		Credentials: models.Credentials{UserName: userName},
+		Note:        models.Note{UserName: userName},
+		Card:        models.Card{UserName: userName},
+	}
+}
+
+// typeLabel возвращает название типа секрета
+func typeLabel(t models.SecretType) string {
+	switch t {
+	case models.SecretCredentials:
+		return "Учетные данные"
+	case models.SecretNote:
+		return "Заметки"
+	case models.SecretCard:
+		return "Карты"
+	default:
+		return "Все"
+	}
+}
@@ -0,0 +1,88 @@
+package tui
+
+import "unicode/utf8"
+
+// KeyType определяет вид нажатой клавиши
+type KeyType int
+
+const (
+	KeyRune KeyType = iota // печатный символ
+	KeyCtrl                // сочетание Ctrl с буквой
+	KeyEnter
+	KeyEsc
+	KeyBackspace
+	KeyDelete
+	KeyTab
+	KeyBackTab
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyHome
+	KeyEnd
+	KeyPgUp
+	KeyPgDn
+)
+
+// Key нажатая клавиша. Rune заполняется для печатных символов и сочетаний с Ctrl (строчная латинская буква).
+type Key struct {
+	Type KeyType
+	Rune rune
+}
+
+// escapeSequences управляющие последовательности клавиш терминала без начального ESC
+var escapeSequences = map[string]KeyType{
+	"[A": KeyUp, "[B": KeyDown, "[C": KeyRight, "[D": KeyLeft,
+	"OA": KeyUp, "OB": KeyDown, "OC": KeyRight, "OD": KeyLeft,
+	"[H": KeyHome, "[F": KeyEnd, "OH": KeyHome, "OF": KeyEnd,
+	"[1~": KeyHome, "[4~": KeyEnd, "[7~": KeyHome, "[8~": KeyEnd,
+	"[3~": KeyDelete, "[5~": KeyPgUp, "[6~": KeyPgDn, "[Z": KeyBackTab,
+}
+
+// ParseKeys разбирает байты, прочитанные из терминала в режиме raw, на нажатые клавиши.
+// Одиночный ESC считается клавишей Esc, неизвестные управляющие последовательности пропускаются.
+func ParseKeys(buf []byte) []Key {
+	var keys []Key
+	for len(buf) > 0 {
+		b := buf[0]
+		switch {
+		case b == 0x1b:
+			if len(buf) == 1 || (buf[1] != '[' && buf[1] != 'O') {
+				keys = append(keys, Key{Type: KeyEsc})
+				buf = buf[1:]
+				continue
+			}
+			// Последовательность заканчивается буквой или тильдой
+			end := 2
+			for end < len(buf) && !(buf[end] >= 'A' && buf[end] <= 'Z' || buf[end] >= 'a' && buf[end] <= 'z' || buf[end] == '~') {
+				end++
+			}
+			if end == len(buf) {
+				return keys
+			}
+			if t, ok := escapeSequences[string(buf[1:end+1])]; ok {
+				keys = append(keys, Key{Type: t})
+			}
+			buf = buf[end+1:]
+			continue
+		case b == '\r' || b == '\n':
+			keys = append(keys, Key{Type: KeyEnter})
+		case b == '\t':
+			keys = append(keys, Key{Type: KeyTab})
+		case b == 0x7f || b == 0x08:
+			keys = append(keys, Key{Type: KeyBackspace})
+		case b >= 1 && b <= 26:
+			keys = append(keys, Key{Type: KeyCtrl, Rune: rune('a' + b - 1)})
+		case b < 0x20:
+		default:
+			r, size := utf8.DecodeRune(buf)
+			if r != utf8.RuneError {
+				keys = append(keys, Key{Type: KeyRune, Rune: r})
+			}
+			buf = buf[size:]
+			continue
+		}
+		buf = buf[1:]
+	}
+	return keys
+}
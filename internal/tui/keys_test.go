@@ -0,0 +1,29 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Key
+	}{
+		{name: "printable", input: "aд", want: []Key{{Type: KeyRune, Rune: 'a'}, {Type: KeyRune, Rune: 'д'}}},
+		{name: "arrows", input: "\x1b[A\x1b[B\x1bOC\x1b[D", want: []Key{{Type: KeyUp}, {Type: KeyDown}, {Type: KeyRight}, {Type: KeyLeft}}},
+		{name: "paging", input: "\x1b[5~\x1b[6~\x1b[H\x1b[4~", want: []Key{{Type: KeyPgUp}, {Type: KeyPgDn}, {Type: KeyHome}, {Type: KeyEnd}}},
+		{name: "control", input: "\r\t\x7f\x13\x1b[Z", want: []Key{{Type: KeyEnter}, {Type: KeyTab}, {Type: KeyBackspace}, {Type: KeyCtrl, Rune: 's'}, {Type: KeyBackTab}}},
+		{name: "single escape", input: "\x1b", want: []Key{{Type: KeyEsc}}},
+		{name: "escape before rune", input: "\x1bq", want: []Key{{Type: KeyEsc}, {Type: KeyRune, Rune: 'q'}}},
+		{name: "unknown sequence skipped", input: "\x1b[15~x", want: []Key{{Type: KeyRune, Rune: 'x'}}},
+		{name: "incomplete sequence dropped", input: "x\x1b[1", want: []Key{{Type: KeyRune, Rune: 'x'}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseKeys([]byte(tt.input)))
+		})
+	}
+}
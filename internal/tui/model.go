@@ -0,0 +1,553 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// screen определяет экран интерфейса
+type screen int
+
+const (
+	screenList       screen = iota // список секретов
+	screenView                     // просмотр секрета
+	screenForm                     // добавление или изменение секрета
+	screenConfirm                  // подтверждение удаления
+	screenChooseType               // выбор типа добавляемого секрета
+)
+
+// tabs вкладки списка: все секреты и секреты каждого типа
+var tabs = []models.SecretType{"", models.SecretCredentials, models.SecretNote, models.SecretCard}
+
+// typeOrder порядок типов секретов во вкладке со всеми секретами
+var typeOrder = map[models.SecretType]int{models.SecretCredentials: 0, models.SecretNote: 1, models.SecretCard: 2}
+
+// mask заменяет скрытое значение секретного поля
+const mask = "********"
+
+// form состояние формы добавления или изменения секрета
+type form struct {
+	item    Item // Исходный секрет
+	editing bool // Изменение сохраненного секрета, а не добавление нового
+	fields  []field
+	values  []string
+	focus   int
+}
+
+// editable проверяет, можно ли изменить поле формы: ключевые поля сохраненного секрета не изменяются
+func (f *form) editable(i int) bool {
+	return !f.editing || !f.fields[i].key
+}
+
+// move переводит фокус на следующее (step > 0) или предыдущее изменяемое поле
+func (f *form) move(step int) {
+	for i := f.focus + step; i >= 0 && i < len(f.fields); i += step {
+		if f.editable(i) {
+			f.focus = i
+			return
+		}
+	}
+}
+
+// Model состояние интерфейса. Обрабатывает нажатия клавиш и формирует строки экрана независимо от терминала.
+type Model struct {
+	backend       Backend
+	userName      string
+	revealTimeout time.Duration
+
+	items     []Item
+	tab       int
+	filter    string
+	filtering bool
+	cursor    int
+	offset    int
+
+	screen     screen
+	current    Item
+	form       form
+	revealed   bool
+	revealedAt time.Time
+
+	status    string
+	statusErr bool
+}
+
+// NewModel создает интерфейс для секретов пользователя userName. Открытые секреты скрываются через revealTimeout.
+func NewModel(backend Backend, userName string, revealTimeout time.Duration) *Model {
+	return &Model{backend: backend, userName: userName, revealTimeout: revealTimeout}
+}
+
+// Load загружает секреты из хранилища
+func (m *Model) Load(ctx context.Context) error {
+	items, err := m.backend.List(ctx)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Type != items[j].Type {
+			return typeOrder[items[i].Type] < typeOrder[items[j].Type]
+		}
+		return strings.ToLower(items[i].Title()) < strings.ToLower(items[j].Title())
+	})
+	m.items = items
+	m.clampCursor()
+	return nil
+}
+
+// visible возвращает секреты текущей вкладки, подходящие под фильтр
+func (m *Model) visible() []Item {
+	var res []Item
+	for _, it := range m.items {
+		if (tabs[m.tab] == "" || it.Type == tabs[m.tab]) && it.matches(m.filter) {
+			res = append(res, it)
+		}
+	}
+	return res
+}
+
+// clampCursor удерживает курсор в пределах списка
+func (m *Model) clampCursor() {
+	n := len(m.visible())
+	m.cursor = max(min(m.cursor, n-1), 0)
+}
+
+// setStatus выводит сообщение в строке состояния
+func (m *Model) setStatus(err error, format string, args ...any) {
+	if err != nil {
+		m.status, m.statusErr = err.Error(), true
+		return
+	}
+	m.status, m.statusErr = fmt.Sprintf(format, args...), false
+}
+
+// reveal открывает секретные поля до истечения revealTimeout
+func (m *Model) reveal(now time.Time) {
+	m.revealed, m.revealedAt = true, now
+}
+
+// hide скрывает секретные поля
+func (m *Model) hide() {
+	m.revealed = false
+}
+
+// Tick скрывает открытые секреты по истечении revealTimeout. Возвращает true, если экран нужно перерисовать.
+func (m *Model) Tick(now time.Time) bool {
+	if !m.revealed {
+		return false
+	}
+	if now.Sub(m.revealedAt) >= m.revealTimeout {
+		m.hide()
+		m.setStatus(nil, "секреты скрыты по истечении %s", m.revealTimeout)
+	}
+	// Пока секреты открыты, на экране обновляется время до их скрытия
+	return true
+}
+
+// HandleKey обрабатывает нажатие клавиши. Возвращает true, если работу интерфейса нужно завершить.
+func (m *Model) HandleKey(ctx context.Context, key Key, now time.Time) bool {
+	if key.Type == KeyCtrl && key.Rune == 'c' {
+		return true
+	}
+	switch m.screen {
+	case screenList:
+		return m.handleList(ctx, key, now)
+	case screenView:
+		m.handleView(key, now)
+	case screenForm:
+		m.handleForm(ctx, key, now)
+	case screenConfirm:
+		m.handleConfirm(ctx, key)
+	case screenChooseType:
+		m.handleChooseType(key)
+	}
+	return false
+}
+
+// handleList обрабатывает клавиши списка
+func (m *Model) handleList(ctx context.Context, key Key, now time.Time) bool {
+	visible := m.visible()
+	if m.filtering {
+		switch key.Type {
+		case KeyRune:
+			m.filter += string(key.Rune)
+			m.cursor = 0
+			return false
+		case KeyBackspace:
+			if m.filter != "" {
+				_, size := utf8.DecodeLastRuneInString(m.filter)
+				m.filter = m.filter[:len(m.filter)-size]
+			}
+			return false
+		case KeyEnter:
+			m.filtering = false
+			return false
+		case KeyEsc:
+			m.filtering, m.filter = false, ""
+			m.clampCursor()
+			return false
+		}
+	}
+
+	switch {
+	case key.Type == KeyUp || key.Type == KeyRune && key.Rune == 'k':
+		m.cursor = max(m.cursor-1, 0)
+	case key.Type == KeyDown || key.Type == KeyRune && key.Rune == 'j':
+		m.cursor = min(m.cursor+1, max(len(visible)-1, 0))
+	case key.Type == KeyPgUp:
+		m.cursor = max(m.cursor-10, 0)
+	case key.Type == KeyPgDn:
+		m.cursor = min(m.cursor+10, max(len(visible)-1, 0))
+	case key.Type == KeyHome || key.Type == KeyRune && key.Rune == 'g':
+		m.cursor = 0
+	case key.Type == KeyEnd || key.Type == KeyRune && key.Rune == 'G':
+		m.cursor = max(len(visible)-1, 0)
+	case key.Type == KeyTab || key.Type == KeyRight:
+		m.tab = (m.tab + 1) % len(tabs)
+		m.cursor = 0
+	case key.Type == KeyBackTab || key.Type == KeyLeft:
+		m.tab = (m.tab + len(tabs) - 1) % len(tabs)
+		m.cursor = 0
+	case key.Type == KeyRune && key.Rune == '/':
+		m.filtering = true
+	case key.Type == KeyEsc:
+		m.filter = ""
+		m.clampCursor()
+	case key.Type == KeyRune && key.Rune == 'q':
+		return true
+	case key.Type == KeyRune && key.Rune == 'r':
+		m.setStatus(m.Load(ctx), "список обновлен")
+	case key.Type == KeyRune && key.Rune == 'a':
+		if tabs[m.tab] == "" {
+			m.screen = screenChooseType
+		} else {
+			m.openForm(newItem(tabs[m.tab], m.userName), false)
+		}
+	case len(visible) == 0:
+	case key.Type == KeyEnter:
+		m.current = visible[m.cursor]
+		m.hide()
+		m.screen = screenView
+	case key.Type == KeyRune && key.Rune == 'e':
+		m.openForm(visible[m.cursor], true)
+	case key.Type == KeyRune && key.Rune == 'd':
+		m.current = visible[m.cursor]
+		m.screen = screenConfirm
+	}
+	return false
+}
+
+// handleView обрабатывает клавиши просмотра секрета
+func (m *Model) handleView(key Key, now time.Time) {
+	switch {
+	case key.Type == KeyEsc || key.Type == KeyLeft || key.Type == KeyRune && key.Rune == 'q':
+		m.hide()
+		m.screen = screenList
+	case key.Type == KeyRune && (key.Rune == 's' || key.Rune == ' '):
+		if m.revealed {
+			m.hide()
+		} else {
+			m.reveal(now)
+		}
+	case key.Type == KeyRune && key.Rune == 'e':
+		m.openForm(m.current, true)
+	case key.Type == KeyRune && key.Rune == 'd':
+		m.hide()
+		m.screen = screenConfirm
+	}
+}
+
+// openForm открывает форму добавления или изменения секрета
+func (m *Model) openForm(item Item, editing bool) {
+	fields := item.fields()
+	values := make([]string, len(fields))
+	for i, f := range fields {
+		values[i] = f.value
+	}
+	m.form = form{item: item, editing: editing, fields: fields, values: values, focus: -1}
+	m.form.move(1)
+	m.hide()
+	m.screen = screenForm
+}
+
+// handleForm обрабатывает клавиши формы
+func (m *Model) handleForm(ctx context.Context, key Key, now time.Time) {
+	f := &m.form
+	switch {
+	case key.Type == KeyEsc:
+		m.hide()
+		m.screen = screenList
+		m.setStatus(nil, "изменения отменены")
+	case key.Type == KeyUp || key.Type == KeyBackTab:
+		f.move(-1)
+	case key.Type == KeyDown || key.Type == KeyTab:
+		f.move(1)
+	case key.Type == KeyEnter:
+		last := f.focus
+		f.move(1)
+		if f.focus == last {
+			m.save(ctx)
+		}
+	case key.Type == KeyCtrl && key.Rune == 's':
+		m.save(ctx)
+	case key.Type == KeyCtrl && key.Rune == 'r':
+		if m.revealed {
+			m.hide()
+		} else {
+			m.reveal(now)
+		}
+	case key.Type == KeyCtrl && key.Rune == 'u':
+		f.values[f.focus] = ""
+	case key.Type == KeyBackspace:
+		if v := f.values[f.focus]; v != "" {
+			_, size := utf8.DecodeLastRuneInString(v)
+			f.values[f.focus] = v[:len(v)-size]
+		}
+	case key.Type == KeyRune:
+		f.values[f.focus] += string(key.Rune)
+	}
+}
+
+// save сохраняет секрет из формы и возвращается к списку
+func (m *Model) save(ctx context.Context) {
+	item, err := m.form.item.withFields(m.form.values)
+	if err == nil {
+		if m.form.editing {
+			err = m.backend.Update(ctx, m.form.item, item)
+		} else {
+			err = m.backend.Save(ctx, item)
+		}
+	}
+	if err != nil {
+		m.setStatus(err, "")
+		return
+	}
+	m.hide()
+	m.screen = screenList
+	if err = m.Load(ctx); err != nil {
+		m.setStatus(err, "")
+		return
+	}
+	m.setStatus(nil, "%q сохранено", item.Title())
+}
+
+// handleConfirm обрабатывает подтверждение удаления
+func (m *Model) handleConfirm(ctx context.Context, key Key) {
+	m.screen = screenList
+	if key.Type != KeyRune || (key.Rune != 'y' && key.Rune != 'д') {
+		m.setStatus(nil, "удаление отменено")
+		return
+	}
+	if err := m.backend.Delete(ctx, m.current); err != nil {
+		m.setStatus(err, "")
+		return
+	}
+	if err := m.Load(ctx); err != nil {
+		m.setStatus(err, "")
+		return
+	}
+	m.setStatus(nil, "%q перемещено в корзину", m.current.Title())
+}
+
+// handleChooseType обрабатывает выбор типа добавляемого секрета
+func (m *Model) handleChooseType(key Key) {
+	types := map[rune]models.SecretType{'1': models.SecretCredentials, '2': models.SecretNote, '3': models.SecretCard}
+	if t, ok := types[key.Rune]; ok && key.Type == KeyRune {
+		m.openForm(newItem(t, m.userName), false)
+		return
+	}
+	m.screen = screenList
+}
+
+// Render формирует строки экрана шириной width и высотой height. Строки могут содержать управляющие
+// последовательности оформления ANSI.
+func (m *Model) Render(width, height int, now time.Time) []string {
+	var body []string
+	var help string
+	switch m.screen {
+	case screenList, screenConfirm, screenChooseType:
+		body, help = m.renderList(width, height-3)
+		switch m.screen {
+		case screenConfirm:
+			help = fmt.Sprintf("Переместить %q в корзину? y - да, любая другая клавиша - нет", m.current.Title())
+		case screenChooseType:
+			help = "Добавить: 1 - учетные данные, 2 - заметку, 3 - карту, Esc - отмена"
+		}
+	case screenView:
+		body = m.renderView(width)
+		help = "s/пробел - показать/скрыть секреты  e - изменить  d - удалить  Esc - назад"
+	case screenForm:
+		body = m.renderForm(width)
+		help = "Tab/стрелки - поле  Enter - далее/сохранить  Ctrl+S - сохранить  Ctrl+R - показать секреты  Esc - отмена"
+	}
+
+	lines := []string{m.renderHeader(width)}
+	lines = append(lines, body...)
+	for len(lines) < height-2 {
+		lines = append(lines, "")
+	}
+	lines = lines[:max(height-2, 1)]
+
+	status := m.status
+	if m.revealed {
+		left := m.revealTimeout - now.Sub(m.revealedAt)
+		status = fmt.Sprintf("секреты открыты, скроются через %d с", int(left.Round(time.Second).Seconds()))
+	}
+	if m.statusErr && !m.revealed {
+		status = style(truncate(status, width), "31")
+	} else {
+		status = truncate(status, width)
+	}
+	return append(lines, status, style(truncate(help, width), "2"))
+}
+
+// renderHeader формирует строку заголовка с вкладками
+func (m *Model) renderHeader(width int) string {
+	header := style(truncate("GopherVault: "+m.userName, width), "1")
+	for i, t := range tabs {
+		label := " " + typeLabel(t) + " "
+		if i == m.tab {
+			label = style(label, "7")
+		}
+		header += " " + label
+	}
+	return header
+}
+
+// renderList формирует строки списка высотой не больше height
+func (m *Model) renderList(width, height int) ([]string, string) {
+	visible := m.visible()
+	var lines []string
+	if m.filtering || m.filter != "" {
+		cursor := ""
+		if m.filtering {
+			cursor = "_"
+		}
+		lines = append(lines, truncate("Фильтр: "+m.filter+cursor, width))
+		height--
+	}
+	if len(visible) == 0 {
+		lines = append(lines, style("нет секретов", "2"))
+	}
+
+	// Прокручиваем список так, чтобы курсор был виден
+	height = max(height, 1)
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+height {
+		m.offset = m.cursor - height + 1
+	}
+	for i := m.offset; i < len(visible) && i < m.offset+height; i++ {
+		it := visible[i]
+		line := fmt.Sprintf("%-9s %s", itemTag(it.Type), oneLine(it.Title()))
+		if sub := it.Subtitle(); sub != "" {
+			line += " - " + oneLine(sub)
+		}
+		line = truncate(line, width)
+		if i == m.cursor {
+			line = style(padRight(line, width), "7")
+		}
+		lines = append(lines, line)
+	}
+	help := "↑↓ - выбор  Tab - вкладка  / - фильтр  Enter - открыть  a - добавить  e - изменить  d - удалить  r - обновить  q - выход"
+	return lines, help
+}
+
+// renderView формирует строки просмотра секрета
+func (m *Model) renderView(width int) []string {
+	lines := []string{"", style(truncate(m.current.Title(), width), "1")}
+	for _, f := range m.current.fields() {
+		if f.value == "" {
+			continue
+		}
+		v := f.value
+		if f.secret && !m.revealed {
+			v = mask
+		}
+		for i, part := range strings.Split(v, "\n") {
+			label := fmt.Sprintf("%-12s", f.label+":")
+			if i > 0 {
+				label = strings.Repeat(" ", utf8.RuneCountInString(label))
+			}
+			lines = append(lines, truncate(label+" "+part, width))
+		}
+	}
+	if revision := m.current.Revision(); revision != 0 {
+		lines = append(lines, style(fmt.Sprintf("Ревизия %d", revision), "2"))
+	}
+	return lines
+}
+
+// renderForm формирует строки формы
+func (m *Model) renderForm(width int) []string {
+	f := &m.form
+	title := "Новый секрет: " + typeLabel(f.item.Type)
+	if f.editing {
+		title = "Изменение: " + f.item.Title()
+	}
+	lines := []string{"", style(truncate(title, width), "1")}
+	for i, fl := range f.fields {
+		v := oneLine(f.values[i])
+		if fl.secret && !m.revealed {
+			v = strings.Repeat("*", utf8.RuneCountInString(f.values[i]))
+		}
+		marker := "  "
+		if i == f.focus {
+			marker, v = "> ", v+"_"
+		}
+		line := truncate(fmt.Sprintf("%s%-12s %s", marker, fl.label+":", v), width)
+		if !f.editable(i) {
+			line = style(line, "2")
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// itemTag возвращает короткую метку типа секрета для списка
+func itemTag(t models.SecretType) string {
+	switch t {
+	case models.SecretCredentials:
+		return "[логин]"
+	case models.SecretNote:
+		return "[заметка]"
+	default:
+		return "[карта]"
+	}
+}
+
+// style оформляет строку управляющей последовательностью SGR
+func style(s, code string) string {
+	return "\x1b[" + code + "m" + s + "\x1b[0m"
+}
+
+// truncate обрезает строку до width символов
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	if width < 1 {
+		return ""
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// padRight дополняет строку пробелами до width символов
+func padRight(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// oneLine заменяет переводы строк, чтобы многострочное значение поместилось в одну строку
+func oneLine(s string) string {
+	return strings.ReplaceAll(s, "\n", " ⏎ ")
+}
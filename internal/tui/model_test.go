@@ -0,0 +1,301 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Ptr(s string) *string {
+	return &s
+}
+
+// fakeBackend хранилище в памяти для проверки интерфейса
+type fakeBackend struct {
+	items   []Item
+	saved   []Item
+	updated [][2]Item
+	deleted []Item
+	err     error
+}
+
+func (b *fakeBackend) List(context.Context) ([]Item, error) {
+	return append([]Item(nil), b.items...), b.err
+}
+
+func (b *fakeBackend) Save(_ context.Context, item Item) error {
+	if b.err != nil {
+		return b.err
+	}
+	b.saved = append(b.saved, item)
+	b.items = append(b.items, item)
+	return nil
+}
+
+func (b *fakeBackend) Update(_ context.Context, old, item Item) error {
+	if b.err != nil {
+		return b.err
+	}
+	b.updated = append(b.updated, [2]Item{old, item})
+	for i := range b.items {
+		if b.items[i].Title() == old.Title() {
+			b.items[i] = item
+		}
+	}
+	return nil
+}
+
+func (b *fakeBackend) Delete(_ context.Context, item Item) error {
+	if b.err != nil {
+		return b.err
+	}
+	b.deleted = append(b.deleted, item)
+	for i := range b.items {
+		if b.items[i].Title() == item.Title() {
+			b.items = append(b.items[:i], b.items[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func credentialsItem(login, site, password string) Item {
+	return Item{Type: models.SecretCredentials, Credentials: models.Credentials{
+		UserName: "bran", Login: Ptr(login), Site: Ptr(site), Password: Ptr(password), Audit: models.Audit{Revision: 2},
+	}}
+}
+
+func noteItem(title, content string) Item {
+	return Item{Type: models.SecretNote, Note: models.Note{UserName: "bran", Title: Ptr(title), Content: Ptr(content)}}
+}
+
+func cardItem(bank, number string) Item {
+	return Item{Type: models.SecretCard, Card: models.Card{
+		UserName: "bran", BankName: Ptr(bank), Number: Ptr(number), CardType: Ptr("visa"), CV: Ptr("123"), Password: Ptr("0000"),
+	}}
+}
+
+func newTestModel(t *testing.T) (*Model, *fakeBackend) {
+	backend := &fakeBackend{items: []Item{
+		cardItem("sber", "1111222233334444"),
+		noteItem("wifi", "winter-is-coming"),
+		credentialsItem("bran", "github.com", "s3cret-pass"),
+		credentialsItem("arya", "mail.com", "needle"),
+	}}
+	m := NewModel(backend, "bran", 15*time.Second)
+	require.NoError(t, m.Load(context.Background()))
+	return m, backend
+}
+
+// press передает интерфейсу нажатия клавиш, разобранные из строки
+func press(m *Model, input string, now time.Time) bool {
+	for _, key := range ParseKeys([]byte(input)) {
+		if m.HandleKey(context.Background(), key, now) {
+			return true
+		}
+	}
+	return false
+}
+
+// screenText возвращает содержимое экрана без оформления
+func screenText(m *Model, now time.Time) string {
+	text := strings.Join(m.Render(120, 30, now), "\n")
+	for strings.Contains(text, "\x1b[") {
+		start := strings.Index(text, "\x1b[")
+		end := strings.IndexByte(text[start:], 'm')
+		text = text[:start] + text[start+end+1:]
+	}
+	return text
+}
+
+func TestModel_ListNavigationAndTabs(t *testing.T) {
+	m, _ := newTestModel(t)
+	now := time.Now()
+
+	titles := make([]string, 0, len(m.visible()))
+	for _, it := range m.visible() {
+		titles = append(titles, it.Title())
+	}
+	assert.Equal(t, []string{"arya @ mail.com", "bran @ github.com", "wifi", "sber *4444"}, titles)
+
+	press(m, "jj", now)
+	assert.Equal(t, 2, m.cursor)
+	press(m, "\x1b[6~", now)
+	assert.Equal(t, 3, m.cursor)
+	press(m, "k", now)
+	assert.Equal(t, 2, m.cursor)
+
+	// Вкладка с заметками
+	press(m, "\t\t", now)
+	require.Len(t, m.visible(), 1)
+	assert.Equal(t, "wifi", m.visible()[0].Title())
+	assert.Equal(t, 0, m.cursor)
+	press(m, "\x1b[D\x1b[D", now)
+	assert.Len(t, m.visible(), 4)
+
+	assert.True(t, press(m, "q", now))
+}
+
+func TestModel_Filter(t *testing.T) {
+	m, _ := newTestModel(t)
+	now := time.Now()
+
+	press(m, "/GIT", now)
+	require.Len(t, m.visible(), 1)
+	assert.Equal(t, "bran @ github.com", m.visible()[0].Title())
+	assert.Contains(t, screenText(m, now), "Фильтр: GIT_")
+
+	// Пока вводится фильтр, буквы не считаются командами
+	assert.False(t, press(m, "\x7f\x7f\x7fq", now))
+	assert.Equal(t, "q", m.filter)
+	press(m, "\x7fmail\r", now)
+	assert.False(t, m.filtering)
+	require.Len(t, m.visible(), 1)
+
+	press(m, "\x1b", now)
+	assert.Equal(t, "", m.filter)
+	assert.Len(t, m.visible(), 4)
+}
+
+func TestModel_RevealAutoHide(t *testing.T) {
+	m, _ := newTestModel(t)
+	now := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+
+	press(m, "j\r", now)
+	require.Equal(t, screenView, m.screen)
+	text := screenText(m, now)
+	assert.Contains(t, text, "bran @ github.com")
+	assert.Contains(t, text, mask)
+	assert.NotContains(t, text, "s3cret-pass")
+	assert.False(t, m.Tick(now.Add(time.Minute)))
+
+	press(m, "s", now)
+	text = screenText(m, now.Add(5*time.Second))
+	assert.Contains(t, text, "s3cret-pass")
+	assert.Contains(t, text, "скроются через 10 с")
+
+	assert.True(t, m.Tick(now.Add(14*time.Second)))
+	assert.True(t, m.revealed)
+	assert.True(t, m.Tick(now.Add(15*time.Second)))
+	assert.False(t, m.revealed)
+	text = screenText(m, now.Add(15*time.Second))
+	assert.NotContains(t, text, "s3cret-pass")
+	assert.Contains(t, text, "секреты скрыты")
+
+	// Возврат к списку скрывает секреты
+	press(m, " ", now)
+	press(m, "\x1b", now)
+	assert.Equal(t, screenList, m.screen)
+	assert.False(t, m.revealed)
+}
+
+func TestModel_AddItem(t *testing.T) {
+	m, backend := newTestModel(t)
+	now := time.Now()
+
+	// На вкладке со всеми секретами сначала выбирается тип
+	press(m, "a2", now)
+	require.Equal(t, screenForm, m.screen)
+	press(m, "todo\r", now)
+	// Пустое содержимое не сохраняется
+	press(m, "\x13", now)
+	assert.True(t, m.statusErr)
+	assert.Contains(t, m.status, "не должны быть пустыми")
+	assert.Equal(t, screenForm, m.screen)
+
+	press(m, "buy milk", now)
+	assert.Contains(t, screenText(m, now), "Содержимое:  ********_")
+	press(m, "\r\r", now)
+	assert.Equal(t, screenList, m.screen)
+	require.Len(t, backend.saved, 1)
+	saved := backend.saved[0]
+	assert.Equal(t, models.SecretNote, saved.Type)
+	assert.Equal(t, "bran", saved.Note.UserName)
+	assert.Equal(t, "todo", *saved.Note.Title)
+	assert.Equal(t, "buy milk", *saved.Note.Content)
+	assert.Nil(t, saved.Note.Metadata)
+	assert.Len(t, m.items, 5)
+	assert.Contains(t, m.status, "сохранено")
+}
+
+func TestModel_AddCardValidation(t *testing.T) {
+	m, backend := newTestModel(t)
+	now := time.Now()
+
+	press(m, "\t\t\ta", now)
+	require.Equal(t, screenForm, m.screen)
+	press(m, "tinkoff\r1234\rmir\r999\r0000\r", now)
+	press(m, "\x13", now)
+	assert.Contains(t, m.status, "16 цифр")
+	assert.Empty(t, backend.saved)
+
+	// Возврат к номеру карты и его исправление
+	press(m, "\x1b[A\x1b[A\x1b[A\x1b[A", now)
+	press(m, "\x15", now)
+	press(m, "5555666677778888\x13", now)
+	require.Len(t, backend.saved, 1)
+	assert.Equal(t, "5555666677778888", *backend.saved[0].Card.Number)
+}
+
+func TestModel_EditItem(t *testing.T) {
+	m, backend := newTestModel(t)
+	now := time.Now()
+
+	press(m, "je", now)
+	require.Equal(t, screenForm, m.screen)
+	// Логин и сайт не изменяются, фокус на названии
+	assert.Equal(t, 2, m.form.focus)
+	press(m, "Work\r\x15new-pass\x13", now)
+
+	require.Len(t, backend.updated, 1)
+	old, item := backend.updated[0][0], backend.updated[0][1]
+	assert.Equal(t, "s3cret-pass", *old.Credentials.Password)
+	assert.Equal(t, "new-pass", *item.Credentials.Password)
+	assert.Equal(t, "Work", *item.Credentials.Name)
+	assert.Equal(t, "github.com", *item.Credentials.Site)
+	assert.Equal(t, int64(2), item.Revision())
+
+	// Отмена изменений
+	press(m, "e\x1b", now)
+	assert.Equal(t, screenList, m.screen)
+	assert.Len(t, backend.updated, 1)
+}
+
+func TestModel_DeleteItem(t *testing.T) {
+	m, backend := newTestModel(t)
+	now := time.Now()
+
+	press(m, "d", now)
+	assert.Contains(t, screenText(m, now), `Переместить "arya @ mail.com" в корзину?`)
+	press(m, "n", now)
+	assert.Empty(t, backend.deleted)
+	assert.Equal(t, "удаление отменено", m.status)
+
+	press(m, "jj\rd", now)
+	require.Equal(t, screenConfirm, m.screen)
+	press(m, "y", now)
+	require.Len(t, backend.deleted, 1)
+	assert.Equal(t, "wifi", backend.deleted[0].Title())
+	assert.Len(t, m.items, 3)
+	assert.Equal(t, screenList, m.screen)
+}
+
+func TestModel_BackendError(t *testing.T) {
+	m, backend := newTestModel(t)
+	now := time.Now()
+	backend.err = errors.New("сервер недоступен")
+
+	press(m, "d", now)
+	press(m, "y", now)
+	assert.True(t, m.statusErr)
+	assert.Contains(t, screenText(m, now), "сервер недоступен")
+
+	press(m, "r", now)
+	assert.Len(t, m.items, 4)
+}
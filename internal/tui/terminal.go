@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Управляющие последовательности терминала
+const (
+	enterAltScreen = "\x1b[?1049h\x1b[?25l" // альтернативный экран без курсора
+	leaveAltScreen = "\x1b[2J\x1b[?25h\x1b[?1049l"
+	cursorHome     = "\x1b[H"
+	clearLine      = "\x1b[K"
+	clearBelow     = "\x1b[J"
+)
+
+// tickInterval период проверки таймаута открытых секретов
+const tickInterval = time.Second
+
+// Run запускает полноэкранный интерфейс в терминале in до выхода пользователя или отмены ctx.
+// Интерфейс работает на альтернативном экране, поэтому после выхода секреты не остаются в истории терминала.
+func Run(ctx context.Context, m *Model, in *os.File, out io.Writer) error {
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("стандартный ввод не является терминалом")
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("ошибка при переводе терминала в режим raw: %w", err)
+	}
+	defer term.Restore(fd, state)
+	fmt.Fprint(out, enterAltScreen)
+	defer fmt.Fprint(out, leaveAltScreen)
+
+	input := make(chan []byte)
+	go func() {
+		buf := make([]byte, 256)
+		for {
+			n, err := in.Read(buf)
+			if err != nil {
+				close(input)
+				return
+			}
+			chunk := make([]byte, n)
+			copy(chunk, buf[:n])
+			input <- chunk
+		}
+	}()
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	draw(m, fd, out)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			if m.Tick(now) {
+				draw(m, fd, out)
+			}
+		case chunk, ok := <-input:
+			if !ok {
+				return nil
+			}
+			for _, key := range ParseKeys(chunk) {
+				if m.HandleKey(ctx, key, time.Now()) {
+					return nil
+				}
+			}
+			draw(m, fd, out)
+		}
+	}
+}
+
+// draw перерисовывает экран по размеру терминала
+func draw(m *Model, fd int, out io.Writer) {
+	width, height, err := term.GetSize(fd)
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = 80, 24
+	}
+	var b strings.Builder
+	b.WriteString(cursorHome)
+	for i, line := range m.Render(width, height, time.Now()) {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(line)
+		b.WriteString(clearLine)
+	}
+	b.WriteString(clearBelow)
+	io.WriteString(out, b.String())
+}
@@ -0,0 +1,145 @@
+package urlmatch
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Оценки качества совпадения: чем больше значение, тем точнее совпадение
+const (
+	scoreNone       = 0
+	scoreRegex      = 1
+	scoreBaseDomain = 2
+	scoreHost       = 3
+)
+
+// Matcher сопоставляет адреса сайтов с сохраненным адресом по его правилу.
+// Регулярное выражение компилируется один раз при создании.
+type Matcher struct {
+	rule models.MatchRule
+	url  string
+	re   *regexp.Regexp
+}
+
+// Compile создает Matcher для сохраненного адреса u. Возвращает ошибку для неизвестного правила
+// и для регулярного выражения, которое не компилируется.
+// Регулярное выражение должно совпадать со всем адресом, а не с его частью.
+func Compile(u models.CredentialURL) (Matcher, error) {
+	switch u.Match {
+	case "", models.MatchBaseDomain, models.MatchHost:
+		return Matcher{rule: u.Match, url: u.URL}, nil
+	case models.MatchRegex:
+		re, err := compileRegex(u.URL)
+		if err != nil {
+			return Matcher{}, fmt.Errorf("некорректное регулярное выражение адреса %q: %w", u.URL, err)
+		}
+		return Matcher{rule: u.Match, url: u.URL, re: re}, nil
+	default:
+		return Matcher{}, fmt.Errorf("неизвестное правило сопоставления адреса %q: допустимы %s, %s и %s",
+			u.Match, models.MatchBaseDomain, models.MatchHost, models.MatchRegex)
+	}
+}
+
+// regexCache скомпилированные регулярные выражения сохраненных адресов
+var regexCache sync.Map
+
+// compileRegex компилирует регулярное выражение, привязанное к началу и концу адреса,
+// или возвращает уже скомпилированное
+func compileRegex(expr string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(`^(?:` + expr + `)$`)
+	if err != nil {
+		return nil, err
+	}
+	regexCache.Store(expr, re)
+	return re, nil
+}
+
+// Validate проверяет правила сопоставления всех адресов
+func Validate(urls []models.CredentialURL) error {
+	for _, u := range urls {
+		if _, err := Compile(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Score возвращает оценку совпадения адреса target с сохраненным адресом.
+// Нулевое значение означает, что адрес не подходит.
+func (m Matcher) Score(target string) int {
+	switch m.rule {
+	case models.MatchRegex:
+		if !m.re.MatchString(target) {
+			return scoreNone
+		}
+		return scoreRegex
+	case models.MatchHost:
+		if host(m.url) != "" && host(m.url) == host(target) {
+			return scoreHost
+		}
+		return scoreNone
+	default:
+		storedHost, targetHost := host(m.url), host(target)
+		if storedHost == "" || targetHost == "" {
+			return scoreNone
+		}
+		if storedHost == targetHost {
+			return scoreHost
+		}
+		if baseDomain(storedHost) == baseDomain(targetHost) {
+			return scoreBaseDomain
+		}
+		return scoreNone
+	}
+}
+
+// Best возвращает учетные данные, наиболее точно подходящие для адреса target.
+// При равной оценке выбираются учетные данные, идущие в списке раньше.
+// Возвращает ошибку, если правило сохраненного адреса некорректно.
+func Best(creds []models.Credentials, target string) (models.Credentials, bool, error) {
+	var best models.Credentials
+	bestScore := scoreNone
+	for _, c := range creds {
+		for _, u := range c.URLs {
+			m, err := Compile(u)
+			if err != nil {
+				return models.Credentials{}, false, err
+			}
+			if s := m.Score(target); s > bestScore {
+				best, bestScore = c, s
+			}
+		}
+	}
+	return best, bestScore > scoreNone, nil
+}
+
+// host извлекает имя хоста из адреса; адрес без схемы трактуется как https
+func host(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// baseDomain возвращает зарегистрированный домен (eTLD+1) для имени хоста
+func baseDomain(h string) string {
+	d, err := publicsuffix.EffectiveTLDPlusOne(h)
+	if err != nil {
+		return h
+	}
+	return d
+}
@@ -0,0 +1,127 @@
+package urlmatch
+
+import (
+	"testing"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScore(t *testing.T) {
+	testCases := []struct {
+		name     string
+		stored   models.CredentialURL
+		target   string
+		expected int
+	}{
+		{
+			name:     "base domain: same host",
+			stored:   models.CredentialURL{URL: "https://mail.example.com/login"},
+			target:   "https://mail.example.com/inbox",
+			expected: scoreHost,
+		},
+		{
+			name:     "base domain: other subdomain",
+			stored:   models.CredentialURL{URL: "https://mail.example.com"},
+			target:   "accounts.example.com",
+			expected: scoreBaseDomain,
+		},
+		{
+			name:     "base domain: public suffix is respected",
+			stored:   models.CredentialURL{URL: "https://alice.github.io"},
+			target:   "https://bob.github.io",
+			expected: scoreNone,
+		},
+		{
+			name:     "host: other subdomain does not match",
+			stored:   models.CredentialURL{URL: "https://mail.example.com", Match: models.MatchHost},
+			target:   "https://accounts.example.com",
+			expected: scoreNone,
+		},
+		{
+			name:     "host: exact host",
+			stored:   models.CredentialURL{URL: "mail.example.com", Match: models.MatchHost},
+			target:   "https://MAIL.example.com/path",
+			expected: scoreHost,
+		},
+		{
+			name:     "regex: matches",
+			stored:   models.CredentialURL{URL: `https://[a-z]+\.corp\.local/.*`, Match: models.MatchRegex},
+			target:   "https://wiki.corp.local/page",
+			expected: scoreRegex,
+		},
+		{
+			name:     "regex: must match the whole url",
+			stored:   models.CredentialURL{URL: `https://bank\.com/`, Match: models.MatchRegex},
+			target:   "https://evil.example/?next=https://bank.com/",
+			expected: scoreNone,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Compile(tt.stored)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, m.Score(tt.target))
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	testCases := []struct {
+		name    string
+		stored  models.CredentialURL
+		wantErr bool
+	}{
+		{name: "positive: default rule", stored: models.CredentialURL{URL: "example.com"}},
+		{name: "positive: host", stored: models.CredentialURL{URL: "example.com", Match: models.MatchHost}},
+		{name: "positive: regex", stored: models.CredentialURL{URL: `https://.*\.example\.com/.*`, Match: models.MatchRegex}},
+		{name: "negative: unknown rule", stored: models.CredentialURL{URL: "example.com", Match: "prefix"}, wantErr: true},
+		{name: "negative: invalid expression", stored: models.CredentialURL{URL: `([`, Match: models.MatchRegex}, wantErr: true},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.stored)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestBest(t *testing.T) {
+	creds := []models.Credentials{
+		{UserName: "bran", Login: Ptr("regex"), URLs: []models.CredentialURL{{URL: `https://[a-z]+\.example\.com(/.*)?`, Match: models.MatchRegex}}},
+		{UserName: "bran", Login: Ptr("domain"), URLs: []models.CredentialURL{{URL: "https://www.example.com"}}},
+		{UserName: "bran", Login: Ptr("host"), URLs: []models.CredentialURL{{URL: "https://mail.example.com", Match: models.MatchHost}}},
+		{UserName: "bran", Login: Ptr("none")},
+	}
+
+	t.Run("positive: exact host wins", func(t *testing.T) {
+		best, ok, err := Best(creds, "https://mail.example.com/inbox")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "host", *best.Login)
+	})
+	t.Run("positive: base domain beats regex", func(t *testing.T) {
+		best, ok, err := Best(creds, "https://news.example.com")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "domain", *best.Login)
+	})
+	t.Run("negative: nothing matches", func(t *testing.T) {
+		_, ok, err := Best(creds, "https://example.org")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+	t.Run("negative: invalid stored rule", func(t *testing.T) {
+		invalid := []models.Credentials{{UserName: "bran", Login: Ptr("broken"), URLs: []models.CredentialURL{{URL: `([`, Match: models.MatchRegex}}}}
+		_, _, err := Best(invalid, "https://example.com")
+		assert.Error(t, err)
+	})
+}
+
+func Ptr(s string) *string {
+	return &s
+}
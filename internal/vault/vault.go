@@ -0,0 +1,137 @@
+// Package vault выгружает все секреты пользователя и загружает их обратно в хранилище,
+// в том числе на другом сервере или под другим именем пользователя.
+//
+// Загрузка выполняется в одной транзакции хранилища; загруженные секреты попадают в историю версий
+// и журнал изменений так же, как секреты, сохраненные клиентом.
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ZnNr/GopherVault/internal/database"
+	"github.com/ZnNr/GopherVault/internal/models"
+)
+
+// ErrInvalidImport означает некорректный запрос на загрузку секретов
+var ErrInvalidImport = errors.New("invalid import")
+
+// Export выгружает все секреты пользователя: папки, учетные данные, заметки, карты, секреты TOTP и SSH-ключи.
+// Секреты из корзины не выгружаются.
+func Export(ctx context.Context, db models.Storage, userName string, now time.Time) (models.Vault, error) {
+	vault := models.Vault{Version: models.VaultVersion, ExportedAt: now.UTC()}
+	var err error
+	if vault.Folders, err = ignoreNoData(db.GetFolders(ctx, models.Folder{UserName: userName})); err != nil {
+		return models.Vault{}, err
+	}
+	if vault.Credentials, _, err = ignoreNoDataPage(db.GetCredentials(ctx, models.Credentials{UserName: userName}, models.ListOptions{})); err != nil {
+		return models.Vault{}, err
+	}
+	if vault.Notes, _, err = ignoreNoDataPage(db.GetNotes(ctx, models.Note{UserName: userName}, models.ListOptions{})); err != nil {
+		return models.Vault{}, err
+	}
+	if vault.Cards, _, err = ignoreNoDataPage(db.GetCard(ctx, models.Card{UserName: userName}, models.ListOptions{})); err != nil {
+		return models.Vault{}, err
+	}
+	if vault.TOTP, err = ignoreNoData(db.GetTOTP(ctx, models.TOTP{UserName: userName})); err != nil {
+		return models.Vault{}, err
+	}
+	if vault.SSHKeys, err = ignoreNoData(db.GetSSHKeys(ctx, models.SSHKey{UserName: userName})); err != nil {
+		return models.Vault{}, err
+	}
+	return vault, nil
+}
+
+// Validate проверяет версию выгрузки, режим загрузки и обязательные поля секретов
+func Validate(vaultImport models.VaultImport) error {
+	if vaultImport.Vault.Version != models.VaultVersion {
+		return fmt.Errorf("%w: неподдерживаемая версия выгрузки %d", ErrInvalidImport, vaultImport.Vault.Version)
+	}
+	switch vaultImport.Mode {
+	case "", models.ImportSkipDuplicates, models.ImportMerge, models.ImportReplace:
+	default:
+		return fmt.Errorf("%w: неизвестный режим загрузки %q", ErrInvalidImport, vaultImport.Mode)
+	}
+	vault := vaultImport.Vault
+	for i, folder := range vault.Folders {
+		if folder.Path == nil {
+			return fmt.Errorf("%w: у папки %d не указан путь", ErrInvalidImport, i+1)
+		}
+	}
+	for i, creds := range vault.Credentials {
+		if creds.Login == nil || creds.Password == nil {
+			return fmt.Errorf("%w: у учетных данных %d не указан логин или пароль", ErrInvalidImport, i+1)
+		}
+	}
+	for i, note := range vault.Notes {
+		if note.Title == nil || note.Content == nil {
+			return fmt.Errorf("%w: у заметки %d не указан заголовок или содержимое", ErrInvalidImport, i+1)
+		}
+	}
+	for i, card := range vault.Cards {
+		if card.BankName == nil || card.Number == nil || card.CV == nil || card.Password == nil {
+			return fmt.Errorf("%w: у карты %d не указан банк, номер, CV или пароль", ErrInvalidImport, i+1)
+		}
+	}
+	for i, totp := range vault.TOTP {
+		if totp.Name == nil || totp.URI == nil {
+			return fmt.Errorf("%w: у секрета TOTP %d не указано название или адрес", ErrInvalidImport, i+1)
+		}
+	}
+	for i, key := range vault.SSHKeys {
+		if key.Name == nil || key.PrivateKey == nil || key.PublicKey == nil || key.Fingerprint == nil || key.KeyType == nil {
+			return fmt.Errorf("%w: у SSH-ключа %d не указано название или ключ", ErrInvalidImport, i+1)
+		}
+	}
+	return nil
+}
+
+// MaxBatchSize максимальное число секретов в пакете, переносимом из другого менеджера паролей
+const MaxBatchSize = 1000
+
+// ValidateBatch проверяет размер пакета и обязательные поля секретов, переносимых из другого менеджера паролей
+func ValidateBatch(batch models.ImportBatch) error {
+	if size := len(batch.Credentials) + len(batch.Notes) + len(batch.Cards); size > MaxBatchSize {
+		return fmt.Errorf("%w: в пакете %d секретов, допускается не больше %d", ErrInvalidImport, size, MaxBatchSize)
+	}
+	return Validate(models.VaultImport{Vault: models.Vault{
+		Version:     models.VaultVersion,
+		Credentials: batch.Credentials,
+		Notes:       batch.Notes,
+		Cards:       batch.Cards,
+	}})
+}
+
+// Import проверяет выгрузку и загружает секреты в хранилище пользователя vaultImport.UserName.
+//
+// В режиме skip-duplicates секреты, которые уже есть в хранилище, пропускаются, в режиме merge - заменяются
+// загружаемыми. В режиме replace все секреты пользователя предварительно удаляются: учетные данные, заметки
+// и карты перемещаются в корзину, а секреты TOTP и SSH-ключи удаляются окончательно.
+// Загрузка атомарна: при ошибке хранилище пользователя остается без изменений.
+func Import(ctx context.Context, db models.Storage, vaultImport models.VaultImport) (models.ImportResult, error) {
+	if err := Validate(vaultImport); err != nil {
+		return models.ImportResult{}, err
+	}
+	if vaultImport.Mode == "" {
+		vaultImport.Mode = models.ImportSkipDuplicates
+	}
+	return db.ImportVault(ctx, vaultImport)
+}
+
+// ignoreNoData возвращает пустой список вместо ошибки отсутствия данных
+func ignoreNoData[T any](items []T, err error) ([]T, error) {
+	if errors.Is(err, database.ErrNoData) {
+		return nil, nil
+	}
+	return items, err
+}
+
+// ignoreNoDataPage возвращает пустую страницу вместо ошибки отсутствия данных
+func ignoreNoDataPage[T any](items []T, cursor string, err error) ([]T, string, error) {
+	if errors.Is(err, database.ErrNoData) {
+		return nil, "", nil
+	}
+	return items, cursor, err
+}
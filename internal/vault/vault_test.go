@@ -0,0 +1,143 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ZnNr/GopherVault/internal/database"
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/ZnNr/GopherVault/internal/models/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func Ptr(s string) *string {
+	return &s
+}
+
+func TestExport(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	id := int64(4)
+
+	db := mocks.NewStorage(t)
+	db.On("GetFolders", ctx, models.Folder{UserName: "sansa"}).Return([]models.Folder{{UserName: "sansa", Path: Ptr("work")}}, nil)
+	db.On("GetCredentials", ctx, models.Credentials{UserName: "sansa"}, models.ListOptions{}).
+		Return([]models.Credentials{{UserName: "sansa", Login: Ptr("lady"), Password: Ptr("winterfell"), Folder: Ptr("work"), Audit: models.Audit{ID: &id, Revision: 2}}}, "", nil)
+	db.On("GetNotes", ctx, models.Note{UserName: "sansa"}, models.ListOptions{}).Return(nil, "", database.ErrNoData)
+	db.On("GetCard", ctx, models.Card{UserName: "sansa"}, models.ListOptions{}).Return(nil, "", database.ErrNoData)
+	db.On("GetTOTP", ctx, models.TOTP{UserName: "sansa"}).Return(nil, database.ErrNoData)
+	db.On("GetSSHKeys", ctx, models.SSHKey{UserName: "sansa"}).Return(nil, database.ErrNoData)
+
+	vault, err := Export(ctx, db, "sansa", now)
+	assert.NoError(t, err)
+	assert.Equal(t, models.Vault{
+		Version:     models.VaultVersion,
+		ExportedAt:  now,
+		Folders:     []models.Folder{{UserName: "sansa", Path: Ptr("work")}},
+		Credentials: []models.Credentials{{UserName: "sansa", Login: Ptr("lady"), Password: Ptr("winterfell"), Folder: Ptr("work"), Audit: models.Audit{ID: &id, Revision: 2}}},
+	}, vault)
+}
+
+func TestExport_StorageError(t *testing.T) {
+	ctx := context.Background()
+	db := mocks.NewStorage(t)
+	db.On("GetFolders", ctx, models.Folder{UserName: "sansa"}).Return(nil, database.ErrNoData)
+	db.On("GetCredentials", ctx, models.Credentials{UserName: "sansa"}, models.ListOptions{}).Return(nil, "", errors.New("connection refused"))
+
+	_, err := Export(ctx, db, "sansa", time.Now())
+	assert.EqualError(t, err, "connection refused")
+}
+
+func TestImport(t *testing.T) {
+	ctx := context.Background()
+	vault := models.Vault{
+		Version: models.VaultVersion,
+		Notes:   []models.Note{{UserName: "arya", Title: Ptr("list"), Content: Ptr("needle")}},
+	}
+
+	t.Run("positive: skip-duplicates is the default mode", func(t *testing.T) {
+		db := mocks.NewStorage(t)
+		db.On("ImportVault", ctx, models.VaultImport{UserName: "sansa", Mode: models.ImportSkipDuplicates, Vault: vault}).
+			Return(models.ImportResult{Created: 1}, nil)
+
+		res, err := Import(ctx, db, models.VaultImport{UserName: "sansa", Vault: vault})
+		assert.NoError(t, err)
+		assert.Equal(t, models.ImportResult{Created: 1}, res)
+	})
+	t.Run("positive: mode is passed to the storage", func(t *testing.T) {
+		db := mocks.NewStorage(t)
+		db.On("ImportVault", ctx, models.VaultImport{UserName: "sansa", Mode: models.ImportReplace, Vault: vault}).
+			Return(models.ImportResult{Created: 1}, nil)
+
+		res, err := Import(ctx, db, models.VaultImport{UserName: "sansa", Mode: models.ImportReplace, Vault: vault})
+		assert.NoError(t, err)
+		assert.Equal(t, models.ImportResult{Created: 1}, res)
+	})
+	t.Run("negative: invalid vault is not imported", func(t *testing.T) {
+		db := mocks.NewStorage(t)
+
+		_, err := Import(ctx, db, models.VaultImport{UserName: "sansa", Mode: "overwrite", Vault: vault})
+		assert.ErrorIs(t, err, ErrInvalidImport)
+	})
+	t.Run("negative: storage error", func(t *testing.T) {
+		db := mocks.NewStorage(t)
+		db.On("ImportVault", ctx, models.VaultImport{UserName: "sansa", Mode: models.ImportMerge, Vault: vault}).
+			Return(models.ImportResult{}, errors.New("connection refused"))
+
+		res, err := Import(ctx, db, models.VaultImport{UserName: "sansa", Mode: models.ImportMerge, Vault: vault})
+		assert.EqualError(t, err, "connection refused")
+		assert.Equal(t, models.ImportResult{}, res)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		vaultImport models.VaultImport
+		expectedErr string
+	}{
+		{
+			name:        "positive: empty vault",
+			vaultImport: models.VaultImport{Vault: models.Vault{Version: models.VaultVersion}},
+		},
+		{
+			name:        "negative: unsupported version",
+			vaultImport: models.VaultImport{Vault: models.Vault{Version: 2}},
+			expectedErr: "invalid import: неподдерживаемая версия выгрузки 2",
+		},
+		{
+			name:        "negative: unknown mode",
+			vaultImport: models.VaultImport{Mode: "overwrite", Vault: models.Vault{Version: models.VaultVersion}},
+			expectedErr: `invalid import: неизвестный режим загрузки "overwrite"`,
+		},
+		{
+			name: "negative: note without content",
+			vaultImport: models.VaultImport{Vault: models.Vault{Version: models.VaultVersion,
+				Notes: []models.Note{{Title: Ptr("list"), Content: Ptr("needle")}, {Title: Ptr("empty")}}}},
+			expectedErr: "invalid import: у заметки 2 не указан заголовок или содержимое",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.vaultImport)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidImport)
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestValidateBatch(t *testing.T) {
+	assert.NoError(t, ValidateBatch(models.ImportBatch{Notes: []models.Note{{Title: Ptr("list"), Content: Ptr("")}}}))
+
+	err := ValidateBatch(models.ImportBatch{Credentials: []models.Credentials{{Login: Ptr("lady")}}})
+	assert.EqualError(t, err, "invalid import: у учетных данных 1 не указан логин или пароль")
+
+	err = ValidateBatch(models.ImportBatch{Notes: make([]models.Note, MaxBatchSize+1)})
+	assert.ErrorIs(t, err, ErrInvalidImport)
+}
@@ -0,0 +1,130 @@
+// Package vaultfile шифрует выгрузку секретов пользователя в переносимый файл.
+//
+// Ключ файла получается из парольной фразы с помощью Argon2id, содержимое шифруется AES-256-GCM.
+// Формат файла: заголовок "GVX1", параметры Argon2id (время и память - uint32, число потоков - uint8),
+// соль, nonce и шифротекст. Заголовок вместе с солью аутентифицируется как дополнительные данные AEAD,
+// поэтому подмена параметров обнаруживается при расшифровке.
+package vaultfile
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	saltSize = 16
+	keySize  = 32
+	// argon2Time, argon2Memory и argon2Threads параметры Argon2id для новых файлов
+	argon2Time    = 3
+	argon2Memory  = 64 * 1024
+	argon2Threads = 4
+	// maxArgon2Time и maxArgon2Memory ограничивают параметры из файла, чтобы поврежденный файл не исчерпал ресурсы
+	maxArgon2Time   = 16
+	maxArgon2Memory = 1024 * 1024
+	// MinPassphraseLength минимальная длина парольной фразы
+	MinPassphraseLength = 8
+)
+
+// magic заголовок файла выгрузки, по которому проверяется формат
+var magic = []byte("GVX1")
+
+// headerSize размер заголовка с параметрами Argon2id и солью
+var headerSize = len(magic) + 4 + 4 + 1 + saltSize
+
+var (
+	// ErrWrongPassphrase означает, что файл не удалось расшифровать: парольная фраза неверна или файл поврежден
+	ErrWrongPassphrase = errors.New("wrong passphrase or corrupted file")
+	// ErrUnsupportedFormat означает, что файл не является выгрузкой GopherVault
+	ErrUnsupportedFormat = errors.New("unsupported vault file format")
+	// ErrWeakPassphrase означает, что парольная фраза короче MinPassphraseLength символов
+	ErrWeakPassphrase = fmt.Errorf("passphrase must be at least %d characters long", MinPassphraseLength)
+)
+
+// Seal шифрует выгрузку секретов ключом, полученным из парольной фразы
+func Seal(vault models.Vault, passphrase string) ([]byte, error) {
+	if len([]rune(passphrase)) < MinPassphraseLength {
+		return nil, ErrWeakPassphrase
+	}
+	plaintext, err := json.Marshal(vault)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при маршалинге выгрузки: %w", err)
+	}
+
+	header := make([]byte, 0, headerSize)
+	header = append(header, magic...)
+	header = binary.BigEndian.AppendUint32(header, argon2Time)
+	header = binary.BigEndian.AppendUint32(header, argon2Memory)
+	header = append(header, argon2Threads)
+	salt := make([]byte, saltSize)
+	if _, err = rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("ошибка при генерации соли: %w", err)
+	}
+	header = append(header, salt...)
+
+	aead, err := newAEAD(passphrase, salt, argon2Time, argon2Memory, argon2Threads)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("ошибка при генерации nonce: %w", err)
+	}
+	out := append(append([]byte{}, header...), nonce...)
+	return aead.Seal(out, nonce, plaintext, header), nil
+}
+
+// Open расшифровывает файл выгрузки парольной фразой
+func Open(data []byte, passphrase string) (models.Vault, error) {
+	if len(data) < headerSize || !bytes.Equal(data[:len(magic)], magic) {
+		return models.Vault{}, ErrUnsupportedFormat
+	}
+	header := data[:headerSize]
+	params := header[len(magic):]
+	iterations, memory, threads := binary.BigEndian.Uint32(params[0:4]), binary.BigEndian.Uint32(params[4:8]), params[8]
+	if iterations == 0 || iterations > maxArgon2Time || memory == 0 || memory > maxArgon2Memory || threads == 0 {
+		return models.Vault{}, fmt.Errorf("%w: недопустимые параметры Argon2id", ErrUnsupportedFormat)
+	}
+	salt := params[9:]
+
+	aead, err := newAEAD(passphrase, salt, iterations, memory, threads)
+	if err != nil {
+		return models.Vault{}, err
+	}
+	sealed := data[headerSize:]
+	if len(sealed) < aead.NonceSize() {
+		return models.Vault{}, ErrWrongPassphrase
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		return models.Vault{}, ErrWrongPassphrase
+	}
+	var vault models.Vault
+	if err = json.Unmarshal(plaintext, &vault); err != nil {
+		return models.Vault{}, fmt.Errorf("ошибка при разборе выгрузки: %w", err)
+	}
+	return vault, nil
+}
+
+// newAEAD создает шифр AES-256-GCM с ключом, полученным из парольной фразы
+func newAEAD(passphrase string, salt []byte, iterations, memory uint32, threads uint8) (cipher.AEAD, error) {
+	key := argon2.IDKey([]byte(passphrase), salt, iterations, memory, threads, keySize)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при создании шифра: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при создании шифра: %w", err)
+	}
+	return aead, nil
+}
@@ -0,0 +1,68 @@
+package vaultfile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ZnNr/GopherVault/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestSealOpen(t *testing.T) {
+	vault := models.Vault{
+		Version:     models.VaultVersion,
+		ExportedAt:  time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC),
+		Credentials: []models.Credentials{{UserName: "sansa", Login: strPtr("lady"), Password: strPtr("winterfell")}},
+		Notes:       []models.Note{{UserName: "sansa", Title: strPtr("list"), Content: strPtr("lemon cakes")}},
+	}
+
+	data, err := Seal(vault, "correct horse battery")
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "winterfell")
+	assert.NotContains(t, string(data), "lemon cakes")
+
+	opened, err := Open(data, "correct horse battery")
+	require.NoError(t, err)
+	assert.Equal(t, vault, opened)
+
+	// Повторное шифрование использует новые соль и nonce
+	again, err := Seal(vault, "correct horse battery")
+	require.NoError(t, err)
+	assert.NotEqual(t, data, again)
+}
+
+func TestOpen_Errors(t *testing.T) {
+	data, err := Seal(models.Vault{Version: models.VaultVersion}, "correct horse battery")
+	require.NoError(t, err)
+
+	_, err = Open(data, "wrong horse battery")
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+
+	// Изменение параметров в заголовке обнаруживается при расшифровке
+	tampered := append([]byte{}, data...)
+	tampered[len(magic)+3]++
+	_, err = Open(tampered, "correct horse battery")
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+
+	// Параметры, требующие слишком много памяти, отклоняются до получения ключа
+	tampered = append([]byte{}, data...)
+	tampered[len(magic)+4] = 0xff
+	_, err = Open(tampered, "correct horse battery")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+
+	_, err = Open([]byte(`{"version":1}`), "correct horse battery")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+
+	_, err = Open(data[:headerSize+2], "correct horse battery")
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+}
+
+func TestSeal_WeakPassphrase(t *testing.T) {
+	_, err := Seal(models.Vault{Version: models.VaultVersion}, "short")
+	assert.ErrorIs(t, err, ErrWeakPassphrase)
+}